@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package client is a Go client for the flowd serve-mode REST API.
+//
+// A Client exposes typed services for runs, plans and sources:
+//
+//	c := client.New("http://127.0.0.1:8080", client.WithToken(os.Getenv("FLWD_TOKEN")))
+//	run, err := c.Runs.Create(ctx, client.RunRequest{JobID: "hello-world"}, nil)
+//	stream, err := c.Runs.Events(ctx, run.ID, nil)
+//	for stream.Next() {
+//		fmt.Println(stream.Event().Type)
+//	}
+//
+// Non-2xx responses are returned as *APIError carrying the RFC7807 problem.
+// CheckVersion compares the client's APIVersion with the server's before use.
+//
+// The TypeScript client in ts/ is generated from this package's types.
+package client
+
+//go:generate go run ./internal/tsgen -out ts/src/flowd.ts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBaseURL   = "http://127.0.0.1:8080"
+	defaultUserAgent = "flowd-go-client"
+	defaultTimeout   = 30 * time.Second
+)
+
+// Client talks to a flowd server.
+type Client struct {
+	baseURL    string
+	token      string
+	userAgent  string
+	httpClient *http.Client
+	// streamClient has no overall timeout so SSE streams can stay open.
+	streamClient *http.Client
+
+	Runs    *RunsService
+	Plans   *PlansService
+	Sources *SourcesService
+}
+
+// Option customises a Client.
+type Option func(*Client)
+
+// WithToken sets the bearer token sent with every request.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = strings.TrimSpace(token)
+	}
+}
+
+// WithHTTPClient replaces the HTTP client used for regular requests. Event
+// streams reuse its transport without the client's timeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			c.httpClient = hc
+		}
+	}
+}
+
+// WithUserAgent overrides the User-Agent header.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		if ua != "" {
+			c.userAgent = ua
+		}
+	}
+}
+
+// New returns a Client for the server at baseURL. A missing scheme defaults
+// to http.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    normalizeBaseURL(baseURL),
+		userAgent:  defaultUserAgent,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.streamClient = &http.Client{
+		Transport:     c.httpClient.Transport,
+		CheckRedirect: c.httpClient.CheckRedirect,
+		Jar:           c.httpClient.Jar,
+	}
+	c.Runs = &RunsService{client: c}
+	c.Plans = &PlansService{client: c}
+	c.Sources = &SourcesService{client: c}
+	return c
+}
+
+// BaseURL returns the normalised server URL.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// APIError is returned for non-2xx responses.
+type APIError struct {
+	StatusCode int
+	Type       string         `json:"type"`
+	Title      string         `json:"title"`
+	Detail     string         `json:"detail"`
+	Code       string         `json:"code"`
+	Extensions map[string]any `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	msg := e.Title
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return fmt.Sprintf("flowd API error %d: %s", e.StatusCode, msg)
+}
+
+// IsStatus reports whether err is an *APIError with the given status code.
+func IsStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body any) (*http.Request, error) {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set(HeaderAPIVersion, APIVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return req, nil
+}
+
+// do sends req and decodes a successful JSON response into out (when non-nil).
+func (c *Client) do(req *http.Request, out any) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, decodeAPIError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp, fmt.Errorf("decode response: %w", err)
+	}
+	return resp, nil
+}
+
+func decodeAPIError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if len(data) == 0 {
+		return apiErr
+	}
+	if err := json.Unmarshal(data, apiErr); err != nil {
+		apiErr.Detail = strings.TrimSpace(string(data))
+		return apiErr
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err == nil {
+		for _, known := range []string{"type", "title", "detail", "code", "status"} {
+			delete(raw, known)
+		}
+		if len(raw) > 0 {
+			apiErr.Extensions = raw
+		}
+	}
+	return apiErr
+}
+
+func normalizeBaseURL(raw string) string {
+	base := strings.TrimSpace(raw)
+	if base == "" {
+		return defaultBaseURL
+	}
+	if !strings.HasPrefix(base, "http://") && !strings.HasPrefix(base, "https://") {
+		base = "http://" + base
+	}
+	return strings.TrimRight(base, "/")
+}
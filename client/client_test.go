@@ -0,0 +1,225 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/testserve"
+)
+
+const testToken = "jobs:read,runs:read,runs:write,events:read,sources:read,sources:write"
+
+// startServe runs the real serve-mode server on a free loopback port.
+func startServe(t *testing.T) *Client {
+	t.Helper()
+	return New(testserve.Start(t, testToken, testserve.Greet), WithToken(testToken))
+}
+
+func TestClientRunLifecycleAgainstServe(t *testing.T) {
+	c := startServe(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	plan, err := c.Plans.Create(ctx, PlanRequest{JobID: "greet", Args: map[string]any{"name": "Ada"}})
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if plan.JobID != "greet" || plan.ResolvedArgs["name"] != "Ada" {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+
+	key, err := NewIdempotencyKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := c.Runs.Create(ctx, RunRequest{JobID: "greet", Args: map[string]any{"name": "Ada"}}, &CreateOptions{IdempotencyKey: key})
+	if err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	replay, err := c.Runs.Create(ctx, RunRequest{JobID: "greet", Args: map[string]any{"name": "Ada"}}, &CreateOptions{IdempotencyKey: key})
+	if err != nil {
+		t.Fatalf("replay run: %v", err)
+	}
+	if replay.ID != run.ID {
+		t.Fatalf("expected idempotent replay of %s, got %s", run.ID, replay.ID)
+	}
+
+	stream, err := c.Runs.Events(ctx, run.ID, nil)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	defer stream.Close()
+	var types []string
+	for stream.Next() {
+		types = append(types, stream.Event().Type)
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+	if len(types) == 0 || types[len(types)-1] != "run.finish" {
+		t.Fatalf("expected stream to end with run.finish, got %v", types)
+	}
+	if stream.LastEventID() == "" {
+		t.Fatal("expected last event id to be tracked")
+	}
+
+	got, err := c.Runs.Get(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("get run: %v", err)
+	}
+	if got.Status != "completed" || !got.Terminal() {
+		t.Fatalf("expected completed run, got %q", got.Status)
+	}
+	runs, err := c.Runs.List(ctx, &ListOptions{PerPage: 10})
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 run, got %d", len(runs))
+	}
+}
+
+func TestClientReturnsAPIErrors(t *testing.T) {
+	c := startServe(t)
+	ctx := context.Background()
+
+	_, err := c.Runs.Get(ctx, "missing")
+	if !IsStatus(err, http.StatusNotFound) {
+		t.Fatalf("expected 404 APIError, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Title == "" {
+		t.Fatalf("expected problem title, got %+v", err)
+	}
+
+	unauthorized := New(c.BaseURL())
+	if _, err := unauthorized.Sources.List(ctx); !IsStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("expected 401 without token, got %v", err)
+	}
+}
+
+func TestEventStreamResumesWithLastEventID(t *testing.T) {
+	var connections atomic.Int32
+	var resumedFrom atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch connections.Add(1) {
+		case 1:
+			fmt.Fprint(w, "retry: 10\n:connected\n\nid: 1\nevent: run.start\ndata: {}\n\nid: 2\nevent: step.log\ndata: line one\ndata: line two\n\n")
+		default:
+			resumedFrom.Store(r.Header.Get("Last-Event-ID"))
+			fmt.Fprint(w, "id: 3\nevent: run.finish\ndata: {}\n\n")
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+	stream, err := c.Runs.Events(context.Background(), "run-1", nil)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	var got []string
+	for stream.Next() {
+		ev := stream.Event()
+		got = append(got, ev.ID+":"+ev.Type)
+		if ev.Type == "step.log" && string(ev.Data) != "line one\nline two" {
+			t.Fatalf("unexpected multi-line data %q", ev.Data)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+	if strings.Join(got, ",") != "1:run.start,2:step.log,3:run.finish" {
+		t.Fatalf("unexpected events %v", got)
+	}
+	if resumedFrom.Load() != "2" {
+		t.Fatalf("expected reconnect with Last-Event-ID 2, got %v", resumedFrom.Load())
+	}
+}
+
+func TestEventStreamReportsExpiredCursor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusGone)
+		fmt.Fprint(w, `{"type":"https://flowd.dev/problems/cursor-expired","title":"cursor expired","status":410}`)
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL).Runs.Events(context.Background(), "run-1", &EventsOptions{LastEventID: "5"})
+	if !IsCursorExpired(err) {
+		t.Fatalf("expected cursor expired error, got %v", err)
+	}
+}
+
+func TestCreateRetriesWithSameIdempotencyKey(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":"run-1","job_id":"demo","status":"queued"}`)
+	}))
+	defer srv.Close()
+
+	run, err := New(srv.URL).Runs.Create(context.Background(), RunRequest{JobID: "demo"}, nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if run.ID != "run-1" {
+		t.Fatalf("unexpected run %+v", run)
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Fatalf("expected retry with the same generated key, got %v", keys)
+	}
+}
+
+func TestEventStreamFallsBackToPollingOnExpiredCursor(t *testing.T) {
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/events") {
+			w.WriteHeader(http.StatusGone)
+			fmt.Fprint(w, `{"title":"cursor expired","status":410}`)
+			return
+		}
+		status := "running"
+		if polls.Add(1) >= 3 {
+			status = "completed"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"run-1","job_id":"demo","status":%q}`, status)
+	}))
+	defer srv.Close()
+
+	stream, err := New(srv.URL).Runs.Events(context.Background(), "run-1", &EventsOptions{
+		LastEventID:         "5",
+		PollOnExpiredCursor: true,
+		PollInterval:        time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	var got []string
+	for stream.Next() {
+		got = append(got, stream.Event().Type)
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+	if !stream.Polling() {
+		t.Fatal("expected stream to report polling")
+	}
+	if strings.Join(got, ",") != "run.status,run.finish" {
+		t.Fatalf("unexpected events %v", got)
+	}
+}
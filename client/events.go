@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/flowd-org/flowd/client/sse"
+)
+
+const defaultPollInterval = 2 * time.Second
+
+// Event is one server-sent event.
+type Event = sse.Event
+
+// EventsOptions tunes an event stream.
+type EventsOptions struct {
+	// LastEventID resumes the stream after the given event.
+	LastEventID string
+	// MaxReconnects bounds consecutive reconnect attempts after the stream
+	// drops. Zero means five; a negative value disables reconnection.
+	MaxReconnects int
+	// ReconnectDelay is the wait before reconnecting. Zero uses the server's
+	// advertised retry interval, falling back to two seconds.
+	ReconnectDelay time.Duration
+	// PollOnExpiredCursor switches to polling GET /runs/{id} when the server
+	// answers 410 because LastEventID is no longer retained. Polling yields a
+	// run.status event per status change and ends with run.finish or
+	// run.canceled carrying the run as data.
+	PollOnExpiredCursor bool
+	// PollInterval is the polling period. Zero means two seconds.
+	PollInterval time.Duration
+}
+
+// EventStream iterates over a run's events. When the connection drops it
+// reconnects with Last-Event-ID so no retained event is lost or repeated.
+// The stream ends after run.finish or run.canceled.
+type EventStream struct {
+	runs   *RunsService
+	runID  string
+	ctx    context.Context
+	cancel context.CancelFunc
+	opts   EventsOptions
+
+	stream     *sse.Stream
+	polling    bool
+	lastStatus string
+
+	cur    Event
+	err    error
+	done   bool
+	ending bool
+}
+
+// Events opens the SSE stream for a run. Unless PollOnExpiredCursor is set,
+// a 410 response means the requested cursor is no longer retained; see
+// IsCursorExpired.
+func (s *RunsService) Events(ctx context.Context, runID string, opts *EventsOptions) (*EventStream, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	es := &EventStream{runs: s, runID: runID, ctx: streamCtx, cancel: cancel}
+	if opts != nil {
+		es.opts = *opts
+	}
+	stream, err := sse.Open(streamCtx, es.connect, sse.Options{
+		LastEventID:    es.opts.LastEventID,
+		MaxReconnects:  es.opts.MaxReconnects,
+		ReconnectDelay: es.opts.ReconnectDelay,
+		Retryable:      retryable,
+	})
+	switch {
+	case err == nil:
+		es.stream = stream
+	case es.opts.PollOnExpiredCursor && IsCursorExpired(err):
+		es.polling = true
+	default:
+		cancel()
+		return nil, err
+	}
+	return es, nil
+}
+
+// IsCursorExpired reports whether err is the server's 410 response for a
+// Last-Event-ID that has aged out of the journal.
+func IsCursorExpired(err error) bool {
+	return IsStatus(err, http.StatusGone)
+}
+
+// Next advances to the next event. It returns false when the run has ended,
+// the context is canceled or the stream cannot be resumed; check Err.
+func (es *EventStream) Next() bool {
+	if es.ending {
+		es.Close()
+	}
+	if es.done {
+		return false
+	}
+	if !es.polling {
+		if es.stream.Next() {
+			es.set(es.stream.Event())
+			return true
+		}
+		err := es.stream.Err()
+		if !es.opts.PollOnExpiredCursor || !IsCursorExpired(err) {
+			es.err = err
+			es.Close()
+			return false
+		}
+		es.polling = true
+	}
+	return es.poll()
+}
+
+// Event returns the current event.
+func (es *EventStream) Event() Event {
+	return es.cur
+}
+
+// LastEventID returns the ID of the last event received, suitable for
+// resuming later.
+func (es *EventStream) LastEventID() string {
+	if es.stream != nil {
+		return es.stream.LastEventID()
+	}
+	return es.opts.LastEventID
+}
+
+// Polling reports whether the stream fell back to polling the run.
+func (es *EventStream) Polling() bool {
+	return es.polling
+}
+
+// Err returns the error that stopped the stream, if any. A stream that ended
+// because the run finished or Close was called reports nil.
+func (es *EventStream) Err() error {
+	return es.err
+}
+
+// Close stops the stream.
+func (es *EventStream) Close() error {
+	es.done = true
+	es.cancel()
+	if es.stream != nil {
+		return es.stream.Close()
+	}
+	return nil
+}
+
+func (es *EventStream) set(ev Event) {
+	es.cur = ev
+	if ev.Type == "run.finish" || ev.Type == "run.canceled" {
+		es.ending = true
+	}
+}
+
+// poll fetches the run until its status changes and reports the change.
+func (es *EventStream) poll() bool {
+	interval := es.opts.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	for {
+		run, err := es.runs.Get(es.ctx, es.runID)
+		if err != nil {
+			if !retryable(err) || es.ctx.Err() != nil {
+				es.err = err
+				es.Close()
+				return false
+			}
+		} else if run.Status != es.lastStatus {
+			es.lastStatus = run.Status
+			data, _ := json.Marshal(run)
+			ev := Event{Type: "run.status", Data: data}
+			if run.Terminal() {
+				ev.Type = "run.finish"
+				if run.Status == "canceled" {
+					ev.Type = "run.canceled"
+				}
+			}
+			es.set(ev)
+			return true
+		}
+		select {
+		case <-es.ctx.Done():
+			if !es.done {
+				es.err = es.ctx.Err()
+			}
+			es.Close()
+			return false
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (es *EventStream) connect(ctx context.Context, lastEventID string) (io.ReadCloser, error) {
+	path := "/runs/" + url.PathEscape(es.runID) + "/events"
+	req, err := es.runs.client.newRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := es.runs.client.streamClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, decodeAPIError(resp)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Command tsgen generates the TypeScript client in client/ts from the Go
+// client's request and response types, so the web UI and the CLI share one
+// definition of the API payloads. Run it with go generate in ./client.
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/client"
+)
+
+//go:embed runtime.ts
+var runtime string
+
+const header = "// SPDX-License-Identifier: AGPL-3.0-or-later\n" +
+	"// Code generated by client/internal/tsgen from the Go client types. DO NOT EDIT.\n"
+
+// apiTypes are the payloads exported to TypeScript, in output order.
+var apiTypes = []any{
+	client.Run{},
+	client.RunnerInfo{},
+	client.ArgWarning{},
+	client.SourceRef{},
+	client.RunRequest{},
+	client.BatchResult{},
+	client.PlanRequest{},
+	client.Plan{},
+	client.Source{},
+	client.TrustChange{},
+	client.Quarantine{},
+	client.SourceQuarantine{},
+	client.SourceRequest{},
+}
+
+func main() {
+	out := flag.String("out", "ts/src/flowd.ts", "Output file")
+	flag.Parse()
+	src, err := generate()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "tsgen:", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "tsgen:", err)
+		os.Exit(1)
+	}
+}
+
+func generate() ([]byte, error) {
+	known := map[reflect.Type]bool{}
+	for _, v := range apiTypes {
+		known[reflect.TypeOf(v)] = true
+	}
+	var b bytes.Buffer
+	b.WriteString(header)
+	for _, v := range apiTypes {
+		if err := writeInterface(&b, reflect.TypeOf(v), known); err != nil {
+			return nil, err
+		}
+	}
+	// The runtime carries its own license header for the SPDX check.
+	_, body, _ := strings.Cut(runtime, "\n")
+	b.WriteString(body)
+	return b.Bytes(), nil
+}
+
+func writeInterface(b *bytes.Buffer, t reflect.Type, known map[reflect.Type]bool) error {
+	fmt.Fprintf(b, "\nexport interface %s {\n", t.Name())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		ts, err := tsType(field.Type, known)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+		optional := ""
+		if strings.Contains(opts, "omitempty") {
+			optional = "?"
+		} else if field.Type.Kind() == reflect.Pointer {
+			ts += " | null"
+		}
+		fmt.Fprintf(b, "  %s%s: %s;\n", name, optional, ts)
+	}
+	b.WriteString("}\n")
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func tsType(t reflect.Type, known map[reflect.Type]bool) (string, error) {
+	if t == timeType {
+		return "string", nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string", nil
+	case reflect.Bool:
+		return "boolean", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number", nil
+	case reflect.Interface:
+		return "unknown", nil
+	case reflect.Pointer:
+		return tsType(t.Elem(), known)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "unknown", nil // json.RawMessage
+		}
+		elem, err := tsType(t.Elem(), known)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(elem, " | ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]", nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return "", fmt.Errorf("unsupported map key %s", t.Key())
+		}
+		elem, err := tsType(t.Elem(), known)
+		if err != nil {
+			return "", err
+		}
+		return "Record<string, " + elem + ">", nil
+	case reflect.Struct:
+		if known[t] {
+			return t.Name(), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type %s", t)
+}
@@ -0,0 +1,23 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestGeneratedClientUpToDate(t *testing.T) {
+	want, err := generate()
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	got, err := os.ReadFile("../../ts/src/flowd.ts")
+	if err != nil {
+		t.Fatalf("read generated client: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("client/ts/src/flowd.ts is stale; run go generate ./client")
+	}
+}
@@ -0,0 +1,253 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+/** RFC 7807 problem returned for non-2xx responses. */
+export interface Problem {
+  type?: string;
+  title?: string;
+  status?: number;
+  detail?: string;
+  code?: string;
+  [extension: string]: unknown;
+}
+
+/** Error thrown for non-2xx responses, carrying the problem body. */
+export class FlowdError extends Error {
+  readonly status: number;
+  readonly problem: Problem;
+
+  constructor(status: number, problem: Problem) {
+    const title = problem.title || `HTTP ${status}`;
+    super(`flowd API error ${status}: ${problem.detail ? `${title}: ${problem.detail}` : title}`);
+    this.name = "FlowdError";
+    this.status = status;
+    this.problem = problem;
+  }
+}
+
+/** A server-sent event from GET /runs/{id}/events. */
+export interface RunEvent {
+  id: string;
+  type: string;
+  data: string;
+}
+
+export interface ClientOptions {
+  token?: string;
+  fetch?: typeof fetch;
+}
+
+export interface CreateOptions {
+  /** Sent as Idempotency-Key; generated when omitted. */
+  idempotencyKey?: string;
+}
+
+export interface ListOptions {
+  page?: number;
+  perPage?: number;
+  fields?: string[];
+}
+
+export interface GetOptions {
+  fields?: string[];
+  /** Long-poll until the run is terminal, e.g. "30s". */
+  wait?: string;
+}
+
+export interface EventsOptions {
+  lastEventId?: string;
+  signal?: AbortSignal;
+  /** Consecutive reconnects after the stream drops; default 5. */
+  maxReconnects?: number;
+  reconnectDelayMs?: number;
+}
+
+type Query = Record<string, string | undefined>;
+
+/** Client for the flowd serve-mode REST and SSE API. */
+export class FlowdClient {
+  readonly baseUrl: string;
+  private readonly token?: string;
+  private readonly fetchFn: typeof fetch;
+
+  constructor(baseUrl: string, options: ClientOptions = {}) {
+    this.baseUrl = baseUrl.replace(/\/+$/, "");
+    this.token = options.token;
+    this.fetchFn = options.fetch ?? fetch.bind(globalThis);
+  }
+
+  readonly runs = {
+    create: (req: RunRequest, opts: CreateOptions = {}): Promise<Run> =>
+      this.request("POST", "/runs", { body: req, idempotencyKey: opts.idempotencyKey ?? newIdempotencyKey() }),
+    batch: (reqs: RunRequest[], opts: CreateOptions = {}): Promise<BatchResult[]> =>
+      this.request<{ results: BatchResult[] }>("POST", "/runs:batch", {
+        body: { runs: reqs },
+        idempotencyKey: opts.idempotencyKey ?? newIdempotencyKey(),
+      }).then((out) => out.results),
+    get: (id: string, opts: GetOptions = {}): Promise<Run> =>
+      this.request("GET", `/runs/${encodeURIComponent(id)}`, {
+        query: { fields: opts.fields?.join(","), wait: opts.wait, until: opts.wait ? "terminal" : undefined },
+      }),
+    list: (opts: ListOptions = {}): Promise<Run[]> =>
+      this.request("GET", "/runs", {
+        query: { page: opts.page?.toString(), per_page: opts.perPage?.toString(), fields: opts.fields?.join(",") },
+      }),
+    cancel: (id: string): Promise<Run> => this.request("POST", `/runs/${encodeURIComponent(id)}:cancel`),
+    provenance: (id: string, format?: string): Promise<unknown> =>
+      this.request("GET", `/runs/${encodeURIComponent(id)}/provenance`, { query: { format } }),
+    events: (id: string, opts: EventsOptions = {}): AsyncGenerator<RunEvent> => this.streamEvents(id, opts),
+  };
+
+  readonly plans = {
+    create: (req: PlanRequest): Promise<Plan> => this.request("POST", "/plans", { body: req }),
+  };
+
+  readonly sources = {
+    list: (): Promise<Source[]> => this.request("GET", "/sources"),
+    get: (name: string): Promise<Source> => this.request("GET", `/sources/${encodeURIComponent(name)}`),
+    add: (req: SourceRequest): Promise<Source> => this.request("POST", "/sources", { body: req }),
+    delete: (name: string): Promise<void> => this.request("DELETE", `/sources/${encodeURIComponent(name)}`),
+  };
+
+  private headers(accept: string): Record<string, string> {
+    const headers: Record<string, string> = { Accept: accept };
+    if (this.token) {
+      headers.Authorization = `Bearer ${this.token}`;
+    }
+    return headers;
+  }
+
+  private async request<T>(
+    method: string,
+    path: string,
+    opts: { body?: unknown; query?: Query; idempotencyKey?: string } = {},
+  ): Promise<T> {
+    const url = new URL(this.baseUrl + path);
+    for (const [key, value] of Object.entries(opts.query ?? {})) {
+      if (value) {
+        url.searchParams.set(key, value);
+      }
+    }
+    const headers = this.headers("application/json");
+    if (opts.body !== undefined) {
+      headers["Content-Type"] = "application/json";
+    }
+    if (opts.idempotencyKey) {
+      headers["Idempotency-Key"] = opts.idempotencyKey;
+    }
+    const resp = await this.fetchFn(url, {
+      method,
+      headers,
+      body: opts.body === undefined ? undefined : JSON.stringify(opts.body),
+    });
+    if (!resp.ok) {
+      throw await problemError(resp);
+    }
+    if (resp.status === 204) {
+      return undefined as T;
+    }
+    return (await resp.json()) as T;
+  }
+
+  /** Streams a run's events, resuming with Last-Event-ID after drops. */
+  private async *streamEvents(id: string, opts: EventsOptions): AsyncGenerator<RunEvent> {
+    let lastEventId = opts.lastEventId ?? "";
+    const maxReconnects = opts.maxReconnects ?? 5;
+    const delay = opts.reconnectDelayMs ?? 2000;
+    let reconnects = 0;
+    for (;;) {
+      const headers = this.headers("text/event-stream");
+      if (lastEventId) {
+        headers["Last-Event-ID"] = lastEventId;
+      }
+      try {
+        const resp = await this.fetchFn(`${this.baseUrl}/runs/${encodeURIComponent(id)}/events`, {
+          headers,
+          signal: opts.signal,
+        });
+        if (!resp.ok) {
+          throw await problemError(resp);
+        }
+        for await (const ev of parseEvents(resp.body)) {
+          reconnects = 0;
+          if (ev.id) {
+            lastEventId = ev.id;
+          }
+          yield ev;
+          if (ev.type === "run.finish" || ev.type === "run.canceled") {
+            return;
+          }
+        }
+      } catch (err) {
+        if (err instanceof FlowdError || opts.signal?.aborted) {
+          throw err;
+        }
+      }
+      if (reconnects >= maxReconnects) {
+        return;
+      }
+      reconnects++;
+      await new Promise((resolve) => setTimeout(resolve, delay));
+    }
+  }
+}
+
+async function problemError(resp: Response): Promise<FlowdError> {
+  const text = await resp.text();
+  let problem: Problem = {};
+  try {
+    problem = JSON.parse(text) as Problem;
+  } catch {
+    problem = { detail: text.trim() };
+  }
+  return new FlowdError(resp.status, problem);
+}
+
+async function* parseEvents(body: ReadableStream<Uint8Array> | null): AsyncGenerator<RunEvent> {
+  if (!body) {
+    return;
+  }
+  const reader = body.pipeThrough(new TextDecoderStream()).getReader();
+  let buffer = "";
+  let ev: RunEvent = { id: "", type: "message", data: "" };
+  let data: string[] = [];
+  for (;;) {
+    const { value, done } = await reader.read();
+    if (done) {
+      return;
+    }
+    buffer += value;
+    let newline: number;
+    while ((newline = buffer.indexOf("\n")) >= 0) {
+      const line = buffer.slice(0, newline).replace(/\r$/, "");
+      buffer = buffer.slice(newline + 1);
+      if (line === "") {
+        if (data.length > 0) {
+          yield { ...ev, data: data.join("\n") };
+        }
+        ev = { id: ev.id, type: "message", data: "" };
+        data = [];
+        continue;
+      }
+      if (line.startsWith(":")) {
+        continue;
+      }
+      const colon = line.indexOf(":");
+      const field = colon < 0 ? line : line.slice(0, colon);
+      const value = colon < 0 ? "" : line.slice(colon + 1).replace(/^ /, "");
+      if (field === "id") {
+        ev.id = value;
+      } else if (field === "event") {
+        ev.type = value;
+      } else if (field === "data") {
+        data.push(value);
+      }
+    }
+  }
+}
+
+/** Returns a random key accepted by the server's Idempotency-Key check. */
+export function newIdempotencyKey(): string {
+  const bytes = new Uint8Array(16);
+  crypto.getRandomValues(bytes);
+  return Array.from(bytes, (b) => b.toString(16).padStart(2, "0")).join("");
+}
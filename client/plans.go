@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// PlanRequest is the body of POST /plans.
+type PlanRequest struct {
+	JobID                    string         `json:"job_id"`
+	Args                     map[string]any `json:"args,omitempty"`
+	RequestedSecurityProfile string         `json:"requested_security_profile,omitempty"`
+	Source                   *SourceRef     `json:"source,omitempty"`
+	// Overlay merges config.d/overlays/<overlay>.yaml over the job config.
+	Overlay string `json:"overlay,omitempty"`
+}
+
+// Plan is the server's execution preview for a job. Nested sections that
+// evolve with the spec are kept as generic JSON values.
+type Plan struct {
+	JobID            string           `json:"job_id"`
+	EffectiveArgSpec map[string]any   `json:"effective_argspec"`
+	ExecutorPreview  map[string]any   `json:"executor_preview,omitempty"`
+	Requirements     map[string]any   `json:"requirements,omitempty"`
+	ResolvedArgs     map[string]any   `json:"resolved_args,omitempty"`
+	SecurityProfile  string           `json:"security_profile,omitempty"`
+	PolicyFindings   []map[string]any `json:"policy_findings,omitempty"`
+	ImageTrust       map[string]any   `json:"image_trust,omitempty"`
+	Steps            []map[string]any `json:"steps,omitempty"`
+	Provenance       map[string]any   `json:"provenance,omitempty"`
+	Warnings         []ArgWarning     `json:"warnings,omitempty"`
+}
+
+// PlansService wraps POST /plans.
+type PlansService struct {
+	client *Client
+}
+
+// Create resolves a plan without starting a run.
+func (s *PlansService) Create(ctx context.Context, req PlanRequest) (*Plan, error) {
+	httpReq, err := s.client.newRequest(ctx, http.MethodPost, "/plans", nil, req)
+	if err != nil {
+		return nil, err
+	}
+	var out Plan
+	if _, err := s.client.do(httpReq, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
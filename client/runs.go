@@ -0,0 +1,289 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultCreateAttempts = 3
+	createRetryBackoff    = 250 * time.Millisecond
+)
+
+// Run is a run as reported by the server.
+type Run struct {
+	ID              string         `json:"id"`
+	JobID           string         `json:"job_id"`
+	Status          string         `json:"status"`
+	StartedAt       time.Time      `json:"started_at"`
+	FinishedAt      *time.Time     `json:"finished_at,omitempty"`
+	Result          map[string]any `json:"result,omitempty"`
+	Executor        string         `json:"executor,omitempty"`
+	Runtime         string         `json:"runtime,omitempty"`
+	SecurityProfile string         `json:"security_profile,omitempty"`
+	Provenance      map[string]any `json:"provenance,omitempty"`
+	// Shutdown is "graceful" or "forced" for canceled runs.
+	Shutdown string `json:"shutdown,omitempty"`
+	// Runner describes the flowd build and host that executed the run.
+	Runner *RunnerInfo `json:"runner,omitempty"`
+	// Labels are the tags supplied when the run was created.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Warnings lists arg rules marked severity: warning that the args broke.
+	Warnings []ArgWarning `json:"warnings,omitempty"`
+}
+
+// ArgWarning reports a non-fatal argument validation finding, such as a
+// deprecated arg.
+type ArgWarning struct {
+	Arg     string `json:"arg"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// RunnerInfo identifies the flowd build, host and container runtime that
+// executed a run.
+type RunnerInfo struct {
+	Version                 string `json:"version"`
+	OS                      string `json:"os"`
+	Arch                    string `json:"arch"`
+	Hostname                string `json:"hostname,omitempty"`
+	ContainerRuntime        string `json:"container_runtime,omitempty"`
+	ContainerRuntimeVersion string `json:"container_runtime_version,omitempty"`
+}
+
+// Terminal reports whether the run has reached a final status.
+func (r Run) Terminal() bool {
+	switch r.Status {
+	case "completed", "failed", "canceled", "timed_out":
+		return true
+	}
+	return false
+}
+
+// SourceRef selects a registered source for a plan or run.
+type SourceRef struct {
+	Name string `json:"name"`
+}
+
+// RunRequest is the body of POST /runs.
+type RunRequest struct {
+	JobID                    string         `json:"job_id"`
+	Args                     map[string]any `json:"args,omitempty"`
+	RequestedSecurityProfile string         `json:"requested_security_profile,omitempty"`
+	Source                   *SourceRef     `json:"source,omitempty"`
+	// Labels tag the run for filtering with GET /runs?label=key:value.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Overlay merges config.d/overlays/<overlay>.yaml over the job config.
+	Overlay string `json:"overlay,omitempty"`
+}
+
+// CreateOptions tunes run submission.
+type CreateOptions struct {
+	// IdempotencyKey is sent as the Idempotency-Key header. When empty a
+	// random key is generated; it is reused for every retry of the call.
+	IdempotencyKey string
+	// MaxAttempts bounds retries after network errors and 502/503/504
+	// responses. Zero means three attempts.
+	MaxAttempts int
+}
+
+// ListOptions paginates GET /runs.
+type ListOptions struct {
+	Page    int
+	PerPage int
+}
+
+// BatchResult reports the outcome of one item of a batch submission.
+type BatchResult struct {
+	Index  int            `json:"index"`
+	Status int            `json:"status"`
+	Run    *Run           `json:"run,omitempty"`
+	Error  map[string]any `json:"error,omitempty"`
+}
+
+// RunsService wraps the /runs endpoints.
+type RunsService struct {
+	client *Client
+}
+
+// Create submits a run. Transient failures are retried with the same
+// idempotency key, so a retried submission never starts a second run.
+func (s *RunsService) Create(ctx context.Context, req RunRequest, opts *CreateOptions) (*Run, error) {
+	var out Run
+	if err := s.submit(ctx, "/runs", req, opts, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Batch submits several runs atomically through POST /runs:batch.
+func (s *RunsService) Batch(ctx context.Context, reqs []RunRequest, opts *CreateOptions) ([]BatchResult, error) {
+	var out struct {
+		Results []BatchResult `json:"results"`
+	}
+	body := struct {
+		Runs []RunRequest `json:"runs"`
+	}{Runs: reqs}
+	if err := s.submit(ctx, "/runs:batch", body, opts, &out); err != nil {
+		return nil, err
+	}
+	return out.Results, nil
+}
+
+func (s *RunsService) submit(ctx context.Context, path string, body any, opts *CreateOptions, out any) error {
+	key := ""
+	attempts := defaultCreateAttempts
+	if opts != nil {
+		key = opts.IdempotencyKey
+		if opts.MaxAttempts > 0 {
+			attempts = opts.MaxAttempts
+		}
+	}
+	if key == "" {
+		generated, err := NewIdempotencyKey()
+		if err != nil {
+			return err
+		}
+		key = generated
+	}
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		req, err := s.client.newRequest(ctx, http.MethodPost, path, nil, body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Idempotency-Key", key)
+		_, err = s.client.do(req, out)
+		if err == nil || !retryable(err) {
+			return err
+		}
+		lastErr = err
+		if attempt < attempts {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * createRetryBackoff):
+			}
+		}
+	}
+	return lastErr
+}
+
+// Get fetches a run by ID.
+func (s *RunsService) Get(ctx context.Context, id string) (*Run, error) {
+	req, err := s.client.newRequest(ctx, http.MethodGet, "/runs/"+url.PathEscape(id), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out Run
+	if _, err := s.client.do(req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// List returns a page of runs.
+func (s *RunsService) List(ctx context.Context, opts *ListOptions) ([]Run, error) {
+	query := url.Values{}
+	if opts != nil {
+		if opts.Page > 0 {
+			query.Set("page", strconv.Itoa(opts.Page))
+		}
+		if opts.PerPage > 0 {
+			query.Set("per_page", strconv.Itoa(opts.PerPage))
+		}
+	}
+	req, err := s.client.newRequest(ctx, http.MethodGet, "/runs", query, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out []Run
+	if _, err := s.client.do(req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Cancel requests cancellation of a run and returns its updated state.
+func (s *RunsService) Cancel(ctx context.Context, id string) (*Run, error) {
+	req, err := s.client.newRequest(ctx, http.MethodPost, "/runs/"+url.PathEscape(id)+":cancel", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out Run
+	if _, err := s.client.do(req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Provenance returns the run's provenance document. An empty format returns
+// the stored provenance map; "intoto" returns an in-toto Statement.
+func (s *RunsService) Provenance(ctx context.Context, id, format string) (json.RawMessage, error) {
+	query := url.Values{}
+	if format != "" {
+		query.Set("format", format)
+	}
+	req, err := s.client.newRequest(ctx, http.MethodGet, "/runs/"+url.PathEscape(id)+"/provenance", query, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out json.RawMessage
+	if _, err := s.client.do(req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExportEvents returns the run's journaled events as NDJSON. Servers without
+// the export extension answer 404.
+func (s *RunsService) ExportEvents(ctx context.Context, id string) ([]byte, error) {
+	req, err := s.client.newRequest(ctx, http.MethodGet, "/runs/"+url.PathEscape(id)+"/events.ndjson", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/x-ndjson")
+	resp, err := s.client.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, decodeAPIError(resp)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// NewIdempotencyKey returns a random key accepted by the server.
+func NewIdempotencyKey() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	return true
+}
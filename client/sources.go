@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Source is a registered job source.
+type Source struct {
+	Name             string           `json:"name"`
+	Type             string           `json:"type"`
+	Ref              string           `json:"ref,omitempty"`
+	ResolvedRef      string           `json:"resolved_ref,omitempty"`
+	ResolvedCommit   string           `json:"resolved_commit,omitempty"`
+	URL              string           `json:"url,omitempty"`
+	Trust            map[string]any   `json:"trust,omitempty"`
+	Aliases          []map[string]any `json:"aliases,omitempty"`
+	Metadata         map[string]any   `json:"metadata,omitempty"`
+	Digest           string           `json:"digest,omitempty"`
+	PullPolicy       string           `json:"pull_policy,omitempty"`
+	VerifySignatures bool             `json:"verify_signatures,omitempty"`
+	Provenance       map[string]any   `json:"provenance,omitempty"`
+	Expose           string           `json:"expose,omitempty"`
+	// TrustLevel is untrusted, limited or trusted.
+	TrustLevel   string        `json:"trust_level,omitempty"`
+	TrustHistory []TrustChange `json:"trust_history,omitempty"`
+	// Quarantine is set while the source fails scheduled re-verification or
+	// after it was quarantined through Quarantine.
+	Quarantine *Quarantine `json:"quarantine,omitempty"`
+	VerifiedAt *time.Time  `json:"verified_at,omitempty"`
+}
+
+// Quarantine explains why a source refuses new runs.
+type Quarantine struct {
+	Code   string    `json:"code"`
+	Reason string    `json:"reason"`
+	Since  time.Time `json:"since"`
+	// By is the principal that quarantined the source; code is "manual".
+	By string `json:"by,omitempty"`
+}
+
+// SourceQuarantine is the outcome of quarantining a source.
+type SourceQuarantine struct {
+	Source Source `json:"source"`
+	// AffectedRuns are the runs from the source whose provenance records the
+	// quarantine; CanceledRuns are the in-flight ones that were canceled.
+	AffectedRuns []string `json:"affected_runs"`
+	CanceledRuns []string `json:"canceled_runs"`
+}
+
+// TrustChange is one audited transition of a source's trust level.
+type TrustChange struct {
+	At     time.Time `json:"at"`
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Actor  string    `json:"actor,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// SourceRequest is the body of POST /sources.
+type SourceRequest struct {
+	Name             string         `json:"name"`
+	Type             string         `json:"type"`
+	Ref              string         `json:"ref,omitempty"`
+	URL              string         `json:"url,omitempty"`
+	Trusted          bool           `json:"trusted,omitempty"`
+	PullPolicy       string         `json:"pull_policy,omitempty"`
+	Trust            map[string]any `json:"trust,omitempty"`
+	Expose           string         `json:"expose,omitempty"`
+	VerifySignatures bool           `json:"verify_signatures,omitempty"`
+	TrustLevel       string         `json:"trust_level,omitempty"`
+	// AliasConflict is refuse (the default) or prefix and decides what
+	// happens to add-on aliases that collide with existing names.
+	AliasConflict string `json:"alias_conflict,omitempty"`
+}
+
+// SourcesService wraps the /sources endpoints.
+type SourcesService struct {
+	client *Client
+}
+
+// List returns all registered sources.
+func (s *SourcesService) List(ctx context.Context) ([]Source, error) {
+	req, err := s.client.newRequest(ctx, http.MethodGet, "/sources", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out []Source
+	if _, err := s.client.do(req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Get fetches a source by name.
+func (s *SourcesService) Get(ctx context.Context, name string) (*Source, error) {
+	req, err := s.client.newRequest(ctx, http.MethodGet, "/sources/"+url.PathEscape(name), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out Source
+	if _, err := s.client.do(req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Add registers or updates a source.
+func (s *SourcesService) Add(ctx context.Context, src SourceRequest) (*Source, error) {
+	req, err := s.client.newRequest(ctx, http.MethodPost, "/sources", nil, src)
+	if err != nil {
+		return nil, err
+	}
+	var out Source
+	if _, err := s.client.do(req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetTrustLevel moves a source to level (untrusted, limited or trusted). The
+// reason is kept in the source's trust history. Requires sources:trust.
+func (s *SourcesService) SetTrustLevel(ctx context.Context, name, level, reason string) (*Source, error) {
+	body := map[string]string{"trust_level": level, "reason": reason}
+	req, err := s.client.newRequest(ctx, http.MethodPost, "/sources/"+url.PathEscape(name)+":trust", nil, body)
+	if err != nil {
+		return nil, err
+	}
+	var out Source
+	if _, err := s.client.do(req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Quarantine refuses new runs and plans from a source at once and annotates
+// the provenance of its runs. With cancelRuns its queued and running runs are
+// also canceled. Requires sources:trust.
+func (s *SourcesService) Quarantine(ctx context.Context, name, reason string, cancelRuns bool) (*SourceQuarantine, error) {
+	body := map[string]any{"reason": reason, "cancel_runs": cancelRuns}
+	req, err := s.client.newRequest(ctx, http.MethodPost, "/sources/"+url.PathEscape(name)+":quarantine", nil, body)
+	if err != nil {
+		return nil, err
+	}
+	var out SourceQuarantine
+	if _, err := s.client.do(req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Release lifts a source's quarantine, whether set by hand or by
+// re-verification. Requires sources:trust.
+func (s *SourcesService) Release(ctx context.Context, name, reason string) (*Source, error) {
+	body := map[string]string{"reason": reason}
+	req, err := s.client.newRequest(ctx, http.MethodPost, "/sources/"+url.PathEscape(name)+":release", nil, body)
+	if err != nil {
+		return nil, err
+	}
+	var out Source
+	if _, err := s.client.do(req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Delete removes a source.
+func (s *SourcesService) Delete(ctx context.Context, name string) error {
+	req, err := s.client.newRequest(ctx, http.MethodDelete, "/sources/"+url.PathEscape(name), nil, nil)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.do(req, nil)
+	return err
+}
@@ -0,0 +1,234 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package sse reads Server-Sent Event streams and transparently resumes them
+// with Last-Event-ID when the connection drops. It is shared by the Go client
+// and the flwd CLI.
+package sse
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxReconnects  = 5
+	defaultReconnectDelay = 2 * time.Second
+)
+
+// Event is one dispatched server-sent event.
+type Event struct {
+	ID   string
+	Type string
+	Data []byte
+}
+
+// ConnectFunc opens the event stream, resuming after lastEventID when it is
+// non-empty. Implementations return an error for non-2xx responses.
+type ConnectFunc func(ctx context.Context, lastEventID string) (io.ReadCloser, error)
+
+// Options tunes a Stream.
+type Options struct {
+	// LastEventID resumes the first connection after the given event.
+	LastEventID string
+	// MaxReconnects bounds consecutive reconnect attempts after the stream
+	// drops. Zero means five; a negative value disables reconnection.
+	MaxReconnects int
+	// ReconnectDelay is the wait before reconnecting. Zero uses the server's
+	// advertised retry interval, falling back to two seconds.
+	ReconnectDelay time.Duration
+	// Retryable reports whether a failed reconnect should be attempted
+	// again. Nil retries every error.
+	Retryable func(error) bool
+}
+
+// Stream iterates over events, reconnecting as needed.
+type Stream struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	connect ConnectFunc
+	opts    Options
+
+	lastID      string
+	serverRetry time.Duration
+
+	body   io.ReadCloser
+	reader *bufio.Reader
+	cur    Event
+	err    error
+	done   bool
+}
+
+// Open makes the first connection and returns the stream. Errors from the
+// first connection are returned as-is so callers can inspect them.
+func Open(ctx context.Context, connect ConnectFunc, opts Options) (*Stream, error) {
+	if opts.MaxReconnects == 0 {
+		opts.MaxReconnects = defaultMaxReconnects
+	}
+	streamCtx, cancel := context.WithCancel(ctx)
+	s := &Stream{
+		ctx:     streamCtx,
+		cancel:  cancel,
+		connect: connect,
+		opts:    opts,
+		lastID:  opts.LastEventID,
+	}
+	if err := s.dial(); err != nil {
+		cancel()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Next advances to the next event. It returns false once the stream is
+// closed, the context is canceled or the stream cannot be resumed; check Err.
+func (s *Stream) Next() bool {
+	for !s.done {
+		ev, err := s.read()
+		if err == nil {
+			s.cur = ev
+			return true
+		}
+		if s.ctx.Err() != nil {
+			s.fail(s.ctx.Err())
+			break
+		}
+		if err := s.reconnect(err); err != nil {
+			s.fail(err)
+		}
+	}
+	return false
+}
+
+// Event returns the current event.
+func (s *Stream) Event() Event {
+	return s.cur
+}
+
+// LastEventID returns the ID of the last event received.
+func (s *Stream) LastEventID() string {
+	return s.lastID
+}
+
+// Err returns the error that stopped the stream. Streams stopped by Close
+// report nil.
+func (s *Stream) Err() error {
+	return s.err
+}
+
+// Close stops the stream.
+func (s *Stream) Close() error {
+	s.done = true
+	s.cancel()
+	if s.body != nil {
+		err := s.body.Close()
+		s.body = nil
+		return err
+	}
+	return nil
+}
+
+func (s *Stream) fail(err error) {
+	if !s.done {
+		s.err = err
+	}
+	s.Close()
+}
+
+func (s *Stream) dial() error {
+	body, err := s.connect(s.ctx, s.lastID)
+	if err != nil {
+		return err
+	}
+	s.body = body
+	s.reader = bufio.NewReader(body)
+	return nil
+}
+
+func (s *Stream) reconnect(cause error) error {
+	if s.body != nil {
+		_ = s.body.Close()
+		s.body = nil
+	}
+	if s.opts.MaxReconnects < 0 {
+		return fmt.Errorf("event stream closed: %w", cause)
+	}
+	lastErr := cause
+	for attempt := 1; attempt <= s.opts.MaxReconnects; attempt++ {
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-time.After(s.reconnectDelay()):
+		}
+		err := s.dial()
+		if err == nil {
+			return nil
+		}
+		if s.opts.Retryable != nil && !s.opts.Retryable(err) {
+			return err
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("event stream lost after %d reconnect attempts: %w", s.opts.MaxReconnects, lastErr)
+}
+
+func (s *Stream) reconnectDelay() time.Duration {
+	if s.opts.ReconnectDelay > 0 {
+		return s.opts.ReconnectDelay
+	}
+	if s.serverRetry > 0 {
+		return s.serverRetry
+	}
+	return defaultReconnectDelay
+}
+
+// read parses the next dispatched event, skipping comments and keep-alives.
+func (s *Stream) read() (Event, error) {
+	if s.reader == nil {
+		return Event{}, io.ErrUnexpectedEOF
+	}
+	var (
+		ev      Event
+		data    []string
+		hasData bool
+	)
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return Event{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if ev.Type == "" && !hasData {
+				continue
+			}
+			ev.Data = []byte(strings.Join(data, "\n"))
+			if ev.ID != "" {
+				s.lastID = ev.ID
+			}
+			return ev, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			ev.ID = value
+		case "event":
+			ev.Type = value
+		case "data":
+			data = append(data, value)
+			hasData = true
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms > 0 {
+				s.serverRetry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStreamParsesAndResumes(t *testing.T) {
+	bodies := []string{
+		"retry: 5\n:connected\n\nid: 1\nevent: run.start\ndata: {}\n\nid: 2\nevent: step.log\ndata: a\ndata: b\n\n",
+		"id: 3\nevent: run.finish\ndata: {}\n\n",
+	}
+	var resumed []string
+	connect := func(ctx context.Context, lastEventID string) (io.ReadCloser, error) {
+		resumed = append(resumed, lastEventID)
+		if len(resumed) > len(bodies) {
+			return nil, errors.New("gone")
+		}
+		return io.NopCloser(strings.NewReader(bodies[len(resumed)-1])), nil
+	}
+	s, err := Open(context.Background(), connect, Options{MaxReconnects: 1})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	var got []string
+	for s.Next() {
+		ev := s.Event()
+		got = append(got, ev.ID+"/"+ev.Type+"/"+string(ev.Data))
+	}
+	want := "1/run.start/{},2/step.log/a\nb,3/run.finish/{}"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("unexpected events %q", got)
+	}
+	if strings.Join(resumed, ",") != ",2,3" {
+		t.Fatalf("unexpected resume cursors %q", resumed)
+	}
+	if err := s.Err(); err == nil || !strings.Contains(err.Error(), "gone") {
+		t.Fatalf("expected reconnect failure, got %v", err)
+	}
+}
+
+func TestStreamStopsOnNonRetryableError(t *testing.T) {
+	fatal := errors.New("cursor expired")
+	calls := 0
+	connect := func(ctx context.Context, lastEventID string) (io.ReadCloser, error) {
+		calls++
+		if calls == 1 {
+			return io.NopCloser(strings.NewReader("id: 7\nevent: step.log\ndata: x\n\n")), nil
+		}
+		return nil, fatal
+	}
+	s, err := Open(context.Background(), connect, Options{
+		ReconnectDelay: 1,
+		Retryable:      func(err error) bool { return !errors.Is(err, fatal) },
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for s.Next() {
+	}
+	if !errors.Is(s.Err(), fatal) {
+		t.Fatalf("expected non-retryable error, got %v", s.Err())
+	}
+	if calls != 2 {
+		t.Fatalf("expected a single reconnect attempt, got %d", calls-1)
+	}
+	if s.LastEventID() != "7" {
+		t.Fatalf("expected last event id 7, got %q", s.LastEventID())
+	}
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// APIVersion is the API specification version this client implements. It is
+// sent with every request and compared with the server's spec_version.
+const APIVersion = "1.2.0"
+
+// Version negotiation headers. Requests carry HeaderAPIVersion; responses
+// carry the server's HeaderAPIVersion and HeaderServerVersion.
+const (
+	HeaderAPIVersion    = "X-Flowd-API-Version"
+	HeaderServerVersion = "X-Flowd-Version"
+)
+
+// Capabilities is the response of GET /capabilities.
+type Capabilities struct {
+	Version         string          `json:"version"`
+	SpecVersion     string          `json:"spec_version,omitempty"`
+	SecurityProfile string          `json:"security_profile"`
+	PolicyVersion   string          `json:"policy_version"`
+	Features        map[string]bool `json:"features"`
+}
+
+// Capabilities fetches what the server supports.
+func (c *Client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/capabilities", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out Capabilities
+	if _, err := c.do(req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Compatibility classifies the skew between client and server API versions.
+type Compatibility string
+
+const (
+	// Compatible means the server implements every API this client uses.
+	Compatible Compatibility = "compatible"
+	// CompatibilityServerOlder means the server lags by a minor version, so
+	// endpoints or fields added since may be missing.
+	CompatibilityServerOlder Compatibility = "server-older"
+	// CompatibilityUnknown means the server did not report a usable version.
+	CompatibilityUnknown Compatibility = "unknown"
+	// Incompatible means the major versions differ.
+	Incompatible Compatibility = "incompatible"
+)
+
+// VersionCheck is the outcome of comparing client and server versions.
+type VersionCheck struct {
+	ClientAPIVersion string        `json:"client_api_version"`
+	ServerAPIVersion string        `json:"server_api_version,omitempty"`
+	ServerVersion    string        `json:"server_version,omitempty"`
+	Compatibility    Compatibility `json:"compatibility"`
+}
+
+// Message describes the check for users; it is empty when compatible.
+func (v VersionCheck) Message() string {
+	switch v.Compatibility {
+	case Incompatible:
+		return fmt.Sprintf("server API %s is incompatible with client API %s", v.ServerAPIVersion, v.ClientAPIVersion)
+	case CompatibilityServerOlder:
+		return fmt.Sprintf("server API %s is older than client API %s; newer features may be unavailable", v.ServerAPIVersion, v.ClientAPIVersion)
+	case CompatibilityUnknown:
+		return fmt.Sprintf("server did not report a usable API version; client API is %s", v.ClientAPIVersion)
+	}
+	return ""
+}
+
+// CheckVersion compares APIVersion with the spec_version the server reports
+// on GET /capabilities.
+func (c *Client) CheckVersion(ctx context.Context) (*VersionCheck, error) {
+	caps, err := c.Capabilities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &VersionCheck{
+		ClientAPIVersion: APIVersion,
+		ServerAPIVersion: caps.SpecVersion,
+		ServerVersion:    caps.Version,
+		Compatibility:    CompareAPIVersions(APIVersion, caps.SpecVersion),
+	}, nil
+}
+
+// CompareAPIVersions classifies server against client. Minor versions only
+// add to the API, so a newer server minor is compatible; an older one may
+// lack what the client expects.
+func CompareAPIVersions(client, server string) Compatibility {
+	c, ok := parseAPIVersion(client)
+	if !ok {
+		return CompatibilityUnknown
+	}
+	s, ok := parseAPIVersion(server)
+	if !ok {
+		return CompatibilityUnknown
+	}
+	switch {
+	case c[0] != s[0]:
+		return Incompatible
+	case s[1] < c[1]:
+		return CompatibilityServerOlder
+	}
+	return Compatible
+}
+
+// parseAPIVersion reads major.minor[.patch] with an optional v prefix.
+func parseAPIVersion(v string) ([2]int, bool) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(v), "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return [2]int{}, false
+	}
+	var out [2]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return [2]int{}, false
+		}
+		if i < 2 {
+			out[i] = n
+		}
+	}
+	return out, true
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestCompareAPIVersions(t *testing.T) {
+	cases := []struct {
+		client, server string
+		want           Compatibility
+	}{
+		{"1.2.0", "1.2.0", Compatible},
+		{"1.2.0", "1.2.7", Compatible},
+		{"1.2.0", "1.3.0", Compatible},
+		{"1.2.0", "v1.2", Compatible},
+		{"1.2.0", "1.1.9", CompatibilityServerOlder},
+		{"1.2.0", "2.0.0", Incompatible},
+		{"2.0.0", "1.9.0", Incompatible},
+		{"1.2.0", "", CompatibilityUnknown},
+		{"1.2.0", "dev", CompatibilityUnknown},
+	}
+	for _, tc := range cases {
+		if got := CompareAPIVersions(tc.client, tc.server); got != tc.want {
+			t.Errorf("CompareAPIVersions(%q, %q) = %s, want %s", tc.client, tc.server, got, tc.want)
+		}
+	}
+}
+
+func TestCheckVersionAgainstServe(t *testing.T) {
+	c := startServe(t)
+	check, err := c.CheckVersion(context.Background())
+	if err != nil {
+		t.Fatalf("check version: %v", err)
+	}
+	if check.Compatibility != Compatible || check.ServerAPIVersion != APIVersion {
+		t.Fatalf("expected the server to implement API %s, got %+v", APIVersion, check)
+	}
+
+	req, err := c.newRequest(context.Background(), http.MethodGet, "/runs", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get(HeaderAPIVersion) != APIVersion {
+		t.Fatalf("expected requests to carry %s", HeaderAPIVersion)
+	}
+	resp, err := c.do(req, nil)
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if resp.Header.Get(HeaderAPIVersion) != APIVersion || resp.Header.Get(HeaderServerVersion) == "" {
+		t.Fatalf("expected version headers on the response, got %v", resp.Header)
+	}
+}
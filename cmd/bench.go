@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/client"
+	"github.com/flowd-org/flowd/internal/bench"
+	"github.com/spf13/cobra"
+)
+
+func NewBenchCmd() *cobra.Command {
+	var (
+		server       string
+		token        string
+		jobs         []string
+		argsJSON     string
+		concurrency  int
+		count        int
+		watch        bool
+		watchTimeout time.Duration
+		jsonOut      bool
+	)
+	defaultServer := os.Getenv("FLWD_API")
+	if strings.TrimSpace(defaultServer) == "" {
+		defaultServer = "http://127.0.0.1:8080"
+	}
+	cmd := &cobra.Command{
+		Use:   ":bench --jobs <job-id>[,...]",
+		Short: "Measure run creation throughput and latency against a server",
+		Long: "Create --count runs of the given jobs with --concurrency parallel submitters and " +
+			"report created/sec and create latency. Each run's event stream is followed to " +
+			"measure time to start (submission to run.start) and event latency (finished_at " +
+			"to run.finish arrival; assumes synchronised clocks). Pass --watch=false to measure " +
+			"creation only.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(jobs) == 0 {
+				return fmt.Errorf("[x] --jobs is required")
+			}
+			if count <= 0 {
+				return fmt.Errorf("[x] --count must be positive")
+			}
+			cfg := bench.Config{
+				Client:       client.New(normalizeBaseURL(server), client.WithToken(token)),
+				Jobs:         jobs,
+				Concurrency:  concurrency,
+				Count:        count,
+				Watch:        watch,
+				WatchTimeout: watchTimeout,
+			}
+			if argsJSON != "" {
+				if err := json.Unmarshal([]byte(argsJSON), &cfg.Args); err != nil {
+					return fmt.Errorf("[x] decode --args: %w", err)
+				}
+			}
+
+			report, err := bench.Run(cmd.Context(), cfg)
+			if err != nil {
+				return fmt.Errorf("[x] bench: %w", err)
+			}
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(report); err != nil {
+					return err
+				}
+			} else {
+				printBench(cmd.OutOrStdout(), report)
+			}
+			if report.Failed > 0 {
+				return fmt.Errorf("[x] %d of %d runs could not be created", report.Failed, report.Requested)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&server, "server", defaultServer, "Runner API base URL (or set FLWD_API)")
+	cmd.Flags().StringVar(&token, "token", os.Getenv("FLWD_TOKEN"), "Bearer token for Runner API (or set FLWD_TOKEN)")
+	cmd.Flags().StringSliceVar(&jobs, "jobs", nil, "Jobs to submit round-robin (comma-separated or repeated)")
+	cmd.Flags().StringVar(&argsJSON, "args", "", "Arguments for every run as a JSON object")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 10, "Number of parallel submitters")
+	cmd.Flags().IntVar(&count, "count", 100, "Total number of runs to create")
+	cmd.Flags().BoolVar(&watch, "watch", true, "Follow each run's event stream to measure start and event latency")
+	cmd.Flags().DurationVar(&watchTimeout, "watch-timeout", 5*time.Minute, "How long to follow each run")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the report as JSON")
+	return cmd
+}
+
+func printBench(w io.Writer, r bench.Report) {
+	fmt.Fprintf(w, "runs:          %d created, %d failed in %s\n", r.Created, r.Failed, r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(w, "throughput:    %.1f created/sec\n", r.CreatedPerSec)
+	for _, row := range []struct {
+		label string
+		stats bench.Stats
+	}{{"create", r.Create}, {"time-to-start", r.TimeToStart}, {"sse latency", r.SSELatency}} {
+		if row.stats.Samples == 0 {
+			continue
+		}
+		s := row.stats
+		fmt.Fprintf(w, "%-14s p50 %s  p95 %s  p99 %s  max %s\n", row.label+":",
+			s.P50.Round(time.Millisecond), s.P95.Round(time.Millisecond), s.P99.Round(time.Millisecond), s.Max.Round(time.Millisecond))
+	}
+	for msg, n := range r.Errors {
+		fmt.Fprintf(w, "  - %dx %s\n", n, msg)
+	}
+}
@@ -4,11 +4,13 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/flowd-org/flowd/internal/argsloader"
 	"github.com/flowd-org/flowd/internal/configloader"
@@ -17,6 +19,7 @@ import (
 	"github.com/flowd-org/flowd/internal/executor"
 	"github.com/flowd-org/flowd/internal/indexer"
 	"github.com/flowd-org/flowd/internal/paths"
+	"github.com/flowd-org/flowd/internal/policy"
 	"github.com/flowd-org/flowd/internal/types"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -176,13 +179,23 @@ func makeRunE(scriptDir string) func(cmd *cobra.Command, args []string) error {
 				// E_ARGS: return with field-level message
 				return fmt.Errorf("E_ARGS: %v", vErr)
 			}
+			for _, w := range b.Warnings {
+				fmt.Fprintf(os.Stderr, "[!] arg %s: %s\n", w.Arg, w.Message)
+			}
+			if cfg.ArgEnv != nil {
+				names, err := engine.ArgEnvNames(*cfg.ArgSpec, cfg)
+				if err != nil {
+					return fmt.Errorf("E_ARGS: arg_env: %v", err)
+				}
+				engine.ApplyArgEnv(b, names)
+			}
 			bind = b
 		}
 
 		flagsMap := make(map[string]interface{})
 		cmd.Flags().VisitAll(func(f *pflag.Flag) {
 			switch f.Name {
-			case "dry-run", "verbose", "quiet", "strict", "on-error", "report", "report-file", "json":
+			case "dry-run", "verbose", "quiet", "strict", "on-error", "report", "report-file", "json", "yes-prod", "max-parallel-steps":
 				return
 			}
 			switch f.Value.Type() {
@@ -203,10 +216,15 @@ func makeRunE(scriptDir string) func(cmd *cobra.Command, args []string) error {
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		verbosity, _ := cmd.Flags().GetCount("verbose")
 		strict, _ := cmd.Flags().GetBool("strict")
+		maxParallel, _ := cmd.Flags().GetInt("max-parallel-steps")
 		quiet, _ := cmd.Flags().GetBool("quiet")
 		reportFormat, _ := cmd.Flags().GetString("report")
 		reportFile, _ := cmd.Flags().GetString("report-file")
 		jsonEvents, _ := cmd.Flags().GetBool("json")
+		yesProd, _ := cmd.Flags().GetBool("yes-prod")
+		if cfg.Impact.High() && !dryRun && !yesProd {
+			return fmt.Errorf("E_IMPACT: %s is a high-impact job (%s); rerun with --yes-prod to confirm", cmd.CommandPath(), cfg.Impact.Summary())
+		}
 
 		runID := events.GenerateRunID()
 		jobID := cmd.CommandPath()
@@ -222,7 +240,16 @@ func makeRunE(scriptDir string) func(cmd *cobra.Command, args []string) error {
 		if prof == "" {
 			prof = "secure"
 		}
-		plan.SecurityProfile = strings.ToLower(prof)
+		// A job-level security_profile acts as a floor the caller cannot relax.
+		plan.SecurityProfile = policy.StrictestProfile(strings.ToLower(prof), cfg.SecurityProfile)
+		if plan.SecurityProfile == "" {
+			plan.SecurityProfile = strings.ToLower(prof)
+		}
+		scripts, err := executor.ScriptDigests(scriptDir, cfg)
+		if err != nil {
+			return err
+		}
+		plan.Scripts = scripts
 		runDir := paths.RunDir(runID)
 		if abs, err := filepath.Abs(runDir); err == nil {
 			runDir = abs
@@ -251,11 +278,9 @@ func makeRunE(scriptDir string) func(cmd *cobra.Command, args []string) error {
 			emitter.EmitRunStart(runID, jobID)
 		}
 
-		stdoutWriter := io.MultiWriter(stdoutFile, os.Stdout)
-		stderrWriter := io.MultiWriter(stderrFile, os.Stderr)
+		var stdoutWriter, stderrWriter io.Writer = os.Stdout, os.Stderr
 		if quiet {
-			stdoutWriter = io.Writer(stdoutFile)
-			stderrWriter = io.Writer(stderrFile)
+			stdoutWriter, stderrWriter = nil, nil
 		}
 
 		ecfg := executor.ExecutorConfig{
@@ -269,6 +294,12 @@ func makeRunE(scriptDir string) func(cmd *cobra.Command, args []string) error {
 			RunDir:       runDir,
 			StdoutWriter: stdoutWriter,
 			StderrWriter: stderrWriter,
+			StdoutLog:    stdoutFile,
+			StderrLog:    stderrFile,
+			// Scripts replaced after plan.json was written are refused.
+			ScriptDigests: executor.DigestMap(plan.Scripts),
+			// DAG steps whose needs are met run up to this many at a time.
+			MaxParallelSteps: maxParallel,
 		}
 		if bind != nil {
 			ecfg.ArgEnv = bind.ScalarEnv
@@ -277,9 +308,13 @@ func makeRunE(scriptDir string) func(cmd *cobra.Command, args []string) error {
 			ecfg.LineRedactor = events.NewLineRedactor(bind.SecretValues)
 		}
 
+		startedAt := time.Now().UTC()
 		results, err := executor.RunScripts(context.Background(), scriptDir, ecfg)
 		status := "completed"
-		if err != nil {
+		var timeout *executor.TimeoutError
+		if errors.As(err, &timeout) {
+			status = "timed_out"
+		} else if err != nil {
 			status = "failed"
 		} else {
 			for _, r := range results {
@@ -292,6 +327,29 @@ func makeRunE(scriptDir string) func(cmd *cobra.Command, args []string) error {
 		if emitter != nil {
 			emitter.EmitRunFinish(runID, status, err)
 		}
+		if !dryRun {
+			receipt := types.RunReceipt{
+				RunID:           runID,
+				JobID:           jobID,
+				Status:          status,
+				SecurityProfile: plan.SecurityProfile,
+				StartedAt:       startedAt,
+				FinishedAt:      time.Now().UTC(),
+				Scripts:         plan.Scripts,
+				Provenance:      localReceiptProvenance(jobID, scriptDir),
+			}
+			artifacts, skipped, aErr := executor.IndexArtifacts(runDir, executor.ArtifactLimits{})
+			if aErr != nil {
+				fmt.Fprintf(os.Stderr, "[!] Artifact index error: %v\n", aErr)
+			}
+			if len(skipped) > 0 {
+				fmt.Fprintf(os.Stderr, "[!] Artifacts over the size limits were not indexed: %s\n", strings.Join(skipped, ", "))
+			}
+			receipt.Artifacts = artifacts
+			if rErr := writeRunReceipt(receipt, runDir); rErr != nil {
+				fmt.Fprintf(os.Stderr, "[!] Receipt error: %v\n", rErr)
+			}
+		}
 
 		if reportFile != "" && (reportFormat != "json" && reportFormat != "yaml") {
 			return fmt.Errorf("[x] --report-file requires --report=json or --report=yaml")
@@ -377,3 +435,31 @@ func writePlanArtifact(plan types.Plan, runDir string) error {
 	}
 	return nil
 }
+
+// localReceiptProvenance mirrors the local source shape serve mode records so
+// :verify-run can locate the job directory for either kind of receipt.
+func localReceiptProvenance(jobID, scriptDir string) map[string]interface{} {
+	resolved := scriptDir
+	if abs, err := filepath.Abs(scriptDir); err == nil {
+		resolved = abs
+	}
+	return map[string]interface{}{
+		"source": map[string]interface{}{
+			"type":         "local",
+			"name":         jobID,
+			"ref":          scriptDir,
+			"resolved_ref": resolved,
+		},
+	}
+}
+
+func writeRunReceipt(receipt types.RunReceipt, runDir string) error {
+	data, err := json.MarshalIndent(receipt, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode receipt: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(runDir, "receipt.json"), append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write receipt: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ package cmd
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -51,3 +52,33 @@ job:
 		t.Fatalf("unexpected alias target annotation %q", aliasCmd.Annotations["aliasTarget"])
 	}
 }
+
+func TestHighImpactJobRequiresYesProd(t *testing.T) {
+	scriptsDir := t.TempDir()
+	jobDir := filepath.Join(scriptsDir, "deploy")
+	if err := os.MkdirAll(filepath.Join(jobDir, "config.d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	config := `version: v1
+job:
+  id: deploy
+  name: Deploy
+interpreter: bash
+impact:
+  environments: [staging]
+  blast_radius: high
+`
+	if err := os.WriteFile(filepath.Join(jobDir, "config.d", "config.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd := &cobra.Command{Use: "flwd", SilenceUsage: true, SilenceErrors: true}
+	if err := RegisterScriptCommands(rootCmd, scriptsDir); err != nil {
+		t.Fatalf("RegisterScriptCommands error: %v", err)
+	}
+	rootCmd.SetArgs([]string{"deploy"})
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "E_IMPACT") || !strings.Contains(err.Error(), "--yes-prod") {
+		t.Fatalf("expected E_IMPACT refusal, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/conformance"
+	"github.com/spf13/cobra"
+)
+
+func NewConformanceCmd() *cobra.Command {
+	var (
+		server       string
+		token        string
+		limitedToken string
+		jobID        string
+		argsJSON     string
+		timeout      time.Duration
+		jsonOut      bool
+	)
+	defaultServer := os.Getenv("FLWD_API")
+	if strings.TrimSpace(defaultServer) == "" {
+		defaultServer = "http://127.0.0.1:8080"
+	}
+	cmd := &cobra.Command{
+		Use:   ":conformance --job <job-id>",
+		Short: "Check that a deployment preserves the serve-mode API contract",
+		Long: "Run the HTTP conformance checks against a flowd server, usually through the proxy " +
+			"or gateway clients use. The checks cover 401/403/409/410/422 problem responses, " +
+			"idempotent run creation and event stream resumption. One run of --job is started; " +
+			"pick a quick, harmless job. Exits non-zero when any check fails.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if jobID == "" {
+				return fmt.Errorf("[x] --job is required")
+			}
+			cfg := conformance.Config{
+				Server:       normalizeBaseURL(server),
+				Token:        token,
+				LimitedToken: limitedToken,
+				JobID:        jobID,
+				Timeout:      timeout,
+			}
+			if argsJSON != "" {
+				if err := json.Unmarshal([]byte(argsJSON), &cfg.Args); err != nil {
+					return fmt.Errorf("[x] decode --args: %w", err)
+				}
+			}
+
+			results := conformance.Run(cmd.Context(), cfg)
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(results); err != nil {
+					return err
+				}
+			} else {
+				printConformance(cmd.OutOrStdout(), results)
+			}
+			if conformance.AnyFailed(results) {
+				return fmt.Errorf("[x] %s does not conform to the API contract", cfg.Server)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&server, "server", defaultServer, "Runner API base URL (or set FLWD_API)")
+	cmd.Flags().StringVar(&token, "token", os.Getenv("FLWD_TOKEN"), "Bearer token with jobs:read, runs:read, runs:write and events:read (or set FLWD_TOKEN)")
+	cmd.Flags().StringVar(&limitedToken, "limited-token", "", "Valid token without runs:read, for the 403 check")
+	cmd.Flags().StringVar(&jobID, "job", "", "Job to start for the run checks")
+	cmd.Flags().StringVar(&argsJSON, "args", "", "Arguments for --job as a JSON object")
+	cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "Timeout for each check")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the results as JSON")
+	return cmd
+}
+
+func printConformance(w io.Writer, results []conformance.Result) {
+	for _, r := range results {
+		line := fmt.Sprintf("%-30s %s", r.Name+":", r.Result)
+		if r.Detail != "" {
+			line += " (" + r.Detail + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
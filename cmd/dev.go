@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server"
+	"github.com/spf13/cobra"
+)
+
+// NewDevCmd creates the :dev command: :serve with development defaults, a
+// scripts watcher that also reacts to script edits, and the API URLs printed
+// once the server answers.
+func NewDevCmd() *cobra.Command {
+	var (
+		bindAddr      string
+		profile       string
+		scriptsRoot   string
+		watchInterval time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   ":dev",
+		Short: "Run a local development server that reloads on script changes",
+		Long: "Start serve mode with development defaults (relaxed auth and CORS, permissive " +
+			"security profile), watch the scripts tree and reload the job index and cached " +
+			"plans whenever a config or script changes. The API and OpenAPI URLs are printed " +
+			"once the server is ready.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Resolve profile precedence for dev: flag > env > permissive
+			if profile == "" {
+				profile = os.Getenv("FLWD_PROFILE")
+			}
+			if profile == "" {
+				profile = "permissive"
+			}
+			cfg := server.Config{
+				Bind:              bindAddr,
+				Dev:               true,
+				Log:               "text",
+				Profile:           strings.ToLower(profile),
+				ScriptsRoot:       scriptsRoot,
+				StdOut:            os.Stdout,
+				StdErr:            os.Stderr,
+				MetricsEnabled:    true,
+				MetricsConfigured: true,
+				WatchScripts:      true,
+				WatchInterval:     watchInterval,
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			url := devURL(bindAddr)
+			go func() {
+				if !waitHealthy(ctx, url+"/healthz") {
+					return
+				}
+				announceDev(cmd.ErrOrStderr(), url, scriptsRoot)
+			}()
+
+			if err := server.Run(ctx, cfg); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("[x] dev: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&bindAddr, "bind", "127.0.0.1:8080", "Address for HTTP server to listen on")
+	cmd.Flags().StringVar(&profile, "profile", "", "Security profile (secure|permissive|disabled; default permissive); overrides FLWD_PROFILE")
+	cmd.Flags().StringVar(&scriptsRoot, "root", "scripts", "Scripts directory to serve and watch")
+	cmd.Flags().DurationVar(&watchInterval, "watch-interval", 500*time.Millisecond, "How often to poll the scripts tree for changes")
+	return cmd
+}
+
+// devURL turns a bind address into a URL a client can reach; wildcard hosts
+// are replaced by loopback.
+func devURL(bind string) string {
+	host, port, err := net.SplitHostPort(bind)
+	if err != nil {
+		return "http://" + bind
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+// waitHealthy polls url until it answers 2xx or ctx ends.
+func waitHealthy(ctx context.Context, url string) bool {
+	client := &http.Client{Timeout: time.Second}
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return false
+		}
+		if resp, err := client.Do(req); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode/100 == 2 {
+				return true
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+func announceDev(w io.Writer, url, root string) {
+	fmt.Fprintf(w, "[OK] flowd dev server ready\n")
+	fmt.Fprintf(w, "    API:      %s (FLWD_API=%s)\n", url, url)
+	fmt.Fprintf(w, "    OpenAPI:  %s/openapi.json\n", url)
+	fmt.Fprintf(w, "    Watching: %s\n", root)
+}
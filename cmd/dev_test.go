@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cmd
+
+import "testing"
+
+func TestDevURL(t *testing.T) {
+	cases := map[string]string{
+		"127.0.0.1:8080": "http://127.0.0.1:8080",
+		":9000":          "http://127.0.0.1:9000",
+		"0.0.0.0:8080":   "http://127.0.0.1:8080",
+		"[::]:8080":      "http://127.0.0.1:8080",
+		"[::1]:8080":     "http://[::1]:8080",
+		"localhost:8080": "http://localhost:8080",
+	}
+	for bind, want := range cases {
+		if got := devURL(bind); got != want {
+			t.Errorf("devURL(%q) = %q, want %q", bind, got, want)
+		}
+	}
+}
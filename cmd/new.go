@@ -0,0 +1,224 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/configloader"
+	"github.com/spf13/cobra"
+)
+
+// jobSegmentPattern restricts scaffolded job path segments to names that
+// work as directory names and cobra command names alike.
+var jobSegmentPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// scaffoldOptions describes the job :new job generates.
+type scaffoldOptions struct {
+	Root     string
+	Path     string
+	Executor string
+	Image    string
+	Force    bool
+}
+
+// scaffoldResult lists the files written for a scaffolded job.
+type scaffoldResult struct {
+	ID    string   `json:"id"`
+	Dir   string   `json:"dir"`
+	Files []string `json:"files"`
+}
+
+func NewNewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   ":new",
+		Short: "Scaffold new flwd resources",
+	}
+	cmd.AddCommand(newNewJobCmd())
+	return cmd
+}
+
+func newNewJobCmd() *cobra.Command {
+	var (
+		opts    scaffoldOptions
+		jsonOut bool
+	)
+	cmd := &cobra.Command{
+		Use:   "job <group/name>",
+		Short: "Generate a job with config, a starter script and a smoke test",
+		Long: "Generate config.d/config.yaml, a 100_main.sh starter script that reads its " +
+			"arguments from ARG_* variables, and a smoke_test.sh that runs the job through the " +
+			"CLI. The path is one or two segments, e.g. deploy or demo/deploy; the job ID " +
+			"joins them with dots. Use --executor container with --image for container jobs.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Path = args[0]
+			result, err := scaffoldJob(opts)
+			if err != nil {
+				return fmt.Errorf("[x] %w", err)
+			}
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(result)
+			}
+			printScaffold(cmd.OutOrStdout(), opts.Path, result)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opts.Root, "root", "scripts", "Scripts directory to create the job in")
+	cmd.Flags().StringVar(&opts.Executor, "executor", "proc", "Executor for the job (proc|container)")
+	cmd.Flags().StringVar(&opts.Image, "image", "", "Container image (required with --executor container)")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Overwrite an existing job")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output the generated files as JSON")
+	return cmd
+}
+
+// scaffoldJob writes the job files and validates the generated config with
+// the loader runs use, so a scaffolded job is runnable as-is.
+func scaffoldJob(opts scaffoldOptions) (scaffoldResult, error) {
+	segments := strings.Split(strings.Trim(opts.Path, "/"), "/")
+	if len(segments) > 2 {
+		return scaffoldResult{}, fmt.Errorf("job path %q has more than two segments", opts.Path)
+	}
+	for _, seg := range segments {
+		if !jobSegmentPattern.MatchString(seg) {
+			return scaffoldResult{}, fmt.Errorf("invalid job path segment %q: use lowercase letters, digits, '-' and '_'", seg)
+		}
+	}
+	switch opts.Executor {
+	case "proc":
+		if opts.Image != "" {
+			return scaffoldResult{}, fmt.Errorf("--image requires --executor container")
+		}
+	case "container":
+		if opts.Image == "" {
+			return scaffoldResult{}, fmt.Errorf("--executor container requires --image")
+		}
+	default:
+		return scaffoldResult{}, fmt.Errorf("unknown executor %q (want proc or container)", opts.Executor)
+	}
+
+	root := opts.Root
+	if root == "" {
+		root = "scripts"
+	}
+	// A leaf job cannot also be a group; the parent would hide the new job.
+	if len(segments) == 2 {
+		if _, err := os.Stat(filepath.Join(root, segments[0], "config.d", "config.yaml")); err == nil {
+			return scaffoldResult{}, fmt.Errorf("%s is already a job and cannot hold sub-jobs", filepath.Join(root, segments[0]))
+		}
+	}
+	dir := filepath.Join(append([]string{root}, segments...)...)
+	configPath := filepath.Join(dir, "config.d", "config.yaml")
+	if _, err := os.Stat(configPath); err == nil && !opts.Force {
+		return scaffoldResult{}, fmt.Errorf("%s already exists (use --force to overwrite)", configPath)
+	}
+
+	id := strings.Join(segments, ".")
+	files := []struct {
+		path    string
+		content string
+		mode    os.FileMode
+	}{
+		{configPath, scaffoldConfig(id, segments, opts), 0o644},
+		{filepath.Join(dir, "100_main.sh"), scaffoldMainScript(opts), 0o755},
+		{filepath.Join(dir, "smoke_test.sh"), scaffoldSmokeTest(segments), 0o755},
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "config.d"), 0o755); err != nil {
+		return scaffoldResult{}, fmt.Errorf("create %s: %w", dir, err)
+	}
+	result := scaffoldResult{ID: id, Dir: dir}
+	for _, f := range files {
+		if err := os.WriteFile(f.path, []byte(f.content), f.mode); err != nil {
+			return scaffoldResult{}, fmt.Errorf("write %s: %w", f.path, err)
+		}
+		// WriteFile keeps the mode of an existing file; --force must not.
+		if err := os.Chmod(f.path, f.mode); err != nil {
+			return scaffoldResult{}, err
+		}
+		result.Files = append(result.Files, f.path)
+	}
+	if _, err := configloader.LoadConfig(dir); err != nil {
+		return scaffoldResult{}, fmt.Errorf("generated config does not load: %w", err)
+	}
+	return result, nil
+}
+
+func scaffoldConfig(id string, segments []string, opts scaffoldOptions) string {
+	var b strings.Builder
+	b.WriteString("# SPDX-License-Identifier: AGPL-3.0-or-later\n")
+	b.WriteString("version: v1\n")
+	b.WriteString("job:\n")
+	fmt.Fprintf(&b, "  id: %s\n", id)
+	fmt.Fprintf(&b, "  name: %s\n", strings.Join(segments, " "))
+	fmt.Fprintf(&b, "  summary: %q\n", "TODO: describe what "+id+" does")
+	if opts.Executor == "container" {
+		fmt.Fprintf(&b, "interpreter: %q\n", "container:"+opts.Image)
+		b.WriteString("executor: container\n")
+	} else {
+		b.WriteString("interpreter: bash\n")
+		b.WriteString("executor: proc\n")
+	}
+	b.WriteString(`argspec:
+  args:
+    - name: name
+      type: string
+      description: "Who or what the job acts on"
+      required: true
+    - name: dry_run
+      type: boolean
+      description: "Print what would happen without changing anything"
+      default: false
+`)
+	return b.String()
+}
+
+func scaffoldMainScript(opts scaffoldOptions) string {
+	shebang := "#!/usr/bin/env bash\nset -euo pipefail\n"
+	if opts.Executor == "container" {
+		// Minimal images such as alpine ship without bash.
+		shebang = "#!/bin/sh\nset -eu\n"
+	}
+	return shebang + `
+# Arguments declared in config.d/config.yaml arrive as ARG_<NAME>; the full
+# set is also available as JSON in $FLWD_ARGS_JSON.
+name="${ARG_NAME:-}"
+dry_run="${ARG_DRY_RUN:-false}"
+
+if [ "$dry_run" = "true" ]; then
+  echo "[dry-run] would act on ${name}"
+  exit 0
+fi
+
+echo "Acting on ${name}"
+`
+}
+
+func scaffoldSmokeTest(segments []string) string {
+	return `#!/usr/bin/env bash
+# Smoke test: runs the job through the CLI in dry-run mode and checks its
+# output. Run it from the directory that holds scripts/.
+set -euo pipefail
+
+out="$(${FLWD:-flwd} ` + strings.Join(segments, " ") + ` --name smoke --dry_run)"
+case "$out" in
+  *"would act on smoke"*) echo "ok" ;;
+  *) echo "unexpected output: $out" >&2; exit 1 ;;
+esac
+`
+}
+
+func printScaffold(w io.Writer, path string, result scaffoldResult) {
+	fmt.Fprintf(w, "[+] Created job %s in %s\n", result.ID, result.Dir)
+	for _, f := range result.Files {
+		fmt.Fprintf(w, "    %s\n", f)
+	}
+	fmt.Fprintf(w, "Run it with: flwd %s --name world\n", strings.ReplaceAll(strings.Trim(path, "/"), "/", " "))
+}
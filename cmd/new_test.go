@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/configloader"
+	"github.com/flowd-org/flowd/internal/indexer"
+	"github.com/spf13/cobra"
+)
+
+func TestScaffoldContainerJob(t *testing.T) {
+	root := t.TempDir()
+	result, err := scaffoldJob(scaffoldOptions{Root: root, Path: "demo/deploy", Executor: "container", Image: "alpine:3.20"})
+	if err != nil {
+		t.Fatalf("scaffoldJob: %v", err)
+	}
+	if result.ID != "demo.deploy" || len(result.Files) != 3 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	dir := filepath.Join(root, "demo", "deploy")
+	cfg, err := configloader.LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.Executor != "container" || cfg.Interpreter != "container:alpine:3.20" {
+		t.Fatalf("unexpected executor config %q %q", cfg.Executor, cfg.Interpreter)
+	}
+	info, err := os.Stat(filepath.Join(dir, "100_main.sh"))
+	if err != nil || info.Mode().Perm()&0o100 == 0 {
+		t.Fatalf("starter script not executable: %v", err)
+	}
+	script, _ := os.ReadFile(filepath.Join(dir, "100_main.sh"))
+	if !strings.HasPrefix(string(script), "#!/bin/sh") || !strings.Contains(string(script), "${ARG_NAME:-}") {
+		t.Fatalf("unexpected starter script:\n%s", script)
+	}
+	smoke, _ := os.ReadFile(filepath.Join(dir, "smoke_test.sh"))
+	if !strings.Contains(string(smoke), "demo deploy --name smoke --dry_run") {
+		t.Fatalf("unexpected smoke test:\n%s", smoke)
+	}
+
+	res, err := indexer.Discover(root)
+	if err != nil || len(res.Jobs) != 1 || res.Jobs[0].ID != "demo.deploy" {
+		t.Fatalf("discover: %v %+v", err, res.Jobs)
+	}
+	rootCmd := &cobra.Command{Use: "flwd"}
+	if err := RegisterScriptCommands(rootCmd, root); err != nil {
+		t.Fatalf("RegisterScriptCommands: %v", err)
+	}
+	if c, _, err := rootCmd.Find([]string{"demo", "deploy"}); err != nil || c.Flags().Lookup("dry_run") == nil {
+		t.Fatalf("scaffolded job not registered with its flags: %v", err)
+	}
+}
+
+func TestScaffoldJobRejections(t *testing.T) {
+	root := t.TempDir()
+	if _, err := scaffoldJob(scaffoldOptions{Root: root, Path: "hello", Executor: "proc"}); err != nil {
+		t.Fatalf("scaffold proc job: %v", err)
+	}
+	cases := []struct {
+		name string
+		opts scaffoldOptions
+		want string
+	}{
+		{"exists", scaffoldOptions{Root: root, Path: "hello", Executor: "proc"}, "already exists"},
+		{"leaf parent", scaffoldOptions{Root: root, Path: "hello/sub", Executor: "proc"}, "cannot hold sub-jobs"},
+		{"too deep", scaffoldOptions{Root: root, Path: "a/b/c", Executor: "proc"}, "more than two segments"},
+		{"bad segment", scaffoldOptions{Root: root, Path: "Demo", Executor: "proc"}, "invalid job path segment"},
+		{"missing image", scaffoldOptions{Root: root, Path: "box", Executor: "container"}, "requires --image"},
+		{"image without container", scaffoldOptions{Root: root, Path: "box", Executor: "proc", Image: "alpine"}, "requires --executor container"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := scaffoldJob(tc.opts)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("want error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+	if _, err := scaffoldJob(scaffoldOptions{Root: root, Path: "hello", Executor: "proc", Force: true}); err != nil {
+		t.Fatalf("--force overwrite: %v", err)
+	}
+}
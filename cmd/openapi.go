@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/flowd-org/flowd/internal/server/openapi"
+	"github.com/spf13/cobra"
+)
+
+func NewOpenAPICmd() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   ":openapi",
+		Short: "Write the serve-mode OpenAPI document",
+		Long: "Write the OpenAPI 3.1 document of the serve-mode API, the same one a server " +
+			"serves at /openapi.json, for generating client bindings. Use -o - to print it.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data := openapi.JSON()
+			if output == "-" {
+				_, err := cmd.OutOrStdout().Write(data)
+				return err
+			}
+			if err := os.WriteFile(output, data, 0o644); err != nil {
+				return fmt.Errorf("[x] write openapi document: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "[+] Wrote OpenAPI document %s\n", output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "openapi.json", "File to write, or - for stdout")
+	return cmd
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/flowd-org/flowd/internal/configloader"
 	"github.com/flowd-org/flowd/internal/engine"
+	"github.com/flowd-org/flowd/internal/policy"
 	"github.com/spf13/cobra"
 )
 
@@ -59,7 +60,11 @@ func NewPlanCmd(root *cobra.Command) *cobra.Command {
 			if profile == "" {
 				profile = "secure"
 			}
-			plan.SecurityProfile = strings.ToLower(profile)
+			// A job-level security_profile acts as a floor the caller cannot relax.
+			plan.SecurityProfile = policy.StrictestProfile(strings.ToLower(profile), cfg.SecurityProfile)
+			if plan.SecurityProfile == "" {
+				plan.SecurityProfile = strings.ToLower(profile)
+			}
 
 			if asJSON {
 				enc := json.NewEncoder(os.Stdout)
@@ -73,6 +78,13 @@ func NewPlanCmd(root *cobra.Command) *cobra.Command {
 					fmt.Printf("Interpreter: %s\n", interp)
 				}
 			}
+			if plan.Impact != nil {
+				confirm := ""
+				if plan.Impact.ConfirmationRequired {
+					confirm = " (requires --yes-prod)"
+				}
+				fmt.Printf("Impact: %s%s\n", cfg.Impact.Summary(), confirm)
+			}
 			fmt.Println("Args:")
 			if spec == nil || len(spec.Args) == 0 {
 				fmt.Println("  (none)")
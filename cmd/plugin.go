@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/flowd-org/flowd/internal/paths"
+	"github.com/spf13/cobra"
+)
+
+// pluginPrefix names plugin binaries: flwd :foo runs flwd-foo from PATH.
+const pluginPrefix = "flwd-"
+
+var pluginNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// pluginInfo describes a plugin binary found on PATH.
+type pluginInfo struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Shadowed is set when a built-in command of the same name wins.
+	Shadowed bool `json:"shadowed,omitempty"`
+}
+
+// findPlugin resolves args to a plugin binary when args[0] is a :name command
+// flwd does not define itself. Built-in commands always take precedence.
+func findPlugin(root *cobra.Command, args []string) (string, bool) {
+	if len(args) == 0 || !strings.HasPrefix(args[0], ":") {
+		return "", false
+	}
+	name := strings.TrimPrefix(args[0], ":")
+	if !pluginNamePattern.MatchString(name) || isBuiltinCommand(root, args[0]) {
+		return "", false
+	}
+	path, err := exec.LookPath(pluginPrefix + name)
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+func isBuiltinCommand(root *cobra.Command, name string) bool {
+	for _, c := range root.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginEnv returns the environment for a plugin: the caller's environment,
+// which carries FLWD_TOKEN, plus the server address and data directory flwd
+// itself would use.
+func pluginEnv(self string) []string {
+	server := os.Getenv("FLWD_API")
+	if strings.TrimSpace(server) == "" {
+		server = "http://127.0.0.1:8080"
+	}
+	env := append(os.Environ(),
+		"FLWD_API="+normalizeBaseURL(server),
+		"DATA_DIR="+paths.DataDir(),
+	)
+	if self != "" {
+		env = append(env, "FLWD_BIN="+self)
+	}
+	return env
+}
+
+// runPlugin runs the plugin at path with args and returns its exit code.
+// Interrupts reach the plugin directly from the terminal, so flwd only waits
+// for it instead of exiting first.
+func runPlugin(path string, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	self, _ := os.Executable()
+	c := exec.Command(path, args...)
+	c.Stdin, c.Stdout, c.Stderr = stdin, stdout, stderr
+	c.Env = pluginEnv(self)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	if err := c.Start(); err != nil {
+		fmt.Fprintf(stderr, "[x] plugin %s: %v\n", filepath.Base(path), err)
+		return 1
+	}
+	go func() {
+		for sig := range signals {
+			if sig == syscall.SIGTERM {
+				_ = c.Process.Signal(sig)
+			}
+		}
+	}()
+	err := c.Wait()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exitErr) && exitErr.ExitCode() >= 0:
+		return exitErr.ExitCode()
+	}
+	fmt.Fprintf(stderr, "[x] plugin %s: %v\n", filepath.Base(path), err)
+	return 1
+}
+
+// listPlugins scans PATH for plugin binaries. As with command lookup, the
+// first directory providing a name wins.
+func listPlugins(root *cobra.Command) []pluginInfo {
+	seen := map[string]bool{}
+	var out []pluginInfo
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			dir = "."
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok || seen[name] || entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			out = append(out, pluginInfo{Name: ":" + name, Path: path, Shadowed: isBuiltinCommand(root, ":"+name)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func pluginName(file string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		file = strings.TrimSuffix(strings.ToLower(file), ".exe")
+	}
+	name, ok := strings.CutPrefix(file, pluginPrefix)
+	if !ok || !pluginNamePattern.MatchString(name) {
+		return "", false
+	}
+	return name, true
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+	return info.Mode()&0o111 != 0
+}
+
+func NewPluginsCmd(root *cobra.Command) *cobra.Command {
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   ":plugins",
+		Short: "List CLI plugins found on PATH",
+		Long: "List flwd-<name> executables on PATH. Each one is available as flwd :<name>; " +
+			"arguments are passed through and the plugin receives FLWD_API, FLWD_TOKEN, " +
+			"DATA_DIR and FLWD_BIN in its environment. Built-in commands take precedence " +
+			"over plugins of the same name.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			plugins := listPlugins(root)
+			if jsonOut {
+				if plugins == nil {
+					plugins = []pluginInfo{}
+				}
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(plugins)
+			}
+			if len(plugins) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "(no flwd-* plugins found on PATH)")
+				return nil
+			}
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "COMMAND\tPATH")
+			for _, p := range plugins {
+				path := p.Path
+				if p.Shadowed {
+					path += " (shadowed by built-in)"
+				}
+				fmt.Fprintf(tw, "%s\t%s\n", p.Name, path)
+			}
+			return tw.Flush()
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output plugins as JSON")
+	return cmd
+}
@@ -0,0 +1,72 @@
+//go:build unix
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/paths"
+	"github.com/spf13/cobra"
+)
+
+func writePlugin(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, pluginPrefix+name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPluginDispatch(t *testing.T) {
+	dir := t.TempDir()
+	hello := writePlugin(t, dir, "hello", `echo "args=$*"
+echo "api=$FLWD_API"
+echo "token=$FLWD_TOKEN"
+echo "data=$DATA_DIR"
+exit 3
+`)
+	writePlugin(t, dir, "jobs", "echo plugin\n")
+	t.Setenv("PATH", dir)
+	t.Setenv("FLWD_API", "127.0.0.1:9999/")
+	t.Setenv("FLWD_TOKEN", "secret")
+	paths.SetDataDirOverride("/srv/flowd")
+	t.Cleanup(func() { paths.SetDataDirOverride("") })
+
+	root := &cobra.Command{Use: "flwd"}
+	root.AddCommand(&cobra.Command{Use: ":jobs"})
+
+	path, ok := findPlugin(root, []string{":hello", "a", "b"})
+	if !ok || path != hello {
+		t.Fatalf("expected :hello to resolve to %s, got %q", hello, path)
+	}
+	if _, ok := findPlugin(root, []string{":jobs"}); ok {
+		t.Fatalf("expected built-in :jobs to win over the plugin")
+	}
+	if _, ok := findPlugin(root, []string{":missing"}); ok {
+		t.Fatalf("expected no plugin for :missing")
+	}
+	if _, ok := findPlugin(root, []string{"hello"}); ok {
+		t.Fatalf("expected plugins to require the : prefix")
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := runPlugin(path, []string{"a", "b"}, strings.NewReader(""), &stdout, &stderr)
+	if code != 3 {
+		t.Fatalf("expected plugin exit code 3, got %d (stderr %q)", code, stderr.String())
+	}
+	for _, want := range []string{"args=a b", "api=http://127.0.0.1:9999", "token=secret", "data=/srv/flowd"} {
+		if !strings.Contains(stdout.String(), want+"\n") {
+			t.Fatalf("expected %q in plugin output, got %q", want, stdout.String())
+		}
+	}
+
+	plugins := listPlugins(root)
+	if len(plugins) != 2 || plugins[0].Name != ":hello" || plugins[0].Shadowed || plugins[1].Name != ":jobs" || !plugins[1].Shadowed {
+		t.Fatalf("unexpected plugin list %+v", plugins)
+	}
+}
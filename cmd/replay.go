@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/policy"
+	"github.com/flowd-org/flowd/internal/server/handlers"
+	"github.com/spf13/cobra"
+)
+
+func NewReplayCmd() *cobra.Command {
+	var (
+		root    string
+		profile string
+		jsonOut bool
+	)
+	cmd := &cobra.Command{
+		Use:   ":replay <request.json>",
+		Short: "Re-evaluate a recorded POST /runs or POST /plans request offline",
+		Long: "Replay a recorded request (method, path, headers, canonical body, caller principal " +
+			"and scopes, and the registered sources it saw) through the server's validation and " +
+			"policy checks in dry-run. Nothing is executed. Prints the status the server would " +
+			"return together with any policy decisions, and exits non-zero when the request is rejected.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("[x] read request: %w", err)
+			}
+			var req handlers.ReplayRequest
+			if err := json.Unmarshal(data, &req); err != nil {
+				return fmt.Errorf("[x] decode request: %w", err)
+			}
+
+			// Resolve profile precedence for replay: flag > env > default
+			if profile == "" {
+				profile = os.Getenv("FLWD_PROFILE")
+			}
+			if profile == "" {
+				profile = "secure"
+			}
+			bundle, _, err := policy.LoadFromEnvOrDefault()
+			if err != nil {
+				return fmt.Errorf("[x] load policy bundle: %w", err)
+			}
+			policyCtx, err := policy.NewContext(bundle)
+			if err != nil {
+				return fmt.Errorf("[x] policy context: %w", err)
+			}
+			verifier, err := policyCtx.NewImageVerifier()
+			if err != nil {
+				return fmt.Errorf("[x] image verifier: %w", err)
+			}
+			cfg := handlers.ReplayConfig{
+				Root:     root,
+				Profile:  strings.ToLower(profile),
+				Policy:   policyCtx,
+				Verifier: verifier,
+			}
+
+			result, err := handlers.Replay(cmd.Context(), cfg, req)
+			if err != nil {
+				return fmt.Errorf("[x] replay: %w", err)
+			}
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(result); err != nil {
+					return err
+				}
+			} else {
+				printReplay(cmd.OutOrStdout(), result)
+			}
+			if result.Status >= 400 {
+				return fmt.Errorf("[x] %s %s rejected with status %d", result.Method, result.Path, result.Status)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&root, "root", "scripts", "Job root the request is evaluated against")
+	cmd.Flags().StringVar(&profile, "profile", "", "Security profile (secure|permissive|disabled); overrides FLWD_PROFILE")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the replay result as JSON")
+	return cmd
+}
+
+func printReplay(w io.Writer, r handlers.ReplayResult) {
+	fmt.Fprintf(w, "request:   %s %s\n", r.Method, r.Path)
+	fmt.Fprintf(w, "status:    %d\n", r.Status)
+	if r.Problem != nil {
+		line := fmt.Sprintf("problem:   %v", r.Problem["title"])
+		if code, ok := r.Problem["code"]; ok {
+			line += fmt.Sprintf(" [%v]", code)
+		}
+		if detail, ok := r.Problem["detail"]; ok && detail != "" {
+			line += fmt.Sprintf(": %v", detail)
+		}
+		fmt.Fprintln(w, line)
+	}
+	if r.Plan != nil {
+		fmt.Fprintf(w, "job:       %s (profile %s)\n", r.Plan.JobID, r.Plan.SecurityProfile)
+	}
+	for _, d := range r.Decisions {
+		line := fmt.Sprintf("policy:    %s %s", d.Subject, d.Decision)
+		if d.Code != "" {
+			line += " [" + d.Code + "]"
+		}
+		if d.Reason != "" {
+			line += ": " + d.Reason
+		}
+		fmt.Fprintln(w, line)
+	}
+}
@@ -2,7 +2,9 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 
 	"github.com/flowd-org/flowd/internal/paths"
@@ -20,8 +22,9 @@ func Execute() {
 		paths.SetDataDirOverride(dataDir)
 	}
 
-	// Dynamically register commands based on scripts folder
-	if err := RegisterScriptCommands(rootCmd, "scripts"); err != nil {
+	// Dynamically register commands based on scripts folder. A missing
+	// folder is fine: :new job creates it.
+	if err := RegisterScriptCommands(rootCmd, "scripts"); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -30,9 +33,24 @@ func Execute() {
 	rootCmd.AddCommand(NewCompletionCmd(rootCmd))
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(NewSourcesCmd())
+	rootCmd.AddCommand(NewRunsCmd())
 	rootCmd.AddCommand(NewJobsCmd(rootCmd))
 	rootCmd.AddCommand(NewPlanCmd(rootCmd))
 	rootCmd.AddCommand(NewServeCmd())
+	rootCmd.AddCommand(NewDevCmd())
+	rootCmd.AddCommand(NewVerifyRunCmd())
+	rootCmd.AddCommand(NewReplayCmd())
+	rootCmd.AddCommand(NewConformanceCmd())
+	rootCmd.AddCommand(NewBenchCmd())
+	rootCmd.AddCommand(NewNewCmd())
+	rootCmd.AddCommand(NewSupportBundleCmd())
+	rootCmd.AddCommand(NewOpenAPICmd())
+	rootCmd.AddCommand(NewPluginsCmd(rootCmd))
+
+	// Unknown :name commands dispatch to a flwd-name plugin on PATH.
+	if path, ok := findPlugin(rootCmd, os.Args[1:]); ok {
+		os.Exit(runPlugin(path, os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+	}
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -45,8 +63,10 @@ func addCommonFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().CountP("verbose", "v", "Increase verbosity")
 	cmd.PersistentFlags().BoolP("quiet", "q", false, "Quiet mode")
 	cmd.PersistentFlags().Bool("strict", false, "Fail fast on errors")
+	cmd.PersistentFlags().Int("max-parallel-steps", 1, "Independent DAG steps executed at once")
 	cmd.PersistentFlags().String("on-error", "", "Override error policy (abort|continue|retry)")
 	cmd.PersistentFlags().String("report", "", "Output report format (json|yaml)")
 	cmd.PersistentFlags().String("report-file", "", "Write execution report to file (JSON/YAML format)")
 	cmd.PersistentFlags().String("profile", "", "Security profile (secure|permissive|disabled); overrides FLWD_PROFILE")
+	cmd.PersistentFlags().Bool("yes-prod", false, "Confirm running a high-impact job (production environment or high blast radius)")
 }
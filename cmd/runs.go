@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/client"
+	"github.com/spf13/cobra"
+)
+
+func NewRunsCmd() *cobra.Command {
+	defaultServer := os.Getenv("FLWD_API")
+	if strings.TrimSpace(defaultServer) == "" {
+		defaultServer = "http://127.0.0.1:8080"
+	}
+	cmd := &cobra.Command{
+		Use:   ":runs",
+		Short: "Inspect runs via the Runner API",
+	}
+	cmd.PersistentFlags().String("server", defaultServer, "Runner API base URL (or set FLWD_API)")
+	cmd.PersistentFlags().String("token", os.Getenv("FLWD_TOKEN"), "Bearer token for Runner API (or set FLWD_TOKEN)")
+	addVersionCheckFlag(cmd)
+	cmd.AddCommand(newRunsWatchCmd())
+	return cmd
+}
+
+func resolveAPIClient(cmd *cobra.Command) (*client.Client, error) {
+	server, err := cmd.InheritedFlags().GetString("server")
+	if err != nil {
+		return nil, err
+	}
+	token, err := cmd.InheritedFlags().GetString("token")
+	if err != nil {
+		return nil, err
+	}
+	api := client.New(normalizeBaseURL(server), client.WithToken(token))
+	if err := checkServerVersion(cmd, api); err != nil {
+		return nil, err
+	}
+	return api, nil
+}
+
+func newRunsWatchCmd() *cobra.Command {
+	var (
+		jsonOut      bool
+		lastEventID  string
+		pollInterval time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "watch <run-id>",
+		Short: "Follow a run's events until it finishes",
+		Long: "Follow a run's events until it finishes. The stream reconnects with Last-Event-ID " +
+			"when the server restarts; if the server no longer retains the cursor, the command " +
+			"falls back to polling the run's status.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			api, err := resolveAPIClient(cmd)
+			if err != nil {
+				return err
+			}
+			stream, err := api.Runs.Events(cmd.Context(), args[0], &client.EventsOptions{
+				LastEventID:         lastEventID,
+				PollOnExpiredCursor: true,
+				PollInterval:        pollInterval,
+			})
+			if err != nil {
+				return err
+			}
+			defer stream.Close()
+			notified := false
+			enc := json.NewEncoder(os.Stdout)
+			for stream.Next() {
+				if stream.Polling() && !notified {
+					fmt.Fprintln(os.Stderr, "event cursor expired; polling run status")
+					notified = true
+				}
+				ev := stream.Event()
+				if jsonOut {
+					if err := enc.Encode(watchEvent(ev)); err != nil {
+						return err
+					}
+					continue
+				}
+				fmt.Printf("%s\t%s\t%s\n", ev.ID, ev.Type, strings.TrimSpace(string(ev.Data)))
+			}
+			return stream.Err()
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Stream events as NDJSON")
+	cmd.Flags().StringVar(&lastEventID, "last-event-id", "", "Resume after the given event ID")
+	cmd.Flags().DurationVar(&pollInterval, "poll-interval", 2*time.Second, "Status polling interval when the event cursor has expired")
+	return cmd
+}
+
+func watchEvent(ev client.Event) map[string]any {
+	out := map[string]any{"event": ev.Type}
+	if ev.ID != "" {
+		out["id"] = ev.ID
+	}
+	if json.Valid(ev.Data) {
+		out["data"] = json.RawMessage(ev.Data)
+	} else {
+		out["data"] = string(ev.Data)
+	}
+	return out
+}
@@ -6,10 +6,17 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/flowd-org/flowd/internal/executor"
 	"github.com/flowd-org/flowd/internal/server"
+	"github.com/flowd-org/flowd/internal/server/authz"
+	"github.com/flowd-org/flowd/internal/server/faults"
+	"github.com/flowd-org/flowd/internal/server/handlers"
+	"github.com/flowd-org/flowd/internal/server/oidc"
 	"github.com/spf13/cobra"
 )
 
@@ -17,12 +24,39 @@ import (
 func NewServeCmd() *cobra.Command {
 	var (
 		bindAddr       string
+		grpcBind       string
+		rbacPolicy     string
+		oidcCfg        oidc.Config
 		logMode        string
 		devMode        bool
 		profile        string
 		metricsEnabled bool
 		aliasesPublic  bool
+		publicBadges   bool
 		extensionFlags []string
+		publicURL      string
+		smtp           server.SMTPConfig
+		vault          server.VaultConfig
+		runHooks       []string
+		runHookTimeout time.Duration
+		webhooks       server.WebhooksConfig
+		admission      handlers.AdmissionWebhookConfig
+		readOnly       bool
+		runArchive     server.RunArchiveConfig
+		defaultPerPage int
+		maxPerPage     int
+		snapshotEvery  time.Duration
+		eventStreams   handlers.StreamLimits
+		offlineVerify  bool
+		reverifyEvery  time.Duration
+		maxParallel    int
+		maxLogLine     int
+		journalBytes   int
+		journalRetain  time.Duration
+		stripANSI      bool
+		artifactBytes  int
+		runArtifacts   int
+		flakiness      handlers.FlakinessPolicy
 	)
 
 	cmd := &cobra.Command{
@@ -47,7 +81,107 @@ func NewServeCmd() *cobra.Command {
 			}
 			cfg.Profile = strings.ToLower(profile)
 			cfg.AliasesPublic = resolveAliasesPublic(aliasesPublic, cmd)
+			cfg.PublicBadges = resolveBoolFlag(publicBadges, "public-badges", "FLWD_PUBLIC_BADGES", cmd)
 			cfg.Extensions = resolveExtensions(extensionFlags, cmd)
+			cfg.PublicURL = publicURL
+			if !cmd.Flags().Changed("public-url") {
+				cfg.PublicURL = os.Getenv("FLWD_PUBLIC_URL")
+			}
+			cfg.GRPCBind = grpcBind
+			if !cmd.Flags().Changed("grpc-bind") {
+				cfg.GRPCBind = os.Getenv("FLWD_GRPC_BIND")
+			}
+			if !cmd.Flags().Changed("rbac-policy") {
+				rbacPolicy = os.Getenv("FLWD_RBAC_POLICY")
+			}
+			if rbacPolicy != "" {
+				policy, err := authz.LoadPolicy(rbacPolicy)
+				if err != nil {
+					return err
+				}
+				cfg.RBAC = policy
+			}
+			verifier, err := resolveOIDC(oidcCfg, cmd)
+			if err != nil {
+				return err
+			}
+			cfg.OIDC = verifier
+			cfg.SMTP = resolveSMTP(smtp, cmd)
+			cfg.Vault = resolveVault(vault, cmd)
+			hooks, err := resolveRunHooks(runHooks, runHookTimeout, cmd)
+			if err != nil {
+				return err
+			}
+			cfg.RunHooks = hooks
+			cfg.Webhooks = resolveWebhooks(webhooks, cmd)
+			cfg.AdmissionWebhook, err = resolveAdmissionWebhook(admission, cmd)
+			if err != nil {
+				return err
+			}
+			cfg.ReadOnly = resolveBoolFlag(readOnly, "read-only", "FLWD_READ_ONLY", cmd)
+			cfg.Sources.OfflineVerification = resolveBoolFlag(offlineVerify, "offline-verification", "FLWD_OFFLINE_VERIFICATION", cmd)
+			cfg.Sources.ReverifyInterval, err = resolveDurationFlag(reverifyEvery, "source-reverify-interval", "FLWD_SOURCE_REVERIFY_INTERVAL", cmd)
+			if err != nil {
+				return err
+			}
+			archive, err := resolveRunArchive(runArchive, cmd)
+			if err != nil {
+				return err
+			}
+			cfg.RunArchive = archive
+			cfg.MetricsSnapshotInterval, err = resolveMetricsSnapshotInterval(snapshotEvery, cmd)
+			if err != nil {
+				return err
+			}
+			cfg.EventStreams, err = resolveEventStreams(eventStreams, cmd)
+			if err != nil {
+				return err
+			}
+			cfg.DefaultPerPage, cfg.MaxPerPage, err = resolvePagination(defaultPerPage, maxPerPage, cmd)
+			if err != nil {
+				return err
+			}
+			cfg.MaxParallelSteps, err = resolveIntFlag(maxParallel, "max-parallel-steps", "FLWD_MAX_PARALLEL_STEPS", cmd)
+			if err != nil {
+				return err
+			}
+			cfg.MaxLogLineBytes, err = resolveIntFlag(maxLogLine, "max-log-line-bytes", "FLWD_MAX_LOG_LINE_BYTES", cmd)
+			if err != nil {
+				return err
+			}
+			journalBytes, err = resolveIntFlag(journalBytes, "journal-max-bytes", "FLWD_JOURNAL_MAX_BYTES", cmd)
+			if err != nil {
+				return err
+			}
+			cfg.CoreDBOptions.JournalMaxBytes = int64(journalBytes)
+			cfg.StripANSILogs = resolveBoolFlag(stripANSI, "strip-ansi-logs", "FLWD_STRIP_ANSI_LOGS", cmd)
+			artifactBytes, err = resolveIntFlag(artifactBytes, "max-artifact-bytes", "FLWD_MAX_ARTIFACT_BYTES", cmd)
+			if err != nil {
+				return err
+			}
+			runArtifacts, err = resolveIntFlag(runArtifacts, "max-run-artifacts-bytes", "FLWD_MAX_RUN_ARTIFACTS_BYTES", cmd)
+			if err != nil {
+				return err
+			}
+			cfg.ArtifactLimits = executor.ArtifactLimits{MaxFileBytes: int64(artifactBytes), MaxTotalBytes: int64(runArtifacts)}
+			cfg.Flakiness, err = resolveFlakiness(flakiness, cmd)
+			if err != nil {
+				return err
+			}
+			if os.Getenv("FLWD_METRICS") != "" {
+				cfg.MetricsEnabled = resolveBoolFlag(metricsEnabled, "metrics", "FLWD_METRICS", cmd)
+			}
+			cfg.EventJournal.Retention, err = resolveDurationFlag(journalRetain, "journal-retention", "FLWD_JOURNAL_RETENTION", cmd)
+			if err != nil {
+				return err
+			}
+			if env := strings.TrimSpace(os.Getenv(faults.EnvVar)); env != "" {
+				set, err := faults.Parse(env)
+				if err != nil {
+					return fmt.Errorf("invalid %s: %w", faults.EnvVar, err)
+				}
+				cfg.Faults = set
+			}
 
 			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 			defer stop()
@@ -64,21 +198,72 @@ func NewServeCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&bindAddr, "bind", "127.0.0.1:8080", "Address for HTTP server to listen on")
+	cmd.Flags().StringVar(&grpcBind, "grpc-bind", "", "Address for the gRPC API to listen on; empty disables it (overrides FLWD_GRPC_BIND)")
+	cmd.Flags().StringVar(&oidcCfg.Issuer, "oidc-issuer", "", "OpenID Connect issuer URL that bearer tokens must come from; empty keeps FLWD_JWT_SECRET tokens (overrides FLWD_OIDC_ISSUER)")
+	cmd.Flags().StringVar(&oidcCfg.Audience, "oidc-audience", "", "Audience bearer tokens must be issued for (overrides FLWD_OIDC_AUDIENCE)")
+	cmd.Flags().StringVar(&oidcCfg.JWKSURL, "oidc-jwks-url", "", "JWKS URL to fetch signing keys from instead of the issuer's discovery document (overrides FLWD_OIDC_JWKS_URL)")
+	cmd.Flags().DurationVar(&oidcCfg.ClockSkew, "oidc-clock-skew", 0, "Leeway on token exp, nbf and iat (default 1m; overrides FLWD_OIDC_CLOCK_SKEW)")
+	cmd.Flags().StringVar(&rbacPolicy, "rbac-policy", "", "YAML file defining roles and per-job and per-source access rules for principals (overrides FLWD_RBAC_POLICY)")
 	cmd.Flags().BoolVar(&devMode, "dev", false, "Enable development defaults (relaxed auth, CORS)")
 	cmd.Flags().StringVar(&logMode, "log", "text", "Log output format (text|json)")
 	cmd.Flags().StringVar(&profile, "profile", "", "Security profile (secure|permissive|disabled); overrides FLWD_PROFILE")
-	cmd.Flags().BoolVar(&metricsEnabled, "metrics", true, "Expose Prometheus /metrics endpoint")
+	cmd.Flags().BoolVar(&metricsEnabled, "metrics", true, "Expose Prometheus /metrics endpoint (overrides FLWD_METRICS)")
+	cmd.Flags().DurationVar(&snapshotEvery, "metrics-snapshot-interval", 0, "How often metric counters are saved so totals survive restarts (default 1m; negative disables; overrides FLWD_METRICS_SNAPSHOT_INTERVAL)")
 	cmd.Flags().BoolVar(&aliasesPublic, "aliases-public", false, "Expose alias names in API responses (overrides FLWD_ALIASES_PUBLIC)")
+	cmd.Flags().BoolVar(&publicBadges, "public-badges", false, "Serve job status badges without authentication (overrides FLWD_PUBLIC_BADGES)")
 	cmd.Flags().StringSliceVar(&extensionFlags, "extension", nil, "Enable optional extension (repeatable)")
+	cmd.Flags().StringVar(&publicURL, "public-url", "", "External base URL used in links reported to forges (overrides FLWD_PUBLIC_URL)")
+	cmd.Flags().StringVar(&smtp.Addr, "smtp-addr", "", "SMTP relay host:port for job email notifications (overrides FLWD_SMTP_ADDR)")
+	cmd.Flags().StringVar(&smtp.From, "smtp-from", "", "Sender address for notification emails (overrides FLWD_SMTP_FROM)")
+	cmd.Flags().StringVar(&smtp.Username, "smtp-username", "", "SMTP auth username (overrides FLWD_SMTP_USERNAME)")
+	cmd.Flags().StringVar(&smtp.PasswordRef, "smtp-password-ref", "", "Secret reference for the SMTP password, e.g. env:SMTP_PASSWORD (overrides FLWD_SMTP_PASSWORD_REF)")
+	cmd.Flags().StringVar(&smtp.TLS, "smtp-tls", "", "SMTP transport security (starttls|tls|none; default starttls; overrides FLWD_SMTP_TLS)")
+	cmd.Flags().StringVar(&vault.Addr, "vault-addr", "", "HashiCorp Vault URL that vault: references of secret args are read from (overrides FLWD_VAULT_ADDR)")
+	cmd.Flags().StringVar(&vault.Mount, "vault-mount", "", "Mount path of the Vault KV version 2 engine (default secret; overrides FLWD_VAULT_MOUNT)")
+	cmd.Flags().StringVar(&vault.Namespace, "vault-namespace", "", "Vault Enterprise namespace (overrides FLWD_VAULT_NAMESPACE)")
+	cmd.Flags().StringVar(&vault.TokenRef, "vault-token-ref", "", "Secret reference for the Vault token, e.g. env:VAULT_TOKEN (overrides FLWD_VAULT_TOKEN_REF)")
+	cmd.Flags().StringArrayVar(&runHooks, "run-hook", nil, "URL to POST or command to run on every run start and finish (repeatable; overrides comma-separated FLWD_RUN_HOOKS)")
+	cmd.Flags().DurationVar(&runHookTimeout, "run-hook-timeout", 0, "Timeout for each run hook delivery (default 10s; overrides FLWD_RUN_HOOK_TIMEOUT)")
+	cmd.Flags().StringArrayVar(&webhooks.URLs, "webhook", nil, "URL to POST signed run lifecycle and policy events to (repeatable; overrides comma-separated FLWD_WEBHOOKS)")
+	cmd.Flags().StringVar(&webhooks.SecretRef, "webhook-secret-ref", "", "Secret reference for the webhook HMAC key, e.g. env:FLWD_HOOK_KEY (overrides FLWD_WEBHOOK_SECRET_REF)")
+	cmd.Flags().StringSliceVar(&webhooks.Events, "webhook-events", nil, "Events sent to webhooks: run.start, run.finish, run.canceled, policy.decision (default all; overrides FLWD_WEBHOOK_EVENTS)")
+	cmd.Flags().StringVar(&admission.URL, "admission-webhook", "", "URL to POST each candidate run plan to before admission; it may deny the run or add findings (overrides FLWD_ADMISSION_WEBHOOK)")
+	cmd.Flags().DurationVar(&admission.Timeout, "admission-webhook-timeout", 0, "Timeout for each admission webhook call (default 5s; overrides FLWD_ADMISSION_WEBHOOK_TIMEOUT)")
+	cmd.Flags().BoolVar(&admission.FailOpen, "admission-webhook-fail-open", false, "Admit runs with a warning when the admission webhook is unreachable instead of refusing them (overrides FLWD_ADMISSION_WEBHOOK_FAIL_OPEN)")
+	cmd.Flags().DurationVar(&runArchive.HotRetention, "run-hot-retention", 0, "Archive finished runs older than this to cold storage; 0 keeps all runs hot (overrides FLWD_RUN_HOT_RETENTION)")
+	cmd.Flags().StringVar(&runArchive.Dir, "run-archive-dir", "", "Directory holding archived runs (default <data dir>/archive; overrides FLWD_RUN_ARCHIVE_DIR)")
+	cmd.Flags().IntVar(&defaultPerPage, "default-per-page", 0, "Page size of GET /runs and GET /jobs when per_page is omitted (default 50; overrides FLWD_DEFAULT_PER_PAGE)")
+	cmd.Flags().IntVar(&maxPerPage, "max-per-page", 0, "Largest per_page accepted by GET /runs and GET /jobs (default 200; overrides FLWD_MAX_PER_PAGE)")
+	cmd.Flags().IntVar(&maxLogLine, "max-log-line-bytes", 0, "Truncate longer step output lines in step.log events (default 16384; negative disables; overrides FLWD_MAX_LOG_LINE_BYTES)")
+	cmd.Flags().BoolVar(&stripANSI, "strip-ansi-logs", false, "Remove ANSI color and cursor codes from stored stdout and stderr; step.log events keep them (overrides FLWD_STRIP_ANSI_LOGS)")
+	cmd.Flags().IntVar(&artifactBytes, "max-artifact-bytes", 0, "Largest file a run publishes as an artifact; larger files are not indexed (default 100 MiB; overrides FLWD_MAX_ARTIFACT_BYTES)")
+	cmd.Flags().IntVar(&runArtifacts, "max-run-artifacts-bytes", 0, "Total size of the artifacts a run publishes (default 1 GiB; overrides FLWD_MAX_RUN_ARTIFACTS_BYTES)")
+	cmd.Flags().IntVar(&journalBytes, "journal-max-bytes", 0, "Size budget of the event journal used to resume event streams; the oldest events are evicted beyond it (default 64 MiB; overrides FLWD_JOURNAL_MAX_BYTES)")
+	cmd.Flags().DurationVar(&journalRetain, "journal-retention", 0, "Drop journaled events of runs quiet for longer than this; 0 keeps them until evicted by size (overrides FLWD_JOURNAL_RETENTION)")
+	cmd.Flags().Float64Var(&flakiness.Threshold, "flaky-threshold", 0, "Share of outcome flips between a job's recent finished runs, 0-1, at which it is flagged as flaky; 0 disables detection (overrides FLWD_FLAKY_THRESHOLD)")
+	cmd.Flags().IntVar(&flakiness.Window, "flaky-window", 0, "Recent finished runs per job scored for flakiness (default 10; overrides FLWD_FLAKY_WINDOW)")
+	cmd.Flags().StringVar(&flakiness.Action, "flaky-action", "", "What happens to new runs of flaky jobs: flag, retry or acknowledge (default flag; overrides FLWD_FLAKY_ACTION)")
+	cmd.Flags().IntVar(&maxParallel, "max-parallel-steps", 0, "Independent DAG steps of a run executed at once (default 1; overrides FLWD_MAX_PARALLEL_STEPS)")
+	cmd.Flags().IntVar(&eventStreams.PerPrincipal, "max-streams-per-principal", 0, "Open event streams allowed per principal before 429 (default 32; negative removes the cap; overrides FLWD_MAX_STREAMS_PER_PRINCIPAL)")
+	cmd.Flags().IntVar(&eventStreams.PerRun, "max-streams-per-run", 0, "Open event streams allowed per run before 429 (default 100; negative removes the cap; overrides FLWD_MAX_STREAMS_PER_RUN)")
+	cmd.Flags().BoolVar(&readOnly, "read-only", false, "Start refusing mutating API requests with 503; reads and event streams keep working (overrides FLWD_READ_ONLY)")
+	cmd.Flags().BoolVar(&offlineVerify, "offline-verification", false, "Verify OCI source signatures only against offline bundles supplied with each source; the secure profile rejects sources without one (overrides FLWD_OFFLINE_VERIFICATION)")
+	cmd.Flags().DurationVar(&reverifyEvery, "source-reverify-interval", 0, "How often OCI sources are re-checked against registry and signature policy; failures quarantine the source (default 6h; negative disables; overrides FLWD_SOURCE_REVERIFY_INTERVAL)")
 
 	return cmd
 }
 
 func resolveAliasesPublic(flagValue bool, cmd *cobra.Command) bool {
-	if cmd.Flags().Changed("aliases-public") {
+	return resolveBoolFlag(flagValue, "aliases-public", "FLWD_ALIASES_PUBLIC", cmd)
+}
+
+// resolveBoolFlag returns the flag value when set, otherwise the boolean
+// spelled by the environment variable, defaulting to false.
+func resolveBoolFlag(flagValue bool, flag, envVar string, cmd *cobra.Command) bool {
+	if cmd.Flags().Changed(flag) {
 		return flagValue
 	}
-	if env := os.Getenv("FLWD_ALIASES_PUBLIC"); env != "" {
+	if env := os.Getenv(envVar); env != "" {
 		switch strings.ToLower(strings.TrimSpace(env)) {
 		case "1", "true", "yes", "on":
 			return true
@@ -89,6 +274,267 @@ func resolveAliasesPublic(flagValue bool, cmd *cobra.Command) bool {
 	return false
 }
 
+// resolveSMTP fills SMTP settings not given as flags from FLWD_SMTP_* env vars.
+func resolveSMTP(flags server.SMTPConfig, cmd *cobra.Command) server.SMTPConfig {
+	out := flags
+	for _, field := range []struct {
+		flag, env string
+		dst       *string
+	}{
+		{"smtp-addr", "FLWD_SMTP_ADDR", &out.Addr},
+		{"smtp-from", "FLWD_SMTP_FROM", &out.From},
+		{"smtp-username", "FLWD_SMTP_USERNAME", &out.Username},
+		{"smtp-password-ref", "FLWD_SMTP_PASSWORD_REF", &out.PasswordRef},
+		{"smtp-tls", "FLWD_SMTP_TLS", &out.TLS},
+	} {
+		if !cmd.Flags().Changed(field.flag) {
+			*field.dst = strings.TrimSpace(os.Getenv(field.env))
+		}
+	}
+	return out
+}
+
+// resolveOIDC fills OIDC settings not given as flags from FLWD_OIDC_* env
+// vars and builds the token verifier, or returns nil without an issuer.
+func resolveOIDC(flags oidc.Config, cmd *cobra.Command) (*oidc.Verifier, error) {
+	out := flags
+	for _, field := range []struct {
+		flag, env string
+		dst       *string
+	}{
+		{"oidc-issuer", "FLWD_OIDC_ISSUER", &out.Issuer},
+		{"oidc-audience", "FLWD_OIDC_AUDIENCE", &out.Audience},
+		{"oidc-jwks-url", "FLWD_OIDC_JWKS_URL", &out.JWKSURL},
+	} {
+		if !cmd.Flags().Changed(field.flag) {
+			*field.dst = strings.TrimSpace(os.Getenv(field.env))
+		}
+	}
+	skew, err := resolveDurationFlag(flags.ClockSkew, "oidc-clock-skew", "FLWD_OIDC_CLOCK_SKEW", cmd)
+	if err != nil {
+		return nil, err
+	}
+	out.ClockSkew = skew
+	if out.Issuer == "" {
+		return nil, nil
+	}
+	return oidc.NewVerifier(out)
+}
+
+// resolveVault fills Vault settings not given as flags from FLWD_VAULT_*
+// env vars.
+func resolveVault(flags server.VaultConfig, cmd *cobra.Command) server.VaultConfig {
+	out := flags
+	for _, field := range []struct {
+		flag, env string
+		dst       *string
+	}{
+		{"vault-addr", "FLWD_VAULT_ADDR", &out.Addr},
+		{"vault-mount", "FLWD_VAULT_MOUNT", &out.Mount},
+		{"vault-namespace", "FLWD_VAULT_NAMESPACE", &out.Namespace},
+		{"vault-token-ref", "FLWD_VAULT_TOKEN_REF", &out.TokenRef},
+	} {
+		if !cmd.Flags().Changed(field.flag) {
+			*field.dst = strings.TrimSpace(os.Getenv(field.env))
+		}
+	}
+	return out
+}
+
+// resolveRunHooks fills run hook settings not given as flags from
+// FLWD_RUN_HOOKS and FLWD_RUN_HOOK_TIMEOUT.
+func resolveRunHooks(targets []string, timeout time.Duration, cmd *cobra.Command) (server.RunHooksConfig, error) {
+	out := server.RunHooksConfig{Targets: targets, Timeout: timeout}
+	if !cmd.Flags().Changed("run-hook") {
+		out.Targets = nil
+		for _, target := range strings.Split(os.Getenv("FLWD_RUN_HOOKS"), ",") {
+			if target = strings.TrimSpace(target); target != "" {
+				out.Targets = append(out.Targets, target)
+			}
+		}
+	}
+	if !cmd.Flags().Changed("run-hook-timeout") {
+		if env := strings.TrimSpace(os.Getenv("FLWD_RUN_HOOK_TIMEOUT")); env != "" {
+			d, err := time.ParseDuration(env)
+			if err != nil {
+				return out, fmt.Errorf("invalid FLWD_RUN_HOOK_TIMEOUT: %w", err)
+			}
+			out.Timeout = d
+		}
+	}
+	return out, nil
+}
+
+// resolveWebhooks fills webhook settings not given as flags from
+// FLWD_WEBHOOKS, FLWD_WEBHOOK_SECRET_REF and FLWD_WEBHOOK_EVENTS.
+func resolveWebhooks(cfg server.WebhooksConfig, cmd *cobra.Command) server.WebhooksConfig {
+	splitEnv := func(name string) []string {
+		var out []string
+		for _, item := range strings.Split(os.Getenv(name), ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+		return out
+	}
+	if !cmd.Flags().Changed("webhook") {
+		cfg.URLs = splitEnv("FLWD_WEBHOOKS")
+	}
+	if !cmd.Flags().Changed("webhook-secret-ref") {
+		cfg.SecretRef = strings.TrimSpace(os.Getenv("FLWD_WEBHOOK_SECRET_REF"))
+	}
+	if !cmd.Flags().Changed("webhook-events") {
+		cfg.Events = splitEnv("FLWD_WEBHOOK_EVENTS")
+	}
+	return cfg
+}
+
+// resolveAdmissionWebhook fills admission webhook settings not given as
+// flags from FLWD_ADMISSION_WEBHOOK, FLWD_ADMISSION_WEBHOOK_TIMEOUT and
+// FLWD_ADMISSION_WEBHOOK_FAIL_OPEN.
+func resolveAdmissionWebhook(cfg handlers.AdmissionWebhookConfig, cmd *cobra.Command) (handlers.AdmissionWebhookConfig, error) {
+	if !cmd.Flags().Changed("admission-webhook") {
+		cfg.URL = strings.TrimSpace(os.Getenv("FLWD_ADMISSION_WEBHOOK"))
+	}
+	timeout, err := resolveDurationFlag(cfg.Timeout, "admission-webhook-timeout", "FLWD_ADMISSION_WEBHOOK_TIMEOUT", cmd)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Timeout = timeout
+	cfg.FailOpen = resolveBoolFlag(cfg.FailOpen, "admission-webhook-fail-open", "FLWD_ADMISSION_WEBHOOK_FAIL_OPEN", cmd)
+	return cfg, nil
+}
+
+// resolveRunArchive fills run archive settings not given as flags from
+// FLWD_RUN_HOT_RETENTION and FLWD_RUN_ARCHIVE_DIR.
+func resolveRunArchive(cfg server.RunArchiveConfig, cmd *cobra.Command) (server.RunArchiveConfig, error) {
+	if !cmd.Flags().Changed("run-hot-retention") {
+		if env := strings.TrimSpace(os.Getenv("FLWD_RUN_HOT_RETENTION")); env != "" {
+			d, err := time.ParseDuration(env)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid FLWD_RUN_HOT_RETENTION: %w", err)
+			}
+			cfg.HotRetention = d
+		}
+	}
+	if !cmd.Flags().Changed("run-archive-dir") {
+		cfg.Dir = strings.TrimSpace(os.Getenv("FLWD_RUN_ARCHIVE_DIR"))
+	}
+	return cfg, nil
+}
+
+// resolveMetricsSnapshotInterval falls back to FLWD_METRICS_SNAPSHOT_INTERVAL
+// when the flag is not given.
+func resolveMetricsSnapshotInterval(interval time.Duration, cmd *cobra.Command) (time.Duration, error) {
+	return resolveDurationFlag(interval, "metrics-snapshot-interval", "FLWD_METRICS_SNAPSHOT_INTERVAL", cmd)
+}
+
+// resolveDurationFlag returns the flag value when set, otherwise the duration
+// parsed from envVar, otherwise the flag default.
+func resolveDurationFlag(value time.Duration, flag, envVar string, cmd *cobra.Command) (time.Duration, error) {
+	if cmd.Flags().Changed(flag) {
+		return value, nil
+	}
+	if env := strings.TrimSpace(os.Getenv(envVar)); env != "" {
+		d, err := time.ParseDuration(env)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %w", envVar, err)
+		}
+		return d, nil
+	}
+	return value, nil
+}
+
+// resolveEventStreams fills stream limits not given as flags from
+// FLWD_MAX_STREAMS_PER_PRINCIPAL and FLWD_MAX_STREAMS_PER_RUN.
+func resolveEventStreams(limits handlers.StreamLimits, cmd *cobra.Command) (handlers.StreamLimits, error) {
+	for _, field := range []struct {
+		flag, env string
+		dst       *int
+	}{
+		{"max-streams-per-principal", "FLWD_MAX_STREAMS_PER_PRINCIPAL", &limits.PerPrincipal},
+		{"max-streams-per-run", "FLWD_MAX_STREAMS_PER_RUN", &limits.PerRun},
+	} {
+		if cmd.Flags().Changed(field.flag) {
+			continue
+		}
+		if env := strings.TrimSpace(os.Getenv(field.env)); env != "" {
+			n, err := strconv.Atoi(env)
+			if err != nil {
+				return limits, fmt.Errorf("invalid %s: %w", field.env, err)
+			}
+			*field.dst = n
+		}
+	}
+	return limits, nil
+}
+
+// resolveFlakiness fills flaky job settings not given as flags from
+// FLWD_FLAKY_THRESHOLD, FLWD_FLAKY_WINDOW and FLWD_FLAKY_ACTION.
+func resolveFlakiness(policy handlers.FlakinessPolicy, cmd *cobra.Command) (handlers.FlakinessPolicy, error) {
+	if env := strings.TrimSpace(os.Getenv("FLWD_FLAKY_THRESHOLD")); env != "" && !cmd.Flags().Changed("flaky-threshold") {
+		v, err := strconv.ParseFloat(env, 64)
+		if err != nil {
+			return policy, fmt.Errorf("invalid FLWD_FLAKY_THRESHOLD: %w", err)
+		}
+		policy.Threshold = v
+	}
+	window, err := resolveIntFlag(policy.Window, "flaky-window", "FLWD_FLAKY_WINDOW", cmd)
+	if err != nil {
+		return policy, err
+	}
+	policy.Window = window
+	if env := strings.TrimSpace(os.Getenv("FLWD_FLAKY_ACTION")); env != "" && !cmd.Flags().Changed("flaky-action") {
+		policy.Action = env
+	}
+	policy.Action = strings.ToLower(strings.TrimSpace(policy.Action))
+	if err := policy.Validate(); err != nil {
+		return policy, err
+	}
+	return policy, nil
+}
+
+// resolvePagination fills per-page bounds not given as flags from
+// FLWD_DEFAULT_PER_PAGE and FLWD_MAX_PER_PAGE.
+func resolvePagination(defaultPerPage, maxPerPage int, cmd *cobra.Command) (int, int, error) {
+	for _, field := range []struct {
+		flag, env string
+		dst       *int
+	}{
+		{"default-per-page", "FLWD_DEFAULT_PER_PAGE", &defaultPerPage},
+		{"max-per-page", "FLWD_MAX_PER_PAGE", &maxPerPage},
+	} {
+		if cmd.Flags().Changed(field.flag) {
+			continue
+		}
+		if env := strings.TrimSpace(os.Getenv(field.env)); env != "" {
+			n, err := strconv.Atoi(env)
+			if err != nil {
+				return 0, 0, fmt.Errorf("invalid %s: %w", field.env, err)
+			}
+			*field.dst = n
+		}
+	}
+	return defaultPerPage, maxPerPage, nil
+}
+
+// resolveIntFlag returns the flag value when set, otherwise the integer
+// parsed from envVar, otherwise the flag default.
+func resolveIntFlag(value int, flag, envVar string, cmd *cobra.Command) (int, error) {
+	if cmd.Flags().Changed(flag) {
+		return value, nil
+	}
+	env := strings.TrimSpace(os.Getenv(envVar))
+	if env == "" {
+		return value, nil
+	}
+	n, err := strconv.Atoi(env)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", envVar, err)
+	}
+	return n, nil
+}
+
 func resolveExtensions(flags []string, cmd *cobra.Command) map[string]bool {
 	enabled := map[string]bool{}
 	values := append([]string{}, flags...)
@@ -16,6 +16,7 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/flowd-org/flowd/client"
 	"github.com/spf13/cobra"
 )
 
@@ -30,9 +31,13 @@ func NewSourcesCmd() *cobra.Command {
 	}
 	cmd.PersistentFlags().String("server", defaultServer, "Runner API base URL (or set FLWD_API)")
 	cmd.PersistentFlags().String("token", os.Getenv("FLWD_TOKEN"), "Bearer token for Runner API (or set FLWD_TOKEN)")
+	addVersionCheckFlag(cmd)
 	cmd.AddCommand(newSourcesListCmd())
 	cmd.AddCommand(newSourcesAddCmd())
 	cmd.AddCommand(newSourcesRemoveCmd())
+	cmd.AddCommand(newSourcesTrustCmd())
+	cmd.AddCommand(newSourcesQuarantineCmd())
+	cmd.AddCommand(newSourcesReleaseCmd())
 	return cmd
 }
 
@@ -52,6 +57,9 @@ func resolveSourcesClient(cmd *cobra.Command) (*sourcesClient, error) {
 		return nil, err
 	}
 	base := normalizeBaseURL(server)
+	if err := checkServerVersion(cmd, client.New(base, client.WithToken(token))); err != nil {
+		return nil, err
+	}
 	return &sourcesClient{
 		base:       base,
 		token:      strings.TrimSpace(token),
@@ -70,6 +78,7 @@ func (c *sourcesClient) do(ctx context.Context, method, path string, body []byte
 		return nil, err
 	}
 	req.Header.Set("Accept", "application/json")
+	req.Header.Set(client.HeaderAPIVersion, client.APIVersion)
 	if len(body) > 0 {
 		req.Header.Set("Content-Type", "application/json")
 	}
@@ -112,9 +121,13 @@ func newSourcesListCmd() *cobra.Command {
 				return nil
 			}
 			tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-			fmt.Fprintln(tw, "NAME\tTYPE\tREF\tDIGEST\tPULL POLICY\tEXPOSE")
+			fmt.Fprintln(tw, "NAME\tTYPE\tREF\tDIGEST\tPULL POLICY\tEXPOSE\tTRUST")
 			for _, src := range payload {
-				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", src.Name, src.Type, src.Ref, src.Digest, src.PullPolicy, src.Expose)
+				trust := src.TrustLevel
+				if src.Quarantine != nil {
+					trust += " (quarantined)"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", src.Name, src.Type, src.Ref, src.Digest, src.PullPolicy, src.Expose, trust)
 			}
 			tw.Flush()
 			return nil
@@ -135,6 +148,7 @@ func newSourcesAddCmd() *cobra.Command {
 		expose           string
 		verifySignatures bool
 		jsonOut          bool
+		trustLevel       string
 	)
 	cmd := &cobra.Command{
 		Use:   "add",
@@ -178,6 +192,9 @@ func newSourcesAddCmd() *cobra.Command {
 			if verifySignatures {
 				payload["verify_signatures"] = true
 			}
+			if strings.TrimSpace(trustLevel) != "" {
+				payload["trust_level"] = strings.TrimSpace(trustLevel)
+			}
 			body, err := json.Marshal(payload)
 			if err != nil {
 				return err
@@ -211,6 +228,139 @@ func newSourcesAddCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&verifySignatures, "verify-signatures", false, "Require signature verification for OCI sources")
 	cmd.Flags().StringVar(&expose, "expose", "", "Alias exposure level (none|read|readwrite)")
 	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output API response as JSON")
+	cmd.Flags().StringVar(&trustLevel, "trust-level", "", "Trust level for a new source (untrusted|limited|trusted; default trusted)")
+	return cmd
+}
+
+func newSourcesTrustCmd() *cobra.Command {
+	var reason string
+	cmd := &cobra.Command{
+		Use:   "trust <name> <untrusted|limited|trusted>",
+		Short: "Change a source's trust level via the Runner API (needs sources:trust)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := resolveSourcesClient(cmd)
+			if err != nil {
+				return err
+			}
+			name := strings.TrimSpace(args[0])
+			if name == "" {
+				return errors.New("source name is required")
+			}
+			body, err := json.Marshal(map[string]string{
+				"trust_level": strings.TrimSpace(args[1]),
+				"reason":      reason,
+			})
+			if err != nil {
+				return err
+			}
+			resp, err := client.do(cmd.Context(), http.MethodPost, "/sources/"+urlEscape(name)+":trust", body)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return apiError(resp)
+			}
+			var src apiSource
+			if err := json.NewDecoder(resp.Body).Decode(&src); err != nil {
+				return err
+			}
+			fmt.Printf("Source %s is now %s\n", src.Name, src.TrustLevel)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&reason, "reason", "", "Reason recorded in the source's trust history")
+	return cmd
+}
+
+func newSourcesQuarantineCmd() *cobra.Command {
+	var (
+		reason     string
+		cancelRuns bool
+	)
+	cmd := &cobra.Command{
+		Use:   "quarantine <name>",
+		Short: "Block runs and plans from a source via the Runner API (needs sources:trust)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := resolveSourcesClient(cmd)
+			if err != nil {
+				return err
+			}
+			name := strings.TrimSpace(args[0])
+			if name == "" {
+				return errors.New("source name is required")
+			}
+			if strings.TrimSpace(reason) == "" {
+				return errors.New("--reason is required")
+			}
+			body, err := json.Marshal(map[string]any{
+				"reason":      reason,
+				"cancel_runs": cancelRuns,
+			})
+			if err != nil {
+				return err
+			}
+			resp, err := client.do(cmd.Context(), http.MethodPost, "/sources/"+urlEscape(name)+":quarantine", body)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return apiError(resp)
+			}
+			var out struct {
+				AffectedRuns []string `json:"affected_runs"`
+				CanceledRuns []string `json:"canceled_runs"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+				return err
+			}
+			fmt.Printf("Source %s quarantined (%d runs annotated, %d canceled)\n", name, len(out.AffectedRuns), len(out.CanceledRuns))
+			for _, id := range out.CanceledRuns {
+				fmt.Printf("  canceled %s\n", id)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&reason, "reason", "", "Why the source is quarantined (required)")
+	cmd.Flags().BoolVar(&cancelRuns, "cancel-runs", false, "Also cancel the source's queued and running runs")
+	return cmd
+}
+
+func newSourcesReleaseCmd() *cobra.Command {
+	var reason string
+	cmd := &cobra.Command{
+		Use:   "release <name>",
+		Short: "Lift a source's quarantine via the Runner API (needs sources:trust)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := resolveSourcesClient(cmd)
+			if err != nil {
+				return err
+			}
+			name := strings.TrimSpace(args[0])
+			if name == "" {
+				return errors.New("source name is required")
+			}
+			body, err := json.Marshal(map[string]string{"reason": reason})
+			if err != nil {
+				return err
+			}
+			resp, err := client.do(cmd.Context(), http.MethodPost, "/sources/"+urlEscape(name)+":release", body)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return apiError(resp)
+			}
+			fmt.Printf("Source %s released from quarantine\n", name)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&reason, "reason", "", "Reason recorded in the server log")
 	return cmd
 }
 
@@ -254,6 +404,11 @@ type apiSource struct {
 	Expose           string         `json:"expose"`
 	VerifySignatures bool           `json:"verify_signatures"`
 	Provenance       map[string]any `json:"provenance"`
+	TrustLevel       string         `json:"trust_level"`
+	Quarantine       *struct {
+		Code   string `json:"code"`
+		Reason string `json:"reason"`
+	} `json:"quarantine"`
 }
 
 func apiError(resp *http.Response) error {
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/client"
+	"github.com/flowd-org/flowd/internal/paths"
+	"github.com/flowd-org/flowd/internal/supportbundle"
+	"github.com/flowd-org/flowd/internal/types"
+	"github.com/spf13/cobra"
+)
+
+func NewSupportBundleCmd() *cobra.Command {
+	var (
+		runID   string
+		output  string
+		jobDir  string
+		server  string
+		token   string
+		jsonOut bool
+	)
+	cmd := &cobra.Command{
+		Use:   ":support-bundle",
+		Short: "Write a redacted archive of a run for bug reports",
+		Long: "Collect a run's plan, receipt, logs, outputs and job config into a .tar.gz " +
+			"suitable for attaching to bug reports. JSON documents keep only allow-listed " +
+			"fields, and secret arguments, credential-shaped strings and URL passwords are " +
+			"replaced with [secret]. With --server the run record and event journal are " +
+			"fetched from serve mode as well. Review the archive before sharing it.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(runID) == "" {
+				return fmt.Errorf("[x] --run is required")
+			}
+			src := supportbundle.Source{RunID: runID, RunDir: paths.RunDir(runID), JobDir: jobDir}
+			if src.JobDir == "" {
+				src.JobDir = bundleJobDir(src.RunDir)
+			}
+			if server != "" {
+				if err := fetchRemoteRun(cmd, client.New(normalizeBaseURL(server), client.WithToken(token)), &src); err != nil {
+					return err
+				}
+			}
+			if token != "" {
+				src.Secrets = append(src.Secrets, token)
+			}
+
+			if output == "" {
+				output = "support-" + runID + ".tar.gz"
+			}
+			f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+			if err != nil {
+				return fmt.Errorf("[x] create bundle: %w", err)
+			}
+			manifest, err := supportbundle.Write(f, src, time.Now())
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				_ = os.Remove(output)
+				return fmt.Errorf("[x] support bundle: %w", err)
+			}
+
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(struct {
+					Path string `json:"path"`
+					supportbundle.Manifest
+				}{output, manifest})
+			}
+			printBundle(cmd.OutOrStdout(), output, manifest)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&runID, "run", "", "Run ID to bundle")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Archive path (default support-<run>.tar.gz)")
+	cmd.Flags().StringVar(&jobDir, "job-dir", "", "Job directory to include config from (default: local source recorded in the receipt)")
+	cmd.Flags().StringVar(&server, "server", "", "Runner API base URL to fetch the run record and events from")
+	cmd.Flags().StringVar(&token, "token", os.Getenv("FLWD_TOKEN"), "Bearer token for Runner API (or set FLWD_TOKEN)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the bundle manifest as JSON")
+	return cmd
+}
+
+// bundleJobDir finds the job directory from the run's receipt, as
+// :verify-run does.
+func bundleJobDir(runDir string) string {
+	data, err := os.ReadFile(filepath.Join(runDir, "receipt.json"))
+	if err != nil {
+		return ""
+	}
+	var receipt types.RunReceipt
+	if json.Unmarshal(data, &receipt) != nil {
+		return ""
+	}
+	return receiptJobDir(receipt)
+}
+
+// fetchRemoteRun adds the server's run record and, when the export extension
+// is enabled, its event journal to src.
+func fetchRemoteRun(cmd *cobra.Command, api *client.Client, src *supportbundle.Source) error {
+	if err := checkServerVersion(cmd, api); err != nil {
+		return err
+	}
+	run, err := api.Runs.Get(cmd.Context(), src.RunID)
+	if err != nil {
+		return fmt.Errorf("[x] fetch run: %w", err)
+	}
+	if src.Run, err = json.Marshal(run); err != nil {
+		return err
+	}
+	src.Events, err = api.Runs.ExportEvents(cmd.Context(), src.RunID)
+	if err != nil {
+		if !client.IsStatus(err, http.StatusNotFound) && !client.IsStatus(err, http.StatusGone) {
+			return fmt.Errorf("[x] fetch events: %w", err)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "[!] run events not included: %v\n", err)
+	}
+	return nil
+}
+
+func printBundle(w io.Writer, path string, m supportbundle.Manifest) {
+	fmt.Fprintf(w, "[+] Wrote support bundle %s (%d secret(s) redacted)\n", path, m.Redactions)
+	for _, f := range m.Files {
+		fmt.Fprintf(w, "    %s\n", f)
+	}
+	for _, s := range m.Skipped {
+		fmt.Fprintf(w, "[!] skipped %s\n", s)
+	}
+}
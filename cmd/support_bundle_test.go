@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/paths"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+func TestSupportBundleUsesReceiptJobDir(t *testing.T) {
+	tmp := t.TempDir()
+	paths.SetDataDirOverride(filepath.Join(tmp, "data"))
+	t.Cleanup(func() { paths.SetDataDirOverride("") })
+
+	jobDir := filepath.Join(tmp, "job")
+	if err := os.MkdirAll(filepath.Join(jobDir, "config.d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(jobDir, "config.d", "config.yaml"), []byte("version: v1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runDir := paths.RunDir("run-1")
+	if err := os.MkdirAll(runDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(types.RunReceipt{RunID: "run-1", JobID: "demo", Status: "failed", Provenance: localReceiptProvenance("demo", jobDir)})
+	if err := os.WriteFile(filepath.Join(runDir, "receipt.json"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(tmp, "bundle.tar.gz")
+	cmd := NewSupportBundleCmd()
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetArgs([]string{"--run", "run-1", "--output", out, "--token", "", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("support bundle: %v", err)
+	}
+	var manifest struct {
+		Path  string   `json:"path"`
+		Files []string `json:"files"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &manifest); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if manifest.Path != out || len(manifest.Files) != 2 || manifest.Files[0] != "receipt.json" || manifest.Files[1] != "config.yaml" {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+	if _, err := os.Stat(out); err != nil {
+		t.Fatalf("expected bundle at %s: %v", out, err)
+	}
+}
@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/executor"
+	"github.com/flowd-org/flowd/internal/policy/verify"
+	"github.com/flowd-org/flowd/internal/types"
+	"github.com/spf13/cobra"
+)
+
+const (
+	checkPassed  = "passed"
+	checkFailed  = "failed"
+	checkSkipped = "skipped"
+)
+
+// runVerdict is the outcome of :verify-run. Each check is passed, failed or
+// skipped; the run verifies only when no check failed.
+type runVerdict struct {
+	RunID     string       `json:"run_id"`
+	JobID     string       `json:"job_id"`
+	Status    string       `json:"status"`
+	Signature verdictCheck `json:"signature"`
+	Scripts   verdictCheck `json:"scripts"`
+	Verified  bool         `json:"verified"`
+}
+
+type verdictCheck struct {
+	Result   string   `json:"result"`
+	Detail   string   `json:"detail,omitempty"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+func NewVerifyRunCmd() *cobra.Command {
+	var (
+		signature string
+		jobDir    string
+		jsonOut   bool
+		opts      verify.BlobOptions
+	)
+	cmd := &cobra.Command{
+		Use:   ":verify-run <receipt.json>",
+		Short: "Verify a run receipt's signature and recorded script hashes",
+		Long: "Verify a run receipt. When a detached signature is present (receipt.json.sig by " +
+			"default) it is checked with `cosign verify-blob`. When the job directory is available " +
+			"the scripts recorded in the receipt are re-hashed and compared. Exits non-zero unless " +
+			"every check that ran passed.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			receiptPath := args[0]
+			data, err := os.ReadFile(receiptPath)
+			if err != nil {
+				return fmt.Errorf("[x] read receipt: %w", err)
+			}
+			var receipt types.RunReceipt
+			if err := json.Unmarshal(data, &receipt); err != nil {
+				return fmt.Errorf("[x] decode receipt: %w", err)
+			}
+
+			verdict := runVerdict{RunID: receipt.RunID, JobID: receipt.JobID, Status: receipt.Status}
+			sigExplicit := signature != ""
+			if !sigExplicit {
+				signature = receiptPath + ".sig"
+			}
+			verdict.Signature = checkReceiptSignature(cmd, receiptPath, signature, sigExplicit, opts)
+
+			if jobDir == "" {
+				jobDir = receiptJobDir(receipt)
+			}
+			verdict.Scripts = checkReceiptScripts(receipt.Scripts, jobDir)
+
+			verdict.Verified = verdict.Signature.Result != checkFailed && verdict.Scripts.Result != checkFailed
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(verdict); err != nil {
+					return err
+				}
+			} else {
+				printVerdict(cmd.OutOrStdout(), verdict)
+			}
+			if !verdict.Verified {
+				return fmt.Errorf("[x] run %s failed verification", receipt.RunID)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&signature, "signature", "", "Detached signature for the receipt (default <receipt>.sig)")
+	cmd.Flags().StringVar(&jobDir, "job-dir", "", "Job directory to re-hash scripts from (default: local source recorded in the receipt)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the verdict as JSON")
+	cmd.Flags().StringVar(&opts.Key, "key", "", "Public key to verify the signature with")
+	cmd.Flags().StringVar(&opts.Certificate, "certificate", "", "Signing certificate for keyless signatures")
+	cmd.Flags().StringVar(&opts.CertificateIdentity, "certificate-identity", "", "Expected identity in the signing certificate")
+	cmd.Flags().StringVar(&opts.CertificateOIDCIssuer, "certificate-oidc-issuer", "", "Expected OIDC issuer of the signing certificate")
+	return cmd
+}
+
+// checkReceiptSignature skips when the default signature file is absent; an
+// explicitly requested signature that cannot be found fails the check.
+func checkReceiptSignature(cmd *cobra.Command, receiptPath, signature string, explicit bool, opts verify.BlobOptions) verdictCheck {
+	if _, err := os.Stat(signature); err != nil {
+		if explicit || !os.IsNotExist(err) {
+			return verdictCheck{Result: checkFailed, Detail: err.Error()}
+		}
+		return verdictCheck{Result: checkSkipped, Detail: "no signature found"}
+	}
+	res, err := verify.NewCosignBlobVerifier(opts).Verify(cmd.Context(), receiptPath, signature)
+	if err != nil {
+		return verdictCheck{Result: checkFailed, Detail: err.Error()}
+	}
+	if !res.Verified {
+		return verdictCheck{Result: checkFailed, Detail: res.Reason}
+	}
+	return verdictCheck{Result: checkPassed, Detail: signature}
+}
+
+func checkReceiptScripts(scripts []types.ScriptDigest, jobDir string) verdictCheck {
+	if len(scripts) == 0 {
+		return verdictCheck{Result: checkSkipped, Detail: "receipt records no scripts"}
+	}
+	if jobDir == "" {
+		return verdictCheck{Result: checkSkipped, Detail: "job directory unknown; pass --job-dir"}
+	}
+	if info, err := os.Stat(jobDir); err != nil || !info.IsDir() {
+		return verdictCheck{Result: checkSkipped, Detail: fmt.Sprintf("job directory %s not available", jobDir)}
+	}
+	errs := executor.CheckScripts(jobDir, scripts)
+	if len(errs) == 0 {
+		return verdictCheck{Result: checkPassed, Detail: fmt.Sprintf("%d script(s) match", len(scripts))}
+	}
+	problems := make([]string, 0, len(errs))
+	for _, err := range errs {
+		problems = append(problems, err.Error())
+	}
+	return verdictCheck{Result: checkFailed, Detail: fmt.Sprintf("%d of %d script(s) differ", len(errs), len(scripts)), Problems: problems}
+}
+
+// receiptJobDir returns the job directory of a local-source receipt. Other
+// source types are checked out elsewhere and need --job-dir.
+func receiptJobDir(receipt types.RunReceipt) string {
+	src, ok := receipt.Provenance["source"].(map[string]interface{})
+	if !ok || src["type"] != "local" {
+		return ""
+	}
+	dir, _ := src["resolved_ref"].(string)
+	if dir == "" || !filepath.IsAbs(dir) {
+		return ""
+	}
+	return dir
+}
+
+func printVerdict(w io.Writer, v runVerdict) {
+	fmt.Fprintf(w, "run:       %s (%s, %s)\n", v.RunID, v.JobID, v.Status)
+	for _, c := range []struct {
+		label string
+		check verdictCheck
+	}{{"signature", v.Signature}, {"scripts", v.Scripts}} {
+		line := fmt.Sprintf("%-10s %s", c.label+":", c.check.Result)
+		if c.check.Detail != "" {
+			line += " (" + c.check.Detail + ")"
+		}
+		fmt.Fprintln(w, line)
+		for _, p := range c.check.Problems {
+			fmt.Fprintf(w, "  - %s\n", p)
+		}
+	}
+	if v.Verified {
+		fmt.Fprintln(w, "verdict:   verified")
+	} else {
+		fmt.Fprintln(w, "verdict:   "+strings.ToUpper(checkFailed))
+	}
+}
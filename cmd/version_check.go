@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/flowd-org/flowd/client"
+	"github.com/spf13/cobra"
+)
+
+// addVersionCheckFlag registers --skip-version-check on a command that talks
+// to a remote server.
+func addVersionCheckFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().Bool("skip-version-check", false, "Do not compare the CLI and server API versions")
+}
+
+// checkServerVersion compares the CLI's API version with the one the server
+// reports on /capabilities. A major mismatch is refused; a lagging minor
+// version or an unknown one only warns. When the check itself cannot run
+// the command proceeds and surfaces any real connection error on its own.
+func checkServerVersion(cmd *cobra.Command, api *client.Client) error {
+	if skip, _ := cmd.Flags().GetBool("skip-version-check"); skip {
+		return nil
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	check, err := api.CheckVersion(ctx)
+	if err != nil {
+		if client.IsStatus(err, http.StatusNotFound) {
+			warnVersion(cmd.ErrOrStderr(), "server does not report capabilities; it may predate API version "+client.APIVersion)
+		}
+		return nil
+	}
+	switch check.Compatibility {
+	case client.Compatible:
+		return nil
+	case client.Incompatible:
+		return fmt.Errorf("[x] %s (server %s); upgrade the older side or pass --skip-version-check", check.Message(), check.ServerVersion)
+	}
+	warnVersion(cmd.ErrOrStderr(), check.Message())
+	return nil
+}
+
+func warnVersion(w io.Writer, msg string) {
+	fmt.Fprintf(w, "[!] %s\n", msg)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flowd-org/flowd/client"
+	"github.com/spf13/cobra"
+)
+
+func TestCheckServerVersion(t *testing.T) {
+	cases := []struct {
+		name     string
+		spec     string
+		status   int
+		skip     bool
+		wantErr  bool
+		wantWarn string
+	}{
+		{name: "matching", spec: client.APIVersion, status: http.StatusOK},
+		{name: "older minor", spec: "1.0.0", status: http.StatusOK, wantWarn: "older than client API"},
+		{name: "major mismatch", spec: "2.0.0", status: http.StatusOK, wantErr: true},
+		{name: "major mismatch skipped", spec: "2.0.0", status: http.StatusOK, skip: true},
+		{name: "no capabilities", status: http.StatusNotFound, wantWarn: "does not report capabilities"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.status != http.StatusOK {
+					w.WriteHeader(tc.status)
+					return
+				}
+				fmt.Fprintf(w, `{"version":"9.9.9","spec_version":%q}`, tc.spec)
+			}))
+			defer srv.Close()
+
+			cmd := &cobra.Command{}
+			addVersionCheckFlag(cmd)
+			if tc.skip {
+				if err := cmd.ParseFlags([]string{"--skip-version-check"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var stderr bytes.Buffer
+			cmd.SetErr(&stderr)
+			err := checkServerVersion(cmd, client.New(srv.URL))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if tc.wantWarn == "" && stderr.Len() > 0 {
+				t.Fatalf("unexpected warning %q", stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tc.wantWarn) {
+				t.Fatalf("expected warning %q, got %q", tc.wantWarn, stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package bench drives run creation against a flowd server and measures
+// creation throughput, time to start and event stream latency.
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flowd-org/flowd/client"
+)
+
+// Config configures a benchmark.
+type Config struct {
+	Client *client.Client
+	// Jobs are submitted round-robin.
+	Jobs []string
+	Args map[string]any
+	// Concurrency is the number of concurrent submitters.
+	Concurrency int
+	// Count is the total number of runs to create.
+	Count int
+	// Watch follows every created run's event stream to measure time to
+	// start and event latency. Without it only creation is measured.
+	Watch bool
+	// WatchTimeout bounds how long each run is followed.
+	WatchTimeout time.Duration
+	Now          func() time.Time
+}
+
+// Report summarises a benchmark.
+type Report struct {
+	Requested     int            `json:"requested"`
+	Created       int            `json:"created"`
+	Failed        int            `json:"failed"`
+	Duration      time.Duration  `json:"duration_ns"`
+	CreatedPerSec float64        `json:"created_per_sec"`
+	Create        Stats          `json:"create_latency"`
+	TimeToStart   Stats          `json:"time_to_start"`
+	SSELatency    Stats          `json:"sse_latency"`
+	Errors        map[string]int `json:"errors,omitempty"`
+}
+
+// Stats is a latency distribution.
+type Stats struct {
+	Samples int           `json:"samples"`
+	P50     time.Duration `json:"p50_ns"`
+	P95     time.Duration `json:"p95_ns"`
+	P99     time.Duration `json:"p99_ns"`
+	Max     time.Duration `json:"max_ns"`
+}
+
+// Run creates cfg.Count runs and returns the measurements. Creation
+// throughput covers only the submission phase; watched runs are awaited
+// afterwards.
+func Run(ctx context.Context, cfg Config) (Report, error) {
+	if cfg.Client == nil || len(cfg.Jobs) == 0 {
+		return Report{}, fmt.Errorf("bench: client and at least one job are required")
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.WatchTimeout <= 0 {
+		cfg.WatchTimeout = 5 * time.Minute
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	var (
+		rec     recorder
+		next    int64 = -1
+		workers sync.WaitGroup
+		watches sync.WaitGroup
+	)
+	start := cfg.Now()
+	for w := 0; w < cfg.Concurrency; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= cfg.Count || ctx.Err() != nil {
+					return
+				}
+				req := client.RunRequest{JobID: cfg.Jobs[i%len(cfg.Jobs)], Args: cfg.Args}
+				submitted := cfg.Now()
+				run, err := cfg.Client.Runs.Create(ctx, req, &client.CreateOptions{MaxAttempts: 1})
+				if err != nil {
+					rec.fail(err)
+					continue
+				}
+				rec.created(cfg.Now().Sub(submitted))
+				if cfg.Watch {
+					watches.Add(1)
+					go func() {
+						defer watches.Done()
+						watch(ctx, cfg, run.ID, submitted, &rec)
+					}()
+				}
+			}
+		}()
+	}
+	workers.Wait()
+	elapsed := cfg.Now().Sub(start)
+	watches.Wait()
+
+	report := rec.report()
+	report.Requested = cfg.Count
+	report.Duration = elapsed
+	if elapsed > 0 {
+		report.CreatedPerSec = float64(report.Created) / elapsed.Seconds()
+	}
+	return report, ctx.Err()
+}
+
+// watch follows a run's events. Time to start runs from submission to the
+// run.start event; event latency compares a run.finish event's finished_at
+// with its arrival, so it assumes the client and server clocks agree.
+func watch(ctx context.Context, cfg Config, runID string, submitted time.Time, rec *recorder) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.WatchTimeout)
+	defer cancel()
+	stream, err := cfg.Client.Runs.Events(ctx, runID, nil)
+	if err != nil {
+		rec.note(fmt.Errorf("events: %w", err))
+		return
+	}
+	defer stream.Close()
+	for stream.Next() {
+		ev := stream.Event()
+		now := cfg.Now()
+		switch ev.Type {
+		case "run.start":
+			rec.started(now.Sub(submitted))
+		case "run.finish":
+			var data struct {
+				FinishedAt time.Time `json:"finished_at"`
+			}
+			if json.Unmarshal(ev.Data, &data) == nil && !data.FinishedAt.IsZero() {
+				rec.delivered(now.Sub(data.FinishedAt))
+			}
+		}
+	}
+	if err := stream.Err(); err != nil {
+		rec.note(fmt.Errorf("events: %w", err))
+	}
+}
+
+type recorder struct {
+	mu          sync.Mutex
+	create      []time.Duration
+	timeToStart []time.Duration
+	sseLatency  []time.Duration
+	failed      int
+	errors      map[string]int
+}
+
+func (r *recorder) created(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.create = append(r.create, d)
+}
+
+func (r *recorder) started(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timeToStart = append(r.timeToStart, d)
+}
+
+func (r *recorder) delivered(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sseLatency = append(r.sseLatency, d)
+}
+
+// fail records a run that could not be created.
+func (r *recorder) fail(err error) {
+	r.note(err)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed++
+}
+
+// note counts an error without failing the run.
+func (r *recorder) note(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.errors == nil {
+		r.errors = map[string]int{}
+	}
+	r.errors[err.Error()]++
+}
+
+func (r *recorder) report() Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Report{
+		Created:     len(r.create),
+		Failed:      r.failed,
+		Create:      summarize(r.create),
+		TimeToStart: summarize(r.timeToStart),
+		SSELatency:  summarize(r.sseLatency),
+		Errors:      r.errors,
+	}
+}
+
+func summarize(samples []time.Duration) Stats {
+	if len(samples) == 0 {
+		return Stats{}
+	}
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	pct := func(p float64) time.Duration {
+		return sorted[int(p*float64(len(sorted)-1))]
+	}
+	return Stats{
+		Samples: len(sorted),
+		P50:     pct(0.50),
+		P95:     pct(0.95),
+		P99:     pct(0.99),
+		Max:     sorted[len(sorted)-1],
+	}
+}
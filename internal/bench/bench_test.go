@@ -0,0 +1,62 @@
+package bench
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/client"
+	"github.com/flowd-org/flowd/internal/testserve"
+)
+
+const testToken = "jobs:read,runs:read,runs:write,events:read"
+
+// demo succeeds without arguments.
+var demo = testserve.Job{
+	ID:     "demo",
+	Config: "version: v1\njob:\n  id: demo\n  name: Demo\ninterpreter: \"/bin/bash\"\n",
+	Script: "#!/usr/bin/env bash\ntrue\n",
+}
+
+func startServe(t *testing.T) *client.Client {
+	t.Helper()
+	return client.New(testserve.Start(t, testToken, demo), client.WithToken(testToken))
+}
+
+func TestRunMeasuresCreatedRuns(t *testing.T) {
+	c := startServe(t)
+	report, err := Run(context.Background(), Config{
+		Client:       c,
+		Jobs:         []string{"demo", "missing"},
+		Concurrency:  3,
+		Count:        6,
+		Watch:        true,
+		WatchTimeout: 10 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Requested != 6 || report.Created != 3 || report.Failed != 3 {
+		t.Fatalf("expected 3 created and 3 failed runs, got %+v", report)
+	}
+	if report.CreatedPerSec <= 0 || report.Create.Samples != 3 {
+		t.Fatalf("expected creation measurements, got %+v", report)
+	}
+	if report.TimeToStart.Samples != 3 || report.SSELatency.Samples != 3 {
+		t.Fatalf("expected watched runs to report start and event latency, got %+v", report)
+	}
+	if len(report.Errors) == 0 {
+		t.Fatalf("expected failed creations to be reported")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	var samples []time.Duration
+	for i := 100; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	s := summarize(samples)
+	if s.Samples != 100 || s.P50 != 50*time.Millisecond || s.P99 != 99*time.Millisecond || s.Max != 100*time.Millisecond {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
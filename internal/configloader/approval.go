@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package configloader
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// validateApproval defaults a job's approval policy to one approval and
+// rejects policies that no set of approvers could satisfy.
+func validateApproval(a *types.ApprovalPolicy) error {
+	if a.Required < 0 {
+		return fmt.Errorf("required must not be negative")
+	}
+	if a.Required == 0 {
+		a.Required = 1
+	}
+	approvers := a.Approvers[:0]
+	for _, p := range a.Approvers {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			return fmt.Errorf("approvers: empty principal")
+		}
+		approvers = append(approvers, p)
+	}
+	a.Approvers = approvers
+	if len(a.Approvers) > 0 && a.Required > len(a.Approvers) {
+		return fmt.Errorf("required exceeds the number of approvers")
+	}
+	return nil
+}
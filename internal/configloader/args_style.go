@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package configloader
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// validateArgsStyle normalises cfg.ArgsStyle. Positional passing needs a
+// stable argument order, so it requires argspec (legacy arguments maps are
+// unordered), allows an array only as the last argument and rejects objects
+// and secrets, which have no positional form that keeps them off the
+// process list.
+func validateArgsStyle(cfg *types.Config) error {
+	cfg.ArgsStyle = strings.ToLower(strings.TrimSpace(cfg.ArgsStyle))
+	switch cfg.ArgsStyle {
+	case "", types.ArgsStyleFlags, types.ArgsStyleStdinJSON:
+		return nil
+	case types.ArgsStylePositional:
+	default:
+		return fmt.Errorf("%q is not one of positional, flags or stdin-json", cfg.ArgsStyle)
+	}
+	if cfg.ArgSpec == nil {
+		return nil
+	}
+	if len(cfg.Arguments) > 1 {
+		return fmt.Errorf("positional args require argspec; legacy arguments have no order")
+	}
+	args := cfg.ArgSpec.Args
+	for i, arg := range args {
+		switch {
+		case arg.Secret || arg.Format == "secret":
+			return fmt.Errorf("secret arg %q cannot be passed positionally", arg.Name)
+		case arg.Type == "object":
+			return fmt.Errorf("object arg %q cannot be passed positionally", arg.Name)
+		case arg.Type == "array" && i != len(args)-1:
+			return fmt.Errorf("array arg %q must be the last positional arg", arg.Name)
+		}
+	}
+	return nil
+}
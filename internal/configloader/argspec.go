@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package configloader
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// validateArgSeverities normalises each arg's severity, which downgrades its
+// rule violations to warnings when set to "warning".
+func validateArgSeverities(spec *types.ArgSpec) error {
+	if spec == nil {
+		return nil
+	}
+	for i, arg := range spec.Args {
+		severity := strings.ToLower(strings.TrimSpace(arg.Severity))
+		switch severity {
+		case "", types.ArgSeverityError, types.ArgSeverityWarning:
+		default:
+			return fmt.Errorf("arg %q: severity %q is not one of error or warning", arg.Name, arg.Severity)
+		}
+		spec.Args[i].Severity = severity
+		spec.Args[i].Deprecated = strings.TrimSpace(arg.Deprecated)
+	}
+	return nil
+}
+
+// validateArgRefs checks that only secret string args declare from and that
+// each reference names a scheme serve mode resolves args from.
+func validateArgRefs(spec *types.ArgSpec) error {
+	if spec == nil {
+		return nil
+	}
+	for i, arg := range spec.Args {
+		ref := strings.TrimSpace(arg.From)
+		if ref == "" {
+			continue
+		}
+		if arg.Type != "string" || (!arg.Secret && arg.Format != "secret") {
+			return fmt.Errorf("arg %q: from is only allowed on secret string args", arg.Name)
+		}
+		if arg.Default != nil {
+			return fmt.Errorf("arg %q: from and default are mutually exclusive", arg.Name)
+		}
+		scheme, name, _ := strings.Cut(ref, ":")
+		if (scheme != "vault" && scheme != "env") || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("arg %q: from %q must be vault:path/to/key or env:NAME", arg.Name, ref)
+		}
+		spec.Args[i].From = ref
+	}
+	return nil
+}
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateArgEnv checks that arg_env only maps scalar, non-secret args of
+// the argspec, which are the ones exported as variables, to valid names.
+// Collisions are checked when the job is planned.
+func validateArgEnv(cfg *types.Config) error {
+	if cfg.ArgEnv == nil {
+		return nil
+	}
+	args := make(map[string]types.Arg)
+	if cfg.ArgSpec != nil {
+		for _, arg := range cfg.ArgSpec.Args {
+			args[arg.Name] = arg
+		}
+	}
+	for name, env := range cfg.ArgEnv.Names {
+		arg, ok := args[name]
+		if !ok {
+			return fmt.Errorf("unknown arg %q", name)
+		}
+		exported := arg.Type == "integer" || arg.Type == "boolean" || (arg.Type == "string" && !arg.Secret && arg.Format != "secret")
+		if !exported {
+			return fmt.Errorf("arg %q is not exported as a variable; only non-secret string, integer and boolean args are", name)
+		}
+		if !envNamePattern.MatchString(env) {
+			return fmt.Errorf("arg %q: %q is not a valid variable name", name, env)
+		}
+	}
+	return nil
+}
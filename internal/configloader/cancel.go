@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package configloader
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// cancelSignals are the signals a job may ask to receive on cancel. SIGKILL
+// is excluded: it cannot be caught, so there would be nothing to be graceful.
+var cancelSignals = map[string]bool{
+	"SIGTERM": true,
+	"SIGINT":  true,
+	"SIGHUP":  true,
+	"SIGQUIT": true,
+	"SIGUSR1": true,
+	"SIGUSR2": true,
+}
+
+// validateCancel normalises the signal name to its upper-case SIG form and
+// checks the grace period parses.
+func validateCancel(c *types.CancelConfig) error {
+	sig := strings.ToUpper(strings.TrimSpace(c.Signal))
+	if sig != "" && !strings.HasPrefix(sig, "SIG") {
+		sig = "SIG" + sig
+	}
+	if sig != "" && !cancelSignals[sig] {
+		return fmt.Errorf("unsupported signal %q (want SIGTERM, SIGINT, SIGHUP, SIGQUIT, SIGUSR1 or SIGUSR2)", c.Signal)
+	}
+	c.Signal = sig
+	c.GracePeriod = strings.TrimSpace(c.GracePeriod)
+	if c.GracePeriod != "" {
+		if d, err := time.ParseDuration(c.GracePeriod); err != nil || d <= 0 {
+			return fmt.Errorf("grace_period %q must be a positive duration such as 30s", c.GracePeriod)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package configloader
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// validateConcurrency requires a positive run limit and defaults the policy
+// to queueing.
+func validateConcurrency(c *types.ConcurrencyConfig) error {
+	if c.Max < 1 {
+		return fmt.Errorf("max must be at least 1")
+	}
+	c.Policy = strings.TrimSpace(c.Policy)
+	switch c.Policy {
+	case "":
+		c.Policy = types.ConcurrencyQueue
+	case types.ConcurrencyQueue, types.ConcurrencyReject:
+	default:
+		return fmt.Errorf("policy must be %s or %s", types.ConcurrencyQueue, types.ConcurrencyReject)
+	}
+	return nil
+}
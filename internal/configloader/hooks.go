@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package configloader
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// validateHooks requires hook scripts to be relative paths inside the job
+// directory so plans can record their digests like any other script.
+func validateHooks(hooks *types.HooksConfig) error {
+	for _, hook := range []struct {
+		name string
+		path *string
+	}{{"pre_run", &hooks.PreRun}, {"post_run", &hooks.PostRun}} {
+		*hook.path = strings.TrimSpace(*hook.path)
+		if *hook.path == "" {
+			continue
+		}
+		clean := filepath.ToSlash(filepath.Clean(*hook.path))
+		if filepath.IsAbs(*hook.path) || strings.HasPrefix(*hook.path, "/") || clean == ".." || strings.HasPrefix(clean, "../") {
+			return fmt.Errorf("%s %q must be a relative path inside the job directory", hook.name, *hook.path)
+		}
+		*hook.path = clean
+	}
+	return nil
+}
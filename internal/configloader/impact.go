@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package configloader
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// validateImpact lowercases environment names and the blast radius so the
+// production check in ImpactConfig.High is case-insensitive.
+func validateImpact(impact *types.ImpactConfig) error {
+	envs := impact.Environments[:0]
+	for _, env := range impact.Environments {
+		env = strings.ToLower(strings.TrimSpace(env))
+		if env == "" {
+			return fmt.Errorf("environments: empty name")
+		}
+		envs = append(envs, env)
+	}
+	impact.Environments = envs
+	impact.BlastRadius = strings.ToLower(strings.TrimSpace(impact.BlastRadius))
+	switch impact.BlastRadius {
+	case "", types.BlastRadiusLow, types.BlastRadiusMedium, types.BlastRadiusHigh:
+		return nil
+	}
+	return fmt.Errorf("blast_radius %q is not one of low, medium or high", impact.BlastRadius)
+}
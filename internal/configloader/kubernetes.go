@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package configloader
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// dnsLabelPattern matches Kubernetes namespace and service account names.
+var dnsLabelPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// validateKubernetes checks the placement of executor: kubernetes jobs. The
+// kubernetes block is only allowed on such jobs.
+func validateKubernetes(cfg *types.Config) error {
+	k := cfg.Kubernetes
+	if !strings.EqualFold(strings.TrimSpace(cfg.Executor), "kubernetes") {
+		if k != nil {
+			return fmt.Errorf("kubernetes settings require executor: kubernetes")
+		}
+		return nil
+	}
+	if len(cfg.Steps) == 0 && (cfg.Container == nil || strings.TrimSpace(cfg.Container.Image) == "") {
+		return fmt.Errorf("executor kubernetes requires container.image")
+	}
+	if k == nil {
+		return nil
+	}
+	for _, f := range []struct{ name, value string }{{"namespace", k.Namespace}, {"service_account", k.ServiceAccount}} {
+		if value := strings.TrimSpace(f.value); value != "" && (len(value) > 63 || !dnsLabelPattern.MatchString(value)) {
+			return fmt.Errorf("kubernetes.%s %q is not a valid name", f.name, value)
+		}
+	}
+	for key := range k.NodeSelector {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("kubernetes.node_selector keys must not be empty")
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@
 package configloader
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -16,16 +17,27 @@ import (
 // fullConfig retained for potential future extended parsing; current path decodes directly into types.Config
 
 func LoadConfig(scriptDir string) (*types.Config, error) {
+	return LoadConfigOverlay(scriptDir, "")
+}
+
+// LoadConfigOverlay loads the job config with the named environment overlay
+// (config.d/overlays/<overlay>.yaml) merged over it. An empty overlay loads
+// the base config alone; an unknown one fails with ErrOverlayNotFound.
+func LoadConfigOverlay(scriptDir, overlay string) (*types.Config, error) {
 	configPath := filepath.Join(scriptDir, "config.d", "config.yaml")
 
-	f, err := os.Open(configPath)
+	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("open config: %w", err)
 	}
-	defer f.Close()
+	if overlay != "" {
+		if data, err = applyOverlay(scriptDir, overlay, data); err != nil {
+			return nil, err
+		}
+	}
 
 	var cfg types.Config
-	decoder := yaml.NewDecoder(f)
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
 	if err := decoder.Decode(&cfg); err != nil {
 		return nil, fmt.Errorf("decode config: %w", err)
 	}
@@ -49,6 +61,69 @@ func LoadConfig(scriptDir string) (*types.Config, error) {
 	}
 	cfg.Aliases = normalised
 
+	seenOutputs := make(map[string]bool, len(cfg.Outputs))
+	for i, out := range cfg.Outputs {
+		name := strings.TrimSpace(out.Name)
+		if name == "" {
+			return nil, fmt.Errorf("invalid output #%d: name is required", i+1)
+		}
+		if seenOutputs[name] {
+			return nil, fmt.Errorf("invalid output %q: declared more than once", name)
+		}
+		seenOutputs[name] = true
+		switch out.Type {
+		case "":
+			cfg.Outputs[i].Type = "string"
+		case "string", "integer", "boolean":
+		default:
+			return nil, fmt.Errorf("invalid output %q: unsupported type %q", name, out.Type)
+		}
+		cfg.Outputs[i].Name = name
+	}
+	cfg.InputsFrom = strings.TrimSpace(cfg.InputsFrom)
+	if cfg.Notify != nil {
+		if err := validateNotify(cfg.Notify); err != nil {
+			return nil, fmt.Errorf("invalid notify config: %w", err)
+		}
+	}
+	if cfg.Impact != nil {
+		if err := validateImpact(cfg.Impact); err != nil {
+			return nil, fmt.Errorf("invalid impact: %w", err)
+		}
+	}
+	if cfg.Hooks != nil {
+		if err := validateHooks(cfg.Hooks); err != nil {
+			return nil, fmt.Errorf("invalid hooks: %w", err)
+		}
+	}
+	if cfg.Cancel != nil {
+		if err := validateCancel(cfg.Cancel); err != nil {
+			return nil, fmt.Errorf("invalid cancel: %w", err)
+		}
+	}
+	if err := validateTimeouts(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid timeout: %w", err)
+	}
+	if err := validateKubernetes(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid executor: %w", err)
+	}
+	if err := validateSSH(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid executor: %w", err)
+	}
+	if cfg.Approval != nil {
+		if err := validateApproval(cfg.Approval); err != nil {
+			return nil, fmt.Errorf("invalid approval: %w", err)
+		}
+	}
+	if cfg.Concurrency != nil {
+		if err := validateConcurrency(cfg.Concurrency); err != nil {
+			return nil, fmt.Errorf("invalid concurrency: %w", err)
+		}
+	}
+	if err := validateSchedules(cfg.Schedule); err != nil {
+		return nil, fmt.Errorf("invalid schedule: %w", err)
+	}
+
 	// Resolve data directory precedence: explicit env in config > process env > platform default.
 	dataDir := ""
 	if cfg.Env != nil {
@@ -98,6 +173,18 @@ func LoadConfig(scriptDir string) (*types.Config, error) {
 		}
 		cfg.ArgSpec = &as
 	}
+	if err := validateArgSeverities(cfg.ArgSpec); err != nil {
+		return nil, fmt.Errorf("invalid argspec: %w", err)
+	}
+	if err := validateArgRefs(cfg.ArgSpec); err != nil {
+		return nil, fmt.Errorf("invalid argspec: %w", err)
+	}
+	if err := validateArgEnv(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid arg_env: %w", err)
+	}
+	if err := validateArgsStyle(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid args_style: %w", err)
+	}
 
 	return &cfg, nil
 }
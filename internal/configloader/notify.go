@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package configloader
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+var (
+	notifyStatuses     = []string{"completed", "failed", "canceled", "timed_out"}
+	pagerDutySeverity  = []string{"critical", "error", "warning", "info"}
+	opsgeniePriorities = []string{"P1", "P2", "P3", "P4", "P5"}
+)
+
+func validateNotify(cfg *types.NotifyConfig) error {
+	if cfg.Email != nil {
+		if err := validateEmailNotify(cfg.Email); err != nil {
+			return err
+		}
+	}
+	for i := range cfg.Alerts {
+		if err := validateAlertRule(&cfg.Alerts[i]); err != nil {
+			return fmt.Errorf("alerts[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateAlertRule normalises the provider and applies the default
+// threshold and severity.
+func validateAlertRule(rule *types.AlertRule) error {
+	rule.Provider = strings.ToLower(strings.TrimSpace(rule.Provider))
+	rule.KeyRef = strings.TrimSpace(rule.KeyRef)
+	rule.Severity = strings.TrimSpace(rule.Severity)
+	if rule.KeyRef == "" {
+		return errors.New("key_ref is required")
+	}
+	if rule.Failures < 0 {
+		return fmt.Errorf("failures must be positive, got %d", rule.Failures)
+	}
+	if rule.Failures == 0 {
+		rule.Failures = 1
+	}
+	switch rule.Provider {
+	case "pagerduty":
+		rule.Severity = strings.ToLower(rule.Severity)
+		if rule.Severity == "" {
+			rule.Severity = "error"
+		}
+		if !slices.Contains(pagerDutySeverity, rule.Severity) {
+			return fmt.Errorf("unsupported pagerduty severity %q (want one of %s)", rule.Severity, strings.Join(pagerDutySeverity, ", "))
+		}
+	case "opsgenie":
+		rule.Severity = strings.ToUpper(rule.Severity)
+		if rule.Severity == "" {
+			rule.Severity = "P3"
+		}
+		if !slices.Contains(opsgeniePriorities, rule.Severity) {
+			return fmt.Errorf("unsupported opsgenie priority %q (want one of %s)", rule.Severity, strings.Join(opsgeniePriorities, ", "))
+		}
+	default:
+		return fmt.Errorf("unsupported alert provider %q", rule.Provider)
+	}
+	return nil
+}
+
+// validateEmailNotify checks that recipients are set, templates parse, the
+// digest interval is a positive duration and on lists terminal statuses only.
+func validateEmailNotify(cfg *types.EmailNotify) error {
+	if len(cfg.To) == 0 {
+		return errors.New("email.to must list at least one recipient")
+	}
+	for i, addr := range cfg.To {
+		addr = strings.TrimSpace(addr)
+		if addr == "" || strings.ContainsAny(addr, "\r\n,") {
+			return fmt.Errorf("email.to: invalid recipient %q", cfg.To[i])
+		}
+		cfg.To[i] = addr
+	}
+	for _, status := range cfg.On {
+		if !slices.Contains(notifyStatuses, status) {
+			return fmt.Errorf("email.on: unsupported status %q (want one of %s)", status, strings.Join(notifyStatuses, ", "))
+		}
+	}
+	if cfg.Digest != "" {
+		if d, err := time.ParseDuration(cfg.Digest); err != nil || d <= 0 {
+			return fmt.Errorf("email.digest: invalid interval %q", cfg.Digest)
+		}
+	}
+	for _, tmpl := range []struct{ name, text string }{
+		{"subject", cfg.Subject},
+		{"body", cfg.Body},
+		{"digest_subject", cfg.DigestSubject},
+		{"digest_body", cfg.DigestBody},
+	} {
+		if _, err := template.New(tmpl.name).Parse(tmpl.text); err != nil {
+			return fmt.Errorf("email.%s: %w", tmpl.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package configloader
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrOverlayNotFound reports a requested overlay the job does not define.
+var ErrOverlayNotFound = errors.New("overlay not found")
+
+var overlayNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+func overlayPath(scriptDir, name string) string {
+	return filepath.Join(scriptDir, "config.d", "overlays", name+".yaml")
+}
+
+// Overlays lists the environment overlays defined under
+// config.d/overlays, sorted by name.
+func Overlays(scriptDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(scriptDir, "config.d", "overlays"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".yaml")
+		if ok && !entry.IsDir() && overlayNamePattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// applyOverlay merges config.d/overlays/<name>.yaml over the base config
+// document and returns the merged YAML.
+func applyOverlay(scriptDir, name string, base []byte) ([]byte, error) {
+	if !overlayNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("overlay %q: %w", name, ErrOverlayNotFound)
+	}
+	data, err := os.ReadFile(overlayPath(scriptDir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("overlay %q: %w", name, ErrOverlayNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open overlay %q: %w", name, err)
+	}
+	var doc, patch map[string]any
+	if err := yaml.Unmarshal(base, &doc); err != nil {
+		return nil, fmt.Errorf("decode config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &patch); err != nil {
+		return nil, fmt.Errorf("decode overlay %q: %w", name, err)
+	}
+	merged, err := yaml.Marshal(mergeOverlay(doc, patch))
+	if err != nil {
+		return nil, fmt.Errorf("merge overlay %q: %w", name, err)
+	}
+	return merged, nil
+}
+
+// mergeOverlay merges patch over base. Mappings merge key by key, lists whose
+// items all carry a name (or id) merge item by item in base order with new
+// items appended, and anything else in patch replaces the base value.
+func mergeOverlay(base, patch any) any {
+	switch p := patch.(type) {
+	case map[string]any:
+		b, ok := base.(map[string]any)
+		if !ok {
+			return p
+		}
+		out := make(map[string]any, len(b)+len(p))
+		for k, v := range b {
+			out[k] = v
+		}
+		for k, v := range p {
+			out[k] = mergeOverlay(b[k], v)
+		}
+		return out
+	case []any:
+		b, ok := base.([]any)
+		if !ok {
+			return p
+		}
+		for _, key := range []string{"name", "id"} {
+			if merged, ok := mergeKeyedList(b, p, key); ok {
+				return merged
+			}
+		}
+		return p
+	default:
+		return patch
+	}
+}
+
+func mergeKeyedList(base, patch []any, key string) ([]any, bool) {
+	keyOf := func(item any) (string, bool) {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		id, ok := m[key].(string)
+		return id, ok && id != ""
+	}
+	index := make(map[string]int, len(base))
+	for i, item := range base {
+		id, ok := keyOf(item)
+		if !ok {
+			return nil, false
+		}
+		index[id] = i
+	}
+	out := append([]any(nil), base...)
+	for _, item := range patch {
+		id, ok := keyOf(item)
+		if !ok {
+			return nil, false
+		}
+		if i, exists := index[id]; exists {
+			out[i] = mergeOverlay(out[i], item)
+			continue
+		}
+		index[id] = len(out)
+		out = append(out, item)
+	}
+	return out, true
+}
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package configloader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// PipelinesDir is the directory, relative to the scripts root, holding one
+// pipeline definition per YAML file.
+const PipelinesDir = "pipelines"
+
+// LoadPipelines reads and validates every pipeline under root/pipelines,
+// sorted by id. A missing directory yields no pipelines.
+func LoadPipelines(root string) ([]types.Pipeline, error) {
+	entries, err := os.ReadDir(filepath.Join(root, PipelinesDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read pipelines: %w", err)
+	}
+	seen := map[string]string{}
+	var out []types.Pipeline
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		path := filepath.Join(root, PipelinesDir, e.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read pipeline %s: %w", e.Name(), err)
+		}
+		var doc types.PipelineFile
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("decode pipeline %s: %w", e.Name(), err)
+		}
+		p := doc.Pipeline
+		if err := normalisePipeline(&p); err != nil {
+			return nil, fmt.Errorf("pipeline %s: %w", e.Name(), err)
+		}
+		if prev, dup := seen[p.ID]; dup {
+			return nil, fmt.Errorf("pipeline %q defined in both %s and %s", p.ID, prev, e.Name())
+		}
+		seen[p.ID] = e.Name()
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func normalisePipeline(p *types.Pipeline) error {
+	p.ID = strings.TrimSpace(p.ID)
+	if p.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	if len(p.Stages) == 0 {
+		return fmt.Errorf("at least one stage is required")
+	}
+	names := map[string]bool{}
+	for i := range p.Stages {
+		st := &p.Stages[i]
+		st.Name = strings.TrimSpace(st.Name)
+		st.Job = strings.TrimSpace(st.Job)
+		if st.Name == "" || st.Job == "" {
+			return fmt.Errorf("stage #%d: name and job are required", i+1)
+		}
+		if names[st.Name] {
+			return fmt.Errorf("stage %q declared more than once", st.Name)
+		}
+		names[st.Name] = true
+		if i == 0 && st.Chain {
+			return fmt.Errorf("stage %q: the first stage has no previous run to chain from", st.Name)
+		}
+		if a := st.Approval; a != nil {
+			if a.Required < 0 {
+				return fmt.Errorf("stage %q: approval.required must not be negative", st.Name)
+			}
+			if a.Required == 0 && len(a.Approvers) > 0 {
+				a.Required = 1
+			}
+			if len(a.Approvers) > 0 && a.Required > len(a.Approvers) {
+				return fmt.Errorf("stage %q: approval.required exceeds the number of approvers", st.Name)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package configloader
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/cron"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+var scheduleNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// validateSchedules names unnamed schedules after their position and checks
+// each cron expression and time zone parses, so a bad schedule fails when the
+// job is loaded rather than silently never firing.
+func validateSchedules(schedules []types.ScheduleConfig) error {
+	seen := make(map[string]bool, len(schedules))
+	for i := range schedules {
+		s := &schedules[i]
+		s.Name = strings.TrimSpace(s.Name)
+		if s.Name == "" {
+			s.Name = strconv.Itoa(i + 1)
+		}
+		if !scheduleNamePattern.MatchString(s.Name) {
+			return fmt.Errorf("schedule name %q may only contain letters, digits, '.', '_' and '-'", s.Name)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("schedule %q declared more than once", s.Name)
+		}
+		seen[s.Name] = true
+		s.Cron = strings.TrimSpace(s.Cron)
+		if _, err := cron.Parse(s.Cron); err != nil {
+			return fmt.Errorf("schedule %q: %w", s.Name, err)
+		}
+		s.Timezone = strings.TrimSpace(s.Timezone)
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("schedule %q: unknown timezone %q", s.Name, s.Timezone)
+		}
+		s.Misfire = strings.TrimSpace(s.Misfire)
+		switch s.Misfire {
+		case "", types.MisfireSkip, types.MisfireRunOnce, types.MisfireRunAllMissed:
+		default:
+			return fmt.Errorf("schedule %q: misfire must be %s, %s or %s", s.Name, types.MisfireSkip, types.MisfireRunOnce, types.MisfireRunAllMissed)
+		}
+		if s.DST != nil {
+			s.DST.Skipped = strings.TrimSpace(s.DST.Skipped)
+			s.DST.Repeated = strings.TrimSpace(s.DST.Repeated)
+			if err := (cron.DSTPolicy{Skipped: s.DST.Skipped, Repeated: s.DST.Repeated}).Validate(); err != nil {
+				return fmt.Errorf("schedule %q: %w", s.Name, err)
+			}
+		}
+	}
+	return nil
+}
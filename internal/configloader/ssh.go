@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package configloader
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// sshNamePattern matches host names, IP addresses and user names accepted
+// for executor: ssh. A leading dash would be read as a client option.
+var sshNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.:\[\]][A-Za-z0-9_.:\[\]%-]*$`)
+
+// validateSSH checks the target of executor: ssh jobs. The ssh block is only
+// allowed on such jobs.
+func validateSSH(cfg *types.Config) error {
+	s := cfg.SSH
+	if !strings.EqualFold(strings.TrimSpace(cfg.Executor), "ssh") {
+		if s != nil {
+			return fmt.Errorf("ssh settings require executor: ssh")
+		}
+		return nil
+	}
+	if s == nil || strings.TrimSpace(s.Host) == "" {
+		return fmt.Errorf("executor ssh requires ssh.host")
+	}
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(cfg.Interpreter)), "container:") {
+		return fmt.Errorf("executor ssh does not support container interpreters")
+	}
+	for _, f := range []struct{ name, value string }{{"host", s.Host}, {"user", s.User}} {
+		if value := strings.TrimSpace(f.value); value != "" && !sshNamePattern.MatchString(value) {
+			return fmt.Errorf("ssh.%s %q is not a valid name", f.name, value)
+		}
+	}
+	if s.Port < 0 || s.Port > 65535 {
+		return fmt.Errorf("ssh.port %d is out of range", s.Port)
+	}
+	for _, f := range []struct{ name, value string }{{"identity_file", s.IdentityFile}, {"known_hosts_file", s.KnownHostsFile}} {
+		if value := strings.TrimSpace(f.value); value != "" && !filepath.IsAbs(value) {
+			return fmt.Errorf("ssh.%s must be an absolute path", f.name)
+		}
+	}
+	return nil
+}
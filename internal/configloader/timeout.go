@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package configloader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// validateTimeouts checks the run and step timeouts parse, normalising plain
+// numbers of seconds to durations such as "300s".
+func validateTimeouts(cfg *types.Config) error {
+	var err error
+	if cfg.Timeout, err = normalizeTimeout(cfg.Timeout); err != nil {
+		return err
+	}
+	for i := range cfg.Steps {
+		if cfg.Steps[i].Timeout, err = normalizeTimeout(cfg.Steps[i].Timeout); err != nil {
+			return fmt.Errorf("steps[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func normalizeTimeout(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		value = strconv.Itoa(n) + "s"
+	}
+	if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+		return "", fmt.Errorf("timeout %q must be a positive duration such as 10m", value)
+	}
+	return value, nil
+}
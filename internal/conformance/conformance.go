@@ -0,0 +1,355 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package conformance checks that a flowd deployment, including any proxy or
+// gateway in front of it, preserves the serve-mode API contract: problem
+// responses and their status codes, idempotent run creation and event stream
+// resumption.
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/client"
+)
+
+// Check results.
+const (
+	Passed  = "passed"
+	Failed  = "failed"
+	Skipped = "skipped"
+)
+
+const (
+	cursorExpiredType       = "https://flowd.dev/problems/cursor-expired"
+	idempotencyConflictType = "https://flowd.dev/problems/idempotency-key-conflict"
+	// expiredCursor lies past the end of any run journal.
+	expiredCursor = "9223372036854775807"
+)
+
+// Config describes the deployment under test.
+type Config struct {
+	// Server is the base URL clients use, e.g. https://flowd.example.org.
+	Server string
+	// Token is a bearer token with jobs:read, runs:read, runs:write and
+	// events:read. Empty sends no Authorization header.
+	Token string
+	// LimitedToken is a valid token lacking runs:read. The 403 check is
+	// skipped without one.
+	LimitedToken string
+	// JobID is a quick job the checks may start; Args are its arguments.
+	JobID string
+	Args  map[string]any
+	// Timeout bounds each request and the wait for the run to finish.
+	Timeout time.Duration
+	// HTTPClient overrides the client used for requests.
+	HTTPClient *http.Client
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Name   string `json:"name"`
+	Result string `json:"result"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Run executes every check in order and returns their results. Checks that
+// depend on a started run are skipped when it could not be created.
+func Run(ctx context.Context, cfg Config) []Result {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{}
+	}
+	s := &suite{ctx: ctx, cfg: cfg, base: strings.TrimRight(cfg.Server, "/")}
+
+	return []Result{
+		s.check("401 unauthorized", s.unauthorized),
+		s.check("403 forbidden", s.forbidden),
+		s.check("400 idempotency key required", s.idempotencyKeyRequired),
+		s.check("201 run created", s.createRun),
+		s.check("idempotent replay", s.idempotentReplay),
+		s.check("409 idempotency conflict", s.idempotencyConflict),
+		s.check("422 unprocessable request", s.unprocessable),
+		s.check("SSE resume", s.sseResume),
+		s.check("410 cursor expired", s.cursorExpired),
+		{Name: "429 storage quota", Result: Skipped, Detail: "only returned when the server's storage quota is exhausted"},
+	}
+}
+
+// AnyFailed reports whether any result failed.
+func AnyFailed(results []Result) bool {
+	for _, r := range results {
+		if r.Result == Failed {
+			return true
+		}
+	}
+	return false
+}
+
+type suite struct {
+	ctx   context.Context
+	cfg   Config
+	base  string
+	body  string
+	key   string
+	runID string
+}
+
+// skip marks a check as skipped rather than failed.
+type skip string
+
+func (s skip) Error() string { return string(s) }
+
+func (s *suite) check(name string, fn func(context.Context) (string, error)) Result {
+	ctx, cancel := context.WithTimeout(s.ctx, s.cfg.Timeout)
+	defer cancel()
+	detail, err := fn(ctx)
+	switch e := err.(type) {
+	case nil:
+		return Result{Name: name, Result: Passed, Detail: detail}
+	case skip:
+		return Result{Name: name, Result: Skipped, Detail: string(e)}
+	default:
+		return Result{Name: name, Result: Failed, Detail: err.Error()}
+	}
+}
+
+func (s *suite) unauthorized(ctx context.Context) (string, error) {
+	resp, body, err := s.do(ctx, http.MethodGet, "/jobs", "", nil)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return "", skip("server accepts anonymous requests (dev mode?)")
+	}
+	if err := expectProblem(resp, body, http.StatusUnauthorized, ""); err != nil {
+		return "", err
+	}
+	if challenge := resp.Header.Get("WWW-Authenticate"); !strings.Contains(strings.ToLower(challenge), "bearer") {
+		return "", fmt.Errorf("missing WWW-Authenticate Bearer challenge, got %q", challenge)
+	}
+	return "", nil
+}
+
+func (s *suite) forbidden(ctx context.Context) (string, error) {
+	if s.cfg.LimitedToken == "" {
+		return "", skip("no --limited-token given")
+	}
+	headers := map[string]string{"Authorization": "Bearer " + s.cfg.LimitedToken}
+	resp, body, err := s.do(ctx, http.MethodGet, "/runs", "", headers)
+	if err != nil {
+		return "", err
+	}
+	return "", expectProblem(resp, body, http.StatusForbidden, "")
+}
+
+func (s *suite) idempotencyKeyRequired(ctx context.Context) (string, error) {
+	resp, body, err := s.do(ctx, http.MethodPost, "/runs", s.runBody(nil), s.auth(nil))
+	if err != nil {
+		return "", err
+	}
+	return "", expectProblem(resp, body, http.StatusBadRequest, "")
+}
+
+func (s *suite) createRun(ctx context.Context) (string, error) {
+	key, err := client.NewIdempotencyKey()
+	if err != nil {
+		return "", err
+	}
+	s.key, s.body = key, s.runBody(nil)
+	resp, body, err := s.do(ctx, http.MethodPost, "/runs", s.body, s.auth(map[string]string{"Idempotency-Key": key}))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("expected 201, got %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var run struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &run); err != nil || run.ID == "" {
+		return "", fmt.Errorf("response carries no run id: %s", strings.TrimSpace(string(body)))
+	}
+	s.runID = run.ID
+	return "run " + run.ID, nil
+}
+
+func (s *suite) idempotentReplay(ctx context.Context) (string, error) {
+	if s.runID == "" {
+		return "", skip("no run was created")
+	}
+	resp, body, err := s.do(ctx, http.MethodPost, "/runs", s.body, s.auth(map[string]string{"Idempotency-Key": s.key}))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("expected replayed 201, got %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var run struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &run); err != nil || run.ID != s.runID {
+		return "", fmt.Errorf("replay returned run %q, want %q", run.ID, s.runID)
+	}
+	if resp.Header.Get("Idempotent-Replay") != "true" {
+		return "", fmt.Errorf("Idempotent-Replay header not preserved")
+	}
+	return "", nil
+}
+
+func (s *suite) idempotencyConflict(ctx context.Context) (string, error) {
+	if s.runID == "" {
+		return "", skip("no run was created")
+	}
+	body := s.runBody(map[string]any{"requested_security_profile": "secure"})
+	resp, data, err := s.do(ctx, http.MethodPost, "/runs", body, s.auth(map[string]string{"Idempotency-Key": s.key}))
+	if err != nil {
+		return "", err
+	}
+	return "", expectProblem(resp, data, http.StatusConflict, idempotencyConflictType)
+}
+
+func (s *suite) unprocessable(ctx context.Context) (string, error) {
+	key, err := client.NewIdempotencyKey()
+	if err != nil {
+		return "", err
+	}
+	body := s.runBody(map[string]any{"requested_security_profile": "conformance-invalid"})
+	resp, data, err := s.do(ctx, http.MethodPost, "/runs", body, s.auth(map[string]string{"Idempotency-Key": key}))
+	if err != nil {
+		return "", err
+	}
+	return "", expectProblem(resp, data, http.StatusUnprocessableEntity, "")
+}
+
+// sseResume reads the run's full event stream, then resumes after the first
+// event and expects exactly the remaining events.
+func (s *suite) sseResume(ctx context.Context) (string, error) {
+	if s.runID == "" {
+		return "", skip("no run was created")
+	}
+	api := client.New(s.base, client.WithToken(s.cfg.Token), client.WithHTTPClient(s.cfg.HTTPClient))
+	all, err := readEvents(ctx, api, s.runID, "")
+	if err != nil {
+		return "", fmt.Errorf("read stream: %w", err)
+	}
+	if len(all) < 2 {
+		return "", fmt.Errorf("expected at least two events, got %d", len(all))
+	}
+	resumed, err := readEvents(ctx, api, s.runID, all[0])
+	if err != nil {
+		return "", fmt.Errorf("resume after %s: %w", all[0], err)
+	}
+	if strings.Join(resumed, ",") != strings.Join(all[1:], ",") {
+		return "", fmt.Errorf("resume after %s returned events %v, want %v", all[0], resumed, all[1:])
+	}
+	return fmt.Sprintf("%d events", len(all)), nil
+}
+
+func (s *suite) cursorExpired(ctx context.Context) (string, error) {
+	if s.runID == "" {
+		return "", skip("no run was created")
+	}
+	resp, body, err := s.do(ctx, http.MethodGet, "/runs/"+s.runID+"/events", "", s.auth(map[string]string{"Last-Event-ID": expiredCursor}))
+	if err != nil {
+		return "", err
+	}
+	return "", expectProblem(resp, body, http.StatusGone, cursorExpiredType)
+}
+
+// readEvents returns the IDs of the run's events until the stream ends. The
+// stream is not allowed to reconnect so a dropped connection fails the check.
+func readEvents(ctx context.Context, api *client.Client, runID, lastEventID string) ([]string, error) {
+	stream, err := api.Runs.Events(ctx, runID, &client.EventsOptions{LastEventID: lastEventID, MaxReconnects: -1})
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	var ids []string
+	for stream.Next() {
+		ids = append(ids, stream.Event().ID)
+	}
+	return ids, stream.Err()
+}
+
+func (s *suite) runBody(extra map[string]any) string {
+	body := map[string]any{"job_id": s.cfg.JobID}
+	if len(s.cfg.Args) > 0 {
+		body["args"] = s.cfg.Args
+	}
+	for k, v := range extra {
+		body[k] = v
+	}
+	data, _ := json.Marshal(body)
+	return string(data)
+}
+
+func (s *suite) auth(headers map[string]string) map[string]string {
+	out := map[string]string{}
+	if s.cfg.Token != "" {
+		out["Authorization"] = "Bearer " + s.cfg.Token
+	}
+	for k, v := range headers {
+		out[k] = v
+	}
+	return out
+}
+
+func (s *suite) do(ctx context.Context, method, path, body string, headers map[string]string) (*http.Response, []byte, error) {
+	var reader io.Reader
+	if body != "" {
+		reader = bytes.NewReader([]byte(body))
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.base+path, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	return resp, data, err
+}
+
+// expectProblem checks the status code and that the body is an RFC7807
+// problem served as application/problem+json with a matching status.
+func expectProblem(resp *http.Response, body []byte, status int, problemType string) error {
+	if resp.StatusCode != status {
+		return fmt.Errorf("expected %d, got %d: %s", status, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt != "application/problem+json" {
+		return fmt.Errorf("expected application/problem+json, got %q", resp.Header.Get("Content-Type"))
+	}
+	var problem struct {
+		Type   string `json:"type"`
+		Title  string `json:"title"`
+		Status int    `json:"status"`
+	}
+	if err := json.Unmarshal(body, &problem); err != nil {
+		return fmt.Errorf("decode problem: %w", err)
+	}
+	if problem.Status != status || problem.Title == "" {
+		return fmt.Errorf("problem body does not match the response: %s", strings.TrimSpace(string(body)))
+	}
+	if problemType != "" && problem.Type != problemType {
+		return fmt.Errorf("expected problem type %s, got %q", problemType, problem.Type)
+	}
+	return nil
+}
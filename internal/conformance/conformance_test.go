@@ -0,0 +1,65 @@
+package conformance
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/testserve"
+)
+
+const testToken = "jobs:read,runs:read,runs:write,events:read"
+
+func startServe(t *testing.T) string {
+	t.Helper()
+	return "http://" + testserve.Start(t, testToken, testserve.Greet)
+}
+
+func TestRunAgainstServe(t *testing.T) {
+	base := startServe(t)
+	results := Run(context.Background(), Config{
+		Server:       base,
+		Token:        testToken,
+		LimitedToken: "jobs:read",
+		JobID:        "greet",
+		Args:         map[string]any{"name": "conformance"},
+		Timeout:      10 * time.Second,
+	})
+	for _, r := range results {
+		want := Passed
+		if r.Name == "429 storage quota" {
+			want = Skipped
+		}
+		if r.Result != want {
+			t.Errorf("%s: expected %s, got %s (%s)", r.Name, want, r.Result, r.Detail)
+		}
+	}
+}
+
+func TestRunDetectsStrippedProblemType(t *testing.T) {
+	base := startServe(t)
+	// A gateway that rewrites error bodies to plain text breaks the contract.
+	rewriting := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err == nil && resp.StatusCode >= 400 {
+			resp.Header.Set("Content-Type", "text/plain")
+		}
+		return resp, err
+	})}
+	results := Run(context.Background(), Config{
+		Server:     base,
+		Token:      testToken,
+		JobID:      "greet",
+		Args:       map[string]any{"name": "conformance"},
+		Timeout:    10 * time.Second,
+		HTTPClient: rewriting,
+	})
+	if !AnyFailed(results) {
+		t.Fatalf("expected rewritten problem responses to fail, got %+v", results)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
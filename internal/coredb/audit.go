@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package coredb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AuditRecord is one entry of the audit log. Principal, Action and Status
+// are kept in their own columns for filtering; Data holds the full encoded
+// entry.
+type AuditRecord struct {
+	Seq       int64
+	Timestamp time.Time
+	Principal string
+	Action    string
+	Status    int
+	Data      []byte
+}
+
+// AuditQuery selects audit records. Zero fields do not filter; Since is
+// inclusive and Until exclusive. Limit zero returns every match.
+type AuditQuery struct {
+	Since     time.Time
+	Until     time.Time
+	Principal string
+	Action    string
+	Offset    int
+	Limit     int
+}
+
+// AuditStore is the append-only audit log. Records cannot be updated or
+// deleted once written.
+type AuditStore struct {
+	db *sql.DB
+}
+
+// NewAuditStore returns a store backed by the provided DB.
+func NewAuditStore(db *DB) *AuditStore {
+	if db == nil {
+		return nil
+	}
+	return &AuditStore{db: db.sql}
+}
+
+// Append writes rec and returns its sequence number.
+func (s *AuditStore) Append(ctx context.Context, rec AuditRecord) (int64, error) {
+	if s == nil {
+		return 0, nil
+	}
+	res, err := s.db.ExecContext(ctx, `INSERT INTO core_audit(ts, principal, action, status, data) VALUES(?, ?, ?, ?, ?)`,
+		rec.Timestamp.UnixMilli(), rec.Principal, rec.Action, rec.Status, rec.Data)
+	if err != nil {
+		return 0, fmt.Errorf("append audit record: %w", err)
+	}
+	seq, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("append audit record: %w", err)
+	}
+	return seq, nil
+}
+
+// Count returns the number of records matching q, ignoring its offset and
+// limit.
+func (s *AuditStore) Count(ctx context.Context, q AuditQuery) (int, error) {
+	if s == nil {
+		return 0, nil
+	}
+	where, args := q.where()
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM core_audit`+where, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count audit records: %w", err)
+	}
+	return n, nil
+}
+
+// ForEach calls fn with the records matching q, oldest first, stopping at
+// the first error.
+func (s *AuditStore) ForEach(ctx context.Context, q AuditQuery, fn func(AuditRecord) error) error {
+	if s == nil {
+		return nil
+	}
+	where, args := q.where()
+	stmt := `SELECT seq, ts, principal, action, status, data FROM core_audit` + where + ` ORDER BY seq`
+	if q.Limit > 0 || q.Offset > 0 {
+		limit := q.Limit
+		if limit <= 0 {
+			limit = -1
+		}
+		stmt += ` LIMIT ? OFFSET ?`
+		args = append(args, limit, max(q.Offset, 0))
+	}
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return fmt.Errorf("query audit records: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			rec AuditRecord
+			ts  int64
+		)
+		if err := rows.Scan(&rec.Seq, &ts, &rec.Principal, &rec.Action, &rec.Status, &rec.Data); err != nil {
+			return fmt.Errorf("scan audit record: %w", err)
+		}
+		rec.Timestamp = time.UnixMilli(ts).UTC()
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("query audit records: %w", err)
+	}
+	return nil
+}
+
+// Query returns the records matching q, oldest first.
+func (s *AuditStore) Query(ctx context.Context, q AuditQuery) ([]AuditRecord, error) {
+	var out []AuditRecord
+	err := s.ForEach(ctx, q, func(rec AuditRecord) error {
+		out = append(out, rec)
+		return nil
+	})
+	return out, err
+}
+
+func (q AuditQuery) where() (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	if !q.Since.IsZero() {
+		conds = append(conds, "ts >= ?")
+		args = append(args, q.Since.UnixMilli())
+	}
+	if !q.Until.IsZero() {
+		conds = append(conds, "ts < ?")
+		args = append(args, q.Until.UnixMilli())
+	}
+	if q.Principal != "" {
+		conds = append(conds, "principal = ?")
+		args = append(args, q.Principal)
+	}
+	if q.Action != "" {
+		conds = append(conds, "action = ?")
+		args = append(args, q.Action)
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
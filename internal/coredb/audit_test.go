@@ -0,0 +1,57 @@
+package coredb
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditStoreAppendQueryAndImmutability(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := openTestDB(t)
+	store := NewAuditStore(db)
+	base := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	entries := []AuditRecord{
+		{Timestamp: base, Principal: "alice", Action: "POST /runs", Status: 201, Data: []byte(`{"n":1}`)},
+		{Timestamp: base.Add(time.Minute), Principal: "bob", Action: "POST /runs", Status: 403, Data: []byte(`{"n":2}`)},
+		{Timestamp: base.Add(2 * time.Minute), Principal: "alice", Action: "DELETE /sources/{name}", Status: 204, Data: []byte(`{"n":3}`)},
+	}
+	for i, rec := range entries {
+		seq, err := store.Append(ctx, rec)
+		if err != nil {
+			t.Fatalf("append: %v", err)
+		}
+		if seq != int64(i+1) {
+			t.Fatalf("expected seq %d, got %d", i+1, seq)
+		}
+	}
+
+	records, err := store.Query(ctx, AuditQuery{Principal: "alice"})
+	if err != nil || len(records) != 2 || string(records[0].Data) != `{"n":1}` || string(records[1].Data) != `{"n":3}` {
+		t.Fatalf("expected alice's records oldest first, got %+v (err %v)", records, err)
+	}
+	if !records[0].Timestamp.Equal(base) || records[0].Status != 201 {
+		t.Fatalf("unexpected record %+v", records[0])
+	}
+	records, err = store.Query(ctx, AuditQuery{Action: "POST /runs", Since: base.Add(time.Minute), Until: base.Add(2 * time.Minute)})
+	if err != nil || len(records) != 1 || records[0].Principal != "bob" {
+		t.Fatalf("expected bob's run in the window, got %+v (err %v)", records, err)
+	}
+	records, err = store.Query(ctx, AuditQuery{Offset: 1, Limit: 1})
+	if err != nil || len(records) != 1 || records[0].Seq != 2 {
+		t.Fatalf("expected the second record, got %+v (err %v)", records, err)
+	}
+	if n, err := store.Count(ctx, AuditQuery{Since: base.Add(time.Minute)}); err != nil || n != 2 {
+		t.Fatalf("expected 2 records since base+1m, got %d (err %v)", n, err)
+	}
+
+	for _, stmt := range []string{`UPDATE core_audit SET principal = 'mallory'`, `DELETE FROM core_audit`} {
+		if _, err := db.SQL().ExecContext(ctx, stmt); err == nil || !strings.Contains(err.Error(), "append-only") {
+			t.Fatalf("expected %q to be refused, got %v", stmt, err)
+		}
+	}
+}
@@ -207,6 +207,94 @@ FROM core_run_journal WHERE run_id = ?
 	return earliest, latest, nil
 }
 
+// DeleteRun removes every event retained for the provided run and returns the
+// number of events deleted.
+func (j *Journal) DeleteRun(ctx context.Context, runID string) (int64, error) {
+	if j == nil {
+		return 0, nil
+	}
+	res, err := j.db.ExecContext(ctx, `DELETE FROM core_run_journal WHERE run_id = ?`, runID)
+	if err != nil {
+		return 0, fmt.Errorf("journal delete run: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("journal delete run: %w", err)
+	}
+	return n, nil
+}
+
+// Compact removes the events of every run whose newest event is older than
+// cutoff and returns the number of events deleted. A run that is still
+// journaling keeps its whole history, so resume cursors stay valid until the
+// run has been quiet for the retention window.
+func (j *Journal) Compact(ctx context.Context, cutoff time.Time) (deleted int64, err error) {
+	if j == nil {
+		return 0, nil
+	}
+	ctx, span := tracing.Start(ctx, "coredb.journal.compact",
+		tracing.PersistDriver(sqliteDriverName),
+		tracing.PersistOp("compact"),
+		tracing.PersistKeyspace("core_run_journal"),
+	)
+	defer tracing.End(span, &err)
+
+	var tx *sql.Tx
+	tx, err = j.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin journal compact tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	const stale = `SELECT run_id FROM core_run_journal GROUP BY run_id HAVING MAX(ts) < ?`
+	var rows *sql.Rows
+	rows, err = tx.QueryContext(ctx, `SELECT length(payload) FROM core_run_journal WHERE run_id IN (`+stale+`)`, cutoff.UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("journal compact lookup: %w", err)
+	}
+	var sizes []int64
+	for rows.Next() {
+		var size int64
+		if err = rows.Scan(&size); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("journal compact scan: %w", err)
+		}
+		sizes = append(sizes, size)
+	}
+	if err = rows.Err(); err != nil {
+		rows.Close()
+		return 0, fmt.Errorf("journal compact rows: %w", err)
+	}
+	rows.Close()
+	if len(sizes) == 0 {
+		err = tx.Commit()
+		return 0, err
+	}
+
+	var res sql.Result
+	res, err = tx.ExecContext(ctx, `DELETE FROM core_run_journal WHERE run_id IN (`+stale+`)`, cutoff.UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("journal compact delete: %w", err)
+	}
+	if deleted, err = res.RowsAffected(); err != nil {
+		return 0, fmt.Errorf("journal compact delete: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("journal compact commit: %w", err)
+	}
+	for _, size := range sizes {
+		metrics.RecordPersistenceEviction(metrics.PersistenceKindJournal, size)
+	}
+	if span != nil {
+		span.SetAttributes(tracing.Int64("journal.compacted", deleted))
+	}
+	return deleted, nil
+}
+
 // ForEach streams events for the supplied run strictly after the provided
 // sequence (i.e. seq > afterSeq) in ascending order. Iteration halts if the
 // callback returns an error.
@@ -127,6 +127,58 @@ func TestJournalRejectsPayloadAboveLimit(t *testing.T) {
 	}
 }
 
+func TestJournalCompactDropsQuietRuns(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db, err := Open(ctx, Options{DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	journal := NewJournal(db, 0)
+
+	base := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	appends := []struct {
+		run string
+		at  time.Time
+	}{
+		{"run-old", base},
+		{"run-old", base.Add(time.Minute)},
+		// run-live started before the cutoff but is still journaling.
+		{"run-live", base},
+		{"run-live", base.Add(2 * time.Hour)},
+	}
+	for _, a := range appends {
+		if _, err := journal.Append(ctx, a.run, "step.log", []byte(`{}`), a.at); err != nil {
+			t.Fatalf("append %s: %v", a.run, err)
+		}
+	}
+
+	deleted, err := journal.Compact(ctx, base.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected 2 events compacted, got %d", deleted)
+	}
+	if earliest, _, _ := journal.Bounds(ctx, "run-old"); earliest != 0 {
+		t.Fatalf("expected run-old compacted, earliest=%d", earliest)
+	}
+	count := 0
+	if err := journal.ForEach(ctx, "run-live", 0, func(JournalEntry) error {
+		count++
+		return nil
+	}); err != nil {
+		t.Fatalf("journal iterate: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected run-live history kept, got %d events", count)
+	}
+}
+
 func TestParseEventID(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
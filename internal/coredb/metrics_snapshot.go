@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package coredb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// MetricSample is one persisted counter series.
+type MetricSample struct {
+	Name  string
+	Label string
+	Value uint64
+}
+
+// MetricsSnapshotStore keeps the latest snapshot of the server's counters so
+// totals survive restarts.
+type MetricsSnapshotStore struct {
+	db *sql.DB
+}
+
+// NewMetricsSnapshotStore returns a store backed by the provided DB.
+func NewMetricsSnapshotStore(db *DB) *MetricsSnapshotStore {
+	if db == nil {
+		return nil
+	}
+	return &MetricsSnapshotStore{db: db.sql}
+}
+
+// Save replaces the stored snapshot with samples taken at ts.
+func (s *MetricsSnapshotStore) Save(ctx context.Context, samples []MetricSample, ts time.Time) (err error) {
+	if s == nil {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin metrics snapshot tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	if _, err = tx.ExecContext(ctx, `DELETE FROM core_metrics_snapshot`); err != nil {
+		return fmt.Errorf("clear metrics snapshot: %w", err)
+	}
+	for _, sample := range samples {
+		if _, err = tx.ExecContext(ctx, `INSERT INTO core_metrics_snapshot(name, label, value, ts) VALUES(?, ?, ?, ?)`,
+			sample.Name, sample.Label, int64(sample.Value), ts.UnixMilli()); err != nil {
+			return fmt.Errorf("store metrics snapshot: %w", err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit metrics snapshot: %w", err)
+	}
+	return nil
+}
+
+// Load returns the stored snapshot and the time it was taken. An empty
+// snapshot returns no samples and a zero time.
+func (s *MetricsSnapshotStore) Load(ctx context.Context) (samples []MetricSample, ts time.Time, err error) {
+	if s == nil {
+		return nil, time.Time{}, nil
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT name, label, value, ts FROM core_metrics_snapshot ORDER BY name, label`)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("load metrics snapshot: %w", err)
+	}
+	defer rows.Close()
+	var latest int64
+	for rows.Next() {
+		var (
+			sample MetricSample
+			value  int64
+			at     int64
+		)
+		if err := rows.Scan(&sample.Name, &sample.Label, &value, &at); err != nil {
+			return nil, time.Time{}, fmt.Errorf("scan metrics snapshot: %w", err)
+		}
+		if value < 0 {
+			continue
+		}
+		sample.Value = uint64(value)
+		samples = append(samples, sample)
+		latest = max(latest, at)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("load metrics snapshot: %w", err)
+	}
+	if latest > 0 {
+		ts = time.UnixMilli(latest).UTC()
+	}
+	return samples, ts, nil
+}
@@ -0,0 +1,46 @@
+package coredb
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMetricsSnapshotSaveReplacesAndLoads(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db, err := Open(ctx, Options{DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	store := NewMetricsSnapshotStore(db)
+
+	samples, ts, err := store.Load(ctx)
+	if err != nil || len(samples) != 0 || !ts.IsZero() {
+		t.Fatalf("expected empty snapshot, got %v at %v (err %v)", samples, ts, err)
+	}
+
+	first := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if err := store.Save(ctx, []MetricSample{{Name: "a_total", Value: 1}, {Name: "b_total", Label: "x", Value: 2}}, first); err != nil {
+		t.Fatalf("save first: %v", err)
+	}
+	second := first.Add(time.Minute)
+	if err := store.Save(ctx, []MetricSample{{Name: "b_total", Label: "x", Value: 5}}, second); err != nil {
+		t.Fatalf("save second: %v", err)
+	}
+
+	samples, ts, err = store.Load(ctx)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(samples) != 1 || samples[0] != (MetricSample{Name: "b_total", Label: "x", Value: 5}) {
+		t.Fatalf("expected latest snapshot only, got %+v", samples)
+	}
+	if !ts.Equal(second) {
+		t.Fatalf("expected snapshot time %v, got %v", second, ts)
+	}
+}
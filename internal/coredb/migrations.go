@@ -29,6 +29,37 @@ var baseMigrations = [...]string{
 		ts INTEGER NOT NULL
 	);`,
 	`CREATE INDEX IF NOT EXISTS idx_core_journal_run_ts ON core_run_journal(run_id, ts);`,
+	`CREATE TABLE IF NOT EXISTS core_metrics_snapshot (
+		name TEXT NOT NULL,
+		label TEXT NOT NULL,
+		value INTEGER NOT NULL,
+		ts INTEGER NOT NULL,
+		PRIMARY KEY (name, label)
+	);`,
+	`CREATE TABLE IF NOT EXISTS core_runs (
+		id TEXT PRIMARY KEY,
+		job_id TEXT NOT NULL,
+		status TEXT NOT NULL,
+		started_at INTEGER NOT NULL,
+		data BLOB NOT NULL
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_core_runs_started ON core_runs(started_at);`,
+	`CREATE TABLE IF NOT EXISTS core_audit (
+		seq INTEGER PRIMARY KEY AUTOINCREMENT,
+		ts INTEGER NOT NULL,
+		principal TEXT NOT NULL,
+		action TEXT NOT NULL,
+		status INTEGER NOT NULL,
+		data BLOB NOT NULL
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_core_audit_ts ON core_audit(ts);`,
+	`CREATE INDEX IF NOT EXISTS idx_core_audit_principal ON core_audit(principal, ts);`,
+	// The audit log is append-only: rows cannot be changed or removed
+	// through SQL.
+	`CREATE TRIGGER IF NOT EXISTS core_audit_no_update BEFORE UPDATE ON core_audit
+	BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END;`,
+	`CREATE TRIGGER IF NOT EXISTS core_audit_no_delete BEFORE DELETE ON core_audit
+	BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END;`,
 }
 
 func applyMigrations(ctx context.Context, conn *sql.DB) error {
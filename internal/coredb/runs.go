@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package coredb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RunRecord is a serialized run. JobID, Status and StartedAt are kept in
+// their own columns for inspection; Data holds the full encoded run.
+type RunRecord struct {
+	ID        string
+	JobID     string
+	Status    string
+	StartedAt time.Time
+	Data      []byte
+}
+
+// RunRecordStore persists run records so run history survives restarts.
+type RunRecordStore struct {
+	db *sql.DB
+}
+
+// NewRunRecordStore returns a store backed by the provided DB.
+func NewRunRecordStore(db *DB) *RunRecordStore {
+	if db == nil {
+		return nil
+	}
+	return &RunRecordStore{db: db.sql}
+}
+
+// Put inserts or replaces rec.
+func (s *RunRecordStore) Put(ctx context.Context, rec RunRecord) error {
+	if s == nil {
+		return nil
+	}
+	_, err := s.db.ExecContext(ctx, `INSERT INTO core_runs(id, job_id, status, started_at, data) VALUES(?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET job_id = excluded.job_id, status = excluded.status, started_at = excluded.started_at, data = excluded.data`,
+		rec.ID, rec.JobID, rec.Status, rec.StartedAt.UnixMilli(), rec.Data)
+	if err != nil {
+		return fmt.Errorf("store run %s: %w", rec.ID, err)
+	}
+	return nil
+}
+
+// Delete removes the record for id, if any.
+func (s *RunRecordStore) Delete(ctx context.Context, id string) error {
+	if s == nil {
+		return nil
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM core_runs WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete run %s: %w", id, err)
+	}
+	return nil
+}
+
+// All returns every stored record, oldest first.
+func (s *RunRecordStore) All(ctx context.Context) ([]RunRecord, error) {
+	if s == nil {
+		return nil, nil
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT id, job_id, status, started_at, data FROM core_runs ORDER BY started_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("load runs: %w", err)
+	}
+	defer rows.Close()
+	var out []RunRecord
+	for rows.Next() {
+		var (
+			rec     RunRecord
+			started int64
+		)
+		if err := rows.Scan(&rec.ID, &rec.JobID, &rec.Status, &started, &rec.Data); err != nil {
+			return nil, fmt.Errorf("scan run: %w", err)
+		}
+		rec.StartedAt = time.UnixMilli(started).UTC()
+		out = append(out, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("load runs: %w", err)
+	}
+	return out, nil
+}
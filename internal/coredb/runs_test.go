@@ -0,0 +1,47 @@
+package coredb
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRunRecordStorePutAllDelete(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewRunRecordStore(openTestDB(t))
+	base := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := store.Put(ctx, RunRecord{ID: "r2", JobID: "b", Status: "running", StartedAt: base.Add(time.Minute), Data: []byte(`{"id":"r2"}`)}); err != nil {
+		t.Fatalf("put r2: %v", err)
+	}
+	if err := store.Put(ctx, RunRecord{ID: "r1", JobID: "a", Status: "running", StartedAt: base, Data: []byte(`{"id":"r1"}`)}); err != nil {
+		t.Fatalf("put r1: %v", err)
+	}
+	if err := store.Put(ctx, RunRecord{ID: "r1", JobID: "a", Status: "completed", StartedAt: base, Data: []byte(`{"id":"r1","status":"completed"}`)}); err != nil {
+		t.Fatalf("replace r1: %v", err)
+	}
+
+	records, err := store.All(ctx)
+	if err != nil {
+		t.Fatalf("all: %v", err)
+	}
+	if len(records) != 2 || records[0].ID != "r1" || records[1].ID != "r2" {
+		t.Fatalf("expected r1, r2 oldest first, got %+v", records)
+	}
+	if records[0].Status != "completed" || string(records[0].Data) != `{"id":"r1","status":"completed"}` {
+		t.Fatalf("expected replaced r1, got %+v", records[0])
+	}
+	if !records[0].StartedAt.Equal(base) {
+		t.Fatalf("expected started_at %v, got %v", base, records[0].StartedAt)
+	}
+
+	if err := store.Delete(ctx, "r1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	records, err = store.All(ctx)
+	if err != nil || len(records) != 1 || records[0].ID != "r2" {
+		t.Fatalf("expected only r2 after delete, got %+v (err %v)", records, err)
+	}
+}
@@ -0,0 +1,286 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package cron parses five-field cron expressions and computes when they next
+// fire.
+package cron
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression. Times are evaluated in the location
+// of the time passed to Next.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record unrestricted day fields: when both day
+	// fields are restricted a day matching either one fires, as in Vixie cron.
+	domStar, dowStar bool
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// dowField accepts 7 as a second spelling of Sunday.
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a standard five-field expression (minute, hour, day of month,
+// month, day of week) or one of the @yearly, @monthly, @weekly, @daily and
+// @hourly macros. Fields accept *, numbers, names for months and weekdays,
+// ranges (a-b), lists (a,b) and steps (*/n, a-b/n).
+func Parse(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := macros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return Schedule{}, fmt.Errorf("cron expression %q must have 5 fields, got %d", expr, len(parts))
+	}
+	var (
+		s   Schedule
+		err error
+	)
+	if s.minute, err = minuteField.parse(parts[0]); err != nil {
+		return Schedule{}, err
+	}
+	if s.hour, err = hourField.parse(parts[1]); err != nil {
+		return Schedule{}, err
+	}
+	if s.dom, err = domField.parse(parts[2]); err != nil {
+		return Schedule{}, err
+	}
+	if s.month, err = monthField.parse(parts[3]); err != nil {
+		return Schedule{}, err
+	}
+	if s.dow, err = dowField.parse(parts[4]); err != nil {
+		return Schedule{}, err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = strings.HasPrefix(parts[2], "*")
+	s.dowStar = strings.HasPrefix(parts[4], "*")
+	return s, nil
+}
+
+func (f field) parse(spec string) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(spec, ",") {
+		lo, hi, step := f.min, f.max, 1
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid %s step %q", f.name, stepStr)
+			}
+			step = n
+		}
+		if rng != "*" {
+			start, end, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(start); err != nil {
+				return 0, err
+			}
+			switch {
+			case isRange:
+				if hi, err = f.value(end); err != nil {
+					return 0, err
+				}
+			case !hasStep:
+				hi = lo
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid %s range %q", f.name, rng)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (f field) value(s string) (int, error) {
+	if n, ok := f.names[strings.ToLower(s)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("invalid %s %q (want %d-%d)", f.name, s, f.min, f.max)
+	}
+	return n, nil
+}
+
+// searchDays bounds how far Next looks ahead, so expressions that can never
+// fire, such as February 30th, end the search.
+const searchDays = 5 * 366
+
+// DST policies for wall-clock times that a daylight saving change skips or
+// repeats.
+const (
+	// DSTSkip does not fire times skipped by a change.
+	DSTSkip = "skip"
+	// DSTShift fires skipped times at the instant the change ends the gap.
+	DSTShift = "shift"
+	// DSTOnce fires repeated times at their first occurrence.
+	DSTOnce = "once"
+	// DSTTwice fires repeated times at both occurrences.
+	DSTTwice = "twice"
+)
+
+// DSTPolicy decides how times affected by daylight saving changes fire.
+// Skipped is DSTSkip (the default) or DSTShift; Repeated is DSTOnce (the
+// default) or DSTTwice.
+type DSTPolicy struct {
+	Skipped  string
+	Repeated string
+}
+
+// Validate reports whether p names known policies.
+func (p DSTPolicy) Validate() error {
+	switch p.Skipped {
+	case "", DSTSkip, DSTShift:
+	default:
+		return fmt.Errorf("dst skipped must be %s or %s", DSTSkip, DSTShift)
+	}
+	switch p.Repeated {
+	case "", DSTOnce, DSTTwice:
+	default:
+		return fmt.Errorf("dst repeated must be %s or %s", DSTOnce, DSTTwice)
+	}
+	return nil
+}
+
+// Next returns the first activation strictly after t, in t's location, or
+// the zero time if there is none within five years. Wall-clock times skipped
+// by a daylight saving change do not fire; repeated ones fire once.
+func (s Schedule) Next(t time.Time) time.Time {
+	return s.NextDST(t, DSTPolicy{})
+}
+
+// NextDST is Next with the daylight saving policy p.
+func (s Schedule) NextDST(t time.Time, p DSTPolicy) time.Time {
+	loc := t.Location()
+	y, m, d := t.Date()
+	for i := 0; i < searchDays; i++ {
+		// Noon is never skipped by daylight saving changes.
+		day := time.Date(y, m, d+i, 12, 0, 0, 0, loc)
+		if !s.dayMatches(day) {
+			continue
+		}
+		for hour := 0; hour < 24; hour++ {
+			if s.hour&(1<<uint(hour)) == 0 {
+				continue
+			}
+			for minute := 0; minute < 60; minute++ {
+				if s.minute&(1<<uint(minute)) == 0 {
+					continue
+				}
+				wall := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, time.UTC)
+				for _, at := range fireTimes(wall, loc, p) {
+					if at.After(t) {
+						return at
+					}
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// fireTimes returns the instants, in order, at which the wall-clock time wall
+// (given in UTC) fires in loc under p.
+func fireTimes(wall time.Time, loc *time.Location, p DSTPolicy) []time.Time {
+	occurrences := wallOccurrences(wall, loc)
+	switch {
+	case len(occurrences) == 0 && p.Skipped == DSTShift:
+		return []time.Time{gapEnd(wall, loc)}
+	case len(occurrences) > 1 && p.Repeated != DSTTwice:
+		return occurrences[:1]
+	}
+	return occurrences
+}
+
+// wallOccurrences returns the instants whose wall-clock time in loc is wall:
+// none inside a daylight saving gap, two inside an overlap.
+func wallOccurrences(wall time.Time, loc *time.Location) []time.Time {
+	var out []time.Time
+	seen := map[int]bool{}
+	// The offsets in effect half a day either side cover any transition
+	// near wall.
+	for _, probe := range []time.Duration{-12 * time.Hour, 0, 12 * time.Hour} {
+		_, offset := wall.Add(probe).In(loc).Zone()
+		if seen[offset] {
+			continue
+		}
+		seen[offset] = true
+		at := wall.Add(-time.Duration(offset) * time.Second).In(loc)
+		if localWall(at).Equal(wall) {
+			out = append(out, at)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return out
+}
+
+// gapEnd returns the first instant whose wall-clock time in loc is at or
+// after wall, which for a time skipped by a daylight saving change is the
+// moment the change happens.
+func gapEnd(wall time.Time, loc *time.Location) time.Time {
+	lo, hi := wall.Add(-15*time.Hour), wall.Add(15*time.Hour)
+	for hi.Sub(lo) > time.Second {
+		mid := lo.Add(hi.Sub(lo) / 2)
+		if localWall(mid.In(loc)).Before(wall) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi.Truncate(time.Second).In(loc)
+}
+
+// localWall returns t's wall-clock time as the same reading in UTC.
+func localWall(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+func (s Schedule) dayMatches(day time.Time) bool {
+	if s.month&(1<<uint(day.Month())) == 0 {
+		return false
+	}
+	dom := s.dom&(1<<uint(day.Day())) != 0
+	dow := s.dow&(1<<uint(day.Weekday())) != 0
+	if !s.domStar && !s.dowStar {
+		return dom || dow
+	}
+	return dom && dow
+}
@@ -0,0 +1,111 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	base := time.Date(2025, time.January, 15, 10, 7, 30, 0, time.UTC) // Wednesday
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 1, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 1, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2025, 1, 16, 2, 0, 0, 0, time.UTC)},
+		{"30 9-17/4 * * mon-fri", time.Date(2025, 1, 15, 13, 30, 0, 0, time.UTC)},
+		{"0 0 1 feb,mar *", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2025, 1, 19, 12, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 5", time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tc := range cases {
+		s, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.expr, err)
+		}
+		if got := s.Next(base); !got.Equal(tc.want) {
+			t.Errorf("%q: expected %v, got %v", tc.expr, tc.want, got)
+		}
+	}
+}
+
+func TestScheduleNextInLocation(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	s, err := Parse("30 2 * * *")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	// 02:30 does not exist on 2025-03-30 in Berlin.
+	got := s.Next(time.Date(2025, time.March, 29, 12, 0, 0, 0, loc))
+	if want := time.Date(2025, time.March, 31, 2, 30, 0, 0, loc); !got.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got.UTC().Hour() != 0 {
+		t.Fatalf("expected 00:30 UTC, got %v", got.UTC())
+	}
+}
+
+func TestScheduleNextDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	s, err := Parse("30 2 * * *")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	utc := func(day, hour, minute int, month time.Month) time.Time {
+		return time.Date(2025, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	cases := []struct {
+		name   string
+		policy DSTPolicy
+		from   time.Time
+		want   []time.Time
+	}{
+		// Clocks jump from 02:00 to 03:00 (01:00 UTC) on 2025-03-30.
+		{"gap skip", DSTPolicy{}, utc(29, 12, 0, time.March),
+			[]time.Time{utc(31, 0, 30, time.March)}},
+		{"gap shift", DSTPolicy{Skipped: DSTShift}, utc(29, 12, 0, time.March),
+			[]time.Time{utc(30, 1, 0, time.March), utc(31, 0, 30, time.March)}},
+		// Clocks fall back from 03:00 to 02:00 (01:00 UTC) on 2025-10-26.
+		{"overlap once", DSTPolicy{}, utc(25, 12, 0, time.October),
+			[]time.Time{utc(26, 0, 30, time.October), utc(27, 1, 30, time.October)}},
+		{"overlap twice", DSTPolicy{Repeated: DSTTwice}, utc(25, 12, 0, time.October),
+			[]time.Time{utc(26, 0, 30, time.October), utc(26, 1, 30, time.October), utc(27, 1, 30, time.October)}},
+	}
+	for _, tc := range cases {
+		at := tc.from.In(loc)
+		for i, want := range tc.want {
+			at = s.NextDST(at, tc.policy)
+			if !at.Equal(want) {
+				t.Fatalf("%s: fire %d: expected %v, got %v", tc.name, i, want, at.UTC())
+			}
+			if at.Location() != loc {
+				t.Fatalf("%s: expected times in %v, got %v", tc.name, loc, at.Location())
+			}
+		}
+	}
+	if err := (DSTPolicy{Skipped: "run"}).Validate(); err == nil {
+		t.Fatal("expected unknown skipped policy to be rejected")
+	}
+	if err := (DSTPolicy{Repeated: "both"}).Validate(); err == nil {
+		t.Fatal("expected unknown repeated policy to be rejected")
+	}
+}
+
+func TestParseRejectsInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *", "@often"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("expected %q to be rejected", expr)
+		}
+	}
+}
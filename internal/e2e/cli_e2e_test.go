@@ -1291,6 +1291,7 @@ func awaitRunCompletionWithAuth(t *testing.T, client *http.Client, addr, runID,
 	t.Helper()
 	url := fmt.Sprintf("http://%s/runs/%s", addr, runID)
 	deadline := time.Now().Add(15 * time.Second)
+	etag := ""
 	for time.Now().Before(deadline) {
 		req, err := http.NewRequest(http.MethodGet, url, nil)
 		if err != nil {
@@ -1299,6 +1300,9 @@ func awaitRunCompletionWithAuth(t *testing.T, client *http.Client, addr, runID,
 		if authHeader != "" {
 			req.Header.Set("Authorization", authHeader)
 		}
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
 		resp, err := client.Do(req)
 		if err != nil {
 			time.Sleep(100 * time.Millisecond)
@@ -1314,6 +1318,7 @@ func awaitRunCompletionWithAuth(t *testing.T, client *http.Client, addr, runID,
 			time.Sleep(100 * time.Millisecond)
 			continue
 		}
+		etag = resp.Header.Get("ETag")
 		var payload struct {
 			Status string `json:"status"`
 		}
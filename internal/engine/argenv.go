@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// ArgEnvNames returns, for each scalar arg of spec, the environment
+// variables its value is exported as under the job's arg_env mapping:
+// ARG_<NAME> unless dropped, plus the mapped name. It fails when two args, or
+// an arg and the job's env, would set the same variable, or when a mapped
+// name shadows PATH or a FLWD_ variable.
+func ArgEnvNames(spec types.ArgSpec, cfg *types.Config) (map[string][]string, error) {
+	var mapping types.ArgEnvConfig
+	var jobEnv map[string]string
+	if cfg != nil {
+		if cfg.ArgEnv != nil {
+			mapping = *cfg.ArgEnv
+		}
+		jobEnv = cfg.Env
+	}
+	out := make(map[string][]string)
+	owner := make(map[string]string)
+	claim := func(arg, name string) error {
+		if other, ok := owner[name]; ok {
+			return fmt.Errorf("args %s and %s both map to %s", other, arg, name)
+		}
+		owner[name] = arg
+		out[arg] = append(out[arg], name)
+		return nil
+	}
+	for _, a := range spec.Args {
+		if !scalarEnvArg(a) {
+			continue
+		}
+		mapped, isMapped := mapping.Names[a.Name]
+		if !isMapped || !mapping.DropArgVars {
+			if err := claim(a.Name, argEnvName(a.Name)); err != nil {
+				return nil, err
+			}
+		}
+		if !isMapped {
+			continue
+		}
+		if mapped == "PATH" || strings.HasPrefix(mapped, "FLWD_") {
+			return nil, fmt.Errorf("arg %s cannot map to reserved variable %s", a.Name, mapped)
+		}
+		if _, ok := jobEnv[mapped]; ok {
+			return nil, fmt.Errorf("arg %s maps to %s, which the job env also sets", a.Name, mapped)
+		}
+		if err := claim(a.Name, mapped); err != nil {
+			return nil, err
+		}
+	}
+	for _, names := range out {
+		sort.Strings(names)
+	}
+	return out, nil
+}
+
+// ApplyArgEnv re-keys b.ScalarEnv by the names from ArgEnvNames.
+func ApplyArgEnv(b *Binding, names map[string][]string) {
+	if b == nil {
+		return
+	}
+	env := make(map[string]string, len(b.ScalarEnv))
+	for arg, vars := range names {
+		value, ok := b.ScalarEnv[argEnvName(arg)]
+		if !ok {
+			continue
+		}
+		for _, name := range vars {
+			env[name] = value
+		}
+	}
+	b.ScalarEnv = env
+}
+
+// scalarEnvArg reports whether ValidateAndBind exports a's value as an
+// environment variable.
+func scalarEnvArg(a types.Arg) bool {
+	switch a.Type {
+	case "string":
+		return !isSecret(a.Format, a.Secret)
+	case "boolean", "integer":
+		return true
+	}
+	return false
+}
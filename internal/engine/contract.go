@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package engine
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// OutputsFile is the name of the file, inside the run directory, that scripts
+// append `name=value` lines to. Its path is exported as $FLWD_OUTPUTS.
+const OutputsFile = "outputs.env"
+
+// ContractViolation describes one way a run's outputs break the producing
+// job's declared outputs or the argument types of the job consuming them.
+type ContractViolation struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ParseOutputs reads `name=value` lines. Blank lines and lines starting with
+// # are ignored; a later value for the same name replaces an earlier one.
+func ParseOutputs(r io.Reader) (map[string]string, error) {
+	out := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(text, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("outputs line %d: expected name=value", line)
+		}
+		out[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read outputs: %w", err)
+	}
+	return out, nil
+}
+
+// ValidateOutputs converts raw outputs to their declared types. Missing
+// required outputs, undeclared outputs and unparsable values are violations;
+// violations are sorted by field for stable reporting.
+func ValidateOutputs(specs []types.OutputSpec, raw map[string]string) (map[string]any, []ContractViolation) {
+	values := make(map[string]any, len(raw))
+	var violations []ContractViolation
+	declared := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		declared[spec.Name] = true
+		rawVal, ok := raw[spec.Name]
+		if !ok {
+			if spec.Required {
+				violations = append(violations, ContractViolation{Field: spec.Name, Code: "output.missing", Message: "required output was not written"})
+			}
+			continue
+		}
+		val, err := convertOutput(spec.Type, rawVal)
+		if err != nil {
+			violations = append(violations, ContractViolation{Field: spec.Name, Code: "output.type", Message: err.Error()})
+			continue
+		}
+		values[spec.Name] = val
+	}
+	for name := range raw {
+		if !declared[name] {
+			violations = append(violations, ContractViolation{Field: name, Code: "output.undeclared", Message: "output is not declared in outputs:"})
+		}
+	}
+	sort.Slice(violations, func(i, j int) bool { return violations[i].Field < violations[j].Field })
+	return values, violations
+}
+
+// CheckInputs compares an upstream job's declared outputs with the args of
+// the job that consumes them. Outputs feed args of the same name, so a shared
+// name must agree on type. Args without a matching output are left to the
+// caller and to normal argument validation.
+func CheckInputs(outputs []types.OutputSpec, spec *types.ArgSpec) []ContractViolation {
+	if spec == nil {
+		return nil
+	}
+	byName := make(map[string]types.OutputSpec, len(outputs))
+	for _, out := range outputs {
+		byName[out.Name] = out
+	}
+	var violations []ContractViolation
+	for _, arg := range spec.Args {
+		out, ok := byName[arg.Name]
+		if !ok || out.Type == arg.Type {
+			continue
+		}
+		violations = append(violations, ContractViolation{
+			Field:   arg.Name,
+			Code:    "input.type",
+			Message: fmt.Sprintf("upstream output is %s but argument expects %s", out.Type, arg.Type),
+		})
+	}
+	return violations
+}
+
+// InputArgs selects the upstream output values that match an arg of spec.
+func InputArgs(outputs map[string]any, spec *types.ArgSpec) map[string]any {
+	if spec == nil || len(outputs) == 0 {
+		return nil
+	}
+	args := map[string]any{}
+	for _, arg := range spec.Args {
+		if val, ok := outputs[arg.Name]; ok {
+			args[arg.Name] = normalizeOutputValue(arg.Type, val)
+		}
+	}
+	return args
+}
+
+func convertOutput(typ, raw string) (any, error) {
+	switch typ {
+	case "", "string":
+		return raw, nil
+	case "integer":
+		v, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not an integer", raw)
+		}
+		return v, nil
+	case "boolean":
+		v, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not a boolean", raw)
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unsupported output type %q", typ)
+	}
+}
+
+// normalizeOutputValue undoes JSON round-trips of stored outputs, which turn
+// integers into float64.
+func normalizeOutputValue(typ string, val any) any {
+	if f, ok := val.(float64); ok && typ == "integer" && f == math.Trunc(f) {
+		return int64(f)
+	}
+	return val
+}
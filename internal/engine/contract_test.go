@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+func TestParseOutputsSkipsCommentsAndKeepsLastValue(t *testing.T) {
+	raw, err := ParseOutputs(strings.NewReader("# produced by build\nversion=1.0\n\nurl=https://x/?a=b\nversion=1.1\n"))
+	if err != nil {
+		t.Fatalf("ParseOutputs: %v", err)
+	}
+	if raw["version"] != "1.1" || raw["url"] != "https://x/?a=b" || len(raw) != 2 {
+		t.Fatalf("unexpected outputs %v", raw)
+	}
+	if _, err := ParseOutputs(strings.NewReader("no separator\n")); err == nil {
+		t.Fatalf("expected malformed line to fail")
+	}
+}
+
+func TestValidateOutputsConvertsTypesAndReportsViolations(t *testing.T) {
+	specs := []types.OutputSpec{
+		{Name: "count", Type: "integer", Required: true},
+		{Name: "ok", Type: "boolean"},
+		{Name: "tag", Type: "string", Required: true},
+	}
+	values, violations := ValidateOutputs(specs, map[string]string{"count": "3", "ok": "true", "tag": "v1"})
+	if len(violations) != 0 || values["count"] != int64(3) || values["ok"] != true || values["tag"] != "v1" {
+		t.Fatalf("unexpected result %v %v", values, violations)
+	}
+	_, violations = ValidateOutputs(specs, map[string]string{"count": "three", "extra": "x"})
+	codes := make([]string, 0, len(violations))
+	for _, v := range violations {
+		codes = append(codes, v.Field+":"+v.Code)
+	}
+	if got := strings.Join(codes, ","); got != "count:output.type,extra:output.undeclared,tag:output.missing" {
+		t.Fatalf("unexpected violations %s", got)
+	}
+}
+
+func TestCheckInputsAndInputArgs(t *testing.T) {
+	outputs := []types.OutputSpec{{Name: "size", Type: "integer"}, {Name: "tag", Type: "string"}}
+	spec := &types.ArgSpec{Args: []types.Arg{{Name: "size", Type: "string"}, {Name: "env", Type: "string"}}}
+	violations := CheckInputs(outputs, spec)
+	if len(violations) != 1 || violations[0].Field != "size" || violations[0].Code != "input.type" {
+		t.Fatalf("unexpected violations %+v", violations)
+	}
+
+	spec.Args[0].Type = "integer"
+	args := InputArgs(map[string]any{"size": float64(7), "tag": "v1"}, spec)
+	if len(args) != 1 || args["size"] != int64(7) {
+		t.Fatalf("expected only matching args with integers restored, got %v", args)
+	}
+}
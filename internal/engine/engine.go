@@ -16,6 +16,12 @@ type Binding struct {
 	ScalarEnv    map[string]string // ARG_<UPPER> for scalar types only
 	SecretNames  map[string]struct{}
 	SecretValues []string
+	// SecretRefs maps secret args declaring from to their reference; the
+	// values are resolved when the run starts and never bound here.
+	SecretRefs map[string]string
+	// Warnings lists violations of rules marked severity: warning and uses
+	// of deprecated args; they do not fail validation.
+	Warnings []types.ArgWarning
 }
 
 type ArgError struct {
@@ -30,11 +36,35 @@ func ValidateAndBind(flags *pflag.FlagSet, spec types.ArgSpec) (*Binding, error)
 	vals := make(map[string]interface{})
 	scalars := make(map[string]string)
 	secretNames := make(map[string]struct{})
+	secretRefs := make(map[string]string)
 	var secretValues []string
+	var warnings []types.ArgWarning
+
+	// violate fails validation for a, or records a warning when a's rules
+	// are marked severity: warning.
+	violate := func(a types.Arg, code, msg string) error {
+		if a.Severity == types.ArgSeverityWarning {
+			warnings = append(warnings, types.ArgWarning{Arg: a.Name, Code: code, Message: msg})
+			return nil
+		}
+		return &ArgError{Arg: a.Name, Msg: msg}
+	}
 
 	for _, a := range spec.Args {
 		name := a.Name
 		provided := flags.Changed(name)
+		if provided && a.Deprecated != "" {
+			warnings = append(warnings, types.ArgWarning{Arg: name, Code: "arg.deprecated", Message: a.Deprecated})
+		}
+
+		if a.From != "" {
+			if provided {
+				return nil, &ArgError{Arg: name, Msg: fmt.Sprintf("value is resolved from %s and may not be supplied", a.From)}
+			}
+			secretNames[name] = struct{}{}
+			secretRefs[name] = a.From
+			continue
+		}
 
 		// secret defaults are forbidden
 		if (a.Format == "secret" || a.Secret) && a.Default != nil {
@@ -50,11 +80,15 @@ func ValidateAndBind(flags *pflag.FlagSet, spec types.ArgSpec) (*Binding, error)
 				v, _ = a.Default.(string)
 			}
 			if a.Required && v == "" {
-				return nil, &ArgError{Arg: name, Msg: "required"}
+				if err := violate(a, "arg.required", "required"); err != nil {
+					return nil, err
+				}
 			}
 			if len(a.Enum) > 0 && v != "" {
 				if !contains(a.Enum, v) {
-					return nil, &ArgError{Arg: name, Msg: fmt.Sprintf("value %q not in enum", v)}
+					if err := violate(a, "arg.enum", fmt.Sprintf("value %q not in enum", v)); err != nil {
+						return nil, err
+					}
 				}
 			}
 			vals[name] = v
@@ -77,7 +111,9 @@ func ValidateAndBind(flags *pflag.FlagSet, spec types.ArgSpec) (*Binding, error)
 				}
 			}
 			if a.Required && !provided && a.Default == nil {
-				return nil, &ArgError{Arg: name, Msg: "required"}
+				if err := violate(a, "arg.required", "required"); err != nil {
+					return nil, err
+				}
 			}
 			vals[name] = v
 			scalars[argEnvName(name)] = fmt.Sprintf("%t", v)
@@ -97,7 +133,9 @@ func ValidateAndBind(flags *pflag.FlagSet, spec types.ArgSpec) (*Binding, error)
 				}
 			}
 			if a.Required && !provided && a.Default == nil {
-				return nil, &ArgError{Arg: name, Msg: "required"}
+				if err := violate(a, "arg.required", "required"); err != nil {
+					return nil, err
+				}
 			}
 			vals[name] = v
 			scalars[argEnvName(name)] = fmt.Sprintf("%d", v)
@@ -124,7 +162,9 @@ func ValidateAndBind(flags *pflag.FlagSet, spec types.ArgSpec) (*Binding, error)
 				}
 			}
 			if a.Required && len(arr) == 0 {
-				return nil, &ArgError{Arg: name, Msg: "required"}
+				if err := violate(a, "arg.required", "required"); err != nil {
+					return nil, err
+				}
 			}
 			if a.ItemsType != "" && a.ItemsType != "string" {
 				return nil, &ArgError{Arg: name, Msg: "items_type not supported in Phase 1"}
@@ -132,7 +172,9 @@ func ValidateAndBind(flags *pflag.FlagSet, spec types.ArgSpec) (*Binding, error)
 			if len(a.ItemsEnum) > 0 {
 				for _, it := range arr {
 					if !contains(a.ItemsEnum, it) {
-						return nil, &ArgError{Arg: name, Msg: fmt.Sprintf("item %q not in items_enum", it)}
+						if err := violate(a, "arg.enum", fmt.Sprintf("item %q not in items_enum", it)); err != nil {
+							return nil, err
+						}
 					}
 				}
 			}
@@ -155,7 +197,9 @@ func ValidateAndBind(flags *pflag.FlagSet, spec types.ArgSpec) (*Binding, error)
 				m[k] = v
 			}
 			if a.Required && len(m) == 0 {
-				return nil, &ArgError{Arg: name, Msg: "required"}
+				if err := violate(a, "arg.required", "required"); err != nil {
+					return nil, err
+				}
 			}
 			if a.ValueType != "" && a.ValueType != "string" {
 				return nil, &ArgError{Arg: name, Msg: "value_type not supported in Phase 1"}
@@ -179,6 +223,10 @@ func ValidateAndBind(flags *pflag.FlagSet, spec types.ArgSpec) (*Binding, error)
 	if len(secretValues) > 0 {
 		b.SecretValues = secretValues
 	}
+	if len(secretRefs) > 0 {
+		b.SecretRefs = secretRefs
+	}
+	b.Warnings = warnings
 	return b, nil
 }
 
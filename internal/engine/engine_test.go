@@ -50,6 +50,37 @@ func TestValidateAndBind_SecretDefaultForbidden(t *testing.T) {
 	}
 }
 
+func TestValidateAndBind_SecretFromReference(t *testing.T) {
+	spec := types.ArgSpec{Args: []types.Arg{{
+		Name:     "db_password",
+		Type:     "string",
+		Secret:   true,
+		Required: true,
+		From:     "vault:apps/db/password",
+	}}}
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("db_password", "", "")
+	bind, err := ValidateAndBind(flags, spec)
+	if err != nil {
+		t.Fatalf("expected referenced secret to need no value, got %v", err)
+	}
+	if bind.SecretRefs["db_password"] != "vault:apps/db/password" {
+		t.Fatalf("expected secret reference, got %+v", bind.SecretRefs)
+	}
+	if _, ok := bind.SecretNames["db_password"]; !ok {
+		t.Fatalf("expected db_password to be a secret, got %+v", bind.SecretNames)
+	}
+	if _, ok := bind.Values["db_password"]; ok {
+		t.Fatalf("expected no bound value, got %+v", bind.Values)
+	}
+
+	_ = flags.Set("db_password", "hunter2")
+	if _, err := ValidateAndBind(flags, spec); err == nil {
+		t.Fatalf("expected supplied value for referenced secret to be rejected")
+	}
+}
+
 func TestValidateAndBind_ArrayItemsEnum(t *testing.T) {
 	spec := types.ArgSpec{Args: []types.Arg{{
 		Name:      "tags",
@@ -97,3 +128,85 @@ func TestValidateAndBind_ObjectRequiresKV(t *testing.T) {
 		t.Fatalf("expected error for invalid pair")
 	}
 }
+
+func TestValidateAndBind_WarningSeverity(t *testing.T) {
+	spec := types.ArgSpec{Args: []types.Arg{
+		{Name: "mode", Type: "string", Enum: []string{"quick", "full"}, Severity: types.ArgSeverityWarning},
+		{Name: "zone", Type: "string", Deprecated: "use region instead"},
+		{Name: "region", Type: "string", Required: true, Severity: types.ArgSeverityWarning},
+	}}
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("mode", "", "")
+	flags.String("zone", "", "")
+	flags.String("region", "", "")
+	_ = flags.Set("mode", "slow")
+	_ = flags.Set("zone", "b")
+
+	bind, err := ValidateAndBind(flags, spec)
+	if err != nil {
+		t.Fatalf("expected warnings only, got %v", err)
+	}
+	if got := bind.Values["mode"]; got != "slow" {
+		t.Fatalf("expected mode bound despite the warning, got %v", got)
+	}
+	want := []types.ArgWarning{
+		{Arg: "mode", Code: "arg.enum", Message: `value "slow" not in enum`},
+		{Arg: "zone", Code: "arg.deprecated", Message: "use region instead"},
+		{Arg: "region", Code: "arg.required", Message: "required"},
+	}
+	if len(bind.Warnings) != len(want) {
+		t.Fatalf("expected %d warnings, got %+v", len(want), bind.Warnings)
+	}
+	for i, w := range want {
+		if bind.Warnings[i] != w {
+			t.Fatalf("warning %d: expected %+v, got %+v", i, w, bind.Warnings[i])
+		}
+	}
+
+	spec.Args[0].Severity = types.ArgSeverityError
+	if _, err := ValidateAndBind(flags, spec); err == nil {
+		t.Fatalf("expected enum violation to fail with error severity")
+	}
+}
+
+func TestArgEnvNames(t *testing.T) {
+	spec := types.ArgSpec{Args: []types.Arg{
+		{Name: "region", Type: "string"},
+		{Name: "dry-run", Type: "boolean"},
+		{Name: "token", Type: "string", Secret: true},
+	}}
+	cfg := &types.Config{ArgEnv: &types.ArgEnvConfig{Names: map[string]string{"region": "DEPLOY_REGION"}}}
+
+	names, err := ArgEnvNames(spec, cfg)
+	if err != nil {
+		t.Fatalf("ArgEnvNames: %v", err)
+	}
+	if got := names["region"]; len(got) != 2 || got[0] != "ARG_REGION" || got[1] != "DEPLOY_REGION" {
+		t.Fatalf("unexpected region names %v", got)
+	}
+	if _, ok := names["token"]; ok {
+		t.Fatalf("secret args must not be exported")
+	}
+
+	bind := &Binding{ScalarEnv: map[string]string{"ARG_REGION": "eu", "ARG_DRY_RUN": "true"}}
+	cfg.ArgEnv.DropArgVars = true
+	names, err = ArgEnvNames(spec, cfg)
+	if err != nil {
+		t.Fatalf("ArgEnvNames: %v", err)
+	}
+	ApplyArgEnv(bind, names)
+	if len(bind.ScalarEnv) != 2 || bind.ScalarEnv["DEPLOY_REGION"] != "eu" || bind.ScalarEnv["ARG_DRY_RUN"] != "true" {
+		t.Fatalf("unexpected env %v", bind.ScalarEnv)
+	}
+
+	cfg.ArgEnv.Names["dry-run"] = "DEPLOY_REGION"
+	if _, err := ArgEnvNames(spec, cfg); err == nil {
+		t.Fatalf("expected two args mapped to one name to collide")
+	}
+	delete(cfg.ArgEnv.Names, "dry-run")
+	cfg.Env = map[string]string{"DEPLOY_REGION": "us"}
+	if _, err := ArgEnvNames(spec, cfg); err == nil {
+		t.Fatalf("expected a collision with the job env")
+	}
+}
@@ -28,6 +28,58 @@ func BuildPlan(jobID string, cfg *types.Config, spec *types.ArgSpec, bind *Bindi
 		if strings.HasPrefix(cfg.Interpreter, "container:") {
 			plan.ExecutorPreview["container_image"] = strings.TrimPrefix(cfg.Interpreter, "container:")
 		}
+		if strings.EqualFold(cfg.Executor, "kubernetes") {
+			if cfg.Container != nil && cfg.Container.Image != "" {
+				plan.ExecutorPreview["container_image"] = cfg.Container.Image
+			}
+			if k := cfg.Kubernetes; k != nil {
+				placement := map[string]interface{}{}
+				if k.Context != "" {
+					placement["context"] = k.Context
+				}
+				if k.Namespace != "" {
+					placement["namespace"] = k.Namespace
+				}
+				if k.ServiceAccount != "" {
+					placement["service_account"] = k.ServiceAccount
+				}
+				if len(k.NodeSelector) > 0 {
+					placement["node_selector"] = k.NodeSelector
+				}
+				plan.ExecutorPreview["kubernetes"] = placement
+			}
+		}
+		if strings.EqualFold(cfg.Executor, "ssh") && cfg.SSH != nil {
+			target := map[string]interface{}{"host": cfg.SSH.Host, "auth": "agent"}
+			if cfg.SSH.Port != 0 {
+				target["port"] = cfg.SSH.Port
+			}
+			if cfg.SSH.User != "" {
+				target["user"] = cfg.SSH.User
+			}
+			if cfg.SSH.IdentityFile != "" {
+				target["auth"] = "identity_file"
+			}
+			plan.ExecutorPreview["ssh"] = target
+		}
+		if cfg.ArgsStyle != "" {
+			plan.ExecutorPreview["args_style"] = cfg.ArgsStyle
+		}
+		if cfg.Timeout != "" {
+			plan.ExecutorPreview["timeout"] = cfg.Timeout
+		}
+		if cfg.ArgEnv != nil && spec != nil {
+			if names, err := ArgEnvNames(*spec, cfg); err == nil {
+				plan.ExecutorPreview["arg_env"] = names
+			}
+		}
+		if cfg.Impact != nil {
+			plan.Impact = &types.ImpactPreview{
+				Environments:         cfg.Impact.Environments,
+				BlastRadius:          cfg.Impact.BlastRadius,
+				ConfirmationRequired: cfg.Impact.High(),
+			}
+		}
 	}
 
 	if bind != nil && spec != nil {
@@ -43,6 +95,7 @@ func BuildPlan(jobID string, cfg *types.Config, spec *types.ArgSpec, bind *Bindi
 		if len(resolved) > 0 {
 			plan.ResolvedArgs = resolved
 		}
+		plan.Warnings = bind.Warnings
 	}
 
 	return plan
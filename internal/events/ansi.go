@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package events
+
+import "io"
+
+// ansiState tracks where an ANSIStripper is inside an escape sequence, so
+// sequences split across writes are still removed.
+type ansiState uint8
+
+const (
+	ansiText ansiState = iota
+	ansiEscape
+	ansiCSI
+	// ansiString covers OSC, DCS, SOS, PM and APC payloads, which run until
+	// BEL or ST (ESC \).
+	ansiString
+	ansiStringEscape
+)
+
+// ANSIStripper removes ANSI escape sequences (colors, cursor movement,
+// terminal titles, hyperlinks) from the output written through it.
+type ANSIStripper struct {
+	out   io.Writer
+	state ansiState
+	buf   []byte
+}
+
+// NewANSIStripper returns a writer that strips escape sequences before
+// passing output on to out.
+func NewANSIStripper(out io.Writer) *ANSIStripper {
+	return &ANSIStripper{out: out}
+}
+
+func (s *ANSIStripper) Write(p []byte) (int, error) {
+	s.buf = s.buf[:0]
+	for _, b := range p {
+		switch s.state {
+		case ansiText:
+			if b == 0x1b {
+				s.state = ansiEscape
+				continue
+			}
+			s.buf = append(s.buf, b)
+		case ansiEscape:
+			switch {
+			case b == '[':
+				s.state = ansiCSI
+			case b == ']' || b == 'P' || b == 'X' || b == '^' || b == '_':
+				s.state = ansiString
+			case b >= 0x20 && b <= 0x2f:
+				// Intermediate bytes, e.g. the "(" of a charset selection.
+			default:
+				s.state = ansiText
+			}
+		case ansiCSI:
+			if b >= 0x40 && b <= 0x7e {
+				s.state = ansiText
+			}
+		case ansiString:
+			switch b {
+			case 0x07:
+				s.state = ansiText
+			case 0x1b:
+				s.state = ansiStringEscape
+			}
+		case ansiStringEscape:
+			if b == '\\' {
+				s.state = ansiText
+			} else {
+				s.state = ansiString
+			}
+		}
+	}
+	if len(s.buf) > 0 {
+		if _, err := s.out.Write(s.buf); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
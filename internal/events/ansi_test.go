@@ -0,0 +1,28 @@
+package events
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestANSIStripperRemovesEscapeSequences(t *testing.T) {
+	var out bytes.Buffer
+	s := NewANSIStripper(&out)
+	writes := []string{
+		"\x1b[1;31mred\x1b[0m plain\n",
+		// A sequence split across writes, as pipes deliver it.
+		"\x1b[3", "2mgreen\x1b", "[0m\n",
+		"\x1b]0;title\x07\x1b]8;;https://x.test\x1b\\link\x1b]8;;\x1b\\\n",
+		"\x1b(Bcharset \x1b[2K\x1b[1Gdone\n",
+		"héllo\ttab\n",
+	}
+	for _, w := range writes {
+		if n, err := s.Write([]byte(w)); err != nil || n != len(w) {
+			t.Fatalf("write %q = %d, %v", w, n, err)
+		}
+	}
+	want := "red plain\ngreen\nlink\ncharset done\nhéllo\ttab\n"
+	if got := out.String(); got != want {
+		t.Fatalf("stripped output = %q, want %q", got, want)
+	}
+}
@@ -1,5 +1,7 @@
 package events
 
+import "time"
+
 // Sink represents something that can consume run events.
 type Sink interface {
 	EmitRunStart(runID, jobID string)
@@ -9,6 +11,36 @@ type Sink interface {
 	EmitStepFinish(runID, step string, exitCode int, err error)
 }
 
+// ImagePullSink is implemented by sinks that record container image pulls.
+type ImagePullSink interface {
+	EmitImagePullStart(runID, step, image string)
+	EmitImagePullProgress(runID, step, image string, progress ImagePullProgress)
+	EmitImagePullFinish(runID, step, image string, err error)
+}
+
+// ImagePullProgress reports layer and byte counts of an in-flight pull. Byte
+// counts are zero when the runtime does not expose them.
+type ImagePullProgress struct {
+	LayersTotal int
+	LayersDone  int
+	BytesDone   int64
+	BytesTotal  int64
+}
+
+// BinaryOutputSink is implemented by sinks that record step output that is
+// not text. size counts the bytes held back from step.log events; data holds
+// them, redacted, unless size exceeds BinaryInlineLimit, in which case it is
+// nil.
+type BinaryOutputSink interface {
+	EmitStepBinary(runID, step, channel string, size int, data []byte)
+}
+
+// StampedLogSink is implemented by sinks that record when a step.log line was
+// written and its number among the step's lines; see StepWriter.StampLines.
+type StampedLogSink interface {
+	EmitStepLogAt(runID, step, channel, message string, at time.Time, line int64)
+}
+
 // CompositeSink fan-outs emitted events to multiple sinks.
 type CompositeSink struct {
 	sinks []Sink
@@ -56,8 +88,50 @@ func (c *CompositeSink) EmitStepLog(runID, step, channel, message string) {
 	}
 }
 
+func (c *CompositeSink) EmitStepLogAt(runID, step, channel, message string, at time.Time, line int64) {
+	for _, s := range c.sinks {
+		if ss, ok := s.(StampedLogSink); ok {
+			ss.EmitStepLogAt(runID, step, channel, message, at, line)
+		} else {
+			s.EmitStepLog(runID, step, channel, message)
+		}
+	}
+}
+
 func (c *CompositeSink) EmitStepFinish(runID, step string, exitCode int, err error) {
 	for _, s := range c.sinks {
 		s.EmitStepFinish(runID, step, exitCode, err)
 	}
 }
+
+func (c *CompositeSink) EmitImagePullStart(runID, step, image string) {
+	for _, s := range c.sinks {
+		if ps, ok := s.(ImagePullSink); ok {
+			ps.EmitImagePullStart(runID, step, image)
+		}
+	}
+}
+
+func (c *CompositeSink) EmitImagePullProgress(runID, step, image string, progress ImagePullProgress) {
+	for _, s := range c.sinks {
+		if ps, ok := s.(ImagePullSink); ok {
+			ps.EmitImagePullProgress(runID, step, image, progress)
+		}
+	}
+}
+
+func (c *CompositeSink) EmitImagePullFinish(runID, step, image string, err error) {
+	for _, s := range c.sinks {
+		if ps, ok := s.(ImagePullSink); ok {
+			ps.EmitImagePullFinish(runID, step, image, err)
+		}
+	}
+}
+
+func (c *CompositeSink) EmitStepBinary(runID, step, channel string, size int, data []byte) {
+	for _, s := range c.sinks {
+		if bs, ok := s.(BinaryOutputSink); ok {
+			bs.EmitStepBinary(runID, step, channel, size, data)
+		}
+	}
+}
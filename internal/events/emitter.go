@@ -15,6 +15,7 @@ const (
 	TypeStepStart  = "step.start"
 	TypeStepLog    = "step.log"
 	TypeStepFinish = "step.finish"
+	TypeStepBinary = "step.output.binary"
 )
 
 type RunEvent struct {
@@ -127,6 +128,12 @@ func (e *Emitter) EmitStepLog(runID, step, channel, message string) {
 	e.emit(RunEvent{Type: TypeStepLog, RunID: runID, Step: step, Channel: channel, Message: message})
 }
 
+// EmitStepBinary notes binary output in place of its step.log lines; the
+// bytes themselves only reach the step's output files.
+func (e *Emitter) EmitStepBinary(runID, step, channel string, size int, data []byte) {
+	e.emit(RunEvent{Type: TypeStepBinary, RunID: runID, Step: step, Channel: channel, Data: map[string]interface{}{"bytes": size}})
+}
+
 func (e *Emitter) EmitStepFinish(runID, step string, exitCode int, err error) {
 	status := "completed"
 	if exitCode != 0 || err != nil {
@@ -3,9 +3,18 @@ package events
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 )
 
+// BinaryInlineLimit is the largest run of binary step output whose bytes
+// are passed to BinaryOutputSink; larger runs are only counted.
+const BinaryInlineLimit = 4 << 10
+
 type StepWriter struct {
 	emitter  Sink
 	runID    string
@@ -14,12 +23,43 @@ type StepWriter struct {
 	out      io.Writer
 	buf      bytes.Buffer
 	redactor func(string) string
+	maxLine  int
+	onCut    func()
+	// binary holds consecutive binary lines, with their newlines, until the
+	// next text line or Flush reports them; binaryLen counts them once
+	// binary is dropped for exceeding BinaryInlineLimit.
+	binary    []byte
+	binaryLen int
+	// seq numbers the step's lines and log receives them stamped; see
+	// StampLines.
+	seq *atomic.Int64
+	log io.Writer
 }
 
 func NewStepWriter(em Sink, runID, stepID, channel string, out io.Writer, redactor func(string) string) *StepWriter {
 	return &StepWriter{emitter: em, runID: runID, stepID: stepID, channel: channel, out: out, redactor: redactor}
 }
 
+// LimitLines truncates the lines emitted as step.log events to max bytes,
+// ending each cut line with a marker that counts the bytes dropped, and calls
+// truncated for every such line. out still receives the full output. A max
+// of zero or less leaves lines whole.
+func (w *StepWriter) LimitLines(max int, truncated func()) {
+	w.maxLine = max
+	w.onCut = truncated
+}
+
+// StampLines numbers every line of output from seq and writes it to log
+// prefixed with the time it ended, in RFC3339Nano, and "<step>#<number>". The
+// step's stdout and stderr writers share seq so their lines order within the
+// step; the time orders them against other steps writing to the same log.
+// step.log events carry the same time and number when the sink is a
+// StampedLogSink. log may be nil to stamp events only.
+func (w *StepWriter) StampLines(seq *atomic.Int64, log io.Writer) {
+	w.seq = seq
+	w.log = log
+}
+
 func (w *StepWriter) Write(p []byte) (int, error) {
 	if len(p) == 0 {
 		return 0, nil
@@ -33,7 +73,7 @@ func (w *StepWriter) Write(p []byte) (int, error) {
 	for i, b := range p {
 		if b == '\n' {
 			w.buf.Write(p[start:i])
-			w.flushLine()
+			w.flushLine(true)
 			start = i + 1
 		}
 	}
@@ -45,17 +85,74 @@ func (w *StepWriter) Write(p []byte) (int, error) {
 
 func (w *StepWriter) Flush() {
 	if w.buf.Len() > 0 {
-		w.flushLine()
+		w.flushLine(false)
 	}
+	w.flushBinary()
 }
 
-func (w *StepWriter) flushLine() {
+// flushLine emits the buffered line. Lines that are not valid UTF-8 or hold
+// NUL bytes are held back and reported through BinaryOutputSink instead, so
+// step.log events only ever carry text.
+func (w *StepWriter) flushLine(newline bool) {
 	line := w.buf.String()
 	w.buf.Reset()
-	if w.emitter != nil {
-		if w.redactor != nil {
-			line = w.redactor(line)
+	var at time.Time
+	var n int64
+	if w.seq != nil {
+		at, n = time.Now().UTC(), w.seq.Add(1)
+		if w.log != nil {
+			fmt.Fprintf(w.log, "%s %s#%d %s\n", at.Format(time.RFC3339Nano), w.stepID, n, line)
+		}
+	}
+	if w.emitter == nil {
+		return
+	}
+	if w.redactor != nil {
+		line = w.redactor(line)
+	}
+	if !utf8.ValidString(line) || strings.IndexByte(line, 0) >= 0 {
+		if newline {
+			line += "\n"
+		}
+		w.binaryLen += len(line)
+		if w.binaryLen <= BinaryInlineLimit {
+			w.binary = append(w.binary, line...)
+		} else {
+			w.binary = nil
 		}
-		w.emitter.EmitStepLog(w.runID, w.stepID, w.channel, line)
+		return
+	}
+	w.flushBinary()
+	if w.maxLine > 0 && len(line) > w.maxLine {
+		line = truncateLine(line, w.maxLine)
+		if w.onCut != nil {
+			w.onCut()
+		}
+	}
+	if ss, ok := w.emitter.(StampedLogSink); ok && w.seq != nil {
+		ss.EmitStepLogAt(w.runID, w.stepID, w.channel, line, at, n)
+		return
+	}
+	w.emitter.EmitStepLog(w.runID, w.stepID, w.channel, line)
+}
+
+// flushBinary reports the binary output held back since the last text line.
+func (w *StepWriter) flushBinary() {
+	if w.binaryLen == 0 {
+		return
+	}
+	if bs, ok := w.emitter.(BinaryOutputSink); ok {
+		bs.EmitStepBinary(w.runID, w.stepID, w.channel, w.binaryLen, w.binary)
+	}
+	w.binary, w.binaryLen = nil, 0
+}
+
+// truncateLine cuts line to at most max bytes without splitting a UTF-8
+// sequence and appends a marker with the number of bytes dropped.
+func truncateLine(line string, max int) string {
+	cut := max
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
 	}
+	return fmt.Sprintf("%s [truncated %d bytes]", line[:cut], len(line)-cut)
 }
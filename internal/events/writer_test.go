@@ -0,0 +1,119 @@
+package events
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type logRecorder struct {
+	Sink
+	lines []string
+}
+
+func (r *logRecorder) EmitStepLog(runID, step, channel, message string) {
+	r.lines = append(r.lines, message)
+}
+
+func (r *logRecorder) EmitStepBinary(runID, step, channel string, size int, data []byte) {
+	r.lines = append(r.lines, fmt.Sprintf("binary %d %q", size, data))
+}
+
+type stampedRecorder struct {
+	logRecorder
+}
+
+func (r *stampedRecorder) EmitStepLogAt(runID, step, channel, message string, at time.Time, line int64) {
+	r.lines = append(r.lines, fmt.Sprintf("%d %s %s", line, channel, message))
+}
+
+func TestStepWriterStampLines(t *testing.T) {
+	rec := &stampedRecorder{}
+	var log bytes.Buffer
+	seq := new(atomic.Int64)
+	stdout := NewStepWriter(rec, "run", "build", "stdout", nil, nil)
+	stdout.StampLines(seq, &log)
+	stderr := NewStepWriter(rec, "run", "build", "stderr", nil, nil)
+	stderr.StampLines(seq, nil)
+
+	stdout.Write([]byte("one\ntw"))
+	stderr.Write([]byte("oops\n"))
+	stdout.Write([]byte("o\n"))
+	stdout.Write([]byte("tail"))
+	stdout.Flush()
+
+	want := []string{"1 stdout one", "2 stderr oops", "3 stdout two", "4 stdout tail"}
+	if strings.Join(rec.lines, "|") != strings.Join(want, "|") {
+		t.Fatalf("events = %q, want %q", rec.lines, want)
+	}
+	lines := strings.Split(strings.TrimSuffix(log.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected three stamped stdout lines, got %q", log.String())
+	}
+	for i, want := range []string{"build#1 one", "build#3 two", "build#4 tail"} {
+		ts, rest, _ := strings.Cut(lines[i], " ")
+		if _, err := time.Parse(time.RFC3339Nano, ts); err != nil || rest != want {
+			t.Fatalf("line %d = %q, want an RFC3339Nano time then %q", i, lines[i], want)
+		}
+	}
+}
+
+func TestStepWriterLimitLines(t *testing.T) {
+	rec := &logRecorder{}
+	var out bytes.Buffer
+	w := NewStepWriter(rec, "run", "step", "stdout", &out, NewLineRedactor([]string{"hunter2"}))
+	cut := 0
+	w.LimitLines(8, func() { cut++ })
+
+	input := "short\nhunter2 is long\nabcdefgé\n"
+	if _, err := w.Write([]byte(input)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if out.String() != input {
+		t.Fatalf("expected out to receive the full output, got %q", out.String())
+	}
+	want := []string{"short", "[secret] [truncated 8 bytes]", "abcdefg [truncated 2 bytes]"}
+	if len(rec.lines) != len(want) {
+		t.Fatalf("expected %d lines, got %q", len(want), rec.lines)
+	}
+	for i := range want {
+		if rec.lines[i] != want[i] {
+			t.Fatalf("line %d: got %q, want %q", i, rec.lines[i], want[i])
+		}
+	}
+	if cut != 2 {
+		t.Fatalf("expected two truncated lines, got %d", cut)
+	}
+}
+
+func TestStepWriterHoldsBackBinaryOutput(t *testing.T) {
+	rec := &logRecorder{}
+	var out bytes.Buffer
+	w := NewStepWriter(rec, "run", "step", "stdout", &out, NewLineRedactor([]string{"hunter2"}))
+
+	input := "before\n\x89PNG\x00hunter2\n\xff\xfe\nafter\n" + strings.Repeat("\x00", BinaryInlineLimit+1)
+	if _, err := w.Write([]byte(input)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	w.Flush()
+	if out.String() != input {
+		t.Fatalf("expected out to receive the raw bytes")
+	}
+	want := []string{
+		"before",
+		fmt.Sprintf("binary 17 %q", "\x89PNG\x00[secret]\n\xff\xfe\n"),
+		"after",
+		fmt.Sprintf("binary %d %q", BinaryInlineLimit+1, []byte(nil)),
+	}
+	if len(rec.lines) != len(want) {
+		t.Fatalf("expected %d events, got %q", len(want), rec.lines)
+	}
+	for i := range want {
+		if rec.lines[i] != want[i] {
+			t.Fatalf("event %d: got %q, want %q", i, rec.lines[i], want[i])
+		}
+	}
+}
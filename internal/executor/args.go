@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package executor
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// scriptArgs returns the argv passed to a script after its path. Without an
+// args_style the raw CLI flags are forwarded as before; otherwise the validated
+// arg values are rendered in argspec order. Secrets never appear on argv.
+func scriptArgs(cfg *types.Config, ecfg ExecutorConfig) []string {
+	style := ""
+	if cfg != nil {
+		style = cfg.ArgsStyle
+	}
+	switch style {
+	case "":
+		return legacyFlagArgs(ecfg.Flags)
+	case types.ArgsStylePositional:
+		return positionalArgs(cfg.ArgSpec, ecfg.ArgValues)
+	case types.ArgsStyleFlags:
+		return styledFlagArgs(cfg.ArgSpec, ecfg.ArgValues)
+	default:
+		return nil
+	}
+}
+
+// scriptStdin returns the JSON document fed to a stdin-json script, or nil.
+func scriptStdin(cfg *types.Config, ecfg ExecutorConfig) io.Reader {
+	if cfg == nil || cfg.ArgsStyle != types.ArgsStyleStdinJSON {
+		return nil
+	}
+	doc := ecfg.ArgsJSON
+	if strings.TrimSpace(doc) == "" {
+		doc = "{}"
+	}
+	return strings.NewReader(doc)
+}
+
+func legacyFlagArgs(flags map[string]interface{}) []string {
+	flagArgs := make([]string, 0, len(flags))
+	for name, val := range flags {
+		switch v := val.(type) {
+		case bool:
+			if v {
+				flagArgs = append(flagArgs, "--"+name)
+			}
+		case string:
+			flagArgs = append(flagArgs, fmt.Sprintf("--%s=%s", name, v))
+		case int:
+			flagArgs = append(flagArgs, fmt.Sprintf("--%s=%d", name, v))
+		}
+	}
+	return flagArgs
+}
+
+// positionalArgs passes one parameter per arg, empty when unset. A trailing
+// array arg expands to one parameter per element.
+func positionalArgs(spec *types.ArgSpec, values map[string]interface{}) []string {
+	if spec == nil {
+		return nil
+	}
+	var out []string
+	for _, arg := range spec.Args {
+		if arg.Secret || arg.Format == "secret" {
+			continue
+		}
+		val, ok := values[arg.Name]
+		if !ok || val == nil {
+			if arg.Type != "array" {
+				out = append(out, "")
+			}
+			continue
+		}
+		if items, isList := listValues(val); isList {
+			out = append(out, items...)
+			continue
+		}
+		out = append(out, formatArgValue(val))
+	}
+	return out
+}
+
+// styledFlagArgs renders args as --name=value. True booleans become --name and
+// false ones are omitted; arrays repeat the flag and objects pass key=value.
+func styledFlagArgs(spec *types.ArgSpec, values map[string]interface{}) []string {
+	if spec == nil {
+		return nil
+	}
+	var out []string
+	for _, arg := range spec.Args {
+		if arg.Secret || arg.Format == "secret" {
+			continue
+		}
+		val, ok := values[arg.Name]
+		if !ok || val == nil {
+			continue
+		}
+		flag := "--" + arg.Name
+		if b, isBool := val.(bool); isBool {
+			if b {
+				out = append(out, flag)
+			}
+			continue
+		}
+		if items, isList := listValues(val); isList {
+			for _, item := range items {
+				out = append(out, flag+"="+item)
+			}
+			continue
+		}
+		if pairs, isMap := mapValues(val); isMap {
+			for _, pair := range pairs {
+				out = append(out, flag+"="+pair)
+			}
+			continue
+		}
+		out = append(out, flag+"="+formatArgValue(val))
+	}
+	return out
+}
+
+func listValues(val interface{}) ([]string, bool) {
+	switch v := val.(type) {
+	case []string:
+		return append([]string(nil), v...), true
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			out = append(out, formatArgValue(item))
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// mapValues returns an object arg as key=value pairs sorted by key.
+func mapValues(val interface{}) ([]string, bool) {
+	var m map[string]string
+	switch v := val.(type) {
+	case map[string]string:
+		m = v
+	case map[string]interface{}:
+		m = make(map[string]string, len(v))
+		for key, item := range v {
+			m[key] = formatArgValue(item)
+		}
+	default:
+		return nil, false
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make([]string, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, key+"="+m[key])
+	}
+	return out, true
+}
+
+func formatArgValue(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
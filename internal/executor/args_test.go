@@ -0,0 +1,91 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+func TestScriptArgsStyles(t *testing.T) {
+	spec := &types.ArgSpec{Args: []types.Arg{
+		{Name: "env", Type: "string"},
+		{Name: "count", Type: "integer"},
+		{Name: "dry_run", Type: "boolean"},
+		{Name: "token", Type: "string", Format: "secret"},
+		{Name: "labels", Type: "object", ValueType: "string"},
+		{Name: "targets", Type: "array", ItemsType: "string"},
+	}}
+	values := map[string]interface{}{
+		"env":     "prod",
+		"count":   3,
+		"dry_run": false,
+		"token":   "s3cret",
+		"labels":  map[string]string{"team": "ops", "app": "web"},
+		"targets": []string{"a", "b"},
+	}
+	ecfg := ExecutorConfig{ArgValues: values, Flags: map[string]interface{}{"env": "prod"}}
+
+	if got := scriptArgs(&types.Config{ArgSpec: spec}, ecfg); !reflect.DeepEqual(got, []string{"--env=prod"}) {
+		t.Fatalf("legacy: got %q", got)
+	}
+	flags := scriptArgs(&types.Config{ArgSpec: spec, ArgsStyle: types.ArgsStyleFlags}, ecfg)
+	want := []string{"--env=prod", "--count=3", "--labels=app=web", "--labels=team=ops", "--targets=a", "--targets=b"}
+	if !reflect.DeepEqual(flags, want) {
+		t.Fatalf("flags: got %q, want %q", flags, want)
+	}
+
+	positionalSpec := &types.ArgSpec{Args: []types.Arg{spec.Args[0], spec.Args[1], spec.Args[2], {Name: "missing", Type: "string"}, spec.Args[5]}}
+	positional := scriptArgs(&types.Config{ArgSpec: positionalSpec, ArgsStyle: types.ArgsStylePositional}, ecfg)
+	want = []string{"prod", "3", "false", "", "a", "b"}
+	if !reflect.DeepEqual(positional, want) {
+		t.Fatalf("positional: got %q, want %q", positional, want)
+	}
+
+	if got := scriptArgs(&types.Config{ArgSpec: spec, ArgsStyle: types.ArgsStyleStdinJSON}, ecfg); len(got) != 0 {
+		t.Fatalf("stdin-json: expected no argv, got %q", got)
+	}
+}
+
+func TestRunScriptsPassesArgsOnStdin(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "config.d"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	config := `version: v1
+job:
+  id: stdin
+  name: Stdin
+interpreter: bash
+args_style: stdin-json
+argspec:
+  args:
+    - name: env
+      type: string
+`
+	if err := os.WriteFile(filepath.Join(dir, "config.d", "config.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "100_main.sh"), []byte("echo \"argc=$# stdin=$(cat)\"\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	var stdout bytes.Buffer
+	if _, err := RunScripts(context.Background(), dir, ExecutorConfig{
+		Strict:       true,
+		RunDir:       t.TempDir(),
+		ArgsJSON:     `{"env":"prod"}`,
+		ArgValues:    map[string]interface{}{"env": "prod"},
+		StdoutWriter: &stdout,
+		StderrWriter: os.Stderr,
+	}); err != nil {
+		t.Fatalf("RunScripts: %v", err)
+	}
+	if got, want := strings.TrimSpace(stdout.String()), `argc=0 stdin={"env":"prod"}`; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
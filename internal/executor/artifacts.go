@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// ArtifactsDir is the directory below the run directory whose files are
+// published as run artifacts.
+const ArtifactsDir = "artifacts"
+
+// Default artifact limits applied when ArtifactLimits leaves a field zero.
+const (
+	DefaultMaxArtifactBytes     = 100 << 20
+	DefaultMaxRunArtifactsBytes = 1 << 30
+)
+
+// ArtifactLimits bounds what IndexArtifacts publishes.
+type ArtifactLimits struct {
+	// MaxFileBytes skips larger files; zero uses DefaultMaxArtifactBytes.
+	MaxFileBytes int64
+	// MaxTotalBytes skips files once the published ones would exceed it;
+	// zero uses DefaultMaxRunArtifactsBytes.
+	MaxTotalBytes int64
+}
+
+func (l ArtifactLimits) normalized() ArtifactLimits {
+	if l.MaxFileBytes <= 0 {
+		l.MaxFileBytes = DefaultMaxArtifactBytes
+	}
+	if l.MaxTotalBytes <= 0 {
+		l.MaxTotalBytes = DefaultMaxRunArtifactsBytes
+	}
+	return l
+}
+
+// IndexArtifacts records name, size and SHA-256 of the regular files under
+// the artifacts directory of runDir, in lexical order. Symlinks and other
+// special files are never published. Files over the limits are left out and
+// returned as skipped, so callers can report them.
+func IndexArtifacts(runDir string, limits ArtifactLimits) (artifacts []types.RunArtifact, skipped []string, err error) {
+	limits = limits.normalized()
+	root := filepath.Join(runDir, ArtifactsDir)
+	var total int64
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if path == root && errors.Is(walkErr, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return walkErr
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() > limits.MaxFileBytes || total+info.Size() > limits.MaxTotalBytes {
+			skipped = append(skipped, name)
+			return nil
+		}
+		sum, err := HashFile(path)
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		artifacts = append(artifacts, types.RunArtifact{Name: name, Size: info.Size(), SHA256: sum})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("index artifacts: %w", err)
+	}
+	return artifacts, skipped, nil
+}
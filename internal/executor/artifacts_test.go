@@ -0,0 +1,56 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestIndexArtifacts(t *testing.T) {
+	runDir := t.TempDir()
+	if artifacts, skipped, err := IndexArtifacts(runDir, ArtifactLimits{}); err != nil || artifacts != nil || skipped != nil {
+		t.Fatalf("expected no artifacts without a directory, got %v %v %v", artifacts, skipped, err)
+	}
+	dir := filepath.Join(runDir, ArtifactsDir)
+	if err := os.MkdirAll(filepath.Join(dir, "reports"), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for name, body := range map[string]string{
+		"app.tar":            "tarball",
+		"reports/junit.xml":  "<testsuites/>",
+		"reports/coverage.o": "0123456789abcdef",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(body), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if runtime.GOOS != "windows" {
+		if err := os.Symlink("/etc/hostname", filepath.Join(dir, "host")); err != nil {
+			t.Fatalf("symlink: %v", err)
+		}
+	}
+
+	artifacts, skipped, err := IndexArtifacts(runDir, ArtifactLimits{MaxFileBytes: 15})
+	if err != nil {
+		t.Fatalf("index: %v", err)
+	}
+	var names []string
+	for _, a := range artifacts {
+		names = append(names, a.Name)
+	}
+	if !slices.Equal(names, []string{"app.tar", "reports/junit.xml"}) {
+		t.Fatalf("unexpected artifacts %v", names)
+	}
+	if artifacts[0].Size != 7 || artifacts[0].SHA256 != "db4b4d0d1cb480bf9aeea253771c00febe627f236765fa37d6a5614f079a3aa0" {
+		t.Fatalf("unexpected artifact %+v", artifacts[0])
+	}
+	if !slices.Equal(skipped, []string{"reports/coverage.o"}) {
+		t.Fatalf("expected oversized file to be skipped, got %v", skipped)
+	}
+
+	if _, skipped, _ = IndexArtifacts(runDir, ArtifactLimits{MaxTotalBytes: 10}); !slices.Equal(skipped, []string{"reports/coverage.o", "reports/junit.xml"}) {
+		t.Fatalf("expected files beyond the total to be skipped, got %v", skipped)
+	}
+}
@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package executor
+
+import (
+	"time"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// defaultCancelGrace is how long a canceled step may take to exit after its
+// cancel signal before it is killed, unless the job sets cancel.grace_period.
+const defaultCancelGrace = 10 * time.Second
+
+// cancelPolicy resolves the signal and grace period a canceled step gets.
+// The config loader has already validated both.
+func cancelPolicy(cfg *types.Config) (signal string, grace time.Duration) {
+	signal, grace = "SIGTERM", defaultCancelGrace
+	if cfg == nil || cfg.Cancel == nil {
+		return signal, grace
+	}
+	if cfg.Cancel.Signal != "" {
+		signal = cfg.Cancel.Signal
+	}
+	if d, err := time.ParseDuration(cfg.Cancel.GracePeriod); err == nil && d > 0 {
+		grace = d
+	}
+	return signal, grace
+}
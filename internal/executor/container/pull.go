@@ -0,0 +1,240 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package container
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PullProgress summarises an in-flight image pull. Byte counts cover only the
+// layers whose size the runtime has reported and are zero when it reports
+// none, e.g. when its output is not a terminal.
+type PullProgress struct {
+	LayersTotal int
+	LayersDone  int
+	BytesDone   int64
+	BytesTotal  int64
+}
+
+// pullOutputTail bounds how much runtime output is kept for error messages.
+const pullOutputTail = 4 << 10
+
+// runtimeStream runs the runtime CLI, passing each line of combined output to
+// onLine as it arrives, and returns the tail of that output.
+var runtimeStream = func(ctx context.Context, runtime Runtime, onLine func(string), args ...string) ([]byte, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	w := &lineWriter{onLine: onLine}
+	cmd := exec.CommandContext(ctx, string(runtime), args...)
+	cmd.Stdout = w
+	cmd.Stderr = w
+	err := cmd.Run()
+	w.flush()
+	return w.tail.Bytes(), err
+}
+
+// PullImage fetches image ahead of the run so pull time is measured apart
+// from execution.
+func PullImage(ctx context.Context, runtime Runtime, image string) error {
+	return PullImageProgress(ctx, runtime, image, nil)
+}
+
+// PullImageProgress pulls image like PullImage, calling onProgress whenever
+// the runtime reports a change in layer state or bytes transferred.
+func PullImageProgress(ctx context.Context, runtime Runtime, image string, onProgress func(PullProgress)) error {
+	if runtime == "" || image == "" {
+		return nil
+	}
+	tracker := newPullTracker()
+	onLine := func(line string) {
+		if tracker.observe(line) && onProgress != nil {
+			onProgress(tracker.progress())
+		}
+	}
+	output, err := runtimeStream(backgroundContext(ctx), runtime, onLine, "pull", image)
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("pull image %s: %w: %s", image, err, msg)
+		}
+		return fmt.Errorf("pull image %s: %w", image, err)
+	}
+	return nil
+}
+
+var (
+	// dockerLayerLine matches "<layer>: <status>" lines from docker pull.
+	dockerLayerLine = regexp.MustCompile(`^([0-9a-f]{12,64}): (.+)$`)
+	// podmanBlobLine matches "Copying blob <digest> ..." lines from podman pull.
+	podmanBlobLine = regexp.MustCompile(`^Copying blob (?:sha256:)?([0-9a-f]{12,64})(.*)$`)
+	transferSizes  = regexp.MustCompile(`([0-9.]+)\s*([kKMGT]?i?[bB])\s*/\s*([0-9.]+)\s*([kKMGT]?i?[bB])`)
+)
+
+type layerState struct {
+	done       bool
+	current    int64
+	total      int64
+	knownTotal bool
+}
+
+// pullTracker folds docker and podman pull output into per-layer state.
+type pullTracker struct {
+	order  []string
+	layers map[string]*layerState
+}
+
+func newPullTracker() *pullTracker {
+	return &pullTracker{layers: make(map[string]*layerState)}
+}
+
+// observe applies one line of pull output and reports whether the progress
+// summary changed.
+func (t *pullTracker) observe(line string) bool {
+	line = strings.TrimSpace(line)
+	var id, status string
+	if m := dockerLayerLine.FindStringSubmatch(line); m != nil {
+		id, status = m[1], m[2]
+	} else if m := podmanBlobLine.FindStringSubmatch(line); m != nil {
+		id, status = m[1], strings.TrimSpace(m[2])
+	} else {
+		return false
+	}
+	// Podman prints full digests and docker short IDs; key on the short form.
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	before := t.progress()
+	layer, ok := t.layers[id]
+	if !ok {
+		layer = &layerState{}
+		t.layers[id] = layer
+		t.order = append(t.order, id)
+	}
+	// Newer podman releases end blob lines with a bar separator.
+	lower := strings.ToLower(strings.TrimRight(status, "| "))
+	switch {
+	case strings.HasPrefix(lower, "pull complete"),
+		strings.HasPrefix(lower, "already exists"),
+		strings.HasPrefix(lower, "skipped"),
+		strings.HasSuffix(lower, "done"):
+		layer.done = true
+		if layer.knownTotal {
+			layer.current = layer.total
+		}
+	case strings.HasPrefix(lower, "download complete"), strings.HasPrefix(lower, "verifying checksum"):
+		if layer.knownTotal {
+			layer.current = layer.total
+		}
+	case strings.HasPrefix(lower, "downloading"), strings.HasPrefix(status, "["):
+		if current, total, ok := parseTransfer(status); ok {
+			layer.current, layer.total, layer.knownTotal = current, total, true
+		}
+	}
+	return t.progress() != before
+}
+
+func (t *pullTracker) progress() PullProgress {
+	p := PullProgress{LayersTotal: len(t.order)}
+	for _, id := range t.order {
+		layer := t.layers[id]
+		if layer.done {
+			p.LayersDone++
+		}
+		if layer.knownTotal {
+			p.BytesDone += layer.current
+			p.BytesTotal += layer.total
+		}
+	}
+	return p
+}
+
+func parseTransfer(s string) (current, total int64, ok bool) {
+	m := transferSizes.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, false
+	}
+	current, ok1 := parseSize(m[1], m[2])
+	total, ok2 := parseSize(m[3], m[4])
+	if !ok1 || !ok2 {
+		return 0, 0, false
+	}
+	return current, total, true
+}
+
+// parseSize converts a runtime-reported size such as 12.5MB or 3.1MiB to
+// bytes. Docker uses decimal units and podman binary ones.
+func parseSize(num, unit string) (int64, bool) {
+	value, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, false
+	}
+	base := 1000.0
+	if strings.Contains(unit, "i") {
+		base = 1024
+	}
+	exp := 0
+	switch strings.ToUpper(unit[:1]) {
+	case "K":
+		exp = 1
+	case "M":
+		exp = 2
+	case "G":
+		exp = 3
+	case "T":
+		exp = 4
+	}
+	for i := 0; i < exp; i++ {
+		value *= base
+	}
+	return int64(value), true
+}
+
+// lineWriter splits written output into lines, treating carriage returns as
+// line breaks so redrawn progress bars are seen as they update. It keeps the
+// tail of the output for error reporting.
+type lineWriter struct {
+	mu     sync.Mutex
+	onLine func(string)
+	buf    []byte
+	tail   bytes.Buffer
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.tail.Write(p)
+	if extra := w.tail.Len() - pullOutputTail; extra > 0 {
+		w.tail.Next(extra)
+	}
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexAny(w.buf, "\r\n")
+		if i < 0 {
+			break
+		}
+		w.emit(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.emit(string(w.buf))
+		w.buf = nil
+	}
+}
+
+func (w *lineWriter) emit(line string) {
+	if w.onLine != nil && strings.TrimSpace(line) != "" {
+		w.onLine(line)
+	}
+}
@@ -0,0 +1,116 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImagePresentAndPull(t *testing.T) {
+	var calls []string
+	local := map[string]bool{"alpine:3": true}
+	origCommand, origStream := runtimeCommand, runtimeStream
+	runtimeCommand = func(_ context.Context, runtime Runtime, args ...string) ([]byte, error) {
+		calls = append(calls, string(runtime)+" "+strings.Join(args, " "))
+		if local[args[2]] {
+			return []byte("[]"), nil
+		}
+		return []byte("Error: no such image"), errors.New("exit status 125")
+	}
+	runtimeStream = func(_ context.Context, runtime Runtime, _ func(string), args ...string) ([]byte, error) {
+		calls = append(calls, string(runtime)+" "+strings.Join(args, " "))
+		if args[1] == "missing:1" {
+			return []byte("manifest unknown"), errors.New("exit status 125")
+		}
+		return nil, nil
+	}
+	t.Cleanup(func() { runtimeCommand, runtimeStream = origCommand, origStream })
+
+	if !ImagePresent(context.Background(), RuntimePodman, "alpine:3") {
+		t.Fatalf("expected local image to be present")
+	}
+	if ImagePresent(context.Background(), RuntimePodman, "busybox:1") {
+		t.Fatalf("expected remote image to be absent")
+	}
+	if err := PullImage(context.Background(), RuntimePodman, "busybox:1"); err != nil {
+		t.Fatalf("pull: %v", err)
+	}
+	err := PullImage(context.Background(), RuntimePodman, "missing:1")
+	if err == nil || !strings.Contains(err.Error(), "manifest unknown") {
+		t.Fatalf("expected pull error with runtime output, got %v", err)
+	}
+	want := []string{
+		"podman image inspect alpine:3",
+		"podman image inspect busybox:1",
+		"podman pull busybox:1",
+		"podman pull missing:1",
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected runtime calls:\n%s", strings.Join(calls, "\n"))
+	}
+}
+
+func TestPullImageProgress(t *testing.T) {
+	cases := []struct {
+		name   string
+		output string
+		want   []PullProgress
+	}{
+		{
+			name: "docker",
+			output: "3: Pulling from library/alpine\n" +
+				"aaaaaaaaaaaa: Pulling fs layer\n" +
+				"bbbbbbbbbbbb: Already exists\n" +
+				"aaaaaaaaaaaa: Downloading [=====>      ]  1.5MB/3MB\r" +
+				"aaaaaaaaaaaa: Downloading [=====>      ]  1.5MB/3MB\r" +
+				"aaaaaaaaaaaa: Download complete\n" +
+				"aaaaaaaaaaaa: Pull complete\n" +
+				"Digest: sha256:cccc\n",
+			want: []PullProgress{
+				{LayersTotal: 1},
+				{LayersTotal: 2, LayersDone: 1},
+				{LayersTotal: 2, LayersDone: 1, BytesDone: 1500000, BytesTotal: 3000000},
+				{LayersTotal: 2, LayersDone: 1, BytesDone: 3000000, BytesTotal: 3000000},
+				{LayersTotal: 2, LayersDone: 2, BytesDone: 3000000, BytesTotal: 3000000},
+			},
+		},
+		{
+			name: "podman",
+			output: "Trying to pull docker.io/library/alpine:3...\n" +
+				"Copying blob sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa\n" +
+				"Copying blob aaaaaaaaaaaa [==>-------] 1.0MiB / 4.0MiB\r" +
+				"Copying blob aaaaaaaaaaaa done   |\n" +
+				"Copying config sha256:dddd done\n" +
+				"Writing manifest to image destination\n",
+			want: []PullProgress{
+				{LayersTotal: 1},
+				{LayersTotal: 1, BytesDone: 1 << 20, BytesTotal: 4 << 20},
+				{LayersTotal: 1, LayersDone: 1, BytesDone: 4 << 20, BytesTotal: 4 << 20},
+			},
+		},
+	}
+	orig := runtimeStream
+	t.Cleanup(func() { runtimeStream = orig })
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			runtimeStream = func(_ context.Context, _ Runtime, onLine func(string), _ ...string) ([]byte, error) {
+				w := &lineWriter{onLine: onLine}
+				_, _ = w.Write([]byte(tc.output))
+				w.flush()
+				return w.tail.Bytes(), nil
+			}
+			var got []PullProgress
+			err := PullImageProgress(context.Background(), RuntimeDocker, "alpine:3", func(p PullProgress) {
+				got = append(got, p)
+			})
+			if err != nil {
+				t.Fatalf("pull: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("progress = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package container
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"path/filepath"
@@ -78,6 +79,9 @@ func BuildArgs(opts RunOptions) ([]string, error) {
 	if opts.Name != "" {
 		args = append(args, "--name", opts.Name)
 	}
+	if opts.Interactive {
+		args = append(args, "--interactive")
+	}
 
 	// Secure defaults
 	args = append(args,
@@ -230,6 +234,160 @@ func RemoveContainer(ctx context.Context, runtime Runtime, name string) error {
 	return nil
 }
 
+// ContainerExists reports whether the runtime still knows a container called
+// name, running or not.
+func ContainerExists(ctx context.Context, runtime Runtime, name string) (bool, error) {
+	if runtime == "" || name == "" {
+		return false, nil
+	}
+	runCtx, cancel := context.WithTimeout(backgroundContext(ctx), 10*time.Second)
+	defer cancel()
+	output, err := runtimeCommand(runCtx, runtime, "container", "inspect", "--format", "{{.Id}}", name)
+	if err != nil {
+		if isContainerNotFound(output) {
+			return false, nil
+		}
+		return false, fmt.Errorf("inspect container %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// exitPollInterval is how often TerminateContainer checks whether a
+// signaled container has exited.
+var exitPollInterval = 250 * time.Millisecond
+
+// SignalContainer sends signal (e.g. SIGINT) to the container's main process.
+func SignalContainer(ctx context.Context, runtime Runtime, name, signal string) error {
+	if runtime == "" || name == "" {
+		return nil
+	}
+	runCtx, cancel := context.WithTimeout(backgroundContext(ctx), 10*time.Second)
+	defer cancel()
+	output, err := runtimeCommand(runCtx, runtime, "kill", "--signal", signal, name)
+	if err != nil {
+		if isContainerNotFound(output) {
+			return nil
+		}
+		return fmt.Errorf("signal container %s: %w", name, err)
+	}
+	return nil
+}
+
+// ContainerRunning reports whether the container called name is running.
+func ContainerRunning(ctx context.Context, runtime Runtime, name string) (bool, error) {
+	if runtime == "" || name == "" {
+		return false, nil
+	}
+	runCtx, cancel := context.WithTimeout(backgroundContext(ctx), 10*time.Second)
+	defer cancel()
+	output, err := runtimeCommand(runCtx, runtime, "container", "inspect", "--format", "{{.State.Running}}", name)
+	if err != nil {
+		if isContainerNotFound(output) {
+			return false, nil
+		}
+		return false, fmt.Errorf("inspect container %s: %w", name, err)
+	}
+	return strings.TrimSpace(string(output)) == "true", nil
+}
+
+// TerminateContainer shuts name down after a timeout or cancellation. The
+// container receives signal (SIGTERM when empty) and gets grace to exit; if
+// it is still running then, it is killed and forced reports true. It is
+// removed either way. Killing the runtime client does not stop the
+// container, so the result is verified: a nil error means it is gone.
+func TerminateContainer(ctx context.Context, runtime Runtime, name, signal string, grace time.Duration) (forced bool, err error) {
+	if runtime == "" || name == "" {
+		return false, nil
+	}
+	if signal == "" {
+		signal = "SIGTERM"
+	}
+	var errs []error
+	if err := SignalContainer(ctx, runtime, name, signal); err != nil {
+		errs = append(errs, err)
+	}
+	deadline := time.Now().Add(grace)
+	for {
+		running, err := ContainerRunning(ctx, runtime, name)
+		if err == nil && !running {
+			break
+		}
+		if time.Now().After(deadline) || ctx.Err() != nil {
+			forced = true
+			if err := KillContainer(ctx, runtime, name); err != nil {
+				errs = append(errs, err)
+			}
+			break
+		}
+		time.Sleep(exitPollInterval)
+	}
+	if err := RemoveContainer(ctx, runtime, name); err != nil {
+		errs = append(errs, err)
+	}
+	exists, err := ContainerExists(ctx, runtime, name)
+	if err != nil {
+		return forced, errors.Join(append(errs, err)...)
+	}
+	if exists {
+		return forced, errors.Join(append(errs, fmt.Errorf("container %s still present after signal, kill and remove", name))...)
+	}
+	return forced, nil
+}
+
+// RuntimeVersion returns the version of the runtime's engine: the daemon for
+// Docker, the CLI for daemonless Podman.
+func RuntimeVersion(ctx context.Context, runtime Runtime) (string, error) {
+	if runtime == "" {
+		return "", nil
+	}
+	format := "{{.Server.Version}}"
+	if runtime == RuntimePodman {
+		format = "{{.Client.Version}}"
+	}
+	runCtx, cancel := context.WithTimeout(backgroundContext(ctx), 10*time.Second)
+	defer cancel()
+	output, err := runtimeCommand(runCtx, runtime, "version", "--format", format)
+	if err != nil {
+		return "", fmt.Errorf("%s version: %w", runtime, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ProbeRuntime checks that runtime can actually serve requests, which a
+// present CLI alone does not prove: `docker version` fails when the daemon is
+// down and `podman info` when its service or machine is. It returns the
+// engine version on success.
+func ProbeRuntime(ctx context.Context, runtime Runtime, timeout time.Duration) (string, error) {
+	if runtime == "" {
+		return "", fmt.Errorf("no container runtime detected")
+	}
+	args := []string{"version", "--format", "{{.Server.Version}}"}
+	if runtime == RuntimePodman {
+		args = []string{"info", "--format", "{{.Version.Version}}"}
+	}
+	runCtx, cancel := context.WithTimeout(backgroundContext(ctx), timeout)
+	defer cancel()
+	output, err := runtimeCommand(runCtx, runtime, args...)
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return "", fmt.Errorf("%s %s: %w: %s", runtime, args[0], err, msg)
+		}
+		return "", fmt.Errorf("%s %s: %w", runtime, args[0], err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ImagePresent reports whether the runtime already has image locally.
+func ImagePresent(ctx context.Context, runtime Runtime, image string) bool {
+	if runtime == "" || image == "" {
+		return false
+	}
+	runCtx, cancel := context.WithTimeout(backgroundContext(ctx), 30*time.Second)
+	defer cancel()
+	_, err := runtimeCommand(runCtx, runtime, "image", "inspect", image)
+	return err == nil
+}
+
 func backgroundContext(ctx context.Context) context.Context {
 	if ctx == nil {
 		return context.Background()
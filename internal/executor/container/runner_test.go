@@ -1,8 +1,12 @@
 package container
 
 import (
+	"context"
 	"errors"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDetectRuntimePrefersPodman(t *testing.T) {
@@ -110,6 +114,7 @@ func TestBuildArgsOverrides(t *testing.T) {
 		WritableRootfs: true,
 		Capabilities:   []string{"NET_ADMIN", "cap_sys_time"},
 		NetworkMode:    "bridge",
+		Interactive:    true,
 	}
 	args, err := BuildArgs(opts)
 	if err != nil {
@@ -131,6 +136,9 @@ func TestBuildArgsOverrides(t *testing.T) {
 	if !containsSequence(args, []string{"--cap-add=cap_sys_time"}) {
 		t.Fatalf("expected cap-add for cap_sys_time: %v", args)
 	}
+	if !containsSequence(args, []string{"--interactive"}) {
+		t.Fatalf("expected --interactive for stdin: %v", args)
+	}
 }
 
 func containsSequence(args, expect []string) bool {
@@ -151,3 +159,104 @@ outer:
 	}
 	return false
 }
+
+func TestTerminateContainer(t *testing.T) {
+	cases := []struct {
+		name       string
+		survive    string // last command the container survives: "", "signal" or "rm"
+		wantCalls  []string
+		wantForced bool
+		wantErr    bool
+	}{
+		{"exits on signal", "", []string{"kill --signal SIGINT c1", "running", "rm --force c1", "exists"}, false, false},
+		{"killed after grace", "signal", []string{"kill --signal SIGINT c1", "running", "kill c1", "rm --force c1", "exists"}, true, false},
+		{"still present", "rm", []string{"kill --signal SIGINT c1", "running", "kill c1", "rm --force c1", "exists"}, true, true},
+	}
+	origCommand, origPoll := runtimeCommand, exitPollInterval
+	exitPollInterval = 40 * time.Millisecond
+	t.Cleanup(func() { runtimeCommand, exitPollInterval = origCommand, origPoll })
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			running, present := true, true
+			var calls []string
+			runtimeCommand = func(_ context.Context, _ Runtime, args ...string) ([]byte, error) {
+				if args[0] == "container" {
+					if strings.Contains(args[3], "Running") {
+						// Polls repeat until the grace period ends; record one.
+						if calls[len(calls)-1] != "running" {
+							calls = append(calls, "running")
+						}
+						return []byte(strconv.FormatBool(running)), nil
+					}
+					calls = append(calls, "exists")
+					if !present {
+						return []byte("Error: No such container: c1"), errors.New("exit status 1")
+					}
+					return []byte("abc123"), nil
+				}
+				calls = append(calls, strings.Join(args, " "))
+				switch {
+				case args[0] == "kill" && len(args) > 2 && tc.survive == "":
+					running = false
+				case args[0] == "kill" && len(args) == 2:
+					running = false
+				case args[0] == "rm" && tc.survive != "rm":
+					present = false
+				}
+				return nil, nil
+			}
+			forced, err := TerminateContainer(context.Background(), RuntimeDocker, "c1", "SIGINT", 50*time.Millisecond)
+			if (err != nil) != tc.wantErr || forced != tc.wantForced {
+				t.Fatalf("forced=%v err=%v, want forced=%v wantErr=%v", forced, err, tc.wantForced, tc.wantErr)
+			}
+			if strings.Join(calls, "|") != strings.Join(tc.wantCalls, "|") {
+				t.Fatalf("calls = %q, want %q", calls, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRuntimeVersion(t *testing.T) {
+	orig := runtimeCommand
+	t.Cleanup(func() { runtimeCommand = orig })
+	var got []string
+	runtimeCommand = func(_ context.Context, _ Runtime, args ...string) ([]byte, error) {
+		got = args
+		return []byte("27.1.1\n"), nil
+	}
+	for rt, format := range map[Runtime]string{RuntimeDocker: "{{.Server.Version}}", RuntimePodman: "{{.Client.Version}}"} {
+		v, err := RuntimeVersion(context.Background(), rt)
+		if err != nil || v != "27.1.1" {
+			t.Fatalf("%s: got %q, %v", rt, v, err)
+		}
+		if strings.Join(got, " ") != "version --format "+format {
+			t.Fatalf("%s: unexpected args %v", rt, got)
+		}
+	}
+	if v, err := RuntimeVersion(context.Background(), ""); v != "" || err != nil {
+		t.Fatalf("expected empty version without runtime, got %q, %v", v, err)
+	}
+}
+
+func TestProbeRuntime(t *testing.T) {
+	orig := runtimeCommand
+	t.Cleanup(func() { runtimeCommand = orig })
+	var got []string
+	runtimeCommand = func(_ context.Context, _ Runtime, args ...string) ([]byte, error) {
+		got = args
+		if args[0] == "version" {
+			return []byte("Cannot connect to the Docker daemon\n"), errors.New("exit status 1")
+		}
+		return []byte("5.2.0\n"), nil
+	}
+	if v, err := ProbeRuntime(context.Background(), RuntimePodman, time.Second); err != nil || v != "5.2.0" {
+		t.Fatalf("podman: got %q, %v", v, err)
+	}
+	if strings.Join(got, " ") != "info --format {{.Version.Version}}" {
+		t.Fatalf("podman: unexpected args %v", got)
+	}
+	_, err := ProbeRuntime(context.Background(), RuntimeDocker, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "Cannot connect to the Docker daemon") {
+		t.Fatalf("docker: expected daemon error, got %v", err)
+	}
+}
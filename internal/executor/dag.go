@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// stepGraph holds the DAG steps' IDs and the indexes of the steps each one
+// needs.
+type stepGraph struct {
+	ids   []string
+	needs [][]int
+}
+
+// newStepGraph resolves the steps' needs and rejects steps without a script,
+// duplicate IDs, unknown needs and dependency cycles before anything runs.
+func newStepGraph(steps []types.StepConfig) (*stepGraph, error) {
+	g := &stepGraph{ids: make([]string, len(steps)), needs: make([][]int, len(steps))}
+	index := make(map[string]int, len(steps))
+	for idx, step := range steps {
+		id := strings.TrimSpace(step.ID)
+		if id == "" {
+			id = fmt.Sprintf("step-%03d", idx)
+		}
+		if strings.TrimSpace(step.Script) == "" {
+			return nil, fmt.Errorf("step %s missing script path", id)
+		}
+		if _, dup := index[id]; dup {
+			return nil, fmt.Errorf("duplicate step id %s", id)
+		}
+		index[id] = idx
+		g.ids[idx] = id
+	}
+	for idx, step := range steps {
+		for _, need := range step.Needs {
+			need = strings.TrimSpace(need)
+			if need == "" {
+				continue
+			}
+			dep, ok := index[need]
+			if !ok {
+				return nil, fmt.Errorf("step %s needs unknown step %s", g.ids[idx], need)
+			}
+			g.needs[idx] = append(g.needs[idx], dep)
+		}
+	}
+	if cycle := g.cycle(); len(cycle) > 0 {
+		return nil, fmt.Errorf("steps %s form a dependency cycle", strings.Join(cycle, ", "))
+	}
+	return g, nil
+}
+
+// CheckStepGraph reports the step errors runs of a DAG job would fail on
+// before starting any step, such as needs that form a cycle.
+func CheckStepGraph(steps []types.StepConfig) error {
+	_, err := newStepGraph(steps)
+	return err
+}
+
+// cycle returns the IDs of the steps that can never start because their
+// needs loop back on themselves, or nil.
+func (g *stepGraph) cycle() []string {
+	waiting, dependents := g.edges()
+	var ready []int
+	for idx := range g.ids {
+		if waiting[idx] == 0 {
+			ready = append(ready, idx)
+		}
+	}
+	for len(ready) > 0 {
+		idx := ready[len(ready)-1]
+		ready = ready[:len(ready)-1]
+		for _, next := range dependents[idx] {
+			if waiting[next]--; waiting[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+	var stuck []string
+	for idx, n := range waiting {
+		if n > 0 {
+			stuck = append(stuck, g.ids[idx])
+		}
+	}
+	return stuck
+}
+
+// edges returns each step's number of needs and the steps that need it.
+func (g *stepGraph) edges() ([]int, [][]int) {
+	waiting := make([]int, len(g.ids))
+	dependents := make([][]int, len(g.ids))
+	for idx, deps := range g.needs {
+		waiting[idx] = len(deps)
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], idx)
+		}
+	}
+	return waiting, dependents
+}
+
+// run starts every step once the steps it needs have finished, at most limit
+// at a time, and returns the results in completion order. Ready steps start
+// in declaration order. When runStep returns an error or ctx is canceled no
+// further steps start and the context of the in-flight ones is canceled.
+func (g *stepGraph) run(ctx context.Context, limit int, runStep func(context.Context, int) (ScriptResult, error)) ([]ScriptResult, error) {
+	if limit < 1 {
+		limit = 1
+	}
+	stepCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type outcome struct {
+		idx    int
+		result ScriptResult
+		err    error
+	}
+	finished := make(chan outcome)
+	waiting, dependents := g.edges()
+	ready := make([]bool, len(g.ids))
+	for idx, n := range waiting {
+		ready[idx] = n == 0
+	}
+	nextReady := func() (int, bool) {
+		for idx, ok := range ready {
+			if ok {
+				ready[idx] = false
+				return idx, true
+			}
+		}
+		return 0, false
+	}
+
+	results := make([]ScriptResult, 0, len(g.ids))
+	var firstErr error
+	running := 0
+	for {
+		for firstErr == nil && stepCtx.Err() == nil && running < limit {
+			idx, ok := nextReady()
+			if !ok {
+				break
+			}
+			running++
+			go func() {
+				result, err := runStep(stepCtx, idx)
+				finished <- outcome{idx: idx, result: result, err: err}
+			}()
+		}
+		if running == 0 {
+			break
+		}
+		done := <-finished
+		running--
+		results = append(results, done.result)
+		if done.err != nil && firstErr == nil {
+			firstErr = done.err
+			cancel()
+		}
+		for _, next := range dependents[done.idx] {
+			if waiting[next]--; waiting[next] == 0 {
+				ready[next] = true
+			}
+		}
+	}
+	if firstErr != nil {
+		return results, firstErr
+	}
+	if len(results) < len(g.ids) {
+		return results, ctx.Err()
+	}
+	return results, nil
+}
@@ -0,0 +1,189 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+type stepEventRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *stepEventRecorder) EmitRunStart(runID, jobID string)                 {}
+func (r *stepEventRecorder) EmitRunFinish(runID, status string, err error)    {}
+func (r *stepEventRecorder) EmitStepLog(runID, step, channel, message string) {}
+
+func (r *stepEventRecorder) EmitStepStart(runID, step string) {
+	r.record("start:" + step)
+}
+
+func (r *stepEventRecorder) EmitStepFinish(runID, step string, exitCode int, err error) {
+	r.record("finish:" + step)
+}
+
+func (r *stepEventRecorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *stepEventRecorder) index(event string) int {
+	for i, e := range r.events {
+		if e == event {
+			return i
+		}
+	}
+	return -1
+}
+
+func writeDAGJob(t *testing.T, steps string, scripts map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "config.d"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	config := "version: v1\njob:\n  id: dag\n  name: DAG\ncomposition: steps\nexecutor: proc\ninterpreter: bash\nsteps:\n" + steps
+	if err := os.WriteFile(filepath.Join(dir, "config.d", "config.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	for name, body := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o755); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestRunDAGStepsRunsIndependentStepsInParallel(t *testing.T) {
+	// a and b each wait for the other's marker, so they only both succeed
+	// when they run at the same time.
+	wait := func(self, other string) string {
+		return "touch \"$FLWD_RUN_DIR/" + self + "\"\nfor i in $(seq 100); do [ -e \"$FLWD_RUN_DIR/" + other + "\" ] && exit 0; sleep 0.05; done\nexit 1\n"
+	}
+	dir := writeDAGJob(t, `  - id: a
+    script: a.sh
+  - id: b
+    script: b.sh
+  - id: c
+    script: c.sh
+    needs: [a, b]
+`, map[string]string{"a.sh": wait("a", "b"), "b.sh": wait("b", "a"), "c.sh": "exit 0\n"})
+
+	rec := &stepEventRecorder{}
+	results, err := RunScripts(context.Background(), dir, ExecutorConfig{
+		Strict:           true,
+		RunDir:           t.TempDir(),
+		Emitter:          rec,
+		StdoutWriter:     os.Stdout,
+		StderrWriter:     os.Stderr,
+		MaxParallelSteps: 2,
+	})
+	if err != nil {
+		t.Fatalf("RunScripts: %v", err)
+	}
+	if len(results) != 3 || results[2].Name != "c" {
+		t.Fatalf("expected c to finish last, got %+v", results)
+	}
+	start := rec.index("start:c")
+	if start < rec.index("finish:a") || start < rec.index("finish:b") {
+		t.Fatalf("c started before its needs finished: %v", rec.events)
+	}
+}
+
+func TestRunDAGStepsFailureCancelsInFlightSteps(t *testing.T) {
+	dir := writeDAGJob(t, `  - id: slow
+    script: slow.sh
+  - id: broken
+    script: broken.sh
+  - id: after
+    script: slow.sh
+    needs: [broken]
+`, map[string]string{"slow.sh": "sleep 30\n", "broken.sh": "exit 3\n"})
+
+	rec := &stepEventRecorder{}
+	started := time.Now()
+	results, err := RunScripts(context.Background(), dir, ExecutorConfig{
+		Strict:           true,
+		RunDir:           t.TempDir(),
+		Emitter:          rec,
+		StdoutWriter:     os.Stdout,
+		StderrWriter:     os.Stderr,
+		MaxParallelSteps: 4,
+	})
+	if err == nil || !strings.Contains(err.Error(), "step broken failed") {
+		t.Fatalf("expected the broken step to fail the run, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 20*time.Second {
+		t.Fatalf("in-flight step was not canceled, run took %s", elapsed)
+	}
+	if len(results) != 2 || rec.index("start:after") != -1 {
+		t.Fatalf("expected the dependent step to be skipped, got %+v %v", results, rec.events)
+	}
+}
+
+func TestCheckStepGraphRejectsCycles(t *testing.T) {
+	err := CheckStepGraph([]types.StepConfig{
+		{ID: "a", Script: "a.sh", Needs: []string{"c"}},
+		{ID: "b", Script: "b.sh", Needs: []string{"a"}},
+		{ID: "c", Script: "c.sh", Needs: []string{"b"}},
+		{ID: "d", Script: "d.sh"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "a, b, c form a dependency cycle") {
+		t.Fatalf("expected a cycle error, got %v", err)
+	}
+}
+
+func TestRunScriptsEnforcesTimeouts(t *testing.T) {
+	dir := writeDAGJob(t, `  - id: quick
+    script: quick.sh
+  - id: hung
+    script: hung.sh
+    timeout: 200ms
+`, map[string]string{"quick.sh": "exit 0\n", "hung.sh": "sleep 30\n"})
+
+	started := time.Now()
+	results, err := RunScripts(context.Background(), dir, ExecutorConfig{
+		Strict:       true,
+		RunDir:       t.TempDir(),
+		StdoutWriter: os.Stdout,
+		StderrWriter: os.Stderr,
+	})
+	var timeout *TimeoutError
+	if !errors.As(err, &timeout) || timeout.Step != "hung" {
+		t.Fatalf("expected the hung step to time out, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 20*time.Second {
+		t.Fatalf("hung step was not stopped, run took %s", elapsed)
+	}
+	if len(results) != 2 || !errors.As(results[1].Err, &timeout) {
+		t.Fatalf("expected the step result to carry the timeout, got %+v", results)
+	}
+
+	config := filepath.Join(dir, "config.d", "config.yaml")
+	data, err := os.ReadFile(config)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	data = append([]byte("timeout: 1\n"), strings.Replace(string(data), "    timeout: 200ms\n", "", 1)...)
+	if err := os.WriteFile(config, data, 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	_, err = RunScripts(context.Background(), dir, ExecutorConfig{
+		Strict:       true,
+		RunDir:       t.TempDir(),
+		StdoutWriter: os.Stdout,
+		StderrWriter: os.Stderr,
+	})
+	if !errors.As(err, &timeout) || timeout.Step != "" || timeout.Limit != time.Second {
+		t.Fatalf("expected the run to time out after 1s, got %v", err)
+	}
+}
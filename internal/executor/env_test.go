@@ -2,6 +2,7 @@ package executor
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/flowd-org/flowd/internal/types"
@@ -14,7 +15,7 @@ func TestBuildEnv_StripsParentEnv(t *testing.T) {
 	cfg := &types.Config{Env: map[string]string{"PATH": "/usr/bin"}}
 	argEnv := map[string]string{"ARG_NAME": "alice"}
 
-	env := buildSecureEnv(cfg, argEnv, "{}", false)
+	env := buildSecureEnv(cfg, argEnv, "{}", false, nil)
 
 	for _, e := range env {
 		if len(e) >= len("UNSAFE_VAR=") && e[:len("UNSAFE_VAR=")] == "UNSAFE_VAR=" {
@@ -36,7 +37,7 @@ func TestBuildEnv_StripsParentEnv(t *testing.T) {
 
 func TestBuildSecureEnvAddsDefaultPath(t *testing.T) {
 	t.Setenv("PATH", "/usr/local/bin")
-	env := buildSecureEnv(nil, nil, "", false)
+	env := buildSecureEnv(nil, nil, "", false, nil)
 	expect := "PATH=/usr/local/bin"
 	found := false
 	for _, e := range env {
@@ -52,7 +53,7 @@ func TestBuildSecureEnvAddsDefaultPath(t *testing.T) {
 
 func TestBuildSecureEnvPrefersConfigPath(t *testing.T) {
 	cfg := &types.Config{Env: map[string]string{"PATH": "/custom/bin"}}
-	env := buildSecureEnv(cfg, nil, "", false)
+	env := buildSecureEnv(cfg, nil, "", false, nil)
 	count := 0
 	for _, e := range env {
 		if e == "PATH=/custom/bin" {
@@ -72,7 +73,7 @@ func TestBuildSecureEnvInheritHost(t *testing.T) {
 	defer os.Setenv(key, prev)
 
 	cfg := &types.Config{}
-	env := buildSecureEnv(cfg, nil, "", true)
+	env := buildSecureEnv(cfg, nil, "", true, nil)
 	found := false
 	for _, e := range env {
 		if e == key+"="+val {
@@ -84,3 +85,27 @@ func TestBuildSecureEnvInheritHost(t *testing.T) {
 		t.Fatalf("expected inherited env %s in %v", key, env)
 	}
 }
+
+func TestBuildSecureEnvAppliesFilter(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	cfg := &types.Config{Env: map[string]string{"APP_MODE": "prod", "AWS_REGION": "eu-west-1"}}
+	argEnv := map[string]string{"ARG_NAME": "alice"}
+	filter := func(name string) bool { return name == "PATH" || name == "APP_MODE" }
+
+	env := buildSecureEnv(cfg, argEnv, "{}", true, filter)
+	got := map[string]bool{}
+	for _, e := range env {
+		got[e] = true
+	}
+	for _, want := range []string{"PATH=/usr/bin", "APP_MODE=prod", "ARG_NAME=alice", "FLWD_ARGS_JSON={}"} {
+		if !got[want] {
+			t.Fatalf("expected %s in %v", want, env)
+		}
+	}
+	for _, e := range env {
+		if strings.HasPrefix(e, "AWS_") {
+			t.Fatalf("expected AWS_* to be stripped, got %v", env)
+		}
+	}
+}
@@ -9,11 +9,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/flowd-org/flowd/internal/configloader"
+	"github.com/flowd-org/flowd/internal/engine"
 	"github.com/flowd-org/flowd/internal/events"
 	"github.com/flowd-org/flowd/internal/executor/container"
 	"github.com/flowd-org/flowd/internal/paths"
@@ -44,14 +45,46 @@ type ExecutorConfig struct {
 	ContainerRootfsWritable bool
 	ContainerCapabilities   []string
 	SecretsDir              string
+	// ScriptDigests maps script paths relative to the job directory to the
+	// sha256 recorded in the plan. When set, a script whose content no longer
+	// matches is not executed and the run fails with a ScriptTamperedError.
+	ScriptDigests map[string]string
+	// EnvFilter, when set, decides which job and host environment variables
+	// reach steps. Variables the engine sets itself (see EngineEnv) always pass.
+	EnvFilter func(name string) bool
+	// Sandboxed confines the run to containers: process steps are refused and
+	// container steps get no network, a read-only rootfs and no extra
+	// capabilities or runtime arguments, whatever the job config asks for.
+	Sandboxed bool
+	// MaxParallelSteps bounds how many DAG steps whose needs are met run at
+	// once. Zero or one runs them one at a time in declaration order.
+	MaxParallelSteps int
+	// MaxLogLineBytes truncates longer output lines in step.log events; the
+	// stdout and stderr writers still get them whole. Zero disables it.
+	MaxLogLineBytes int
+	// StdoutLog and StderrLog receive the persisted step output one line at
+	// a time, each prefixed with its RFC3339Nano time and "<step>#<n>", n
+	// counting the step's lines across both channels. Unlike StdoutWriter
+	// and StderrWriter they never fall back to the process's own streams.
+	StdoutLog io.Writer
+	StderrLog io.Writer
+	// MinRetries retries failed steps at least this many times unless the
+	// job sets an error_handling policy other than retry.
+	MinRetries int
 }
 
+// ErrSandboxedProcessStep is returned for process steps of sandboxed runs.
+var ErrSandboxedProcessStep = errors.New("sandboxed runs may only execute container steps")
+
 // ScriptResult holds per-script run outcome.
 type ScriptResult struct {
 	Name     string
 	ExitCode int
 	Duration time.Duration
 	Err      error
+	// Shutdown is types.ShutdownGraceful or types.ShutdownForced when the
+	// step was canceled, and empty otherwise.
+	Shutdown string
 }
 
 func sanitizeName(id string) string {
@@ -92,11 +125,47 @@ func isDAGConfig(cfg *types.Config) bool {
 	return strings.EqualFold(strings.TrimSpace(cfg.Composition), "steps")
 }
 
+// RunScripts runs the job in dir. A run that exceeds the job's timeout is
+// stopped like a canceled one and fails with a *TimeoutError.
 func RunScripts(ctx context.Context, dir string, ecfg ExecutorConfig) ([]ScriptResult, error) {
 	cfg, err := configloader.LoadConfig(dir)
 	if err != nil {
 		return nil, fmt.Errorf("loading config: %w", err)
 	}
+	applyMinRetries(cfg, ecfg.MinRetries)
+	if ecfg.RunDir != "" && !ecfg.DryRun {
+		// Steps publish artifacts by writing below this directory.
+		if err := os.MkdirAll(filepath.Join(ecfg.RunDir, ArtifactsDir), 0o700); err != nil {
+			return nil, fmt.Errorf("create artifacts dir: %w", err)
+		}
+	}
+	ctx, cancel := withTimeout(ctx, cfg.Timeout, "")
+	defer cancel()
+	var results []ScriptResult
+	if cfg.Hooks != nil && (cfg.Hooks.PreRun != "" || cfg.Hooks.PostRun != "") {
+		results, err = runWithHooks(ctx, dir, cfg, ecfg)
+	} else {
+		results, err = runJob(ctx, dir, cfg, ecfg)
+	}
+	if timeout := timeoutCause(ctx); timeout != nil {
+		err = timeout
+	}
+	return results, err
+}
+
+// applyMinRetries raises the job's retries to n, switching an unset error
+// handling policy to retry.
+func applyMinRetries(cfg *types.Config, n int) {
+	policy := strings.ToLower(cfg.ErrorHandling.Policy)
+	if n <= cfg.ErrorHandling.Retries || (policy != "" && policy != "retry") {
+		return
+	}
+	cfg.ErrorHandling.Policy = "retry"
+	cfg.ErrorHandling.Retries = n
+}
+
+// runJob executes the job's DAG steps or phase scripts.
+func runJob(ctx context.Context, dir string, cfg *types.Config, ecfg ExecutorConfig) ([]ScriptResult, error) {
 	if isDAGConfig(cfg) {
 		return runDAGSteps(ctx, dir, cfg, ecfg)
 	}
@@ -110,22 +179,10 @@ func RunScripts(ctx context.Context, dir string, ecfg ExecutorConfig) ([]ScriptR
 		}
 	}
 
-	entries, err := os.ReadDir(dir)
+	scripts, err := listScripts(dir)
 	if err != nil {
-		return nil, fmt.Errorf("reading dir: %w", err)
-	}
-
-	var scripts []string
-	for _, e := range entries {
-		if e.IsDir() {
-			continue
-		}
-		name := e.Name()
-		if strings.HasPrefix(name, "000_") || strings.HasPrefix(name, "100_") || strings.HasPrefix(name, "999_") {
-			scripts = append(scripts, name)
-		}
+		return nil, err
 	}
-	sort.Strings(scripts)
 
 	var results []ScriptResult
 	retryPolicy := strings.ToLower(cfg.ErrorHandling.Policy)
@@ -143,28 +200,42 @@ func RunScripts(ctx context.Context, dir string, ecfg ExecutorConfig) ([]ScriptR
 		if ecfg.Emitter != nil {
 			ecfg.Emitter.EmitStepStart(ecfg.RunID, stepID)
 		}
+		if err := verifyScriptDigest(dir, scriptPath, ecfg.ScriptDigests); err != nil {
+			if ecfg.Emitter != nil {
+				ecfg.Emitter.EmitStepFinish(ecfg.RunID, stepID, -1, err)
+			}
+			return append(results, ScriptResult{Name: script, ExitCode: -1, Err: err}), err
+		}
 
-		var flagArgs []string
-		for name, val := range ecfg.Flags {
-			switch v := val.(type) {
-			case bool:
-				if v {
-					flagArgs = append(flagArgs, "--"+name)
-				}
-			case string:
-				flagArgs = append(flagArgs, fmt.Sprintf("--%s=%s", name, v))
-			case int:
-				flagArgs = append(flagArgs, fmt.Sprintf("--%s=%d", name, v))
+		flagArgs := scriptArgs(cfg, ecfg)
+		if isKubernetesExecutor(cfg) {
+			var image string
+			if cfg.Container != nil {
+				image = strings.TrimSpace(cfg.Container.Image)
 			}
+			result := ScriptResult{Name: script, ExitCode: -1, Err: fmt.Errorf("kubernetes executor requires container.image")}
+			if image != "" {
+				result = runKubernetesStep(ctx, cfg, ecfg, scriptPath, image, flagArgs, ecfg.Emitter, stepID, stepOptions{})
+				result.Name = script
+			}
+			if ecfg.Emitter != nil {
+				ecfg.Emitter.EmitStepFinish(ecfg.RunID, stepID, result.ExitCode, result.Err)
+			}
+			results = append(results, result)
+			if result.Err != nil {
+				return results, result.Err
+			}
+			continue
 		}
 		if strings.HasPrefix(interpreter, "container:") {
-			exitCode, dur, err := runContainerStep(ctx, cfg, ecfg, scriptPath, interpreter, flagArgs, ecfg.Emitter, stepID)
+			result := runContainerStep(ctx, cfg, ecfg, scriptPath, interpreter, flagArgs, ecfg.Emitter, stepID, stepOptions{})
+			result.Name = script
 			if ecfg.Emitter != nil {
-				ecfg.Emitter.EmitStepFinish(ecfg.RunID, stepID, exitCode, err)
+				ecfg.Emitter.EmitStepFinish(ecfg.RunID, stepID, result.ExitCode, result.Err)
 			}
-			results = append(results, ScriptResult{Name: script, ExitCode: exitCode, Duration: dur, Err: err})
-			if err != nil {
-				return results, err
+			results = append(results, result)
+			if result.Err != nil {
+				return results, result.Err
 			}
 			continue
 		}
@@ -178,7 +249,20 @@ func RunScripts(ctx context.Context, dir string, ecfg ExecutorConfig) ([]ScriptR
 			continue
 		}
 
-		result := executeProcessStep(ctx, cfg, ecfg, scriptPath, script, interpreter, flagArgs, stepID, retryPolicy, maxRetries, retryBackoff)
+		if isSSHExecutor(cfg) {
+			result := runSSHStep(ctx, cfg, ecfg, scriptPath, interpreter, flagArgs, ecfg.Emitter, stepID, stepOptions{})
+			result.Name = script
+			if ecfg.Emitter != nil {
+				ecfg.Emitter.EmitStepFinish(ecfg.RunID, stepID, result.ExitCode, result.Err)
+			}
+			results = append(results, result)
+			if result.Err != nil && ecfg.Strict {
+				return results, fmt.Errorf("script %s failed: %w", script, result.Err)
+			}
+			continue
+		}
+
+		result := executeProcessStep(ctx, cfg, ecfg, scriptPath, script, interpreter, flagArgs, stepID, retryPolicy, maxRetries, retryBackoff, stepOptions{})
 		if ecfg.Emitter != nil {
 			ecfg.Emitter.EmitStepFinish(ecfg.RunID, stepID, result.ExitCode, result.Err)
 		}
@@ -196,93 +280,178 @@ func runDAGSteps(ctx context.Context, dir string, cfg *types.Config, ecfg Execut
 	if executor == "" {
 		return nil, fmt.Errorf("dag executor not configured")
 	}
+	graph, err := newStepGraph(cfg.Steps)
+	if err != nil {
+		return nil, err
+	}
+	return graph.run(ctx, ecfg.MaxParallelSteps, func(ctx context.Context, idx int) (ScriptResult, error) {
+		return runDAGStep(ctx, dir, cfg, ecfg, executor, cfg.Steps[idx], graph.ids[idx])
+	})
+}
+
+// runDAGStep executes one DAG step between its step.start and step.finish
+// events. A non-nil error stops the DAG: steps that cannot be started always
+// do, failed ones only for strict runs.
+func runDAGStep(ctx context.Context, dir string, cfg *types.Config, ecfg ExecutorConfig, executor string, step types.StepConfig, stepID string) (ScriptResult, error) {
 	retryPolicy := strings.ToLower(cfg.ErrorHandling.Policy)
 	maxRetries := cfg.ErrorHandling.Retries
 	retryBackoff := cfg.ErrorHandling.RetryBackoff
 
-	results := make([]ScriptResult, 0, len(cfg.Steps))
-	for idx, step := range cfg.Steps {
-		stepID := strings.TrimSpace(step.ID)
-		if stepID == "" {
-			stepID = fmt.Sprintf("step-%03d", idx)
-		}
-		scriptPath := strings.TrimSpace(step.Script)
-		if scriptPath == "" {
-			return results, fmt.Errorf("step %s missing script path", stepID)
-		}
-		if !filepath.IsAbs(scriptPath) {
-			scriptPath = filepath.Join(dir, scriptPath)
+	scriptPath := strings.TrimSpace(step.Script)
+	if !filepath.IsAbs(scriptPath) {
+		scriptPath = filepath.Join(dir, scriptPath)
+	}
+	ctx, cancel := withTimeout(ctx, step.Timeout, stepID)
+	defer cancel()
+	if ecfg.Emitter != nil {
+		ecfg.Emitter.EmitStepStart(ecfg.RunID, stepID)
+	}
+	if err := verifyScriptDigest(dir, scriptPath, ecfg.ScriptDigests); err != nil {
+		if ecfg.Emitter != nil {
+			ecfg.Emitter.EmitStepFinish(ecfg.RunID, stepID, -1, err)
 		}
+		return ScriptResult{Name: stepID, ExitCode: -1, Err: err}, err
+	}
+	opts, optErr := resolveStepOptions(step, dir, ecfg.RunDir)
+	if optErr != nil {
+		err := fmt.Errorf("step %s: %w", stepID, optErr)
 		if ecfg.Emitter != nil {
-			ecfg.Emitter.EmitStepStart(ecfg.RunID, stepID)
+			ecfg.Emitter.EmitStepFinish(ecfg.RunID, stepID, -1, err)
 		}
+		return ScriptResult{Name: stepID, ExitCode: -1, Err: err}, err
+	}
 
-		flagArgs := make([]string, 0, len(ecfg.Flags))
-		for name, val := range ecfg.Flags {
-			switch v := val.(type) {
-			case bool:
-				if v {
-					flagArgs = append(flagArgs, "--"+name)
-				}
-			case string:
-				flagArgs = append(flagArgs, fmt.Sprintf("--%s=%s", name, v))
-			case int:
-				flagArgs = append(flagArgs, fmt.Sprintf("--%s=%d", name, v))
-			}
-		}
+	flagArgs := scriptArgs(cfg, ecfg)
 
-		var (
-			result ScriptResult
-			err    error
-		)
+	var (
+		result ScriptResult
+		err    error
+	)
 
-		switch executor {
-		case "container":
-			merged := mergeContainerConfigs(cfg.Container, step.Container)
-			image := strings.TrimSpace(merged.Image)
-			if image == "" {
-				err = fmt.Errorf("step %s missing container image", stepID)
-				result = ScriptResult{Name: stepID, ExitCode: -1, Err: err}
-			} else {
-				interpreter := "container:" + image
-				stepCfg := &types.Config{
-					Container:      merged,
-					Env:            cfg.Env,
-					EnvInheritance: cfg.EnvInheritance,
-				}
-				exitCode, dur, runErr := runContainerStep(ctx, stepCfg, ecfg, scriptPath, interpreter, flagArgs, ecfg.Emitter, stepID)
-				result = ScriptResult{Name: stepID, ExitCode: exitCode, Duration: dur, Err: runErr}
-				err = runErr
+	switch executor {
+	case "container":
+		merged := mergeContainerConfigs(cfg.Container, step.Container)
+		image := strings.TrimSpace(merged.Image)
+		if image == "" {
+			err = fmt.Errorf("step %s missing container image", stepID)
+			result = ScriptResult{Name: stepID, ExitCode: -1, Err: err}
+		} else {
+			interpreter := "container:" + image
+			stepCfg := &types.Config{
+				Container:      merged,
+				Env:            cfg.Env,
+				EnvInheritance: cfg.EnvInheritance,
+				ArgsStyle:      cfg.ArgsStyle,
+				Cancel:         cfg.Cancel,
 			}
-		case "proc":
-			interpreter := cfg.Interpreter
-			if interpreter == "" {
-				err = fmt.Errorf("no interpreter defined for DAG job")
-				result = ScriptResult{Name: stepID, ExitCode: -1, Err: err}
-			} else {
-				result = executeProcessStep(ctx, cfg, ecfg, scriptPath, stepID, interpreter, flagArgs, stepID, retryPolicy, maxRetries, retryBackoff)
-				err = result.Err
+			result = runContainerStep(ctx, stepCfg, ecfg, scriptPath, interpreter, flagArgs, ecfg.Emitter, stepID, opts)
+			err = result.Err
+		}
+	case ExecutorKubernetes:
+		merged := mergeContainerConfigs(cfg.Container, step.Container)
+		image := strings.TrimSpace(merged.Image)
+		if image == "" {
+			err = fmt.Errorf("step %s missing container image", stepID)
+			result = ScriptResult{Name: stepID, ExitCode: -1, Err: err}
+		} else {
+			stepCfg := &types.Config{
+				Container:      merged,
+				Kubernetes:     cfg.Kubernetes,
+				Env:            cfg.Env,
+				EnvInheritance: cfg.EnvInheritance,
+				ArgsStyle:      cfg.ArgsStyle,
+				Cancel:         cfg.Cancel,
 			}
-		default:
-			err = fmt.Errorf("unsupported executor %s", executor)
+			result = runKubernetesStep(ctx, stepCfg, ecfg, scriptPath, image, flagArgs, ecfg.Emitter, stepID, opts)
+			err = result.Err
+		}
+	case ExecutorSSH:
+		result = runSSHStep(ctx, cfg, ecfg, scriptPath, cfg.Interpreter, flagArgs, ecfg.Emitter, stepID, opts)
+		err = result.Err
+	case "proc":
+		interpreter := cfg.Interpreter
+		if opts.shell != "" {
+			interpreter = opts.shell
+		}
+		if interpreter == "" {
+			err = fmt.Errorf("no interpreter defined for DAG job")
 			result = ScriptResult{Name: stepID, ExitCode: -1, Err: err}
+		} else {
+			result = executeProcessStep(ctx, cfg, ecfg, scriptPath, stepID, interpreter, flagArgs, stepID, retryPolicy, maxRetries, retryBackoff, opts)
+			err = result.Err
 		}
+	default:
+		err = fmt.Errorf("unsupported executor %s", executor)
+		result = ScriptResult{Name: stepID, ExitCode: -1, Err: err}
+	}
+	if timeout := timeoutCause(ctx); timeout != nil {
+		err = timeout
+		result.Err = timeout
+	}
 
-		if ecfg.Emitter != nil {
-			ecfg.Emitter.EmitStepFinish(ecfg.RunID, stepID, result.ExitCode, err)
+	if ecfg.Emitter != nil {
+		ecfg.Emitter.EmitStepFinish(ecfg.RunID, stepID, result.ExitCode, err)
+	}
+	if err != nil && ecfg.Strict {
+		return result, fmt.Errorf("step %s failed: %w", stepID, err)
+	}
+	return result, nil
+}
+
+// newStepWriter tees a step's output channel to out, the channel's stamped
+// log and step.log events, cutting event lines at ecfg.MaxLogLineBytes. The
+// step's writers share seq.
+func newStepWriter(ecfg ExecutorConfig, sink events.Sink, stepID, channel string, out io.Writer, seq *atomic.Int64) *events.StepWriter {
+	w := events.NewStepWriter(sink, ecfg.RunID, stepID, channel, out, ecfg.LineRedactor)
+	w.LimitLines(ecfg.MaxLogLineBytes, func() { metrics.Default.RecordLogLineTruncated(channel) })
+	log := ecfg.StdoutLog
+	if channel == "stderr" {
+		log = ecfg.StderrLog
+	}
+	w.StampLines(seq, log)
+	return w
+}
+
+// stepOptions carries per-step overrides declared on DAG steps.
+type stepOptions struct {
+	// workDir is the resolved working directory; empty keeps the default.
+	workDir string
+	// shell replaces the job interpreter, e.g. "bash -euo pipefail".
+	shell string
+}
+
+func resolveStepOptions(step types.StepConfig, jobDir, runDir string) (stepOptions, error) {
+	var opts stepOptions
+	if strings.TrimSpace(step.Shell) != "" {
+		shell, err := ParseStepShell(step.Shell)
+		if err != nil {
+			return opts, err
 		}
-		results = append(results, result)
+		opts.shell = shell
+	}
+	if strings.TrimSpace(step.Workdir) != "" {
+		workdir, err := ParseStepWorkdir(step.Workdir)
 		if err != nil {
-			if ecfg.Strict {
-				return results, fmt.Errorf("step %s failed: %w", stepID, err)
-			}
+			return opts, err
+		}
+		if runDir == "" {
+			runDir = jobDir
+		}
+		if opts.workDir, err = workdir.Resolve(runDir, jobDir); err != nil {
+			return opts, err
 		}
 	}
-	return results, nil
+	return opts, nil
 }
 
-func executeProcessStep(ctx context.Context, cfg *types.Config, ecfg ExecutorConfig, scriptPath, scriptLabel, interpreter string, flagArgs []string, stepID string, retryPolicy string, maxRetries, retryBackoff int) ScriptResult {
+func executeProcessStep(ctx context.Context, cfg *types.Config, ecfg ExecutorConfig, scriptPath, scriptLabel, interpreter string, flagArgs []string, stepID string, retryPolicy string, maxRetries, retryBackoff int, opts stepOptions) ScriptResult {
 	result := ScriptResult{Name: scriptLabel}
+	if ecfg.Sandboxed {
+		result.ExitCode = -1
+		result.Err = ErrSandboxedProcessStep
+		return result
+	}
+	lineSeq := new(atomic.Int64)
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		start := time.Now()
 		profilePath, cleanup, err := GenerateRunnerProfile(filepath.Dir(scriptPath), interpreter, ecfg.Verbosity, cfg.ArgSpec, ecfg.ArgValues)
@@ -322,23 +491,27 @@ func executeProcessStep(ctx context.Context, cfg *types.Config, ecfg ExecutorCon
 		}
 
 		stdoutSink := ecfg.StdoutWriter
-		if stdoutSink == nil {
+		if stdoutSink == nil && ecfg.StdoutLog == nil {
 			stdoutSink = os.Stdout
 		}
 		stderrSink := ecfg.StderrWriter
-		if stderrSink == nil {
+		if stderrSink == nil && ecfg.StderrLog == nil {
 			stderrSink = os.Stderr
 		}
-		stdoutWriter := events.NewStepWriter(ecfg.Emitter, ecfg.RunID, stepID, "stdout", stdoutSink, ecfg.LineRedactor)
-		stderrWriter := events.NewStepWriter(ecfg.Emitter, ecfg.RunID, stepID, "stderr", stderrSink, ecfg.LineRedactor)
+		stdoutWriter := newStepWriter(ecfg, ecfg.Emitter, stepID, "stdout", stdoutSink, lineSeq)
+		stderrWriter := newStepWriter(ecfg, ecfg.Emitter, stepID, "stderr", stderrSink, lineSeq)
 		cmd.Stdout = stdoutWriter
 		cmd.Stderr = stderrWriter
+		cmd.Dir = opts.workDir
+		cmd.Stdin = scriptStdin(cfg, ecfg)
+		cancelSignal, cancelGrace := cancelPolicy(cfg)
+		group := startInProcessGroup(cmd, cancelSignal, cancelGrace)
 
 		inherit := ecfg.EnvInherit
 		if !inherit && cfg != nil && cfg.EnvInheritance {
 			inherit = true
 		}
-		env := buildSecureEnv(cfg, ecfg.ArgEnv, ecfg.ArgsJSON, inherit)
+		env := buildSecureEnv(cfg, ecfg.ArgEnv, ecfg.ArgsJSON, inherit, ecfg.EnvFilter)
 		runDir := ecfg.RunDir
 		if runDir == "" {
 			runDir = filepath.Dir(scriptPath)
@@ -349,6 +522,9 @@ func executeProcessStep(ctx context.Context, cfg *types.Config, ecfg ExecutorCon
 		env = upsertEnv(env, "FLOWD_RUN_DIR", runDir)
 		env = upsertEnv(env, "RUN_DIR", runDir)
 		env = upsertEnv(env, "FLWD_RUN_DIR", runDir)
+		if ecfg.RunDir != "" {
+			env = upsertEnv(env, "FLWD_OUTPUTS", filepath.Join(ecfg.RunDir, engine.OutputsFile))
+		}
 		if strings.Contains(interpreter, "bash") {
 			cmd.Env = append(env, fmt.Sprintf("BASH_ENV=%s", profilePath))
 		} else {
@@ -364,6 +540,7 @@ func executeProcessStep(ctx context.Context, cfg *types.Config, ecfg ExecutorCon
 		stderrWriter.Flush()
 		duration := time.Since(start)
 		result.Duration = duration
+		result.Shutdown = group.shutdown()
 
 		if err == nil {
 			result.ExitCode = 0
@@ -461,6 +638,12 @@ func cloneContainer(cfg *types.ContainerConfig) *types.ContainerConfig {
 	return clone
 }
 
+// withinDir reports whether path is dir or below it.
+func withinDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
 func quoteArgs(args []string) string {
 	quoted := make([]string, len(args))
 	for i, arg := range args {
@@ -480,7 +663,17 @@ func upsertEnv(env []string, key, value string) []string {
 	return append(env, prefix+value)
 }
 
-func buildSecureEnv(cfg *types.Config, argEnv map[string]string, argsJSON string, inherit bool) []string {
+// EngineEnv reports whether the executor sets name itself for every step:
+// argument bindings and the data, run and outputs locations.
+func EngineEnv(name string) bool {
+	switch name {
+	case "FLWD_ARGS_JSON", "DATA_DIR", "FLOWD_DATA_DIR", "FLOWD_RUN_DIR", "RUN_DIR", "FLWD_RUN_DIR", "FLWD_OUTPUTS", RunStatusEnv:
+		return true
+	}
+	return strings.HasPrefix(name, "ARG_")
+}
+
+func buildSecureEnv(cfg *types.Config, argEnv map[string]string, argsJSON string, inherit bool, filter func(string) bool) []string {
 	type entry struct {
 		key string
 		val string
@@ -493,13 +686,18 @@ func buildSecureEnv(cfg *types.Config, argEnv map[string]string, argsJSON string
 		}
 		envSet[k] = v
 	}
+	allowed := func(k string) bool {
+		return filter == nil || EngineEnv(k) || filter(k)
+	}
 
 	if cfg != nil && cfg.Env != nil {
 		for k, v := range cfg.Env {
-			set(k, v)
+			if allowed(k) {
+				set(k, v)
+			}
 		}
 	}
-	if _, ok := envSet["PATH"]; !ok {
+	if _, ok := envSet["PATH"]; !ok && allowed("PATH") {
 		if path := os.Getenv("PATH"); path != "" {
 			set("PATH", path)
 		}
@@ -516,7 +714,7 @@ func buildSecureEnv(cfg *types.Config, argEnv map[string]string, argsJSON string
 			if len(parts) != 2 {
 				continue
 			}
-			if _, exists := envSet[parts[0]]; exists {
+			if _, exists := envSet[parts[0]]; exists || !allowed(parts[0]) {
 				continue
 			}
 			set(parts[0], parts[1])
@@ -536,10 +734,51 @@ func splitInterpreter(interpreter string) (string, []string, error) {
 	}
 	return fields[0], fields[1:], nil
 }
-func runContainerStep(ctx context.Context, cfg *types.Config, ecfg ExecutorConfig, scriptPath, interpreter string, flagArgs []string, sink events.Sink, stepID string) (int, time.Duration, error) {
+
+// pullProgressInterval throttles image pull progress events between layer
+// transitions.
+const pullProgressInterval = time.Second
+
+// ensureContainerImage pulls image when the runtime lacks it, reporting the
+// pull to sinks that record image pulls.
+func ensureContainerImage(ctx context.Context, runtime container.Runtime, image string, sink events.Sink, runID, stepID string) error {
+	if container.ImagePresent(ctx, runtime, image) {
+		return nil
+	}
+	pullSink, _ := sink.(events.ImagePullSink)
+	if pullSink != nil {
+		pullSink.EmitImagePullStart(runID, stepID, image)
+	}
+	start := time.Now()
+	var onProgress func(container.PullProgress)
+	if pullSink != nil {
+		var (
+			lastAt time.Time
+			last   container.PullProgress
+		)
+		onProgress = func(p container.PullProgress) {
+			// Layer transitions are always reported; byte updates at most
+			// once per interval so redrawn progress bars don't flood sinks.
+			sameLayers := p.LayersTotal == last.LayersTotal && p.LayersDone == last.LayersDone
+			if sameLayers && time.Since(lastAt) < pullProgressInterval {
+				return
+			}
+			lastAt, last = time.Now(), p
+			pullSink.EmitImagePullProgress(runID, stepID, image, events.ImagePullProgress(p))
+		}
+	}
+	err := container.PullImageProgress(ctx, runtime, image, onProgress)
+	metrics.Default.RecordContainerPull(time.Since(start))
+	if pullSink != nil {
+		pullSink.EmitImagePullFinish(runID, stepID, image, err)
+	}
+	return err
+}
+
+func runContainerStep(ctx context.Context, cfg *types.Config, ecfg ExecutorConfig, scriptPath, interpreter string, flagArgs []string, sink events.Sink, stepID string, stepOpts stepOptions) ScriptResult {
 	parts := strings.SplitN(interpreter, ":", 2)
 	if len(parts) != 2 {
-		return -1, 0, fmt.Errorf("invalid container interpreter: %s", interpreter)
+		return ScriptResult{Name: stepID, ExitCode: -1, Err: fmt.Errorf("invalid container interpreter: %s", interpreter)}
 	}
 	image := parts[1]
 	runtime := ecfg.ContainerRuntime
@@ -547,7 +786,7 @@ func runContainerStep(ctx context.Context, cfg *types.Config, ecfg ExecutorConfi
 		var err error
 		runtime, err = container.DetectRuntime(nil)
 		if err != nil {
-			return -1, 0, err
+			return ScriptResult{Name: stepID, ExitCode: -1, Err: err}
 		}
 	}
 	containerName := ecfg.RunID
@@ -558,14 +797,17 @@ func runContainerStep(ctx context.Context, cfg *types.Config, ecfg ExecutorConfi
 		containerName = fmt.Sprintf("flwd-%d", time.Now().UnixNano())
 	}
 	if err := container.RemoveContainer(context.Background(), runtime, containerName); err != nil {
-		return -1, 0, fmt.Errorf("prepare container %s: %w", containerName, err)
+		return ScriptResult{Name: stepID, ExitCode: -1, Err: fmt.Errorf("prepare container %s: %w", containerName, err)}
+	}
+	if err := ensureContainerImage(ctx, runtime, image, sink, ecfg.RunID, stepID); err != nil {
+		return ScriptResult{Name: stepID, ExitCode: -1, Err: err}
 	}
 
 	inherit := ecfg.EnvInherit
 	if !inherit && cfg != nil && cfg.EnvInheritance {
 		inherit = true
 	}
-	envList := buildSecureEnv(cfg, ecfg.ArgEnv, ecfg.ArgsJSON, inherit)
+	envList := buildSecureEnv(cfg, ecfg.ArgEnv, ecfg.ArgsJSON, inherit, ecfg.EnvFilter)
 	envMap := make(map[string]string, len(envList))
 	for _, kv := range envList {
 		parts := strings.SplitN(kv, "=", 2)
@@ -577,7 +819,7 @@ func runContainerStep(ctx context.Context, cfg *types.Config, ecfg ExecutorConfi
 	scriptDir := filepath.Dir(scriptPath)
 	absScriptDir, err := filepath.Abs(scriptDir)
 	if err != nil {
-		return -1, 0, err
+		return ScriptResult{Name: stepID, ExitCode: -1, Err: err}
 	}
 	// Ensure the command we exec inside the container uses an absolute path that
 	// matches the mount destination, so the script is resolvable regardless of
@@ -595,6 +837,9 @@ func runContainerStep(ctx context.Context, cfg *types.Config, ecfg ExecutorConfi
 		"RUN_DIR":        runDir,
 		"FLWD_RUN_DIR":   runDir,
 	}
+	if ecfg.RunDir != "" {
+		updates["FLWD_OUTPUTS"] = filepath.Join(ecfg.RunDir, engine.OutputsFile)
+	}
 	for k, v := range updates {
 		envList = upsertEnv(envList, k, v)
 		envMap[k] = v
@@ -609,13 +854,28 @@ func runContainerStep(ctx context.Context, cfg *types.Config, ecfg ExecutorConfi
 	if ecfg.SecretsDir != "" {
 		mounts = append(mounts, container.Mount{Source: ecfg.SecretsDir, Destination: "/run/secrets", ReadOnly: true})
 	}
+	workDir := runDir
+	if stepOpts.workDir != "" {
+		workDir = stepOpts.workDir
+		if !withinDir(workDir, absScriptDir) && !withinDir(workDir, runDir) {
+			mounts = append(mounts, container.Mount{Source: workDir, Destination: workDir, ReadOnly: true})
+		}
+	}
+	command := append([]string{scriptAbs}, flagArgs...)
+	if stepOpts.shell != "" {
+		shell := strings.Fields(stepOpts.shell)
+		if shell[0] == "pwsh" {
+			shell = append(shell, "-File")
+		}
+		command = append(shell, command...)
+	}
 
 	opts := container.RunOptions{
 		Runtime:        runtime,
 		Image:          image,
-		Command:        append([]string{scriptAbs}, flagArgs...),
+		Command:        command,
 		Env:            envMap,
-		WorkDir:        runDir,
+		WorkDir:        workDir,
 		Mounts:         mounts,
 		Remove:         true,
 		Name:           containerName,
@@ -623,6 +883,8 @@ func runContainerStep(ctx context.Context, cfg *types.Config, ecfg ExecutorConfi
 		WritableRootfs: ecfg.ContainerRootfsWritable,
 		Capabilities:   append([]string{}, ecfg.ContainerCapabilities...),
 	}
+	stdin := scriptStdin(cfg, ecfg)
+	opts.Interactive = stdin != nil
 	if cfg != nil && cfg.Container != nil {
 		if opts.NetworkMode == "" {
 			opts.NetworkMode = strings.TrimSpace(cfg.Container.Network)
@@ -637,50 +899,65 @@ func runContainerStep(ctx context.Context, cfg *types.Config, ecfg ExecutorConfi
 			opts.ExtraArgs = append(opts.ExtraArgs, cfg.Container.ExtraArgs...)
 		}
 	}
+	if ecfg.Sandboxed {
+		opts.NetworkMode = "none"
+		opts.WritableRootfs = false
+		opts.Capabilities = nil
+		opts.ExtraArgs = nil
+	}
 	args, err := container.BuildArgs(opts)
 	if err != nil {
-		return -1, 0, err
+		return ScriptResult{Name: stepID, ExitCode: -1, Err: err}
 	}
-	stdoutWriter := events.NewStepWriter(sink, ecfg.RunID, stepID, "stdout", ecfg.StdoutWriter, ecfg.LineRedactor)
-	stderrWriter := events.NewStepWriter(sink, ecfg.RunID, stepID, "stderr", ecfg.StderrWriter, ecfg.LineRedactor)
+	lineSeq := new(atomic.Int64)
+	stdoutWriter := newStepWriter(ecfg, sink, stepID, "stdout", ecfg.StdoutWriter, lineSeq)
+	stderrWriter := newStepWriter(ecfg, sink, stepID, "stderr", ecfg.StderrWriter, lineSeq)
 	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
 	cmd.Stdout = stdoutWriter
 	cmd.Stderr = stderrWriter
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
 	cmd.Env = envList
 	runStart := time.Now()
 	err = cmd.Run()
 	stdoutWriter.Flush()
 	stderrWriter.Flush()
 	dur := time.Since(runStart)
-	exitCode := 0
-	if ctx != nil && errors.Is(ctx.Err(), context.Canceled) {
-		cancelCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	result := ScriptResult{Name: stepID, Duration: dur}
+	// The client process dies with ctx but the container keeps running, so on
+	// cancel or timeout stop it through the runtime and make sure it is gone.
+	var ctxErr error
+	if ctx != nil {
+		ctxErr = ctx.Err()
+	}
+	if ctxErr != nil || errors.Is(err, context.Canceled) {
+		signal, grace := cancelPolicy(cfg)
+		stopCtx, cancel := context.WithTimeout(context.Background(), grace+30*time.Second)
 		defer cancel()
-		_ = container.StopContainer(cancelCtx, runtime, containerName, 10*time.Second)
-		_ = container.KillContainer(cancelCtx, runtime, containerName)
-		_ = container.RemoveContainer(cancelCtx, runtime, containerName)
+		forced, termErr := container.TerminateContainer(stopCtx, runtime, containerName, signal, grace)
+		result.Shutdown = types.ShutdownGraceful
+		if forced {
+			result.Shutdown = types.ShutdownForced
+		}
+		if termErr != nil {
+			if sink != nil {
+				sink.EmitStepLog(ecfg.RunID, stepID, "stderr", fmt.Sprintf("[flwd] terminate container: %v", termErr))
+			}
+			err = errors.Join(err, termErr)
+		}
 		if err == nil {
-			err = context.Canceled
+			err = ctxErr
 		}
 	}
-	if errors.Is(err, context.Canceled) {
-		cancelCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		_ = container.StopContainer(cancelCtx, runtime, containerName, 10*time.Second)
-		_ = container.KillContainer(cancelCtx, runtime, containerName)
-		_ = container.RemoveContainer(cancelCtx, runtime, containerName)
-	}
 	metrics.Default.RecordContainerRun(dur)
-	metrics.Default.RecordContainerPull(dur)
+	result.Err = err
 	if err != nil {
+		result.ExitCode = -1
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
-			exitCode = exitErr.ExitCode()
-		} else {
-			exitCode = -1
+			result.ExitCode = exitErr.ExitCode()
 		}
-	} else {
-		exitCode = 0
 	}
-	return exitCode, dur, err
+	return result
 }
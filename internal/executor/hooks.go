@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// Step IDs reported for job hooks.
+const (
+	HookPreRun  = "pre_run"
+	HookPostRun = "post_run"
+)
+
+// RunStatusEnv carries the run outcome (completed, failed, canceled or
+// timed_out) to the post_run hook.
+const RunStatusEnv = "FLWD_RUN_STATUS"
+
+// postRunGrace bounds a post_run hook that starts after the run was canceled.
+const postRunGrace = 2 * time.Minute
+
+// runWithHooks runs the job between its pre_run and post_run hooks. A failing
+// pre_run hook skips the job; post_run runs regardless and a failure there
+// fails an otherwise successful run.
+func runWithHooks(ctx context.Context, dir string, cfg *types.Config, ecfg ExecutorConfig) ([]ScriptResult, error) {
+	var (
+		results []ScriptResult
+		err     error
+	)
+	if cfg.Hooks.PreRun != "" {
+		res := runHook(ctx, dir, cfg, ecfg, HookPreRun, cfg.Hooks.PreRun)
+		results = append(results, res)
+		if res.Err != nil {
+			err = fmt.Errorf("%s hook failed: %w", HookPreRun, res.Err)
+		}
+	}
+	if err == nil {
+		var jobResults []ScriptResult
+		jobResults, err = runJob(ctx, dir, cfg, ecfg)
+		results = append(results, jobResults...)
+	}
+	if cfg.Hooks.PostRun == "" {
+		return results, err
+	}
+
+	hookCtx := ctx
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		hookCtx, cancel = context.WithTimeout(context.WithoutCancel(ctx), postRunGrace)
+		defer cancel()
+	}
+	hookCfg := ecfg
+	hookCfg.ArgEnv = make(map[string]string, len(ecfg.ArgEnv)+1)
+	for k, v := range ecfg.ArgEnv {
+		hookCfg.ArgEnv[k] = v
+	}
+	hookCfg.ArgEnv[RunStatusEnv] = runStatus(ctx, results, err)
+	res := runHook(hookCtx, dir, cfg, hookCfg, HookPostRun, cfg.Hooks.PostRun)
+	results = append(results, res)
+	if res.Err != nil && err == nil {
+		err = fmt.Errorf("%s hook failed: %w", HookPostRun, res.Err)
+	}
+	return results, err
+}
+
+// runStatus mirrors how the server classifies a finished run.
+func runStatus(ctx context.Context, results []ScriptResult, err error) string {
+	var timeout *TimeoutError
+	if timeoutCause(ctx) != nil || errors.As(err, &timeout) {
+		return "timed_out"
+	}
+	if errors.Is(err, context.Canceled) || ctx.Err() != nil {
+		return "canceled"
+	}
+	if err != nil {
+		return "failed"
+	}
+	for _, res := range results {
+		if res.ExitCode != 0 {
+			return "failed"
+		}
+	}
+	return "completed"
+}
+
+// runHook executes one hook script with the job's interpreter, on the job's
+// host for ssh jobs, or in the job's container image for container and
+// kubernetes jobs, emitting it as a step.
+func runHook(ctx context.Context, dir string, cfg *types.Config, ecfg ExecutorConfig, stepID, script string) ScriptResult {
+	scriptPath := filepath.Join(dir, filepath.FromSlash(script))
+	interpreter := hookInterpreter(cfg)
+	if ecfg.DryRun && !strings.HasPrefix(interpreter, "container:") {
+		return ScriptResult{Name: stepID}
+	}
+	if ecfg.Emitter != nil {
+		ecfg.Emitter.EmitStepStart(ecfg.RunID, stepID)
+	}
+	finish := func(res ScriptResult) ScriptResult {
+		if ecfg.Emitter != nil {
+			ecfg.Emitter.EmitStepFinish(ecfg.RunID, stepID, res.ExitCode, res.Err)
+		}
+		return res
+	}
+	if err := verifyScriptDigest(dir, scriptPath, ecfg.ScriptDigests); err != nil {
+		return finish(ScriptResult{Name: stepID, ExitCode: -1, Err: err})
+	}
+
+	flagArgs := scriptArgs(cfg, ecfg)
+	switch {
+	case isKubernetesExecutor(cfg):
+		if cfg.Container == nil || strings.TrimSpace(cfg.Container.Image) == "" {
+			return finish(ScriptResult{Name: stepID, ExitCode: -1, Err: fmt.Errorf("no container image defined for %s hook", stepID)})
+		}
+		return finish(runKubernetesStep(ctx, cfg, ecfg, scriptPath, strings.TrimSpace(cfg.Container.Image), flagArgs, ecfg.Emitter, stepID, stepOptions{}))
+	case isSSHExecutor(cfg):
+		return finish(runSSHStep(ctx, cfg, ecfg, scriptPath, interpreter, flagArgs, ecfg.Emitter, stepID, stepOptions{}))
+	case interpreter == "":
+		return finish(ScriptResult{Name: stepID, ExitCode: -1, Err: fmt.Errorf("no interpreter defined for %s hook", stepID)})
+	case strings.HasPrefix(interpreter, "container:"):
+		return finish(runContainerStep(ctx, cfg, ecfg, scriptPath, interpreter, flagArgs, ecfg.Emitter, stepID, stepOptions{}))
+	}
+	return finish(executeProcessStep(ctx, cfg, ecfg, scriptPath, stepID, interpreter, flagArgs, stepID, "", 0, 0, stepOptions{}))
+}
+
+// hookInterpreter picks the sandbox hooks share with the job's steps.
+func hookInterpreter(cfg *types.Config) string {
+	if isDAGConfig(cfg) && strings.EqualFold(strings.TrimSpace(cfg.Executor), "container") {
+		if cfg.Container == nil || strings.TrimSpace(cfg.Container.Image) == "" {
+			return ""
+		}
+		return "container:" + strings.TrimSpace(cfg.Container.Image)
+	}
+	return cfg.Interpreter
+}
@@ -0,0 +1,101 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeHookJob(t *testing.T, scripts map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "config.d"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	config := `version: v1
+job:
+  id: hooks
+  name: Hooks
+interpreter: bash
+hooks:
+  pre_run: scripts/pre.sh
+  post_run: scripts/post.sh
+`
+	if err := os.WriteFile(filepath.Join(dir, "config.d", "config.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	for name, body := range scripts {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o755); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func runHookJob(ctx context.Context, t *testing.T, dir string) ([]ScriptResult, string, error) {
+	t.Helper()
+	var stdout bytes.Buffer
+	results, err := RunScripts(ctx, dir, ExecutorConfig{
+		Strict:       true,
+		RunDir:       t.TempDir(),
+		StdoutWriter: &stdout,
+		StderrWriter: os.Stderr,
+	})
+	return results, strings.TrimSpace(stdout.String()), err
+}
+
+func TestRunScriptsHooksWrapSteps(t *testing.T) {
+	dir := writeHookJob(t, map[string]string{
+		"scripts/pre.sh":  "echo pre\n",
+		"100_main.sh":     "echo main\nexit 3\n",
+		"scripts/post.sh": "echo \"post $FLWD_RUN_STATUS\"\n",
+	})
+	results, out, err := runHookJob(context.Background(), t, dir)
+	if err == nil {
+		t.Fatalf("expected main step failure")
+	}
+	if out != "pre\nmain\npost failed" {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if len(results) != 3 || results[0].Name != HookPreRun || results[2].Name != HookPostRun || results[2].ExitCode != 0 {
+		t.Fatalf("unexpected results %+v", results)
+	}
+}
+
+func TestRunScriptsPreRunFailureSkipsSteps(t *testing.T) {
+	dir := writeHookJob(t, map[string]string{
+		"scripts/pre.sh":  "exit 1\n",
+		"100_main.sh":     "echo main\n",
+		"scripts/post.sh": "echo \"post $FLWD_RUN_STATUS\"\n",
+	})
+	results, out, err := runHookJob(context.Background(), t, dir)
+	if err == nil || !strings.Contains(err.Error(), "pre_run hook failed") {
+		t.Fatalf("expected pre_run failure, got %v", err)
+	}
+	if out != "post failed" || len(results) != 2 {
+		t.Fatalf("expected only hooks to run, got %q and %+v", out, results)
+	}
+}
+
+func TestRunScriptsPostRunAfterCancel(t *testing.T) {
+	dir := writeHookJob(t, map[string]string{
+		"scripts/pre.sh":  "true\n",
+		"100_main.sh":     "exec sleep 5\n",
+		"scripts/post.sh": "echo \"post $FLWD_RUN_STATUS\"\n",
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(200*time.Millisecond, cancel)
+	_, out, _ := runHookJob(ctx, t, dir)
+	if out != "post canceled" {
+		t.Fatalf("expected post_run to see cancellation, got %q", out)
+	}
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// ScriptTamperedError reports a script whose content changed after the plan
+// recorded its digest.
+type ScriptTamperedError struct {
+	Script   string
+	Expected string
+	Actual   string
+}
+
+func (e *ScriptTamperedError) Error() string {
+	if e.Actual == "" {
+		return fmt.Sprintf("script %s changed since plan: not recorded in plan", e.Script)
+	}
+	return fmt.Sprintf("script %s changed since plan: expected sha256 %s, got %s", e.Script, e.Expected, e.Actual)
+}
+
+// listScripts returns the phase scripts (000_, 100_, 999_) of a job directory
+// in execution order.
+func listScripts(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading dir: %w", err)
+	}
+	var scripts []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		if strings.HasPrefix(name, "000_") || strings.HasPrefix(name, "100_") || strings.HasPrefix(name, "999_") {
+			scripts = append(scripts, name)
+		}
+	}
+	sort.Strings(scripts)
+	return scripts, nil
+}
+
+// ScriptDigests hashes every script RunScripts would execute for the job in
+// dir, in execution order. DAG jobs contribute one entry per distinct step
+// script, hooks one entry each; scripts that do not exist are skipped.
+func ScriptDigests(dir string, cfg *types.Config) ([]types.ScriptDigest, error) {
+	var paths []string
+	if cfg != nil && cfg.Hooks != nil && cfg.Hooks.PreRun != "" {
+		paths = append(paths, filepath.Join(dir, filepath.FromSlash(cfg.Hooks.PreRun)))
+	}
+	if isDAGConfig(cfg) {
+		seen := map[string]bool{}
+		for _, step := range cfg.Steps {
+			script := strings.TrimSpace(step.Script)
+			if script == "" || seen[script] {
+				continue
+			}
+			seen[script] = true
+			if !filepath.IsAbs(script) {
+				script = filepath.Join(dir, script)
+			}
+			paths = append(paths, script)
+		}
+	} else {
+		scripts, err := listScripts(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range scripts {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+	if cfg != nil && cfg.Hooks != nil && cfg.Hooks.PostRun != "" {
+		paths = append(paths, filepath.Join(dir, filepath.FromSlash(cfg.Hooks.PostRun)))
+	}
+	out := make([]types.ScriptDigest, 0, len(paths))
+	for _, path := range paths {
+		sum, err := HashFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			// Missing step scripts fail at execution; previews still plan.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, types.ScriptDigest{Path: scriptKey(dir, path), SHA256: sum})
+	}
+	return out, nil
+}
+
+// DigestMap indexes digests by script path for ExecutorConfig.ScriptDigests.
+func DigestMap(digests []types.ScriptDigest) map[string]string {
+	if len(digests) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(digests))
+	for _, d := range digests {
+		out[d.Path] = d.SHA256
+	}
+	return out
+}
+
+// HashFile returns the hex-encoded sha256 of the file at path.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("hash script: %w", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hash script: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func verifyScriptDigest(dir, scriptPath string, expected map[string]string) error {
+	if expected == nil {
+		return nil
+	}
+	key := scriptKey(dir, scriptPath)
+	want, ok := expected[key]
+	if !ok {
+		return &ScriptTamperedError{Script: key}
+	}
+	got, err := HashFile(scriptPath)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return &ScriptTamperedError{Script: key, Expected: want, Actual: got}
+	}
+	return nil
+}
+
+// scriptKey renders path relative to the job directory when it lives inside
+// it, and as a cleaned absolute path otherwise.
+func scriptKey(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// CheckScripts re-hashes recorded scripts relative to dir and returns one
+// error per script that is missing or whose content no longer matches.
+func CheckScripts(dir string, digests []types.ScriptDigest) []error {
+	var errs []error
+	for _, d := range digests {
+		path := filepath.FromSlash(d.Path)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		got, err := HashFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("script %s: %w", d.Path, err))
+			continue
+		}
+		if got != d.SHA256 {
+			errs = append(errs, &ScriptTamperedError{Script: d.Path, Expected: d.SHA256, Actual: got})
+		}
+	}
+	return errs
+}
@@ -0,0 +1,93 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeIntegrityJob(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "config.d"), 0o755); err != nil {
+		t.Fatalf("mkdir config.d: %v", err)
+	}
+	config := "version: v1\njob:\n  id: integrity\n  name: Integrity\ninterpreter: bash\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.d", "config.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	for name, body := range map[string]string{
+		"100_main.sh": "echo main\n",
+		"000_pre.sh":  "true\n",
+		"notes.txt":   "not a script\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o755); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestScriptDigestsListsScriptsInExecutionOrder(t *testing.T) {
+	dir := writeIntegrityJob(t)
+	digests, err := ScriptDigests(dir, nil)
+	if err != nil {
+		t.Fatalf("ScriptDigests: %v", err)
+	}
+	if len(digests) != 2 || digests[0].Path != "000_pre.sh" || digests[1].Path != "100_main.sh" {
+		t.Fatalf("unexpected digests: %+v", digests)
+	}
+	want, _ := HashFile(filepath.Join(dir, "100_main.sh"))
+	if digests[1].SHA256 != want || len(want) != 64 {
+		t.Fatalf("expected sha256 %s, got %s", want, digests[1].SHA256)
+	}
+}
+
+func TestRunScriptsRefusesScriptChangedSincePlan(t *testing.T) {
+	dir := writeIntegrityJob(t)
+	digests, err := ScriptDigests(dir, nil)
+	if err != nil {
+		t.Fatalf("ScriptDigests: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "100_main.sh"), []byte("echo replaced\n"), 0o755); err != nil {
+		t.Fatalf("replace script: %v", err)
+	}
+
+	results, err := RunScripts(context.Background(), dir, ExecutorConfig{
+		Strict:        true,
+		RunDir:        t.TempDir(),
+		ScriptDigests: DigestMap(digests),
+		StdoutWriter:  os.Stdout,
+		StderrWriter:  os.Stderr,
+	})
+	var tampered *ScriptTamperedError
+	if !errors.As(err, &tampered) {
+		t.Fatalf("expected ScriptTamperedError, got %v", err)
+	}
+	if tampered.Script != "100_main.sh" || tampered.Expected != digests[1].SHA256 {
+		t.Fatalf("unexpected tamper report: %+v", tampered)
+	}
+	last := results[len(results)-1]
+	if last.Name != "100_main.sh" || last.ExitCode != -1 {
+		t.Fatalf("expected failed result for replaced script, got %+v", last)
+	}
+}
+
+func TestRunScriptsSandboxedRefusesProcessSteps(t *testing.T) {
+	dir := writeIntegrityJob(t)
+	results, err := RunScripts(context.Background(), dir, ExecutorConfig{
+		Strict:       true,
+		RunDir:       t.TempDir(),
+		Sandboxed:    true,
+		StdoutWriter: os.Stdout,
+		StderrWriter: os.Stderr,
+	})
+	if !errors.Is(err, ErrSandboxedProcessStep) {
+		t.Fatalf("expected ErrSandboxedProcessStep, got %v", err)
+	}
+	if len(results) != 1 || results[0].ExitCode != -1 {
+		t.Fatalf("expected first step refused, got %+v", results)
+	}
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/events"
+	"github.com/flowd-org/flowd/internal/executor/kubernetes"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// ExecutorKubernetes runs each step as a pod on a Kubernetes cluster.
+const ExecutorKubernetes = "kubernetes"
+
+func isKubernetesExecutor(cfg *types.Config) bool {
+	return cfg != nil && strings.EqualFold(strings.TrimSpace(cfg.Executor), ExecutorKubernetes)
+}
+
+// runKubernetesStep runs the script in a pod built from image and the job's
+// container and kubernetes settings. The pod has no access to the host, so
+// the run directory is the pod's own scratch volume and $FLWD_OUTPUTS is not
+// set. Pod logs interleave stdout and stderr and are reported as stdout.
+func runKubernetesStep(ctx context.Context, cfg *types.Config, ecfg ExecutorConfig, scriptPath, image string, flagArgs []string, sink events.Sink, stepID string, stepOpts stepOptions) ScriptResult {
+	if ecfg.Sandboxed {
+		// Pods cannot be confined the way local sandboxed containers are.
+		return ScriptResult{Name: stepID, ExitCode: -1, Err: ErrSandboxedProcessStep}
+	}
+	script, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return ScriptResult{Name: stepID, ExitCode: -1, Err: fmt.Errorf("read script: %w", err)}
+	}
+
+	inherit := ecfg.EnvInherit
+	if !inherit && cfg != nil && cfg.EnvInheritance {
+		inherit = true
+	}
+	env := make(map[string]string)
+	for _, kv := range buildSecureEnv(cfg, ecfg.ArgEnv, ecfg.ArgsJSON, inherit, ecfg.EnvFilter) {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	// Host paths mean nothing inside the pod.
+	delete(env, "PATH")
+	delete(env, "HOME")
+	for _, k := range []string{"FLOWD_RUN_DIR", "RUN_DIR", "FLWD_RUN_DIR"} {
+		env[k] = kubernetes.WorkDir
+	}
+
+	_, grace := cancelPolicy(cfg)
+	opts := kubernetes.PodOptions{
+		Name:        kubernetes.PodName("flwd", ecfg.RunID, stepID),
+		Labels:      map[string]string{"flowd.dev/run-id": kubernetes.PodName(ecfg.RunID)},
+		Image:       image,
+		Script:      string(script),
+		Args:        flagArgs,
+		Env:         env,
+		GracePeriod: grace,
+	}
+	if stepOpts.shell != "" {
+		opts.Shell = strings.Fields(stepOpts.shell)
+	}
+	if stdin := scriptStdin(cfg, ecfg); stdin != nil {
+		data, _ := io.ReadAll(stdin)
+		opts.Stdin = string(data)
+	}
+	client := kubernetes.Client{}
+	if cfg != nil && cfg.Kubernetes != nil {
+		k := cfg.Kubernetes
+		client = kubernetes.Client{Context: strings.TrimSpace(k.Context), Namespace: strings.TrimSpace(k.Namespace)}
+		opts.Namespace = client.Namespace
+		opts.ServiceAccount = strings.TrimSpace(k.ServiceAccount)
+		opts.NodeSelector = k.NodeSelector
+	}
+	if cfg != nil && cfg.Container != nil && cfg.Container.Resources != nil {
+		opts.CPU = cfg.Container.Resources.CPU
+		opts.Memory = cfg.Container.Resources.Memory
+	}
+
+	stdoutWriter := newStepWriter(ecfg, sink, stepID, "stdout", ecfg.StdoutWriter, new(atomic.Int64))
+	start := time.Now()
+	code, err := client.Run(ctx, opts, stdoutWriter)
+	stdoutWriter.Flush()
+	result := ScriptResult{Name: stepID, ExitCode: code, Duration: time.Since(start), Err: err}
+	if ctx.Err() != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		// The pod is deleted with the job's grace period and SIGTERM;
+		// Kubernetes kills it once the grace period is over.
+		result.Shutdown = types.ShutdownGraceful
+	}
+	return result
+}
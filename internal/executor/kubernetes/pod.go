@@ -0,0 +1,408 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package kubernetes runs job steps as pods on a Kubernetes cluster through
+// the kubectl CLI, so flowd needs no local container runtime or cluster
+// client libraries: kubectl's own kubeconfig decides which cluster is used.
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WorkDir is the writable emptyDir volume mounted into every step pod. The
+// step script is copied there before it runs, and it doubles as the step's
+// run directory.
+const WorkDir = "/flwd"
+
+// ScriptEnv and StdinEnv carry the step script and its standard input into
+// the pod; the entrypoint writes them under WorkDir and unsets them.
+const (
+	ScriptEnv = "FLWD_STEP_SCRIPT"
+	StdinEnv  = "FLWD_STEP_STDIN"
+)
+
+// ManagedByLabel marks the pods flowd creates.
+const ManagedByLabel = "app.kubernetes.io/managed-by"
+
+// PodOptions describes the pod a step runs in.
+type PodOptions struct {
+	Name           string
+	Namespace      string
+	ServiceAccount string
+	NodeSelector   map[string]string
+	Labels         map[string]string
+	Image          string
+	// Script is the content of the step script, started with Args. It is run
+	// directly, so its shebang picks the interpreter, unless Shell is set.
+	Script string
+	Args   []string
+	// Shell, e.g. ["bash", "-eu"], runs the script through an interpreter.
+	Shell []string
+	// Stdin is fed to the script's standard input when non-empty.
+	Stdin string
+	Env   map[string]string
+	// CPU and Memory become both the request and the limit of the step
+	// container, e.g. "500m" and "256Mi".
+	CPU    string
+	Memory string
+	// GracePeriod is how long the pod may take to exit after SIGTERM when it
+	// is deleted.
+	GracePeriod time.Duration
+}
+
+// ExitError reports a step pod whose container exited with a non-zero code.
+type ExitError struct {
+	Pod  string
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("pod %s exited with code %d", e.Pod, e.Code)
+}
+
+// entrypoint materializes the script and its stdin in WorkDir and runs it
+// with the step arguments.
+const entrypoint = `umask 077
+printf '%s' "$` + ScriptEnv + `" > ` + WorkDir + `/step
+printf '%s' "$` + StdinEnv + `" > ` + WorkDir + `/stdin
+chmod 700 ` + WorkDir + `/step
+unset ` + ScriptEnv + ` ` + StdinEnv + `
+exec "$@" < ` + WorkDir + `/stdin`
+
+// BuildPod renders the pod manifest for opts as JSON, with the same secure
+// defaults as local container steps: no privilege escalation, all
+// capabilities dropped and a read-only root filesystem. Only WorkDir is
+// writable.
+func BuildPod(opts PodOptions) ([]byte, error) {
+	if strings.TrimSpace(opts.Image) == "" {
+		return nil, fmt.Errorf("image is required")
+	}
+	if strings.TrimSpace(opts.Name) == "" {
+		return nil, fmt.Errorf("pod name is required")
+	}
+	command := append([]string{}, opts.Shell...)
+	if len(command) > 0 && command[0] == "pwsh" {
+		command = append(command, "-File")
+	}
+	command = append(command, WorkDir+"/step")
+	command = append(command, opts.Args...)
+
+	env := []map[string]string{
+		{"name": ScriptEnv, "value": opts.Script},
+		{"name": StdinEnv, "value": opts.Stdin},
+	}
+	keys := make([]string, 0, len(opts.Env))
+	for k := range opts.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, map[string]string{"name": k, "value": opts.Env[k]})
+	}
+
+	labels := map[string]string{ManagedByLabel: "flowd"}
+	for k, v := range opts.Labels {
+		labels[k] = v
+	}
+	metadata := map[string]any{"name": opts.Name, "labels": labels}
+	if opts.Namespace != "" {
+		metadata["namespace"] = opts.Namespace
+	}
+
+	stepContainer := map[string]any{
+		"name":       "step",
+		"image":      opts.Image,
+		"command":    append([]string{"/bin/sh", "-c", entrypoint, "flwd-step"}, command...),
+		"env":        env,
+		"workingDir": WorkDir,
+		"securityContext": map[string]any{
+			"allowPrivilegeEscalation": false,
+			"readOnlyRootFilesystem":   true,
+			"capabilities":             map[string]any{"drop": []string{"ALL"}},
+		},
+		"volumeMounts": []map[string]any{{"name": "work", "mountPath": WorkDir}},
+	}
+	if resources := podResources(opts.CPU, opts.Memory); resources != nil {
+		stepContainer["resources"] = map[string]any{"requests": resources, "limits": resources}
+	}
+
+	spec := map[string]any{
+		"restartPolicy": "Never",
+		"containers":    []any{stepContainer},
+		"volumes":       []map[string]any{{"name": "work", "emptyDir": map[string]any{}}},
+	}
+	if opts.ServiceAccount != "" {
+		spec["serviceAccountName"] = opts.ServiceAccount
+	}
+	if len(opts.NodeSelector) > 0 {
+		spec["nodeSelector"] = opts.NodeSelector
+	}
+	if opts.GracePeriod > 0 {
+		spec["terminationGracePeriodSeconds"] = int64(opts.GracePeriod.Seconds())
+	}
+	return json.Marshal(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   metadata,
+		"spec":       spec,
+	})
+}
+
+func podResources(cpu, memory string) map[string]string {
+	out := map[string]string{}
+	if v := strings.TrimSpace(cpu); v != "" {
+		out["cpu"] = v
+	}
+	if v := strings.TrimSpace(memory); v != "" {
+		out["memory"] = v
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// PodName derives a DNS-1123 pod name from parts, e.g. the run and step IDs.
+func PodName(parts ...string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.Join(parts, "-")) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	name := strings.Trim(b.String(), "-")
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-")
+	}
+	if name == "" {
+		name = "flwd-step"
+	}
+	return name
+}
+
+// PodStatus is the part of a pod's status that decides a step's outcome.
+type PodStatus struct {
+	Phase   string
+	Reason  string
+	Message string
+	// ExitCode is set once the step container has terminated.
+	ExitCode *int
+	// Waiting is the reason the step container has not started, e.g.
+	// ImagePullBackOff.
+	Waiting        string
+	WaitingMessage string
+}
+
+// failedWaitReasons are container waiting reasons a pod does not recover
+// from without intervention, so the step fails instead of waiting forever.
+var failedWaitReasons = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// Outcome maps the pod status to the step's exit code. done is false while
+// the pod is still pending or running. A pod that failed without an exit
+// code (evicted, unschedulable image, ...) reports -1 and an error.
+func (s PodStatus) Outcome(pod string) (code int, done bool, err error) {
+	switch s.Phase {
+	case "Succeeded":
+		return 0, true, nil
+	case "Failed":
+		if s.ExitCode != nil && *s.ExitCode != 0 {
+			return *s.ExitCode, true, &ExitError{Pod: pod, Code: *s.ExitCode}
+		}
+		return -1, true, fmt.Errorf("pod %s failed: %s", pod, describe(s.Reason, s.Message))
+	case "Unknown":
+		return -1, true, fmt.Errorf("pod %s state unknown: %s", pod, describe(s.Reason, s.Message))
+	}
+	if failedWaitReasons[s.Waiting] {
+		return -1, true, fmt.Errorf("pod %s cannot start: %s", pod, describe(s.Waiting, s.WaitingMessage))
+	}
+	return 0, false, nil
+}
+
+func describe(reason, message string) string {
+	switch {
+	case reason == "" && message == "":
+		return "no reason reported"
+	case message == "":
+		return reason
+	case reason == "":
+		return message
+	}
+	return reason + ": " + message
+}
+
+// kubectl runs kubectl with args, feeding it stdin and copying its standard
+// output to stdout. Standard error is returned in the error. Tests replace it.
+var kubectl = func(ctx context.Context, stdin io.Reader, stdout io.Writer, args ...string) error {
+	cmd := exec.CommandContext(ctx, "kubectl", args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("kubectl %s: %w: %s", args[0], err, msg)
+		}
+		return fmt.Errorf("kubectl %s: %w", args[0], err)
+	}
+	return nil
+}
+
+// Client issues kubectl commands against one kubeconfig context (the
+// current one when Context is empty) and namespace (the context's default
+// when empty).
+type Client struct {
+	Context   string
+	Namespace string
+}
+
+func (c Client) args(args ...string) []string {
+	if c.Namespace != "" {
+		args = append(args, "--namespace", c.Namespace)
+	}
+	if c.Context != "" {
+		args = append(args, "--context", c.Context)
+	}
+	return args
+}
+
+// CreatePod submits the pod manifest.
+func (c Client) CreatePod(ctx context.Context, manifest []byte) error {
+	return kubectl(ctx, bytes.NewReader(manifest), io.Discard, c.args("create", "-f", "-")...)
+}
+
+// StreamLogs follows the logs of the pod's step container into out, waiting
+// up to startTimeout for it to start. Kubernetes interleaves stdout and
+// stderr into a single stream.
+func (c Client) StreamLogs(ctx context.Context, name string, out io.Writer, startTimeout time.Duration) error {
+	return kubectl(ctx, nil, out, c.args("logs", "--follow", "--container", "step",
+		"--pod-running-timeout", startTimeout.String(), "pod/"+name)...)
+}
+
+// Status fetches the pod's status.
+func (c Client) Status(ctx context.Context, name string) (PodStatus, error) {
+	var out bytes.Buffer
+	if err := kubectl(ctx, nil, &out, c.args("get", "pod", name, "--output", "json")...); err != nil {
+		return PodStatus{}, err
+	}
+	var pod struct {
+		Status struct {
+			Phase             string `json:"phase"`
+			Reason            string `json:"reason"`
+			Message           string `json:"message"`
+			ContainerStatuses []struct {
+				Name  string `json:"name"`
+				State struct {
+					Waiting *struct {
+						Reason  string `json:"reason"`
+						Message string `json:"message"`
+					} `json:"waiting"`
+					Terminated *struct {
+						ExitCode int    `json:"exitCode"`
+						Reason   string `json:"reason"`
+					} `json:"terminated"`
+				} `json:"state"`
+			} `json:"containerStatuses"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &pod); err != nil {
+		return PodStatus{}, fmt.Errorf("decode pod %s: %w", name, err)
+	}
+	status := PodStatus{Phase: pod.Status.Phase, Reason: pod.Status.Reason, Message: pod.Status.Message}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name != "step" {
+			continue
+		}
+		if t := cs.State.Terminated; t != nil {
+			code := t.ExitCode
+			status.ExitCode = &code
+			if status.Reason == "" {
+				status.Reason = t.Reason
+			}
+		}
+		if w := cs.State.Waiting; w != nil {
+			status.Waiting, status.WaitingMessage = w.Reason, w.Message
+		}
+	}
+	return status, nil
+}
+
+// DeletePod deletes the pod without waiting for it to go away, giving it
+// grace to exit after SIGTERM. A pod that is already gone is not an error.
+func (c Client) DeletePod(ctx context.Context, name string, grace time.Duration) error {
+	return kubectl(ctx, nil, io.Discard, c.args("delete", "pod", name, "--ignore-not-found", "--wait=false",
+		"--grace-period", strconv.Itoa(int(grace.Seconds())))...)
+}
+
+// statusPollInterval is how often Run checks a pod whose logs have ended but
+// whose phase has not caught up yet.
+var statusPollInterval = time.Second
+
+// DefaultStartTimeout bounds how long a step pod may stay pending, e.g. while
+// its image is pulled or it waits for a node.
+const DefaultStartTimeout = 10 * time.Minute
+
+// Run creates the pod, streams its logs to out, waits for it to finish and
+// deletes it. It returns the step container's exit code; a non-zero code
+// comes with an *ExitError. When ctx ends first the pod is deleted with
+// opts.GracePeriod and ctx's error is returned.
+func (c Client) Run(ctx context.Context, opts PodOptions, out io.Writer) (int, error) {
+	manifest, err := BuildPod(opts)
+	if err != nil {
+		return -1, err
+	}
+	if err := c.CreatePod(ctx, manifest); err != nil {
+		return -1, fmt.Errorf("create pod %s: %w", opts.Name, err)
+	}
+	defer func() {
+		// The run context may be done already; cleanup must still happen.
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_ = c.DeletePod(cleanupCtx, opts.Name, opts.GracePeriod)
+	}()
+
+	logErr := c.StreamLogs(ctx, opts.Name, out, DefaultStartTimeout)
+	for {
+		if ctx.Err() != nil {
+			return -1, ctx.Err()
+		}
+		status, err := c.Status(ctx, opts.Name)
+		if err != nil {
+			if ctx.Err() != nil {
+				return -1, ctx.Err()
+			}
+			return -1, errors.Join(logErr, err)
+		}
+		code, done, err := status.Outcome(opts.Name)
+		if done {
+			return code, err
+		}
+		if logErr != nil && status.Phase == "Pending" {
+			// The pod never started within the start timeout.
+			return -1, fmt.Errorf("pod %s did not start: %w", opts.Name, logErr)
+		}
+		// Logs have ended (or the stream broke off) while the pod is still
+		// running; wait for the phase to settle.
+		select {
+		case <-ctx.Done():
+		case <-time.After(statusPollInterval):
+		}
+	}
+}
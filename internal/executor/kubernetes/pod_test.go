@@ -0,0 +1,159 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildPod(t *testing.T) {
+	manifest, err := BuildPod(PodOptions{
+		Name:           "run-1-build",
+		Namespace:      "ci",
+		ServiceAccount: "flowd-runner",
+		NodeSelector:   map[string]string{"pool": "batch"},
+		Labels:         map[string]string{"flowd.dev/run-id": "run-1"},
+		Image:          "alpine:3.20",
+		Script:         "#!/bin/sh\necho hi\n",
+		Args:           []string{"--target=prod"},
+		Env:            map[string]string{"ARG_TARGET": "prod"},
+		CPU:            "500m",
+		Memory:         "256Mi",
+		GracePeriod:    30 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("build pod: %v", err)
+	}
+	var pod struct {
+		Metadata struct {
+			Name      string            `json:"name"`
+			Namespace string            `json:"namespace"`
+			Labels    map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			RestartPolicy      string            `json:"restartPolicy"`
+			ServiceAccountName string            `json:"serviceAccountName"`
+			NodeSelector       map[string]string `json:"nodeSelector"`
+			Grace              int64             `json:"terminationGracePeriodSeconds"`
+			Containers         []struct {
+				Image   string   `json:"image"`
+				Command []string `json:"command"`
+				Env     []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"env"`
+				Resources struct {
+					Limits map[string]string `json:"limits"`
+				} `json:"resources"`
+				SecurityContext struct {
+					ReadOnlyRootFilesystem bool `json:"readOnlyRootFilesystem"`
+				} `json:"securityContext"`
+			} `json:"containers"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(manifest, &pod); err != nil {
+		t.Fatalf("decode manifest: %v", err)
+	}
+	if pod.Metadata.Name != "run-1-build" || pod.Metadata.Namespace != "ci" || pod.Metadata.Labels[ManagedByLabel] != "flowd" || pod.Metadata.Labels["flowd.dev/run-id"] != "run-1" {
+		t.Fatalf("unexpected metadata %+v", pod.Metadata)
+	}
+	if pod.Spec.RestartPolicy != "Never" || pod.Spec.ServiceAccountName != "flowd-runner" || pod.Spec.NodeSelector["pool"] != "batch" || pod.Spec.Grace != 30 {
+		t.Fatalf("unexpected spec %+v", pod.Spec)
+	}
+	c := pod.Spec.Containers[0]
+	if c.Image != "alpine:3.20" || !c.SecurityContext.ReadOnlyRootFilesystem || c.Resources.Limits["cpu"] != "500m" || c.Resources.Limits["memory"] != "256Mi" {
+		t.Fatalf("unexpected container %+v", c)
+	}
+	if got := strings.Join(c.Command[len(c.Command)-2:], " "); got != WorkDir+"/step --target=prod" {
+		t.Fatalf("unexpected command tail %q", got)
+	}
+	if c.Env[0].Name != ScriptEnv || c.Env[0].Value != "#!/bin/sh\necho hi\n" || c.Env[2].Name != "ARG_TARGET" {
+		t.Fatalf("unexpected env %+v", c.Env)
+	}
+}
+
+func TestPodStatusOutcome(t *testing.T) {
+	code := func(n int) *int { return &n }
+	cases := []struct {
+		name     string
+		status   PodStatus
+		wantCode int
+		wantDone bool
+		wantErr  string
+	}{
+		{"running", PodStatus{Phase: "Running"}, 0, false, ""},
+		{"succeeded", PodStatus{Phase: "Succeeded", ExitCode: code(0)}, 0, true, ""},
+		{"exit code", PodStatus{Phase: "Failed", ExitCode: code(3)}, 3, true, "exited with code 3"},
+		{"evicted", PodStatus{Phase: "Failed", Reason: "Evicted", Message: "node low on memory"}, -1, true, "Evicted: node low on memory"},
+		{"image pull", PodStatus{Phase: "Pending", Waiting: "ImagePullBackOff"}, -1, true, "cannot start: ImagePullBackOff"},
+		{"pulling", PodStatus{Phase: "Pending", Waiting: "ContainerCreating"}, 0, false, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotCode, done, err := tc.status.Outcome("p")
+			if gotCode != tc.wantCode || done != tc.wantDone {
+				t.Fatalf("Outcome = %d, %v; want %d, %v", gotCode, done, tc.wantCode, tc.wantDone)
+			}
+			if (err == nil) != (tc.wantErr == "") || (err != nil && !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Fatalf("err = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientRunStreamsLogsAndDeletesPod(t *testing.T) {
+	orig, origPoll := kubectl, statusPollInterval
+	statusPollInterval = time.Millisecond
+	t.Cleanup(func() { kubectl, statusPollInterval = orig, origPoll })
+	var calls []string
+	polls := 0
+	kubectl = func(_ context.Context, stdin io.Reader, stdout io.Writer, args ...string) error {
+		calls = append(calls, args[0])
+		switch args[0] {
+		case "create":
+			if data, _ := io.ReadAll(stdin); !bytes.Contains(data, []byte(`"kind":"Pod"`)) {
+				t.Fatalf("expected a pod manifest, got %s", data)
+			}
+		case "logs":
+			io.WriteString(stdout, "hello\n")
+		case "get":
+			polls++
+			status := `{"status":{"phase":"Running"}}`
+			if polls > 1 {
+				status = `{"status":{"phase":"Failed","containerStatuses":[{"name":"step","state":{"terminated":{"exitCode":2,"reason":"Error"}}}]}}`
+			}
+			io.WriteString(stdout, status)
+		}
+		if got := strings.Join(args, " "); !strings.Contains(got, "--namespace ci") {
+			t.Fatalf("expected the namespace on %q", got)
+		}
+		return nil
+	}
+
+	var out bytes.Buffer
+	code, err := Client{Namespace: "ci"}.Run(context.Background(), PodOptions{Name: "run-1-build", Image: "alpine"}, &out)
+	var exitErr *ExitError
+	if code != 2 || !errors.As(err, &exitErr) {
+		t.Fatalf("Run = %d, %v; want exit code 2", code, err)
+	}
+	if out.String() != "hello\n" {
+		t.Fatalf("expected streamed logs, got %q", out.String())
+	}
+	if got := strings.Join(calls, " "); got != "create logs get get delete" {
+		t.Fatalf("calls = %q", got)
+	}
+}
+
+func TestPodName(t *testing.T) {
+	if got := PodName("Run_01HX", "Build.Step"); got != "run-01hx-build-step" {
+		t.Fatalf("PodName = %q", got)
+	}
+	if got := PodName(strings.Repeat("a", 62), "b"); len(got) > 63 || strings.HasSuffix(got, "-") {
+		t.Fatalf("PodName = %q, want at most 63 chars without a trailing dash", got)
+	}
+}
@@ -0,0 +1,37 @@
+//go:build !unix
+
+package executor
+
+import (
+	"os/exec"
+	"sync/atomic"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// processGroup records whether a step was canceled on platforms without
+// process groups or catchable signals.
+type processGroup struct {
+	canceled atomic.Bool
+}
+
+// startInProcessGroup keeps the default kill of the direct child on cancel
+// and bounds the wait for its output.
+func startInProcessGroup(cmd *exec.Cmd, _ string, grace time.Duration) *processGroup {
+	pg := &processGroup{}
+	cmd.Cancel = func() error {
+		pg.canceled.Store(true)
+		return cmd.Process.Kill()
+	}
+	cmd.WaitDelay = grace + time.Second
+	return pg
+}
+
+// shutdown reports a canceled step as forced: it was killed outright.
+func (pg *processGroup) shutdown() string {
+	if pg.canceled.Load() {
+		return types.ShutdownForced
+	}
+	return ""
+}
@@ -0,0 +1,139 @@
+//go:build unix
+
+package executor
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+var cancelSignals = map[string]syscall.Signal{
+	"SIGTERM": syscall.SIGTERM,
+	"SIGINT":  syscall.SIGINT,
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+}
+
+// processGroup tracks how a canceled step's process group shut down.
+type processGroup struct {
+	mu       sync.Mutex
+	pgid     int
+	canceled bool
+	killed   bool
+	deadline time.Time
+	timer    *time.Timer
+}
+
+// startInProcessGroup runs cmd as the leader of a new process group and
+// replaces the default cancel (SIGKILL to the leader only) with signal to
+// the whole group, followed by SIGKILL once grace has passed. Background
+// children a script spawned are signaled with it instead of being orphaned.
+func startInProcessGroup(cmd *exec.Cmd, signal string, grace time.Duration) *processGroup {
+	sig, ok := cancelSignals[signal]
+	if !ok {
+		sig = syscall.SIGTERM
+	}
+	pg := &processGroup{}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+	cmd.Cancel = func() error {
+		pg.mu.Lock()
+		pg.pgid = cmd.Process.Pid
+		pg.canceled = true
+		pg.deadline = time.Now().Add(grace)
+		pgid := pg.pgid
+		pg.timer = time.AfterFunc(grace, func() {
+			pg.mu.Lock()
+			pg.killed = true
+			pg.mu.Unlock()
+			_ = syscall.Kill(-pgid, syscall.SIGKILL)
+		})
+		pg.mu.Unlock()
+		err := syscall.Kill(-pgid, sig)
+		if errors.Is(err, syscall.ESRCH) {
+			return nil
+		}
+		return err
+	}
+	// Children that survive the leader keep the output pipes open; stop
+	// waiting for them once the group has been killed.
+	cmd.WaitDelay = grace + time.Second
+	return pg
+}
+
+// shutdown reports, once the command has been waited for, whether a
+// canceled group exited within its grace period. Orphaned members linger
+// until init reaps them, so it polls until the group is gone or the grace
+// period ends. It returns "" when the step was not canceled.
+func (pg *processGroup) shutdown() string {
+	pg.mu.Lock()
+	canceled, pgid, deadline := pg.canceled, pg.pgid, pg.deadline
+	pg.mu.Unlock()
+	if !canceled {
+		return ""
+	}
+	for {
+		pg.mu.Lock()
+		killed := pg.killed
+		pg.mu.Unlock()
+		if killed {
+			return types.ShutdownForced
+		}
+		if !groupAlive(pgid) {
+			pg.timer.Stop()
+			return types.ShutdownGraceful
+		}
+		if time.Now().After(deadline) {
+			return types.ShutdownForced
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// groupAlive reports whether any process in the group is still running.
+// Zombies count as gone: orphans are reparented to init, which may never reap
+// them, e.g. when flowd itself is PID 1 in a container. Without procfs it
+// falls back to probing the group with signal 0.
+func groupAlive(pgid int) bool {
+	if err := syscall.Kill(-pgid, 0); errors.Is(err, syscall.ESRCH) {
+		return false
+	}
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return true
+	}
+	want := strconv.Itoa(pgid)
+	for _, e := range entries {
+		if _, err := strconv.Atoi(e.Name()); err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join("/proc", e.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		// Fields after the parenthesised command name: state ppid pgrp ...
+		i := bytes.LastIndexByte(data, ')')
+		if i < 0 {
+			continue
+		}
+		fields := strings.Fields(string(data[i+1:]))
+		if len(fields) >= 3 && fields[2] == want && fields[0] != "Z" {
+			return true
+		}
+	}
+	return false
+}
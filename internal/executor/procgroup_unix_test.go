@@ -0,0 +1,92 @@
+//go:build unix
+
+package executor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+func TestProcessGroupCancelReachesBackgroundChildren(t *testing.T) {
+	if _, err := os.Stat("/bin/bash"); err != nil {
+		t.Skip("/bin/bash not available")
+	}
+	cases := []struct {
+		name     string
+		signal   string
+		script   string
+		shutdown string
+	}{
+		{"terminated", "SIGTERM", `sleep 30 & echo $! > "$PIDFILE"; wait`, types.ShutdownGraceful},
+		{"custom signal", "SIGINT", `trap 'kill $child; wait $child; exit 0' INT; sleep 30 & child=$!; echo $child > "$PIDFILE"; wait`, types.ShutdownGraceful},
+		// The child ignores SIGTERM and must be killed after the grace period.
+		{"killed", "SIGTERM", `(trap '' TERM; echo $BASHPID > "$PIDFILE"; exec sleep 30) & wait`, types.ShutdownForced},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pidFile := filepath.Join(t.TempDir(), "pid")
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cmd := exec.CommandContext(ctx, "/bin/bash", "-c", tc.script)
+			cmd.Env = append(os.Environ(), "PIDFILE="+pidFile)
+			group := startInProcessGroup(cmd, tc.signal, 200*time.Millisecond)
+			if err := cmd.Start(); err != nil {
+				t.Fatal(err)
+			}
+			pid := waitForPID(t, pidFile)
+			cancel()
+			if err := cmd.Wait(); err == nil {
+				t.Fatalf("expected canceled command to fail")
+			}
+			if got := group.shutdown(); got != tc.shutdown {
+				t.Fatalf("shutdown = %q, want %q", got, tc.shutdown)
+			}
+			deadline := time.Now().Add(3 * time.Second)
+			for processAlive(pid) {
+				if time.Now().After(deadline) {
+					_ = syscall.Kill(pid, syscall.SIGKILL)
+					t.Fatalf("background child %d survived cancel", pid)
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+		})
+	}
+}
+
+func waitForPID(t *testing.T, path string) int {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if data, err := os.ReadFile(path); err == nil {
+			if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+				return pid
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("child pid not written to %s", path)
+	return 0
+}
+
+// processAlive treats zombies as dead: orphans are reaped by init, which may
+// lag behind in containers.
+func processAlive(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return false
+	}
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return true
+	}
+	fields := strings.Fields(string(data))
+	return len(fields) < 3 || fields[2] != "Z"
+}
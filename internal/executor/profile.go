@@ -25,6 +25,9 @@ param (
   [Parameter(ValueFromRemainingArguments = $true)][string[]]$ScriptArgs
 )
 ` + "\n"
+	case interpreterName(interp) == "sh":
+		// POSIX sh has no BASH_ENV equivalent to load a profile from.
+		return "", func() {}, nil
 	default:
 		return "", nil, fmt.Errorf("unsupported interpreter for profile: %s", interp)
 	}
@@ -243,3 +246,12 @@ func toStringMap(v interface{}) map[string]string {
 	}
 	return out
 }
+
+// interpreterName returns the base name of the interpreter command.
+func interpreterName(interp string) string {
+	fields := strings.Fields(interp)
+	if len(fields) == 0 {
+		return ""
+	}
+	return filepath.Base(fields[0])
+}
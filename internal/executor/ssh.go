@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/events"
+	"github.com/flowd-org/flowd/internal/executor/ssh"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// ExecutorSSH runs each step on a remote host over SSH.
+const ExecutorSSH = "ssh"
+
+func isSSHExecutor(cfg *types.Config) bool {
+	return cfg != nil && strings.EqualFold(strings.TrimSpace(cfg.Executor), ExecutorSSH)
+}
+
+// runSSHStep copies the script to a scratch directory on the job's ssh host
+// and runs it there under interpreter. The scratch directory is the step's
+// run directory; $FLWD_OUTPUTS is not set because the host cannot reach the
+// server's run directory. Output passes through the same step writers, and so
+// the same redaction, as local steps.
+func runSSHStep(ctx context.Context, cfg *types.Config, ecfg ExecutorConfig, scriptPath, interpreter string, flagArgs []string, sink events.Sink, stepID string, stepOpts stepOptions) ScriptResult {
+	if ecfg.Sandboxed {
+		return ScriptResult{Name: stepID, ExitCode: -1, Err: ErrSandboxedProcessStep}
+	}
+	if cfg == nil || cfg.SSH == nil || strings.TrimSpace(cfg.SSH.Host) == "" {
+		return ScriptResult{Name: stepID, ExitCode: -1, Err: fmt.Errorf("ssh executor requires ssh.host")}
+	}
+	if stepOpts.shell != "" {
+		interpreter = stepOpts.shell
+	}
+	if strings.TrimSpace(interpreter) == "" {
+		return ScriptResult{Name: stepID, ExitCode: -1, Err: fmt.Errorf("no interpreter defined for ssh step %s", stepID)}
+	}
+	script, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return ScriptResult{Name: stepID, ExitCode: -1, Err: fmt.Errorf("read script: %w", err)}
+	}
+
+	inherit := ecfg.EnvInherit
+	if !inherit && cfg.EnvInheritance {
+		inherit = true
+	}
+	env := make(map[string]string)
+	for _, kv := range buildSecureEnv(cfg, ecfg.ArgEnv, ecfg.ArgsJSON, inherit, ecfg.EnvFilter) {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	// The remote login sets its own paths and user.
+	for _, k := range []string{"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TMPDIR"} {
+		delete(env, k)
+	}
+
+	signal, grace := cancelPolicy(cfg)
+	target := ssh.Target{
+		Host:           strings.TrimSpace(cfg.SSH.Host),
+		Port:           cfg.SSH.Port,
+		User:           strings.TrimSpace(cfg.SSH.User),
+		IdentityFile:   strings.TrimSpace(cfg.SSH.IdentityFile),
+		KnownHostsFile: strings.TrimSpace(cfg.SSH.KnownHostsFile),
+	}
+	opts := ssh.StepOptions{
+		Script:      string(script),
+		Interpreter: strings.Fields(interpreter),
+		Args:        flagArgs,
+		Env:         env,
+		Stdin:       scriptStdin(cfg, ecfg),
+		Signal:      signal,
+		GracePeriod: grace,
+	}
+
+	lineSeq := new(atomic.Int64)
+	stdoutWriter := newStepWriter(ecfg, sink, stepID, "stdout", ecfg.StdoutWriter, lineSeq)
+	stderrWriter := newStepWriter(ecfg, sink, stepID, "stderr", ecfg.StderrWriter, lineSeq)
+	start := time.Now()
+	code, err := target.Run(ctx, opts, stdoutWriter, stderrWriter)
+	stdoutWriter.Flush()
+	stderrWriter.Flush()
+	result := ScriptResult{Name: stepID, ExitCode: code, Duration: time.Since(start), Err: err}
+	if ctx.Err() != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		// The remote process got the cancel signal and, after the grace
+		// period, SIGKILL.
+		result.Shutdown = types.ShutdownGraceful
+	}
+	return result
+}
@@ -0,0 +1,270 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package ssh runs job steps on remote hosts through the OpenSSH client, so
+// flowd needs no SSH libraries: the client's own configuration, agent and
+// known_hosts apply, and keys never pass through flowd.
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Target is the remote host and the credentials used to reach it.
+type Target struct {
+	Host string
+	// Port defaults to the client's configured port, usually 22.
+	Port int
+	// User defaults to the client's configured user.
+	User string
+	// IdentityFile is the private key to log in with. When empty the keys
+	// held by the ssh-agent at $SSH_AUTH_SOCK are used.
+	IdentityFile string
+	// KnownHostsFile replaces the client's known_hosts. Host keys are always
+	// checked; unknown hosts are refused.
+	KnownHostsFile string
+}
+
+// StepOptions describes one step to run remotely.
+type StepOptions struct {
+	// Script is the content of the step script, started with Args by
+	// Interpreter, e.g. ["bash", "-eu"].
+	Script      string
+	Interpreter []string
+	Args        []string
+	Env         map[string]string
+	// Stdin is fed to the script's standard input.
+	Stdin io.Reader
+	// Signal and GracePeriod stop a step whose context ends: the remote
+	// process gets Signal, then SIGKILL once GracePeriod has passed.
+	Signal      string
+	GracePeriod time.Duration
+}
+
+// ExitError reports a remote step that exited with a non-zero code.
+type ExitError struct {
+	Host string
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("step on %s exited with code %d", e.Host, e.Code)
+}
+
+// connectionFailed is the exit status of the ssh client itself when it
+// cannot connect or authenticate.
+const connectionFailed = 255
+
+// runSSH runs the ssh client with args, feeding it stdin and copying its
+// standard output and error. It returns the remote command's exit code, or
+// -1 when the client did not run. Tests replace it.
+var runSSH = func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, args ...string) (int, error) {
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
+
+// Args returns the ssh client arguments that run command on the target.
+// The client never prompts, checks host keys strictly and allocates no
+// terminal, so stdout and stderr stay separate.
+func (t Target) Args(command string) []string {
+	args := []string{"-T",
+		"-o", "BatchMode=yes",
+		"-o", "StrictHostKeyChecking=yes",
+	}
+	if t.Port > 0 {
+		args = append(args, "-p", strconv.Itoa(t.Port))
+	}
+	if t.User != "" {
+		args = append(args, "-l", t.User)
+	}
+	if t.IdentityFile != "" {
+		args = append(args, "-i", t.IdentityFile, "-o", "IdentitiesOnly=yes", "-o", "IdentityAgent=none")
+	}
+	if t.KnownHostsFile != "" {
+		args = append(args, "-o", "UserKnownHostsFile="+t.KnownHostsFile)
+	}
+	return append(args, "--", t.Host, command)
+}
+
+// call runs command on the target, returning its standard output. Standard
+// error is returned in the error.
+func (t Target) call(ctx context.Context, stdin io.Reader, command string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	code, err := runSSH(ctx, stdin, &stdout, &stderr, t.Args(command)...)
+	if err == nil && code != 0 {
+		err = fmt.Errorf("exit status %d", code)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("ssh %s: %w: %s", t.Host, err, msg)
+		}
+		return "", fmt.Errorf("ssh %s: %w", t.Host, err)
+	}
+	return stdout.String(), nil
+}
+
+// uploadCommand creates a private scratch directory, unpacks the bundle read
+// from stdin into it and prints its path.
+const uploadCommand = `umask 077 && d=$(mktemp -d "${TMPDIR:-/tmp}/flwd-step.XXXXXX") && cd "$d" && sh -s && pwd`
+
+// Run copies the step script to a scratch directory on the target, runs it
+// there with stdout and stderr streamed to the given writers and removes
+// the directory. It returns the script's exit code; a non-zero code comes
+// with an *ExitError. The scratch directory is exported as $FLWD_RUN_DIR.
+// When ctx ends first the remote process is signalled and ctx's error is
+// returned.
+func (t Target) Run(ctx context.Context, opts StepOptions, stdout, stderr io.Writer) (int, error) {
+	if len(opts.Interpreter) == 0 {
+		return -1, fmt.Errorf("interpreter is required")
+	}
+	bundle, err := buildBundle(opts)
+	if err != nil {
+		return -1, err
+	}
+	out, err := t.call(ctx, strings.NewReader(bundle), uploadCommand)
+	if err != nil {
+		return -1, fmt.Errorf("copy step script: %w", err)
+	}
+	dir := strings.TrimSpace(out)
+	if !strings.HasPrefix(dir, "/") {
+		return -1, fmt.Errorf("copy step script: unexpected scratch directory %q", dir)
+	}
+	defer func() {
+		// The run context may be done already; the remote process must
+		// still be stopped and its directory removed.
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), opts.GracePeriod+30*time.Second)
+		defer cancel()
+		_, _ = t.call(cleanupCtx, nil, stopCommand(dir, opts.Signal, opts.GracePeriod))
+	}()
+
+	stdin := opts.Stdin
+	if stdin == nil {
+		stdin = strings.NewReader("")
+	}
+	code, err := runSSH(ctx, stdin, stdout, stderr, t.Args(runCommand(dir, opts))...)
+	if ctx.Err() != nil {
+		return -1, ctx.Err()
+	}
+	if err != nil {
+		return -1, fmt.Errorf("ssh %s: %w", t.Host, err)
+	}
+	if code == connectionFailed {
+		return code, fmt.Errorf("ssh %s: connection failed or step exited with code %d", t.Host, code)
+	}
+	if code != 0 {
+		return code, &ExitError{Host: t.Host, Code: code}
+	}
+	return 0, nil
+}
+
+// buildBundle renders the shell bundle uploadCommand unpacks: the script as
+// step and the exports as env, each in a quoted here-document.
+func buildBundle(opts StepOptions) (string, error) {
+	keys := make([]string, 0, len(opts.Env))
+	for k := range opts.Env {
+		if !validEnvName(k) {
+			return "", fmt.Errorf("invalid environment variable name %q", k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var env strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&env, "export %s=%s\n", k, quote(opts.Env[k]))
+	}
+
+	var nonce [8]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return "", err
+	}
+	eof := "FLWD_EOF_" + hex.EncodeToString(nonce[:])
+	var b strings.Builder
+	for _, file := range []struct{ name, content string }{{"step", opts.Script}, {"env", env.String()}} {
+		content := file.content
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		fmt.Fprintf(&b, "cat > %s <<'%s'\n%s%s\n", file.name, eof, content, eof)
+	}
+	b.WriteString("chmod 700 step\n")
+	return b.String(), nil
+}
+
+// runCommand loads the exports, points the run directory variables at the
+// scratch directory, records the step's process ID for stopCommand and
+// replaces the shell with the interpreter.
+func runCommand(dir string, opts StepOptions) string {
+	qdir := quote(dir)
+	words := make([]string, 0, len(opts.Interpreter)+len(opts.Args)+2)
+	for _, w := range opts.Interpreter {
+		words = append(words, quote(w))
+	}
+	if opts.Interpreter[0] == "pwsh" || opts.Interpreter[0] == "powershell" {
+		words = append(words, "-NoProfile", "-File")
+	}
+	words = append(words, quote(dir+"/step"))
+	for _, a := range opts.Args {
+		words = append(words, quote(a))
+	}
+	return fmt.Sprintf("cd %s && . ./env && export FLWD_RUN_DIR=%s FLOWD_RUN_DIR=%s RUN_DIR=%s && echo $$ > pid && exec %s",
+		qdir, qdir, qdir, qdir, strings.Join(words, " "))
+}
+
+// stopCommand signals a step that is still running, kills it once grace has
+// passed and removes its scratch directory.
+func stopCommand(dir, signal string, grace time.Duration) string {
+	sig := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(signal)), "SIG")
+	if sig == "" {
+		sig = "TERM"
+	}
+	qdir := quote(dir)
+	return fmt.Sprintf(`p=$(cat %s/pid 2>/dev/null); `+
+		`if [ -n "$p" ] && kill -0 "$p" 2>/dev/null; then `+
+		`kill -s %s "$p" 2>/dev/null; i=0; `+
+		`while [ $i -lt %d ] && kill -0 "$p" 2>/dev/null; do sleep 1; i=$((i+1)); done; `+
+		`kill -s KILL "$p" 2>/dev/null; fi; rm -rf %s`,
+		qdir, sig, int(grace.Seconds()), qdir)
+}
+
+// quote returns s as a single POSIX shell word.
+func quote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:@,+%", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func validEnvName(name string) bool {
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package ssh
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTargetArgs(t *testing.T) {
+	agent := Target{Host: "build-01"}.Args("true")
+	if slices.Contains(agent, "-i") || agent[len(agent)-3] != "--" || agent[len(agent)-2] != "build-01" {
+		t.Fatalf("unexpected agent args %q", agent)
+	}
+	key := Target{Host: "10.0.0.5", Port: 2222, User: "ci", IdentityFile: "/etc/flwd/id_ed25519", KnownHostsFile: "/etc/flwd/known_hosts"}.Args("true")
+	joined := strings.Join(key, " ")
+	for _, want := range []string{
+		"-o StrictHostKeyChecking=yes",
+		"-p 2222 -l ci",
+		"-i /etc/flwd/id_ed25519 -o IdentitiesOnly=yes -o IdentityAgent=none",
+		"-o UserKnownHostsFile=/etc/flwd/known_hosts",
+		"-- 10.0.0.5 true",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("expected %q in %q", want, joined)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	for in, want := range map[string]string{
+		"plain/path-1.sh": "plain/path-1.sh",
+		"":                "''",
+		"two words":       "'two words'",
+		"it's":            `'it'\''s'`,
+		"$(rm -rf /)":     "'$(rm -rf /)'",
+	} {
+		if got := quote(in); got != want {
+			t.Fatalf("quote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestRunStopsRemoteStepWhenCanceled(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		commands []string
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	orig := runSSH
+	t.Cleanup(func() { runSSH = orig })
+	runSSH = func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, args ...string) (int, error) {
+		command := args[len(args)-1]
+		mu.Lock()
+		commands = append(commands, command)
+		mu.Unlock()
+		switch {
+		case command == uploadCommand:
+			_, _ = io.WriteString(stdout, "/tmp/flwd-step.abc\n")
+		case strings.Contains(command, "exec "):
+			cancel()
+			<-ctx.Done()
+			return -1, ctx.Err()
+		}
+		return 0, nil
+	}
+
+	_, err := Target{Host: "build-01"}.Run(ctx, StepOptions{
+		Script:      "sleep 60",
+		Interpreter: []string{"bash"},
+		Signal:      "SIGINT",
+		GracePeriod: 5 * time.Second,
+	}, io.Discard, io.Discard)
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(commands) != 3 {
+		t.Fatalf("expected upload, run and stop commands, got %q", commands)
+	}
+	if run := commands[1]; !strings.HasPrefix(run, "cd /tmp/flwd-step.abc && . ./env") || !strings.HasSuffix(run, "exec bash /tmp/flwd-step.abc/step") {
+		t.Fatalf("unexpected run command %q", run)
+	}
+	stop := commands[2]
+	if !strings.Contains(stop, "kill -s INT") || !strings.Contains(stop, "-lt 5 ") || !strings.HasSuffix(stop, "rm -rf /tmp/flwd-step.abc") {
+		t.Fatalf("unexpected stop command %q", stop)
+	}
+}
@@ -0,0 +1,117 @@
+//go:build unix
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/events"
+)
+
+// fakeSSH puts an ssh client on PATH that records its arguments and runs the
+// remote command locally, so the host running the test stands in for the
+// remote host.
+func fakeSSH(t *testing.T) (argsFile string) {
+	t.Helper()
+	bin := t.TempDir()
+	argsFile = filepath.Join(bin, "args")
+	client := "#!/bin/sh\necho \"$@\" >> " + argsFile + "\nfor last; do :; done\nexec sh -c \"$last\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "ssh"), []byte(client), 0o755); err != nil {
+		t.Fatalf("write fake ssh: %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return argsFile
+}
+
+// stepLogRecorder keeps the step.log events of a run.
+type stepLogRecorder struct {
+	stepEventRecorder
+	logMu sync.Mutex
+	logs  []string
+}
+
+func (r *stepLogRecorder) EmitStepLog(runID, step, channel, message string) {
+	r.logMu.Lock()
+	defer r.logMu.Unlock()
+	r.logs = append(r.logs, channel+":"+message)
+}
+
+func (r *stepLogRecorder) snapshot() []string {
+	r.logMu.Lock()
+	defer r.logMu.Unlock()
+	return slices.Clone(r.logs)
+}
+
+func TestRunScriptsSSHExecutor(t *testing.T) {
+	argsFile := fakeSSH(t)
+	remoteTmp := t.TempDir()
+	t.Setenv("TMPDIR", remoteTmp)
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "config.d"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	config := `version: v1
+job:
+  id: remote
+  name: Remote
+executor: ssh
+interpreter: sh
+env:
+  GREETING: "it's hello"
+ssh:
+  host: build-01.example.org
+  port: 2222
+  user: deploy
+`
+	if err := os.WriteFile(filepath.Join(dir, "config.d", "config.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	script := "echo \"$GREETING from $FLWD_RUN_DIR\"\necho \"token hunter2\"\necho oops >&2\nexit 4"
+	if err := os.WriteFile(filepath.Join(dir, "100_remote.sh"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	sink := &stepLogRecorder{}
+	results, err := RunScripts(context.Background(), dir, ExecutorConfig{
+		RunDir:       t.TempDir(),
+		Emitter:      sink,
+		StdoutWriter: &stdout,
+		StderrWriter: &stderr,
+		LineRedactor: events.NewLineRedactor([]string{"hunter2"}),
+	})
+	if err != nil {
+		t.Fatalf("run scripts: %v", err)
+	}
+	if len(results) != 1 || results[0].ExitCode != 4 || results[0].Err == nil {
+		t.Fatalf("expected the remote exit code, got %+v", results)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "it's hello from "+remoteTmp+"/flwd-step.") {
+		t.Fatalf("unexpected stdout %q", stdout.String())
+	}
+	if logs := sink.snapshot(); !slices.Contains(logs, "stdout:token [secret]") || !slices.Contains(logs, "stderr:oops") {
+		t.Fatalf("expected redacted step.log events on both channels, got %q", logs)
+	}
+	if strings.TrimSpace(stderr.String()) != "oops" {
+		t.Fatalf("expected stderr kept apart, got %q", stderr.String())
+	}
+	if left, _ := os.ReadDir(remoteTmp); len(left) != 0 {
+		t.Fatalf("expected the scratch directory to be removed, found %v", left)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("read ssh args: %v", err)
+	}
+	if !strings.Contains(string(args), "-p 2222 -l deploy -- build-01.example.org") || !strings.Contains(string(args), "BatchMode=yes") {
+		t.Fatalf("unexpected ssh arguments %q", args)
+	}
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Step working directory bases.
+const (
+	WorkdirRun      = "run"
+	WorkdirCheckout = "checkout"
+)
+
+// StepWorkdir is a step's working directory relative to the run directory or
+// the job's checkout, written as "build", "run:build" or "checkout:src".
+type StepWorkdir struct {
+	Base string
+	Path string
+}
+
+// ParseStepWorkdir validates a step workdir. Paths must be relative and stay
+// inside their base.
+func ParseStepWorkdir(value string) (StepWorkdir, error) {
+	value = strings.TrimSpace(value)
+	w := StepWorkdir{Base: WorkdirRun, Path: value}
+	if base, rest, ok := strings.Cut(value, ":"); ok {
+		switch base {
+		case WorkdirRun, WorkdirCheckout:
+			w.Base, w.Path = base, strings.TrimSpace(rest)
+		default:
+			return StepWorkdir{}, fmt.Errorf("workdir %q: base must be run or checkout", value)
+		}
+	}
+	if w.Path == "" {
+		w.Path = "."
+	}
+	if filepath.IsAbs(w.Path) || strings.HasPrefix(w.Path, "/") {
+		return StepWorkdir{}, fmt.Errorf("workdir %q must be relative", value)
+	}
+	w.Path = filepath.ToSlash(filepath.Clean(w.Path))
+	if w.Path == ".." || strings.HasPrefix(w.Path, "../") {
+		return StepWorkdir{}, fmt.Errorf("workdir %q escapes the %s directory", value, w.Base)
+	}
+	return w, nil
+}
+
+// String returns the normalized form shown in plan previews.
+func (w StepWorkdir) String() string {
+	return w.Base + ":" + w.Path
+}
+
+// Resolve returns the absolute directory for a run, creating it under the run
+// directory when missing. Checkout directories must already exist.
+func (w StepWorkdir) Resolve(runDir, jobDir string) (string, error) {
+	base := runDir
+	if w.Base == WorkdirCheckout {
+		base = jobDir
+	}
+	dir, err := filepath.Abs(filepath.Join(base, filepath.FromSlash(w.Path)))
+	if err != nil {
+		return "", err
+	}
+	if w.Base == WorkdirRun {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", fmt.Errorf("create workdir %s: %w", w, err)
+		}
+		return dir, nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("workdir %s: %w", w, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("workdir %s is not a directory", w)
+	}
+	return dir, nil
+}
+
+// supportedShells lists the shells a step may select with shell:.
+var supportedShells = map[string]bool{"bash": true, "sh": true, "pwsh": true}
+
+// ParseStepShell validates a step shell such as "bash -euo pipefail" and
+// returns it normalized to single spaces.
+func ParseStepShell(value string) (string, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("shell is empty")
+	}
+	if !supportedShells[fields[0]] {
+		return "", fmt.Errorf("shell %q: must be bash, sh or pwsh", fields[0])
+	}
+	for _, flag := range fields[1:] {
+		if !strings.HasPrefix(flag, "-") && !isShellOptionName(flag) {
+			return "", fmt.Errorf("shell %q: %q is not a flag", value, flag)
+		}
+	}
+	return strings.Join(fields, " "), nil
+}
+
+// isShellOptionName accepts the argument of "-o" style options, e.g. the
+// "pipefail" in "bash -o pipefail".
+func isShellOptionName(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && r != '_' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseStepWorkdir(t *testing.T) {
+	for in, want := range map[string]string{
+		"build":          "run:build",
+		"run:out/../bin": "run:bin",
+		"checkout:src":   "checkout:src",
+		"checkout:":      "checkout:.",
+	} {
+		got, err := ParseStepWorkdir(in)
+		if err != nil || got.String() != want {
+			t.Fatalf("ParseStepWorkdir(%q) = %v, %v; want %s", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"/tmp", "../up", "run:a/../../b", "home:src"} {
+		if _, err := ParseStepWorkdir(in); err == nil {
+			t.Fatalf("expected ParseStepWorkdir(%q) to fail", in)
+		}
+	}
+}
+
+func TestParseStepShell(t *testing.T) {
+	if got, err := ParseStepShell("  bash  -euo pipefail "); err != nil || got != "bash -euo pipefail" {
+		t.Fatalf("unexpected shell %q, %v", got, err)
+	}
+	for _, in := range []string{"", "zsh", "bash ./evil.sh", "sh -c 'rm -rf /'"} {
+		if _, err := ParseStepShell(in); err == nil {
+			t.Fatalf("expected ParseStepShell(%q) to fail", in)
+		}
+	}
+}
+
+func TestRunDAGStepsAppliesWorkdirAndShell(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"config.d", "scripts", "src"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", sub, err)
+		}
+	}
+	config := `version: v1
+job:
+  id: dag
+  name: DAG
+composition: steps
+executor: proc
+interpreter: bash
+steps:
+  - id: build
+    script: scripts/pwd.sh
+    workdir: out
+  - id: lint
+    script: scripts/pwd.sh
+    workdir: checkout:src
+    shell: sh -e
+`
+	if err := os.WriteFile(filepath.Join(dir, "config.d", "config.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	script := "echo \"dir=$(pwd) bash=${BASH_VERSION:+yes}\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "scripts", "pwd.sh"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	runDir := t.TempDir()
+	var stdout bytes.Buffer
+	if _, err := RunScripts(context.Background(), dir, ExecutorConfig{
+		Strict:       true,
+		RunDir:       runDir,
+		StdoutWriter: &stdout,
+		StderrWriter: os.Stderr,
+	}); err != nil {
+		t.Fatalf("RunScripts: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected two lines of output, got %q", stdout.String())
+	}
+	outDir, _ := filepath.EvalSymlinks(filepath.Join(runDir, "out"))
+	srcDir, _ := filepath.EvalSymlinks(filepath.Join(dir, "src"))
+	if want := "dir=" + outDir + " bash=yes"; lines[0] != want {
+		t.Fatalf("build step: got %q, want %q", lines[0], want)
+	}
+	if want := "dir=" + srcDir + " bash="; lines[1] != want {
+		t.Fatalf("lint step: got %q, want %q", lines[1], want)
+	}
+}
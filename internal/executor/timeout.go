@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutError reports a run, or the DAG step named by Step, that was stopped
+// for exceeding its configured timeout. Its steps are stopped the same way as
+// on cancel.
+type TimeoutError struct {
+	Step  string
+	Limit time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Step == "" {
+		return fmt.Sprintf("run timed out after %s", e.Limit)
+	}
+	return fmt.Sprintf("step %s timed out after %s", e.Step, e.Limit)
+}
+
+// withTimeout bounds ctx by limit, a duration the config loader has already
+// validated. An empty limit leaves ctx unbounded.
+func withTimeout(ctx context.Context, limit, step string) (context.Context, context.CancelFunc) {
+	d, err := time.ParseDuration(limit)
+	if err != nil || d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, d, &TimeoutError{Step: step, Limit: d})
+}
+
+// timeoutCause returns the TimeoutError ctx ended with, or nil when it is
+// still live or ended for another reason.
+func timeoutCause(ctx context.Context) error {
+	var timeout *TimeoutError
+	if ctx.Err() != nil && errors.As(context.Cause(ctx), &timeout) {
+		return timeout
+	}
+	return nil
+}
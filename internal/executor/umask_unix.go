@@ -2,9 +2,31 @@
 
 package executor
 
-import "syscall"
+import (
+	"sync"
+	"syscall"
+)
+
+// The umask is process-wide, so parallel DAG steps share one secure umask
+// and the last step to finish restores the original.
+var (
+	umaskMu    sync.Mutex
+	umaskUsers int
+	umaskSaved int
+)
 
 func applySecureUmask() func() {
-	old := syscall.Umask(0o077)
-	return func() { syscall.Umask(old) }
+	umaskMu.Lock()
+	defer umaskMu.Unlock()
+	if umaskUsers == 0 {
+		umaskSaved = syscall.Umask(0o077)
+	}
+	umaskUsers++
+	return func() {
+		umaskMu.Lock()
+		defer umaskMu.Unlock()
+		if umaskUsers--; umaskUsers == 0 {
+			syscall.Umask(umaskSaved)
+		}
+	}
 }
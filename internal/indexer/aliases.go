@@ -100,6 +100,10 @@ func BuildAliasIndex(jobs []JobInfo, aliasSets []AliasSet) (AliasIndex, []Discov
 			}
 
 			job, ok := jobIDs[strings.ToLower(targetID)]
+			if !ok {
+				// Add-on jobs keep the "<source>/<job>" form as their ID.
+				job, ok = jobIDs[strings.ToLower(targetPath)]
+			}
 			if !ok {
 				lower := strings.ToLower(aliasName)
 				invalid[lower] = AliasValidation{Code: "alias.target.invalid", Detail: fmt.Sprintf("alias %q target %q not found", aliasName, from)}
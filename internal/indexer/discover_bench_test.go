@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package indexer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// BenchmarkDiscover5kJobs guards the discovery budget for large script trees:
+// a 5 000-job tree must be indexed in under 100ms on average. The budget is
+// only enforced with at least minBudgetCPUs available, since the walk is
+// syscall bound and relies on parallelism to meet it.
+func BenchmarkDiscover5kJobs(b *testing.B) {
+	const (
+		jobCount = 5000
+		budget   = 100 * time.Millisecond
+
+		minBudgetCPUs = 4
+	)
+	root := b.TempDir()
+	for i := 0; i < jobCount; i++ {
+		cfgDir := filepath.Join(root, fmt.Sprintf("group%02d", i%50), fmt.Sprintf("job%04d", i), "config.d")
+		if err := os.MkdirAll(cfgDir, 0o755); err != nil {
+			b.Fatal(err)
+		}
+		cfg := fmt.Sprintf("job:\n  id: job%04d\n  name: Job %d\n  summary: generated\n", i, i)
+		if err := os.WriteFile(filepath.Join(cfgDir, "config.yaml"), []byte(cfg), 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		res, err := Discover(root)
+		if err != nil {
+			b.Fatalf("Discover: %v", err)
+		}
+		if len(res.Jobs) != jobCount {
+			b.Fatalf("expected %d jobs, got %d", jobCount, len(res.Jobs))
+		}
+	}
+	b.StopTimer()
+	avg := time.Since(start) / time.Duration(b.N)
+	b.ReportMetric(float64(avg.Microseconds())/1000, "ms/discover")
+	if avg <= budget {
+		return
+	}
+	if runtime.GOMAXPROCS(0) < minBudgetCPUs {
+		b.Logf("average discovery %s exceeds %s budget; not enforced with GOMAXPROCS < %d", avg, budget, minBudgetCPUs)
+		return
+	}
+	b.Fatalf("average discovery %s exceeds %s budget", avg, budget)
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 
 	"github.com/flowd-org/flowd/internal/configloader"
@@ -40,7 +39,9 @@ type Result struct {
 }
 
 // Discover scans root (typically "scripts") for config.d/config.yaml files
-// and returns job metadata according to the Runner specification.
+// and returns job metadata according to the Runner specification. Directories
+// are walked and configs parsed by a bounded worker pool; paths matching the
+// root's .flwdignore file are skipped.
 func Discover(root string) (Result, error) {
 	var res Result
 
@@ -55,30 +56,21 @@ func Discover(root string) (Result, error) {
 		return res, fmt.Errorf("root %s is not a directory", root)
 	}
 
-	var cfgPaths []string
-	walkErr := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if !d.IsDir() && strings.EqualFold(d.Name(), "config.yaml") {
-			if filepath.Base(filepath.Dir(path)) == "config.d" {
-				cfgPaths = append(cfgPaths, path)
-			}
-		}
-		return nil
-	})
+	ignore, err := loadIgnoreFile(root)
+	if err != nil {
+		return res, err
+	}
+	found, walkErr := walkConfigs(root, ignore, discoverWorkers())
 	if walkErr != nil {
 		return res, fmt.Errorf("walk root: %w", walkErr)
 	}
 
-	sort.Strings(cfgPaths)
-	for _, cfgPath := range cfgPaths {
-		jobs, err := parseConfig(root, cfgPath)
-		if err != nil {
-			res.Errors = append(res.Errors, DiscoveryError{Path: cfgPath, Err: err.Error()})
+	for _, cfg := range found {
+		if cfg.err != nil {
+			res.Errors = append(res.Errors, DiscoveryError{Path: cfg.path, Err: cfg.err.Error()})
 			continue
 		}
-		res.Jobs = append(res.Jobs, jobs...)
+		res.Jobs = append(res.Jobs, cfg.jobs...)
 	}
 
 	aliases, err := configloader.LoadAliases(root)
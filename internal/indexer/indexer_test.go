@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/flowd-org/flowd/internal/types"
 )
 
 func TestDiscoverJobMetadata(t *testing.T) {
@@ -134,6 +136,18 @@ func TestDiscoverInvalidAliasTarget(t *testing.T) {
 	}
 }
 
+func TestBuildAliasIndexResolvesAddonJobIDs(t *testing.T) {
+	jobs := []JobInfo{{ID: "addon/example.job", Name: "Example"}}
+	sets := []AliasSet{{Source: "addon", Aliases: []types.CommandAlias{{From: "addon/example.job", To: "lint"}}}}
+	index, errs := BuildAliasIndex(jobs, sets)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(index.Entries) != 1 || index.Entries[0].TargetID != "addon/example.job" || index.Entries[0].Source != "addon" {
+		t.Fatalf("expected lint to resolve to the add-on job, got %+v", index.Entries)
+	}
+}
+
 func TestDiscoverInvalidYaml(t *testing.T) {
 	root := t.TempDir()
 	jobDir := filepath.Join(root, "demo")
@@ -156,3 +170,104 @@ func TestDiscoverInvalidYaml(t *testing.T) {
 		t.Fatalf("expected 0 jobs, got %d", len(res.Jobs))
 	}
 }
+
+func TestWatcherDetectsConfigChange(t *testing.T) {
+	root := t.TempDir()
+	cfgDir := filepath.Join(root, "demo", "config.d")
+	if err := os.MkdirAll(cfgDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(cfgDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte("job:\n  id: demo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := NewWatcher(root, 0)
+	notified := 0
+	w.OnChange(func() { notified++ })
+
+	if changed, err := w.Check(); err != nil || changed {
+		t.Fatalf("baseline check: changed=%v err=%v", changed, err)
+	}
+	if err := os.WriteFile(cfgPath, []byte("job:\n  id: demo\n  name: Renamed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := w.Check()
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if !changed || notified != 1 {
+		t.Fatalf("expected change notification, changed=%v notified=%d", changed, notified)
+	}
+	if changed, _ := w.Check(); changed || notified != 1 {
+		t.Fatalf("expected no further notifications, changed=%v notified=%d", changed, notified)
+	}
+}
+
+func TestDiscoverHonorsIgnoreFile(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"keep", "vendor/lib", "build/out", ".git/hooks"} {
+		cfgDir := filepath.Join(root, dir, "config.d")
+		if err := os.MkdirAll(cfgDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		cfg := "job:\n  id: " + filepath.Base(dir) + "\n"
+		if err := os.WriteFile(filepath.Join(cfgDir, "config.yaml"), []byte(cfg), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ignore := "# generated trees\nvendor/\nbuild/out\n"
+	if err := os.WriteFile(filepath.Join(root, IgnoreFileName), []byte(ignore), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Discover(root)
+	if err != nil {
+		t.Fatalf("Discover error: %v", err)
+	}
+	if len(res.Jobs) != 1 || res.Jobs[0].ID != "keep" {
+		t.Fatalf("expected only keep job, got %+v", res.Jobs)
+	}
+}
+
+func TestTreeWatcherDetectsScriptChange(t *testing.T) {
+	root := t.TempDir()
+	jobDir := filepath.Join(root, "demo")
+	if err := os.MkdirAll(filepath.Join(jobDir, "config.d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(jobDir, "config.d", "config.yaml"), []byte("job:\n  id: demo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(jobDir, "100_main.sh")
+	if err := os.WriteFile(script, []byte("echo one\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	configOnly := NewWatcher(root, 0)
+	tree := NewTreeWatcher(root, 0)
+	for _, w := range []*Watcher{configOnly, tree} {
+		if _, err := w.Check(); err != nil {
+			t.Fatalf("baseline check: %v", err)
+		}
+	}
+	if err := os.WriteFile(script, []byte("echo two, longer\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if changed, _ := configOnly.Check(); changed {
+		t.Fatalf("config watcher should ignore script edits")
+	}
+	if changed, err := tree.Check(); err != nil || !changed {
+		t.Fatalf("tree watcher missed script edit: changed=%v err=%v", changed, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".git", "index"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, _ := tree.Check(); changed {
+		t.Fatalf("tree watcher should skip hidden directories")
+	}
+}
@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package indexer
+
+import (
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/sortspec"
+)
+
+// JobSortFields are the job fields accepted by CompareJobs.
+var JobSortFields = []string{"id", "name"}
+
+// CompareJobs orders a and b by keys, returning 0 when every key ties.
+// Names compare case-insensitively.
+func CompareJobs(a, b JobInfo, keys []sortspec.Key) int {
+	for _, key := range keys {
+		var c int
+		switch key.Field {
+		case "id":
+			c = strings.Compare(a.ID, b.ID)
+		case "name":
+			c = strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		}
+		if c != 0 {
+			return key.Apply(c)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package indexer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// IgnoreFileName is the per-root file listing directories Discover skips.
+const IgnoreFileName = ".flwdignore"
+
+// defaultIgnored directories never contain job configs worth indexing.
+var defaultIgnored = []string{".git"}
+
+func discoverWorkers() int {
+	n := runtime.GOMAXPROCS(0) * 2
+	if n < 4 {
+		n = 4
+	}
+	return n
+}
+
+// ignoreMatcher implements a small subset of gitignore semantics: blank lines
+// and lines starting with '#' are skipped, a trailing '/' is accepted (only
+// directories are ever matched), patterns containing '/' are matched against
+// the slash-separated path relative to the root, and all other patterns are
+// matched against the directory base name. Globs follow path.Match.
+type ignoreMatcher struct {
+	patterns []string
+}
+
+func loadIgnoreFile(root string) (*ignoreMatcher, error) {
+	m := &ignoreMatcher{patterns: append([]string{}, defaultIgnored...)}
+	f, err := os.Open(filepath.Join(root, IgnoreFileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return m, nil
+		}
+		return nil, fmt.Errorf("open %s: %w", IgnoreFileName, err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.Trim(line, "/")
+		if line == "" {
+			continue
+		}
+		if _, err := path.Match(line, ""); err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %q: %w", IgnoreFileName, line, err)
+		}
+		m.patterns = append(m.patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", IgnoreFileName, err)
+	}
+	return m, nil
+}
+
+func (m *ignoreMatcher) match(rel string) bool {
+	if m == nil {
+		return false
+	}
+	base := path.Base(rel)
+	for _, pattern := range m.patterns {
+		target := base
+		if strings.Contains(pattern, "/") {
+			target = rel
+		}
+		if ok, _ := path.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type discoveredConfig struct {
+	path string
+	jobs []JobInfo
+	err  error
+}
+
+// configWalker traverses a tree concurrently. Each directory is read by a pool
+// worker when one is free and inline otherwise, so the walk never blocks on a
+// saturated pool.
+type configWalker struct {
+	root   string
+	ignore *ignoreMatcher
+	sem    chan struct{}
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	found   []discoveredConfig
+	walkErr error
+}
+
+func walkConfigs(root string, ignore *ignoreMatcher, workers int) ([]discoveredConfig, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	w := &configWalker{
+		root:   root,
+		ignore: ignore,
+		sem:    make(chan struct{}, workers),
+	}
+	w.wg.Add(1)
+	w.visit(root)
+	w.wg.Wait()
+	if w.walkErr != nil {
+		return nil, w.walkErr
+	}
+	sort.Slice(w.found, func(i, j int) bool { return w.found[i].path < w.found[j].path })
+	return w.found, nil
+}
+
+func (w *configWalker) visit(dir string) {
+	defer w.wg.Done()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		w.fail(err)
+		return
+	}
+	isConfigDir := filepath.Base(dir) == "config.d"
+	for _, entry := range entries {
+		full := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() {
+			if isConfigDir && strings.EqualFold(entry.Name(), "config.yaml") {
+				jobs, parseErr := parseConfig(w.root, full)
+				w.record(discoveredConfig{path: full, jobs: jobs, err: parseErr})
+			}
+			continue
+		}
+		rel, relErr := filepath.Rel(w.root, full)
+		if relErr == nil && w.ignore.match(filepath.ToSlash(rel)) {
+			continue
+		}
+		w.wg.Add(1)
+		select {
+		case w.sem <- struct{}{}:
+			go func(p string) {
+				defer func() { <-w.sem }()
+				w.visit(p)
+			}(full)
+		default:
+			w.visit(full)
+		}
+	}
+}
+
+func (w *configWalker) record(cfg discoveredConfig) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.found = append(w.found, cfg)
+}
+
+func (w *configWalker) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.walkErr == nil {
+		w.walkErr = err
+	}
+}
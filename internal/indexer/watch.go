@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package indexer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultWatchInterval = 2 * time.Second
+
+// Watcher polls a scripts root and notifies subscribers whenever a job
+// configuration (config.d/config.yaml) or the root alias file (flwd.yaml)
+// changes. Polling keeps the implementation portable and dependency free.
+type Watcher struct {
+	root     string
+	interval time.Duration
+	tree     bool
+
+	mu       sync.Mutex
+	last     string
+	handlers []func()
+}
+
+// NewWatcher returns a watcher for root. A non-positive interval selects the default.
+func NewWatcher(root string, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	return &Watcher{root: root, interval: interval}
+}
+
+// NewTreeWatcher returns a watcher that also reacts to script edits: every
+// file beneath root except hidden files and directories is fingerprinted.
+func NewTreeWatcher(root string, interval time.Duration) *Watcher {
+	w := NewWatcher(root, interval)
+	w.tree = true
+	return w
+}
+
+// OnChange registers fn to be invoked after a change is detected.
+func (w *Watcher) OnChange(fn func()) {
+	if w == nil || fn == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, fn)
+}
+
+// Run polls until ctx is canceled. The initial fingerprint is recorded without
+// notifying subscribers.
+func (w *Watcher) Run(ctx context.Context) {
+	if w == nil {
+		return
+	}
+	_, _ = w.Check()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = w.Check()
+		}
+	}
+}
+
+// Check recomputes the fingerprint and notifies subscribers when it differs
+// from the previous observation. The first call only records the baseline.
+func (w *Watcher) Check() (bool, error) {
+	fingerprint := Fingerprint
+	if w.tree {
+		fingerprint = FingerprintTree
+	}
+	fp, err := fingerprint(w.root)
+	if err != nil {
+		return false, err
+	}
+	w.mu.Lock()
+	first := w.last == ""
+	changed := !first && fp != w.last
+	w.last = fp
+	handlers := append([]func(){}, w.handlers...)
+	w.mu.Unlock()
+	if changed {
+		for _, fn := range handlers {
+			fn()
+		}
+	}
+	return changed, nil
+}
+
+// Fingerprint summarizes the path, size and modification time of every job
+// configuration beneath root. A missing root yields a stable empty fingerprint.
+func Fingerprint(root string) (string, error) {
+	return fingerprint(root, false, func(path, name string) bool {
+		isConfig := strings.EqualFold(name, "config.yaml") && filepath.Base(filepath.Dir(path)) == "config.d"
+		isAliases := strings.EqualFold(name, "flwd.yaml") && filepath.Dir(path) == filepath.Clean(root)
+		return isConfig || isAliases
+	})
+}
+
+// FingerprintTree is like Fingerprint but covers every file beneath root,
+// skipping hidden files and directories such as .git.
+func FingerprintTree(root string) (string, error) {
+	return fingerprint(root, true, func(_, name string) bool {
+		return !strings.HasPrefix(name, ".")
+	})
+}
+
+func fingerprint(root string, skipHidden bool, include func(path, name string) bool) (string, error) {
+	var entries []string
+	info, err := os.Stat(root)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "empty", nil
+		}
+		return "", fmt.Errorf("stat root: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("root %s is not a directory", root)
+	}
+	walkErr := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if skipHidden && path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !include(path, d.Name()) {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, fmt.Sprintf("%s|%d|%d", path, fi.Size(), fi.ModTime().UnixNano()))
+		return nil
+	})
+	if walkErr != nil {
+		return "", fmt.Errorf("walk root: %w", walkErr)
+	}
+	sort.Strings(entries)
+	sum := sha256.Sum256([]byte(strings.Join(entries, "\n")))
+	return hex.EncodeToString(sum[:]), nil
+}
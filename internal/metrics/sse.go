@@ -28,3 +28,13 @@ func RecordSSEResumeAttempt() {
 func RecordSSECursorExpired() {
 	servermetrics.Default.RecordSSECursorExpired()
 }
+
+// RecordSSEStreamDelta adjusts the open stream gauges of a run and a principal.
+func RecordSSEStreamDelta(runID, principal string, delta int64) {
+	servermetrics.Default.RecordSSEStreamDelta(runID, principal, delta)
+}
+
+// RecordSSEStreamRejected counts a stream refused by limit (principal|run).
+func RecordSSEStreamRejected(limit string) {
+	servermetrics.Default.RecordSSEStreamRejected(limit)
+}
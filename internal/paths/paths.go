@@ -105,6 +105,11 @@ func SourcesDir() string {
 	return DataPath("sources")
 }
 
+// SecretsDir returns the directory file secret references resolve against.
+func SecretsDir() string {
+	return DataPath("secrets")
+}
+
 // OCICacheDir returns the directory for cached OCI artifacts.
 func OCICacheDir() string {
 	return DataPath("oci")
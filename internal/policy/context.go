@@ -2,10 +2,16 @@
 package policy
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"math"
+	"path"
 	"strconv"
 	"strings"
+
+	"github.com/flowd-org/flowd/internal/policy/verify"
 )
 
 // VerifyMode represents the policy mode for container image signature verification.
@@ -17,10 +23,13 @@ const (
 	VerifyModeDisabled   VerifyMode = "disabled"
 )
 
+const defaultPolicyVersion = "none"
+
 // Context encapsulates the loaded policy bundle and derived helpers used by the server.
 type Context struct {
 	bundle          *Bundle
 	containerLimits *ContainerLimits
+	version         string
 }
 
 // ContainerLimits captures parsed resource ceilings derived from the bundle.
@@ -31,11 +40,15 @@ type ContainerLimits struct {
 
 // NewContext wraps the supplied bundle. A nil bundle is valid and produces defaults.
 func NewContext(bundle *Bundle) (*Context, error) {
-	ctx := &Context{}
+	ctx := &Context{version: defaultPolicyVersion}
 	if bundle == nil {
 		return ctx, nil
 	}
 	ctx.bundle = bundle
+	if data, err := json.Marshal(bundle); err == nil {
+		sum := sha256.Sum256(data)
+		ctx.version = "sha256:" + hex.EncodeToString(sum[:])
+	}
 
 	if bundle.Ceilings != nil {
 		limits, err := parseContainerCeilings(bundle.Ceilings)
@@ -47,6 +60,15 @@ func NewContext(bundle *Bundle) (*Context, error) {
 	return ctx, nil
 }
 
+// Version identifies the loaded bundle contents. Contexts without a bundle
+// report "none"; otherwise the value is a digest of the canonical bundle.
+func (c *Context) Version() string {
+	if c == nil || c.version == "" {
+		return defaultPolicyVersion
+	}
+	return c.version
+}
+
 // Bundle returns the backing bundle (may be nil).
 func (c *Context) Bundle() *Bundle {
 	if c == nil {
@@ -83,6 +105,20 @@ func (c *Context) VerifyModeForProfile(profile string) (VerifyMode, error) {
 	}
 }
 
+// NewImageVerifier builds the image verifier the bundle selects, cosign when
+// it names none.
+func (c *Context) NewImageVerifier() (verify.ImageVerifier, error) {
+	var (
+		name string
+		opts verify.Options
+	)
+	if c != nil && c.bundle != nil {
+		name = c.bundle.ImageVerifier
+		opts.PinnedDigests = c.bundle.PinnedDigests
+	}
+	return verify.New(name, opts)
+}
+
 // AllowedRegistries returns the allow-list of registries declared in the bundle.
 func (c *Context) AllowedRegistries() []string {
 	if c == nil || c.bundle == nil {
@@ -91,6 +127,49 @@ func (c *Context) AllowedRegistries() []string {
 	return c.bundle.AllowedRegistries
 }
 
+// ScriptAllowed reports whether a script digest may run under the secure
+// profile. It always returns true when the bundle registers no hashes.
+func (c *Context) ScriptAllowed(sha256Hex string) bool {
+	if c == nil || c.bundle == nil || len(c.bundle.ScriptHashes) == 0 {
+		return true
+	}
+	for _, sum := range c.bundle.ScriptHashes {
+		if sum == lower(sha256Hex) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnvAllowed reports whether the bundle's env rules let a variable through to
+// steps run under the secure profile. Without rules every name is allowed.
+func (c *Context) EnvAllowed(name string) bool {
+	if c == nil || c.bundle == nil || c.bundle.Env == nil {
+		return true
+	}
+	return c.bundle.Env.Allows(name)
+}
+
+// Allows applies the rules to name.
+func (r *EnvRules) Allows(name string) bool {
+	if r == nil {
+		return true
+	}
+	if len(r.Allow) > 0 && !matchAny(r.Allow, name) {
+		return false
+	}
+	return !matchAny(r.Deny, name)
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // Ceilings returns the resource ceilings declared in the bundle (may be nil).
 func (c *Context) Ceilings() *Ceilings {
 	if c == nil || c.bundle == nil {
@@ -5,8 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/flowd-org/flowd/internal/policy/verify"
 	yaml "gopkg.in/yaml.v3"
 )
 
@@ -56,9 +60,43 @@ func validate(b *Bundle) error {
 			return fmt.Errorf("invalid verify_signatures: %q", *b.VerifySignatures)
 		}
 	}
+	if b.ImageVerifier != "" {
+		b.ImageVerifier = lower(strings.TrimSpace(b.ImageVerifier))
+		if !slices.Contains(verify.Backends(), b.ImageVerifier) {
+			return fmt.Errorf("invalid image_verifier: %q", b.ImageVerifier)
+		}
+	}
+	for repo, digests := range b.PinnedDigests {
+		for _, d := range digests {
+			if hex, ok := strings.CutPrefix(lower(d), "sha256:"); !ok || len(hex) != 64 {
+				return fmt.Errorf("invalid pinned_digests entry for %s: %q", repo, d)
+			}
+		}
+	}
+	if b.ImageVerifier == verify.BackendDigest && len(b.PinnedDigests) == 0 {
+		return fmt.Errorf("image_verifier digest requires pinned_digests")
+	}
 	// Normalize allowed registries to lowercase hosts (keep order).
 	for i := range b.AllowedRegistries {
 		b.AllowedRegistries[i] = lower(b.AllowedRegistries[i])
 	}
+	for i, sum := range b.ScriptHashes {
+		sum = lower(strings.TrimPrefix(sum, "sha256:"))
+		if len(sum) != 64 {
+			return fmt.Errorf("invalid script_hashes entry: %q", b.ScriptHashes[i])
+		}
+		b.ScriptHashes[i] = sum
+	}
+	if b.Env != nil {
+		for _, list := range [][]string{b.Env.Allow, b.Env.Deny} {
+			for i, pattern := range list {
+				pattern = strings.TrimSpace(pattern)
+				if _, err := path.Match(pattern, ""); pattern == "" || err != nil {
+					return fmt.Errorf("invalid env pattern: %q", list[i])
+				}
+				list[i] = pattern
+			}
+		}
+	}
 	return nil
 }
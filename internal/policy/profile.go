@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package policy
+
+import "strings"
+
+// profileStrictness ranks security profiles from least to most restrictive.
+var profileStrictness = map[string]int{
+	"disabled":   0,
+	"permissive": 1,
+	"secure":     2,
+}
+
+// NormalizeProfile lower-cases and validates a security profile name.
+func NormalizeProfile(value string) (string, bool) {
+	prof := strings.ToLower(strings.TrimSpace(value))
+	if _, ok := profileStrictness[prof]; !ok {
+		return "", false
+	}
+	return prof, true
+}
+
+// StrictestProfile returns the most restrictive of the supplied (normalized)
+// profiles. Empty values are ignored; when none are set the result is empty.
+func StrictestProfile(profiles ...string) string {
+	best := ""
+	for _, p := range profiles {
+		p = strings.ToLower(strings.TrimSpace(p))
+		rank, ok := profileStrictness[p]
+		if !ok {
+			continue
+		}
+		if best == "" || rank > profileStrictness[best] {
+			best = p
+		}
+	}
+	return best
+}
@@ -8,6 +8,25 @@ type Bundle struct {
 	AllowedRegistries []string   `yaml:"allowed_registries,omitempty" json:"allowed_registries,omitempty"`
 	Ceilings          *Ceilings  `yaml:"ceilings,omitempty" json:"ceilings,omitempty"`
 	Overrides         *Overrides `yaml:"overrides,omitempty" json:"overrides,omitempty"`
+	// ScriptHashes pre-registers the sha256 digests of local scripts allowed
+	// to run under the secure profile. Empty disables the check.
+	ScriptHashes []string `yaml:"script_hashes,omitempty" json:"script_hashes,omitempty"`
+	// Env restricts the environment variables passed to steps under the
+	// secure profile.
+	Env *EnvRules `yaml:"env,omitempty" json:"env,omitempty"`
+	// ImageVerifier selects the backend that verifies container images
+	// (cosign, notation or digest). Empty uses cosign.
+	ImageVerifier string `yaml:"image_verifier,omitempty" json:"image_verifier,omitempty"`
+	// PinnedDigests maps image repositories to the digests the digest
+	// verifier accepts.
+	PinnedDigests map[string][]string `yaml:"pinned_digests,omitempty" json:"pinned_digests,omitempty"`
+}
+
+// EnvRules lists glob patterns (e.g. "AWS_*") of environment variable names.
+// A name passes when Allow is empty or matches it, and Deny does not.
+type EnvRules struct {
+	Allow []string `yaml:"allow,omitempty" json:"allow,omitempty"`
+	Deny  []string `yaml:"deny,omitempty" json:"deny,omitempty"`
 }
 
 // Ceilings captures container resource ceilings (Phase 3 scope).
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// BlobOptions selects how cosign establishes trust in a blob signature. Key
+// verifies against a public key; otherwise Certificate (or the keyless
+// certificate embedded in a bundle) must match the identity and issuer.
+type BlobOptions struct {
+	Key                   string
+	Certificate           string
+	CertificateIdentity   string
+	CertificateOIDCIssuer string
+
+	// Bundle is a Rekor/Fulcio bundle from `cosign sign-blob --bundle`; it
+	// may stand in for the detached signature. Offline stops cosign from
+	// contacting the transparency log so checks work in air-gapped hosts.
+	Bundle  string
+	Offline bool
+}
+
+// CosignBlobVerifier invokes `cosign verify-blob` for detached signatures
+// over local files such as run receipts.
+type CosignBlobVerifier struct {
+	Command ExecCommander
+	Options BlobOptions
+}
+
+// NewCosignBlobVerifier returns a verifier that shells out to cosign.
+func NewCosignBlobVerifier(opts BlobOptions) *CosignBlobVerifier {
+	return &CosignBlobVerifier{
+		Command: exec.CommandContext,
+		Options: opts,
+	}
+}
+
+// Verify runs `cosign verify-blob --signature <sig> <path>`. The signature
+// may be empty when Options.Bundle is set. As with image verification, a
+// non-zero exit status yields Verified=false with the output as the reason
+// and startup failures surface as errors.
+func (v *CosignBlobVerifier) Verify(ctx context.Context, path, signature string) (Result, error) {
+	path = strings.TrimSpace(path)
+	signature = strings.TrimSpace(signature)
+	opts := v.Options
+	if path == "" || (signature == "" && opts.Bundle == "") {
+		return Result{}, errors.New("blob and signature paths are required")
+	}
+	command := v.Command
+	if command == nil {
+		command = exec.CommandContext
+	}
+	args := []string{"verify-blob"}
+	if signature != "" {
+		args = append(args, "--signature", signature)
+	}
+	if opts.Bundle != "" {
+		args = append(args, "--bundle", opts.Bundle)
+	}
+	if opts.Offline {
+		args = append(args, "--offline=true")
+	}
+	if opts.Key != "" {
+		args = append(args, "--key", opts.Key)
+	} else {
+		if opts.Certificate != "" {
+			args = append(args, "--certificate", opts.Certificate)
+		}
+		if opts.CertificateIdentity != "" {
+			args = append(args, "--certificate-identity", opts.CertificateIdentity)
+		}
+		if opts.CertificateOIDCIssuer != "" {
+			args = append(args, "--certificate-oidc-issuer", opts.CertificateOIDCIssuer)
+		}
+	}
+	args = append(args, path)
+	cmd := command(ctx, "cosign", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			reason := strings.TrimSpace(string(output))
+			if reason == "" {
+				reason = exitErr.Error()
+			}
+			return Result{Verified: false, Reason: reason}, nil
+		}
+		return Result{}, fmt.Errorf("cosign execute: %w", err)
+	}
+	return Result{Verified: true}, nil
+}
@@ -24,6 +24,9 @@ func NewCosignVerifier() *CosignVerifier {
 	}
 }
 
+// Backend names the verifier in image trust previews.
+func (v *CosignVerifier) Backend() string { return BackendCosign }
+
 // Verify runs `cosign verify --keyless <image>`. A non-zero exit status is treated
 // as a verification failure (Verified=false) with the combined output captured as
 // the reason. Startup failures (e.g., cosign binary missing) surface as errors.
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DigestVerifier accepts an image only when it is referenced by digest and
+// that digest is pinned for its repository. It needs no signatures or
+// network access.
+type DigestVerifier struct {
+	pinned map[string]map[string]struct{}
+}
+
+// NewDigestVerifier returns a verifier accepting the given digests per
+// repository, e.g. "ghcr.io/acme/tool": ["sha256:..."].
+func NewDigestVerifier(pinned map[string][]string) *DigestVerifier {
+	v := &DigestVerifier{pinned: make(map[string]map[string]struct{}, len(pinned))}
+	for repo, digests := range pinned {
+		repo = strings.ToLower(strings.TrimSpace(repo))
+		set := v.pinned[repo]
+		if set == nil {
+			set = make(map[string]struct{}, len(digests))
+			v.pinned[repo] = set
+		}
+		for _, d := range digests {
+			set[strings.ToLower(strings.TrimSpace(d))] = struct{}{}
+		}
+	}
+	return v
+}
+
+// Backend names the verifier in image trust previews.
+func (v *DigestVerifier) Backend() string { return BackendDigest }
+
+// Verify checks image against the pinned digests.
+func (v *DigestVerifier) Verify(_ context.Context, image string) (Result, error) {
+	image = strings.TrimSpace(image)
+	if image == "" {
+		return Result{}, errors.New("image reference is required")
+	}
+	repo, digest := SplitImageDigest(image)
+	if digest == "" {
+		return Result{Reason: "image is not referenced by digest"}, nil
+	}
+	set, ok := v.pinned[strings.ToLower(repo)]
+	if !ok {
+		return Result{Reason: fmt.Sprintf("no pinned digests for %s", repo)}, nil
+	}
+	if _, ok := set[strings.ToLower(digest)]; !ok {
+		return Result{Reason: fmt.Sprintf("digest %s is not pinned for %s", digest, repo)}, nil
+	}
+	return Result{Verified: true}, nil
+}
+
+// SplitImageDigest splits an image reference into its repository, without
+// tag, and its digest ("" when the reference has none).
+func SplitImageDigest(image string) (repo, digest string) {
+	repo, digest, _ = strings.Cut(image, "@")
+	if slash := strings.LastIndex(repo, "/"); strings.LastIndex(repo, ":") > slash {
+		repo = repo[:strings.LastIndex(repo, ":")]
+	}
+	return repo, digest
+}
@@ -0,0 +1,43 @@
+package verify
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDigestVerifierPinnedDigests(t *testing.T) {
+	pinned := "sha256:" + strings.Repeat("a", 64)
+	v, err := New(BackendDigest, Options{PinnedDigests: map[string][]string{"ghcr.io/acme/tool": {pinned}}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	if got := BackendName(v); got != BackendDigest {
+		t.Fatalf("expected backend digest, got %q", got)
+	}
+	for image, want := range map[string]bool{
+		"ghcr.io/acme/tool:1.2@" + pinned:                     true,
+		"ghcr.io/acme/tool@" + pinned:                         true,
+		"ghcr.io/acme/tool:1.2":                               false,
+		"ghcr.io/acme/tool@sha256:" + strings.Repeat("b", 64): false,
+		"ghcr.io/acme/other@" + pinned:                        false,
+	} {
+		res, err := v.Verify(context.Background(), image)
+		if err != nil {
+			t.Fatalf("%s: %v", image, err)
+		}
+		if res.Verified != want {
+			t.Fatalf("%s: verified=%v (%s), want %v", image, res.Verified, res.Reason, want)
+		}
+	}
+}
+
+func TestNewRejectsUnknownBackend(t *testing.T) {
+	if _, err := New("sigstore-x", Options{}); err == nil {
+		t.Fatalf("expected error for unknown backend")
+	}
+	v, err := New("", Options{})
+	if err != nil || BackendName(v) != BackendCosign {
+		t.Fatalf("expected cosign default, got %v (%v)", BackendName(v), err)
+	}
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// NotationVerifier invokes the external notation CLI (Notary Project v2),
+// which applies the trust policy and trust store configured for notation.
+type NotationVerifier struct {
+	Command ExecCommander
+}
+
+// NewNotationVerifier returns a verifier that shells out to `notation verify`.
+func NewNotationVerifier() *NotationVerifier {
+	return &NotationVerifier{
+		Command: exec.CommandContext,
+	}
+}
+
+// Backend names the verifier in image trust previews.
+func (v *NotationVerifier) Backend() string { return BackendNotation }
+
+// Verify runs `notation verify <image>` with the same semantics as
+// CosignVerifier: a non-zero exit is a failed verification, a missing binary
+// an error.
+func (v *NotationVerifier) Verify(ctx context.Context, image string) (Result, error) {
+	image = strings.TrimSpace(image)
+	if image == "" {
+		return Result{}, errors.New("image reference is required")
+	}
+	command := v.Command
+	if command == nil {
+		command = exec.CommandContext
+	}
+	cmd := command(ctx, "notation", "verify", image)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			reason := strings.TrimSpace(string(output))
+			if reason == "" {
+				reason = exitErr.Error()
+			}
+			return Result{Verified: false, Reason: reason}, nil
+		}
+		return Result{}, fmt.Errorf("notation execute: %w", err)
+	}
+	return Result{Verified: true}, nil
+}
@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BackendCosignOffline names verification against material supplied with a
+// source instead of a registry or transparency log lookup.
+const BackendCosignOffline = "cosign-offline"
+
+// OfflineMaterial is signature material shipped alongside an OCI source so
+// verification needs no outbound network access. Bundle or Signature signs
+// the image digest string (e.g. "sha256:..."); trust comes from Key, or from
+// Certificate (or the bundle's embedded certificate) matching the identity
+// and issuer. All values are inline PEM/base64 content, not paths.
+type OfflineMaterial struct {
+	Bundle                string `json:"bundle,omitempty"`
+	Signature             string `json:"signature,omitempty"`
+	Key                   string `json:"key,omitempty"`
+	Certificate           string `json:"certificate,omitempty"`
+	CertificateIdentity   string `json:"certificate_identity,omitempty"`
+	CertificateOIDCIssuer string `json:"certificate_oidc_issuer,omitempty"`
+}
+
+// Validate reports material that cannot establish trust on its own.
+func (m OfflineMaterial) Validate() error {
+	if strings.TrimSpace(m.Bundle) == "" && strings.TrimSpace(m.Signature) == "" {
+		return errors.New("bundle or signature is required")
+	}
+	if strings.TrimSpace(m.Key) != "" {
+		return nil
+	}
+	if strings.TrimSpace(m.Signature) != "" && strings.TrimSpace(m.Bundle) == "" && strings.TrimSpace(m.Certificate) == "" {
+		return errors.New("a detached signature needs a key or certificate")
+	}
+	if strings.TrimSpace(m.CertificateIdentity) == "" || strings.TrimSpace(m.CertificateOIDCIssuer) == "" {
+		return errors.New("keyless material needs certificate_identity and certificate_oidc_issuer")
+	}
+	return nil
+}
+
+// OfflineVerifier checks an image digest against OfflineMaterial with
+// `cosign verify-blob --offline`. Images must be referenced by digest since
+// there is no registry lookup to resolve tags.
+type OfflineVerifier struct {
+	Command  ExecCommander
+	Material OfflineMaterial
+}
+
+// NewOfflineVerifier returns a verifier for the given material.
+func NewOfflineVerifier(material OfflineMaterial) *OfflineVerifier {
+	return &OfflineVerifier{Material: material}
+}
+
+// Backend names the verifier in image trust previews.
+func (v *OfflineVerifier) Backend() string { return BackendCosignOffline }
+
+// Verify writes the digest and material to a scratch directory and runs
+// cosign against it.
+func (v *OfflineVerifier) Verify(ctx context.Context, image string) (Result, error) {
+	image = strings.TrimSpace(image)
+	if image == "" {
+		return Result{}, errors.New("image reference is required")
+	}
+	_, digest := SplitImageDigest(image)
+	if digest == "" {
+		return Result{Reason: "offline verification requires an image referenced by digest"}, nil
+	}
+	if err := v.Material.Validate(); err != nil {
+		return Result{Reason: err.Error()}, nil
+	}
+
+	dir, err := os.MkdirTemp("", "flwd-offline-verify-")
+	if err != nil {
+		return Result{}, fmt.Errorf("offline verify scratch: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) (string, error) {
+		content = strings.TrimSpace(content)
+		if content == "" {
+			return "", nil
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			return "", fmt.Errorf("offline verify write %s: %w", name, err)
+		}
+		return path, nil
+	}
+
+	payload, err := write("digest", digest)
+	if err != nil {
+		return Result{}, err
+	}
+	opts := BlobOptions{
+		CertificateIdentity:   strings.TrimSpace(v.Material.CertificateIdentity),
+		CertificateOIDCIssuer: strings.TrimSpace(v.Material.CertificateOIDCIssuer),
+		Offline:               true,
+	}
+	if opts.Bundle, err = write("bundle.json", v.Material.Bundle); err != nil {
+		return Result{}, err
+	}
+	if opts.Key, err = write("key.pub", v.Material.Key); err != nil {
+		return Result{}, err
+	}
+	if opts.Certificate, err = write("cert.pem", v.Material.Certificate); err != nil {
+		return Result{}, err
+	}
+	signature, err := write("signature", v.Material.Signature)
+	if err != nil {
+		return Result{}, err
+	}
+
+	blob := NewCosignBlobVerifier(opts)
+	if v.Command != nil {
+		blob.Command = v.Command
+	}
+	return blob.Verify(ctx, payload, signature)
+}
@@ -0,0 +1,61 @@
+package verify
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOfflineVerifierRunsCosignOffline(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	var args []string
+	var payload string
+	v := NewOfflineVerifier(OfflineMaterial{
+		Bundle:                `{"base64Signature":"c2ln"}`,
+		CertificateIdentity:   "ci@example.com",
+		CertificateOIDCIssuer: "https://token.example.com",
+	})
+	v.Command = func(ctx context.Context, name string, arg ...string) *exec.Cmd {
+		args = arg
+		data, err := os.ReadFile(arg[len(arg)-1])
+		if err != nil {
+			t.Fatalf("read payload: %v", err)
+		}
+		payload = string(data)
+		return exec.CommandContext(ctx, "go", "version")
+	}
+
+	res, err := v.Verify(context.Background(), "ghcr.io/acme/tool@"+digest)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !res.Verified {
+		t.Fatalf("expected verified, got %+v", res)
+	}
+	joined := strings.Join(args, " ")
+	for _, want := range []string{"verify-blob", "--bundle", "--offline=true", "--certificate-identity ci@example.com"} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("expected %q in args %v", want, args)
+		}
+	}
+	if payload != digest {
+		t.Fatalf("expected digest payload, got %q", payload)
+	}
+	if _, err := os.Stat(filepath.Dir(args[len(args)-1])); !os.IsNotExist(err) {
+		t.Fatalf("expected scratch directory removed, got %v", err)
+	}
+}
+
+func TestOfflineVerifierRequiresDigest(t *testing.T) {
+	v := NewOfflineVerifier(OfflineMaterial{Signature: "c2ln", Key: "pem"})
+	res, err := v.Verify(context.Background(), "ghcr.io/acme/tool:1.2")
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if res.Verified || !strings.Contains(res.Reason, "digest") {
+		t.Fatalf("expected digest requirement, got %+v", res)
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package verify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Built-in image verification backends.
+const (
+	BackendCosign   = "cosign"
+	BackendNotation = "notation"
+	BackendDigest   = "digest"
+)
+
+// Options carries backend settings taken from the policy bundle.
+type Options struct {
+	// PinnedDigests maps image repositories to the digests the digest
+	// backend accepts.
+	PinnedDigests map[string][]string
+}
+
+// Factory builds an ImageVerifier from options.
+type Factory func(opts Options) (ImageVerifier, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{
+		BackendCosign:   func(Options) (ImageVerifier, error) { return NewCosignVerifier(), nil },
+		BackendNotation: func(Options) (ImageVerifier, error) { return NewNotationVerifier(), nil },
+		BackendDigest:   func(opts Options) (ImageVerifier, error) { return NewDigestVerifier(opts.PinnedDigests), nil },
+	}
+)
+
+// Register makes a backend available under name, replacing any previous
+// registration.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[strings.ToLower(strings.TrimSpace(name))] = factory
+}
+
+// Backends lists the registered backend names.
+func Backends() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New builds the verifier registered under name. An empty name selects cosign.
+func New(name string, opts Options) (ImageVerifier, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = BackendCosign
+	}
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown image verifier %q (known: %s)", name, strings.Join(Backends(), ", "))
+	}
+	return factory(opts)
+}
+
+// BackendName returns the backend a verifier reports through an optional
+// Backend() string method, or "" when it does not.
+func BackendName(v ImageVerifier) string {
+	if named, ok := v.(interface{ Backend() string }); ok {
+		return named.Backend()
+	}
+	return ""
+}
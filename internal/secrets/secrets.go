@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package secrets resolves credentials that serve-mode integrations reference
+// by name rather than embedding in API-managed configuration.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound reports a reference that names no secret.
+var ErrNotFound = errors.New("secret not found")
+
+// JobEnvPrefix prefixes the variables `env:` references in job configs
+// resolve from, keeping the server's own credentials out of their reach.
+const JobEnvPrefix = "FLWD_SECRET_"
+
+// Provider resolves a secret reference such as `env:NAME` or `file:name`.
+type Provider interface {
+	Resolve(ctx context.Context, ref string) ([]byte, error)
+}
+
+// ProviderFunc adapts a function to Provider.
+type ProviderFunc func(ctx context.Context, ref string) ([]byte, error)
+
+// Resolve calls f.
+func (f ProviderFunc) Resolve(ctx context.Context, ref string) ([]byte, error) {
+	return f(ctx, ref)
+}
+
+// Default resolves `env:NAME` from the process environment and `file:name`
+// from files under Dir. File references may not escape Dir, so principals
+// that configure sources cannot point integrations at arbitrary host files.
+type Default struct {
+	Dir string
+}
+
+// Resolve implements Provider.
+func (d Default) Resolve(_ context.Context, ref string) ([]byte, error) {
+	scheme, name, ok := strings.Cut(strings.TrimSpace(ref), ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("invalid secret reference %q: expected env:NAME or file:NAME", ref)
+	}
+	switch scheme {
+	case "env":
+		val, ok := os.LookupEnv(name)
+		if !ok || val == "" {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, ref)
+		}
+		return []byte(val), nil
+	case "file":
+		if d.Dir == "" {
+			return nil, fmt.Errorf("file secrets are not configured: %s", ref)
+		}
+		if filepath.IsAbs(name) || !filepath.IsLocal(name) {
+			return nil, fmt.Errorf("secret file %q must be relative to the secrets directory", name)
+		}
+		data, err := os.ReadFile(filepath.Join(d.Dir, name))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("%w: %s", ErrNotFound, ref)
+			}
+			return nil, fmt.Errorf("read secret %s: %w", ref, err)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported secret scheme %q", scheme)
+	}
+}
+
+// Schemes dispatches a reference to the provider registered for its scheme,
+// e.g. "vault" for `vault:path/to/key`. The provider receives the full
+// reference.
+type Schemes map[string]Provider
+
+// Resolve implements Provider.
+func (s Schemes) Resolve(ctx context.Context, ref string) ([]byte, error) {
+	ref = strings.TrimSpace(ref)
+	scheme, _, ok := strings.Cut(ref, ":")
+	p, found := s[scheme]
+	if !ok || !found {
+		return nil, fmt.Errorf("unsupported secret scheme %q", scheme)
+	}
+	return p.Resolve(ctx, ref)
+}
+
+// Env resolves `env:NAME` from the variable Prefix+NAME, so references held
+// in job configs can only reach variables set aside for them.
+type Env struct {
+	Prefix string
+}
+
+// Resolve implements Provider.
+func (e Env) Resolve(_ context.Context, ref string) ([]byte, error) {
+	name, ok := strings.CutPrefix(strings.TrimSpace(ref), "env:")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("invalid secret reference %q: expected env:NAME", ref)
+	}
+	val, ok := os.LookupEnv(e.Prefix + name)
+	if !ok || val == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, ref)
+	}
+	return []byte(val), nil
+}
+
+// Map is an in-memory Provider keyed by the full reference; useful in tests.
+type Map map[string]string
+
+// Resolve implements Provider.
+func (m Map) Resolve(_ context.Context, ref string) ([]byte, error) {
+	val, ok := m[ref]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, ref)
+	}
+	return []byte(val), nil
+}
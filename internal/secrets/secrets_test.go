@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultResolveEnv(t *testing.T) {
+	t.Setenv("FLWD_TEST_SECRET", "s3cr3t")
+	got, err := Default{}.Resolve(context.Background(), "env:FLWD_TEST_SECRET")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if string(got) != "s3cr3t" {
+		t.Fatalf("unexpected value %q", got)
+	}
+	if _, err := (Default{}).Resolve(context.Background(), "env:FLWD_TEST_MISSING"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestDefaultResolveFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.pem"), []byte("key"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+	p := Default{Dir: dir}
+	got, err := p.Resolve(context.Background(), "file:app.pem")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if string(got) != "key" {
+		t.Fatalf("unexpected value %q", got)
+	}
+	for _, ref := range []string{"file:../app.pem", "file:/etc/passwd", "file:", "vault:x", "plain"} {
+		if _, err := p.Resolve(context.Background(), ref); err == nil {
+			t.Fatalf("expected %q to be rejected", ref)
+		}
+	}
+}
+
+func TestSchemesAndEnv(t *testing.T) {
+	t.Setenv("FLWD_SECRET_DB_PASSWORD", "from-env")
+	t.Setenv("DB_PASSWORD", "unprefixed")
+	p := Schemes{
+		"env":   Env{Prefix: "FLWD_SECRET_"},
+		"vault": Map{"vault:apps/db/password": "from-vault"},
+	}
+	for ref, want := range map[string]string{
+		"env:DB_PASSWORD":        "from-env",
+		"vault:apps/db/password": "from-vault",
+	} {
+		got, err := p.Resolve(context.Background(), ref)
+		if err != nil {
+			t.Fatalf("resolve %s: %v", ref, err)
+		}
+		if string(got) != want {
+			t.Fatalf("resolve %s: got %q, want %q", ref, got, want)
+		}
+	}
+	if _, err := p.Resolve(context.Background(), "env:PATH"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected unprefixed variable to be unreachable, got %v", err)
+	}
+	if _, err := p.Resolve(context.Background(), "file:app.pem"); err == nil {
+		t.Fatal("expected unregistered scheme to be rejected")
+	}
+}
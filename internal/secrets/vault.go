@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultVaultMount = "secret"
+
+// Vault resolves `vault:path/to/key` from a HashiCorp Vault KV version 2
+// engine: key is a field of the secret stored at path/to. The token is read
+// through TokenRef on every lookup, so rotating it needs no restart.
+type Vault struct {
+	// Addr is the Vault server URL, e.g. https://vault.example.com:8200.
+	Addr string
+	// Mount is the path the KV engine is mounted at; empty uses "secret".
+	Mount string
+	// Namespace is sent as X-Vault-Namespace when set (Vault Enterprise).
+	Namespace string
+	// TokenRef is a secret reference to the Vault token, e.g. env:VAULT_TOKEN.
+	TokenRef string
+	// Secrets resolves TokenRef; nil uses Default.
+	Secrets Provider
+	// Client sends requests; nil uses a client with a 10s timeout.
+	Client *http.Client
+}
+
+// Resolve implements Provider.
+func (v Vault) Resolve(ctx context.Context, ref string) ([]byte, error) {
+	path, ok := strings.CutPrefix(strings.TrimSpace(ref), "vault:")
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	slash := strings.LastIndex(path, "/")
+	if !ok || slash <= 0 {
+		return nil, fmt.Errorf("invalid secret reference %q: expected vault:path/to/key", ref)
+	}
+	secretPath, field := path[:slash], path[slash+1:]
+	segments := strings.Split(secretPath, "/")
+	for i, seg := range segments {
+		if seg == "" || seg == "." || seg == ".." {
+			return nil, fmt.Errorf("invalid secret reference %q: empty or relative path element", ref)
+		}
+		segments[i] = url.PathEscape(seg)
+	}
+	if strings.TrimSpace(v.Addr) == "" {
+		return nil, fmt.Errorf("vault secrets are not configured: %s", ref)
+	}
+
+	var token []byte
+	if v.TokenRef != "" {
+		provider := v.Secrets
+		if provider == nil {
+			provider = Default{}
+		}
+		var err error
+		if token, err = provider.Resolve(ctx, v.TokenRef); err != nil {
+			return nil, fmt.Errorf("resolve vault token: %w", err)
+		}
+	}
+	mount := strings.Trim(v.Mount, "/")
+	if mount == "" {
+		mount = defaultVaultMount
+	}
+	endpoint := strings.TrimRight(v.Addr, "/") + "/v1/" + mount + "/data/" + strings.Join(segments, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("vault request: %w", err)
+	}
+	if len(token) > 0 {
+		req.Header.Set("X-Vault-Token", strings.TrimSpace(string(token)))
+	}
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+	client := v.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read vault response: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, ref)
+	case resp.StatusCode != http.StatusOK:
+		var failure struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(body, &failure)
+		if len(failure.Errors) > 0 {
+			return nil, fmt.Errorf("vault returned %d for %s: %s", resp.StatusCode, ref, strings.Join(failure.Errors, "; "))
+		}
+		return nil, fmt.Errorf("vault returned %d for %s", resp.StatusCode, ref)
+	}
+	var secret struct {
+		Data struct {
+			Data map[string]json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("decode vault response for %s: %w", ref, err)
+	}
+	raw, ok := secret.Data.Data[field]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, ref)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("vault field %s of %s is not a string", field, secretPath)
+	}
+	return []byte(value), nil
+}
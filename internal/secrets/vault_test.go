@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVaultResolve(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root-token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/data/apps/db":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"hunter2","port":5432},"metadata":{"version":3}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer srv.Close()
+
+	v := Vault{Addr: srv.URL, Mount: "kv", TokenRef: "token", Secrets: Map{"token": "root-token"}}
+	got, err := v.Resolve(context.Background(), "vault:apps/db/password")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if string(got) != "hunter2" {
+		t.Fatalf("unexpected value %q", got)
+	}
+	for _, ref := range []string{"vault:apps/db/user", "vault:apps/other/password"} {
+		if _, err := v.Resolve(context.Background(), ref); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound for %s, got %v", ref, err)
+		}
+	}
+	if _, err := v.Resolve(context.Background(), "vault:apps/db/port"); err == nil {
+		t.Fatal("expected non-string field to be rejected")
+	}
+	for _, ref := range []string{"vault:password", "vault:apps/../db/password", "vault:", "env:X"} {
+		if _, err := v.Resolve(context.Background(), ref); err == nil {
+			t.Fatalf("expected %q to be rejected", ref)
+		}
+	}
+
+	v.Secrets = Map{"token": "wrong"}
+	if _, err := v.Resolve(context.Background(), "vault:apps/db/password"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected permission error, got %v", err)
+	}
+}
@@ -11,12 +11,17 @@ import (
 	"net/http"
 	"os"
 	"strings"
+
+	"github.com/flowd-org/flowd/internal/server/authz"
 )
 
 type authInfo struct {
 	token   string
 	subject string
 	scopes  map[string]struct{}
+	// roles are RBAC role names carried by the token; they grant scopes
+	// through the server's RBAC policy.
+	roles []string
 }
 
 func (a *authInfo) hasScopes(required []string) bool {
@@ -119,13 +124,19 @@ func parseToken(token string, secret string, allowUnsigned bool) (*authInfo, err
 	if err := json.Unmarshal(payloadBytes, &claims); err != nil {
 		return nil, err
 	}
+	return authInfoFromClaims(token, claims), nil
+}
+
+// authInfoFromClaims maps verified JWT claims to the caller: sub is the
+// principal, scope, scp and scopes its scopes, and roles its RBAC roles.
+func authInfoFromClaims(token string, claims map[string]any) *authInfo {
 	subject, _ := claims["sub"].(string)
-	scopes := extractScopes(claims)
 	return &authInfo{
 		token:   token,
 		subject: subject,
-		scopes:  scopes,
-	}, nil
+		scopes:  extractScopes(claims),
+		roles:   extractRoles(claims),
+	}
 }
 
 func parseOpaqueToken(token string) (*authInfo, error) {
@@ -136,13 +147,18 @@ func parseOpaqueToken(token string) (*authInfo, error) {
 		return nil, errors.New("token missing scopes")
 	}
 	scopes := make(map[string]struct{}, len(fields))
+	var roles []string
 	for _, f := range fields {
 		if f == "" {
 			continue
 		}
+		if role, ok := strings.CutPrefix(f, "role:"); ok {
+			roles = append(roles, role)
+			continue
+		}
 		scopes[f] = struct{}{}
 	}
-	return &authInfo{token: token, scopes: scopes}, nil
+	return &authInfo{token: token, scopes: scopes, roles: roles}, nil
 }
 
 func decodeSegment(seg string) ([]byte, error) {
@@ -162,6 +178,19 @@ func extractScopes(claims map[string]any) map[string]struct{} {
 			set[s] = struct{}{}
 		}
 	}
+	// Some OIDC providers issue scp, as a list or a space-separated string.
+	switch raw := claims["scp"].(type) {
+	case string:
+		for _, s := range strings.Fields(raw) {
+			set[s] = struct{}{}
+		}
+	case []any:
+		for _, v := range raw {
+			if s, ok := v.(string); ok {
+				set[s] = struct{}{}
+			}
+		}
+	}
 	if rawArr, ok := claims["scopes"].([]any); ok {
 		for _, v := range rawArr {
 			if s, ok := v.(string); ok {
@@ -172,6 +201,34 @@ func extractScopes(claims map[string]any) map[string]struct{} {
 	return set
 }
 
+// extractRoles reads the roles claim, a list or a space-separated string.
+func extractRoles(claims map[string]any) []string {
+	var roles []string
+	switch raw := claims["roles"].(type) {
+	case string:
+		roles = strings.Fields(raw)
+	case []any:
+		for _, v := range raw {
+			if s, ok := v.(string); ok && s != "" {
+				roles = append(roles, s)
+			}
+		}
+	}
+	return roles
+}
+
+// applyRBAC adds the scopes of the caller's roles and returns its grant.
+func (a *authInfo) applyRBAC(policy *authz.Policy) authz.Grant {
+	grant := policy.Resolve(a.principal(), a.roles)
+	if a.scopes == nil {
+		a.scopes = make(map[string]struct{}, len(grant.Scopes))
+	}
+	for _, scope := range grant.Scopes {
+		a.scopes[scope] = struct{}{}
+	}
+	return grant
+}
+
 func defaultDevAuth() *authInfo {
 	return &authInfo{
 		token:   "dev",
@@ -180,11 +237,15 @@ func defaultDevAuth() *authInfo {
 			"jobs:read":     {},
 			"runs:read":     {},
 			"runs:write":    {},
+			"runs:admin":    {},
 			"events:read":   {},
 			"sources:read":  {},
 			"sources:write": {},
 			"ruley:read":    {},
 			"ruley:write":   {},
+			"admin:read":    {},
+			"admin:write":   {},
+			"audit:read":    {},
 		},
 	}
 }
@@ -198,7 +259,18 @@ func resolveAuthInfo(r *http.Request, cfg Config) (*authInfo, error) {
 		}
 		return nil, errors.New("missing token")
 	}
-	info, err := parseToken(token, secret, cfg.Dev)
+	var info *authInfo
+	var err error
+	if cfg.OIDC != nil {
+		// Tokens must come from the configured issuer; neither shared-secret
+		// JWTs nor opaque scope lists are accepted.
+		var claims map[string]any
+		if claims, err = cfg.OIDC.Verify(r.Context(), token); err == nil {
+			info = authInfoFromClaims(token, claims)
+		}
+	} else {
+		info, err = parseToken(token, secret, cfg.Dev)
+	}
 	if err != nil && cfg.Dev {
 		// In dev mode fall back to default scopes on parse failure.
 		return defaultDevAuth(), nil
@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package authz
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Built-in roles. A policy file may redefine them or add its own.
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
+
+var (
+	viewerScopes = []string{
+		ScopeJobsRead, ScopeRunsRead, ScopeEventsRead, ScopeSourcesRead, ScopePipelinesRead,
+	}
+	operatorScopes = append(slices.Clone(viewerScopes),
+		ScopeRunsWrite, ScopeRunsApprove, ScopeSourcesWrite, ScopeRuleYRead, ScopeRuleYWrite,
+		ScopePipelinesWrite, ScopePipelinesApprove,
+	)
+	adminScopes = append(slices.Clone(operatorScopes),
+		ScopeRunsAdmin, ScopeRunsHighImpact, ScopeSourcesTrust, ScopeAdminRead, ScopeAdminWrite,
+		ScopeAuditRead,
+	)
+)
+
+// AllScopes lists every scope the server checks.
+func AllScopes() []string {
+	return slices.Clone(adminScopes)
+}
+
+// Policy is an RBAC policy file. Roles map role names to the scopes they
+// grant, on top of the built-in viewer, operator and admin roles. Bindings
+// give principals roles and confine them to some jobs and sources.
+type Policy struct {
+	Roles    map[string][]string `yaml:"roles"`
+	Bindings []Binding           `yaml:"bindings"`
+}
+
+// Binding applies roles and access rules to the principals it names.
+// Principals, Jobs and Sources hold path.Match patterns, compared without
+// regard to case. A binding without jobs allows every job and one without
+// sources every source.
+type Binding struct {
+	Principals []string `yaml:"principals"`
+	Roles      []string `yaml:"roles"`
+	Jobs       []string `yaml:"jobs"`
+	Sources    []string `yaml:"sources"`
+}
+
+// LoadPolicy reads and validates the RBAC policy file at path.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rbac policy: %w", err)
+	}
+	var policy Policy
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("parse rbac policy %s: %w", path, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("rbac policy %s: %w", path, err)
+	}
+	return &policy, nil
+}
+
+// Validate reports unknown scopes and roles and malformed patterns.
+func (p *Policy) Validate() error {
+	for name, scopes := range p.Roles {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("roles: role name is required")
+		}
+		for _, scope := range scopes {
+			if !slices.Contains(adminScopes, scope) {
+				return fmt.Errorf("roles.%s: unknown scope %q", name, scope)
+			}
+		}
+	}
+	for i, binding := range p.Bindings {
+		if len(binding.Principals) == 0 {
+			return fmt.Errorf("bindings[%d]: principals is required", i)
+		}
+		for _, role := range binding.Roles {
+			if _, ok := p.RoleScopes(role); !ok {
+				return fmt.Errorf("bindings[%d]: unknown role %q", i, role)
+			}
+		}
+		fields := []struct {
+			name     string
+			patterns []string
+		}{{"principals", binding.Principals}, {"jobs", binding.Jobs}, {"sources", binding.Sources}}
+		for _, field := range fields {
+			for _, pattern := range field.patterns {
+				if _, err := path.Match(pattern, ""); err != nil || strings.TrimSpace(pattern) == "" {
+					return fmt.Errorf("bindings[%d].%s: invalid pattern %q", i, field.name, pattern)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// RoleScopes returns the scopes granted by role. A nil policy knows only the
+// built-in roles.
+func (p *Policy) RoleScopes(role string) ([]string, bool) {
+	if p != nil {
+		if scopes, ok := p.Roles[role]; ok {
+			return scopes, true
+		}
+	}
+	switch role {
+	case RoleViewer:
+		return viewerScopes, true
+	case RoleOperator:
+		return operatorScopes, true
+	case RoleAdmin:
+		return adminScopes, true
+	}
+	return nil, false
+}
+
+// Resolve returns the grant of principal, who presents roles in its token.
+// Roles unknown to the policy are ignored. A principal matched by no binding
+// keeps access to every job and source.
+func (p *Policy) Resolve(principal string, roles []string) Grant {
+	var grant Grant
+	roles = slices.Clone(roles)
+	var bound, allJobs, allSources bool
+	if p != nil {
+		for _, binding := range p.Bindings {
+			if !matchAny(binding.Principals, principal) {
+				continue
+			}
+			bound = true
+			roles = append(roles, binding.Roles...)
+			if len(binding.Jobs) == 0 {
+				allJobs = true
+			}
+			if len(binding.Sources) == 0 {
+				allSources = true
+			}
+			grant.jobs = append(grant.jobs, binding.Jobs...)
+			grant.sources = append(grant.sources, binding.Sources...)
+		}
+	}
+	if !bound || allJobs {
+		grant.jobs = nil
+	}
+	if !bound || allSources {
+		grant.sources = nil
+	}
+	for _, role := range roles {
+		scopes, _ := p.RoleScopes(role)
+		for _, scope := range scopes {
+			if !slices.Contains(grant.Scopes, scope) {
+				grant.Scopes = append(grant.Scopes, scope)
+			}
+		}
+	}
+	return grant
+}
+
+// Grant is what a policy allows one principal: the scopes of its roles and
+// the jobs and sources it may see and use.
+type Grant struct {
+	Scopes []string
+	// jobs and sources hold the allowed patterns; nil allows everything.
+	jobs    []string
+	sources []string
+}
+
+// Restricted reports whether the grant confines the principal to some jobs
+// or sources.
+func (g Grant) Restricted() bool {
+	return g.jobs != nil || g.sources != nil
+}
+
+// AllowsJob reports whether the principal may see and use job id.
+func (g Grant) AllowsJob(id string) bool {
+	return g.jobs == nil || matchAny(g.jobs, id)
+}
+
+// AllowsSource reports whether the principal may see and use source name.
+func (g Grant) AllowsSource(name string) bool {
+	return g.sources == nil || matchAny(g.sources, name)
+}
+
+func matchAny(patterns []string, value string) bool {
+	value = strings.ToLower(value)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), value); ok {
+			return true
+		}
+	}
+	return false
+}
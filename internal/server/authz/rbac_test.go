@@ -0,0 +1,88 @@
+package authz
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPolicyResolveGrantsRoleScopesAndAccessRules(t *testing.T) {
+	policy := &Policy{
+		Roles: map[string][]string{"deployer": {ScopeRunsWrite}},
+		Bindings: []Binding{
+			{Principals: []string{"team-a-*"}, Roles: []string{RoleViewer}, Jobs: []string{"a.*"}, Sources: []string{"team-a"}},
+			{Principals: []string{"team-a-ci"}, Roles: []string{"deployer"}, Jobs: []string{"shared.build"}},
+		},
+	}
+	if err := policy.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	grant := policy.Resolve("team-a-ci", nil)
+	for _, scope := range []string{ScopeJobsRead, ScopeRunsRead, ScopeRunsWrite} {
+		if !slices.Contains(grant.Scopes, scope) {
+			t.Fatalf("expected %s in %v", scope, grant.Scopes)
+		}
+	}
+	if !grant.Restricted() {
+		t.Fatal("expected a restricted grant")
+	}
+	for id, want := range map[string]bool{"a.deploy": true, "A.Deploy": true, "shared.build": true, "b.deploy": false} {
+		if got := grant.AllowsJob(id); got != want {
+			t.Fatalf("AllowsJob(%q) = %v, want %v", id, got, want)
+		}
+	}
+	// The second binding names no sources, so every source is allowed.
+	if !grant.AllowsSource("team-b") {
+		t.Fatal("expected sources to be unrestricted")
+	}
+
+	unbound := policy.Resolve("someone", []string{RoleAdmin, "unknown"})
+	if unbound.Restricted() || !slices.Contains(unbound.Scopes, ScopeAdminWrite) {
+		t.Fatalf("expected an unrestricted admin grant, got %+v", unbound)
+	}
+	if scopes := (*Policy)(nil).Resolve("someone", []string{RoleViewer}).Scopes; slices.Contains(scopes, ScopeRunsWrite) {
+		t.Fatalf("viewer must not write runs, got %v", scopes)
+	}
+}
+
+func TestLoadPolicyValidates(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatalf("write policy: %v", err)
+		}
+		return path
+	}
+
+	policy, err := LoadPolicy(write("ok.yaml", `
+roles:
+  deployer: [runs:read, runs:write]
+bindings:
+  - principals: [tenant-a]
+    roles: [deployer]
+    jobs: ["a.*"]
+`))
+	if err != nil {
+		t.Fatalf("load policy: %v", err)
+	}
+	if len(policy.Bindings) != 1 || policy.Bindings[0].Jobs[0] != "a.*" {
+		t.Fatalf("unexpected policy %+v", policy)
+	}
+
+	cases := map[string]string{
+		"roles:\n  broken: [runs:everything]\n":                `unknown scope "runs:everything"`,
+		"bindings:\n  - principals: [a]\n    roles: [owner]\n": `unknown role "owner"`,
+		"bindings:\n  - roles: [viewer]\n":                     "principals is required",
+		"bindings:\n  - principals: [a]\n    jobs: [\"[a\"]\n": `invalid pattern "[a"`,
+		"bindings:\n  - principals: [a]\n    tenants: [x]\n":   "field tenants not found",
+	}
+	for contents, want := range cases {
+		if _, err := LoadPolicy(write("bad.yaml", contents)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q for %q, got %v", want, contents, err)
+		}
+	}
+}
@@ -8,14 +8,25 @@ import (
 )
 
 const (
-	ScopeJobsRead     = "jobs:read"
-	ScopeRunsRead     = "runs:read"
-	ScopeRunsWrite    = "runs:write"
-	ScopeEventsRead   = "events:read"
-	ScopeSourcesRead  = "sources:read"
-	ScopeSourcesWrite = "sources:write"
-	ScopeRuleYRead    = "ruley:read"
-	ScopeRuleYWrite   = "ruley:write"
+	ScopeJobsRead       = "jobs:read"
+	ScopeRunsRead       = "runs:read"
+	ScopeRunsWrite      = "runs:write"
+	ScopeRunsAdmin      = "runs:admin"
+	ScopeRunsApprove    = "runs:approve"
+	ScopeRunsHighImpact = "runs:high-impact"
+	ScopeEventsRead     = "events:read"
+	ScopeSourcesRead    = "sources:read"
+	ScopeSourcesWrite   = "sources:write"
+	ScopeSourcesTrust   = "sources:trust"
+	ScopeRuleYRead      = "ruley:read"
+	ScopeRuleYWrite     = "ruley:write"
+	ScopeAdminRead      = "admin:read"
+	ScopeAdminWrite     = "admin:write"
+	ScopeAuditRead      = "audit:read"
+
+	ScopePipelinesRead    = "pipelines:read"
+	ScopePipelinesWrite   = "pipelines:write"
+	ScopePipelinesApprove = "pipelines:approve"
 )
 
 // RequiredScopes returns the scope set required to access the given method/path.
@@ -25,7 +36,11 @@ func RequiredScopes(method, path string) []string {
 		switch {
 		case path == "/jobs":
 			return []string{ScopeJobsRead}
-		case path == "/runs":
+		case strings.HasPrefix(path, "/jobs/") && (strings.HasSuffix(path, "/badge.svg") || strings.HasSuffix(path, "/badge.json")):
+			return []string{ScopeJobsRead, ScopeRunsRead}
+		case path == "/runs", path == "/runs:compare", path == "/stats":
+			return []string{ScopeRunsRead}
+		case strings.HasPrefix(path, "/runs/") && (strings.HasSuffix(path, "/artifacts") || strings.Contains(path, "/artifacts/")):
 			return []string{ScopeRunsRead}
 		case strings.HasPrefix(path, "/runs/") && strings.HasSuffix(path, "/events"):
 			return []string{ScopeRunsRead, ScopeEventsRead}
@@ -41,19 +56,41 @@ func RequiredScopes(method, path string) []string {
 			return []string{ScopeEventsRead}
 		case strings.HasPrefix(path, "/kv/"):
 			return []string{ScopeRuleYRead}
-		case path == "/health/storage":
+		case path == "/health/storage", path == "/health/runtime":
 			return []string{ScopeJobsRead}
+		case path == "/admin/settings", path == "/webhooks/deliveries":
+			return []string{ScopeAdminRead}
+		case path == "/audit", path == "/audit.ndjson":
+			return []string{ScopeAuditRead}
+		case path == "/pipelines", strings.HasPrefix(path, "/pipelines/"):
+			return []string{ScopePipelinesRead}
+		case path == "/schedules", strings.HasPrefix(path, "/schedules/"):
+			return []string{ScopeRunsRead}
 		}
 	case http.MethodPost:
 		switch {
 		case path == "/plans":
 			return []string{ScopeJobsRead}
-		case path == "/runs":
+		case path == "/runs", path == "/runs:batch":
 			return []string{ScopeRunsWrite}
+		case path == "/runs:cancel":
+			return []string{ScopeRunsWrite, ScopeRunsAdmin}
+		case strings.HasPrefix(path, "/runs/") && (strings.HasSuffix(path, ":approve") || strings.HasSuffix(path, ":reject")):
+			return []string{ScopeRunsApprove}
 		case path == "/sources":
 			return []string{ScopeSourcesWrite}
+		case strings.HasPrefix(path, "/sources/") && (strings.HasSuffix(path, ":trust") || strings.HasSuffix(path, ":quarantine") || strings.HasSuffix(path, ":release")):
+			return []string{ScopeSourcesTrust}
 		case strings.HasPrefix(path, "/kv/"):
 			return []string{ScopeRuleYWrite}
+		case strings.HasPrefix(path, "/pipelines/") && (strings.HasSuffix(path, ":approve") || strings.HasSuffix(path, ":reject")):
+			return []string{ScopePipelinesApprove}
+		case strings.HasPrefix(path, "/pipelines/") && strings.HasSuffix(path, "/promotions"):
+			return []string{ScopePipelinesWrite, ScopeRunsWrite}
+		case strings.HasPrefix(path, "/schedules/") && strings.HasSuffix(path, ":trigger"):
+			return []string{ScopeRunsWrite}
+		case strings.HasPrefix(path, "/schedules/") && (strings.HasSuffix(path, ":enable") || strings.HasSuffix(path, ":disable")):
+			return []string{ScopeRunsWrite, ScopeRunsAdmin}
 		}
 	case http.MethodDelete:
 		switch {
@@ -63,9 +100,15 @@ func RequiredScopes(method, path string) []string {
 			return []string{ScopeRuleYWrite}
 		}
 	case http.MethodPut:
+		if strings.HasPrefix(path, "/sources/") && strings.HasSuffix(path, "/config") {
+			return []string{ScopeSourcesWrite}
+		}
 		if strings.HasPrefix(path, "/kv/") {
 			return []string{ScopeRuleYWrite}
 		}
+		if path == "/admin/settings" {
+			return []string{ScopeAdminWrite}
+		}
 	}
 	return nil
 }
@@ -11,16 +11,44 @@ func TestRequiredScopes(t *testing.T) {
 		{method: "GET", path: "/jobs", want: []string{ScopeJobsRead}},
 		{method: "POST", path: "/plans", want: []string{ScopeJobsRead}},
 		{method: "POST", path: "/runs", want: []string{ScopeRunsWrite}},
+		{method: "POST", path: "/runs:batch", want: []string{ScopeRunsWrite}},
+		{method: "POST", path: "/runs:cancel", want: []string{ScopeRunsWrite, ScopeRunsAdmin}},
+		{method: "POST", path: "/runs/run-123:approve", want: []string{ScopeRunsApprove}},
+		{method: "POST", path: "/runs/run-123:reject", want: []string{ScopeRunsApprove}},
 		{method: "GET", path: "/runs", want: []string{ScopeRunsRead}},
+		{method: "GET", path: "/runs:compare", want: []string{ScopeRunsRead}},
+		{method: "GET", path: "/stats", want: []string{ScopeRunsRead}},
 		{method: "GET", path: "/runs/run-123", want: []string{ScopeRunsRead}},
 		{method: "GET", path: "/runs/run-123/events", want: []string{ScopeRunsRead, ScopeEventsRead}},
 		{method: "GET", path: "/runs/run-123/events.ndjson", want: []string{ScopeRunsRead, ScopeEventsRead}},
+		{method: "GET", path: "/runs/run-123/artifacts", want: []string{ScopeRunsRead}},
+		{method: "GET", path: "/runs/run-123/artifacts/logs/events", want: []string{ScopeRunsRead}},
 		{method: "GET", path: "/sources", want: []string{ScopeSourcesRead}},
 		{method: "GET", path: "/sources/main", want: []string{ScopeSourcesRead}},
 		{method: "POST", path: "/sources", want: []string{ScopeSourcesWrite}},
+		{method: "POST", path: "/sources/main:trust", want: []string{ScopeSourcesTrust}},
+		{method: "POST", path: "/sources/main:quarantine", want: []string{ScopeSourcesTrust}},
+		{method: "POST", path: "/sources/main:release", want: []string{ScopeSourcesTrust}},
 		{method: "DELETE", path: "/sources/main", want: []string{ScopeSourcesWrite}},
+		{method: "PUT", path: "/sources/main/jobs/demo/config", want: []string{ScopeSourcesWrite}},
 		{method: "GET", path: "/events", want: []string{ScopeEventsRead}},
 		{method: "GET", path: "/health/storage", want: []string{ScopeJobsRead}},
+		{method: "GET", path: "/health/runtime", want: []string{ScopeJobsRead}},
+		{method: "GET", path: "/admin/settings", want: []string{ScopeAdminRead}},
+		{method: "GET", path: "/webhooks/deliveries", want: []string{ScopeAdminRead}},
+		{method: "GET", path: "/audit", want: []string{ScopeAuditRead}},
+		{method: "GET", path: "/audit.ndjson", want: []string{ScopeAuditRead}},
+		{method: "PUT", path: "/admin/settings", want: []string{ScopeAdminWrite}},
+		{method: "GET", path: "/pipelines", want: []string{ScopePipelinesRead}},
+		{method: "GET", path: "/pipelines/release/promotions", want: []string{ScopePipelinesRead}},
+		{method: "POST", path: "/pipelines/release/promotions", want: []string{ScopePipelinesWrite, ScopeRunsWrite}},
+		{method: "POST", path: "/pipelines/release/promotions/promo-1:approve", want: []string{ScopePipelinesApprove}},
+		{method: "POST", path: "/pipelines/release/promotions/promo-1:reject", want: []string{ScopePipelinesApprove}},
+		{method: "GET", path: "/schedules", want: []string{ScopeRunsRead}},
+		{method: "GET", path: "/schedules/backup/nightly", want: []string{ScopeRunsRead}},
+		{method: "GET", path: "/schedules/backup/nightly/runs", want: []string{ScopeRunsRead}},
+		{method: "POST", path: "/schedules/backup/nightly:trigger", want: []string{ScopeRunsWrite}},
+		{method: "POST", path: "/schedules/backup/nightly:disable", want: []string{ScopeRunsWrite, ScopeRunsAdmin}},
 	}
 
 	for _, tc := range tests {
@@ -2,16 +2,29 @@
 package server
 
 import (
+	"fmt"
 	"io"
 	"net"
+	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/flowd-org/flowd/internal/coredb"
+	"github.com/flowd-org/flowd/internal/executor"
 	"github.com/flowd-org/flowd/internal/executor/container"
 	"github.com/flowd-org/flowd/internal/paths"
+	"github.com/flowd-org/flowd/internal/policy"
 	"github.com/flowd-org/flowd/internal/policy/verify"
+	"github.com/flowd-org/flowd/internal/secrets"
+	"github.com/flowd-org/flowd/internal/server/authz"
+	"github.com/flowd-org/flowd/internal/server/faults"
+	"github.com/flowd-org/flowd/internal/server/handlers"
+	"github.com/flowd-org/flowd/internal/server/notify"
+	"github.com/flowd-org/flowd/internal/server/oidc"
+	"github.com/flowd-org/flowd/internal/server/settings"
 	"github.com/flowd-org/flowd/internal/types"
 )
 
@@ -21,13 +34,26 @@ const (
 	defaultScriptsRoot     = "scripts"
 	defaultShutdownTimeout = 15 * time.Second
 	defaultRuleYLimitBytes = 32 << 20
+	defaultMaxBodyBytes    = 1 << 20
+	defaultPerPage         = 50
+	defaultMaxPerPage      = 200
+
+	defaultMetricsSnapshotInterval = time.Minute
+	defaultSourceReverifyInterval  = 6 * time.Hour
+	defaultStreamsPerPrincipal     = 32
+	defaultStreamsPerRun           = 100
 )
 
 // Config carries serve-mode runtime settings derived from CLI flags and env vars.
 type Config struct {
-	Bind                        string
-	Dev                         bool
-	Log                         string
+	Bind string
+	// GRPCBind is the address of the gRPC API listener; empty disables it.
+	GRPCBind string
+	Dev      bool
+	Log      string
+	// Profile is the server security profile resolved at startup with the
+	// precedence --profile flag > FLWD_PROFILE > "secure". Handlers receive it
+	// through their configs; requests may only tighten it.
 	Profile                     string
 	AliasesPublic               bool
 	Verifier                    verify.ImageVerifier
@@ -45,8 +71,152 @@ type Config struct {
 	DataDir                     string
 	CoreDBOptions               coredb.Options
 	CoreDB                      *coredb.DB
-	RuleY                       types.RuleYConfig
-	Extensions                  map[string]bool
+	PlanCache                   *handlers.PlanCache
+	EventBufferSize             int
+	MaxBodyBytes                int64
+	// DefaultPerPage and MaxPerPage bound per_page on GET /runs and
+	// GET /jobs. Zero values use 50 and 200.
+	DefaultPerPage int
+	MaxPerPage     int
+	Settings       *settings.Store
+	RuleY          types.RuleYConfig
+	Extensions     map[string]bool
+	// PublicURL is the externally reachable base URL of this server, used in
+	// links reported to forges. Empty omits links.
+	PublicURL string
+	// PublicBadges serves job status badges without authentication so they
+	// can be embedded in READMEs and wikis.
+	PublicBadges bool
+	// Secrets resolves credential references held in source configuration.
+	// Defaults to env: references and file: references under the data dir.
+	Secrets secrets.Provider
+	// ArgSecrets resolves the from references of secret job args when runs
+	// start. Defaults to env: references to FLWD_SECRET_ variables and, when
+	// Vault.Addr is set, vault: references.
+	ArgSecrets secrets.Provider
+	// Vault configures the HashiCorp Vault KV engine vault: arg references
+	// are read from.
+	Vault VaultConfig
+	// SMTP configures the relay used for job email notifications. Email
+	// notifications are disabled when Addr is empty.
+	SMTP SMTPConfig
+	// RunHooks are called when any run starts or finishes.
+	RunHooks RunHooksConfig
+	// Webhooks receive signed run lifecycle and policy events, e.g. for CI
+	// systems that cannot hold an SSE stream open.
+	Webhooks WebhooksConfig
+	// ReadOnly starts the server refusing mutating requests. Operators can
+	// lift it at runtime through the read_only admin setting.
+	ReadOnly bool
+	// RunArchive moves finished runs to cold storage after a retention window.
+	RunArchive RunArchiveConfig
+	// MetricsSnapshotInterval is how often counters are saved to the Core DB
+	// so totals survive restarts. Zero uses one minute; negative disables
+	// snapshots.
+	MetricsSnapshotInterval time.Duration
+	// EventStreams caps open SSE streams per principal and per run. Zero
+	// values use 32 and 100; negative values remove the cap.
+	EventStreams handlers.StreamLimits
+	// Faults are injected into every request. They, and the per-request
+	// X-Flowd-Fault header, are only honored in dev mode.
+	Faults faults.Set
+	// WatchScripts makes the scripts watcher react to script edits as well
+	// as job configs, so cached plans never pin stale script digests. Used by
+	// :dev. WatchInterval overrides the 2s polling interval.
+	WatchScripts  bool
+	WatchInterval time.Duration
+	// MaxParallelSteps bounds how many independent DAG steps of a run execute
+	// at once. Zero runs steps one at a time.
+	MaxParallelSteps int
+	// MaxLogLineBytes truncates longer step output lines in step.log events.
+	// Zero uses 16 KiB; negative keeps lines whole.
+	MaxLogLineBytes int
+	// StripANSILogs removes ANSI escape sequences from stored step output
+	// while live step.log events keep them.
+	StripANSILogs bool
+	// ArtifactLimits bounds the files a run publishes under artifacts/ in
+	// its run directory. Zero fields use 100 MiB per file and 1 GiB per run.
+	ArtifactLimits executor.ArtifactLimits
+	// Flakiness flags jobs whose recent runs alternate between success and
+	// failure in /jobs and /stats and may retry or gate their new runs.
+	Flakiness handlers.FlakinessPolicy
+	// RBAC maps token roles to scopes and confines principals to the jobs
+	// and sources its bindings allow. Nil keeps the built-in roles and leaves
+	// every principal unconfined.
+	RBAC *authz.Policy
+	// OIDC validates bearer tokens against an OpenID Connect issuer. When
+	// set, it replaces FLWD_JWT_SECRET and opaque scope-list tokens.
+	OIDC *oidc.Verifier
+	// AdmissionWebhook is consulted with the candidate plan before each run
+	// is admitted and may deny it or add findings. Disabled when URL is
+	// empty.
+	AdmissionWebhook handlers.AdmissionWebhookConfig
+	// EventJournal controls compaction of the Core DB event journal that
+	// serves Last-Event-ID resume and ?replay=all. Its size budget is
+	// CoreDBOptions.JournalMaxBytes.
+	EventJournal EventJournalConfig
+}
+
+// EventJournalConfig controls event journal compaction. A zero Retention
+// keeps events until the journal size budget evicts the oldest; otherwise
+// the events of runs quiet for longer than Retention are dropped every
+// Interval (default ten minutes).
+type EventJournalConfig struct {
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+// RunArchiveConfig controls the run archiver. A zero HotRetention keeps every
+// run in hot storage. Dir defaults to "archive" under the data dir.
+type RunArchiveConfig struct {
+	HotRetention time.Duration
+	Interval     time.Duration
+	Dir          string
+}
+
+// RunHooksConfig carries server-wide run lifecycle hooks. Each target is an
+// http(s) URL or a command; failures are logged and reported as run events
+// but never fail the run.
+type RunHooksConfig struct {
+	Targets []string
+	Timeout time.Duration
+}
+
+// WebhooksConfig configures outbound webhooks. Every URL receives the events
+// in Events (all webhook events when empty), signed with the key SecretRef
+// resolves to.
+type WebhooksConfig struct {
+	URLs      []string
+	SecretRef string
+	Events    []string
+}
+
+// endpoints expands the config into one endpoint per URL.
+func (c WebhooksConfig) endpoints() []handlers.WebhookEndpoint {
+	out := make([]handlers.WebhookEndpoint, 0, len(c.URLs))
+	for _, u := range c.URLs {
+		out = append(out, handlers.WebhookEndpoint{URL: u, SecretRef: c.SecretRef, Events: c.Events})
+	}
+	return out
+}
+
+// SMTPConfig carries the SMTP relay settings. PasswordRef is resolved through
+// the secrets provider at send time.
+type SMTPConfig struct {
+	Addr        string
+	From        string
+	Username    string
+	PasswordRef string
+	TLS         string
+}
+
+// VaultConfig locates a HashiCorp Vault KV version 2 engine. TokenRef is a
+// secrets reference to the token.
+type VaultConfig struct {
+	Addr      string
+	Mount     string
+	Namespace string
+	TokenRef  string
 }
 
 // RuntimeDetector resolves the available container runtime binary.
@@ -57,6 +227,13 @@ type SourcesConfig struct {
 	AllowLocalRoots []string
 	AllowGitHosts   []string
 	CheckoutDir     string
+	// OfflineVerification requires OCI sources to ship signature material
+	// instead of consulting registries or transparency logs.
+	OfflineVerification bool
+	// ReverifyInterval is how often stored OCI sources are re-checked
+	// against the registry allow-list and signature policy. Zero selects the
+	// default; a negative value disables re-verification.
+	ReverifyInterval time.Duration
 }
 
 // normalize applies defaults when values are not supplied.
@@ -70,6 +247,7 @@ func (c Config) normalize() Config {
 	if c.ScriptsRoot == "" {
 		c.ScriptsRoot = defaultScriptsRoot
 	}
+	c.Profile = strings.ToLower(strings.TrimSpace(c.Profile))
 	if c.Profile == "" {
 		c.Profile = "secure"
 	}
@@ -82,9 +260,28 @@ func (c Config) normalize() Config {
 	if c.DataDir == "" {
 		c.DataDir = paths.DataDir()
 	}
+	if c.Secrets == nil {
+		c.Secrets = secrets.Default{Dir: paths.SecretsDir()}
+	}
+	if c.ArgSecrets == nil {
+		schemes := secrets.Schemes{"env": secrets.Env{Prefix: secrets.JobEnvPrefix}}
+		if c.Vault.Addr != "" {
+			schemes["vault"] = secrets.Vault{
+				Addr:      c.Vault.Addr,
+				Mount:     c.Vault.Mount,
+				Namespace: c.Vault.Namespace,
+				TokenRef:  c.Vault.TokenRef,
+				Secrets:   c.Secrets,
+			}
+		}
+		c.ArgSecrets = schemes
+	}
 	if c.CoreDBOptions.DataDir == "" {
 		c.CoreDBOptions.DataDir = c.DataDir
 	}
+	if c.RunArchive.HotRetention > 0 && c.RunArchive.Dir == "" {
+		c.RunArchive.Dir = filepath.Join(c.DataDir, "archive")
+	}
 	if c.StdOut == nil {
 		c.StdOut = os.Stdout
 	}
@@ -94,6 +291,18 @@ func (c Config) normalize() Config {
 	if c.ShutdownTimeout <= 0 {
 		c.ShutdownTimeout = defaultShutdownTimeout
 	}
+	if c.RunHooks.Timeout <= 0 {
+		c.RunHooks.Timeout = notify.DefaultHookTimeout
+	}
+	if c.MaxBodyBytes <= 0 {
+		c.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	if c.MaxPerPage == 0 {
+		c.MaxPerPage = defaultMaxPerPage
+	}
+	if c.DefaultPerPage == 0 {
+		c.DefaultPerPage = min(defaultPerPage, c.MaxPerPage)
+	}
 	if c.RuntimeDetector == nil {
 		c.RuntimeDetector = func() (container.Runtime, error) {
 			return container.DetectRuntime(nil)
@@ -102,6 +311,18 @@ func (c Config) normalize() Config {
 	if !c.MetricsConfigured {
 		c.MetricsEnabled = true
 	}
+	if c.EventStreams.PerPrincipal == 0 {
+		c.EventStreams.PerPrincipal = defaultStreamsPerPrincipal
+	}
+	if c.EventStreams.PerRun == 0 {
+		c.EventStreams.PerRun = defaultStreamsPerRun
+	}
+	if c.MetricsSnapshotInterval == 0 {
+		c.MetricsSnapshotInterval = defaultMetricsSnapshotInterval
+	}
+	if c.Sources.ReverifyInterval == 0 {
+		c.Sources.ReverifyInterval = defaultSourceReverifyInterval
+	}
 	if c.MetricsEnabled {
 		c.MetricsAllowUnauthenticated = isLoopbackAddress(c.Bind)
 	} else {
@@ -138,6 +359,86 @@ func (c Config) normalize() Config {
 	return c
 }
 
+// validate reports configuration errors that must abort startup.
+func (c Config) validate() error {
+	if _, ok := policy.NormalizeProfile(c.Profile); !ok {
+		return fmt.Errorf("invalid security profile %q", c.Profile)
+	}
+	if _, err := c.mailer(); err != nil {
+		return err
+	}
+	for _, target := range c.RunHooks.Targets {
+		if err := validateRunHook(target); err != nil {
+			return err
+		}
+	}
+	for _, target := range c.Webhooks.URLs {
+		if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook: invalid url %q", target)
+		}
+	}
+	for _, event := range c.Webhooks.Events {
+		if !slices.Contains(handlers.WebhookEvents, event) {
+			return fmt.Errorf("webhook: unknown event %q (want one of %s)", event, strings.Join(handlers.WebhookEvents, ", "))
+		}
+	}
+	if target := c.AdmissionWebhook.URL; target != "" {
+		if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("admission webhook: invalid url %q", target)
+		}
+	}
+	if c.AdmissionWebhook.Timeout < 0 {
+		return fmt.Errorf("admission webhook: timeout must not be negative")
+	}
+	if c.RunArchive.HotRetention < 0 {
+		return fmt.Errorf("run archive: hot retention must not be negative")
+	}
+	if c.MaxPerPage < 1 || c.DefaultPerPage < 1 {
+		return fmt.Errorf("pagination: per-page bounds must be positive")
+	}
+	if c.DefaultPerPage > c.MaxPerPage {
+		return fmt.Errorf("pagination: default per page %d exceeds maximum %d", c.DefaultPerPage, c.MaxPerPage)
+	}
+	if len(c.Faults) > 0 && !c.Dev {
+		return fmt.Errorf("fault injection requires dev mode")
+	}
+	return nil
+}
+
+// validateRunHook rejects blank hook targets and URLs without a host.
+func validateRunHook(target string) error {
+	if strings.TrimSpace(target) == "" {
+		return fmt.Errorf("run hook: empty target")
+	}
+	if notify.IsHookURL(target) {
+		u, err := url.Parse(target)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("run hook: invalid url %q", target)
+		}
+	}
+	return nil
+}
+
+// mailer returns the SMTP mailer for job notifications, or nil when no relay
+// is configured.
+func (c Config) mailer() (notify.Mailer, error) {
+	if c.SMTP.Addr == "" {
+		return nil, nil
+	}
+	m, err := notify.NewSMTPMailer(notify.SMTPConfig{
+		Addr:        c.SMTP.Addr,
+		From:        c.SMTP.From,
+		Username:    c.SMTP.Username,
+		PasswordRef: c.SMTP.PasswordRef,
+		TLS:         c.SMTP.TLS,
+		Secrets:     c.Secrets,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("smtp: %w", err)
+	}
+	return m, nil
+}
+
 // ExtensionEnabled reports whether the supplied extension flag is enabled.
 func (c Config) ExtensionEnabled(name string) bool {
 	if len(c.Extensions) == 0 {
@@ -34,3 +34,63 @@ func TestConfigNormalizeRuleYCustomLimit(t *testing.T) {
 		t.Fatalf("expected limit %d, got %d", custom, norm.RuleY.Allowlist["core_triggers"].LimitBytes)
 	}
 }
+
+func TestConfigValidateRejectsUnknownProfile(t *testing.T) {
+	norm := Config{Profile: "Permissive"}.normalize()
+	if norm.Profile != "permissive" {
+		t.Fatalf("expected normalized profile permissive, got %q", norm.Profile)
+	}
+	if err := norm.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if err := (Config{Profile: "lenient"}).normalize().validate(); err == nil {
+		t.Fatalf("expected error for unknown profile")
+	}
+}
+
+func TestConfigValidateRunHooks(t *testing.T) {
+	norm := Config{RunHooks: RunHooksConfig{Targets: []string{"https://cmdb.example/hooks", "/usr/local/bin/register-run --env prod"}}}.normalize()
+	if norm.RunHooks.Timeout <= 0 {
+		t.Fatalf("expected default hook timeout, got %v", norm.RunHooks.Timeout)
+	}
+	if err := norm.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	for _, target := range []string{" ", "https:///hooks"} {
+		if err := (Config{RunHooks: RunHooksConfig{Targets: []string{target}}}).normalize().validate(); err == nil {
+			t.Fatalf("expected error for hook target %q", target)
+		}
+	}
+}
+
+func TestConfigValidateWebhooks(t *testing.T) {
+	ok := Config{Webhooks: WebhooksConfig{URLs: []string{"https://ci.example/flowd"}, Events: []string{"run.finish", "policy.decision"}}}
+	if err := ok.normalize().validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	for _, cfg := range []WebhooksConfig{
+		{URLs: []string{"/usr/local/bin/notify"}},
+		{URLs: []string{"https:///flowd"}},
+		{URLs: []string{"https://ci.example/flowd"}, Events: []string{"step.log"}},
+	} {
+		if err := (Config{Webhooks: cfg}).normalize().validate(); err == nil {
+			t.Fatalf("expected error for %+v", cfg)
+		}
+	}
+}
+
+func TestConfigPaginationBounds(t *testing.T) {
+	norm := Config{MaxPerPage: 20}.normalize()
+	if norm.DefaultPerPage != 20 || norm.MaxPerPage != 20 {
+		t.Fatalf("expected default capped at maximum, got %d/%d", norm.DefaultPerPage, norm.MaxPerPage)
+	}
+	if err := norm.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if err := (Config{DefaultPerPage: 100, MaxPerPage: 20}).normalize().validate(); err == nil {
+		t.Fatal("expected error for default above maximum")
+	}
+	if err := (Config{MaxPerPage: -1}).normalize().validate(); err == nil {
+		t.Fatal("expected error for negative maximum")
+	}
+}
@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package faults injects failures into serve-mode code paths so operators can
+// exercise the retry behaviour of their automation. Faults are armed per
+// request from the FLWD_FAULTS environment variable and the X-Flowd-Fault
+// header, and only when the server runs in dev mode.
+package faults
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+)
+
+// Kind names an injectable fault.
+type Kind string
+
+const (
+	// IdempotencyError fails idempotency store lookups and writes.
+	IdempotencyError Kind = "idempotency"
+	// SSESlow delays every server-sent event write, as a slow consumer would.
+	SSESlow Kind = "sse-slow"
+	// ContainerRuntime reports the container runtime as unavailable.
+	ContainerRuntime Kind = "container-runtime"
+	// GitTimeout fails git operations with a deadline error.
+	GitTimeout Kind = "git-timeout"
+)
+
+// Header is the request header that arms faults for a single request.
+const Header = "X-Flowd-Fault"
+
+// EnvVar arms faults for every request.
+const EnvVar = "FLWD_FAULTS"
+
+// DefaultSSEDelay is the per-event delay of sse-slow without an explicit value.
+const DefaultSSEDelay = 2 * time.Second
+
+var kinds = map[Kind]bool{
+	IdempotencyError: true,
+	SSESlow:          true,
+	ContainerRuntime: true,
+	GitTimeout:       true,
+}
+
+// Set is the collection of armed faults. Values are only meaningful for
+// sse-slow, where they set the per-event delay.
+type Set map[Kind]time.Duration
+
+// Parse reads a comma-separated fault list such as
+// "idempotency,sse-slow=500ms".
+func Parse(spec string) (Set, error) {
+	set := Set{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, hasValue := strings.Cut(item, "=")
+		kind := Kind(strings.ToLower(strings.TrimSpace(name)))
+		if !kinds[kind] {
+			return nil, fmt.Errorf("unknown fault %q", name)
+		}
+		var d time.Duration
+		if hasValue {
+			if kind != SSESlow {
+				return nil, fmt.Errorf("fault %s takes no value", kind)
+			}
+			parsed, err := time.ParseDuration(strings.TrimSpace(value))
+			if err != nil || parsed < 0 {
+				return nil, fmt.Errorf("invalid %s delay %q", kind, value)
+			}
+			d = parsed
+		}
+		set[kind] = d
+	}
+	return set, nil
+}
+
+// Merge returns the union of s and other; entries in other win.
+func (s Set) Merge(other Set) Set {
+	out := make(Set, len(s)+len(other))
+	for k, v := range s {
+		out[k] = v
+	}
+	for k, v := range other {
+		out[k] = v
+	}
+	return out
+}
+
+// String renders the set in the form accepted by Parse.
+func (s Set) String() string {
+	parts := make([]string, 0, len(s))
+	for k, v := range s {
+		if v > 0 {
+			parts = append(parts, fmt.Sprintf("%s=%s", k, v))
+		} else {
+			parts = append(parts, string(k))
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+type ctxKey struct{}
+
+// WithSet arms the faults in set for ctx.
+func WithSet(ctx context.Context, set Set) context.Context {
+	if len(set) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxKey{}, set)
+}
+
+// Inject reports whether kind is armed for ctx and logs the injection.
+func Inject(ctx context.Context, kind Kind) bool {
+	if ctx == nil {
+		return false
+	}
+	set, _ := ctx.Value(ctxKey{}).(Set)
+	if _, ok := set[kind]; !ok {
+		return false
+	}
+	if logger := requestctx.Logger(ctx); logger != nil {
+		logger.Warn("fault.injected", slog.String("fault", string(kind)))
+	}
+	return true
+}
+
+// Err is the error returned by an injected failure.
+func Err(kind Kind) error {
+	return fmt.Errorf("injected fault: %s", kind)
+}
+
+// Delay sleeps for the sse-slow delay when it is armed for ctx. It returns
+// early with ctx's error when ctx is canceled.
+func Delay(ctx context.Context) error {
+	if !Inject(ctx, SSESlow) {
+		return nil
+	}
+	set, _ := ctx.Value(ctxKey{}).(Set)
+	d := set[SSESlow]
+	if d == 0 {
+		d = DefaultSSEDelay
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
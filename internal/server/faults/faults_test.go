@@ -0,0 +1,45 @@
+package faults
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	set, err := Parse(" idempotency , SSE-slow=250ms,git-timeout")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := set.String(); got != "git-timeout,idempotency,sse-slow=250ms" {
+		t.Fatalf("unexpected set %q", got)
+	}
+	for _, bad := range []string{"disk-full", "idempotency=1s", "sse-slow=soon"} {
+		if _, err := Parse(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestInjectAndDelay(t *testing.T) {
+	ctx := context.Background()
+	if Inject(ctx, IdempotencyError) {
+		t.Fatalf("expected no faults without a set")
+	}
+	ctx = WithSet(ctx, Set{IdempotencyError: 0, SSESlow: 20 * time.Millisecond})
+	if !Inject(ctx, IdempotencyError) || Inject(ctx, GitTimeout) {
+		t.Fatalf("expected only armed faults to fire")
+	}
+	start := time.Now()
+	if err := Delay(ctx); err != nil {
+		t.Fatalf("Delay: %v", err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Fatalf("expected sse-slow to delay the write")
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := Delay(canceled); err == nil {
+		t.Fatalf("expected canceled delay to return the context error")
+	}
+}
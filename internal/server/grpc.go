@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/grpcapi"
+	"google.golang.org/grpc"
+)
+
+// listenGRPC starts the gRPC API on cfg.GRPCBind, serving every call through
+// handler. Serve errors are sent to errCh. The returned stop function drains
+// in-flight calls and cancels those still open, such as event streams, after
+// cfg.ShutdownTimeout.
+func listenGRPC(cfg Config, handler http.Handler, errCh chan<- error) (func(), error) {
+	if cfg.GRPCBind == "" {
+		return func() {}, nil
+	}
+	lis, err := net.Listen("tcp", cfg.GRPCBind)
+	if err != nil {
+		return nil, fmt.Errorf("grpc listen: %w", err)
+	}
+	srv := grpc.NewServer()
+	grpcapi.New(handler).Register(srv)
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			errCh <- fmt.Errorf("grpc serve: %w", err)
+		}
+	}()
+	return func() {
+		done := make(chan struct{})
+		go func() {
+			srv.GracefulStop()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(cfg.ShutdownTimeout):
+			srv.Stop()
+			<-done
+		}
+	}, nil
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	flowdv1 "github.com/flowd-org/flowd/api/flowd/v1"
+	"github.com/flowd-org/flowd/internal/policy"
+	"github.com/flowd-org/flowd/internal/server/grpcapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPCSharesAuthAndScopesWithHTTP(t *testing.T) {
+	cfg := Config{Bind: "127.0.0.1:0", Profile: "secure", DataDir: t.TempDir(), ScriptsRoot: t.TempDir()}
+	cfg = cfg.normalize()
+	policyCtx, err := policy.NewContext(nil)
+	if err != nil {
+		t.Fatalf("policy context: %v", err)
+	}
+	handler, closeHandler := buildHandler(cfg, policyCtx, nil)
+	defer closeHandler()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	grpcapi.New(handler).Register(srv)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	client := flowdv1.NewFlowdClient(conn)
+	withToken := func(scopes string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+scopes)
+	}
+
+	var trailer metadata.MD
+	_, err = client.ListJobs(context.Background(), &flowdv1.ListJobsRequest{}, grpc.Trailer(&trailer))
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated without a token, got %v", err)
+	}
+	if problem := trailer.Get(grpcapi.ProblemTrailer); len(problem) != 1 || !strings.Contains(problem[0], `"status":401`) {
+		t.Fatalf("expected problem trailer, got %v", trailer)
+	}
+
+	if _, err := client.ListSources(withToken("jobs:read"), &flowdv1.ListSourcesRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied without sources:read, got %v", err)
+	}
+
+	jobs, err := client.ListJobs(withToken("jobs:read"), &flowdv1.ListJobsRequest{})
+	if err != nil {
+		t.Fatalf("list jobs: %v", err)
+	}
+	if len(jobs.GetJobs()) != 0 || jobs.GetTotal() != 0 {
+		t.Fatalf("expected no jobs in an empty scripts root, got %v", jobs)
+	}
+
+	if _, err := client.GetRun(withToken("runs:read"), &flowdv1.GetRunRequest{Id: "missing"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound for unknown run, got %v", err)
+	}
+}
@@ -0,0 +1,639 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package grpcapi serves the flowd.v1.Flowd gRPC service. Each call is
+// replayed as a request against the serve-mode HTTP handler chain, so gRPC
+// clients go through the same authentication, scope checks, policy, read-only
+// mode and audit logging as REST clients, and the handlers stay the single
+// implementation of every operation.
+package grpcapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	flowdv1 "github.com/flowd-org/flowd/api/flowd/v1"
+	"github.com/flowd-org/flowd/internal/server/headers"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ProblemTrailer is the trailer key carrying the RFC 7807 problem document of
+// a failed call as JSON. The -bin suffix lets it hold any UTF-8 text.
+const ProblemTrailer = "flowd-problem-bin"
+
+// Server implements flowdv1.FlowdServer on top of an HTTP handler.
+type Server struct {
+	flowdv1.UnimplementedFlowdServer
+	handler http.Handler
+}
+
+// New returns a Server dispatching to handler, normally the full serve-mode
+// handler with its middleware chain.
+func New(handler http.Handler) *Server {
+	return &Server{handler: handler}
+}
+
+// Register adds the Flowd service to g.
+func (s *Server) Register(g *grpc.Server) {
+	flowdv1.RegisterFlowdServer(g, s)
+}
+
+// CreateRun serves POST /runs.
+func (s *Server) CreateRun(ctx context.Context, req *flowdv1.CreateRunRequest) (*flowdv1.Run, error) {
+	body := map[string]any{"job_id": req.GetJobId()}
+	if req.GetArgs() != nil {
+		body["args"] = req.GetArgs().AsMap()
+	}
+	if req.GetSource() != "" {
+		body["source"] = map[string]any{"name": req.GetSource()}
+	}
+	if req.GetRequestedSecurityProfile() != "" {
+		body["requested_security_profile"] = req.GetRequestedSecurityProfile()
+	}
+	if req.GetInputsFromRun() != "" {
+		body["inputs_from_run"] = req.GetInputsFromRun()
+	}
+	if len(req.GetLabels()) > 0 {
+		body["labels"] = req.GetLabels()
+	}
+	if req.GetOverlay() != "" {
+		body["overlay"] = req.GetOverlay()
+	}
+	if req.GetStartAt() != nil {
+		body["start_at"] = req.GetStartAt().AsTime()
+	}
+	header := http.Header{}
+	if key := req.GetIdempotencyKey(); key != "" {
+		header.Set("Idempotency-Key", key)
+	}
+	rec, err := s.call(ctx, http.MethodPost, "/runs", nil, body, header)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := rec.object()
+	if err != nil {
+		return nil, err
+	}
+	return runMessage(doc)
+}
+
+// GetRun serves GET /runs/{id}.
+func (s *Server) GetRun(ctx context.Context, req *flowdv1.GetRunRequest) (*flowdv1.Run, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	rec, err := s.call(ctx, http.MethodGet, "/runs/"+url.PathEscape(req.GetId()), nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := rec.object()
+	if err != nil {
+		return nil, err
+	}
+	return runMessage(doc)
+}
+
+// ListRuns serves GET /runs.
+func (s *Server) ListRuns(ctx context.Context, req *flowdv1.ListRunsRequest) (*flowdv1.ListRunsResponse, error) {
+	query := url.Values{}
+	setQuery(query, "job_id", req.GetJobId())
+	setQuery(query, "status", req.GetStatus())
+	setQuery(query, "schedule_id", req.GetScheduleId())
+	setQuery(query, "sort", req.GetSort())
+	setPageQuery(query, req.GetPage(), req.GetPerPage())
+	for _, selector := range req.GetLabels() {
+		query.Add("label", selector)
+	}
+	rec, err := s.call(ctx, http.MethodGet, "/runs", query, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := rec.array()
+	if err != nil {
+		return nil, err
+	}
+	resp := &flowdv1.ListRunsResponse{Total: rec.total()}
+	for _, doc := range docs {
+		run, err := runMessage(doc)
+		if err != nil {
+			return nil, err
+		}
+		resp.Runs = append(resp.Runs, run)
+	}
+	return resp, nil
+}
+
+// StreamEvents serves GET /runs/{id}/events, or GET /events without a run
+// ID, relaying each server-sent event as a message.
+func (s *Server) StreamEvents(req *flowdv1.StreamEventsRequest, stream grpc.ServerStreamingServer[flowdv1.Event]) error {
+	path := "/events"
+	query := url.Values{}
+	if req.GetRunId() != "" {
+		path = "/runs/" + url.PathEscape(req.GetRunId()) + "/events"
+		setQuery(query, "replay", req.GetReplay())
+	}
+	setQuery(query, "last_event_id", req.GetLastEventId())
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	r, err := s.newRequest(ctx, http.MethodGet, path, query, nil, nil)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Accept", "text/event-stream")
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	w := &streamWriter{header: http.Header{}, pipe: pw}
+	go func() {
+		s.handler.ServeHTTP(w, r)
+		_ = pw.Close()
+	}()
+
+	reader := bufio.NewReader(pr)
+	for {
+		ev, err := readEvent(reader)
+		if errors.Is(err, io.EOF) {
+			// The handler has returned, so its status is settled.
+			if w.status >= http.StatusBadRequest {
+				return problemError(w.status, w.problem.Bytes(), stream.SetTrailer)
+			}
+			if ctx.Err() != nil {
+				return status.FromContextError(ctx.Err()).Err()
+			}
+			return nil
+		}
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if err := stream.Send(ev); err != nil {
+			return err
+		}
+	}
+}
+
+// CreatePlan serves POST /plans.
+func (s *Server) CreatePlan(ctx context.Context, req *flowdv1.CreatePlanRequest) (*flowdv1.Plan, error) {
+	body := map[string]any{"job_id": req.GetJobId()}
+	if req.GetArgs() != nil {
+		body["args"] = req.GetArgs().AsMap()
+	}
+	if req.GetSource() != "" {
+		body["source"] = map[string]any{"name": req.GetSource()}
+	}
+	if req.GetRequestedSecurityProfile() != "" {
+		body["requested_security_profile"] = req.GetRequestedSecurityProfile()
+	}
+	if req.GetOverlay() != "" {
+		body["overlay"] = req.GetOverlay()
+	}
+	rec, err := s.call(ctx, http.MethodPost, "/plans", nil, body, nil)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := rec.object()
+	if err != nil {
+		return nil, err
+	}
+	document, err := toStruct(doc)
+	if err != nil {
+		return nil, err
+	}
+	jobID := stringField(doc, "job_id")
+	if jobID == "" {
+		jobID = req.GetJobId()
+	}
+	return &flowdv1.Plan{JobId: jobID, Document: document}, nil
+}
+
+// ListJobs serves GET /jobs.
+func (s *Server) ListJobs(ctx context.Context, req *flowdv1.ListJobsRequest) (*flowdv1.ListJobsResponse, error) {
+	query := url.Values{}
+	setQuery(query, "sort", req.GetSort())
+	setPageQuery(query, req.GetPage(), req.GetPerPage())
+	rec, err := s.call(ctx, http.MethodGet, "/jobs", query, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := rec.array()
+	if err != nil {
+		return nil, err
+	}
+	resp := &flowdv1.ListJobsResponse{Total: rec.total()}
+	for _, doc := range docs {
+		document, err := toStruct(doc)
+		if err != nil {
+			return nil, err
+		}
+		job := &flowdv1.Job{
+			Id:          stringField(doc, "id"),
+			Name:        stringField(doc, "name"),
+			Description: stringField(doc, "description"),
+			Document:    document,
+		}
+		if source, ok := doc["source"].(map[string]any); ok {
+			job.Source = stringField(source, "name")
+		}
+		resp.Jobs = append(resp.Jobs, job)
+	}
+	return resp, nil
+}
+
+// ListSources serves GET /sources.
+func (s *Server) ListSources(ctx context.Context, _ *flowdv1.ListSourcesRequest) (*flowdv1.ListSourcesResponse, error) {
+	rec, err := s.call(ctx, http.MethodGet, "/sources", nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := rec.array()
+	if err != nil {
+		return nil, err
+	}
+	resp := &flowdv1.ListSourcesResponse{}
+	for _, doc := range docs {
+		source, err := sourceMessage(doc)
+		if err != nil {
+			return nil, err
+		}
+		resp.Sources = append(resp.Sources, source)
+	}
+	return resp, nil
+}
+
+// GetSource serves GET /sources/{name}.
+func (s *Server) GetSource(ctx context.Context, req *flowdv1.GetSourceRequest) (*flowdv1.Source, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	rec, err := s.call(ctx, http.MethodGet, "/sources/"+url.PathEscape(req.GetName()), nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := rec.object()
+	if err != nil {
+		return nil, err
+	}
+	return sourceMessage(doc)
+}
+
+// PutSource serves POST /sources.
+func (s *Server) PutSource(ctx context.Context, req *flowdv1.PutSourceRequest) (*flowdv1.Source, error) {
+	body := map[string]any{}
+	if req.GetOptions() != nil {
+		body = req.GetOptions().AsMap()
+	}
+	body["name"] = req.GetName()
+	for key, value := range map[string]string{
+		"type":        req.GetType(),
+		"ref":         req.GetRef(),
+		"url":         req.GetUrl(),
+		"trust_level": req.GetTrustLevel(),
+		"pull_policy": req.GetPullPolicy(),
+	} {
+		if value != "" {
+			body[key] = value
+		}
+	}
+	if req.GetVerifySignatures() {
+		body["verify_signatures"] = true
+	}
+	rec, err := s.call(ctx, http.MethodPost, "/sources", nil, body, nil)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := rec.object()
+	if err != nil {
+		return nil, err
+	}
+	return sourceMessage(doc)
+}
+
+// DeleteSource serves DELETE /sources/{name}.
+func (s *Server) DeleteSource(ctx context.Context, req *flowdv1.DeleteSourceRequest) (*flowdv1.DeleteSourceResponse, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	if _, err := s.call(ctx, http.MethodDelete, "/sources/"+url.PathEscape(req.GetName()), nil, nil, nil); err != nil {
+		return nil, err
+	}
+	return &flowdv1.DeleteSourceResponse{}, nil
+}
+
+// call runs one request through the handler and turns error responses into
+// gRPC statuses.
+func (s *Server) call(ctx context.Context, method, path string, query url.Values, body any, header http.Header) (*recorder, error) {
+	r, err := s.newRequest(ctx, method, path, query, body, header)
+	if err != nil {
+		return nil, err
+	}
+	rec := &recorder{header: http.Header{}}
+	s.handler.ServeHTTP(rec, r)
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	if rec.status >= http.StatusBadRequest {
+		return nil, problemError(rec.status, rec.body.Bytes(), func(md metadata.MD) {
+			_ = grpc.SetTrailer(ctx, md)
+		})
+	}
+	return rec, nil
+}
+
+// newRequest builds the HTTP request for a call, carrying over the caller's
+// authorization metadata and address.
+func (s *Server) newRequest(ctx context.Context, method, path string, query url.Values, body any, header http.Header) (*http.Request, error) {
+	target := path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		reader = bytes.NewReader(data)
+	}
+	r, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	for key, values := range header {
+		r.Header[key] = values
+	}
+	if body != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	if r.Header.Get("Accept") == "" {
+		r.Header.Set("Accept", "application/json")
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			r.Header.Set("Authorization", values[0])
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		r.RemoteAddr = p.Addr.String()
+	}
+	return r, nil
+}
+
+// problemError converts an HTTP error response into a gRPC status and sets
+// the problem document as the ProblemTrailer trailer.
+func problemError(code int, body []byte, setTrailer func(metadata.MD)) error {
+	var prob struct {
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+	}
+	_ = json.Unmarshal(body, &prob)
+	msg := prob.Title
+	if msg == "" {
+		msg = http.StatusText(code)
+	}
+	if prob.Detail != "" {
+		msg += ": " + prob.Detail
+	}
+	if len(body) > 0 {
+		setTrailer(metadata.Pairs(ProblemTrailer, string(body)))
+	}
+	return status.Error(grpcCode(code), msg)
+}
+
+// grpcCode maps an HTTP error status onto the closest gRPC code.
+func grpcCode(code int) codes.Code {
+	switch code {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusGone, http.StatusPreconditionFailed, http.StatusPreconditionRequired:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	if code >= http.StatusInternalServerError {
+		return codes.Internal
+	}
+	return codes.Unknown
+}
+
+func runMessage(doc map[string]any) (*flowdv1.Run, error) {
+	document, err := toStruct(doc)
+	if err != nil {
+		return nil, err
+	}
+	run := &flowdv1.Run{
+		Id:         stringField(doc, "id"),
+		JobId:      stringField(doc, "job_id"),
+		Status:     stringField(doc, "status"),
+		StartedAt:  timeField(doc, "started_at"),
+		FinishedAt: timeField(doc, "finished_at"),
+		Document:   document,
+	}
+	if labels, ok := doc["labels"].(map[string]any); ok {
+		run.Labels = make(map[string]string, len(labels))
+		for key, value := range labels {
+			if text, ok := value.(string); ok {
+				run.Labels[key] = text
+			}
+		}
+	}
+	return run, nil
+}
+
+func sourceMessage(doc map[string]any) (*flowdv1.Source, error) {
+	document, err := toStruct(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &flowdv1.Source{
+		Name:       stringField(doc, "name"),
+		Type:       stringField(doc, "type"),
+		Ref:        stringField(doc, "ref"),
+		Url:        stringField(doc, "url"),
+		TrustLevel: stringField(doc, "trust_level"),
+		Document:   document,
+	}, nil
+}
+
+func toStruct(doc map[string]any) (*structpb.Struct, error) {
+	out, err := structpb.NewStruct(doc)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return out, nil
+}
+
+func stringField(doc map[string]any, key string) string {
+	value, _ := doc[key].(string)
+	return value
+}
+
+func timeField(doc map[string]any, key string) *timestamppb.Timestamp {
+	value, ok := doc[key].(string)
+	if !ok {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil || parsed.IsZero() {
+		return nil
+	}
+	return timestamppb.New(parsed)
+}
+
+func setQuery(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+func setPageQuery(query url.Values, page, perPage int32) {
+	if page > 0 {
+		query.Set("page", strconv.Itoa(int(page)))
+	}
+	if perPage > 0 {
+		query.Set("per_page", strconv.Itoa(int(perPage)))
+	}
+}
+
+// readEvent reads the next server-sent event from r, skipping comments and
+// keep-alives. Data that is not a JSON object is wrapped as {"data": ...}.
+func readEvent(r *bufio.Reader) (*flowdv1.Event, error) {
+	var (
+		ev   flowdv1.Event
+		data []string
+		seen bool
+	)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if !seen {
+				continue
+			}
+			break
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		seen = true
+		switch field {
+		case "id":
+			ev.Id = value
+		case "event":
+			ev.Event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	raw := strings.Join(data, "\n")
+	payload := map[string]any{}
+	if err := json.Unmarshal([]byte(raw), &payload); err != nil || payload == nil {
+		payload = map[string]any{"data": raw}
+	}
+	out, err := structpb.NewStruct(payload)
+	if err != nil {
+		return nil, err
+	}
+	ev.Data = out
+	return &ev, nil
+}
+
+// recorder buffers a complete handler response.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header { return r.header }
+
+func (r *recorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(p)
+}
+
+func (r *recorder) object() (map[string]any, error) {
+	doc := map[string]any{}
+	if err := json.Unmarshal(r.body.Bytes(), &doc); err != nil {
+		return nil, status.Errorf(codes.Internal, "decode response: %v", err)
+	}
+	return doc, nil
+}
+
+func (r *recorder) array() ([]map[string]any, error) {
+	var docs []map[string]any
+	if err := json.Unmarshal(r.body.Bytes(), &docs); err != nil {
+		return nil, status.Errorf(codes.Internal, "decode response: %v", err)
+	}
+	return docs, nil
+}
+
+func (r *recorder) total() int64 {
+	total, _ := strconv.ParseInt(r.header.Get(headers.TotalCount), 10, 64)
+	return total
+}
+
+// streamWriter relays a successful event stream through a pipe and keeps
+// the body of an error response for problemError.
+type streamWriter struct {
+	header  http.Header
+	status  int
+	pipe    *io.PipeWriter
+	problem bytes.Buffer
+}
+
+func (w *streamWriter) Header() http.Header { return w.header }
+
+func (w *streamWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if w.status >= http.StatusBadRequest {
+		return w.problem.Write(p)
+	}
+	return w.pipe.Write(p)
+}
+
+// Flush is a no-op: every Write already reaches the reader.
+func (w *streamWriter) Flush() {}
@@ -0,0 +1,164 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	flowdv1 "github.com/flowd-org/flowd/api/flowd/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) flowdv1.FlowdClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	New(handler).Register(srv)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return flowdv1.NewFlowdClient(conn)
+}
+
+func TestCreateRunTranslatesToPostRuns(t *testing.T) {
+	var (
+		gotAuth, gotKey string
+		gotBody         map[string]any
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/runs" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		gotAuth = r.Header.Get("Authorization")
+		gotKey = r.Header.Get("Idempotency-Key")
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"id":"run-1","job_id":"build","status":"queued","started_at":"2026-03-01T10:00:00Z","labels":{"team":"core"},"executor":"proc"}`))
+	})
+	client := newTestClient(t, handler)
+
+	args, _ := structpb.NewStruct(map[string]any{"target": "prod"})
+	startAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer runs:write")
+	run, err := client.CreateRun(ctx, &flowdv1.CreateRunRequest{
+		JobId:          "build",
+		Args:           args,
+		IdempotencyKey: "key-1",
+		Source:         "tools",
+		Labels:         map[string]string{"team": "core"},
+		StartAt:        timestamppb.New(startAt),
+	})
+	if err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	if gotAuth != "Bearer runs:write" || gotKey != "key-1" {
+		t.Fatalf("expected auth and idempotency headers, got %q %q", gotAuth, gotKey)
+	}
+	if gotBody["job_id"] != "build" || gotBody["start_at"] != "2026-03-01T12:00:00Z" {
+		t.Fatalf("unexpected body %v", gotBody)
+	}
+	if src, _ := gotBody["source"].(map[string]any); src["name"] != "tools" {
+		t.Fatalf("expected source reference, got %v", gotBody["source"])
+	}
+	if run.GetId() != "run-1" || run.GetStatus() != "queued" || run.GetLabels()["team"] != "core" {
+		t.Fatalf("unexpected run %v", run)
+	}
+	if !run.GetStartedAt().AsTime().Equal(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected started_at %v", run.GetStartedAt())
+	}
+	if run.GetDocument().GetFields()["executor"].GetStringValue() != "proc" {
+		t.Fatalf("expected full payload in document, got %v", run.GetDocument())
+	}
+}
+
+func TestCallMapsProblemsToStatus(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"title":"job concurrency limit reached","status":409,"code":"run.concurrency_limited"}`))
+	})
+	client := newTestClient(t, handler)
+
+	var trailer metadata.MD
+	_, err := client.CreateRun(context.Background(), &flowdv1.CreateRunRequest{JobId: "build"}, grpc.Trailer(&trailer))
+	st, _ := status.FromError(err)
+	if st.Code() != codes.Aborted || st.Message() != "job concurrency limit reached" {
+		t.Fatalf("expected Aborted with problem title, got %v", err)
+	}
+	var problem map[string]any
+	if values := trailer.Get(ProblemTrailer); len(values) != 1 || json.Unmarshal([]byte(values[0]), &problem) != nil {
+		t.Fatalf("expected problem trailer, got %v", trailer)
+	}
+	if problem["code"] != "run.concurrency_limited" {
+		t.Fatalf("unexpected problem %v", problem)
+	}
+}
+
+func TestStreamEventsRelaysServerSentEvents(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/runs/run-1/events" || r.URL.Query().Get("last_event_id") != "3" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"title":"run not found","status":404}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(":connected\n\n"))
+		_, _ = w.Write([]byte("id: 4\nevent: step.log\ndata: {\"line\":\"hello\"}\n\n"))
+		_, _ = w.Write([]byte(":keep-alive\n\n"))
+		_, _ = w.Write([]byte("id: 5\nevent: run.finish\ndata: plain\n\n"))
+	})
+	client := newTestClient(t, handler)
+
+	stream, err := client.StreamEvents(context.Background(), &flowdv1.StreamEventsRequest{RunId: "run-1", LastEventId: "3"})
+	if err != nil {
+		t.Fatalf("stream events: %v", err)
+	}
+	var events []*flowdv1.Event
+	for {
+		ev, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		events = append(events, ev)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %v", events)
+	}
+	if events[0].GetId() != "4" || events[0].GetEvent() != "step.log" || events[0].GetData().GetFields()["line"].GetStringValue() != "hello" {
+		t.Fatalf("unexpected first event %v", events[0])
+	}
+	if events[1].GetData().GetFields()["data"].GetStringValue() != "plain" {
+		t.Fatalf("expected non-JSON data to be wrapped, got %v", events[1])
+	}
+
+	missing, err := client.StreamEvents(context.Background(), &flowdv1.StreamEventsRequest{RunId: "other"})
+	if err != nil {
+		t.Fatalf("stream events: %v", err)
+	}
+	if _, err := missing.Recv(); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+}
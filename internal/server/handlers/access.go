@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+// jobVisible reports whether the caller may see and use job id of source,
+// empty for jobs under the scripts root. Add-on job IDs name their source
+// in a "<source>/" prefix.
+func jobVisible(ctx context.Context, id, source string) bool {
+	if source == "" {
+		if prefix, _, ok := strings.Cut(id, "/"); ok {
+			source = prefix
+		}
+	}
+	return requestctx.JobAllowed(ctx, id) && (source == "" || requestctx.SourceAllowed(ctx, source))
+}
+
+// RunVisible reports whether the caller may see and act on run, judged by
+// its job and source. Runs outside the caller's grant are reported as not
+// found.
+func RunVisible(ctx context.Context, run runstore.Run) bool {
+	return jobVisible(ctx, run.JobID, runSourceName(run))
+}
+
+// visibleRuns drops the runs the caller may not see.
+func visibleRuns(ctx context.Context, runs []runstore.Run) []runstore.Run {
+	if requestctx.AccessFromContext(ctx) == nil {
+		return runs
+	}
+	visible := runs[:0:0]
+	for _, run := range runs {
+		if RunVisible(ctx, run) {
+			visible = append(visible, run)
+		}
+	}
+	return visible
+}
+
+// runSourceName returns the name of the source run's job came from. Runs of
+// scripts-root jobs record a local provenance source named after the job,
+// which is not a registered source.
+func runSourceName(run runstore.Run) string {
+	src, _ := run.Provenance["source"].(map[string]any)
+	name, _ := src["name"].(string)
+	if name == run.JobID {
+		return ""
+	}
+	return name
+}
+
+// eventVisible reports whether the caller may receive a formatted SSE
+// message. Messages about runs are held to RunVisible; runs no longer in
+// store are withheld.
+func eventVisible(ctx context.Context, store *runstore.Store, msg []byte) bool {
+	if requestctx.AccessFromContext(ctx) == nil {
+		return true
+	}
+	var data []byte
+	for _, line := range bytes.Split(msg, []byte("\n")) {
+		if rest, ok := bytes.CutPrefix(line, []byte("data: ")); ok {
+			data = append(append(data, rest...), '\n')
+		}
+	}
+	var payload struct {
+		RunID string `json:"run_id"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil || payload.RunID == "" {
+		return true
+	}
+	run, ok := store.Get(payload.RunID)
+	return ok && RunVisible(ctx, run)
+}
+
+// sourceAccessProblem refuses adding or updating a source the caller may not
+// use. Confined callers must name the source, since a derived name is only
+// known once the source has been fetched.
+func sourceAccessProblem(ctx context.Context, name string) *response.Problem {
+	if requestctx.SourceAllowed(ctx, name) {
+		return nil
+	}
+	if name == "" {
+		prob := response.New(http.StatusForbidden, "source name required",
+			response.WithExtension("code", "source.forbidden"),
+			response.WithDetail("callers confined to some sources must name the source explicitly"))
+		return &prob
+	}
+	prob := response.New(http.StatusForbidden, "source not allowed",
+		response.WithExtension("code", "source.forbidden"),
+		response.WithDetail(fmt.Sprintf("source %s is outside the caller's access rules", name)))
+	return &prob
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/settings"
+	"github.com/flowd-org/flowd/internal/server/sse"
+)
+
+// AdminSettingsConfig configures the admin settings handler.
+type AdminSettingsConfig struct {
+	Settings *settings.Store
+	// Events receives settings.changed notifications (typically the global
+	// event stream).
+	Events EventSink
+}
+
+// NewAdminSettingsHandler serves GET/PUT /admin/settings. PUT accepts a partial
+// update; each applied change is audited in the server log and announced as a
+// settings.changed event.
+func NewAdminSettingsHandler(cfg AdminSettingsConfig) http.Handler {
+	store := cfg.Settings
+	if store == nil {
+		store = settings.New(settings.Defaults())
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeSettings(w, store.Get())
+		case http.MethodPut:
+			patch, err := decodeSettingsPatch(r.Body)
+			if err != nil {
+				response.Write(w, response.New(http.StatusBadRequest, "invalid request body", response.WithDetail(err.Error())))
+				return
+			}
+			after, changes, err := store.Apply(patch)
+			if err != nil {
+				response.Write(w, response.New(http.StatusUnprocessableEntity, "invalid settings", response.WithDetail(err.Error())))
+				return
+			}
+			if len(changes) > 0 {
+				principal, _ := requestctx.Principal(r.Context())
+				if logger := requestctx.Logger(r.Context()); logger != nil {
+					logger.Info("admin.settings.changed",
+						slog.String("principal", principal),
+						slog.Any("changes", changes),
+					)
+				}
+				publishSettingsChanged(cfg.Events, principal, after, changes)
+			}
+			writeSettings(w, after)
+		default:
+			response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+		}
+	})
+}
+
+func decodeSettingsPatch(body io.ReadCloser) (settings.Patch, error) {
+	defer body.Close()
+	var patch settings.Patch
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return patch, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&patch); err != nil {
+		return patch, err
+	}
+	return patch, nil
+}
+
+func publishSettingsChanged(sink EventSink, principal string, after settings.Values, changes []settings.Change) {
+	if sink == nil {
+		return
+	}
+	payload := map[string]any{
+		"changes":   changes,
+		"settings":  after,
+		"timestamp": time.Now().UTC(),
+	}
+	if principal != "" {
+		payload["principal"] = principal
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	sink.Publish("", sse.Event{Event: "settings.changed", Data: string(data)})
+}
+
+func writeSettings(w http.ResponseWriter, values settings.Values) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		response.Write(w, response.New(http.StatusInternalServerError, "encode settings failed", response.WithDetail(err.Error())))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/server/settings"
+)
+
+func TestAdminSettingsHandlerGetAndPut(t *testing.T) {
+	store := settings.New(settings.Defaults())
+	sink := &recordingSink{}
+	h := NewAdminSettingsHandler(AdminSettingsConfig{Settings: store, Events: sink})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/settings", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var got settings.Values
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got != settings.Defaults() {
+		t.Fatalf("unexpected defaults %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	body := `{"log_level":"debug","debug_events":true}`
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/settings", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if v := store.Get(); v.LogLevel != "debug" || !v.DebugEvents {
+		t.Fatalf("settings not applied: %+v", v)
+	}
+	if sink.countBy("settings.changed") != 1 {
+		t.Fatalf("expected settings.changed event, got %+v", sink.snapshot())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/settings", strings.NewReader(body)))
+	if rec.Code != http.StatusOK || sink.countBy("settings.changed") != 1 {
+		t.Fatalf("expected unchanged PUT to emit no event, got %d events", sink.countBy("settings.changed"))
+	}
+}
+
+func TestAdminSettingsHandlerRejectsInvalid(t *testing.T) {
+	h := NewAdminSettingsHandler(AdminSettingsConfig{})
+	for body, want := range map[string]int{
+		`{"log_level":"loud"}`: http.StatusUnprocessableEntity,
+		`{"unknown":true}`:     http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/settings", strings.NewReader(body)))
+		if rec.Code != want {
+			t.Fatalf("body %s: expected %d, got %d", body, want, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/metrics"
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+const (
+	defaultAdmissionTimeout = 5 * time.Second
+	// maxAdmissionResponseBytes bounds the review read back from the webhook.
+	maxAdmissionResponseBytes = 1 << 20
+)
+
+// AdmissionWebhookConfig configures the validation webhook consulted before
+// a run is admitted. It receives the candidate plan and may deny the run or
+// add findings, so organisations can enforce their own rules without
+// rebuilding flowd. Admission is skipped when URL is empty.
+type AdmissionWebhookConfig struct {
+	URL string
+	// Timeout bounds each call; zero means 5s.
+	Timeout time.Duration
+	// FailOpen admits runs with a warning finding when the webhook cannot be
+	// reached or answers badly. By default such runs are refused.
+	FailOpen bool
+	Client   *http.Client
+}
+
+// admissionReview is the document POSTed to the admission webhook.
+type admissionReview struct {
+	JobID           string            `json:"job_id"`
+	RequestedID     string            `json:"requested_id,omitempty"`
+	Principal       string            `json:"principal,omitempty"`
+	SecurityProfile string            `json:"security_profile"`
+	Executor        string            `json:"executor"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Provenance      map[string]any    `json:"provenance,omitempty"`
+	Plan            types.Plan        `json:"plan"`
+}
+
+// admissionVerdict is the webhook's answer. Any denial refuses the run;
+// findings are added to the plan's policy findings.
+type admissionVerdict struct {
+	Denials  []admissionDenial `json:"denials"`
+	Findings []types.Finding   `json:"findings"`
+}
+
+type admissionDenial struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// admissionWebhook calls the configured validation webhook.
+type admissionWebhook struct {
+	cfg AdmissionWebhookConfig
+}
+
+// newAdmissionWebhook returns nil when no URL is configured.
+func newAdmissionWebhook(cfg AdmissionWebhookConfig) *admissionWebhook {
+	if strings.TrimSpace(cfg.URL) == "" {
+		return nil
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultAdmissionTimeout
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{}
+	}
+	return &admissionWebhook{cfg: cfg}
+}
+
+// admit submits review and folds the verdict into the policy decision flow:
+// denials refuse the run, findings are returned for the plan and every
+// outcome is recorded as a decision on the "admission" subject.
+func (a *admissionWebhook) admit(ctx context.Context, review admissionReview) ([]types.Finding, []policyDecision, *response.Problem) {
+	if a == nil {
+		return nil, nil, nil
+	}
+	var decisions []policyDecision
+	record := func(decision, code, reason string) {
+		requestctx.LogPolicyDecision(ctx, "admission", decision, code, reason)
+		if decision == "denied" {
+			metrics.Default.RecordPolicyDenial(code)
+		}
+		decisions = append(decisions, policyDecision{Subject: "admission", Decision: decision, Code: code, Reason: reason})
+	}
+
+	verdict, err := a.review(ctx, review)
+	if err != nil {
+		detail := fmt.Sprintf("admission webhook: %v", err)
+		if !a.cfg.FailOpen {
+			record("denied", "admission.unavailable", detail)
+			prob := response.New(http.StatusServiceUnavailable, "admission webhook unavailable",
+				response.WithExtension("code", "admission.unavailable"),
+				response.WithDetail(detail))
+			return nil, decisions, &prob
+		}
+		record("warn", "admission.unavailable", detail)
+		return []types.Finding{{Code: "admission.unavailable", Level: "warning", Message: detail}}, decisions, nil
+	}
+
+	if len(verdict.Denials) > 0 {
+		messages := make([]string, 0, len(verdict.Denials))
+		for i, denial := range verdict.Denials {
+			if denial.Code == "" {
+				verdict.Denials[i].Code = "admission.denied"
+			}
+			record("denied", verdict.Denials[i].Code, denial.Message)
+			if denial.Message != "" {
+				messages = append(messages, denial.Message)
+			}
+		}
+		detail := "run denied by the admission webhook"
+		if len(messages) > 0 {
+			detail = strings.Join(messages, "; ")
+		}
+		prob := response.New(http.StatusUnprocessableEntity, "run denied by admission webhook",
+			response.WithExtension("code", "admission.denied"),
+			response.WithExtension("denials", verdict.Denials),
+			response.WithDetail(detail))
+		return nil, decisions, &prob
+	}
+
+	findings := make([]types.Finding, 0, len(verdict.Findings))
+	for _, finding := range verdict.Findings {
+		if finding.Code == "" {
+			continue
+		}
+		if finding.Level == "" {
+			finding.Level = "info"
+		}
+		findings = append(findings, finding)
+	}
+	record("allowed", "admission.allowed", fmt.Sprintf("admitted with %d finding(s)", len(findings)))
+	return findings, decisions, nil
+}
+
+// review makes one call. Transport errors, non-2xx statuses and
+// undecodable bodies are errors.
+func (a *admissionWebhook) review(ctx context.Context, review admissionReview) (admissionVerdict, error) {
+	body, err := json.Marshal(review)
+	if err != nil {
+		return admissionVerdict{}, fmt.Errorf("encode review: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, a.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return admissionVerdict{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "flowd-admission")
+	resp, err := a.cfg.Client.Do(req)
+	if err != nil {
+		return admissionVerdict{}, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAdmissionResponseBytes))
+	if err != nil {
+		return admissionVerdict{}, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return admissionVerdict{}, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	var verdict admissionVerdict
+	if err := json.Unmarshal(data, &verdict); err != nil {
+		return admissionVerdict{}, fmt.Errorf("decode response: %w", err)
+	}
+	return verdict, nil
+}
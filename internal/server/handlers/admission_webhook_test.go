@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+)
+
+func TestRunsHandlerAdmissionWebhook(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "demo", `
+version: v1
+job:
+  id: demo
+  name: Demo
+interpreter: bash
+`)
+	var (
+		reviews atomic.Value // admissionReview
+		verdict atomic.Value // string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var review admissionReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			t.Errorf("decode review: %v", err)
+		}
+		reviews.Store(review)
+		body := verdict.Load().(string)
+		if body == "" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	ctx := requestctx.WithPrincipal(context.Background(), "alice")
+	req := runRequest{JobID: "demo", Labels: map[string]string{"team": "payments"}}
+	h := NewRunsHandler(RunsConfig{Root: root, Admission: AdmissionWebhookConfig{URL: server.URL}})
+
+	verdict.Store(`{"findings":[{"code":"org.cost-center","level":"warning","message":"no cost center label"}]}`)
+	prep, prob := h.prepareRun(ctx, req)
+	if prob != nil {
+		t.Fatalf("expected the run to be admitted, got %+v", prob)
+	}
+	review := reviews.Load().(admissionReview)
+	if review.JobID != "demo" || review.Principal != "alice" || review.Labels["team"] != "payments" || review.Plan.JobID != "demo" {
+		t.Fatalf("unexpected review %+v", review)
+	}
+	findings := prep.plan.PolicyFindings
+	if len(findings) == 0 || findings[len(findings)-1].Code != "org.cost-center" {
+		t.Fatalf("expected the webhook finding in the plan, got %+v", findings)
+	}
+	if n := len(prep.decisions); n == 0 || prep.decisions[n-1].Code != "admission.allowed" {
+		t.Fatalf("expected an admission decision, got %+v", prep.decisions)
+	}
+
+	verdict.Store(`{"denials":[{"code":"org.freeze","message":"deploy freeze until Monday"}]}`)
+	_, prob = h.prepareRun(ctx, req)
+	if prob == nil || prob.Status != http.StatusUnprocessableEntity || prob.Ext["code"] != "admission.denied" || prob.Detail != "deploy freeze until Monday" {
+		t.Fatalf("expected an admission denial, got %+v", prob)
+	}
+
+	verdict.Store("")
+	_, prob = h.prepareRun(ctx, req)
+	if prob == nil || prob.Status != http.StatusServiceUnavailable || prob.Ext["code"] != "admission.unavailable" {
+		t.Fatalf("expected fail-closed admission, got %+v", prob)
+	}
+
+	h = NewRunsHandler(RunsConfig{Root: root, Admission: AdmissionWebhookConfig{URL: server.URL, FailOpen: true}})
+	prep, prob = h.prepareRun(ctx, req)
+	if prob != nil {
+		t.Fatalf("expected fail-open admission, got %+v", prob)
+	}
+	findings = prep.plan.PolicyFindings
+	if len(findings) == 0 || findings[len(findings)-1].Code != "admission.unavailable" || findings[len(findings)-1].Level != "warning" {
+		t.Fatalf("expected an unavailable warning, got %+v", findings)
+	}
+}
+
+func TestAdmissionWebhookTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	a := newAdmissionWebhook(AdmissionWebhookConfig{URL: server.URL, Timeout: 50 * time.Millisecond})
+	_, _, prob := a.admit(context.Background(), admissionReview{JobID: "demo"})
+	if prob == nil || prob.Ext["code"] != "admission.unavailable" {
+		t.Fatalf("expected a timed-out call to refuse the run, got %+v", prob)
+	}
+}
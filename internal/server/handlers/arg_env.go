@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/flowd-org/flowd/internal/engine"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// applyArgEnv exports binding's scalar args under the job's arg_env names,
+// refusing mappings that collide.
+func applyArgEnv(cfg *types.Config, spec *types.ArgSpec, binding *engine.Binding) *response.Problem {
+	if cfg == nil || cfg.ArgEnv == nil || spec == nil {
+		return nil
+	}
+	names, err := engine.ArgEnvNames(*spec, cfg)
+	if err != nil {
+		p := response.New(http.StatusUnprocessableEntity, "arg env mapping collides",
+			response.WithExtension("code", "arg_env.collision"),
+			response.WithDetail(err.Error()))
+		return &p
+	}
+	engine.ApplyArgEnv(binding, names)
+	return nil
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"sync"
+
+	"github.com/flowd-org/flowd/internal/server/metrics"
+	"github.com/flowd-org/flowd/internal/server/sse"
+)
+
+const defaultAsyncEventBuffer = 256
+
+// AsyncEventSinkConfig tunes the asynchronous event sink.
+type AsyncEventSinkConfig struct {
+	// BufferSize bounds the number of pending events per run. step.log events
+	// published while a run's buffer is full are dropped and counted; other
+	// events are always queued.
+	BufferSize int
+}
+
+// AsyncEventSink decouples event producers from live fan-out. Publish never
+// blocks: events are queued per run and delivered in order by a drain
+// goroutine that exits once the queue is empty, so a stalled subscriber
+// cannot hold up run execution. Only step.log events are dropped when a run
+// falls behind; lifecycle and terminal events always reach next, so
+// subscribers see every run end. The sink belongs behind the journal, which
+// must not lose events.
+type AsyncEventSink struct {
+	next EventSink
+	size int
+
+	mu     sync.Mutex
+	queues map[string]*eventQueue
+	closed bool
+	wg     sync.WaitGroup
+}
+
+type eventQueue struct {
+	events []sse.Event
+}
+
+// NewAsyncEventSink wraps next with bounded, non-blocking delivery.
+func NewAsyncEventSink(next EventSink, cfg AsyncEventSinkConfig) *AsyncEventSink {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultAsyncEventBuffer
+	}
+	return &AsyncEventSink{
+		next:   next,
+		size:   cfg.BufferSize,
+		queues: make(map[string]*eventQueue),
+	}
+}
+
+// Publish enqueues ev for runID without blocking.
+func (s *AsyncEventSink) Publish(runID string, ev sse.Event) {
+	if s == nil || s.next == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		metrics.Default.RecordEventDropped("closed")
+		return
+	}
+	queue, ok := s.queues[runID]
+	if !ok {
+		queue = &eventQueue{}
+		s.queues[runID] = queue
+		s.wg.Add(1)
+		go s.drain(runID, queue)
+	}
+	if ev.Event == "step.log" && len(queue.events) >= s.size {
+		metrics.Default.RecordEventDropped("buffer_full")
+		return
+	}
+	queue.events = append(queue.events, ev)
+}
+
+func (s *AsyncEventSink) drain(runID string, queue *eventQueue) {
+	defer s.wg.Done()
+	for {
+		// Publish enqueues under s.mu, so an empty queue observed here stays
+		// empty until the entry is removed and a new drain takes over.
+		s.mu.Lock()
+		if len(queue.events) == 0 {
+			delete(s.queues, runID)
+			s.mu.Unlock()
+			return
+		}
+		ev := queue.events[0]
+		queue.events[0] = sse.Event{}
+		queue.events = queue.events[1:]
+		s.mu.Unlock()
+		s.next.Publish(runID, ev)
+	}
+}
+
+// Close stops accepting events and waits for queued events to be delivered.
+func (s *AsyncEventSink) Close() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.wg.Wait()
+}
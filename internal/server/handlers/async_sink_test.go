@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/coredb"
+	"github.com/flowd-org/flowd/internal/server/metrics"
+	"github.com/flowd-org/flowd/internal/server/sse"
+)
+
+func TestAsyncEventSinkDoesNotBlockOnStalledConsumer(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var delivered []string
+	next := EventSinkFunc(func(runID string, ev sse.Event) {
+		<-release
+		mu.Lock()
+		delivered = append(delivered, ev.ID)
+		mu.Unlock()
+	})
+	sink := NewAsyncEventSink(next, AsyncEventSinkConfig{BufferSize: 4})
+	dropsBefore := metrics.Default.EventsDropped("buffer_full")
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 20; i++ {
+			sink.Publish("run-1", sse.Event{Event: "step.log", ID: strconv.Itoa(i)})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Publish blocked on stalled consumer")
+	}
+	if drops := metrics.Default.EventsDropped("buffer_full") - dropsBefore; drops == 0 {
+		t.Fatalf("expected dropped events to be counted")
+	}
+
+	close(release)
+	sink.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(delivered) == 0 {
+		t.Fatalf("expected queued events to be delivered")
+	}
+	for i := 1; i < len(delivered); i++ {
+		prev, _ := strconv.Atoi(delivered[i-1])
+		cur, _ := strconv.Atoi(delivered[i])
+		if cur <= prev {
+			t.Fatalf("events delivered out of order: %v", delivered)
+		}
+	}
+}
+
+func TestAsyncEventSinkCloseFlushesAndRejects(t *testing.T) {
+	var mu sync.Mutex
+	count := map[string]int{}
+	next := EventSinkFunc(func(runID string, ev sse.Event) {
+		mu.Lock()
+		count[runID]++
+		mu.Unlock()
+	})
+	sink := NewAsyncEventSink(next, AsyncEventSinkConfig{})
+	for i := 0; i < 10; i++ {
+		sink.Publish("run-a", sse.Event{Event: "step.log"})
+		sink.Publish("run-b", sse.Event{Event: "step.log"})
+	}
+	sink.Close()
+	sink.Publish("run-a", sse.Event{Event: "late"})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if count["run-a"] != 10 || count["run-b"] != 10 {
+		t.Fatalf("expected all events flushed, got %v", count)
+	}
+}
+
+func TestAsyncEventSinkKeepsTerminalEventsWhenFull(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var delivered []string
+	fanout := NewAsyncEventSink(EventSinkFunc(func(runID string, ev sse.Event) {
+		<-release
+		mu.Lock()
+		delivered = append(delivered, ev.Event)
+		mu.Unlock()
+	}), AsyncEventSinkConfig{BufferSize: 2})
+	journal := newTestJournal(t)
+	sink := NewJournalEventSink(journal, fanout)
+	dropsBefore := metrics.Default.EventsDropped("buffer_full")
+
+	sink.Publish("run-1", sse.Event{Event: "run.start", Data: "{}"})
+	for i := 0; i < 10; i++ {
+		sink.Publish("run-1", sse.Event{Event: "step.log", Data: "{}"})
+	}
+	sink.Publish("run-1", sse.Event{Event: "run.finish", Data: "{}"})
+	if drops := metrics.Default.EventsDropped("buffer_full") - dropsBefore; drops == 0 {
+		t.Fatalf("expected step.log events to be dropped from fan-out")
+	}
+
+	var journaled []string
+	err := journal.ForEach(context.Background(), "run-1", 0, func(entry coredb.JournalEntry) error {
+		journaled = append(journaled, entry.EventType)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("read journal: %v", err)
+	}
+	if len(journaled) != 12 || journaled[len(journaled)-1] != "run.finish" {
+		t.Fatalf("expected every event journaled, got %v", journaled)
+	}
+
+	close(release)
+	fanout.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(delivered) == 0 || delivered[0] != "run.start" || delivered[len(delivered)-1] != "run.finish" {
+		t.Fatalf("expected lifecycle events to reach subscribers, got %v", delivered)
+	}
+}
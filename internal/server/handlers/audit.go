@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/coredb"
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/response"
+)
+
+// Audit outcomes, derived from the response status.
+const (
+	AuditSucceeded = "succeeded"
+	AuditDenied    = "denied"
+	AuditFailed    = "failed"
+)
+
+// AuditEntry is one audited request: who sent it, what it asked for, when,
+// how it was answered and the policy decisions taken while serving it.
+type AuditEntry struct {
+	Seq       int64     `json:"seq"`
+	Timestamp time.Time `json:"timestamp"`
+	// Principal is the authenticated caller, empty for requests refused
+	// before authentication and for forge webhook deliveries.
+	Principal string `json:"principal"`
+	// Action is the method and templated route, e.g. "POST /runs/{id}:cancel".
+	Action     string `json:"action"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Status     int    `json:"status"`
+	Outcome    string `json:"outcome"`
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	// RequestSHA256 is the hex SHA-256 of the request body.
+	RequestSHA256 string                      `json:"request_sha256"`
+	Decisions     []requestctx.PolicyDecision `json:"decisions,omitempty"`
+}
+
+// AuditOutcome classifies a response status.
+func AuditOutcome(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return AuditDenied
+	case status >= 400:
+		return AuditFailed
+	default:
+		return AuditSucceeded
+	}
+}
+
+// AuditLog appends audited requests to the Core DB and serves them back.
+type AuditLog struct {
+	store *coredb.AuditStore
+}
+
+// NewAuditLog returns an audit log backed by store, or nil without a store.
+func NewAuditLog(store *coredb.AuditStore) *AuditLog {
+	if store == nil {
+		return nil
+	}
+	return &AuditLog{store: store}
+}
+
+// Record appends entry, assigning its sequence number.
+func (l *AuditLog) Record(ctx context.Context, entry AuditEntry) error {
+	if l == nil {
+		return nil
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode audit entry: %w", err)
+	}
+	_, err = l.store.Append(ctx, coredb.AuditRecord{
+		Timestamp: entry.Timestamp,
+		Principal: entry.Principal,
+		Action:    entry.Action,
+		Status:    entry.Status,
+		Data:      data,
+	})
+	return err
+}
+
+func decodeAuditEntry(rec coredb.AuditRecord) (AuditEntry, error) {
+	var entry AuditEntry
+	if err := json.Unmarshal(rec.Data, &entry); err != nil {
+		return AuditEntry{}, fmt.Errorf("decode audit entry %d: %w", rec.Seq, err)
+	}
+	entry.Seq = rec.Seq
+	return entry, nil
+}
+
+// NewAuditHandler serves GET /audit, a page of the audit log, and
+// GET /audit.ndjson, every matching entry as JSON lines. Both filter by
+// since, until, principal and action.
+func NewAuditHandler(log *AuditLog, pagination Pagination) http.Handler {
+	pagination = pagination.normalized()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+			return
+		}
+		if log == nil {
+			response.Write(w, response.New(http.StatusServiceUnavailable, "audit log unavailable",
+				response.WithDetail("the audit log needs the Core DB")))
+			return
+		}
+		query, prob := parseAuditQuery(r)
+		if prob != nil {
+			response.Write(w, *prob)
+			return
+		}
+		ctx := r.Context()
+		if strings.HasSuffix(r.URL.Path, ".ndjson") {
+			exportAudit(ctx, w, log, query)
+			return
+		}
+
+		page, perPage, err := pagination.parse(r)
+		if err != nil {
+			response.Write(w, response.New(http.StatusBadRequest, "invalid pagination", response.WithDetail(err.Error())))
+			return
+		}
+		total, err := log.store.Count(ctx, query)
+		if err != nil {
+			response.Write(w, response.New(http.StatusInternalServerError, "audit query failed", response.WithDetail(err.Error())))
+			return
+		}
+		query.Offset, query.Limit = (page-1)*perPage, perPage
+		entries := make([]AuditEntry, 0)
+		err = log.store.ForEach(ctx, query, func(rec coredb.AuditRecord) error {
+			entry, err := decodeAuditEntry(rec)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+		if err != nil {
+			response.Write(w, response.New(http.StatusInternalServerError, "audit query failed", response.WithDetail(err.Error())))
+			return
+		}
+		writePaginationHeaders(w, total, page, perPage)
+		writeJSON(w, entries, http.StatusOK)
+	})
+}
+
+func parseAuditQuery(r *http.Request) (coredb.AuditQuery, *response.Problem) {
+	q := r.URL.Query()
+	query := coredb.AuditQuery{
+		Principal: strings.TrimSpace(q.Get("principal")),
+		Action:    strings.TrimSpace(q.Get("action")),
+	}
+	for _, bound := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &query.Since}, {"until", &query.Until}} {
+		raw := strings.TrimSpace(q.Get(bound.name))
+		if raw == "" {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			prob := response.New(http.StatusBadRequest, "invalid time range",
+				response.WithExtension("code", "audit.range.invalid"),
+				response.WithDetail(fmt.Sprintf("%s must be an RFC 3339 timestamp", bound.name)))
+			return coredb.AuditQuery{}, &prob
+		}
+		*bound.dst = at
+	}
+	if !query.Since.IsZero() && !query.Until.IsZero() && !query.Until.After(query.Since) {
+		prob := response.New(http.StatusBadRequest, "invalid time range",
+			response.WithExtension("code", "audit.range.invalid"),
+			response.WithDetail("until must be after since"))
+		return coredb.AuditQuery{}, &prob
+	}
+	return query, nil
+}
+
+// exportAudit streams the entries matching query as JSON lines, oldest first.
+func exportAudit(ctx context.Context, w http.ResponseWriter, log *AuditLog, query coredb.AuditQuery) {
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.Header().Set("Cache-Control", exportCacheControl)
+	writer := bufio.NewWriter(w)
+	err := log.store.ForEach(ctx, query, func(rec coredb.AuditRecord) error {
+		entry, err := decodeAuditEntry(rec)
+		if err != nil {
+			return err
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(append(line, '\n')); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		response.Write(w, response.New(http.StatusInternalServerError, "audit export failed", response.WithDetail(err.Error())))
+	}
+}
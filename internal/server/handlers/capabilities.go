@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/flowd-org/flowd/internal/policy"
+	"github.com/flowd-org/flowd/internal/server/response"
+)
+
+// CapabilitiesConfig describes what the running server supports.
+type CapabilitiesConfig struct {
+	Version     string
+	SpecVersion string
+	Profile     string
+	Features    map[string]bool
+	Policy      *policy.Context
+	// MaxBodyBytes is the request body limit for JSON API endpoints.
+	MaxBodyBytes int64
+	// KVLimitBytes maps Rule-Y namespaces to their value size limits.
+	KVLimitBytes map[string]int64
+	MaxBatchRuns int
+	// Pagination bounds per_page on GET /runs and GET /jobs.
+	Pagination Pagination
+}
+
+type capabilitiesView struct {
+	Version           string           `json:"version"`
+	SpecVersion       string           `json:"spec_version,omitempty"`
+	SecurityProfile   string           `json:"security_profile"`
+	PolicyVersion     string           `json:"policy_version"`
+	Features          map[string]bool  `json:"features"`
+	AllowedRegistries []string         `json:"allowed_registries"`
+	Limits            capabilityLimits `json:"limits"`
+}
+
+type capabilityLimits struct {
+	MaxBodyBytes   int64            `json:"max_body_bytes"`
+	KVValueBytes   map[string]int64 `json:"kv_value_bytes,omitempty"`
+	MaxBatchRuns   int              `json:"max_batch_runs"`
+	DefaultPerPage int              `json:"default_per_page"`
+	MaxRunsPerPage int              `json:"max_runs_per_page"`
+}
+
+// NewCapabilitiesHandler serves GET /capabilities so clients can adapt to the
+// server's version, feature set and limits instead of probing endpoints.
+func NewCapabilitiesHandler(cfg CapabilitiesConfig) http.Handler {
+	maxBatch := cfg.MaxBatchRuns
+	if maxBatch <= 0 {
+		maxBatch = defaultMaxBatchRuns
+	}
+	pagination := cfg.Pagination.normalized()
+	registries := append([]string{}, cfg.Policy.AllowedRegistries()...)
+	sort.Strings(registries)
+	features := make(map[string]bool, len(cfg.Features))
+	for name, enabled := range cfg.Features {
+		features[name] = enabled
+	}
+	view := capabilitiesView{
+		Version:           cfg.Version,
+		SpecVersion:       cfg.SpecVersion,
+		SecurityProfile:   cfg.Profile,
+		PolicyVersion:     cfg.Policy.Version(),
+		Features:          features,
+		AllowedRegistries: registries,
+		Limits: capabilityLimits{
+			MaxBodyBytes:   cfg.MaxBodyBytes,
+			KVValueBytes:   cfg.KVLimitBytes,
+			MaxBatchRuns:   maxBatch,
+			DefaultPerPage: pagination.DefaultPerPage,
+			MaxRunsPerPage: pagination.MaxPerPage,
+		},
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+			return
+		}
+		data, err := json.Marshal(view)
+		if err != nil {
+			response.Write(w, response.New(http.StatusInternalServerError, "encode capabilities failed", response.WithDetail(err.Error())))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(data)
+	})
+}
@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/flowd-org/flowd/internal/configloader"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// loadJobConfig loads the config of the job in dir through load, with the
+// named environment overlay merged over it when overlay is set.
+func loadJobConfig(load func(dir, overlay string) (*types.Config, error), dir, overlay string) (*types.Config, *response.Problem) {
+	cfg, err := load(dir, overlay)
+	if overlay != "" && errors.Is(err, configloader.ErrOverlayNotFound) {
+		available, _ := configloader.Overlays(dir)
+		if available == nil {
+			available = []string{}
+		}
+		p := response.New(http.StatusUnprocessableEntity, "unknown overlay",
+			response.WithExtension("code", "overlay.not_found"),
+			response.WithExtension("overlay", overlay),
+			response.WithExtension("available", available),
+			response.WithDetail(err.Error()))
+		return nil, &p
+	}
+	if err != nil {
+		p := response.New(http.StatusInternalServerError, "load config failed", response.WithDetail(err.Error()))
+		return nil, &p
+	}
+	return cfg, nil
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/flowd-org/flowd/internal/engine"
+	stepexec "github.com/flowd-org/flowd/internal/executor"
 	"github.com/flowd-org/flowd/internal/policy"
 	policyverify "github.com/flowd-org/flowd/internal/policy/verify"
 	"github.com/flowd-org/flowd/internal/server/response"
@@ -49,8 +50,17 @@ func buildDAGPlan(ctx context.Context, jobID string, cfgObj *types.Config, spec
 			Name:     strings.TrimSpace(step.Name),
 			Executor: executor,
 		}
+		if shell, err := stepexec.ParseStepShell(step.Shell); err == nil {
+			preview.Shell = shell
+		}
+		if strings.TrimSpace(step.Workdir) != "" {
+			if workdir, err := stepexec.ParseStepWorkdir(step.Workdir); err == nil {
+				preview.Workdir = workdir.String()
+			}
+		}
+		preview.Timeout = step.Timeout
 
-		if executor == "container" {
+		if executor == "container" || executor == stepexec.ExecutorKubernetes {
 			image := strings.TrimSpace(merged.Image)
 			preview.ContainerImage = image
 			preview.Network = strings.TrimSpace(merged.Network)
@@ -79,6 +89,7 @@ func buildDAGPlan(ctx context.Context, jobID string, cfgObj *types.Config, spec
 				preview.ImageTrust = &types.ImageTrustPreview{
 					Image:    image,
 					Mode:     string(mode),
+					Backend:  outcome.Backend,
 					Verified: outcome.Verified,
 					Reason:   outcome.Reason,
 				}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	stepexec "github.com/flowd-org/flowd/internal/executor"
 	"github.com/flowd-org/flowd/internal/server/response"
 	"github.com/flowd-org/flowd/internal/types"
 )
@@ -37,10 +38,10 @@ func validateDAGConfig(cfg *types.Config) *response.Problem {
 			response.WithDetail("executor is required for DAG jobs"))
 		return &prob
 	}
-	if executor != "proc" && executor != "container" {
+	if executor != "proc" && executor != "container" && executor != stepexec.ExecutorKubernetes && executor != stepexec.ExecutorSSH {
 		prob := response.New(http.StatusUnprocessableEntity, "invalid dag configuration",
 			response.WithExtension("code", "E_CONFIG"),
-			response.WithDetail("executor must be proc or container for DAG jobs"))
+			response.WithDetail("executor must be proc, container, kubernetes or ssh for DAG jobs"))
 		return &prob
 	}
 	if len(cfg.Steps) == 0 {
@@ -63,6 +64,22 @@ func validateDAGConfig(cfg *types.Config) *response.Problem {
 				response.WithDetail(detailPrefix(idx)+"step-level executor is not permitted; set executor on job"))
 			return &prob
 		}
+		if strings.TrimSpace(step.Workdir) != "" {
+			if _, err := stepexec.ParseStepWorkdir(step.Workdir); err != nil {
+				prob := response.New(http.StatusUnprocessableEntity, "invalid dag step",
+					response.WithExtension("code", "E_CONFIG"),
+					response.WithDetail(detailPrefix(idx)+err.Error()))
+				return &prob
+			}
+		}
+		if strings.TrimSpace(step.Shell) != "" {
+			if _, err := stepexec.ParseStepShell(step.Shell); err != nil {
+				prob := response.New(http.StatusUnprocessableEntity, "invalid dag step",
+					response.WithExtension("code", "E_CONFIG"),
+					response.WithDetail(detailPrefix(idx)+err.Error()))
+				return &prob
+			}
+		}
 		id := strings.TrimSpace(step.ID)
 		if id != "" {
 			if _, exists := ids[id]; exists {
@@ -73,14 +90,14 @@ func validateDAGConfig(cfg *types.Config) *response.Problem {
 			}
 			ids[id] = struct{}{}
 		}
-		if executor == "proc" {
+		if executor == "proc" || executor == stepexec.ExecutorSSH {
 			if containerConfigHasSettings(step.Container) {
 				prob := response.New(http.StatusUnprocessableEntity, "invalid dag step",
 					response.WithExtension("code", "E_CONFIG"),
-					response.WithDetail(detailPrefix(idx)+"container settings are not allowed when executor is proc"))
+					response.WithDetail(detailPrefix(idx)+"container settings are not allowed when executor is "+executor))
 				return &prob
 			}
-		} else if executor == "container" {
+		} else {
 			if effectiveStepImage(step.Container, cfg.Container) == "" {
 				prob := response.New(http.StatusUnprocessableEntity, "invalid dag step",
 					response.WithExtension("code", "E_CONFIG"),
@@ -103,6 +120,12 @@ func validateDAGConfig(cfg *types.Config) *response.Problem {
 			}
 		}
 	}
+	if err := stepexec.CheckStepGraph(cfg.Steps); err != nil {
+		prob := response.New(http.StatusUnprocessableEntity, "invalid dag configuration",
+			response.WithExtension("code", "E_CONFIG"),
+			response.WithDetail(err.Error()))
+		return &prob
+	}
 	return nil
 }
 
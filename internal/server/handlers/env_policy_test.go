@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/paths"
+	"github.com/flowd-org/flowd/internal/policy"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+func TestRunStripsEnvDeniedByPolicy(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "envjob", `
+version: v1
+job:
+  id: envjob
+  name: Env Job
+interpreter: bash
+env:
+  APP_MODE: prod
+  AWS_REGION: eu-west-1
+`)
+	script := "echo \"mode=${APP_MODE:-unset} region=${AWS_REGION:-unset}\"\n"
+	if err := os.WriteFile(filepath.Join(root, "envjob", "100_main.sh"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	policyCtx, err := policy.NewContext(&policy.Bundle{Env: &policy.EnvRules{
+		Allow: []string{"PATH", "APP_*", "AWS_*"},
+		Deny:  []string{"AWS_*"},
+	}})
+	if err != nil {
+		t.Fatalf("policy context: %v", err)
+	}
+	sink := &recordingSink{}
+	h := NewRunsHandler(RunsConfig{Root: root, Store: runstore.New(), Events: sink, Profile: "secure", Policy: policyCtx})
+
+	rec := postRun(t, h, `{"job_id":"envjob"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	waitFor(func() bool { return sink.countBy("run.finish") >= 1 }, 2*time.Second, t)
+
+	var output string
+	for _, ev := range sink.snapshot() {
+		if ev.event.Event == "step.log" {
+			output += ev.event.Data
+		}
+	}
+	if !strings.Contains(output, "mode=prod region=unset") {
+		t.Fatalf("expected AWS_REGION to be stripped, got output %q", output)
+	}
+	var payload RunPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode run: %v", err)
+	}
+	var plan types.Plan
+	readJSONFile(t, filepath.Join(paths.RunDir(payload.ID), "plan.json"), &plan)
+	if len(plan.PolicyFindings) != 1 || plan.PolicyFindings[0].Code != "env.stripped" || !strings.Contains(plan.PolicyFindings[0].Message, "AWS_REGION") {
+		t.Fatalf("expected env.stripped finding for AWS_REGION only, got %+v", plan.PolicyFindings)
+	}
+
+	h = NewRunsHandler(RunsConfig{Root: root, Store: runstore.New(), Events: &recordingSink{}, Profile: "permissive", Policy: policyCtx})
+	rec = postRun(t, h, `{"job_id":"envjob"}`)
+	if rec.Code != http.StatusCreated || strings.Contains(rec.Body.String(), "env.stripped") {
+		t.Fatalf("expected env rules to be skipped under permissive, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/flowd-org/flowd/internal/metrics"
+	"github.com/flowd-org/flowd/internal/server/faults"
 	"github.com/flowd-org/flowd/internal/server/response"
 	"github.com/flowd-org/flowd/internal/server/runstore"
 	"github.com/flowd-org/flowd/internal/server/sse"
@@ -15,6 +17,8 @@ type EventsConfig struct {
 	RunStore  *runstore.Store
 	RunHub    *sse.Hub
 	GlobalHub *sse.Hub
+	// Streams limits open streams; nil leaves them unlimited.
+	Streams *StreamLimiter
 }
 
 // NewEventsHandler returns an SSE handler for GET /events.
@@ -31,6 +35,10 @@ func NewEventsHandler(cfg EventsConfig) http.Handler {
 	if globalHub == nil {
 		globalHub = sse.New(sse.Config{})
 	}
+	streams := cfg.Streams
+	if streams == nil {
+		streams = NewStreamLimiter(StreamLimits{})
+	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -48,7 +56,7 @@ func NewEventsHandler(cfg EventsConfig) http.Handler {
 		contextID := "global"
 
 		if runID != "" {
-			if _, ok := store.Get(runID); !ok {
+			if run, ok := store.Get(runID); !ok || !RunVisible(r.Context(), run) {
 				response.Write(w, response.New(http.StatusNotFound, "run not found", response.WithDetail(runID)))
 				return
 			}
@@ -58,6 +66,14 @@ func NewEventsHandler(cfg EventsConfig) http.Handler {
 			hub = globalHub
 		}
 
+		release, problem := streams.Acquire(r, runID)
+		if problem != nil {
+			response.Write(w, *problem)
+			return
+		}
+		defer release()
+		defer metrics.SSEStreamStarted()()
+
 		ctx := r.Context()
 		sub := hub.Subscribe(ctx, contextID, lastEventID)
 		defer sub.Close()
@@ -80,6 +96,12 @@ func NewEventsHandler(cfg EventsConfig) http.Handler {
 				if !ok {
 					return
 				}
+				if !eventVisible(ctx, store, msg) {
+					continue
+				}
+				if err := faults.Delay(ctx); err != nil {
+					return
+				}
 				if _, err := w.Write(msg); err != nil {
 					return
 				}
@@ -0,0 +1,211 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+const (
+	// FlakyActionFlag only reports flaky jobs.
+	FlakyActionFlag = "flag"
+	// FlakyActionRetry retries failed steps of flaky jobs once when the job
+	// does not configure retries itself.
+	FlakyActionRetry = "retry"
+	// FlakyActionAcknowledge refuses runs of flaky jobs that do not carry the
+	// flaky-ack label.
+	FlakyActionAcknowledge = "acknowledge"
+
+	// flakyAckLabel is the run label acknowledging a job is known to be flaky.
+	flakyAckLabel = "flaky-ack"
+	// defaultFlakyWindow is how many finished runs are scored when
+	// FlakinessPolicy leaves Window zero.
+	defaultFlakyWindow = 10
+	// minFlakyRuns is the fewest finished runs a job needs before it can be
+	// flagged, so a single failure after a success is not a flake.
+	minFlakyRuns = 4
+)
+
+// FlakinessPolicy flags jobs whose recent runs keep alternating between
+// success and failure.
+type FlakinessPolicy struct {
+	// Threshold is the share of consecutive finished runs whose outcome
+	// flipped, from 0 to 1, at which a job counts as flaky. Zero disables
+	// detection.
+	Threshold float64
+	// Window is how many of the job's latest finished runs are scored; zero
+	// uses 10.
+	Window int
+	// Action is FlakyActionFlag (the default), FlakyActionRetry or
+	// FlakyActionAcknowledge.
+	Action string
+}
+
+// Validate reports whether the threshold and action are usable.
+func (p FlakinessPolicy) Validate() error {
+	if p.Threshold < 0 || p.Threshold > 1 {
+		return fmt.Errorf("flaky threshold must be between 0 and 1")
+	}
+	if p.Window < 0 {
+		return fmt.Errorf("flaky window must not be negative")
+	}
+	switch p.Action {
+	case "", FlakyActionFlag, FlakyActionRetry, FlakyActionAcknowledge:
+		return nil
+	}
+	return fmt.Errorf("flaky action must be %s, %s or %s", FlakyActionFlag, FlakyActionRetry, FlakyActionAcknowledge)
+}
+
+// Enabled reports whether flaky jobs are detected at all.
+func (p FlakinessPolicy) Enabled() bool {
+	return p.Threshold > 0
+}
+
+// JobFlakiness scores a job's latest finished runs. Canceled runs are not
+// counted; failed and timed out runs are failures.
+type JobFlakiness struct {
+	Runs     int `json:"runs"`
+	Failures int `json:"failures"`
+	// Flips counts consecutive runs whose outcome differs.
+	Flips int     `json:"flips"`
+	Score float64 `json:"score"`
+	Flaky bool    `json:"flaky"`
+}
+
+// Score computes the flakiness of every job with finished runs.
+func (p FlakinessPolicy) Score(runs []runstore.Run) map[string]JobFlakiness {
+	byJob := make(map[string][]runstore.Run)
+	for _, run := range runs {
+		if run.Status == "completed" || run.Status == "failed" || run.Status == "timed_out" {
+			byJob[run.JobID] = append(byJob[run.JobID], run)
+		}
+	}
+	window := p.Window
+	if window <= 0 {
+		window = defaultFlakyWindow
+	}
+	out := make(map[string]JobFlakiness, len(byJob))
+	for jobID, jobRuns := range byJob {
+		slices.SortFunc(jobRuns, func(a, b runstore.Run) int {
+			return a.StartedAt.Compare(b.StartedAt)
+		})
+		if len(jobRuns) > window {
+			jobRuns = jobRuns[len(jobRuns)-window:]
+		}
+		var f JobFlakiness
+		f.Runs = len(jobRuns)
+		for i, run := range jobRuns {
+			if run.Status != "completed" {
+				f.Failures++
+			}
+			if i > 0 && (run.Status == "completed") != (jobRuns[i-1].Status == "completed") {
+				f.Flips++
+			}
+		}
+		if f.Runs > 1 {
+			f.Score = math.Round(float64(f.Flips)/float64(f.Runs-1)*100) / 100
+		}
+		f.Flaky = p.Enabled() && f.Runs >= minFlakyRuns && f.Score >= p.Threshold
+		out[jobID] = f
+	}
+	return out
+}
+
+// applyFlakiness scores jobID and applies the policy action to a run about to
+// be created. It returns the retries to force on the run and records flaky
+// jobs in the provenance.
+func (h *RunsHandler) applyFlakiness(jobID string, labels map[string]string, provenance map[string]any) (int, *response.Problem) {
+	if !h.flakiness.Enabled() {
+		return 0, nil
+	}
+	f, ok := h.flakiness.Score(h.store.List())[jobID]
+	if !ok || !f.Flaky {
+		return 0, nil
+	}
+	provenance["flakiness"] = f
+	switch h.flakiness.Action {
+	case FlakyActionRetry:
+		return 1, nil
+	case FlakyActionAcknowledge:
+		if _, acked := labels[flakyAckLabel]; !acked {
+			p := response.New(http.StatusConflict, "job is flaky",
+				response.WithExtension("code", "E_JOB_FLAKY"),
+				response.WithExtension("flakiness", f),
+				response.WithDetail(fmt.Sprintf("job %s flipped between success and failure in %d of its last %d runs; add the %s label to run it anyway", jobID, f.Flips, f.Runs, flakyAckLabel)))
+			return 0, &p
+		}
+	}
+	return 0, nil
+}
+
+// jobFlakiness returns the flakiness recorded for each job when detection is
+// enabled.
+func jobFlakiness(policy FlakinessPolicy, store *runstore.Store) map[string]JobFlakiness {
+	if !policy.Enabled() || store == nil {
+		return nil
+	}
+	return policy.Score(store.List())
+}
+
+// JobStats summarizes a job's finished runs for GET /stats.
+type JobStats struct {
+	JobID string `json:"job_id"`
+	JobFlakiness
+}
+
+// StatsPayload is the response body of GET /stats.
+type StatsPayload struct {
+	// Runs counts stored runs per status.
+	Runs map[string]int `json:"runs"`
+	// Jobs lists jobs with finished runs, flaky jobs first.
+	Jobs []JobStats `json:"jobs"`
+	// FlakyJobs counts jobs flagged as flaky.
+	FlakyJobs int `json:"flaky_jobs"`
+	// Schedules lists the success rate of schedules with finished runs.
+	Schedules []ScheduleStats `json:"schedules"`
+}
+
+// NewStatsHandler serves GET /stats: run counts per status, the reliability
+// of each job's latest finished runs and the success rate of each schedule.
+// Callers confined by RBAC only see the runs of jobs they may access.
+func NewStatsHandler(store *runstore.Store, policy FlakinessPolicy) http.Handler {
+	if store == nil {
+		store = runstore.New()
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+			return
+		}
+		runs := visibleRuns(r.Context(), store.List())
+		counts := make(map[string]int)
+		for _, run := range runs {
+			counts[run.Status]++
+		}
+		stats := StatsPayload{Runs: counts, Jobs: []JobStats{}, Schedules: scheduleStats(runs)}
+		for jobID, f := range policy.Score(runs) {
+			stats.Jobs = append(stats.Jobs, JobStats{JobID: jobID, JobFlakiness: f})
+			if f.Flaky {
+				stats.FlakyJobs++
+			}
+		}
+		slices.SortFunc(stats.Jobs, func(a, b JobStats) int {
+			if a.Flaky != b.Flaky {
+				if a.Flaky {
+					return -1
+				}
+				return 1
+			}
+			return strings.Compare(a.JobID, b.JobID)
+		})
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, stats, http.StatusOK)
+	})
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+// seedRuns stores finished runs of jobID with the given statuses, oldest
+// first.
+func seedRuns(store *runstore.Store, jobID string, statuses ...string) {
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	for i, status := range statuses {
+		started := base.Add(time.Duration(i) * time.Hour)
+		finished := started.Add(time.Minute)
+		store.Create(runstore.Run{
+			ID: jobID + "-" + string(rune('a'+i)), JobID: jobID, Status: status,
+			StartedAt: started, FinishedAt: &finished,
+		})
+	}
+}
+
+func TestFlakinessScore(t *testing.T) {
+	store := runstore.New()
+	seedRuns(store, "flaky", "completed", "failed", "completed", "canceled", "failed", "completed")
+	seedRuns(store, "broken", "completed", "failed", "failed", "failed", "failed")
+	seedRuns(store, "young", "completed", "failed")
+
+	scores := FlakinessPolicy{Threshold: 0.5}.Score(store.List())
+	if got := scores["flaky"]; got.Runs != 5 || got.Failures != 2 || got.Flips != 4 || got.Score != 1 || !got.Flaky {
+		t.Fatalf("unexpected flaky score %+v", got)
+	}
+	if got := scores["broken"]; got.Flips != 1 || got.Score != 0.25 || got.Flaky {
+		t.Fatalf("consistently failing job should not be flaky: %+v", got)
+	}
+	if got := scores["young"]; got.Score != 1 || got.Flaky {
+		t.Fatalf("job with too few runs should not be flaky: %+v", got)
+	}
+
+	windowed := FlakinessPolicy{Threshold: 0.5, Window: 4}.Score(store.List())
+	if got := windowed["broken"]; got.Runs != 4 || got.Flips != 0 {
+		t.Fatalf("expected window to drop the oldest run, got %+v", got)
+	}
+	if got := (FlakinessPolicy{}).Score(store.List())["flaky"]; got.Flaky {
+		t.Fatalf("disabled policy should not flag jobs: %+v", got)
+	}
+}
+
+func TestRunsHandlerFlakyJobActions(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "demo", `
+version: v1
+job:
+  id: demo
+  name: Demo
+interpreter: bash
+`)
+	store := runstore.New()
+	seedRuns(store, "demo", "completed", "failed", "completed", "failed")
+
+	h := NewRunsHandler(RunsConfig{Root: root, Store: store, Flakiness: FlakinessPolicy{Threshold: 0.5, Action: FlakyActionAcknowledge}})
+	_, prob := h.prepareRun(context.Background(), runRequest{JobID: "demo"})
+	if prob == nil || prob.Status != http.StatusConflict || prob.Ext["code"] != "E_JOB_FLAKY" {
+		t.Fatalf("expected flaky job problem, got %+v", prob)
+	}
+	prep, prob := h.prepareRun(context.Background(), runRequest{JobID: "demo", Labels: map[string]string{flakyAckLabel: "tracked in #42"}})
+	if prob != nil {
+		t.Fatalf("acknowledged run rejected: %+v", prob)
+	}
+	if _, ok := prep.provenance["flakiness"].(JobFlakiness); !ok || prep.minRetries != 0 {
+		t.Fatalf("expected flakiness in provenance without retries, got %+v", prep.provenance)
+	}
+
+	h = NewRunsHandler(RunsConfig{Root: root, Store: store, Flakiness: FlakinessPolicy{Threshold: 0.5, Action: FlakyActionRetry}})
+	prep, prob = h.prepareRun(context.Background(), runRequest{JobID: "demo"})
+	if prob != nil || prep.minRetries != 1 {
+		t.Fatalf("expected retried run, got %+v %+v", prep, prob)
+	}
+}
+
+func TestStatsHandler(t *testing.T) {
+	store := runstore.New()
+	seedRuns(store, "flaky", "completed", "failed", "completed", "failed")
+	seedRuns(store, "stable", "completed", "completed", "completed", "completed")
+	store.Create(runstore.Run{ID: "queued-1", JobID: "stable", Status: "queued", StartedAt: time.Now()})
+
+	rec := httptest.NewRecorder()
+	NewStatsHandler(store, FlakinessPolicy{Threshold: 0.5}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var stats StatsPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode stats: %v", err)
+	}
+	if stats.Runs["completed"] != 6 || stats.Runs["queued"] != 1 || stats.FlakyJobs != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if len(stats.Jobs) != 2 || stats.Jobs[0].JobID != "flaky" || !stats.Jobs[0].Flaky || stats.Jobs[1].Flaky {
+		t.Fatalf("expected flaky job listed first, got %+v", stats.Jobs)
+	}
+	if len(stats.Schedules) != 0 {
+		t.Fatalf("expected no schedule stats, got %+v", stats.Schedules)
+	}
+}
+
+func TestStatsHandlerScheduleSuccessRate(t *testing.T) {
+	store := runstore.New()
+	for i, status := range []string{"completed", "failed", "completed", "canceled", "timed_out", "completed", "running"} {
+		store.Create(runstore.Run{
+			ID: fmt.Sprintf("nightly-%d", i), JobID: "backup", Status: status,
+			StartedAt:  time.Now(),
+			Provenance: map[string]any{"schedule_id": "backup/nightly"},
+		})
+	}
+	store.Create(runstore.Run{ID: "manual", JobID: "backup", Status: "failed", StartedAt: time.Now()})
+
+	rec := httptest.NewRecorder()
+	NewStatsHandler(store, FlakinessPolicy{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	var stats StatsPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode stats: %v", err)
+	}
+	want := []ScheduleStats{{ScheduleID: "backup/nightly", Runs: 5, Failures: 2, SuccessRate: 0.6}}
+	if !reflect.DeepEqual(stats.Schedules, want) {
+		t.Fatalf("expected schedule stats %+v, got %+v", want, stats.Schedules)
+	}
+}
+
+// jobsAccess confines a caller to the listed jobs.
+type jobsAccess map[string]bool
+
+func (a jobsAccess) AllowsJob(id string) bool      { return a[id] }
+func (a jobsAccess) AllowsSource(name string) bool { return true }
+
+func TestStatsHandlerHonoursAccess(t *testing.T) {
+	store := runstore.New()
+	seedRuns(store, "flaky", "completed", "failed", "completed", "failed")
+	seedRuns(store, "stable", "completed", "completed")
+	store.Create(runstore.Run{ID: "nightly", JobID: "flaky", Status: "failed", StartedAt: time.Now(),
+		Provenance: map[string]any{"schedule_id": "flaky/nightly"}})
+
+	ctx := requestctx.WithAccess(context.Background(), jobsAccess{"stable": true})
+	rec := httptest.NewRecorder()
+	NewStatsHandler(store, FlakinessPolicy{Threshold: 0.5}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil).WithContext(ctx))
+	var stats StatsPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode stats: %v", err)
+	}
+	if stats.Runs["completed"] != 2 || stats.Runs["failed"] != 0 || stats.FlakyJobs != 0 {
+		t.Fatalf("expected counts of visible runs only, got %+v", stats)
+	}
+	if len(stats.Jobs) != 1 || stats.Jobs[0].JobID != "stable" || len(stats.Schedules) != 0 {
+		t.Fatalf("expected only the stable job, got %+v", stats)
+	}
+}
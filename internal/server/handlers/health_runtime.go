@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/executor/container"
+	"github.com/flowd-org/flowd/internal/server/response"
+)
+
+const (
+	runtimeUnavailableProblemType = "https://flowd.dev/problems/runtime-unavailable"
+	defaultRuntimeHealthTTL       = 10 * time.Second
+	defaultRuntimeProbeTimeout    = 5 * time.Second
+)
+
+// RuntimeHealthConfig configures GET /health/runtime.
+type RuntimeHealthConfig struct {
+	Runtime container.Runtime
+	// TTL is how long a probe result is served before probing again, so
+	// frequent load balancer checks do not each spawn the runtime CLI.
+	// Zero uses 10s.
+	TTL time.Duration
+	// Probe defaults to container.ProbeRuntime with a 5s timeout.
+	Probe func(ctx context.Context, runtime container.Runtime) (string, error)
+	Now   func() time.Time
+}
+
+// RuntimeHealth is the body of a healthy GET /health/runtime response.
+type RuntimeHealth struct {
+	OK        bool      `json:"ok"`
+	Runtime   string    `json:"runtime"`
+	Version   string    `json:"version,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	LatencyMS int64     `json:"latency_ms"`
+	Cached    bool      `json:"cached"`
+}
+
+type runtimeHealthHandler struct {
+	cfg RuntimeHealthConfig
+
+	mu      sync.Mutex
+	last    RuntimeHealth
+	lastErr error
+}
+
+// NewRuntimeHealthHandler returns an HTTP handler for GET /health/runtime
+// that probes the container runtime, caching the outcome for cfg.TTL.
+func NewRuntimeHealthHandler(cfg RuntimeHealthConfig) http.Handler {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultRuntimeHealthTTL
+	}
+	if cfg.Probe == nil {
+		cfg.Probe = func(ctx context.Context, runtime container.Runtime) (string, error) {
+			return container.ProbeRuntime(ctx, runtime, defaultRuntimeProbeTimeout)
+		}
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &runtimeHealthHandler{cfg: cfg}
+}
+
+func (h *runtimeHealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+
+	health, err := h.check()
+	if err != nil {
+		response.Write(w, response.New(http.StatusServiceUnavailable, "container runtime unavailable",
+			response.WithType(runtimeUnavailableProblemType),
+			response.WithDetail(err.Error()),
+			response.WithExtension("runtime", health.Runtime),
+			response.WithExtension("checked_at", health.CheckedAt),
+			response.WithExtension("cached", health.Cached),
+		))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(health)
+}
+
+// check returns the cached result while fresh and probes otherwise.
+// Concurrent requests wait for a single probe.
+func (h *runtimeHealthHandler) check() (RuntimeHealth, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.cfg.Now()
+	if !h.last.CheckedAt.IsZero() && now.Sub(h.last.CheckedAt) < h.cfg.TTL {
+		cached := h.last
+		cached.Cached = true
+		return cached, h.lastErr
+	}
+	// The probe is not tied to the request: a client hanging up should not
+	// cache a spurious failure.
+	version, err := h.cfg.Probe(context.Background(), h.cfg.Runtime)
+	h.last = RuntimeHealth{
+		OK:        err == nil,
+		Runtime:   string(h.cfg.Runtime),
+		Version:   version,
+		CheckedAt: now.UTC(),
+		LatencyMS: h.cfg.Now().Sub(now).Milliseconds(),
+	}
+	h.lastErr = err
+	return h.last, err
+}
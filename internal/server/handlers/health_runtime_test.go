@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/executor/container"
+)
+
+func TestRuntimeHealthHandlerCachesProbe(t *testing.T) {
+	now := time.Date(2025, time.May, 1, 12, 0, 0, 0, time.UTC)
+	probes := 0
+	probeErr := error(nil)
+	handler := NewRuntimeHealthHandler(RuntimeHealthConfig{
+		Runtime: container.RuntimeDocker,
+		TTL:     10 * time.Second,
+		Now:     func() time.Time { return now },
+		Probe: func(_ context.Context, runtime container.Runtime) (string, error) {
+			probes++
+			if runtime != container.RuntimeDocker {
+				t.Fatalf("unexpected runtime %q", runtime)
+			}
+			return "27.1.1", probeErr
+		},
+	})
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/runtime", nil))
+		return rec
+	}
+
+	rec := get()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var health RuntimeHealth
+	if err := json.Unmarshal(rec.Body.Bytes(), &health); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !health.OK || health.Version != "27.1.1" || health.Runtime != "docker" || health.Cached {
+		t.Fatalf("unexpected health %+v", health)
+	}
+
+	probeErr = errors.New("Cannot connect to the Docker daemon")
+	if rec := get(); rec.Code != http.StatusOK || probes != 1 {
+		t.Fatalf("expected cached 200 within TTL, got %d after %d probes", rec.Code, probes)
+	}
+
+	now = now.Add(11 * time.Second)
+	rec = get()
+	if rec.Code != http.StatusServiceUnavailable || probes != 2 {
+		t.Fatalf("expected 503 after TTL, got %d after %d probes", rec.Code, probes)
+	}
+	var problem map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if problem["type"] != runtimeUnavailableProblemType || problem["runtime"] != "docker" {
+		t.Fatalf("unexpected problem %v", problem)
+	}
+}
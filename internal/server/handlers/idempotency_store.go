@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/flowd-org/flowd/internal/coredb"
+	"github.com/flowd-org/flowd/internal/server/faults"
 )
 
 type idempotencyStore interface {
@@ -115,3 +116,23 @@ func (d *dbIdempotencyStore) Store(ctx context.Context, key, endpoint, bodyHash
 	}
 	return d.store.Store(ctx, key, endpoint, bodyHash, status, data, expiresAt)
 }
+
+// faultyIdempotencyStore fails lookups and writes when the idempotency fault
+// is armed for the request.
+type faultyIdempotencyStore struct {
+	idempotencyStore
+}
+
+func (f faultyIdempotencyStore) Lookup(ctx context.Context, key, endpoint string, now time.Time) (RunPayload, int, string, bool, error) {
+	if faults.Inject(ctx, faults.IdempotencyError) {
+		return RunPayload{}, 0, "", false, faults.Err(faults.IdempotencyError)
+	}
+	return f.idempotencyStore.Lookup(ctx, key, endpoint, now)
+}
+
+func (f faultyIdempotencyStore) Store(ctx context.Context, key, endpoint, bodyHash string, payload RunPayload, status int, expiresAt time.Time) error {
+	if faults.Inject(ctx, faults.IdempotencyError) {
+		return faults.Err(faults.IdempotencyError)
+	}
+	return f.idempotencyStore.Store(ctx, key, endpoint, bodyHash, payload, status, expiresAt)
+}
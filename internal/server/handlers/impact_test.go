@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/server/authz"
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+const impactJobConfig = `
+version: v1
+job:
+  id: deploy
+  name: Deploy
+interpreter: bash
+impact:
+  environments: [Prod]
+  blast_radius: medium
+`
+
+func TestPlansHandlerSurfacesImpact(t *testing.T) {
+	root := t.TempDir()
+	writePlanConfig(t, root, "deploy", impactJobConfig)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/plans", strings.NewReader(`{"job_id":"deploy"}`))
+	req.Header.Set("Content-Type", "application/json")
+	NewPlansHandler(PlansConfig{Root: root}).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var plan types.Plan
+	if err := json.NewDecoder(rec.Body).Decode(&plan); err != nil {
+		t.Fatalf("decode plan: %v", err)
+	}
+	if plan.Impact == nil || !plan.Impact.ConfirmationRequired || plan.Impact.BlastRadius != "medium" ||
+		len(plan.Impact.Environments) != 1 || plan.Impact.Environments[0] != "prod" {
+		t.Fatalf("unexpected impact preview %+v", plan.Impact)
+	}
+}
+
+func TestRunsHandlerRequiresHighImpactScope(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "deploy", impactJobConfig)
+	if err := os.WriteFile(filepath.Join(root, "deploy", "100_main.sh"), []byte("true\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	store := runstore.New()
+	h := NewRunsHandler(RunsConfig{Root: root, Store: store, Events: &recordingSink{}})
+
+	rec := postRun(t, h, `{"job_id":"deploy"}`)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without scope, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var prob map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &prob); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if prob["code"] != "E_IMPACT" || prob["required_scope"] != authz.ScopeRunsHighImpact {
+		t.Fatalf("unexpected problem %v", prob)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/runs", strings.NewReader(`{"job_id":"deploy"}`))
+	req.Header.Set("Content-Type", "application/json")
+	addIdempotencyHeader(req)
+	req = req.WithContext(requestctx.WithScopes(req.Context(), []string{authz.ScopeRunsWrite, authz.ScopeRunsHighImpact}))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201 with scope, got %d: %s", rec.Code, rec.Body.String())
+	}
+	waitForTerminalRun(t, store, rec.Body.Bytes())
+}
@@ -0,0 +1,244 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/flowd-org/flowd/internal/indexer"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+const (
+	badgeMaxAge      = 60
+	badgeMaxLabelLen = 64
+)
+
+// JobBadgeConfig configures the job status badge handler.
+type JobBadgeConfig struct {
+	Root     string
+	Runs     *runstore.Store
+	Discover func(string) (indexer.Result, error)
+	// Public marks badges as cacheable by shared caches; set when badges are
+	// served without authentication.
+	Public bool
+}
+
+// BadgePayload is the JSON form of a job badge.
+type BadgePayload struct {
+	JobID      string     `json:"job_id"`
+	Label      string     `json:"label"`
+	Message    string     `json:"message"`
+	Color      string     `json:"color"`
+	Status     string     `json:"status,omitempty"`
+	RunID      string     `json:"run_id,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	DurationMS *int64     `json:"duration_ms,omitempty"`
+}
+
+type jobBadgeHandler struct {
+	cfg JobBadgeConfig
+}
+
+// NewJobBadgeHandler serves GET /jobs/{id}/badge.svg and /jobs/{id}/badge.json
+// reflecting the job's latest run.
+func NewJobBadgeHandler(cfg JobBadgeConfig) http.Handler {
+	if cfg.Root == "" {
+		cfg.Root = "scripts"
+	}
+	if cfg.Runs == nil {
+		cfg.Runs = runstore.New()
+	}
+	if cfg.Discover == nil {
+		cfg.Discover = indexer.Discover
+	}
+	return &jobBadgeHandler{cfg: cfg}
+}
+
+func (h *jobBadgeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+	rest := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	var format string
+	switch {
+	case strings.HasSuffix(rest, "/badge.svg"):
+		format = "svg"
+	case strings.HasSuffix(rest, "/badge.json"):
+		format = "json"
+	default:
+		response.Write(w, response.New(http.StatusNotFound, "not found"))
+		return
+	}
+	jobID := strings.Trim(rest[:strings.LastIndex(rest, "/badge.")], "/")
+	if jobID == "" {
+		response.Write(w, response.New(http.StatusNotFound, "job not found"))
+		return
+	}
+
+	latest, ok := h.latestRun(jobID)
+	if !ok && !h.jobExists(jobID) {
+		response.Write(w, response.New(http.StatusNotFound, "job not found", response.WithDetail(jobID)))
+		return
+	}
+	label := strings.TrimSpace(r.URL.Query().Get("label"))
+	if label == "" {
+		label = jobID
+	}
+	if utf8.RuneCountInString(label) > badgeMaxLabelLen {
+		label = string([]rune(label)[:badgeMaxLabelLen])
+	}
+	badge := h.badge(jobID, label, latest, ok)
+
+	var (
+		body        []byte
+		contentType string
+	)
+	if format == "svg" {
+		body = renderBadgeSVG(badge.Label, badge.Message, badge.Color)
+		contentType = "image/svg+xml;charset=utf-8"
+	} else {
+		body, _ = json.Marshal(badge)
+		body = append(body, '\n')
+		contentType = "application/json"
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	visibility := "private"
+	if h.cfg.Public {
+		visibility = "public"
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", visibility, badgeMaxAge))
+	w.Header().Set("ETag", etag)
+	w.Header().Add("Vary", "Authorization")
+	if match := r.Header.Get("If-None-Match"); match != "" && etagMatches(match, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (h *jobBadgeHandler) latestRun(jobID string) (runstore.Run, bool) {
+	var (
+		latest runstore.Run
+		found  bool
+	)
+	for _, run := range h.cfg.Runs.List() {
+		if run.JobID != jobID {
+			continue
+		}
+		if !found || run.StartedAt.After(latest.StartedAt) {
+			latest, found = run, true
+		}
+	}
+	return latest, found
+}
+
+func (h *jobBadgeHandler) jobExists(jobID string) bool {
+	result, err := h.cfg.Discover(h.cfg.Root)
+	if err != nil {
+		return false
+	}
+	for _, job := range result.Jobs {
+		if job.ID == jobID {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *jobBadgeHandler) badge(jobID, label string, run runstore.Run, ok bool) BadgePayload {
+	badge := BadgePayload{JobID: jobID, Label: label, Message: "no runs", Color: "#9f9f9f"}
+	if !ok {
+		return badge
+	}
+	badge.Status = run.Status
+	badge.RunID = run.ID
+	started := run.StartedAt
+	badge.StartedAt = &started
+	switch run.Status {
+	case "completed":
+		badge.Message, badge.Color = "passing", "#4c1"
+	case "failed":
+		badge.Message, badge.Color = "failing", "#e05d44"
+	case "canceled":
+		badge.Message, badge.Color = "canceled", "#9f9f9f"
+	case "timed_out":
+		badge.Message, badge.Color = "timed out", "#e05d44"
+	case "running":
+		badge.Message, badge.Color = "running", "#007ec6"
+	default:
+		badge.Message, badge.Color = run.Status, "#dfb317"
+	}
+	if run.FinishedAt != nil && isTerminalStatus(run.Status) {
+		finished := *run.FinishedAt
+		badge.FinishedAt = &finished
+		d := finished.Sub(started)
+		ms := d.Milliseconds()
+		badge.DurationMS = &ms
+		badge.Message += " · " + formatBadgeDuration(d)
+	}
+	return badge
+}
+
+// formatBadgeDuration renders d compactly, e.g. 850ms, 42s, 3m05s or 1h12m.
+func formatBadgeDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// renderBadgeSVG draws a flat two-part badge. Text widths are estimated from
+// an average glyph width, which is close enough for short labels.
+func renderBadgeSVG(label, message, color string) []byte {
+	lw := badgeTextWidth(label)
+	mw := badgeTextWidth(message)
+	total := lw + mw
+	label, message = html.EscapeString(label), html.EscapeString(message)
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`, total, label, message)
+	fmt.Fprintf(&b, `<title>%s: %s</title>`, label, message)
+	b.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&b, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, total)
+	fmt.Fprintf(&b, `<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>`, lw, lw, mw, color, total)
+	b.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	fmt.Fprintf(&b, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>`, lw/2, label, lw/2, label)
+	fmt.Fprintf(&b, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>`, lw+mw/2, message, lw+mw/2, message)
+	b.WriteString(`</g></svg>`)
+	return []byte(b.String())
+}
+
+func badgeTextWidth(s string) int {
+	return utf8.RuneCountInString(s)*7 + 10
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/indexer"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+func badgeDiscover(ids ...string) func(string) (indexer.Result, error) {
+	return func(string) (indexer.Result, error) {
+		var res indexer.Result
+		for _, id := range ids {
+			res.Jobs = append(res.Jobs, indexer.JobInfo{ID: id, Name: id})
+		}
+		return res, nil
+	}
+}
+
+func getBadge(t *testing.T, h http.Handler, path string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestJobBadgeReflectsLatestRun(t *testing.T) {
+	store := runstore.New()
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	older := base.Add(time.Minute)
+	newer := base.Add(time.Hour + 3*time.Minute + 5*time.Second)
+	store.Create(runstore.Run{ID: "r1", JobID: "build", Status: "completed", StartedAt: base, FinishedAt: &older})
+	store.Create(runstore.Run{ID: "r2", JobID: "build", Status: "failed", StartedAt: base.Add(time.Hour), FinishedAt: &newer})
+	store.Create(runstore.Run{ID: "r3", JobID: "deploy", Status: "running", StartedAt: base.Add(2 * time.Hour)})
+	h := NewJobBadgeHandler(JobBadgeConfig{Runs: store, Discover: badgeDiscover("build", "deploy")})
+
+	rec := getBadge(t, h, "/jobs/build/badge.json", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var badge BadgePayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &badge); err != nil {
+		t.Fatalf("decode badge: %v", err)
+	}
+	if badge.RunID != "r2" || badge.Status != "failed" || badge.Message != "failing · 3m05s" || badge.Color != "#e05d44" {
+		t.Fatalf("unexpected badge %+v", badge)
+	}
+	if badge.DurationMS == nil || *badge.DurationMS != 185000 {
+		t.Fatalf("expected duration_ms 185000, got %v", badge.DurationMS)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "private, max-age=60" {
+		t.Fatalf("unexpected Cache-Control %q", cc)
+	}
+
+	rec = getBadge(t, h, "/jobs/deploy/badge.svg?label=prod%20<deploy>", nil)
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "image/svg+xml") {
+		t.Fatalf("expected svg badge, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	svg := rec.Body.String()
+	if !strings.Contains(svg, "prod &lt;deploy&gt;: running") || strings.Contains(svg, "<deploy>") {
+		t.Fatalf("expected escaped label and running message, got %s", svg)
+	}
+}
+
+func TestJobBadgeConditionalRequests(t *testing.T) {
+	store := runstore.New()
+	store.Create(runstore.Run{ID: "r1", JobID: "build", Status: "running", StartedAt: time.Now().UTC()})
+	h := NewJobBadgeHandler(JobBadgeConfig{Runs: store, Discover: badgeDiscover("build"), Public: true})
+
+	rec := getBadge(t, h, "/jobs/build/badge.svg", nil)
+	etag := rec.Header().Get("ETag")
+	if etag == "" || rec.Header().Get("Cache-Control") != "public, max-age=60" {
+		t.Fatalf("expected cacheable badge, got %v", rec.Header())
+	}
+	rec = getBadge(t, h, "/jobs/build/badge.svg", http.Header{"If-None-Match": {etag}})
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("expected 304 for matching etag, got %d", rec.Code)
+	}
+
+	finished := time.Now().UTC()
+	run, _ := store.Get("r1")
+	run.Status, run.FinishedAt = "completed", &finished
+	store.Update(run)
+	rec = getBadge(t, h, "/jobs/build/badge.svg", http.Header{"If-None-Match": {etag}})
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Fatalf("expected a fresh badge after the run finished, got %d", rec.Code)
+	}
+}
+
+func TestJobBadgeUnknownAndIdleJobs(t *testing.T) {
+	h := NewJobBadgeHandler(JobBadgeConfig{Runs: runstore.New(), Discover: badgeDiscover("idle")})
+	if rec := getBadge(t, h, "/jobs/missing/badge.svg", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown job, got %d", rec.Code)
+	}
+	if rec := getBadge(t, h, "/jobs/idle/status.svg", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown badge path, got %d", rec.Code)
+	}
+	rec := getBadge(t, h, "/jobs/idle/badge.json", nil)
+	var badge BadgePayload
+	_ = json.Unmarshal(rec.Body.Bytes(), &badge)
+	if rec.Code != http.StatusOK || badge.Message != "no runs" || badge.RunID != "" {
+		t.Fatalf("expected no-runs badge, got %d %+v", rec.Code, badge)
+	}
+}
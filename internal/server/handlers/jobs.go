@@ -1,8 +1,8 @@
 package handlers
 
 import (
-	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"path/filepath"
 	"sort"
@@ -12,24 +12,26 @@ import (
 	"github.com/flowd-org/flowd/internal/configloader"
 	"github.com/flowd-org/flowd/internal/indexer"
 	"github.com/flowd-org/flowd/internal/server/headers"
+	"github.com/flowd-org/flowd/internal/server/requestctx"
 	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/runstore"
 	"github.com/flowd-org/flowd/internal/server/sourcestore"
-)
-
-const (
-	defaultPage     = 1
-	defaultPerPage  = 50
-	defaultMaxLimit = 200
+	"github.com/flowd-org/flowd/internal/sortspec"
 )
 
 // JobsConfig configures the jobs handler.
 type JobsConfig struct {
-	Root          string
-	MaxPerPage    int
-	Discover      func(string) (indexer.Result, error)
-	Sources       *sourcestore.Store
-	AliasesPublic bool
-	ExposeAliases func(*http.Request) bool
+	Root string
+	// DefaultPerPage and MaxPerPage bound per_page; zero uses 50 and 200.
+	DefaultPerPage int
+	MaxPerPage     int
+	Discover       func(string) (indexer.Result, error)
+	Sources        *sourcestore.Store
+	AliasesPublic  bool
+	ExposeAliases  func(*http.Request) bool
+	// Runs and Flakiness report the flakiness of jobs with finished runs.
+	Runs      *runstore.Store
+	Flakiness FlakinessPolicy
 }
 
 type jobView struct {
@@ -41,6 +43,7 @@ type jobView struct {
 	Source      *jobSource    `json:"source,omitempty"`
 	AliasOf     string        `json:"alias_of,omitempty"`
 	AliasDetail string        `json:"alias_detail,omitempty"`
+	Flakiness   *JobFlakiness `json:"flakiness,omitempty"`
 }
 
 type jobSource struct {
@@ -50,9 +53,7 @@ type jobSource struct {
 
 // NewJobsHandler returns an HTTP handler for GET /jobs.
 func NewJobsHandler(cfg JobsConfig) http.Handler {
-	if cfg.MaxPerPage <= 0 {
-		cfg.MaxPerPage = defaultMaxLimit
-	}
+	bounds := Pagination{DefaultPerPage: cfg.DefaultPerPage, MaxPerPage: cfg.MaxPerPage}.normalized()
 	if cfg.Root == "" {
 		cfg.Root = "scripts"
 	}
@@ -72,11 +73,16 @@ func NewJobsHandler(cfg JobsConfig) http.Handler {
 			exposeAliases = cfg.ExposeAliases(r)
 		}
 
-		page, perPage, err := parsePagination(r, cfg.MaxPerPage)
+		page, perPage, err := bounds.parse(r)
 		if err != nil {
 			response.Write(w, response.New(http.StatusBadRequest, "invalid pagination", response.WithDetail(err.Error())))
 			return
 		}
+		sortKeys, err := sortspec.Parse(r.URL.Query().Get("sort"), indexer.JobSortFields...)
+		if err != nil {
+			response.Write(w, invalidSortProblem(err))
+			return
+		}
 
 		targets, err := resolveJobTargets(cfg.Root, cfg.Sources)
 		if err != nil {
@@ -139,6 +145,20 @@ func NewJobsHandler(cfg JobsConfig) http.Handler {
 			errorCnt += len(discovered.Errors)
 		}
 
+		if requestctx.AccessFromContext(r.Context()) != nil {
+			visible := allViews[:0]
+			for _, view := range allViews {
+				source := ""
+				if view.Source != nil {
+					source = view.Source.Name
+				}
+				if jobVisible(r.Context(), view.ID, source) {
+					visible = append(visible, view)
+				}
+			}
+			allViews = visible
+		}
+
 		aliasIndex, aliasErrs := indexer.BuildAliasIndex(allJobs, aliasSets)
 		if len(aliasErrs) > 0 {
 			errorCnt += len(aliasErrs)
@@ -153,6 +173,9 @@ func NewJobsHandler(cfg JobsConfig) http.Handler {
 				if _, exists := seenAliases[key]; exists {
 					continue
 				}
+				if !jobVisible(r.Context(), alias.TargetID, "") || (alias.Source != "" && !requestctx.SourceAllowed(r.Context(), alias.Source)) {
+					continue
+				}
 				seenAliases[key] = struct{}{}
 				aliasView := jobView{
 					ID:      alias.Name,
@@ -176,6 +199,14 @@ func NewJobsHandler(cfg JobsConfig) http.Handler {
 			}
 		}
 
+		if flaky := jobFlakiness(cfg.Flakiness, cfg.Runs); len(flaky) > 0 {
+			for i := range allViews {
+				if f, ok := flaky[allViews[i].ID]; ok && allViews[i].AliasOf == "" {
+					allViews[i].Flakiness = &f
+				}
+			}
+		}
+
 		if errorCnt > 0 {
 			w.Header().Set(headers.DiscoveryErrors, strconv.Itoa(errorCnt))
 		} else {
@@ -183,6 +214,11 @@ func NewJobsHandler(cfg JobsConfig) http.Handler {
 		}
 
 		sort.Slice(allViews, func(i, j int) bool {
+			left := indexer.JobInfo{ID: allViews[i].ID, Name: allViews[i].Name}
+			right := indexer.JobInfo{ID: allViews[j].ID, Name: allViews[j].Name}
+			if c := indexer.CompareJobs(left, right, sortKeys); c != 0 {
+				return c < 0
+			}
 			if allViews[i].ID == allViews[j].ID {
 				var left, right string
 				if allViews[i].Source != nil {
@@ -196,6 +232,7 @@ func NewJobsHandler(cfg JobsConfig) http.Handler {
 			return allViews[i].ID < allViews[j].ID
 		})
 
+		writePaginationHeaders(w, len(allViews), page, perPage)
 		start := (page - 1) * perPage
 		var views []jobView
 		if start >= len(allViews) {
@@ -208,63 +245,15 @@ func NewJobsHandler(cfg JobsConfig) http.Handler {
 			views = allViews[start:end]
 		}
 
-		payload, err := json.Marshal(views)
+		err = writeJSONArray(w, len(views), func(i int) any { return views[i] })
 		if err != nil {
-			response.Write(w, response.New(http.StatusInternalServerError, "encode response failed", response.WithDetail(err.Error())))
-			return
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		if _, err := w.Write(payload); err != nil {
-			response.Write(w, response.New(http.StatusInternalServerError, "write response failed", response.WithDetail(err.Error())))
-			return
+			if logger := requestctx.Logger(r.Context()); logger != nil {
+				logger.Warn("jobs.list.stream", slog.String("error", err.Error()))
+			}
 		}
 	})
 }
 
-func parsePagination(r *http.Request, maxPerPage int) (page int, perPage int, err error) {
-	page = defaultPage
-	perPage = defaultPerPage
-
-	q := r.URL.Query()
-
-	if v := q.Get("page"); v != "" {
-		page, err = strconv.Atoi(v)
-		if err != nil || page <= 0 {
-			return 0, 0, errInvalidPage(v)
-		}
-	}
-
-	if v := q.Get("per_page"); v != "" {
-		perPage, err = strconv.Atoi(v)
-		if err != nil {
-			return 0, 0, errInvalidPerPage(v, maxPerPage)
-		}
-		if perPage <= 0 || perPage > maxPerPage {
-			return 0, 0, errInvalidPerPage(v, maxPerPage)
-		}
-	}
-
-	return page, perPage, nil
-}
-
-func errInvalidPage(v string) error {
-	return &paginationError{Msg: "page must be a positive integer", Value: v}
-}
-
-func errInvalidPerPage(v string, max int) error {
-	return &paginationError{Msg: "per_page must be between 1 and " + strconv.Itoa(max), Value: v}
-}
-
-type paginationError struct {
-	Msg   string
-	Value string
-}
-
-func (e *paginationError) Error() string {
-	return e.Msg + " (got " + e.Value + ")"
-}
-
 type jobTarget struct {
 	root   string
 	source *sourcestore.Source
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/flowd-org/flowd/internal/indexer"
@@ -184,3 +185,44 @@ func TestJobsHandlerAliasVisibilityPolicy(t *testing.T) {
 		t.Fatalf("expected alias entry hello-demo in job list: %#v", jobs)
 	}
 }
+
+func TestJobsHandlerSort(t *testing.T) {
+	discover := func(string) (indexer.Result, error) {
+		return indexer.Result{Jobs: []indexer.JobInfo{
+			{ID: "a-deploy", Name: "Zeta"},
+			{ID: "b-backup", Name: "alpha"},
+			{ID: "c-build", Name: "Mid"},
+		}}, nil
+	}
+	h := NewJobsHandler(JobsConfig{Root: t.TempDir(), Discover: discover})
+
+	ids := func(query string) []string {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", query, rec.Code, rec.Body.String())
+		}
+		var jobs []jobView
+		if err := json.NewDecoder(rec.Body).Decode(&jobs); err != nil {
+			t.Fatalf("decode jobs: %v", err)
+		}
+		out := make([]string, len(jobs))
+		for i, job := range jobs {
+			out[i] = job.ID
+		}
+		return out
+	}
+
+	if got := ids("?sort=name"); !reflect.DeepEqual(got, []string{"b-backup", "c-build", "a-deploy"}) {
+		t.Fatalf("sort=name: got %v", got)
+	}
+	if got := ids("?sort=-id"); !reflect.DeepEqual(got, []string{"c-build", "b-backup", "a-deploy"}) {
+		t.Fatalf("sort=-id: got %v", got)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs?sort=started_at", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown sort field, got %d", rec.Code)
+	}
+}
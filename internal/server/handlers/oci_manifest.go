@@ -39,6 +39,8 @@ type addonManifest struct {
 	Metadata   *addonManifestMeta     `yaml:"metadata" json:"metadata"`
 	Requires   *addonManifestRequires `yaml:"requires" json:"requires"`
 	Jobs       []addonManifestJob     `yaml:"jobs" json:"jobs"`
+	// Aliases are imported as aliases of the source when it is added.
+	Aliases []addonManifestAlias `yaml:"aliases" json:"aliases,omitempty"`
 }
 
 type addonManifestMeta struct {
@@ -78,6 +80,17 @@ type addonManifestJob struct {
 	Extends      []string              `yaml:"extends" json:"extends"`
 	Argspec      *addonManifestArgspec `yaml:"argspec" json:"argspec"`
 	Requirements addonManifestJobReqs  `yaml:"requirements" json:"requirements"`
+	// Entrypoint is the command run inside the image; it defaults to
+	// /flwd-addon/jobs/<id>.
+	Entrypoint []string `yaml:"entrypoint" json:"entrypoint,omitempty"`
+}
+
+// addonManifestAlias names a job of the add-on by a short, single-level
+// alias.
+type addonManifestAlias struct {
+	Name        string `yaml:"name" json:"name"`
+	Job         string `yaml:"job" json:"job"`
+	Description string `yaml:"description" json:"description,omitempty"`
 }
 
 type addonManifestJobReqs struct {
@@ -189,6 +202,11 @@ func parseAndValidateAddonManifest(data []byte) (*addonManifest, []string, error
 			} else if len([]rune(job.Summary)) > 240 {
 				errs = append(errs, fmt.Sprintf("%s.summary must be <=240 characters per %s", prefix, manifestSchemaRef))
 			}
+			for j, part := range job.Entrypoint {
+				if strings.TrimSpace(part) == "" {
+					errs = append(errs, fmt.Sprintf("%s.entrypoint[%d] must not be empty", prefix, j))
+				}
+			}
 			if job.Argspec == nil {
 				errs = append(errs, fmt.Sprintf("%s.argspec is required", prefix))
 				continue
@@ -208,6 +226,8 @@ func parseAndValidateAddonManifest(data []byte) (*addonManifest, []string, error
 		}
 	}
 
+	errs = append(errs, validateAddonAliases(manifest)...)
+
 	schemaErrs, schemaValidationErr := validateManifestSchemaConstraints(data)
 	if schemaValidationErr != nil {
 		return nil, nil, schemaValidationErr
@@ -217,6 +237,37 @@ func parseAndValidateAddonManifest(data []byte) (*addonManifest, []string, error
 	return &manifest, errs, nil
 }
 
+func validateAddonAliases(manifest addonManifest) []string {
+	jobs := make(map[string]struct{}, len(manifest.Jobs))
+	for _, job := range manifest.Jobs {
+		jobs[job.ID] = struct{}{}
+	}
+	var errs []string
+	seen := make(map[string]struct{}, len(manifest.Aliases))
+	for i, alias := range manifest.Aliases {
+		prefix := fmt.Sprintf("aliases[%d]", i)
+		name := strings.TrimSpace(alias.Name)
+		switch {
+		case name == "":
+			errs = append(errs, fmt.Sprintf("%s.name is required", prefix))
+		case strings.ContainsAny(name, "/\\"):
+			errs = append(errs, fmt.Sprintf("%s.name %q must be single-level (no '/')", prefix, name))
+		case strings.HasPrefix(name, ":"):
+			errs = append(errs, fmt.Sprintf("%s.name %q cannot start with ':'", prefix, name))
+		default:
+			key := strings.ToLower(name)
+			if _, dup := seen[key]; dup {
+				errs = append(errs, fmt.Sprintf("%s.name %q is declared more than once", prefix, name))
+			}
+			seen[key] = struct{}{}
+		}
+		if _, ok := jobs[strings.TrimSpace(alias.Job)]; !ok {
+			errs = append(errs, fmt.Sprintf("%s.job %q does not name a job of the add-on", prefix, alias.Job))
+		}
+	}
+	return errs
+}
+
 func manifestSummary(m *addonManifest) map[string]any {
 	if m == nil {
 		return nil
@@ -224,6 +275,9 @@ func manifestSummary(m *addonManifest) map[string]any {
 	summary := map[string]any{
 		"jobs": len(m.Jobs),
 	}
+	if len(m.Aliases) > 0 {
+		summary["aliases"] = len(m.Aliases)
+	}
 	if m.Metadata != nil {
 		if m.Metadata.Name != "" {
 			summary["name"] = m.Metadata.Name
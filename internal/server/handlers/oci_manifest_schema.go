@@ -164,6 +164,22 @@ func validateManifestSchemaConstraints(data []byte) ([]string, error) {
 		}
 	}
 
+	if aliasesVal, ok := raw["aliases"]; ok {
+		arr, ok := aliasesVal.([]any)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("aliases must be an array per %s", manifestSchemaRef))
+		} else {
+			for i, v := range arr {
+				aliasMap, ok := v.(map[string]any)
+				if !ok {
+					errs = append(errs, fmt.Sprintf("aliases[%d] must be an object per %s", i, manifestSchemaRef))
+					continue
+				}
+				errs = append(errs, validateAllowedKeys(aliasMap, allowedAliasKeys(), fmt.Sprintf("aliases[%d]", i))...)
+			}
+		}
+	}
+
 	return errs, nil
 }
 
@@ -184,6 +200,7 @@ func allowedManifestKeys() map[string]struct{} {
 		"metadata":   {},
 		"requires":   {},
 		"jobs":       {},
+		"aliases":    {},
 	}
 }
 
@@ -224,6 +241,14 @@ func allowedContainerKeys() map[string]struct{} {
 	}
 }
 
+func allowedAliasKeys() map[string]struct{} {
+	return map[string]struct{}{
+		"name":        {},
+		"job":         {},
+		"description": {},
+	}
+}
+
 func allowedJobKeys() map[string]struct{} {
 	return map[string]struct{}{
 		"id":           {},
@@ -233,6 +258,7 @@ func allowedJobKeys() map[string]struct{} {
 		"argspec":      {},
 		"extends":      {},
 		"requirements": {},
+		"entrypoint":   {},
 	}
 }
 
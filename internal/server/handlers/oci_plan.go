@@ -48,7 +48,7 @@ func tryBuildOCIPlan(r *http.Request, req planRequest, cfg PlansConfig) (types.P
 }
 
 func buildOCIPlan(ctx context.Context, req planRequest, cfg PlansConfig, src sourcestore.Source, job addonManifestJob) (types.Plan, []any, *response.Problem, error) {
-	effProfile, err := resolveEffectiveProfile(req.RequestedSecurityProfile, cfg.Profile)
+	effProfile, err := resolveEffectiveProfile(req.RequestedSecurityProfile, "", cfg.Profile)
 	if err != nil {
 		prob := response.New(http.StatusUnprocessableEntity, "invalid security profile",
 			response.WithExtension("code", "E_POLICY"),
@@ -172,6 +172,7 @@ func buildOCIPlan(ctx context.Context, req planRequest, cfg PlansConfig, src sou
 		plan.ImageTrust = &types.ImageTrustPreview{
 			Image:    verifyImage,
 			Mode:     string(mode),
+			Backend:  outcome.Backend,
 			Verified: outcome.Verified,
 			Reason:   outcome.Reason,
 		}
@@ -0,0 +1,215 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/executor/container"
+	"github.com/flowd-org/flowd/internal/indexer"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/sourcestore"
+	"github.com/flowd-org/flowd/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// addonJobsMountPath is where add-on images keep one executable per job when
+// the manifest does not name an entrypoint.
+const addonJobsMountPath = "/flwd-addon/jobs/"
+
+// addonJobScript is the phase script materialized for an add-on job.
+const addonJobScript = "100_main.sh"
+
+// findOCIJob returns the OCI source and manifest job exposed as jobID.
+func (h *RunsHandler) findOCIJob(jobID string) (sourcestore.Source, addonManifestJob, bool) {
+	if h.sources == nil || strings.TrimSpace(jobID) == "" {
+		return sourcestore.Source{}, addonManifestJob{}, false
+	}
+	for _, src := range h.sources.List() {
+		if !strings.EqualFold(src.Type, "oci") {
+			continue
+		}
+		manifest, err := loadAddonManifestFromSource(src)
+		if err != nil {
+			continue
+		}
+		for _, job := range manifest.Jobs {
+			if composeOCIJobID(src.Name, job.ID) == jobID {
+				return src, job, true
+			}
+		}
+	}
+	return sourcestore.Source{}, addonManifestJob{}, false
+}
+
+// findOCIAlias returns the alias named name that an OCI source imported from
+// its manifest.
+func (h *RunsHandler) findOCIAlias(name string) (indexer.AliasInfo, bool) {
+	if h.sources == nil || strings.TrimSpace(name) == "" {
+		return indexer.AliasInfo{}, false
+	}
+	for _, src := range h.sources.List() {
+		if !strings.EqualFold(src.Type, "oci") {
+			continue
+		}
+		for _, alias := range src.Aliases {
+			if strings.EqualFold(alias.To, name) {
+				return indexer.AliasInfo{
+					Name:        alias.To,
+					TargetPath:  alias.From,
+					TargetID:    alias.From,
+					Source:      src.Name,
+					Description: alias.Description,
+				}, true
+			}
+		}
+	}
+	return indexer.AliasInfo{}, false
+}
+
+// ociJobImage is the source image pinned to its resolved digest when known,
+// so a re-tagged image cannot change what an add-on job runs.
+func ociJobImage(src sourcestore.Source) string {
+	image := strings.TrimSpace(src.Ref)
+	if digest := strings.TrimSpace(src.Digest); digest != "" {
+		image = appendDigestReference(image, digest)
+	}
+	return image
+}
+
+// ensureOCIJobImage makes the image digest recorded when src was added
+// available to runtime before one of its jobs runs. The image is pulled by
+// digest when it is absent locally, whatever the source's pull policy, so a
+// tag moved since registration never changes what runs; a registry that no
+// longer serves the digest fails the run with oci.digest.mismatch.
+func ensureOCIJobImage(ctx context.Context, runtime container.Runtime, src sourcestore.Source) *response.Problem {
+	digest := strings.TrimSpace(src.Digest)
+	if digest == "" {
+		prob := response.New(http.StatusConflict, "oci digest missing",
+			response.WithExtension("code", "oci.digest.missing"),
+			response.WithExtension("source", src.Name),
+			response.WithDetail(fmt.Sprintf("source %s recorded no image digest; re-add it to pin its jobs", src.Name)))
+		return &prob
+	}
+	image := ociJobImage(src)
+	if meta, err := inspectImageMetadata(ctx, runtime, image); err == nil && meta.Digest == digest {
+		return nil
+	}
+	if err := pullOCIImage(ctx, runtime, image); err != nil {
+		if errors.Is(err, errOCIPullFailure) && ociDigestUnknown(err.Error()) {
+			return ociDigestMismatchProblem(src, "", err.Error())
+		}
+		prob := response.New(http.StatusBadGateway, "oci pull failed",
+			response.WithExtension("code", "E_OCI"),
+			response.WithDetail(err.Error()))
+		return &prob
+	}
+	meta, err := inspectImageMetadata(ctx, runtime, image)
+	if err != nil {
+		prob := response.New(http.StatusBadGateway, "oci inspect failed",
+			response.WithExtension("code", "E_OCI"),
+			response.WithDetail(err.Error()))
+		return &prob
+	}
+	if meta.Digest != digest {
+		return ociDigestMismatchProblem(src, meta.Digest, fmt.Sprintf("registry served %s for %s", meta.Digest, image))
+	}
+	return nil
+}
+
+// ociDigestUnknown reports whether a pull failure means the registry no
+// longer serves the requested digest.
+func ociDigestUnknown(detail string) bool {
+	detail = strings.ToLower(detail)
+	for _, marker := range []string{"manifest unknown", "manifest_unknown", "no such manifest"} {
+		if strings.Contains(detail, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func ociDigestMismatchProblem(src sourcestore.Source, observed, detail string) *response.Problem {
+	opts := []response.Option{
+		response.WithExtension("code", "oci.digest.mismatch"),
+		response.WithExtension("source", src.Name),
+		response.WithExtension("registered", src.Digest),
+		response.WithDetail(detail),
+	}
+	if observed != "" {
+		opts = append(opts, response.WithExtension("observed", observed))
+	}
+	prob := response.New(http.StatusConflict, "oci digest mismatch", opts...)
+	return &prob
+}
+
+// materializeOCIJob writes a job directory for an add-on job under the
+// source's cache directory: a container config carrying the manifest's
+// argspec and a phase script that execs the job's entrypoint in the image
+// with the args as flags. Existing files are only rewritten when their
+// content changes.
+func materializeOCIJob(src sourcestore.Source, job addonManifestJob) (string, error) {
+	if strings.TrimSpace(src.LocalPath) == "" {
+		return "", fmt.Errorf("source %s has no cache directory", src.Name)
+	}
+	image := ociJobImage(src)
+	if image == "" {
+		return "", fmt.Errorf("source %s has no image reference", src.Name)
+	}
+	jobsDir := filepath.Join(src.LocalPath, "jobs")
+	dir := filepath.Join(jobsDir, strings.ReplaceAll(job.ID, ":", "_"))
+	if !isSubPath(dir, jobsDir) {
+		return "", fmt.Errorf("invalid job id %q", job.ID)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "config.d"), 0o755); err != nil {
+		return "", fmt.Errorf("create job dir: %w", err)
+	}
+
+	cfg := types.Config{
+		Interpreter: "container:" + image,
+		Executor:    "container",
+		ArgsStyle:   types.ArgsStyleFlags,
+	}
+	if spec := convertManifestArgSpec(job.Argspec); len(spec.Args) > 0 {
+		cfg.ArgSpec = &spec
+	}
+	cfgData, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("encode job config: %w", err)
+	}
+	if err := writeIfChanged(filepath.Join(dir, "config.d", "config.yaml"), cfgData, 0o644); err != nil {
+		return "", fmt.Errorf("write job config: %w", err)
+	}
+
+	entrypoint := job.Entrypoint
+	if len(entrypoint) == 0 {
+		entrypoint = []string{addonJobsMountPath + job.ID}
+	}
+	quoted := make([]string, 0, len(entrypoint))
+	for _, part := range entrypoint {
+		quoted = append(quoted, "'"+strings.ReplaceAll(part, "'", `'\''`)+"'")
+	}
+	script := fmt.Sprintf("#!/bin/sh\nexec %s \"$@\"\n", strings.Join(quoted, " "))
+	if err := writeIfChanged(filepath.Join(dir, addonJobScript), []byte(script), 0o755); err != nil {
+		return "", fmt.Errorf("write job script: %w", err)
+	}
+	return dir, nil
+}
+
+// writeIfChanged atomically replaces path with data and mode unless it already
+// holds data, so concurrent runs of the same job do not race on the files.
+func writeIfChanged(path string, data []byte, mode os.FileMode) error {
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return err
+	}
+	return os.Chmod(path, mode)
+}
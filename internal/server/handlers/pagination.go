@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/flowd-org/flowd/internal/server/headers"
+	"github.com/flowd-org/flowd/internal/server/response"
+)
+
+const (
+	defaultPage       = 1
+	defaultPerPage    = 50
+	defaultMaxPerPage = 200
+)
+
+// Pagination bounds the per_page query parameter of list endpoints.
+type Pagination struct {
+	DefaultPerPage int
+	MaxPerPage     int
+}
+
+// normalized fills zero bounds with the defaults and caps DefaultPerPage at
+// MaxPerPage.
+func (p Pagination) normalized() Pagination {
+	if p.MaxPerPage <= 0 {
+		p.MaxPerPage = defaultMaxPerPage
+	}
+	if p.DefaultPerPage <= 0 {
+		p.DefaultPerPage = defaultPerPage
+	}
+	if p.DefaultPerPage > p.MaxPerPage {
+		p.DefaultPerPage = p.MaxPerPage
+	}
+	return p
+}
+
+// parse reads page and per_page from the query.
+func (p Pagination) parse(r *http.Request) (page int, perPage int, err error) {
+	page = defaultPage
+	perPage = p.DefaultPerPage
+
+	q := r.URL.Query()
+
+	if v := q.Get("page"); v != "" {
+		page, err = strconv.Atoi(v)
+		if err != nil || page <= 0 {
+			return 0, 0, errInvalidPage(v)
+		}
+	}
+
+	if v := q.Get("per_page"); v != "" {
+		perPage, err = strconv.Atoi(v)
+		if err != nil {
+			return 0, 0, errInvalidPerPage(v, p.MaxPerPage)
+		}
+		if perPage <= 0 || perPage > p.MaxPerPage {
+			return 0, 0, errInvalidPerPage(v, p.MaxPerPage)
+		}
+	}
+
+	return page, perPage, nil
+}
+
+// writePaginationHeaders reports the size of the full listing and how many
+// items follow the requested page, so clients can render pagers.
+func writePaginationHeaders(w http.ResponseWriter, total, page, perPage int) {
+	remaining := total - page*perPage
+	if remaining < 0 {
+		remaining = 0
+	}
+	w.Header().Set(headers.TotalCount, strconv.Itoa(total))
+	w.Header().Set(headers.RemainingCount, strconv.Itoa(remaining))
+}
+
+// invalidSortProblem reports a sort parameter rejected by sortspec.Parse.
+func invalidSortProblem(err error) response.Problem {
+	return response.New(http.StatusBadRequest, "invalid sort",
+		response.WithExtension("code", "sort.invalid"),
+		response.WithDetail(err.Error()))
+}
+
+func errInvalidPage(v string) error {
+	return &paginationError{Msg: "page must be a positive integer", Value: v}
+}
+
+func errInvalidPerPage(v string, max int) error {
+	return &paginationError{Msg: "per_page must be between 1 and " + strconv.Itoa(max), Value: v}
+}
+
+type paginationError struct {
+	Msg   string
+	Value string
+}
+
+func (e *paginationError) Error() string {
+	return e.Msg + " (got " + e.Value + ")"
+}
@@ -0,0 +1,539 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/configloader"
+	"github.com/flowd-org/flowd/internal/server/pipelinestore"
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/sse"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// PipelinesConfig configures the pipelines handler.
+type PipelinesConfig struct {
+	Root          string
+	LoadPipelines func(root string) ([]types.Pipeline, error)
+	Store         *pipelinestore.Store
+	// Runs launches stage runs and reports their status.
+	Runs *RunsHandler
+	// Events receives pipeline.promotion notifications (typically the global
+	// event stream).
+	Events EventSink
+	Now    func() time.Time
+}
+
+// PipelinesHandler serves /pipelines and promotions through their stages.
+type PipelinesHandler struct {
+	root   string
+	load   func(root string) ([]types.Pipeline, error)
+	store  *pipelinestore.Store
+	runs   *RunsHandler
+	events EventSink
+	now    func() time.Time
+	// mu serializes promotion state changes so stage ordering and approval
+	// counts are evaluated against a consistent view.
+	mu sync.Mutex
+}
+
+type promotionRequest struct {
+	Version string `json:"version"`
+	Stage   string `json:"stage,omitempty"`
+}
+
+type promotionDecision struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+// NewPipelinesHandler returns the handler for /pipelines routes.
+func NewPipelinesHandler(cfg PipelinesConfig) *PipelinesHandler {
+	root := cfg.Root
+	if root == "" {
+		root = "scripts"
+	}
+	load := cfg.LoadPipelines
+	if load == nil {
+		load = configloader.LoadPipelines
+	}
+	store := cfg.Store
+	if store == nil {
+		store = pipelinestore.New()
+	}
+	nowFn := cfg.Now
+	if nowFn == nil {
+		nowFn = func() time.Time { return time.Now().UTC() }
+	}
+	return &PipelinesHandler{
+		root:   root,
+		load:   load,
+		store:  store,
+		runs:   cfg.Runs,
+		events: cfg.Events,
+		now:    nowFn,
+	}
+}
+
+// ServeHTTP routes:
+//
+//	GET  /pipelines
+//	GET  /pipelines/{id}
+//	GET  /pipelines/{id}/promotions
+//	POST /pipelines/{id}/promotions
+//	GET  /pipelines/{id}/promotions/{promotion_id}
+//	POST /pipelines/{id}/promotions/{promotion_id}:approve
+//	POST /pipelines/{id}/promotions/{promotion_id}:reject
+func (h *PipelinesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/pipelines"), "/")
+	if path == "" {
+		if r.Method != http.MethodGet {
+			response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+			return
+		}
+		h.handleList(w)
+		return
+	}
+	parts := strings.Split(path, "/")
+	pipeline, prob := h.pipeline(parts[0])
+	if prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		h.handleGet(w, pipeline)
+	case len(parts) == 2 && parts[1] == "promotions" && r.Method == http.MethodGet:
+		writePipelineJSON(w, http.StatusOK, map[string]any{"promotions": h.syncedPromotions(pipeline.ID)})
+	case len(parts) == 2 && parts[1] == "promotions" && r.Method == http.MethodPost:
+		h.handlePromote(w, r, pipeline)
+	case len(parts) == 3 && parts[1] == "promotions" && r.Method == http.MethodGet:
+		promo, ok := h.store.Get(parts[2])
+		if !ok || promo.PipelineID != pipeline.ID {
+			response.Write(w, response.New(http.StatusNotFound, "promotion not found"))
+			return
+		}
+		h.mu.Lock()
+		promo = h.refresh(promo)
+		h.mu.Unlock()
+		writePipelineJSON(w, http.StatusOK, promo)
+	case len(parts) == 3 && parts[1] == "promotions" && r.Method == http.MethodPost:
+		id, action, ok := strings.Cut(parts[2], ":")
+		if !ok || (action != "approve" && action != "reject") {
+			response.Write(w, response.New(http.StatusNotFound, "not found"))
+			return
+		}
+		h.handleDecision(w, r, pipeline, id, action)
+	case len(parts) <= 3:
+		response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+	default:
+		response.Write(w, response.New(http.StatusNotFound, "not found"))
+	}
+}
+
+func (h *PipelinesHandler) pipeline(id string) (types.Pipeline, *response.Problem) {
+	pipelines, err := h.load(h.root)
+	if err != nil {
+		prob := response.New(http.StatusInternalServerError, "load pipelines failed", response.WithDetail(err.Error()))
+		return types.Pipeline{}, &prob
+	}
+	for _, p := range pipelines {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	prob := response.New(http.StatusNotFound, "pipeline not found", response.WithDetail(id))
+	return types.Pipeline{}, &prob
+}
+
+func (h *PipelinesHandler) handleList(w http.ResponseWriter) {
+	pipelines, err := h.load(h.root)
+	if err != nil {
+		response.Write(w, response.New(http.StatusInternalServerError, "load pipelines failed", response.WithDetail(err.Error())))
+		return
+	}
+	if pipelines == nil {
+		pipelines = []types.Pipeline{}
+	}
+	writePipelineJSON(w, http.StatusOK, map[string]any{"pipelines": pipelines})
+}
+
+// handleGet returns the definition with the version currently deployed to
+// each stage, i.e. that of its latest succeeded promotion.
+func (h *PipelinesHandler) handleGet(w http.ResponseWriter, pipeline types.Pipeline) {
+	current := map[string]string{}
+	for _, p := range h.syncedPromotions(pipeline.ID) {
+		if p.Status == pipelinestore.StatusSucceeded {
+			current[p.Stage] = p.Version
+		}
+	}
+	writePipelineJSON(w, http.StatusOK, map[string]any{"pipeline": pipeline, "current": current})
+}
+
+func (h *PipelinesHandler) handlePromote(w http.ResponseWriter, r *http.Request, pipeline types.Pipeline) {
+	var req promotionRequest
+	if err := decodePipelineBody(r.Body, &req); err != nil {
+		response.Write(w, response.New(http.StatusBadRequest, "invalid request body", response.WithDetail(err.Error())))
+		return
+	}
+	req.Version = strings.TrimSpace(req.Version)
+	if req.Version == "" {
+		response.Write(w, response.New(http.StatusBadRequest, "version is required"))
+		return
+	}
+	ctx := r.Context()
+	principal, _ := requestctx.Principal(ctx)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	history := h.refreshAll(pipeline.ID)
+
+	idx, prob := promotionStage(pipeline, history, req)
+	if prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+	stage := pipeline.Stages[idx]
+	for _, p := range history {
+		if p.Stage == stage.Name && p.Active() {
+			response.Write(w, response.New(http.StatusConflict, "promotion in progress",
+				response.WithExtension("code", "promotion.in_progress"),
+				response.WithExtension("promotion_id", p.ID),
+				response.WithDetail(fmt.Sprintf("stage %s already has promotion %s (%s)", stage.Name, p.ID, p.Status))))
+			return
+		}
+	}
+
+	now := h.now()
+	promo := pipelinestore.Promotion{
+		ID:          newPromotionID(),
+		PipelineID:  pipeline.ID,
+		Stage:       stage.Name,
+		Version:     req.Version,
+		RequestedBy: principal,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	appendAudit(&promo, now, "requested", principal, fmt.Sprintf("promote %s to %s", req.Version, stage.Name))
+	if required := approvalsRequired(stage); required > 0 {
+		promo.Status = pipelinestore.StatusPendingApproval
+		appendAudit(&promo, now, "awaiting_approval", "", fmt.Sprintf("%d approval(s) required", required))
+		h.store.Create(promo)
+		h.publish(ctx, promo)
+		writePipelineJSON(w, http.StatusAccepted, promo)
+		return
+	}
+	if prob := h.startStageRun(ctx, pipeline, idx, &promo); prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+	h.store.Create(promo)
+	h.publish(ctx, promo)
+	writePipelineJSON(w, http.StatusCreated, promo)
+}
+
+func (h *PipelinesHandler) handleDecision(w http.ResponseWriter, r *http.Request, pipeline types.Pipeline, id, action string) {
+	var decision promotionDecision
+	if err := decodePipelineBody(r.Body, &decision); err != nil {
+		response.Write(w, response.New(http.StatusBadRequest, "invalid request body", response.WithDetail(err.Error())))
+		return
+	}
+	ctx := r.Context()
+	principal, _ := requestctx.Principal(ctx)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	promo, ok := h.store.Get(id)
+	if !ok || promo.PipelineID != pipeline.ID {
+		response.Write(w, response.New(http.StatusNotFound, "promotion not found"))
+		return
+	}
+	if promo.Status != pipelinestore.StatusPendingApproval {
+		response.Write(w, response.New(http.StatusConflict, "promotion not awaiting approval",
+			response.WithExtension("code", "promotion.not_pending"),
+			response.WithDetail(fmt.Sprintf("promotion %s is %s", promo.ID, promo.Status))))
+		return
+	}
+	idx := stageIndex(pipeline, promo.Stage)
+	if idx < 0 {
+		response.Write(w, response.New(http.StatusConflict, "stage no longer exists", response.WithDetail(promo.Stage)))
+		return
+	}
+	stage := pipeline.Stages[idx]
+	if prob := checkApprover(stage, promo, principal); prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+
+	now := h.now()
+	promo.UpdatedAt = now
+	if action == "reject" {
+		promo.Status = pipelinestore.StatusRejected
+		appendAudit(&promo, now, "rejected", principal, decision.Comment)
+		h.store.Update(promo)
+		h.publish(ctx, promo)
+		writePipelineJSON(w, http.StatusOK, promo)
+		return
+	}
+
+	promo.Approvals = append(promo.Approvals, pipelinestore.Approval{Principal: principal, At: now})
+	appendAudit(&promo, now, "approved", principal, decision.Comment)
+	if len(promo.Approvals) >= approvalsRequired(stage) {
+		if prob := h.startStageRun(ctx, pipeline, idx, &promo); prob != nil {
+			// The approval stands; the run can be retried with a new promotion.
+			promo.Status = pipelinestore.StatusFailed
+			appendAudit(&promo, now, "run_rejected", "", prob.Title)
+			h.store.Update(promo)
+			h.publish(ctx, promo)
+			response.Write(w, *prob)
+			return
+		}
+	}
+	h.store.Update(promo)
+	h.publish(ctx, promo)
+	writePipelineJSON(w, http.StatusOK, promo)
+}
+
+// startStageRun launches the stage job and moves promo to running.
+func (h *PipelinesHandler) startStageRun(ctx context.Context, pipeline types.Pipeline, idx int, promo *pipelinestore.Promotion) *response.Problem {
+	if h.runs == nil {
+		prob := response.New(http.StatusServiceUnavailable, "runs unavailable")
+		return &prob
+	}
+	stage := pipeline.Stages[idx]
+	req := runRequest{JobID: stage.Job, Args: map[string]any{}}
+	for k, v := range stage.Args {
+		req.Args[k] = v
+	}
+	if stage.VersionArg != "" {
+		req.Args[stage.VersionArg] = promo.Version
+	}
+	if stage.Chain {
+		if prev, ok := latestSucceeded(h.store.List(pipeline.ID), pipeline.Stages[idx-1].Name, promo.Version); ok {
+			req.InputsFromRun = prev.RunID
+		}
+	}
+	run, prob := h.runs.launchRun(ctx, req)
+	if prob != nil {
+		return prob
+	}
+	now := h.now()
+	promo.Status = pipelinestore.StatusRunning
+	promo.RunID = run.ID
+	promo.UpdatedAt = now
+	appendAudit(promo, now, "run_started", "", run.ID)
+	return nil
+}
+
+// promotionStage picks the target stage. Without an explicit stage the
+// version advances to the stage after the furthest one it succeeded in. A
+// stage other than the first requires the version to have succeeded in the
+// previous stage.
+func promotionStage(pipeline types.Pipeline, history []pipelinestore.Promotion, req promotionRequest) (int, *response.Problem) {
+	idx := 0
+	if req.Stage != "" {
+		idx = stageIndex(pipeline, req.Stage)
+		if idx < 0 {
+			prob := response.New(http.StatusNotFound, "stage not found", response.WithDetail(req.Stage))
+			return -1, &prob
+		}
+	} else {
+		for i := len(pipeline.Stages) - 1; i >= 0; i-- {
+			if _, ok := latestSucceeded(history, pipeline.Stages[i].Name, req.Version); ok {
+				idx = i + 1
+				break
+			}
+		}
+		if idx >= len(pipeline.Stages) {
+			prob := response.New(http.StatusConflict, "version already promoted",
+				response.WithExtension("code", "promotion.complete"),
+				response.WithDetail(fmt.Sprintf("version %s has reached the final stage", req.Version)))
+			return -1, &prob
+		}
+	}
+	if idx > 0 {
+		prev := pipeline.Stages[idx-1].Name
+		if _, ok := latestSucceeded(history, prev, req.Version); !ok {
+			prob := response.New(http.StatusConflict, "promotion out of order",
+				response.WithExtension("code", "promotion.order"),
+				response.WithExtension("required_stage", prev),
+				response.WithDetail(fmt.Sprintf("version %s has not succeeded in stage %s", req.Version, prev)))
+			return -1, &prob
+		}
+	}
+	return idx, nil
+}
+
+// checkApprover enforces the stage's approver list, forbids self-approval by
+// the requester and counts each principal once.
+func checkApprover(stage types.PipelineStage, promo pipelinestore.Promotion, principal string) *response.Problem {
+	if !isApprover(stage.Approval, principal) {
+		return approvalDenied("approval.not_approver", fmt.Sprintf("%q is not an approver for stage %s", principal, stage.Name))
+	}
+	if principal != "" && principal == promo.RequestedBy {
+		return approvalDenied("approval.self", "the requester cannot decide their own promotion")
+	}
+	for _, a := range promo.Approvals {
+		if a.Principal == principal {
+			return approvalDenied("approval.duplicate", fmt.Sprintf("%q already approved this promotion", principal))
+		}
+	}
+	return nil
+}
+
+// isApprover reports whether principal may approve under policy. A policy
+// without an approver list admits anyone.
+func isApprover(policy *types.ApprovalPolicy, principal string) bool {
+	if policy == nil || len(policy.Approvers) == 0 {
+		return true
+	}
+	for _, a := range policy.Approvers {
+		if a == principal {
+			return true
+		}
+	}
+	return false
+}
+
+func approvalDenied(code, detail string) *response.Problem {
+	prob := response.New(http.StatusForbidden, "approval not allowed",
+		response.WithExtension("code", code),
+		response.WithDetail(detail))
+	return &prob
+}
+
+// refresh settles a running promotion from its run's status; callers hold mu.
+func (h *PipelinesHandler) refresh(promo pipelinestore.Promotion) pipelinestore.Promotion {
+	if promo.Status != pipelinestore.StatusRunning || h.runs == nil {
+		return promo
+	}
+	run, ok := h.runs.store.Get(promo.RunID)
+	if !ok || !isTerminalStatus(run.Status) {
+		return promo
+	}
+	at := h.now()
+	if run.FinishedAt != nil {
+		at = *run.FinishedAt
+	}
+	promo.Status = pipelinestore.StatusFailed
+	if run.Status == "completed" {
+		promo.Status = pipelinestore.StatusSucceeded
+	}
+	promo.UpdatedAt = at
+	appendAudit(&promo, at, "run_finished", "", run.Status)
+	h.store.Update(promo)
+	return promo
+}
+
+func (h *PipelinesHandler) syncedPromotions(pipelineID string) []pipelinestore.Promotion {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.refreshAll(pipelineID)
+}
+
+// refreshAll settles every running promotion of a pipeline; callers hold mu.
+func (h *PipelinesHandler) refreshAll(pipelineID string) []pipelinestore.Promotion {
+	promotions := h.store.List(pipelineID)
+	for i := range promotions {
+		promotions[i] = h.refresh(promotions[i])
+	}
+	return promotions
+}
+
+func (h *PipelinesHandler) publish(ctx context.Context, promo pipelinestore.Promotion) {
+	last := promo.Audit[len(promo.Audit)-1]
+	if logger := requestctx.Logger(ctx); logger != nil {
+		logger.Info("pipeline.promotion."+last.Action,
+			slog.String("pipeline", promo.PipelineID),
+			slog.String("promotion_id", promo.ID),
+			slog.String("stage", promo.Stage),
+			slog.String("version", promo.Version),
+			slog.String("actor", last.Actor),
+		)
+	}
+	if h.events == nil {
+		return
+	}
+	data, err := json.Marshal(map[string]any{
+		"promotion": promo,
+		"action":    last.Action,
+		"timestamp": last.At,
+	})
+	if err != nil {
+		return
+	}
+	h.events.Publish("", sse.Event{Event: "pipeline.promotion", Data: string(data)})
+}
+
+func approvalsRequired(stage types.PipelineStage) int {
+	if stage.Approval == nil {
+		return 0
+	}
+	return stage.Approval.Required
+}
+
+func stageIndex(pipeline types.Pipeline, name string) int {
+	for i, st := range pipeline.Stages {
+		if st.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func latestSucceeded(history []pipelinestore.Promotion, stage, version string) (pipelinestore.Promotion, bool) {
+	for i := len(history) - 1; i >= 0; i-- {
+		p := history[i]
+		if p.Stage == stage && p.Version == version && p.Status == pipelinestore.StatusSucceeded {
+			return p, true
+		}
+	}
+	return pipelinestore.Promotion{}, false
+}
+
+func appendAudit(promo *pipelinestore.Promotion, at time.Time, action, actor, detail string) {
+	promo.Audit = append(promo.Audit, pipelinestore.AuditEntry{At: at, Action: action, Actor: actor, Detail: detail})
+}
+
+func newPromotionID() string {
+	var buf [8]byte
+	_, _ = rand.Read(buf[:])
+	return "promo-" + hex.EncodeToString(buf[:])
+}
+
+func decodePipelineBody(body io.ReadCloser, out any) error {
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(out)
+}
+
+func writePipelineJSON(w http.ResponseWriter, status int, body any) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		response.Write(w, response.New(http.StatusInternalServerError, "encode response failed", response.WithDetail(err.Error())))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/pipelinestore"
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+func writeReleasePipeline(t *testing.T, root string) {
+	t.Helper()
+	writeJobConfig(t, root, "deploy", `
+version: v1
+job:
+  id: deploy
+  name: Deploy
+interpreter: bash
+argspec:
+  args:
+    - name: version
+      type: string
+      required: true
+    - name: target
+      type: string
+`)
+	if err := os.WriteFile(filepath.Join(root, "deploy", "100_main.sh"), []byte("echo \"$ARG_TARGET $ARG_VERSION\"\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "pipelines"), 0o755); err != nil {
+		t.Fatalf("mkdir pipelines: %v", err)
+	}
+	pipeline := `
+pipeline:
+  id: release
+  stages:
+    - name: dev
+      job: deploy
+      version_arg: version
+      args:
+        target: dev
+    - name: prod
+      job: deploy
+      version_arg: version
+      args:
+        target: prod
+      approval:
+        approvers: [alice]
+`
+	if err := os.WriteFile(filepath.Join(root, "pipelines", "release.yaml"), []byte(pipeline), 0o644); err != nil {
+		t.Fatalf("write pipeline: %v", err)
+	}
+}
+
+func pipelineRequest(t *testing.T, h http.Handler, method, path, principal, body string) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if principal != "" {
+		req = req.WithContext(requestctx.WithPrincipal(req.Context(), principal))
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	var out map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &out)
+	return rec, out
+}
+
+func waitForPromotion(t *testing.T, h http.Handler, id, status string) {
+	t.Helper()
+	waitFor(func() bool {
+		_, promo := pipelineRequest(t, h, http.MethodGet, "/pipelines/release/promotions/"+id, "", "")
+		return promo["status"] == status
+	}, 5*time.Second, t)
+}
+
+func TestPipelinePromotesThroughStagesWithApproval(t *testing.T) {
+	root := t.TempDir()
+	writeReleasePipeline(t, root)
+	runs := NewRunsHandler(RunsConfig{Root: root, Store: runstore.New(), Events: &recordingSink{}})
+	h := NewPipelinesHandler(PipelinesConfig{Root: root, Runs: runs})
+
+	rec, _ := pipelineRequest(t, h, http.MethodPost, "/pipelines/release/promotions", "bob", `{"version":"1.0.0","stage":"prod"}`)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "promotion.order") {
+		t.Fatalf("expected out-of-order promotion to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec, dev := pipelineRequest(t, h, http.MethodPost, "/pipelines/release/promotions", "bob", `{"version":"1.0.0"}`)
+	if rec.Code != http.StatusCreated || dev["stage"] != "dev" || dev["status"] != pipelinestore.StatusRunning {
+		t.Fatalf("expected dev promotion to start, got %d: %s", rec.Code, rec.Body.String())
+	}
+	runID, _ := dev["run_id"].(string)
+	if run, ok := runs.store.Get(runID); !ok || run.Result["resolved_args"].(map[string]any)["target"] != "dev" {
+		t.Fatalf("expected stage args on run %s, got %+v", runID, run)
+	}
+	waitForPromotion(t, h, dev["id"].(string), pipelinestore.StatusSucceeded)
+
+	rec, prod := pipelineRequest(t, h, http.MethodPost, "/pipelines/release/promotions", "bob", `{"version":"1.0.0"}`)
+	if rec.Code != http.StatusAccepted || prod["stage"] != "prod" || prod["status"] != pipelinestore.StatusPendingApproval {
+		t.Fatalf("expected prod promotion to await approval, got %d: %s", rec.Code, rec.Body.String())
+	}
+	prodID := prod["id"].(string)
+
+	rec, _ = pipelineRequest(t, h, http.MethodPost, "/pipelines/release/promotions/"+prodID+":approve", "carol", "")
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "approval.not_approver") {
+		t.Fatalf("expected non-approver to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec, approved := pipelineRequest(t, h, http.MethodPost, "/pipelines/release/promotions/"+prodID+":approve", "alice", `{"comment":"ship it"}`)
+	if rec.Code != http.StatusOK || approved["status"] != pipelinestore.StatusRunning {
+		t.Fatalf("expected approval to start the run, got %d: %s", rec.Code, rec.Body.String())
+	}
+	waitForPromotion(t, h, prodID, pipelinestore.StatusSucceeded)
+
+	_, view := pipelineRequest(t, h, http.MethodGet, "/pipelines/release", "", "")
+	if current, _ := view["current"].(map[string]any); current["dev"] != "1.0.0" || current["prod"] != "1.0.0" {
+		t.Fatalf("expected both stages at 1.0.0, got %v", view["current"])
+	}
+	_, final := pipelineRequest(t, h, http.MethodGet, "/pipelines/release/promotions/"+prodID, "", "")
+	var actions []string
+	for _, entry := range final["audit"].([]any) {
+		actions = append(actions, entry.(map[string]any)["action"].(string))
+	}
+	if got := strings.Join(actions, ","); got != "requested,awaiting_approval,approved,run_started,run_finished" {
+		t.Fatalf("unexpected audit trail %s", got)
+	}
+}
+
+func TestPipelineRejectsSelfApproval(t *testing.T) {
+	root := t.TempDir()
+	writeReleasePipeline(t, root)
+	store := pipelinestore.New()
+	now := time.Now().UTC()
+	store.Create(pipelinestore.Promotion{
+		ID: "promo-1", PipelineID: "release", Stage: "prod", Version: "1.0.0",
+		Status: pipelinestore.StatusPendingApproval, RequestedBy: "alice", CreatedAt: now, UpdatedAt: now,
+	})
+	h := NewPipelinesHandler(PipelinesConfig{Root: root, Store: store})
+
+	rec, _ := pipelineRequest(t, h, http.MethodPost, "/pipelines/release/promotions/promo-1:approve", "alice", "")
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "approval.self") {
+		t.Fatalf("expected self-approval to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,259 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/executor"
+	"github.com/flowd-org/flowd/internal/server/metrics"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+const (
+	defaultPlanCacheEntries = 512
+	defaultPlanCacheTTL     = 5 * time.Minute
+)
+
+// PlanCacheConfig tunes the compiled plan cache.
+type PlanCacheConfig struct {
+	MaxEntries int
+	TTL        time.Duration
+	Now        func() time.Time
+}
+
+// PlanCache memoizes compiled POST /plans responses for local jobs. Entries are
+// keyed by (config digest, argspec digest, script digests, canonical args,
+// effective profile, policy version) and the whole cache is dropped by Purge,
+// which the server wires to the indexer watcher so edits to job configs take
+// effect immediately. The watcher does not see script edits by default, so
+// scripts are rehashed on every lookup.
+type PlanCache struct {
+	mu      sync.Mutex
+	max     int
+	ttl     time.Duration
+	now     func() time.Time
+	jobs    map[string]planCacheJob
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+// planCacheJob records how a requested job id resolved on the last miss so a
+// subsequent request can build its cache key without rediscovering the tree.
+type planCacheJob struct {
+	dir             string
+	config          *types.Config
+	configDigest    string
+	argspecDigest   string
+	securityProfile string
+}
+
+type planCacheEntry struct {
+	key     string
+	body    []byte
+	expires time.Time
+}
+
+// NewPlanCache constructs an empty plan cache.
+func NewPlanCache(cfg PlanCacheConfig) *PlanCache {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = defaultPlanCacheEntries
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultPlanCacheTTL
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &PlanCache{
+		max:     cfg.MaxEntries,
+		ttl:     cfg.TTL,
+		now:     cfg.Now,
+		jobs:    make(map[string]planCacheJob),
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Purge drops every cached job resolution and plan.
+func (c *PlanCache) Purge() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.jobs = make(map[string]planCacheJob)
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	metrics.Default.RecordPlanCache("invalidated")
+}
+
+// Len reports the number of cached plans.
+func (c *PlanCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *PlanCache) lookupJob(requestedID string) (planCacheJob, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	job, ok := c.jobs[strings.ToLower(requestedID)]
+	return job, ok
+}
+
+func (c *PlanCache) lookup(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*planCacheEntry)
+	if c.now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.body, true
+}
+
+func (c *PlanCache) store(requestedID string, job planCacheJob, key string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.jobs[strings.ToLower(requestedID)] = job
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*planCacheEntry)
+		entry.body = body
+		entry.expires = c.now().Add(c.ttl)
+		c.order.MoveToFront(elem)
+		return
+	}
+	elem := c.order.PushFront(&planCacheEntry{key: key, body: body, expires: c.now().Add(c.ttl)})
+	c.entries[key] = elem
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*planCacheEntry).key)
+	}
+}
+
+// planCacheHit is a cached plan with the script digests and effective
+// profile it was looked up with, so callers can re-run the script allow-list.
+type planCacheHit struct {
+	body    []byte
+	scripts []types.ScriptDigest
+	profile string
+}
+
+// get returns the cached plan for req when its job resolution and key are
+// known and its scripts are unchanged.
+func (c *PlanCache) get(req planRequest, serverProfile, policyVersion string) (planCacheHit, bool) {
+	job, ok := c.lookupJob(req.JobID)
+	if !ok {
+		metrics.Default.RecordPlanCache("miss")
+		return planCacheHit{}, false
+	}
+	profile, err := resolveEffectiveProfile(req.RequestedSecurityProfile, job.securityProfile, serverProfile)
+	if err != nil {
+		return planCacheHit{}, false
+	}
+	scripts, err := executor.ScriptDigests(job.dir, job.config)
+	if err != nil {
+		return planCacheHit{}, false
+	}
+	key, ok := planCacheKey(req.JobID, job, scripts, req.Args, profile, policyVersion)
+	if !ok {
+		return planCacheHit{}, false
+	}
+	body, ok := c.lookup(key)
+	if !ok {
+		metrics.Default.RecordPlanCache("miss")
+		return planCacheHit{}, false
+	}
+	metrics.Default.RecordPlanCache("hit")
+	return planCacheHit{body: body, scripts: scripts, profile: profile}, true
+}
+
+// put records a freshly compiled plan for req.
+func (c *PlanCache) put(req planRequest, jobPath string, cfg *types.Config, plan types.Plan, profile, policyVersion string) {
+	job, ok := newPlanCacheJob(jobPath, cfg)
+	if !ok {
+		return
+	}
+	key, ok := planCacheKey(req.JobID, job, plan.Scripts, req.Args, profile, policyVersion)
+	if !ok {
+		return
+	}
+	body, err := json.Marshal(plan)
+	if err != nil {
+		return
+	}
+	c.store(req.JobID, job, key, body)
+}
+
+// newPlanCacheJob digests the job config file and argspec. It reports false
+// when the config cannot be read, in which case the plan is not cached.
+func newPlanCacheJob(jobPath string, cfg *types.Config) (planCacheJob, bool) {
+	data, err := os.ReadFile(filepath.Join(jobPath, "config.d", "config.yaml"))
+	if err != nil {
+		return planCacheJob{}, false
+	}
+	job := planCacheJob{dir: jobPath, config: cfg, configDigest: digestBytes(data)}
+	if cfg != nil {
+		job.securityProfile = cfg.SecurityProfile
+		if cfg.ArgSpec != nil {
+			if spec, err := json.Marshal(cfg.ArgSpec); err == nil {
+				job.argspecDigest = digestBytes(spec)
+			}
+		}
+	}
+	return job, true
+}
+
+// planCacheKey derives the cache key for a request. Args are canonicalized so
+// that key order in the request body does not fragment the cache.
+func planCacheKey(requestedID string, job planCacheJob, scripts []types.ScriptDigest, args map[string]interface{}, profile, policyVersion string) (string, bool) {
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return "", false
+	}
+	canonicalArgs, err := canonicalizeJSON(raw)
+	if err != nil {
+		return "", false
+	}
+	scriptParts := make([]string, 0, len(scripts))
+	for _, script := range scripts {
+		scriptParts = append(scriptParts, script.Path+"="+script.SHA256)
+	}
+	parts := []string{
+		strings.ToLower(requestedID),
+		job.configDigest,
+		job.argspecDigest,
+		strings.Join(scriptParts, "\n"),
+		string(canonicalArgs),
+		profile,
+		policyVersion,
+	}
+	return digestBytes([]byte(strings.Join(parts, "\x00"))), true
+}
+
+func digestBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
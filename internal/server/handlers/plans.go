@@ -13,6 +13,7 @@ import (
 
 	"github.com/flowd-org/flowd/internal/configloader"
 	"github.com/flowd-org/flowd/internal/engine"
+	"github.com/flowd-org/flowd/internal/executor"
 	"github.com/flowd-org/flowd/internal/executor/container"
 	"github.com/flowd-org/flowd/internal/indexer"
 	"github.com/flowd-org/flowd/internal/policy"
@@ -28,12 +29,14 @@ import (
 type PlansConfig struct {
 	Root       string
 	Discover   func(string) (indexer.Result, error)
-	LoadConfig func(string) (*types.Config, error)
+	LoadConfig func(dir, overlay string) (*types.Config, error)
 	Sources    *sourcestore.Store
 	Profile    string
 	Policy     *policy.Context
 	Verifier   verify.ImageVerifier
 	Runtime    container.Runtime
+	// Cache memoizes compiled plans for local jobs; nil disables caching.
+	Cache *PlanCache
 }
 
 // NewPlansHandler returns an HTTP handler for POST /plans.
@@ -47,7 +50,7 @@ func NewPlansHandler(cfg PlansConfig) http.Handler {
 	}
 	loadConfig := cfg.LoadConfig
 	if loadConfig == nil {
-		loadConfig = configloader.LoadConfig
+		loadConfig = configloader.LoadConfigOverlay
 	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -68,6 +71,29 @@ func NewPlansHandler(cfg PlansConfig) http.Handler {
 			return
 		}
 
+		// Source checkouts change outside the indexer watcher, so only plans for
+		// jobs under the scripts root are cached. The watcher does not see
+		// overlay files either, so overlay plans are never cached.
+		// Cached plans skip job resolution, so callers confined by RBAC
+		// always plan afresh. Hits still pass the script allow-list.
+		useCache := cfg.Cache != nil && (req.Source == nil || req.Source.Name == "") && req.Overlay == "" && requestctx.AccessFromContext(ctx) == nil
+		if useCache {
+			if hit, ok := cfg.Cache.get(req, cfg.Profile, cfg.Policy.Version()); ok {
+				if prob := enforceScriptAllowList(ctx, hit.scripts, hit.profile, cfg.Policy); prob != nil {
+					response.Write(w, *prob)
+					return
+				}
+				if logger := requestctx.Logger(ctx); logger != nil {
+					logger.Info("plan.generated",
+						slog.String("job_id", req.JobID),
+						slog.String("cache", "hit"),
+					)
+				}
+				writePlanBody(w, hit.body)
+				return
+			}
+		}
+
 		discoverRoot := cfg.Root
 		if discoverRoot == "" {
 			discoverRoot = "scripts"
@@ -83,6 +109,10 @@ func NewPlansHandler(cfg PlansConfig) http.Handler {
 				response.Write(w, response.New(http.StatusNotFound, "source not found", response.WithDetail(req.Source.Name)))
 				return
 			}
+			if source.Quarantine != nil {
+				response.Write(w, sourceQuarantinedProblem(source))
+				return
+			}
 			if source.LocalPath == "" {
 				response.Write(w, response.New(http.StatusBadRequest, "source not materialized", response.WithDetail("source "+req.Source.Name+" has no local checkout")))
 				return
@@ -155,6 +185,9 @@ func NewPlansHandler(cfg PlansConfig) http.Handler {
 				canonicalPath = aliasUsed.TargetPath
 			}
 			plan.Provenance["canonical_path"] = canonicalPath
+			if req.Overlay != "" {
+				plan.Provenance["overlay"] = req.Overlay
+			}
 		}
 
 		if !setJobPath(effectiveID) {
@@ -186,7 +219,17 @@ func NewPlansHandler(cfg PlansConfig) http.Handler {
 				response.Write(w, *aliasValidationProblem(requestedID, validation))
 				return
 			}
+			if !jobVisible(ctx, requestedID, "") {
+				response.Write(w, response.New(http.StatusNotFound, "job not found", response.WithDetail(requestedID)))
+				return
+			}
 			ociPlan, attrs, handled, prob, planErr := tryBuildOCIPlan(r, req, cfg)
+			if handled && req.Overlay != "" {
+				response.Write(w, response.New(http.StatusUnprocessableEntity, "overlays apply to script jobs only",
+					response.WithExtension("code", "overlay.unsupported"),
+					response.WithDetail("OCI add-on jobs do not have config overlays")))
+				return
+			}
 			if handled {
 				if planErr != nil {
 					response.Write(w, response.New(http.StatusInternalServerError, "plan generation failed", response.WithDetail(planErr.Error())))
@@ -205,10 +248,18 @@ func NewPlansHandler(cfg PlansConfig) http.Handler {
 			response.Write(w, response.New(http.StatusNotFound, "job not found", response.WithDetail(requestedID)))
 			return
 		}
+		sourceName := ""
+		if req.Source != nil {
+			sourceName = req.Source.Name
+		}
+		if !jobVisible(ctx, effectiveID, sourceName) {
+			response.Write(w, response.New(http.StatusNotFound, "job not found", response.WithDetail(requestedID)))
+			return
+		}
 
-		cfgObj, err := loadConfig(jobPath)
-		if err != nil {
-			response.Write(w, response.New(http.StatusInternalServerError, "load config failed", response.WithDetail(err.Error())))
+		cfgObj, prob := loadJobConfig(loadConfig, jobPath, req.Overlay)
+		if prob != nil {
+			response.Write(w, *prob)
 			return
 		}
 		isDAG := isDAGConfig(cfgObj)
@@ -238,8 +289,12 @@ func NewPlansHandler(cfg PlansConfig) http.Handler {
 			response.Write(w, response.New(http.StatusBadRequest, "job does not accept arguments"))
 			return
 		}
+		if prob := applyArgEnv(cfgObj, spec, binding); prob != nil {
+			response.Write(w, *prob)
+			return
+		}
 
-		effProfile, err := resolveEffectiveProfile(req.RequestedSecurityProfile, cfg.Profile)
+		effProfile, err := resolveEffectiveProfile(req.RequestedSecurityProfile, cfgObj.SecurityProfile, cfg.Profile)
 		if err != nil {
 			response.Write(w, response.New(http.StatusUnprocessableEntity, "invalid security profile",
 				response.WithExtension("code", "E_POLICY"),
@@ -251,6 +306,21 @@ func NewPlansHandler(cfg PlansConfig) http.Handler {
 		if policyCtx == nil {
 			policyCtx, _ = policy.NewContext(nil)
 		}
+		remember := func(plan types.Plan) {
+			if useCache {
+				cfg.Cache.put(req, jobPath, cfgObj, plan, effProfile, policyCtx.Version())
+			}
+		}
+
+		scripts, err := executor.ScriptDigests(jobPath, cfgObj)
+		if err != nil {
+			response.Write(w, response.New(http.StatusInternalServerError, "hash scripts failed", response.WithDetail(err.Error())))
+			return
+		}
+		if prob := enforceScriptAllowList(ctx, scripts, effProfile, policyCtx); prob != nil {
+			response.Write(w, *prob)
+			return
+		}
 
 		runtimeVal := cfg.Runtime
 		runtimeStr := string(runtimeVal)
@@ -278,6 +348,7 @@ func NewPlansHandler(cfg PlansConfig) http.Handler {
 				return
 			}
 			annotatePlan(&plan)
+			plan.Scripts = scripts
 			if prob != nil {
 				response.Write(w, *prob)
 				return
@@ -292,6 +363,7 @@ func NewPlansHandler(cfg PlansConfig) http.Handler {
 				}
 				logger.Info("plan.generated", attrs...)
 			}
+			remember(plan)
 			writePlanResponse(w, plan)
 			return
 		}
@@ -306,7 +378,8 @@ func NewPlansHandler(cfg PlansConfig) http.Handler {
 
 		image := containerImageFromConfig(cfgObj)
 		if image != "" {
-			if runtimeVal == "" {
+			// Kubernetes jobs pull their image on the cluster, not locally.
+			if runtimeVal == "" && !strings.EqualFold(strings.TrimSpace(cfgObj.Executor), executor.ExecutorKubernetes) {
 				if _, detectErr := detectContainerRuntime(nil); detectErr != nil {
 					response.Write(w, runtimeUnavailableProblem(detectErr))
 					return
@@ -334,6 +407,7 @@ func NewPlansHandler(cfg PlansConfig) http.Handler {
 				trustPreview = &types.ImageTrustPreview{
 					Image:    image,
 					Mode:     string(mode),
+					Backend:  outcome.Backend,
 					Verified: outcome.Verified,
 					Reason:   outcome.Reason,
 				}
@@ -364,10 +438,12 @@ func NewPlansHandler(cfg PlansConfig) http.Handler {
 		if len(overrideFindings) > 0 {
 			findings = append(findings, overrideFindings...)
 		}
+		findings = append(findings, evaluateEnvPolicy(ctx, cfgObj, effProfile, policyCtx)...)
 
 		plan := engine.BuildPlan(effectiveID, cfgObj, spec, binding)
 		annotatePlan(&plan)
 		plan.SecurityProfile = effProfile
+		plan.Scripts = scripts
 		if len(findings) > 0 {
 			plan.PolicyFindings = findings
 		}
@@ -391,6 +467,7 @@ func NewPlansHandler(cfg PlansConfig) http.Handler {
 			logger.Info("plan.generated", attrs...)
 		}
 
+		remember(plan)
 		writePlanResponse(w, plan)
 	})
 }
@@ -401,6 +478,10 @@ func writePlanResponse(w http.ResponseWriter, plan types.Plan) {
 		response.Write(w, response.New(http.StatusInternalServerError, "encode plan failed", response.WithDetail(err.Error())))
 		return
 	}
+	writePlanBody(w, data)
+}
+
+func writePlanBody(w http.ResponseWriter, data []byte) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write(data)
@@ -411,6 +492,9 @@ type planRequest struct {
 	Args                     map[string]interface{} `json:"args"`
 	Source                   *RunSourceRef          `json:"source"`
 	RequestedSecurityProfile string                 `json:"requested_security_profile"`
+	// Overlay selects config.d/overlays/<overlay>.yaml to merge over the
+	// job config.
+	Overlay string `json:"overlay,omitempty"`
 }
 
 func decodePlanRequest(body io.ReadCloser) (planRequest, error) {
@@ -424,6 +508,7 @@ func decodePlanRequest(body io.ReadCloser) (planRequest, error) {
 	if req.Args == nil {
 		req.Args = map[string]interface{}{}
 	}
+	req.Overlay = strings.TrimSpace(req.Overlay)
 	return req, nil
 }
 
@@ -16,6 +16,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/flowd-org/flowd/internal/configloader"
 	"github.com/flowd-org/flowd/internal/executor/container"
 	"github.com/flowd-org/flowd/internal/indexer"
 	"github.com/flowd-org/flowd/internal/policy"
@@ -66,6 +67,98 @@ argspec:
 	}
 }
 
+func TestPlansHandlerJobSecurityProfileFloor(t *testing.T) {
+	root := t.TempDir()
+	writePlanConfig(t, root, "pinned", `
+version: v1
+job:
+  id: pinned
+  name: Pinned Job
+security_profile: secure
+`)
+
+	h := NewPlansHandler(PlansConfig{Root: root, Profile: "disabled", Runtime: container.Runtime("podman")})
+
+	body := `{"job_id":"pinned","requested_security_profile":"permissive"}`
+	req := httptest.NewRequest(http.MethodPost, "/plans", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var plan types.Plan
+	if err := json.NewDecoder(rr.Body).Decode(&plan); err != nil {
+		t.Fatalf("decode plan: %v", err)
+	}
+	if plan.SecurityProfile != "secure" {
+		t.Fatalf("expected job floor secure, got %q", plan.SecurityProfile)
+	}
+}
+
+func TestPlansHandlerCachesCompiledPlans(t *testing.T) {
+	root := t.TempDir()
+	writePlanConfig(t, root, "demo", `
+version: v1
+job:
+  id: demo
+  name: Demo Job
+argspec:
+  args:
+    - name: name
+      type: string
+      required: true
+`)
+
+	discoveries := 0
+	cache := NewPlanCache(PlanCacheConfig{})
+	h := NewPlansHandler(PlansConfig{
+		Root:    root,
+		Runtime: container.Runtime("podman"),
+		Cache:   cache,
+		Discover: func(dir string) (indexer.Result, error) {
+			discoveries++
+			return indexer.Discover(dir)
+		},
+	})
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/plans", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", rr.Code, rr.Body.String())
+		}
+		return rr
+	}
+
+	first := post(`{"job_id":"demo","args":{"name":"Alice"}}`)
+	second := post(`{"args":{"name":"Alice"},"job_id":"demo"}`)
+	if discoveries != 1 {
+		t.Fatalf("expected cached plan to skip discovery, got %d discoveries", discoveries)
+	}
+	if first.Body.String() != second.Body.String() {
+		t.Fatalf("cached plan differs:\n%s\n%s", first.Body.String(), second.Body.String())
+	}
+
+	post(`{"job_id":"demo","args":{"name":"Bob"}}`)
+	if discoveries != 2 {
+		t.Fatalf("expected different args to miss the cache, got %d discoveries", discoveries)
+	}
+
+	cache.Purge()
+	if cache.Len() != 0 {
+		t.Fatalf("expected purge to empty cache, got %d entries", cache.Len())
+	}
+	post(`{"job_id":"demo","args":{"name":"Alice"}}`)
+	if discoveries != 3 {
+		t.Fatalf("expected purge to force rediscovery, got %d discoveries", discoveries)
+	}
+}
+
 func TestPlansHandlerContainerExecutor(t *testing.T) {
 	root := t.TempDir()
 	writePlanConfig(t, root, "container", `
@@ -189,6 +282,94 @@ steps:
 	}
 }
 
+func TestPlansHandlerDAGStepShellAndWorkdir(t *testing.T) {
+	root := t.TempDir()
+	writePlanConfig(t, root, "dag-shell", `
+version: v1
+job:
+  id: dag-shell
+  name: Shell Steps
+composition: steps
+executor: proc
+interpreter: bash
+steps:
+  - id: build
+    script: scripts/build.sh
+    workdir: out/build
+    shell: bash  -euo pipefail
+  - id: lint
+    script: scripts/lint.sh
+    workdir: checkout:src
+`)
+
+	h := NewPlansHandler(PlansConfig{Root: root})
+	req := httptest.NewRequest(http.MethodPost, "/plans", strings.NewReader(`{"job_id":"dag-shell"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var plan types.Plan
+	if err := json.NewDecoder(rec.Body).Decode(&plan); err != nil {
+		t.Fatalf("decode plan: %v", err)
+	}
+	if len(plan.Steps) != 2 {
+		t.Fatalf("expected 2 steps in preview, got %+v", plan.Steps)
+	}
+	if plan.Steps[0].Shell != "bash -euo pipefail" || plan.Steps[0].Workdir != "run:out/build" {
+		t.Fatalf("unexpected first step preview %+v", plan.Steps[0])
+	}
+	if plan.Steps[1].Shell != "" || plan.Steps[1].Workdir != "checkout:src" {
+		t.Fatalf("unexpected second step preview %+v", plan.Steps[1])
+	}
+}
+
+func TestPlansHandlerDAGStepOptionsValidated(t *testing.T) {
+	for name, step := range map[string]string{
+		"escaping workdir": "workdir: ../../etc",
+		"absolute workdir": "workdir: /tmp",
+		"unknown shell":    "shell: zsh",
+		"shell argument":   "shell: bash ./other.sh",
+	} {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			writePlanConfig(t, root, "dag-bad", `
+version: v1
+job:
+  id: dag-bad
+  name: invalid
+composition: steps
+executor: proc
+interpreter: bash
+steps:
+  - id: a
+    script: scripts/a.sh
+    `+step+`
+`)
+			h := NewPlansHandler(PlansConfig{Root: root})
+			req := httptest.NewRequest(http.MethodPost, "/plans", strings.NewReader(`{"job_id":"dag-bad"}`))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("expected 422, got %d: %s", rec.Code, rec.Body.String())
+			}
+			var problem map[string]any
+			if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+				t.Fatalf("decode problem: %v", err)
+			}
+			if problem["code"] != "E_CONFIG" || !strings.HasPrefix(problem["detail"].(string), "steps[0]: ") {
+				t.Fatalf("unexpected problem %+v", problem)
+			}
+		})
+	}
+}
+
 func TestPlansHandlerRuntimeMissing(t *testing.T) {
 	root := t.TempDir()
 	writePlanConfig(t, root, "container", `
@@ -519,7 +700,7 @@ container:
 
 	h := NewPlansHandler(PlansConfig{
 		Root:     root,
-		Profile:  "secure",
+		Profile:  "permissive",
 		Policy:   policyCtx,
 		Verifier: stubVerifier{result: verify.Result{Verified: false, Reason: "unsigned"}},
 	})
@@ -766,7 +947,6 @@ env_inheritance: true
 }
 
 func TestPlansHandlerOCIJobSuccess(t *testing.T) {
-	t.Setenv("FLWD_PROFILE", "")
 	store := sourcestore.New()
 	tempDir := t.TempDir()
 	manifestPath := filepath.Join(tempDir, "manifest.yaml")
@@ -826,7 +1006,7 @@ jobs:
 		Discover: func(string) (indexer.Result, error) {
 			return indexer.Result{}, nil
 		},
-		LoadConfig: func(string) (*types.Config, error) {
+		LoadConfig: func(string, string) (*types.Config, error) {
 			return nil, errors.New("should not load config for oci")
 		},
 		Runtime: container.Runtime("podman"),
@@ -915,7 +1095,7 @@ jobs:
 		Verifier: stubVerifier{result: verify.Result{Verified: false, Reason: "no signature"}},
 		Runtime:  container.Runtime("podman"),
 		Discover: func(string) (indexer.Result, error) { return indexer.Result{}, nil },
-		LoadConfig: func(string) (*types.Config, error) {
+		LoadConfig: func(string, string) (*types.Config, error) {
 			return nil, errors.New("should not load config for oci")
 		},
 	})
@@ -1099,7 +1279,7 @@ jobs:
 		Verifier: stubVerifier{result: verify.Result{Verified: true}},
 		Runtime:  container.Runtime("podman"),
 		Discover: func(string) (indexer.Result, error) { return indexer.Result{}, nil },
-		LoadConfig: func(string) (*types.Config, error) {
+		LoadConfig: func(string, string) (*types.Config, error) {
 			return nil, errors.New("should not load config for oci")
 		},
 	})
@@ -1174,7 +1354,7 @@ jobs:
 		Verifier: stubVerifier{result: verify.Result{Verified: true}},
 		Runtime:  container.Runtime("podman"),
 		Discover: func(string) (indexer.Result, error) { return indexer.Result{}, nil },
-		LoadConfig: func(string) (*types.Config, error) {
+		LoadConfig: func(string, string) (*types.Config, error) {
 			return nil, errors.New("should not load config for oci")
 		},
 	})
@@ -1196,6 +1376,107 @@ jobs:
 	}
 }
 
+func TestPlansHandlerAppliesOverlay(t *testing.T) {
+	root := t.TempDir()
+	writePlanConfig(t, root, "deploy", `
+version: v1
+job:
+  id: deploy
+  name: Deploy
+env:
+  LOG_LEVEL: debug
+argspec:
+  args:
+    - name: region
+      type: string
+      default: us-east-1
+    - name: replicas
+      type: integer
+      default: 1
+`)
+	overlays := filepath.Join(root, "deploy", "config.d", "overlays")
+	if err := os.MkdirAll(overlays, 0o755); err != nil {
+		t.Fatalf("mkdir overlays: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(overlays, "prod.yaml"), []byte("argspec:\n  args:\n    - name: region\n      default: eu-west-1\n"), 0o644); err != nil {
+		t.Fatalf("write overlay: %v", err)
+	}
+
+	h := NewPlansHandler(PlansConfig{Root: root, Runtime: container.Runtime("podman"), Cache: NewPlanCache(PlanCacheConfig{})})
+	plan := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/plans", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := plan(`{"job_id":"deploy","overlay":"prod"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var got types.Plan
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode plan: %v", err)
+	}
+	if got.ResolvedArgs["region"] != "eu-west-1" {
+		t.Fatalf("expected overlay default eu-west-1, got %+v", got.ResolvedArgs)
+	}
+	if fmt.Sprint(got.ResolvedArgs["replicas"]) != "1" {
+		t.Fatalf("expected base default replicas 1 to survive the merge, got %+v", got.ResolvedArgs)
+	}
+	if got.Provenance["overlay"] != "prod" {
+		t.Fatalf("expected overlay recorded in provenance, got %+v", got.Provenance)
+	}
+
+	rr = plan(`{"job_id":"deploy"}`)
+	got = types.Plan{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode plan: %v", err)
+	}
+	if got.ResolvedArgs["region"] != "us-east-1" {
+		t.Fatalf("expected base default without overlay, got %+v", got.ResolvedArgs)
+	}
+	if _, ok := got.Provenance["overlay"]; ok {
+		t.Fatalf("expected no overlay in provenance, got %+v", got.Provenance)
+	}
+
+	rr = plan(`{"job_id":"deploy","overlay":"../deploy/config.d/config"}`)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for unknown overlay, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var problem map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if problem["code"] != "overlay.not_found" || fmt.Sprint(problem["available"]) != "[prod]" {
+		t.Fatalf("unexpected problem: %+v", problem)
+	}
+}
+
+func TestPlansHandlerLoadsOverlayThroughInjectedLoader(t *testing.T) {
+	root := t.TempDir()
+	writePlanConfig(t, root, "deploy", `
+version: v1
+job:
+  id: deploy
+  name: Deploy
+`)
+	var overlays []string
+	h := NewPlansHandler(PlansConfig{Root: root, LoadConfig: func(dir, overlay string) (*types.Config, error) {
+		overlays = append(overlays, overlay)
+		return configloader.LoadConfigOverlay(dir, "")
+	}})
+	req := httptest.NewRequest(http.MethodPost, "/plans", strings.NewReader(`{"job_id":"deploy","overlay":"prod"}`))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(overlays) != 1 || overlays[0] != "prod" {
+		t.Fatalf("expected the injected loader to load overlay prod, got %q", overlays)
+	}
+}
+
 func writePlanConfig(t *testing.T, root, jobID, yaml string) {
 	t.Helper()
 	jobDir := filepath.Join(root, jobID)
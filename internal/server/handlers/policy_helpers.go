@@ -4,10 +4,14 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"os"
+	"sort"
 	"strings"
 
+	"github.com/flowd-org/flowd/internal/executor"
 	"github.com/flowd-org/flowd/internal/policy"
 	"github.com/flowd-org/flowd/internal/policy/verify"
+	"github.com/flowd-org/flowd/internal/server/authz"
 	"github.com/flowd-org/flowd/internal/server/metrics"
 	"github.com/flowd-org/flowd/internal/server/requestctx"
 	"github.com/flowd-org/flowd/internal/server/response"
@@ -16,6 +20,7 @@ import (
 
 type verificationOutcome struct {
 	Mode     policy.VerifyMode
+	Backend  string
 	Verified bool
 	Reason   string
 }
@@ -74,6 +79,7 @@ func enforceImageVerification(ctx context.Context, image string, mode policy.Ver
 	if mode == policy.VerifyModeDisabled || verifier == nil {
 		return out, nil
 	}
+	out.Backend = verify.BackendName(verifier)
 	res, err := verifier.Verify(ctx, image)
 	if err != nil {
 		out.Verified = false
@@ -290,3 +296,82 @@ func evaluateOverrides(ctx context.Context, cfg *types.Config, profile string, p
 
 	return findings, decisions, nil
 }
+
+// enforceScriptAllowList rejects scripts whose digest is not pre-registered in
+// the policy bundle. The allow-list only applies under the secure profile.
+func enforceScriptAllowList(ctx context.Context, scripts []types.ScriptDigest, profile string, policyCtx *policy.Context) *response.Problem {
+	if policyCtx == nil || profile != "secure" {
+		return nil
+	}
+	for _, script := range scripts {
+		if policyCtx.ScriptAllowed(script.SHA256) {
+			continue
+		}
+		detail := fmt.Sprintf("script %s (sha256 %s) is not in the policy allow-list", script.Path, script.SHA256)
+		prob := response.New(http.StatusForbidden, "script hash not allowed",
+			response.WithExtension("code", "script.hash.not.allowed"),
+			response.WithExtension("script", script.Path),
+			response.WithExtension("sha256", script.SHA256),
+			response.WithDetail(detail))
+		requestctx.LogPolicyDecision(ctx, "script", "denied", "script.hash.not.allowed", detail)
+		metrics.Default.RecordPolicyDenial("script.hash.not.allowed")
+		return &prob
+	}
+	return nil
+}
+
+// evaluateEnvPolicy reports the job environment variables, and the host PATH
+// the executor would otherwise add, that the policy's env rules strip from
+// steps. The rules only apply under the secure profile and never to variables
+// the engine sets itself.
+func evaluateEnvPolicy(ctx context.Context, cfg *types.Config, profile string, policyCtx *policy.Context) []types.Finding {
+	if cfg == nil || policyCtx == nil || profile != "secure" {
+		return nil
+	}
+	names := make([]string, 0, len(cfg.Env)+1)
+	for name := range cfg.Env {
+		names = append(names, name)
+	}
+	if _, ok := cfg.Env["PATH"]; !ok && os.Getenv("PATH") != "" {
+		names = append(names, "PATH")
+	}
+	sort.Strings(names)
+	var findings []types.Finding
+	for _, name := range names {
+		if executor.EngineEnv(name) || policyCtx.EnvAllowed(name) {
+			continue
+		}
+		message := fmt.Sprintf("environment variable %s stripped by policy", name)
+		requestctx.LogPolicyDecision(ctx, "env."+name, "stripped", "env.stripped", message)
+		findings = append(findings, types.Finding{
+			Code:    "env.stripped",
+			Level:   "warning",
+			Message: message,
+		})
+	}
+	return findings
+}
+
+// stepEnvFilter returns the executor env filter for runs under profile.
+func stepEnvFilter(profile string, policyCtx *policy.Context) func(string) bool {
+	if policyCtx == nil || profile != "secure" {
+		return nil
+	}
+	return policyCtx.EnvAllowed
+}
+
+// requireImpactScope refuses high-impact jobs unless the caller holds the
+// runs:high-impact scope. Requests without scopes, such as webhook
+// deliveries, cannot start them.
+func requireImpactScope(ctx context.Context, jobID string, impact *types.ImpactConfig) *response.Problem {
+	if !impact.High() || requestctx.HasScope(ctx, authz.ScopeRunsHighImpact) {
+		return nil
+	}
+	detail := fmt.Sprintf("job %s is high-impact (%s); starting it requires the %s scope", jobID, impact.Summary(), authz.ScopeRunsHighImpact)
+	prob := response.New(http.StatusForbidden, "high-impact job requires confirmation",
+		response.WithExtension("code", "E_IMPACT"),
+		response.WithExtension("required_scope", authz.ScopeRunsHighImpact),
+		response.WithDetail(detail))
+	requestctx.LogPolicyDecision(ctx, "impact", "denied", "E_IMPACT", detail)
+	return &prob
+}
@@ -0,0 +1,200 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/flowd-org/flowd/internal/executor/container"
+	"github.com/flowd-org/flowd/internal/policy"
+	"github.com/flowd-org/flowd/internal/policy/verify"
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/sourcestore"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// ReplayRequest is a recorded API request together with the caller identity
+// and source state it was evaluated against.
+type ReplayRequest struct {
+	Method    string            `json:"method"`
+	Path      string            `json:"path"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Body      json.RawMessage   `json:"body,omitempty"`
+	Principal string            `json:"principal,omitempty"`
+	Scopes    []string          `json:"scopes,omitempty"`
+	Sources   []ReplaySource    `json:"sources,omitempty"`
+}
+
+// ReplaySource is a registered source including the checkout path, which the
+// source API never exposes.
+type ReplaySource struct {
+	sourcestore.Source
+	LocalPath string `json:"local_path,omitempty"`
+}
+
+// ReplayConfig carries the server settings a replay is evaluated under.
+type ReplayConfig struct {
+	Root     string
+	Profile  string
+	Policy   *policy.Context
+	Verifier verify.ImageVerifier
+	Runtime  container.Runtime
+}
+
+// ReplayResult is the outcome of a replayed request. Status is the response
+// status the server would have returned; accepted runs report 201 without
+// being started.
+type ReplayResult struct {
+	Method    string           `json:"method"`
+	Path      string           `json:"path"`
+	Status    int              `json:"status"`
+	Problem   map[string]any   `json:"problem,omitempty"`
+	Plan      *types.Plan      `json:"plan,omitempty"`
+	Decisions []ReplayDecision `json:"policy_decisions,omitempty"`
+}
+
+// ReplayDecision is a policy decision logged while handling the request.
+type ReplayDecision struct {
+	Subject  string `json:"subject"`
+	Decision string `json:"decision"`
+	Code     string `json:"code,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Replay re-evaluates a recorded POST /runs or POST /plans request offline.
+// It runs the same validation and admission checks as the server but never
+// starts a run, so policy decisions and validation errors can be reproduced
+// from a request capture.
+func Replay(ctx context.Context, cfg ReplayConfig, rec ReplayRequest) (ReplayResult, error) {
+	result := ReplayResult{Method: rec.Method, Path: rec.Path}
+	if rec.Method != http.MethodPost || (rec.Path != "/runs" && rec.Path != "/plans") {
+		return result, fmt.Errorf("replay supports POST /runs and POST /plans, not %s %s", rec.Method, rec.Path)
+	}
+
+	sources := sourcestore.New()
+	for _, src := range rec.Sources {
+		s := src.Source
+		s.LocalPath = src.LocalPath
+		sources.Upsert(s)
+	}
+	decisions := &decisionRecorder{}
+	ctx = requestctx.WithLogger(ctx, slog.New(decisions))
+	if rec.Principal != "" {
+		ctx = requestctx.WithPrincipal(ctx, rec.Principal)
+	}
+	ctx = requestctx.WithScopes(ctx, rec.Scopes)
+
+	req := httptest.NewRequest(rec.Method, rec.Path, bytes.NewReader(rec.Body)).WithContext(ctx)
+	for k, v := range rec.Headers {
+		req.Header.Set(k, v)
+	}
+
+	if rec.Path == "/plans" {
+		w := httptest.NewRecorder()
+		NewPlansHandler(PlansConfig{
+			Root:     cfg.Root,
+			Sources:  sources,
+			Profile:  cfg.Profile,
+			Policy:   cfg.Policy,
+			Verifier: cfg.Verifier,
+			Runtime:  cfg.Runtime,
+		}).ServeHTTP(w, req)
+		result.Status = w.Code
+		if w.Code == http.StatusOK {
+			var plan types.Plan
+			if err := json.Unmarshal(w.Body.Bytes(), &plan); err != nil {
+				return result, fmt.Errorf("decode plan: %w", err)
+			}
+			result.Plan = &plan
+		} else if err := json.Unmarshal(w.Body.Bytes(), &result.Problem); err != nil {
+			return result, fmt.Errorf("decode problem: %w", err)
+		}
+		result.Decisions = decisions.decisions
+		return result, nil
+	}
+
+	h := NewRunsHandler(RunsConfig{
+		Root:     cfg.Root,
+		Sources:  sources,
+		Profile:  cfg.Profile,
+		Policy:   cfg.Policy,
+		Verifier: cfg.Verifier,
+		Runtime:  cfg.Runtime,
+	})
+	plan, prob := h.replayCreate(req)
+	result.Decisions = decisions.decisions
+	if prob != nil {
+		result.Status = prob.Status
+		result.Problem = prob.Body()
+		return result, nil
+	}
+	result.Status = http.StatusCreated
+	result.Plan = plan
+	return result, nil
+}
+
+// replayCreate mirrors handleCreate up to admission without touching the
+// idempotency store or starting the run.
+func (h *RunsHandler) replayCreate(r *http.Request) (*types.Plan, *response.Problem) {
+	req, rawBody, err := decodeRunRequest(r.Body)
+	if err != nil {
+		prob := response.New(http.StatusBadRequest, "invalid request body", response.WithDetail(err.Error()))
+		return nil, &prob
+	}
+	if req.JobID == "" {
+		prob := response.New(http.StatusBadRequest, "job_id is required")
+		return nil, &prob
+	}
+	if _, prob := requestBodyHash(r, rawBody); prob != nil {
+		return nil, prob
+	}
+	if _, prob := requestIdempotencyKey(r); prob != nil {
+		return nil, prob
+	}
+	prep, prob := h.prepareRun(r.Context(), req)
+	if prob != nil {
+		return nil, prob
+	}
+	return &prep.plan, nil
+}
+
+// decisionRecorder is a slog handler that keeps the policy_decision records
+// logged through requestctx.LogPolicyDecision.
+type decisionRecorder struct {
+	decisions []ReplayDecision
+}
+
+func (d *decisionRecorder) Enabled(context.Context, slog.Level) bool { return true }
+
+func (d *decisionRecorder) Handle(_ context.Context, r slog.Record) error {
+	if r.Message != "policy_decision" {
+		return nil
+	}
+	var dec ReplayDecision
+	r.Attrs(func(a slog.Attr) bool {
+		switch a.Key {
+		case "subject":
+			dec.Subject = a.Value.String()
+		case "decision":
+			dec.Decision = a.Value.String()
+		case "code":
+			dec.Code = a.Value.String()
+		case "reason":
+			dec.Reason = a.Value.String()
+		}
+		return true
+	})
+	d.decisions = append(d.decisions, dec)
+	return nil
+}
+
+func (d *decisionRecorder) WithAttrs([]slog.Attr) slog.Handler { return d }
+
+func (d *decisionRecorder) WithGroup(string) slog.Handler { return d }
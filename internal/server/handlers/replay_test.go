@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/executor/container"
+	"github.com/flowd-org/flowd/internal/policy"
+	"github.com/flowd-org/flowd/internal/policy/verify"
+)
+
+func TestReplayRunReproducesPolicyDenial(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "registry", `
+version: v1
+job:
+  id: registry
+  name: Registry Job
+executor: container
+interpreter: "container:ghcr.io/example/app:1"
+container:
+  image: ghcr.io/example/app:1
+`)
+	policyCtx, err := policy.NewContext(&policy.Bundle{AllowedRegistries: []string{"registry.corp.example"}})
+	if err != nil {
+		t.Fatalf("policy context: %v", err)
+	}
+	cfg := ReplayConfig{
+		Root:     root,
+		Profile:  "secure",
+		Policy:   policyCtx,
+		Verifier: stubVerifier{result: verify.Result{Verified: true}},
+		Runtime:  container.RuntimeDocker,
+	}
+
+	result, err := Replay(context.Background(), cfg, ReplayRequest{
+		Method:  http.MethodPost,
+		Path:    "/runs",
+		Headers: map[string]string{"Content-Type": "application/json", "Idempotency-Key": newIdempotencyKey()},
+		Body:    json.RawMessage(`{"job_id":"registry"}`),
+	})
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if result.Status != http.StatusUnprocessableEntity || result.Problem["code"] != "image.registry.not.allowed" {
+		t.Fatalf("expected registry denial, got %+v", result)
+	}
+	if len(result.Decisions) == 0 || result.Decisions[0].Code != "image.registry.not.allowed" || result.Decisions[0].Decision != "denied" {
+		t.Fatalf("expected recorded policy decision, got %+v", result.Decisions)
+	}
+
+	result, err = Replay(context.Background(), cfg, ReplayRequest{Method: http.MethodPost, Path: "/runs", Body: json.RawMessage(`{}`)})
+	if err != nil || result.Status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for missing job_id, got %+v, %v", result, err)
+	}
+	if _, err := Replay(context.Background(), cfg, ReplayRequest{Method: http.MethodGet, Path: "/runs"}); err == nil {
+		t.Fatalf("expected unsupported request to fail")
+	}
+}
+
+func TestReplayRunReturnsPlan(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "hello", `
+version: v1
+job:
+  id: hello
+  name: Hello
+interpreter: bash
+`)
+	if err := os.WriteFile(filepath.Join(root, "hello", "100_main.sh"), []byte("true\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	result, err := Replay(context.Background(), ReplayConfig{Root: root, Profile: "permissive"}, ReplayRequest{
+		Method:  http.MethodPost,
+		Path:    "/runs",
+		Headers: map[string]string{"Idempotency-Key": newIdempotencyKey()},
+		Body:    json.RawMessage(`{"job_id":"hello"}`),
+	})
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if result.Status != http.StatusCreated || result.Plan == nil || result.Plan.JobID != "hello" {
+		t.Fatalf("expected accepted plan, got %+v", result)
+	}
+}
@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/server/sse"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// pendingRun is a prepared run held until its job's approval policy is met.
+type pendingRun struct {
+	prep *preparedRun
+	resp RunPayload
+}
+
+type runDecision struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+// holdRun parks an accepted run that awaits approval.
+func (h *RunsHandler) holdRun(prep *preparedRun, resp RunPayload) {
+	h.approvalMu.Lock()
+	h.pending[resp.ID] = &pendingRun{prep: prep, resp: resp}
+	h.approvalMu.Unlock()
+	h.publishApproval(resp, "run.approval.requested", map[string]any{
+		"requested_by": resp.Approval.RequestedBy,
+		"required":     resp.Approval.Required,
+		"approvers":    prep.config.Approval.Approvers,
+	})
+}
+
+// releaseHeldRun forgets a held run so that it can no longer be approved.
+func (h *RunsHandler) releaseHeldRun(runID string) {
+	h.approvalMu.Lock()
+	delete(h.pending, runID)
+	h.approvalMu.Unlock()
+}
+
+// HandleApproval processes POST /runs/{id}:approve and POST /runs/{id}:reject
+// for runs held by their job's approval policy. Approvals must come from
+// authenticated principals other than the requester; the run starts once the
+// policy's required count is reached.
+func (h *RunsHandler) HandleApproval(w http.ResponseWriter, r *http.Request, runID, action string) {
+	if r.Method != http.MethodPost {
+		response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+	var decision runDecision
+	if err := decodePipelineBody(r.Body, &decision); err != nil {
+		response.Write(w, response.New(http.StatusBadRequest, "invalid request body", response.WithDetail(err.Error())))
+		return
+	}
+	ctx := r.Context()
+	principal, _ := requestctx.Principal(ctx)
+
+	run, rejected, prob := h.decideHeldRun(ctx, runID, principal, action, decision.Comment)
+	if prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+	if rejected {
+		run = h.cancelRun(ctx, runID, fmt.Sprintf("rejected by %s", principal))
+	}
+	writeRunPayload(w, payloadFromStore(run), http.StatusOK)
+}
+
+// decideHeldRun records principal's decision on a held run. An approval that
+// meets the policy releases the run for execution; a rejection removes it from
+// the held set and reports rejected so that the caller cancels it.
+func (h *RunsHandler) decideHeldRun(ctx context.Context, runID, principal, action, comment string) (runstore.Run, bool, *response.Problem) {
+	h.approvalMu.Lock()
+	defer h.approvalMu.Unlock()
+	held, ok := h.pending[runID]
+	if !ok {
+		run, exists := h.store.Get(runID)
+		if !exists {
+			prob := response.New(http.StatusNotFound, "run not found")
+			return runstore.Run{}, false, &prob
+		}
+		prob := response.New(http.StatusConflict, "run not awaiting approval",
+			response.WithExtension("code", "run.not_pending"),
+			response.WithDetail(fmt.Sprintf("run %s is %s", run.ID, run.Status)))
+		return runstore.Run{}, false, &prob
+	}
+	if prob := checkRunApprover(held.resp.JobID, held.prep.config.Approval, *held.resp.Approval, principal); prob != nil {
+		return runstore.Run{}, false, prob
+	}
+
+	if action == "reject" {
+		delete(h.pending, runID)
+		h.publishApproval(held.resp, "run.approval.rejected", map[string]any{
+			"principal": principal,
+			"comment":   comment,
+		})
+		h.logApproval(ctx, held.resp, "run.approval.rejected", principal)
+		return runstore.Run{}, true, nil
+	}
+
+	approval := *held.resp.Approval
+	approval.Approvals = append(append([]types.RunApprovalBy(nil), approval.Approvals...), types.RunApprovalBy{
+		Principal: principal,
+		At:        h.now(),
+		Comment:   comment,
+	})
+	held.resp.Approval = &approval
+	h.publishApproval(held.resp, "run.approval.granted", map[string]any{
+		"principal": principal,
+		"comment":   comment,
+		"approvals": len(approval.Approvals),
+		"required":  approval.Required,
+	})
+	h.logApproval(ctx, held.resp, "run.approval.granted", principal)
+
+	run, ok := h.store.Get(runID)
+	if !ok {
+		delete(h.pending, runID)
+		prob := response.New(http.StatusNotFound, "run not found")
+		return runstore.Run{}, false, &prob
+	}
+	run.Approval = &approval
+	if len(approval.Approvals) < approval.Required {
+		h.store.Update(run)
+		return run, false, nil
+	}
+	// Register before the status change so that a cancel racing the release
+	// reaches the execution context.
+	delete(h.pending, runID)
+	held.resp.Status = defaultRunStatus
+	// An approved run was already accepted, so a job at its concurrency
+	// limit queues it whatever its policy.
+	held.resp.QueuePosition, _ = h.limiter.admit(held.resp.JobID, runID, held.prep.config.Concurrency, false)
+	runCtx := newRunExecutionContext(held.prep, held.resp)
+	h.running.Register(runID, runCtx)
+	run.Status = defaultRunStatus
+	run.QueuePosition = held.resp.QueuePosition
+	h.store.Update(run)
+	h.dispatchRun(runCtx)
+	return run, false, nil
+}
+
+// checkRunApprover enforces the job's approver list and the two-person rule:
+// the approver must be an authenticated principal other than the requester,
+// and each principal counts once.
+func checkRunApprover(jobID string, policy *types.ApprovalPolicy, approval types.RunApproval, principal string) *response.Problem {
+	if principal == "" {
+		return approvalDenied("approval.anonymous", "approving a run requires an authenticated principal")
+	}
+	if !isApprover(policy, principal) {
+		return approvalDenied("approval.not_approver", fmt.Sprintf("%q is not an approver for job %s", principal, jobID))
+	}
+	if principal == approval.RequestedBy {
+		return approvalDenied("approval.self", "the requester cannot decide their own run")
+	}
+	for _, a := range approval.Approvals {
+		if a.Principal == principal {
+			return approvalDenied("approval.duplicate", fmt.Sprintf("%q already approved this run", principal))
+		}
+	}
+	return nil
+}
+
+func (h *RunsHandler) publishApproval(resp RunPayload, event string, fields map[string]any) {
+	if h.events == nil {
+		return
+	}
+	data := map[string]any{
+		"run_id":    resp.ID,
+		"job_id":    resp.JobID,
+		"timestamp": h.now(),
+	}
+	for k, v := range fields {
+		data[k] = v
+	}
+	h.events.Publish(resp.ID, sse.Event{Event: event, Data: encodeData(data)})
+}
+
+func (h *RunsHandler) logApproval(ctx context.Context, resp RunPayload, msg, principal string) {
+	if logger := requestctx.Logger(ctx); logger != nil {
+		logger.Info(msg,
+			slog.String("run_id", resp.ID),
+			slog.String("job_id", resp.JobID),
+			slog.String("requested_by", resp.Approval.RequestedBy),
+			slog.String("actor", principal),
+		)
+	}
+}
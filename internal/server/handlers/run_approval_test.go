@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/paths"
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+const approvalJobConfig = `
+version: v1
+job:
+  id: release
+  name: Release
+interpreter: bash
+approval:
+  required: 1
+`
+
+func newApprovalHandler(t *testing.T) (*RunsHandler, *runstore.Store) {
+	t.Helper()
+	root := t.TempDir()
+	writeJobConfig(t, root, "release", approvalJobConfig)
+	if err := os.WriteFile(filepath.Join(root, "release", "100_main.sh"), []byte("true\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	store := runstore.New()
+	return NewRunsHandler(RunsConfig{Root: root, Store: store, Events: &recordingSink{}}), store
+}
+
+func postRunAs(t *testing.T, h http.Handler, principal, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/runs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	addIdempotencyHeader(req)
+	req = req.WithContext(requestctx.WithPrincipal(req.Context(), principal))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func decideRun(h *RunsHandler, principal, runID, action string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/runs/"+runID+":"+action, strings.NewReader(`{"comment":"ok"}`))
+	if principal != "" {
+		req = req.WithContext(requestctx.WithPrincipal(req.Context(), principal))
+	}
+	rec := httptest.NewRecorder()
+	h.HandleApproval(rec, req, runID, action)
+	return rec
+}
+
+func TestRunApprovalRequiresSecondPrincipal(t *testing.T) {
+	h, store := newApprovalHandler(t)
+
+	rec := postRunAs(t, h, "dave", `{"job_id":"release"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var payload RunPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode run: %v", err)
+	}
+	if payload.Status != awaitingApprovalStatus || payload.Approval == nil || payload.Approval.RequestedBy != "dave" {
+		t.Fatalf("expected held run requested by dave, got %+v", payload)
+	}
+
+	if rec := decideRun(h, "dave", payload.ID, "approve"); rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "approval.self") {
+		t.Fatalf("expected approval.self, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := decideRun(h, "", payload.ID, "approve"); rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "approval.anonymous") {
+		t.Fatalf("expected approval.anonymous, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if run, _ := store.Get(payload.ID); run.Status != awaitingApprovalStatus {
+		t.Fatalf("expected run to stay held, got %s", run.Status)
+	}
+
+	rec = decideRun(h, "erin", payload.ID, "approve")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	run := waitForTerminalRun(t, store, rec.Body.Bytes())
+	if run.Status != "completed" {
+		t.Fatalf("expected completed run, got %s", run.Status)
+	}
+	if rec := decideRun(h, "frank", payload.ID, "approve"); rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "run.not_pending") {
+		t.Fatalf("expected run.not_pending, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var receipt types.RunReceipt
+	receiptPath := filepath.Join(paths.RunDir(payload.ID), "receipt.json")
+	waitFor(func() bool {
+		_, err := os.Stat(receiptPath)
+		return err == nil
+	}, 2*time.Second, t)
+	readJSONFile(t, receiptPath, &receipt)
+	if receipt.Approval == nil || receipt.Approval.RequestedBy != "dave" ||
+		len(receipt.Approval.Approvals) != 1 || receipt.Approval.Approvals[0].Principal != "erin" {
+		t.Fatalf("expected receipt to record requester and approver, got %+v", receipt.Approval)
+	}
+}
+
+func TestRunApprovalRejectCancelsRun(t *testing.T) {
+	h, store := newApprovalHandler(t)
+
+	rec := postRunAs(t, h, "dave", `{"job_id":"release"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var payload RunPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode run: %v", err)
+	}
+	rec = decideRun(h, "erin", payload.ID, "reject")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if run, _ := store.Get(payload.ID); run.Status != "canceled" {
+		t.Fatalf("expected canceled run, got %s", run.Status)
+	}
+	if rec := decideRun(h, "frank", payload.ID, "approve"); rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 after rejection, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/executor"
+	"github.com/flowd-org/flowd/internal/paths"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// recordRunArtifacts indexes the files the run left under artifacts/ and
+// stores them on the run. Files over the limits are logged and left out.
+func (h *RunsHandler) recordRunArtifacts(runID, runDir string) []types.RunArtifact {
+	artifacts, skipped, err := executor.IndexArtifacts(runDir, h.artifactLimits)
+	if err != nil {
+		slog.Default().Warn("run.artifacts.failed", slog.String("run_id", runID), slog.String("error", err.Error()))
+		return nil
+	}
+	if len(skipped) > 0 {
+		slog.Default().Warn("run.artifacts.skipped", slog.String("run_id", runID), slog.Any("names", skipped))
+	}
+	if len(artifacts) == 0 {
+		return nil
+	}
+	if current, ok := h.store.Get(runID); ok {
+		current.Artifacts = artifacts
+		h.store.Update(current)
+	}
+	return artifacts
+}
+
+// IsRunArtifactsPath reports whether path is /runs/{id}/artifacts or an
+// artifact below it. Artifact names may end in segments such as /events, so
+// routing must check this first.
+func IsRunArtifactsPath(p string) bool {
+	_, tail, ok := strings.Cut(strings.TrimPrefix(p, "/runs/"), "/")
+	return ok && (tail == "artifacts" || strings.HasPrefix(tail, "artifacts/"))
+}
+
+// NewRunArtifactsHandler serves GET /runs/{id}/artifacts, the files a run
+// published, and GET /runs/{id}/artifacts/{name}, which downloads one.
+// Artifacts are indexed when the run finishes; only indexed files are
+// served.
+func NewRunArtifactsHandler(store *runstore.Store) http.Handler {
+	if store == nil {
+		store = runstore.New()
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		runID, tail, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/runs/"), "/")
+		name, download := strings.CutPrefix(tail, "artifacts/")
+		if r.Method != http.MethodGet {
+			response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+			return
+		}
+		run, ok := store.Get(runID)
+		if !ok || runID == "" {
+			response.Write(w, response.New(http.StatusNotFound, "run not found"))
+			return
+		}
+		if !download {
+			artifacts := run.Artifacts
+			if artifacts == nil {
+				artifacts = []types.RunArtifact{}
+			}
+			w.Header().Set("Cache-Control", "no-store")
+			writeJSON(w, map[string]any{"run_id": run.ID, "artifacts": artifacts}, http.StatusOK)
+			return
+		}
+		serveRunArtifact(w, r, run, name)
+	})
+}
+
+func serveRunArtifact(w http.ResponseWriter, r *http.Request, run runstore.Run, name string) {
+	var artifact *types.RunArtifact
+	for i := range run.Artifacts {
+		if run.Artifacts[i].Name == name {
+			artifact = &run.Artifacts[i]
+			break
+		}
+	}
+	if artifact == nil {
+		response.Write(w, response.New(http.StatusNotFound, "artifact not found", response.WithDetail(name)))
+		return
+	}
+	file := filepath.Join(paths.RunDir(run.ID), executor.ArtifactsDir, filepath.FromSlash(artifact.Name))
+	info, err := os.Lstat(file)
+	if err != nil {
+		response.Write(w, response.New(http.StatusNotFound, "artifact not found", response.WithDetail("the artifact file is no longer available")))
+		return
+	}
+	if !info.Mode().IsRegular() || info.Size() != artifact.Size {
+		response.Write(w, response.New(http.StatusConflict, "artifact changed",
+			response.WithDetail("the artifact file no longer matches the one indexed when the run finished")))
+		return
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		response.Write(w, response.New(http.StatusInternalServerError, "open artifact failed", response.WithDetail(err.Error())))
+		return
+	}
+	defer f.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(artifact.Name))
+	if contentType == "" {
+		buf := make([]byte, 512)
+		n, _ := f.Read(buf)
+		contentType = http.DetectContentType(buf[:n])
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			response.Write(w, response.New(http.StatusInternalServerError, "read artifact failed", response.WithDetail(err.Error())))
+			return
+		}
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(artifact.Name)}))
+	// Artifacts are untrusted run output; never let a browser render them
+	// as active content on the API's origin.
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("ETag", `"sha256:`+artifact.SHA256+`"`)
+	w.Header().Set("Cache-Control", "private, max-age=0")
+	http.ServeContent(w, r, "", info.ModTime(), f)
+}
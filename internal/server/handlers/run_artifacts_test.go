@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/executor"
+	"github.com/flowd-org/flowd/internal/paths"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+func TestRunArtifactsListAndDownload(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "package", `
+version: v1
+job:
+  id: package
+  name: Package
+interpreter: bash
+`)
+	script := `mkdir -p "$FLWD_RUN_DIR/artifacts/reports"
+printf 'built' > "$FLWD_RUN_DIR/artifacts/app.txt"
+printf '<?xml version="1.0"?><testsuites/>' > "$FLWD_RUN_DIR/artifacts/reports/events"
+head -c 64 /dev/zero > "$FLWD_RUN_DIR/artifacts/big.bin"
+`
+	if err := os.WriteFile(filepath.Join(root, "package", "100_main.sh"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	store := runstore.New()
+	h := NewRunsHandler(RunsConfig{Root: root, Store: store, Events: &recordingSink{}, ArtifactLimits: executor.ArtifactLimits{MaxFileBytes: 48}})
+	rec := postRun(t, h, `{"job_id":"package"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	run := waitForTerminalRun(t, store, rec.Body.Bytes())
+	if run.Status != "completed" {
+		t.Fatalf("expected run to complete, got %s (%v)", run.Status, run.Result)
+	}
+
+	if subjects := runInTotoStatement(run).Subject; len(subjects) != 3 || subjects[1].Name != "flowd-run/"+run.ID+"/artifacts/app.txt" {
+		t.Fatalf("expected artifacts as provenance subjects, got %+v", subjects)
+	}
+
+	artifacts := NewRunArtifactsHandler(store)
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		artifacts.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec = get("/runs/" + run.ID + "/artifacts")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var list struct {
+		Artifacts []struct {
+			Name   string `json:"name"`
+			Size   int64  `json:"size"`
+			SHA256 string `json:"sha256"`
+		} `json:"artifacts"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(list.Artifacts) != 2 || list.Artifacts[0].Name != "app.txt" || list.Artifacts[0].Size != 5 || list.Artifacts[1].Name != "reports/events" {
+		t.Fatalf("expected the oversized file to be left out, got %s", rec.Body.String())
+	}
+
+	rec = get("/runs/" + run.ID + "/artifacts/app.txt")
+	if rec.Code != http.StatusOK || rec.Body.String() != "built" {
+		t.Fatalf("expected artifact content, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Fatalf("expected text content type, got %q", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != "attachment; filename=app.txt" {
+		t.Fatalf("unexpected disposition %q", cd)
+	}
+	if rec.Header().Get("ETag") != `"sha256:`+list.Artifacts[0].SHA256+`"` {
+		t.Fatalf("unexpected etag %q", rec.Header().Get("ETag"))
+	}
+
+	if rec = get("/runs/" + run.ID + "/artifacts/reports/events"); rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/xml") {
+		t.Fatalf("expected sniffed xml artifact, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if rec = get("/runs/" + run.ID + "/artifacts/big.bin"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected unindexed artifact to be 404, got %d", rec.Code)
+	}
+	if rec = get("/runs/" + run.ID + "/artifacts/../stdout"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected path outside the index to be 404, got %d", rec.Code)
+	}
+
+	if err := os.WriteFile(filepath.Join(paths.RunDir(run.ID), executor.ArtifactsDir, "app.txt"), []byte("tampered"), 0o600); err != nil {
+		t.Fatalf("rewrite artifact: %v", err)
+	}
+	if rec = get("/runs/" + run.ID + "/artifacts/app.txt"); rec.Code != http.StatusConflict {
+		t.Fatalf("expected changed artifact to be refused, got %d", rec.Code)
+	}
+	if rec = get("/runs/missing/artifacts"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected unknown run to be 404, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,251 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/coredb"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+// RunCompareRun summarizes one side of a comparison.
+type RunCompareRun struct {
+	ID         string     `json:"id"`
+	JobID      string     `json:"job_id"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	DurationMS *int64     `json:"duration_ms,omitempty"`
+}
+
+// RunCompareStepResult is how a step ended in one of the compared runs.
+type RunCompareStepResult struct {
+	Status     string `json:"status,omitempty"`
+	ExitCode   *int   `json:"exit_code,omitempty"`
+	DurationMS *int64 `json:"duration_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// RunCompareStep pairs a step across both runs. A or B is omitted when the
+// step did not run there.
+type RunCompareStep struct {
+	ID string                `json:"id"`
+	A  *RunCompareStepResult `json:"a,omitempty"`
+	B  *RunCompareStepResult `json:"b,omitempty"`
+	// DurationDeltaMS is B's duration minus A's when both finished.
+	DurationDeltaMS *int64 `json:"duration_delta_ms,omitempty"`
+	ExitCodeChanged bool   `json:"exit_code_changed"`
+}
+
+// RunCompareChange is a value that differs between the runs. A or B is
+// omitted when only one run has it.
+type RunCompareChange struct {
+	Name string `json:"name"`
+	A    any    `json:"a,omitempty"`
+	B    any    `json:"b,omitempty"`
+}
+
+// RunComparison is the response body of GET /runs:compare.
+type RunComparison struct {
+	A               RunCompareRun      `json:"a"`
+	B               RunCompareRun      `json:"b"`
+	DurationDeltaMS *int64             `json:"duration_delta_ms,omitempty"`
+	Steps           []RunCompareStep   `json:"steps"`
+	Args            []RunCompareChange `json:"args"`
+	Images          []RunCompareChange `json:"images"`
+}
+
+type runCompareHandler struct {
+	store   *runstore.Store
+	journal *coredb.Journal
+}
+
+// NewRunCompareHandler serves GET /runs:compare?ids=a,b, lining up the step
+// results, resolved args and image digests of two runs.
+func NewRunCompareHandler(store *runstore.Store, journal *coredb.Journal) http.Handler {
+	if store == nil {
+		store = runstore.New()
+	}
+	return &runCompareHandler{store: store, journal: journal}
+}
+
+func (h *runCompareHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+	var ids []string
+	for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) != 2 {
+		response.Write(w, response.New(http.StatusBadRequest, "invalid ids",
+			response.WithDetail("ids must name exactly two runs, e.g. ids=a,b")))
+		return
+	}
+	timelines := make([]RunTimeline, 0, 2)
+	runs := make([]runstore.Run, 0, 2)
+	for _, id := range ids {
+		run, ok := h.store.Get(id)
+		if !ok || !RunVisible(r.Context(), run) {
+			response.Write(w, response.New(http.StatusNotFound, "run not found", response.WithDetail(id)))
+			return
+		}
+		timeline := newTimeline(run)
+		if h.journal != nil {
+			err := h.journal.ForEach(r.Context(), id, 0, func(entry coredb.JournalEntry) error {
+				timeline.apply(entry)
+				return nil
+			})
+			if err != nil {
+				response.Write(w, response.New(http.StatusInternalServerError, "read run events failed", response.WithDetail(err.Error())))
+				return
+			}
+		}
+		runs = append(runs, run)
+		timelines = append(timelines, timeline.finish())
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, compareRuns(runs[0], runs[1], timelines[0], timelines[1]), http.StatusOK)
+}
+
+func compareRuns(a, b runstore.Run, ta, tb RunTimeline) RunComparison {
+	out := RunComparison{
+		A:      compareRun(a, ta),
+		B:      compareRun(b, tb),
+		Steps:  compareSteps(ta.Steps, tb.Steps),
+		Args:   compareValues(resolvedArgs(a), resolvedArgs(b)),
+		Images: compareValues(runImages(a), runImages(b)),
+	}
+	if out.A.DurationMS != nil && out.B.DurationMS != nil {
+		delta := *out.B.DurationMS - *out.A.DurationMS
+		out.DurationDeltaMS = &delta
+	}
+	return out
+}
+
+func compareRun(run runstore.Run, timeline RunTimeline) RunCompareRun {
+	return RunCompareRun{
+		ID:         run.ID,
+		JobID:      run.JobID,
+		Status:     run.Status,
+		StartedAt:  run.StartedAt,
+		FinishedAt: timeline.FinishedAt,
+		DurationMS: timeline.DurationMS,
+	}
+}
+
+// compareSteps pairs steps by id in the order they first ran, A's steps
+// before steps only B ran. A step that ran more than once is compared by its
+// last run.
+func compareSteps(a, b []TimelineStep) []RunCompareStep {
+	var order []string
+	lastA, lastB := map[string]TimelineStep{}, map[string]TimelineStep{}
+	for _, side := range []struct {
+		steps []TimelineStep
+		last  map[string]TimelineStep
+	}{{a, lastA}, {b, lastB}} {
+		for _, step := range side.steps {
+			if _, seen := lastA[step.ID]; !seen {
+				if _, seen := lastB[step.ID]; !seen {
+					order = append(order, step.ID)
+				}
+			}
+			side.last[step.ID] = step
+		}
+	}
+	out := make([]RunCompareStep, 0, len(order))
+	for _, id := range order {
+		entry := RunCompareStep{ID: id}
+		if step, ok := lastA[id]; ok {
+			entry.A = stepResult(step)
+		}
+		if step, ok := lastB[id]; ok {
+			entry.B = stepResult(step)
+		}
+		if entry.A != nil && entry.B != nil {
+			if entry.A.DurationMS != nil && entry.B.DurationMS != nil {
+				delta := *entry.B.DurationMS - *entry.A.DurationMS
+				entry.DurationDeltaMS = &delta
+			}
+			entry.ExitCodeChanged = !equalExitCodes(entry.A.ExitCode, entry.B.ExitCode)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func stepResult(step TimelineStep) *RunCompareStepResult {
+	return &RunCompareStepResult{
+		Status:     step.Status,
+		ExitCode:   step.ExitCode,
+		DurationMS: step.DurationMS,
+		Error:      step.Error,
+	}
+}
+
+func equalExitCodes(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func resolvedArgs(run runstore.Run) map[string]any {
+	args, _ := run.Result["resolved_args"].(map[string]any)
+	return args
+}
+
+// runImages collects the image references and digests recorded in a run's
+// provenance.
+func runImages(run runstore.Run) map[string]any {
+	images := map[string]any{}
+	if image := stringField(run.Provenance, "container_image"); image != "" {
+		images["container_image"] = image
+	}
+	if src, ok := run.Provenance["source"].(map[string]any); ok {
+		if digest := stringField(src, "digest"); digest != "" {
+			images["source_digest"] = digest
+		}
+	}
+	return images
+}
+
+// compareValues lists the keys whose values differ, sorted by name. Values
+// are compared by their JSON encoding so records read back from storage
+// compare equal to those still in memory.
+func compareValues(a, b map[string]any) []RunCompareChange {
+	names := make([]string, 0, len(a)+len(b))
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	out := []RunCompareChange{}
+	for _, name := range names {
+		av, aok := a[name]
+		bv, bok := b[name]
+		if aok && bok {
+			ae, aerr := json.Marshal(av)
+			be, berr := json.Marshal(bv)
+			if aerr == nil && berr == nil && bytes.Equal(ae, be) {
+				continue
+			}
+		}
+		out = append(out, RunCompareChange{Name: name, A: av, B: bv})
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+func TestRunCompare(t *testing.T) {
+	store := runstore.New()
+	journal := newTestJournal(t)
+	accepted := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	for _, run := range []struct {
+		id, status, image string
+		args              map[string]any
+		events            []string
+		offsets           []time.Duration
+	}{
+		{
+			id: "run-a", status: "completed", image: "alpine:3@sha256:aaa",
+			args: map[string]any{"env": "staging", "retries": 3},
+			events: []string{
+				`run.start|{}`,
+				`step.start|{"step":"000_fetch.sh"}`,
+				`step.finish|{"step":"000_fetch.sh","status":"completed","exit_code":0}`,
+				`step.start|{"step":"100_test.sh"}`,
+				`step.finish|{"step":"100_test.sh","status":"completed","exit_code":0}`,
+				`run.finish|{}`,
+			},
+			offsets: []time.Duration{0, 0, 2 * time.Second, 2 * time.Second, 10 * time.Second, 10 * time.Second},
+		},
+		{
+			id: "run-b", status: "failed", image: "alpine:3@sha256:bbb",
+			args: map[string]any{"env": "staging", "retries": 3, "verbose": true},
+			events: []string{
+				`run.start|{}`,
+				`step.start|{"step":"000_fetch.sh"}`,
+				`step.finish|{"step":"000_fetch.sh","status":"completed","exit_code":0}`,
+				`step.start|{"step":"100_test.sh"}`,
+				`step.finish|{"step":"100_test.sh","status":"failed","exit_code":1}`,
+				`run.finish|{}`,
+			},
+			offsets: []time.Duration{0, 0, 5 * time.Second, 5 * time.Second, 7 * time.Second, 7 * time.Second},
+		},
+	} {
+		finished := accepted.Add(run.offsets[len(run.offsets)-1])
+		store.Create(runstore.Run{
+			ID: run.id, JobID: "build", Status: run.status, StartedAt: accepted, FinishedAt: &finished,
+			Result:     map[string]any{"resolved_args": run.args},
+			Provenance: map[string]any{"container_image": run.image},
+		})
+		for i, ev := range run.events {
+			kind, data, _ := strings.Cut(ev, "|")
+			if _, err := journal.Append(context.Background(), run.id, kind, []byte(data), accepted.Add(run.offsets[i])); err != nil {
+				t.Fatalf("append: %v", err)
+			}
+		}
+	}
+	h := NewRunCompareHandler(store, journal)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs:compare?ids=run-a,run-b", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var cmp RunComparison
+	if err := json.Unmarshal(rec.Body.Bytes(), &cmp); err != nil {
+		t.Fatalf("decode comparison: %v", err)
+	}
+	if cmp.A.ID != "run-a" || cmp.B.Status != "failed" || cmp.DurationDeltaMS == nil || *cmp.DurationDeltaMS != -3000 {
+		t.Fatalf("unexpected runs %+v %+v delta %v", cmp.A, cmp.B, cmp.DurationDeltaMS)
+	}
+	if len(cmp.Steps) != 2 {
+		t.Fatalf("expected two steps, got %+v", cmp.Steps)
+	}
+	fetch, test := cmp.Steps[0], cmp.Steps[1]
+	if fetch.ID != "000_fetch.sh" || fetch.ExitCodeChanged || fetch.DurationDeltaMS == nil || *fetch.DurationDeltaMS != 3000 {
+		t.Fatalf("unexpected fetch step %+v", fetch)
+	}
+	if test.ID != "100_test.sh" || !test.ExitCodeChanged || *test.B.ExitCode != 1 || *test.DurationDeltaMS != -6000 {
+		t.Fatalf("unexpected test step %+v", test)
+	}
+	if len(cmp.Args) != 1 || cmp.Args[0].Name != "verbose" || cmp.Args[0].A != nil || cmp.Args[0].B != true {
+		t.Fatalf("unexpected args diff %+v", cmp.Args)
+	}
+	if len(cmp.Images) != 1 || cmp.Images[0].Name != "container_image" || cmp.Images[0].B != "alpine:3@sha256:bbb" {
+		t.Fatalf("unexpected images diff %+v", cmp.Images)
+	}
+
+	for target, want := range map[string]int{
+		"/runs:compare?ids=run-a":         http.StatusBadRequest,
+		"/runs:compare?ids=run-a,b,c":     http.StatusBadRequest,
+		"/runs:compare?ids=run-a,missing": http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != want {
+			t.Fatalf("%s: expected %d, got %d", target, want, rec.Code)
+		}
+	}
+}
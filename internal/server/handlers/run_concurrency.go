@@ -0,0 +1,224 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/server/sse"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// runLimiter enforces the concurrency limits of jobs. A run of a limited job
+// holds one of the job's slots from admission until it finishes; runs
+// admitted while every slot is taken wait in the job's queue, oldest first.
+// Queue positions are mirrored into the run store so that GET /runs/{id}
+// reports them. A nil runLimiter imposes no limits.
+type runLimiter struct {
+	mu    sync.Mutex
+	store *runstore.Store
+	jobs  map[string]*jobSlots
+	runs  map[string]*runTicket
+}
+
+type jobSlots struct {
+	max    int
+	active int
+	queue  []*runTicket
+}
+
+// runTicket tracks one admitted run. execCtx is set once the run is recorded
+// and ready to execute; a ticket granted a slot before that is started by
+// its own startRun.
+type runTicket struct {
+	runID   string
+	jobID   string
+	granted bool
+	execCtx *runExecutionContext
+}
+
+func newRunLimiter(store *runstore.Store) *runLimiter {
+	return &runLimiter{
+		store: store,
+		jobs:  make(map[string]*jobSlots),
+		runs:  make(map[string]*runTicket),
+	}
+}
+
+// admit takes a slot of jobID for runID or queues it. It returns the run's
+// queue position, 0 when it may execute right away, and false when the job's
+// policy refuses runs while it is at its limit. Runs of jobs without a limit
+// are not tracked.
+func (l *runLimiter) admit(jobID, runID string, cfg *types.ConcurrencyConfig, mayReject bool) (int, bool) {
+	if l == nil || cfg == nil || cfg.Max <= 0 {
+		return 0, true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slots := l.jobs[jobID]
+	if slots == nil {
+		slots = &jobSlots{}
+		l.jobs[jobID] = slots
+	}
+	slots.max = cfg.Max
+	ticket := &runTicket{runID: runID, jobID: jobID}
+	if slots.active < slots.max && len(slots.queue) == 0 {
+		slots.active++
+		ticket.granted = true
+		l.runs[runID] = ticket
+		return 0, true
+	}
+	if mayReject && cfg.Policy == types.ConcurrencyReject {
+		return 0, false
+	}
+	slots.queue = append(slots.queue, ticket)
+	l.runs[runID] = ticket
+	return len(slots.queue), true
+}
+
+// start hands execCtx to the limiter. It reports whether the run holds a
+// slot and should execute now; otherwise it calls queued with the run's
+// position, before any promotion can start it, and the run executes once
+// promoted from the queue.
+func (l *runLimiter) start(runID string, execCtx *runExecutionContext, queued func(position int)) bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ticket, ok := l.runs[runID]
+	if !ok {
+		return true
+	}
+	if ticket.granted {
+		l.setPositionLocked(runID, 0)
+		return true
+	}
+	ticket.execCtx = execCtx
+	position := l.positionLocked(ticket)
+	l.setPositionLocked(runID, position)
+	queued(position)
+	return false
+}
+
+// release frees the slot or queue entry of runID and returns the runs
+// promoted into freed slots that are ready to execute.
+func (l *runLimiter) release(runID string) []*runExecutionContext {
+	ready, _ := l.remove(runID, false)
+	return ready
+}
+
+// dequeue drops runID if it is still waiting for a slot, as release does,
+// and reports whether it was. Runs holding a slot keep it until they finish.
+func (l *runLimiter) dequeue(runID string) ([]*runExecutionContext, bool) {
+	return l.remove(runID, true)
+}
+
+func (l *runLimiter) remove(runID string, queuedOnly bool) ([]*runExecutionContext, bool) {
+	if l == nil {
+		return nil, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ticket, ok := l.runs[runID]
+	if !ok || (queuedOnly && ticket.granted) {
+		return nil, false
+	}
+	delete(l.runs, runID)
+	slots := l.jobs[ticket.jobID]
+	if ticket.granted {
+		slots.active--
+	} else {
+		for i, queued := range slots.queue {
+			if queued == ticket {
+				slots.queue = append(slots.queue[:i], slots.queue[i+1:]...)
+				break
+			}
+		}
+	}
+	var ready []*runExecutionContext
+	for slots.active < slots.max && len(slots.queue) > 0 {
+		next := slots.queue[0]
+		slots.queue = slots.queue[1:]
+		slots.active++
+		next.granted = true
+		if next.execCtx != nil {
+			l.setPositionLocked(next.runID, 0)
+			ready = append(ready, next.execCtx)
+		}
+	}
+	for i, queued := range slots.queue {
+		if queued.execCtx != nil {
+			l.setPositionLocked(queued.runID, i+1)
+		}
+	}
+	if slots.active == 0 && len(slots.queue) == 0 {
+		delete(l.jobs, ticket.jobID)
+	}
+	return ready, true
+}
+
+func (l *runLimiter) positionLocked(ticket *runTicket) int {
+	for i, queued := range l.jobs[ticket.jobID].queue {
+		if queued == ticket {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func (l *runLimiter) setPositionLocked(runID string, position int) {
+	run, ok := l.store.Get(runID)
+	if !ok || run.QueuePosition == position || (position > 0 && run.Status != defaultRunStatus) {
+		return
+	}
+	run.QueuePosition = position
+	l.store.Update(run)
+}
+
+// admitRun applies the job's concurrency limit to a run about to be created,
+// recording its queue position in resp.
+func (h *RunsHandler) admitRun(cfg *types.ConcurrencyConfig, resp *RunPayload) *response.Problem {
+	position, ok := h.limiter.admit(resp.JobID, resp.ID, cfg, true)
+	if !ok {
+		prob := response.New(http.StatusConflict, "job concurrency limit reached",
+			response.WithExtension("code", "run.concurrency_limited"),
+			response.WithExtension("max", cfg.Max),
+			response.WithDetail(fmt.Sprintf("job %s already has %d runs executing; retry later", resp.JobID, cfg.Max)))
+		return &prob
+	}
+	resp.QueuePosition = position
+	return nil
+}
+
+// dispatchRun executes runCtx once the run holds a slot of its job, and
+// otherwise announces that it is queued.
+func (h *RunsHandler) dispatchRun(runCtx *runExecutionContext) {
+	runID, jobID := runCtx.runPayload.ID, runCtx.runPayload.JobID
+	queued := func(position int) {
+		if h.events == nil {
+			return
+		}
+		h.events.Publish(runID, sse.Event{Event: "run.queued", Data: encodeData(map[string]any{
+			"run_id":    runID,
+			"job_id":    jobID,
+			"position":  position,
+			"timestamp": h.now(),
+		})})
+	}
+	if h.limiter.start(runID, runCtx, queued) {
+		go h.executeRun(runCtx)
+	}
+}
+
+// releaseSlot frees the run's slot or queue entry and executes the runs
+// promoted in its place.
+func (h *RunsHandler) releaseSlot(runID string) {
+	for _, runCtx := range h.limiter.release(runID) {
+		go h.executeRun(runCtx)
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+// newConcurrencyHandler serves a job limited to one run at a time whose runs
+// block until the returned gate file exists.
+func newConcurrencyHandler(t *testing.T, policy string) (*RunsHandler, *runstore.Store, *recordingSink, string) {
+	t.Helper()
+	root := t.TempDir()
+	gate := filepath.Join(t.TempDir(), "go")
+	writeJobConfig(t, root, "deploy", `
+version: v1
+job:
+  id: deploy
+  name: Deploy
+interpreter: bash
+concurrency:
+  max: 1
+  policy: `+policy+`
+`)
+	script := "while [ ! -f '" + gate + "' ]; do sleep 0.05; done\n"
+	if err := os.WriteFile(filepath.Join(root, "deploy", "100_main.sh"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	store := runstore.New()
+	sink := &recordingSink{}
+	return NewRunsHandler(RunsConfig{Root: root, Store: store, Events: sink}), store, sink, gate
+}
+
+func createRun(t *testing.T, h http.Handler) RunPayload {
+	t.Helper()
+	rec := postRun(t, h, `{"job_id":"deploy"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var run RunPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &run); err != nil {
+		t.Fatalf("decode run: %v", err)
+	}
+	return run
+}
+
+func TestConcurrencyQueuesRunsUntilSlotFrees(t *testing.T) {
+	h, store, sink, gate := newConcurrencyHandler(t, "queue")
+	first := createRun(t, h)
+	second := createRun(t, h)
+	third := createRun(t, h)
+	if first.QueuePosition != 0 || second.QueuePosition != 1 || third.QueuePosition != 2 {
+		t.Fatalf("expected queue positions 0, 1, 2, got %d, %d, %d", first.QueuePosition, second.QueuePosition, third.QueuePosition)
+	}
+	waitFor(func() bool {
+		run, _ := store.Get(first.ID)
+		return run.Status == "running"
+	}, 5*time.Second, t)
+	if sink.countBy("run.queued") != 2 {
+		t.Fatalf("expected two run.queued events, got %d", sink.countBy("run.queued"))
+	}
+
+	get := NewRunGetHandler(store, nil)
+	rec := httptest.NewRecorder()
+	get.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs/"+third.ID, nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"queue_position":2`) {
+		t.Fatalf("expected queue position 2, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Canceling a queued run moves the runs behind it up.
+	h.cancelRun(context.Background(), second.ID, "test")
+	if run, _ := store.Get(third.ID); run.QueuePosition != 1 || run.Status != "queued" {
+		t.Fatalf("expected third run queued at position 1, got %s at %d", run.Status, run.QueuePosition)
+	}
+
+	if err := os.WriteFile(gate, nil, 0o644); err != nil {
+		t.Fatalf("open gate: %v", err)
+	}
+	firstBody, _ := json.Marshal(first)
+	thirdBody, _ := json.Marshal(third)
+	if run := waitForTerminalRun(t, store, firstBody); run.Status != "completed" {
+		t.Fatalf("expected first run completed, got %s", run.Status)
+	}
+	run := waitForTerminalRun(t, store, thirdBody)
+	if run.Status != "completed" || run.QueuePosition != 0 {
+		t.Fatalf("expected third run completed off the queue, got %s at %d", run.Status, run.QueuePosition)
+	}
+	if run, _ := store.Get(second.ID); run.Status != "canceled" {
+		t.Fatalf("expected second run canceled, got %s", run.Status)
+	}
+}
+
+func TestConcurrencyRejectPolicy(t *testing.T) {
+	h, store, _, gate := newConcurrencyHandler(t, "reject")
+	first := createRun(t, h)
+
+	rec := postRun(t, h, `{"job_id":"deploy"}`)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "run.concurrency_limited") {
+		t.Fatalf("expected 409 concurrency limit, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if err := os.WriteFile(gate, nil, 0o644); err != nil {
+		t.Fatalf("open gate: %v", err)
+	}
+	body, _ := json.Marshal(first)
+	waitForTerminalRun(t, store, body)
+	// The slot frees as the finished run's execution returns.
+	waitFor(func() bool {
+		return postRun(t, h, `{"job_id":"deploy"}`).Code == http.StatusCreated
+	}, 5*time.Second, t)
+}
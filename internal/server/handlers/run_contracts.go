@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/engine"
+	"github.com/flowd-org/flowd/internal/indexer"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+const contractViolationProblemType = "https://flowd.dev/problems/contract-violation"
+
+func contractViolationProblem(detail string, violations []engine.ContractViolation) response.Problem {
+	return response.New(http.StatusUnprocessableEntity, "contract violation",
+		response.WithType(contractViolationProblemType),
+		response.WithExtension("code", "contract.violation"),
+		response.WithExtension("violations", violations),
+		response.WithDetail(detail))
+}
+
+// resolveRunInputs seeds args from the outputs of req.InputsFromRun. The
+// downstream job must declare inputs_from naming the upstream run's job, the
+// upstream run must have completed, and outputs feeding args must agree on
+// type. Explicit request args take precedence over upstream outputs.
+func (h *RunsHandler) resolveRunInputs(req runRequest, cfg *types.Config, jobs map[string]indexer.JobInfo) (map[string]any, map[string]any, *response.Problem) {
+	fail := func(detail string, violations ...engine.ContractViolation) (map[string]any, map[string]any, *response.Problem) {
+		prob := contractViolationProblem(detail, violations)
+		return nil, nil, &prob
+	}
+	upstreamID := cfg.InputsFrom
+	if upstreamID == "" {
+		return fail("job does not declare inputs_from", engine.ContractViolation{
+			Field: "inputs_from_run", Code: "inputs.undeclared", Message: "job does not accept inputs from other runs",
+		})
+	}
+	run, ok := h.store.Get(req.InputsFromRun)
+	if !ok {
+		prob := response.New(http.StatusNotFound, "run not found", response.WithDetail(req.InputsFromRun))
+		return nil, nil, &prob
+	}
+	if !strings.EqualFold(run.JobID, upstreamID) {
+		return fail(fmt.Sprintf("run %s belongs to job %s", run.ID, run.JobID), engine.ContractViolation{
+			Field: "inputs_from_run", Code: "upstream.job", Message: fmt.Sprintf("job accepts inputs from %s only", upstreamID),
+		})
+	}
+	if run.Status != "completed" {
+		return fail(fmt.Sprintf("run %s is %s", run.ID, run.Status), engine.ContractViolation{
+			Field: "inputs_from_run", Code: "upstream.status", Message: "upstream run has not completed",
+		})
+	}
+	if job, ok := jobs[strings.ToLower(upstreamID)]; ok {
+		if upstreamCfg, err := h.loadConfig(filepath.Dir(job.Path), ""); err == nil {
+			if violations := engine.CheckInputs(upstreamCfg.Outputs, cfg.ArgSpec); len(violations) > 0 {
+				return fail(fmt.Sprintf("outputs of %s do not match the arguments of this job", upstreamID), violations...)
+			}
+		}
+	}
+
+	outputs, _ := run.Result["outputs"].(map[string]any)
+	args := engine.InputArgs(outputs, cfg.ArgSpec)
+	if args == nil {
+		args = map[string]any{}
+	}
+	for name, val := range req.Args {
+		args[name] = val
+	}
+	prov := map[string]any{"run_id": run.ID, "job_id": run.JobID}
+	return args, prov, nil
+}
+
+// collectRunOutputs validates the outputs a completed run wrote to
+// $FLWD_OUTPUTS against the job's outputs: declaration. Jobs without declared
+// outputs are not checked.
+func collectRunOutputs(cfg *types.Config, runDir string) (map[string]any, []engine.ContractViolation, error) {
+	if cfg == nil || len(cfg.Outputs) == 0 {
+		return nil, nil, nil
+	}
+	raw := map[string]string{}
+	f, err := os.Open(filepath.Join(runDir, engine.OutputsFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("open outputs: %w", err)
+	}
+	if f != nil {
+		defer f.Close()
+		if raw, err = engine.ParseOutputs(f); err != nil {
+			return nil, nil, err
+		}
+	}
+	values, violations := engine.ValidateOutputs(cfg.Outputs, raw)
+	return values, violations, nil
+}
+
+// recordRunOutputs stores validated outputs, or the violations that failed
+// the run, in the run result.
+func (h *RunsHandler) recordRunOutputs(runID string, outputs map[string]any, violations []engine.ContractViolation) {
+	if len(outputs) == 0 && len(violations) == 0 {
+		return
+	}
+	current, ok := h.store.Get(runID)
+	if !ok {
+		return
+	}
+	result := make(map[string]any, len(current.Result)+1)
+	for k, v := range current.Result {
+		result[k] = v
+	}
+	if len(violations) > 0 {
+		result["contract_violations"] = violations
+	} else {
+		result["outputs"] = outputs
+	}
+	current.Result = result
+	h.store.Update(current)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+func writeContractJobs(t *testing.T, root, buildScript string) {
+	t.Helper()
+	writeJobConfig(t, root, "build", `
+version: v1
+job:
+  id: build
+  name: Build
+interpreter: bash
+outputs:
+  - name: version
+    type: string
+    required: true
+  - name: size
+    type: integer
+`)
+	if err := os.WriteFile(filepath.Join(root, "build", "100_main.sh"), []byte(buildScript), 0o755); err != nil {
+		t.Fatalf("write build script: %v", err)
+	}
+	writeJobConfig(t, root, "deploy", `
+version: v1
+job:
+  id: deploy
+  name: Deploy
+interpreter: bash
+inputs_from: build
+argspec:
+  args:
+    - name: version
+      type: string
+      required: true
+    - name: target
+      type: string
+`)
+	if err := os.WriteFile(filepath.Join(root, "deploy", "100_main.sh"), []byte("echo \"$ARG_VERSION\"\n"), 0o755); err != nil {
+		t.Fatalf("write deploy script: %v", err)
+	}
+}
+
+func waitForTerminalRun(t *testing.T, store *runstore.Store, body []byte) runstore.Run {
+	t.Helper()
+	var payload RunPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("decode run: %v", err)
+	}
+	var run runstore.Run
+	waitFor(func() bool {
+		var ok bool
+		run, ok = store.Get(payload.ID)
+		return ok && isTerminalStatus(run.Status)
+	}, 5*time.Second, t)
+	return run
+}
+
+func TestRunChainsOutputsIntoDownstreamArgs(t *testing.T) {
+	root := t.TempDir()
+	writeContractJobs(t, root, "echo version=1.2.3 >> \"$FLWD_OUTPUTS\"\necho size=42 >> \"$FLWD_OUTPUTS\"\n")
+	store := runstore.New()
+	h := NewRunsHandler(RunsConfig{Root: root, Store: store, Events: &recordingSink{}})
+
+	rec := postRun(t, h, `{"job_id":"build"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	upstream := waitForTerminalRun(t, store, rec.Body.Bytes())
+	if upstream.Status != "completed" {
+		t.Fatalf("expected upstream to complete, got %s (%v)", upstream.Status, upstream.Result)
+	}
+	outputs, _ := upstream.Result["outputs"].(map[string]any)
+	if outputs["version"] != "1.2.3" || outputs["size"] != int64(42) {
+		t.Fatalf("unexpected outputs %v", upstream.Result)
+	}
+
+	rec = postRun(t, h, `{"job_id":"deploy","inputs_from_run":"`+upstream.ID+`","args":{"target":"prod"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var payload RunPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode run: %v", err)
+	}
+	resolved, _ := payload.Result["resolved_args"].(map[string]any)
+	if resolved["version"] != "1.2.3" || resolved["target"] != "prod" {
+		t.Fatalf("expected args seeded from upstream outputs, got %v", payload.Result)
+	}
+	if from, _ := payload.Provenance["inputs_from"].(map[string]any); from["run_id"] != upstream.ID {
+		t.Fatalf("expected provenance.inputs_from, got %v", payload.Provenance)
+	}
+}
+
+func TestRunFailsWhenOutputsBreakContract(t *testing.T) {
+	root := t.TempDir()
+	writeContractJobs(t, root, "echo size=big >> \"$FLWD_OUTPUTS\"\n")
+	store := runstore.New()
+	h := NewRunsHandler(RunsConfig{Root: root, Store: store, Events: &recordingSink{}})
+
+	rec := postRun(t, h, `{"job_id":"build"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	run := waitForTerminalRun(t, store, rec.Body.Bytes())
+	if run.Status != "failed" {
+		t.Fatalf("expected contract violation to fail the run, got %s", run.Status)
+	}
+	data, _ := json.Marshal(run.Result["contract_violations"])
+	var violations []map[string]string
+	_ = json.Unmarshal(data, &violations)
+	if len(violations) != 2 || violations[0]["code"] != "output.type" || violations[1]["code"] != "output.missing" {
+		t.Fatalf("unexpected violations %s", data)
+	}
+
+	rec = postRun(t, h, `{"job_id":"deploy","inputs_from_run":"`+run.ID+`"}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var problem map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &problem)
+	if problem["code"] != "contract.violation" {
+		t.Fatalf("unexpected problem %v", problem)
+	}
+}
+
+func TestRunRejectsInputsFromUndeclaredJob(t *testing.T) {
+	root := t.TempDir()
+	writeContractJobs(t, root, "echo version=1 >> \"$FLWD_OUTPUTS\"\n")
+	store := runstore.New()
+	h := NewRunsHandler(RunsConfig{Root: root, Store: store, Events: &recordingSink{}})
+
+	rec := postRun(t, h, `{"job_id":"build"}`)
+	upstream := waitForTerminalRun(t, store, rec.Body.Bytes())
+
+	rec = postRun(t, h, `{"job_id":"build","inputs_from_run":"`+upstream.ID+`"}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var problem map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &problem)
+	violations, _ := problem["violations"].([]any)
+	if len(violations) != 1 || violations[0].(map[string]any)["code"] != "inputs.undeclared" {
+		t.Fatalf("unexpected problem %v", problem)
+	}
+}
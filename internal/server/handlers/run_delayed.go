@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+// delayedRun is a run created with start_at, waiting in the scheduled status
+// for its timer.
+type delayedRun struct {
+	prep  *preparedRun
+	resp  RunPayload
+	timer *time.Timer
+}
+
+// scheduledRequest is the request a scheduled run keeps in the run store.
+// Trigger and ScheduleID are not part of the request's JSON form, so they are
+// stored beside it.
+type scheduledRequest struct {
+	Request    runRequest     `json:"request"`
+	Trigger    map[string]any `json:"trigger,omitempty"`
+	ScheduleID string         `json:"schedule_id,omitempty"`
+	Principal  string         `json:"principal,omitempty"`
+}
+
+func encodeScheduledRequest(prep *preparedRun) json.RawMessage {
+	principal, _ := requestctx.Principal(prep.ctx)
+	data, err := json.Marshal(scheduledRequest{
+		Request:    prep.request,
+		Trigger:    prep.request.Trigger,
+		ScheduleID: prep.request.ScheduleID,
+		Principal:  principal,
+	})
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// DelayedRunPayload describes a scheduled one-shot run in /schedules
+// responses.
+type DelayedRunPayload struct {
+	RunID   string    `json:"run_id"`
+	JobID   string    `json:"job_id"`
+	StartAt time.Time `json:"start_at"`
+}
+
+// delayRun holds a scheduled run until its start time.
+func (h *RunsHandler) delayRun(prep *preparedRun, resp RunPayload) {
+	h.delayedMu.Lock()
+	defer h.delayedMu.Unlock()
+	runID := resp.ID
+	h.delayed[runID] = &delayedRun{
+		prep:  prep,
+		resp:  resp,
+		timer: time.AfterFunc(resp.StartAt.Sub(h.now()), func() { h.startDelayedRun(runID) }),
+	}
+}
+
+// startDelayedRun dispatches a scheduled run once its start time has come,
+// through the job's concurrency limit like any other run. The run was
+// accepted when it was created, so a job at its limit queues it whatever its
+// policy.
+func (h *RunsHandler) startDelayedRun(runID string) {
+	// Hold delayedMu until the run is registered so that a cancel racing the
+	// start either drops the run first or reaches its execution context.
+	h.delayedMu.Lock()
+	defer h.delayedMu.Unlock()
+	delayed, ok := h.delayed[runID]
+	if !ok {
+		return
+	}
+	delete(h.delayed, runID)
+	run, ok := h.store.Get(runID)
+	if !ok {
+		return
+	}
+	resp := delayed.resp
+	resp.Status = defaultRunStatus
+	resp.QueuePosition, _ = h.limiter.admit(resp.JobID, runID, delayed.prep.config.Concurrency, false)
+	runCtx := newRunExecutionContext(delayed.prep, resp)
+	h.running.Register(runID, runCtx)
+	run.Status = defaultRunStatus
+	run.QueuePosition = resp.QueuePosition
+	run.Request = nil
+	h.store.Update(run)
+	h.dispatchRun(runCtx)
+}
+
+// ResumeScheduledRuns re-arms the scheduled runs a previous server process
+// left in the run store. Each is prepared again from its stored request and
+// starts at its start time, or right away when that has passed. Runs that can
+// no longer be prepared fail. It returns the number of runs re-armed.
+func (h *RunsHandler) ResumeScheduledRuns() int {
+	resumed := 0
+	for _, run := range h.store.List() {
+		if run.Status != scheduledRunStatus {
+			continue
+		}
+		if err := h.resumeScheduledRun(run); err != nil {
+			failRun(h.store, run, h.now(), "scheduled run could not be resumed: "+err.Error())
+			continue
+		}
+		resumed++
+	}
+	return resumed
+}
+
+func (h *RunsHandler) resumeScheduledRun(run runstore.Run) error {
+	if run.StartAt == nil || len(run.Request) == 0 {
+		return errors.New("no stored request")
+	}
+	var stored scheduledRequest
+	if err := json.Unmarshal(run.Request, &stored); err != nil {
+		return err
+	}
+	req := stored.Request
+	req.Trigger, req.ScheduleID = stored.Trigger, stored.ScheduleID
+	// The start time was checked when the run was created and may have
+	// passed since.
+	req.StartAt = nil
+	ctx := context.Background()
+	if stored.Principal != "" {
+		ctx = requestctx.WithPrincipal(ctx, stored.Principal)
+	}
+	prep, prob := h.prepareRun(ctx, req)
+	if prob != nil {
+		if prob.Detail != "" {
+			return errors.New(prob.Detail)
+		}
+		return errors.New(prob.Title)
+	}
+	resp := payloadFromStore(run)
+	resp.SecurityProfile = prep.profile
+	h.delayRun(prep, resp)
+	return nil
+}
+
+// releaseDelayedRun forgets a scheduled run so that it never starts.
+func (h *RunsHandler) releaseDelayedRun(runID string) {
+	h.delayedMu.Lock()
+	defer h.delayedMu.Unlock()
+	if delayed, ok := h.delayed[runID]; ok {
+		delayed.timer.Stop()
+		delete(h.delayed, runID)
+	}
+}
+
+// delayedRuns lists the scheduled runs the caller may see by start time.
+func (h *RunsHandler) delayedRuns(ctx context.Context) []DelayedRunPayload {
+	h.delayedMu.Lock()
+	out := make([]DelayedRunPayload, 0, len(h.delayed))
+	for _, delayed := range h.delayed {
+		if !RunVisible(ctx, runstore.Run{JobID: delayed.resp.JobID, Provenance: delayed.prep.provenance}) {
+			continue
+		}
+		out = append(out, DelayedRunPayload{
+			RunID:   delayed.resp.ID,
+			JobID:   delayed.resp.JobID,
+			StartAt: *delayed.resp.StartAt,
+		})
+	}
+	h.delayedMu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].StartAt.Equal(out[j].StartAt) {
+			return out[i].StartAt.Before(out[j].StartAt)
+		}
+		return out[i].RunID < out[j].RunID
+	})
+	return out
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/coredb"
+	"github.com/flowd-org/flowd/internal/paths"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+func newDelayedRunHandler(t *testing.T) (*RunsHandler, *runstore.Store) {
+	t.Helper()
+	root := t.TempDir()
+	writeJobConfig(t, root, "report", `
+version: v1
+job:
+  id: report
+  name: Report
+interpreter: bash
+`)
+	if err := os.WriteFile(filepath.Join(root, "report", "100_main.sh"), []byte("true\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	store := runstore.New()
+	return NewRunsHandler(RunsConfig{Root: root, Store: store, Events: &recordingSink{}}), store
+}
+
+func TestDelayedRunStartsAtStartTime(t *testing.T) {
+	h, store := newDelayedRunHandler(t)
+	startAt := time.Now().UTC().Add(300 * time.Millisecond)
+	rec := postRun(t, h, `{"job_id":"report","start_at":"`+startAt.Format(time.RFC3339Nano)+`"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var run RunPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &run); err != nil {
+		t.Fatalf("decode run: %v", err)
+	}
+	if run.Status != scheduledRunStatus || run.StartAt == nil || !run.StartAt.Equal(startAt) {
+		t.Fatalf("expected run scheduled for %v, got %s at %v", startAt, run.Status, run.StartAt)
+	}
+
+	schedules := NewSchedulesHandler(SchedulesConfig{Runs: h})
+	rec, out := pipelineRequest(t, schedules, http.MethodGet, "/schedules", "", "")
+	delayed, _ := out["delayed_runs"].([]any)
+	if rec.Code != http.StatusOK || len(delayed) != 1 || delayed[0].(map[string]any)["run_id"] != run.ID {
+		t.Fatalf("expected the run listed as delayed, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	body, _ := json.Marshal(run)
+	finished := waitForTerminalRun(t, store, body)
+	if finished.Status != "completed" {
+		t.Fatalf("expected run completed, got %s", finished.Status)
+	}
+	if receipt, err := os.Stat(filepath.Join(paths.RunDir(run.ID), "receipt.json")); err != nil || receipt.ModTime().Before(startAt) {
+		t.Fatalf("expected the run to execute after %v, got %v (%v)", startAt, receipt, err)
+	}
+	if delayed := h.delayedRuns(context.Background()); len(delayed) != 0 {
+		t.Fatalf("expected no delayed runs left, got %+v", delayed)
+	}
+}
+
+func TestDelayedRunCancelAndValidation(t *testing.T) {
+	h, store := newDelayedRunHandler(t)
+	startAt := time.Now().UTC().Add(time.Hour).Format(time.RFC3339)
+	rec := postRun(t, h, `{"job_id":"report","start_at":"`+startAt+`"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var run RunPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &run); err != nil {
+		t.Fatalf("decode run: %v", err)
+	}
+	canceled := h.cancelRun(context.Background(), run.ID, "test")
+	if canceled.Status != "canceled" {
+		t.Fatalf("expected canceled run, got %s", canceled.Status)
+	}
+	if delayed := h.delayedRuns(context.Background()); len(delayed) != 0 {
+		t.Fatalf("expected canceled run to leave the delayed list, got %+v", delayed)
+	}
+	if got, _ := store.Get(run.ID); got.Status != "canceled" {
+		t.Fatalf("expected stored run canceled, got %s", got.Status)
+	}
+
+	past := time.Now().UTC().Add(-time.Minute).Format(time.RFC3339)
+	rec = postRun(t, h, `{"job_id":"report","start_at":"`+past+`"}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "run.start_at_past") {
+		t.Fatalf("expected 400 for a past start_at, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestScheduledRunsSurviveRestart(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "report", `
+version: v1
+job:
+  id: report
+  name: Report
+interpreter: bash
+`)
+	if err := os.WriteFile(filepath.Join(root, "report", "100_main.sh"), []byte("true\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	dir := t.TempDir()
+	ctx := context.Background()
+	db, err := coredb.Open(ctx, coredb.Options{DataDir: dir})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	store := OpenRunStore(ctx, db, nil)
+	h := NewRunsHandler(RunsConfig{Root: root, Store: store, Events: &recordingSink{}})
+	schedule := func(startAt time.Time) RunPayload {
+		rec := postRun(t, h, `{"job_id":"report","labels":{"team":"ops"},"start_at":"`+startAt.Format(time.RFC3339)+`"}`)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var run RunPayload
+		if err := json.Unmarshal(rec.Body.Bytes(), &run); err != nil {
+			t.Fatalf("decode run: %v", err)
+		}
+		h.releaseDelayedRun(run.ID)
+		return run
+	}
+	now := time.Now().UTC()
+	due := schedule(now.Add(time.Hour))
+	later := schedule(now.Add(3 * time.Hour))
+	store.Create(runstore.Run{ID: "legacy", JobID: "report", Status: scheduledRunStatus, StartedAt: now, StartAt: &now})
+	_ = db.Close()
+
+	// The server comes back two hours later: the first run is overdue.
+	db, err = coredb.Open(ctx, coredb.Options{DataDir: dir})
+	if err != nil {
+		t.Fatalf("reopen db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	store = OpenRunStore(ctx, db, nil)
+	if run, _ := store.Get(later.ID); run.Status != scheduledRunStatus {
+		t.Fatalf("expected scheduled run kept across the restart, got %s", run.Status)
+	}
+	h = NewRunsHandler(RunsConfig{Root: root, Store: store, Events: &recordingSink{},
+		Now: func() time.Time { return time.Now().UTC().Add(2 * time.Hour) }})
+	if n := h.ResumeScheduledRuns(); n != 2 {
+		t.Fatalf("expected two runs re-armed, got %d", n)
+	}
+
+	body, _ := json.Marshal(due)
+	if finished := waitForTerminalRun(t, store, body); finished.Status != "completed" || finished.Labels["team"] != "ops" {
+		t.Fatalf("expected the overdue run to start on resume, got %+v", finished)
+	}
+	if delayed := h.delayedRuns(context.Background()); len(delayed) != 1 || delayed[0].RunID != later.ID {
+		t.Fatalf("expected the later run waiting for its start time, got %+v", delayed)
+	}
+	if run, _ := store.Get("legacy"); run.Status != "failed" || !strings.Contains(run.Result["error"].(string), "could not be resumed") {
+		t.Fatalf("expected a scheduled run without its request to fail, got %+v", run)
+	}
+	h.releaseDelayedRun(later.ID)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/flowd-org/flowd/internal/coredb"
 	"github.com/flowd-org/flowd/internal/metrics"
 	"github.com/flowd-org/flowd/internal/observability/tracing"
+	"github.com/flowd-org/flowd/internal/server/faults"
 	"github.com/flowd-org/flowd/internal/server/response"
 	"github.com/flowd-org/flowd/internal/server/runstore"
 	"github.com/flowd-org/flowd/internal/server/sse"
@@ -31,15 +32,22 @@ func (f EventSinkFunc) Publish(runID string, ev sse.Event) {
 }
 
 // NewRunEventsHandler streams events for GET /runs/{id}/events` using the Core DB
-// journal for replay and the SSE Hub for live fan-out.
-func NewRunEventsHandler(store *runstore.Store, hub EventFeed, journal *coredb.Journal) http.Handler {
+// journal for replay and the SSE Hub for live fan-out. A nil streams limiter
+// leaves open streams unlimited.
+//
+// With ?replay=all the stream starts from the first journaled event whatever
+// Last-Event-ID says, and ends once the run's terminal event has been sent, so
+// clients can fetch the full history of a finished run.
+func NewRunEventsHandler(store *runstore.Store, hub EventFeed, journal *coredb.Journal, streams *StreamLimiter) http.Handler {
 	if store == nil {
 		store = runstore.New()
 	}
-	_ = store
 	if hub == nil {
 		hub = sse.New(sse.Config{})
 	}
+	if streams == nil {
+		streams = NewStreamLimiter(StreamLimits{})
+	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -56,10 +64,24 @@ func NewRunEventsHandler(store *runstore.Store, hub EventFeed, journal *coredb.J
 			return
 		}
 
+		replayAll := false
+		switch replay := r.URL.Query().Get("replay"); replay {
+		case "":
+		case "all":
+			replayAll = true
+		default:
+			response.Write(w, response.New(http.StatusBadRequest, "invalid replay mode",
+				response.WithDetail(fmt.Sprintf("replay %q is not supported; use all", replay))))
+			return
+		}
+
 		lastEventID := r.Header.Get("Last-Event-ID")
 		if lastEventID == "" {
 			lastEventID = r.URL.Query().Get("last_event_id")
 		}
+		if replayAll {
+			lastEventID = ""
+		}
 		lastSeq, err := coredb.ParseEventID(lastEventID)
 		if err != nil {
 			response.Write(w, response.New(http.StatusBadRequest, "invalid Last-Event-ID", response.WithDetail(err.Error())))
@@ -109,6 +131,13 @@ func NewRunEventsHandler(store *runstore.Store, hub EventFeed, journal *coredb.J
 			}
 		}
 
+		release, problem := streams.Acquire(r, runID)
+		if problem != nil {
+			response.Write(w, *problem)
+			return
+		}
+		defer release()
+
 		endStream := metrics.SSEStreamStarted()
 		defer func() {
 			if endStream != nil {
@@ -133,6 +162,7 @@ func NewRunEventsHandler(store *runstore.Store, hub EventFeed, journal *coredb.J
 		flush(w)
 
 		lastSentSeq := lastSeq
+		finished := false
 		if journal != nil {
 			err = journal.ForEach(ctx, runID, lastSeq, func(entry coredb.JournalEntry) error {
 				if ctx.Err() != nil {
@@ -148,6 +178,7 @@ func NewRunEventsHandler(store *runstore.Store, hub EventFeed, journal *coredb.J
 					return err
 				}
 				lastSentSeq = entry.Seq
+				finished = finished || isTerminalEvent(entry.EventType)
 				return nil
 			})
 			if err != nil && !errors.Is(err, context.Canceled) {
@@ -155,6 +186,13 @@ func NewRunEventsHandler(store *runstore.Store, hub EventFeed, journal *coredb.J
 				return
 			}
 		}
+		if replayAll {
+			// A finished run whose events were compacted away has nothing
+			// more to send either.
+			if run, ok := store.Get(runID); finished || (journal != nil && ok && isTerminalStatus(run.Status) && lastSentSeq == 0) {
+				return
+			}
+		}
 
 		for {
 			select {
@@ -174,6 +212,9 @@ func NewRunEventsHandler(store *runstore.Store, hub EventFeed, journal *coredb.J
 				if err := writeSSEPayload(ctx, w, runID, msg, msgSeq); err != nil {
 					return
 				}
+				if replayAll && isTerminalEvent(extractEventType(msg)) {
+					return
+				}
 			}
 		}
 	})
@@ -214,6 +255,9 @@ func writeSSE(ctx context.Context, w http.ResponseWriter, runID string, ev sse.E
 		builder.WriteByte('\n')
 	}
 	builder.WriteByte('\n')
+	if err = faults.Delay(ctx); err != nil {
+		return err
+	}
 	if _, err = w.Write([]byte(builder.String())); err != nil {
 		return err
 	}
@@ -232,6 +276,9 @@ func writeSSEPayload(ctx context.Context, w http.ResponseWriter, runID string, p
 		span.SetAttributes(tracing.Int64("sse.event_seq", seq))
 	}
 	defer tracing.End(span, &err)
+	if err = faults.Delay(ctx); err != nil {
+		return err
+	}
 	if _, err = w.Write(payload); err != nil {
 		return err
 	}
@@ -245,6 +292,23 @@ func flush(w http.ResponseWriter) {
 	}
 }
 
+// isTerminalEvent reports whether eventType is the last event of a run.
+func isTerminalEvent(eventType string) bool {
+	return eventType == "run.finish" || eventType == "run.canceled"
+}
+
+func extractEventType(msg []byte) string {
+	for _, line := range strings.Split(string(msg), "\n") {
+		if eventType, ok := strings.CutPrefix(line, "event:"); ok {
+			return strings.TrimSpace(eventType)
+		}
+		if line == "" {
+			break
+		}
+	}
+	return ""
+}
+
 func extractEventID(msg []byte) int64 {
 	lines := strings.Split(string(msg), "\n")
 	for _, line := range lines {
@@ -22,7 +22,7 @@ func TestRunEventsHandlerStreamsEvents(t *testing.T) {
 		hub.Publish(runID, ev)
 	}))
 
-	h := NewRunEventsHandler(store, hub, journal)
+	h := NewRunEventsHandler(store, hub, journal, nil)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	req := httptest.NewRequest(http.MethodGet, "/runs/run-123/events", nil).WithContext(ctx)
@@ -61,7 +61,7 @@ func TestRunEventsHandlerReplayFromHeader(t *testing.T) {
 	sink.Publish("run-456", sse.Event{Event: "run.start", Data: "{}"})
 	sink.Publish("run-456", sse.Event{Event: "step.log", Data: "{\"msg\":\"hello\"}"})
 
-	h := NewRunEventsHandler(store, hub, journal)
+	h := NewRunEventsHandler(store, hub, journal, nil)
 	req := httptest.NewRequest(http.MethodGet, "/runs/run-456/events", nil)
 	req.Header.Set("Last-Event-ID", "1")
 	rec := httptest.NewRecorder()
@@ -99,7 +99,7 @@ func TestRunEventsHandlerReplayWithoutLastID(t *testing.T) {
 	sink.Publish("run-789", sse.Event{Event: "run.start", Data: "{}"})
 	sink.Publish("run-789", sse.Event{Event: "step.log", Data: "{\"msg\":\"world\"}"})
 
-	h := NewRunEventsHandler(store, hub, journal)
+	h := NewRunEventsHandler(store, hub, journal, nil)
 	req := httptest.NewRequest(http.MethodGet, "/runs/run-789/events", nil)
 	rec := httptest.NewRecorder()
 
@@ -125,7 +125,7 @@ func TestRunEventsHandlerAllowsSubscribeBeforeRun(t *testing.T) {
 	store := runstore.New()
 	hub := sse.New(sse.Config{KeepAliveInterval: time.Millisecond * 10})
 	journal := newTestJournal(t)
-	h := NewRunEventsHandler(store, hub, journal)
+	h := NewRunEventsHandler(store, hub, journal, nil)
 	req := httptest.NewRequest(http.MethodGet, "/runs/pending-run/events", nil)
 	rec := httptest.NewRecorder()
 
@@ -158,7 +158,7 @@ func TestRunEventsHandlerReturns410ForExpiredCursor(t *testing.T) {
 	sink.Publish("run-expired", sse.Event{Event: "step.log", Data: "{\"msg\":\"old\"}"})
 	sink.Publish("run-expired", sse.Event{Event: "step.log", Data: "{\"msg\":\"new\"}"})
 
-	h := NewRunEventsHandler(store, hub, dirJournal)
+	h := NewRunEventsHandler(store, hub, dirJournal, nil)
 	req := httptest.NewRequest(http.MethodGet, "/runs/run-expired/events", nil)
 	req.Header.Set("Last-Event-ID", "1")
 	rec := httptest.NewRecorder()
@@ -175,6 +175,41 @@ func TestRunEventsHandlerReturns410ForExpiredCursor(t *testing.T) {
 	}
 }
 
+func TestRunEventsHandlerReplayAllEndsAfterFinishedRun(t *testing.T) {
+	store := runstore.New()
+	store.Create(runstore.Run{ID: "run-done", JobID: "demo", Status: "completed", StartedAt: time.Unix(0, 0)})
+	hub := sse.New(sse.Config{KeepAliveInterval: time.Hour})
+	journal := newTestJournal(t)
+	sink := NewJournalEventSink(journal, EventSinkFunc(func(runID string, ev sse.Event) {
+		hub.Publish(runID, ev)
+	}))
+
+	sink.Publish("run-done", sse.Event{Event: "run.start", Data: "{}"})
+	sink.Publish("run-done", sse.Event{Event: "step.log", Data: "{\"msg\":\"hi\"}"})
+	sink.Publish("run-done", sse.Event{Event: "run.finish", Data: "{\"status\":\"completed\"}"})
+
+	h := NewRunEventsHandler(store, hub, journal, nil)
+	// The cursor is ignored: replay=all always starts from the first event,
+	// and the stream ends without the client disconnecting.
+	req := httptest.NewRequest(http.MethodGet, "/runs/run-done/events?replay=all", nil)
+	req.Header.Set("Last-Event-ID", "2")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	for _, want := range []string{"id: 1\nevent: run.start", "id: 2\nevent: step.log", "id: 3\nevent: run.finish"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in replay, got %q", want, body)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs/run-done/events?replay=some", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown replay mode, got %d", rec.Code)
+	}
+}
+
 func newTestJournal(t *testing.T) *coredb.Journal {
 	t.Helper()
 	return newTestJournalWithLimit(t, 0)
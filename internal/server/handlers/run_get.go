@@ -1,15 +1,30 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/flowd-org/flowd/internal/server/response"
 	"github.com/flowd-org/flowd/internal/server/runstore"
 )
 
-// NewRunGetHandler returns an HTTP handler for GET /runs/{id}.
-func NewRunGetHandler(store *runstore.Store) http.Handler {
+// RunArchive loads runs that were moved out of the run store into cold
+// storage.
+type RunArchive interface {
+	LoadRun(ctx context.Context, runID string) (runstore.Run, bool, error)
+}
+
+// maxRunWait bounds the ?wait= long-poll on GET /runs/{id}.
+const maxRunWait = 60 * time.Second
+
+// NewRunGetHandler returns an HTTP handler for GET /runs/{id}. Runs missing
+// from store are looked up in archive, when set, and flagged as archived.
+// With ?wait=<duration>&until=terminal the request blocks until the run is
+// terminal or the wait elapses, then returns the run as it stands.
+func NewRunGetHandler(store *runstore.Store, archive RunArchive) http.Handler {
 	if store == nil {
 		store = runstore.New()
 	}
@@ -25,12 +40,97 @@ func NewRunGetHandler(store *runstore.Store) http.Handler {
 			return
 		}
 
-		run, ok := store.Get(id)
-		if !ok {
-			response.Write(w, response.New(http.StatusNotFound, "run not found"))
+		fields, prob := parseRunFields(r)
+		if prob != nil {
+			response.Write(w, *prob)
 			return
 		}
 
-		writeRunPayload(w, payloadFromStore(run), http.StatusOK)
+		wait, prob := parseRunWait(r)
+		if prob != nil {
+			response.Write(w, *prob)
+			return
+		}
+
+		run, ok := store.Get(id)
+		if ok && wait > 0 && !isTerminalStatus(run.Status) {
+			run, ok = awaitTerminalRun(r.Context(), store, id, wait)
+			if r.Context().Err() != nil {
+				return
+			}
+		}
+		if ok {
+			writeRunResource(w, r, run, fields.apply(payloadFromStore(run)))
+			return
+		}
+		if archive != nil {
+			archived, found, err := archive.LoadRun(r.Context(), id)
+			if err != nil {
+				response.Write(w, response.New(http.StatusInternalServerError, "load archived run failed", response.WithDetail(err.Error())))
+				return
+			}
+			if found {
+				payload := payloadFromStore(archived)
+				payload.Archived = true
+				writeRunResource(w, r, archived, fields.apply(payload))
+				return
+			}
+		}
+		response.Write(w, response.New(http.StatusNotFound, "run not found"))
 	})
 }
+
+// parseRunWait reads the long-poll parameters. until defaults to terminal,
+// the only supported condition.
+func parseRunWait(r *http.Request) (time.Duration, *response.Problem) {
+	q := r.URL.Query()
+	raw := strings.TrimSpace(q.Get("wait"))
+	until := strings.TrimSpace(q.Get("until"))
+	if raw == "" {
+		if until != "" {
+			prob := response.New(http.StatusBadRequest, "invalid wait",
+				response.WithExtension("code", "wait.invalid"),
+				response.WithDetail("until requires wait"))
+			return 0, &prob
+		}
+		return 0, nil
+	}
+	if until != "" && until != "terminal" {
+		prob := response.New(http.StatusBadRequest, "invalid wait",
+			response.WithExtension("code", "wait.invalid"),
+			response.WithDetail(fmt.Sprintf("unsupported until %q; expected terminal", until)))
+		return 0, &prob
+	}
+	wait, err := time.ParseDuration(raw)
+	if err != nil || wait < 0 || wait > maxRunWait {
+		prob := response.New(http.StatusBadRequest, "invalid wait",
+			response.WithExtension("code", "wait.invalid"),
+			response.WithDetail(fmt.Sprintf("wait must be a duration between 0s and %s", maxRunWait)))
+		return 0, &prob
+	}
+	return wait, nil
+}
+
+// awaitTerminalRun blocks until the run is terminal, removed from the store,
+// wait elapses or ctx is done, and returns the run's latest state.
+func awaitTerminalRun(ctx context.Context, store *runstore.Store, id string, wait time.Duration) (runstore.Run, bool) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		changed, stop := store.Watch(id)
+		run, ok := store.Get(id)
+		if !ok || isTerminalStatus(run.Status) {
+			stop()
+			return run, ok
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			stop()
+			return run, ok
+		case <-ctx.Done():
+			stop()
+			return run, ok
+		}
+	}
+}
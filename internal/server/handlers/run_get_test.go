@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+type mapRunArchive map[string]runstore.Run
+
+func (a mapRunArchive) LoadRun(_ context.Context, runID string) (runstore.Run, bool, error) {
+	run, ok := a[runID]
+	return run, ok, nil
+}
+
+func TestRunGetHydratesArchivedRun(t *testing.T) {
+	archive := mapRunArchive{"run-old": {ID: "run-old", JobID: "backup", Status: "completed"}}
+	h := NewRunGetHandler(runstore.New(), archive)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs/run-old", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var payload RunPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode run: %v", err)
+	}
+	if !payload.Archived || payload.JobID != "backup" {
+		t.Fatalf("expected archived payload, got %+v", payload)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs/run-missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown run, got %d", rec.Code)
+	}
+}
+
+func TestRunGetSparseFieldset(t *testing.T) {
+	store := runstore.New()
+	store.Create(runstore.Run{ID: "run-1", JobID: "backup", Status: "completed", Result: map[string]any{"ok": true}})
+	h := NewRunGetHandler(store, nil)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs/run-1?fields=id,status", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode run: %v", err)
+	}
+	if len(body) != 2 || body["id"] != "run-1" || body["status"] != "completed" {
+		t.Fatalf("expected only id and status, got %v", body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs/run-1?fields=id,blob", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown field, got %d", rec.Code)
+	}
+	var prob map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &prob); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if prob["code"] != "fields.unknown" {
+		t.Fatalf("expected fields.unknown, got %v", prob)
+	}
+}
+
+func TestRunGetConditionalRequest(t *testing.T) {
+	store := runstore.New()
+	started := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	store.Create(runstore.Run{ID: "run-1", JobID: "backup", Status: "running", StartedAt: started})
+	h := NewRunGetHandler(store, nil)
+
+	get := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/runs/run-1", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("")
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with ETag, got %d %q", rec.Code, etag)
+	}
+	if got := rec.Header().Get("Last-Modified"); got != started.Format(http.TimeFormat) {
+		t.Fatalf("unexpected Last-Modified %q", got)
+	}
+
+	rec = get(etag)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("expected empty 304, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	finished := started.Add(time.Minute)
+	store.Update(runstore.Run{ID: "run-1", JobID: "backup", Status: "completed", StartedAt: started, FinishedAt: &finished})
+	rec = get(etag)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 after the run changed, got %d", rec.Code)
+	}
+	if rec.Header().Get("ETag") == etag {
+		t.Fatal("expected a new ETag after the run changed")
+	}
+	if got := rec.Header().Get("Last-Modified"); got != finished.Format(http.TimeFormat) {
+		t.Fatalf("unexpected Last-Modified %q", got)
+	}
+}
+
+func TestRunGetLongPollUntilTerminal(t *testing.T) {
+	store := runstore.New()
+	store.Create(runstore.Run{ID: "run-1", JobID: "backup", Status: "running"})
+	h := NewRunGetHandler(store, nil)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		store.Update(runstore.Run{ID: "run-1", JobID: "backup", Status: "running"})
+		time.Sleep(50 * time.Millisecond)
+		store.Update(runstore.Run{ID: "run-1", JobID: "backup", Status: "completed"})
+	}()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs/run-1?wait=5s&until=terminal", nil))
+	var payload RunPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode run: %v", err)
+	}
+	if rec.Code != http.StatusOK || payload.Status != "completed" {
+		t.Fatalf("expected completed run, got %d %+v", rec.Code, payload)
+	}
+
+	store.Create(runstore.Run{ID: "run-2", JobID: "backup", Status: "running"})
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs/run-2?wait=20ms", nil))
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode run: %v", err)
+	}
+	if rec.Code != http.StatusOK || payload.Status != "running" {
+		t.Fatalf("expected running run after timeout, got %d %+v", rec.Code, payload)
+	}
+
+	for _, query := range []string{"wait=forever", "wait=10m", "wait=1s&until=started", "until=terminal"} {
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs/run-2?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
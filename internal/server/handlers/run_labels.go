@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+const (
+	maxRunLabels          = 32
+	maxRunLabelKeyLength  = 63
+	maxRunLabelValueBytes = 256
+)
+
+// validateRunLabels checks the labels map of POST /runs. Keys may not contain
+// ':' so every label can be selected with ?label=key:value.
+func validateRunLabels(labels map[string]string) error {
+	if len(labels) > maxRunLabels {
+		return fmt.Errorf("at most %d labels are allowed", maxRunLabels)
+	}
+	for key, value := range labels {
+		if key == "" || key != strings.TrimSpace(key) {
+			return fmt.Errorf("label key %q must be non-empty without surrounding whitespace", key)
+		}
+		if len(key) > maxRunLabelKeyLength {
+			return fmt.Errorf("label key %q exceeds %d characters", key, maxRunLabelKeyLength)
+		}
+		if strings.ContainsAny(key, ":, \t\n") {
+			return fmt.Errorf("label key %q may not contain ':', ',' or whitespace", key)
+		}
+		if len(value) > maxRunLabelValueBytes {
+			return fmt.Errorf("label %q value exceeds %d bytes", key, maxRunLabelValueBytes)
+		}
+	}
+	return nil
+}
+
+// parseLabelSelector splits a key:value label selector.
+func parseLabelSelector(selector string) (key, value string, err error) {
+	key, value, ok := strings.Cut(selector, ":")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("label must have the form key:value")
+	}
+	return key, strings.TrimSpace(value), nil
+}
+
+func runHasLabel(run runstore.Run, key, value string) bool {
+	got, ok := run.Labels[key]
+	return ok && got == value
+}
+
+// runListFilter narrows GET /runs. Every set field must match; repeated
+// ?label= parameters must all match.
+type runListFilter struct {
+	JobID      string
+	Status     string
+	ScheduleID string
+	Labels     [][2]string
+}
+
+func parseRunListFilter(r *http.Request) (runListFilter, *response.Problem) {
+	query := r.URL.Query()
+	filter := runListFilter{
+		JobID:      strings.TrimSpace(query.Get("job_id")),
+		Status:     strings.ToLower(strings.TrimSpace(query.Get("status"))),
+		ScheduleID: strings.TrimSpace(query.Get("schedule_id")),
+	}
+	for _, selector := range query["label"] {
+		key, value, err := parseLabelSelector(selector)
+		if err != nil {
+			prob := response.New(http.StatusBadRequest, "invalid run filter", response.WithDetail(err.Error()))
+			return runListFilter{}, &prob
+		}
+		filter.Labels = append(filter.Labels, [2]string{key, value})
+	}
+	return filter, nil
+}
+
+func (f runListFilter) empty() bool {
+	return f.JobID == "" && f.Status == "" && f.ScheduleID == "" && len(f.Labels) == 0
+}
+
+func (f runListFilter) matches(run runstore.Run) bool {
+	if f.JobID != "" && !strings.EqualFold(run.JobID, f.JobID) {
+		return false
+	}
+	if f.Status != "" && !strings.EqualFold(run.Status, f.Status) {
+		return false
+	}
+	if f.ScheduleID != "" && runScheduleID(run) != f.ScheduleID {
+		return false
+	}
+	for _, label := range f.Labels {
+		if !runHasLabel(run, label[0], label[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// apply returns the runs matching f, preserving order.
+func (f runListFilter) apply(runs []runstore.Run) []runstore.Run {
+	if f.empty() {
+		return runs
+	}
+	matched := runs[:0:0]
+	for _, run := range runs {
+		if f.matches(run) {
+			matched = append(matched, run)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/headers"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+func TestRunsHandlerListFilters(t *testing.T) {
+	store := runstore.New()
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	store.Create(runstore.Run{ID: "r1", JobID: "deploy", Status: "running", StartedAt: base, Labels: map[string]string{"env": "prod", "team": "core"}})
+	store.Create(runstore.Run{ID: "r2", JobID: "deploy", Status: "completed", StartedAt: base.Add(time.Hour), Labels: map[string]string{"env": "prod"}})
+	store.Create(runstore.Run{ID: "r3", JobID: "backup", Status: "running", StartedAt: base.Add(2 * time.Hour), Labels: map[string]string{"env": "staging"}})
+	h := NewRunsHandler(RunsConfig{Root: t.TempDir(), Store: store})
+
+	for query, want := range map[string]string{
+		"":                                  "r3,r2,r1",
+		"?job_id=deploy":                    "r2,r1",
+		"?status=running":                   "r3,r1",
+		"?label=env:prod":                   "r2,r1",
+		"?label=env:prod&label=team:core":   "r1",
+		"?job_id=backup&label=env:prod":     "",
+		"?label=env:prod&sort=started_at":   "r1,r2",
+		"?status=RUNNING&label=env:staging": "r3",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/runs"+query, nil)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q: expected 200, got %d: %s", query, rec.Code, rec.Body.String())
+		}
+		var runs []RunPayload
+		if err := json.Unmarshal(rec.Body.Bytes(), &runs); err != nil {
+			t.Fatalf("%q: decode: %v", query, err)
+		}
+		ids := make([]string, 0, len(runs))
+		for _, run := range runs {
+			ids = append(ids, run.ID)
+		}
+		if got := strings.Join(ids, ","); got != want {
+			t.Fatalf("%q: expected %q, got %q", query, want, got)
+		}
+		if got := rec.Header().Get(headers.TotalCount); got != strconv.Itoa(len(ids)) {
+			t.Fatalf("%q: expected total count %d, got %q", query, len(ids), got)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/runs?label=env", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for malformed label, got %d", rec.Code)
+	}
+}
+
+func TestRunsHandlerCreateStoresLabels(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "demo", `
+version: v1
+job:
+  id: demo
+  name: Demo Job
+argspec:
+  args: []
+`)
+	store := runstore.New()
+	h := NewRunsHandler(RunsConfig{Root: root, Store: store})
+
+	req := httptest.NewRequest(http.MethodPost, "/runs", strings.NewReader(`{"job_id":"demo","labels":{"env":"prod"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	addIdempotencyHeader(req)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var payload RunPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if payload.Labels["env"] != "prod" {
+		t.Fatalf("expected labels in response, got %+v", payload.Labels)
+	}
+	run, ok := store.Get(payload.ID)
+	if !ok || run.Labels["env"] != "prod" {
+		t.Fatalf("expected labels stored with run, got %+v", run.Labels)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/runs", strings.NewReader(`{"job_id":"demo","labels":{"bad:key":"x"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	addIdempotencyHeader(req)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid label key, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
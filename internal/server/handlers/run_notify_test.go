@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/paths"
+	"github.com/flowd-org/flowd/internal/secrets"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+type recordingNotifier struct {
+	mu      sync.Mutex
+	jobs    []*types.Config
+	runs    []runstore.Run
+	errs    []error
+	started []runstore.Run
+}
+
+func (n *recordingNotifier) RunStarted(job *types.Config, run runstore.Run) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.started = append(n.started, run)
+}
+
+func (n *recordingNotifier) RunFinished(job *types.Config, run runstore.Run, runErr error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.jobs = append(n.jobs, job)
+	n.runs = append(n.runs, run)
+	n.errs = append(n.errs, runErr)
+}
+
+func (n *recordingNotifier) count() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.runs)
+}
+
+func TestRunsHandlerNotifiesFinishedRuns(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "nightly", `
+version: v1
+job:
+  id: nightly
+  name: Nightly
+interpreter: bash
+notify:
+  email:
+    to: [ops@example.com]
+    only_on_change: true
+`)
+	if err := os.WriteFile(filepath.Join(root, "nightly", "100_main.sh"), []byte("exit 3\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	store := runstore.New()
+	notifier := &recordingNotifier{}
+	h := NewRunsHandler(RunsConfig{Root: root, Store: store, Events: &recordingSink{}, Notifiers: []RunNotifier{notifier}})
+
+	rec := postRun(t, h, `{"job_id":"nightly"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	waitForTerminalRun(t, store, rec.Body.Bytes())
+	waitFor(func() bool { return notifier.count() == 1 }, 5*time.Second, t)
+
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+	run := notifier.runs[0]
+	if run.Status != "failed" || run.FinishedAt == nil {
+		t.Fatalf("expected finished failed run, got %+v", run)
+	}
+	if notifier.errs[0] == nil {
+		t.Fatalf("expected run error to be passed to notifier")
+	}
+	if len(notifier.started) != 1 || notifier.started[0].Status != "running" || notifier.started[0].ID != run.ID {
+		t.Fatalf("expected one running start notification, got %+v", notifier.started)
+	}
+	job := notifier.jobs[0]
+	if job == nil || job.Notify == nil || job.Notify.Email == nil || !job.Notify.Email.OnlyOnChange {
+		t.Fatalf("expected job notify config, got %+v", job)
+	}
+}
+
+func TestRunsHandlerNotifiesSecretResolutionFailures(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "deploy", `
+version: v1
+job:
+  id: deploy
+  name: Deploy
+interpreter: bash
+argspec:
+  args:
+    - name: token
+      type: string
+      secret: true
+      from: vault:apps/deploy/token
+`)
+	if err := os.WriteFile(filepath.Join(root, "deploy", "100_main.sh"), []byte("true\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	store := runstore.New()
+	sink := &recordingSink{}
+	notifier := &recordingNotifier{}
+	outage := secrets.ProviderFunc(func(context.Context, string) ([]byte, error) {
+		return nil, errors.New("vault unavailable")
+	})
+	h := NewRunsHandler(RunsConfig{Root: root, Store: store, Events: sink, Notifiers: []RunNotifier{notifier}, ArgSecrets: outage})
+
+	rec := postRun(t, h, `{"job_id":"deploy"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	waitFor(func() bool { return notifier.count() == 1 }, 5*time.Second, t)
+
+	notifier.mu.Lock()
+	run, runErr := notifier.runs[0], notifier.errs[0]
+	notifier.mu.Unlock()
+	if run.Status != "failed" || run.FinishedAt == nil || runErr == nil || !strings.Contains(runErr.Error(), "vault unavailable") {
+		t.Fatalf("expected failed run with the provider error, got %+v (%v)", run, runErr)
+	}
+	var finish map[string]any
+	for _, ev := range sink.snapshot() {
+		if ev.event.Event == "run.finish" {
+			_ = json.Unmarshal([]byte(ev.event.Data), &finish)
+		}
+	}
+	if finish["run_id"] != run.ID || finish["job_id"] != "deploy" || finish["status"] != "failed" {
+		t.Fatalf("expected a full run.finish event, got %+v", finish)
+	}
+	if _, err := os.Stat(filepath.Join(paths.RunDir(run.ID), "receipt.json")); err != nil {
+		t.Fatalf("expected a receipt for the failed run: %v", err)
+	}
+}
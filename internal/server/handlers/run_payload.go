@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/flowd-org/flowd/internal/server/response"
 	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/types"
 )
 
 type RunPayload struct {
@@ -20,6 +25,26 @@ type RunPayload struct {
 	Runtime         string         `json:"runtime,omitempty"`
 	SecurityProfile string         `json:"security_profile,omitempty"`
 	Provenance      map[string]any `json:"provenance,omitempty"`
+	// Approval is set for runs of jobs with an approval policy.
+	Approval *types.RunApproval `json:"approval,omitempty"`
+	// Archived marks runs hydrated from cold storage.
+	Archived bool `json:"archived,omitempty"`
+	// Shutdown tells whether a canceled run's steps exited within their
+	// grace period ("graceful") or had to be killed ("forced").
+	Shutdown string `json:"shutdown,omitempty"`
+	// StartAt is when a delayed run is dispatched; it waits in the
+	// scheduled status until then.
+	StartAt *time.Time `json:"start_at,omitempty"`
+	// QueuePosition is the run's 1-based place in its job's queue while it
+	// waits for a concurrency slot.
+	QueuePosition int `json:"queue_position,omitempty"`
+	// Runner describes the flowd build and host executing the run.
+	Runner *types.RunnerInfo `json:"runner,omitempty"`
+	// Labels echo the labels supplied when the run was created.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Warnings lists arg rules marked severity: warning that the args broke
+	// and deprecated args that were supplied.
+	Warnings []types.ArgWarning `json:"warnings,omitempty"`
 }
 
 func newRunPayload(id, jobID, status string, startedAt time.Time) RunPayload {
@@ -33,19 +58,31 @@ func newRunPayload(id, jobID, status string, startedAt time.Time) RunPayload {
 
 func payloadFromStore(run runstore.Run) RunPayload {
 	return RunPayload{
-		ID:         run.ID,
-		JobID:      run.JobID,
-		Status:     run.Status,
-		StartedAt:  run.StartedAt,
-		FinishedAt: run.FinishedAt,
-		Result:     run.Result,
-		Executor:   run.Executor,
-		Runtime:    run.Runtime,
-		Provenance: run.Provenance,
+		ID:            run.ID,
+		JobID:         run.JobID,
+		Status:        run.Status,
+		StartedAt:     run.StartedAt,
+		FinishedAt:    run.FinishedAt,
+		Result:        run.Result,
+		Executor:      run.Executor,
+		Runtime:       run.Runtime,
+		Provenance:    run.Provenance,
+		Approval:      run.Approval,
+		Shutdown:      run.Shutdown,
+		QueuePosition: run.QueuePosition,
+		StartAt:       run.StartAt,
+		Runner:        run.Runner,
+		Labels:        run.Labels,
+		Warnings:      run.Warnings,
 	}
 }
 
 func writeRunPayload(w http.ResponseWriter, payload RunPayload, status int) {
+	writeRunJSON(w, payload, status)
+}
+
+// writeRunJSON writes a run payload or a sparse fieldset of one.
+func writeRunJSON(w http.ResponseWriter, payload any, status int) {
 	data, err := json.Marshal(payload)
 	if err != nil {
 		response.Write(w, response.New(http.StatusInternalServerError, "encode run failed", response.WithDetail(err.Error())))
@@ -55,3 +92,108 @@ func writeRunPayload(w http.ResponseWriter, payload RunPayload, status int) {
 	w.WriteHeader(status)
 	_, _ = w.Write(data)
 }
+
+// writeRunResource writes a single run representation with validators so
+// pollers can revalidate cheaply. The ETag hashes the encoded body, so it
+// also tracks the selected fieldset; a matching If-None-Match yields 304.
+func writeRunResource(w http.ResponseWriter, r *http.Request, run runstore.Run, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		response.Write(w, response.New(http.StatusInternalServerError, "encode run failed", response.WithDetail(err.Error())))
+		return
+	}
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	lastModified := run.StartedAt
+	if run.FinishedAt != nil {
+		lastModified = *run.FinishedAt
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Add("Vary", "Authorization")
+	if match := r.Header.Get("If-None-Match"); match != "" && etagMatches(match, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// runFields lists the JSON field names of RunPayload in declaration order.
+var runFields = func() []string {
+	t := reflect.TypeOf(RunPayload{})
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		names = append(names, name)
+	}
+	return names
+}()
+
+// runFieldSet is a sparse fieldset selected with ?fields=. A nil set keeps
+// every field.
+type runFieldSet map[string]bool
+
+// parseRunFields reads the comma-separated fields query parameter and rejects
+// names that are not run payload fields.
+func parseRunFields(r *http.Request) (runFieldSet, *response.Problem) {
+	raw := strings.TrimSpace(r.URL.Query().Get("fields"))
+	if raw == "" {
+		return nil, nil
+	}
+	known := make(map[string]bool, len(runFields))
+	for _, name := range runFields {
+		known[name] = true
+	}
+	set := runFieldSet{}
+	var unknown []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			unknown = append(unknown, name)
+			continue
+		}
+		set[name] = true
+	}
+	if len(unknown) > 0 {
+		prob := response.New(http.StatusBadRequest, "invalid fields",
+			response.WithExtension("code", "fields.unknown"),
+			response.WithExtension("allowed", runFields),
+			response.WithDetail("unknown run field(s): "+strings.Join(unknown, ", ")))
+		return nil, &prob
+	}
+	if len(set) == 0 {
+		return nil, nil
+	}
+	return set, nil
+}
+
+// apply returns payload restricted to the selected fields. Empty optional
+// fields stay omitted, as in the full payload.
+func (f runFieldSet) apply(payload RunPayload) any {
+	if f == nil {
+		return payload
+	}
+	out := make(map[string]any, len(f))
+	v := reflect.ValueOf(payload)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, opts, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if !f[name] {
+			continue
+		}
+		field := v.Field(i)
+		if opts == "omitempty" && field.IsZero() {
+			continue
+		}
+		out[name] = field.Interface()
+	}
+	return out
+}
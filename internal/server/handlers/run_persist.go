@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/coredb"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+const interruptedRunError = "server restarted before the run finished"
+
+// OpenRunStore returns a run store persisted in db so runs, their provenance
+// and statuses survive restarts. Runs left executing by a previous process
+// are marked failed; scheduled runs are kept for
+// RunsHandler.ResumeScheduledRuns. A nil db, or one that cannot be read,
+// yields an in-memory store.
+func OpenRunStore(ctx context.Context, db *coredb.DB, now func() time.Time) *runstore.Store {
+	if db == nil {
+		return runstore.New()
+	}
+	logger := slog.Default()
+	store, err := runstore.Open(ctx, coredb.NewRunRecordStore(db), func(err error) {
+		logger.Warn("runs.persist.failed", slog.String("error", err.Error()))
+	})
+	if err != nil {
+		logger.Error("runs.load.failed", slog.String("error", err.Error()))
+		return runstore.New()
+	}
+	if n := RecoverInterruptedRuns(store, now); n > 0 {
+		logger.Warn("runs.interrupted", slog.Int("count", n))
+	}
+	return store
+}
+
+// RecoverInterruptedRuns fails every run in store that has not reached a
+// terminal status, since no executor survives a restart to finish it.
+// Scheduled runs have not started yet and are left alone. It returns the
+// number of runs updated.
+func RecoverInterruptedRuns(store *runstore.Store, now func() time.Time) int {
+	if now == nil {
+		now = time.Now
+	}
+	recovered := 0
+	for _, run := range store.List() {
+		if isTerminalStatus(run.Status) || run.Status == scheduledRunStatus {
+			continue
+		}
+		failRun(store, run, now(), interruptedRunError)
+		recovered++
+	}
+	return recovered
+}
+
+// failRun records run as failed with reason, keeping its earlier result
+// fields and the status it was in.
+func failRun(store *runstore.Store, run runstore.Run, now time.Time, reason string) {
+	finished := now.UTC()
+	result := make(map[string]any, len(run.Result)+1)
+	for k, v := range run.Result {
+		result[k] = v
+	}
+	result["error"] = reason
+	if prev := strings.ToLower(run.Status); prev != "" {
+		result["interrupted_status"] = prev
+	}
+	run.Status = "failed"
+	run.FinishedAt = &finished
+	run.Result = result
+	run.Request = nil
+	store.Update(run)
+}
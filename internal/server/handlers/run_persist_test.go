@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/coredb"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+func TestOpenRunStoreFailsInterruptedRuns(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	started := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	restart := started.Add(time.Hour)
+
+	db, err := coredb.Open(ctx, coredb.Options{DataDir: dir})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	store := OpenRunStore(ctx, db, nil)
+	store.Create(runstore.Run{ID: "done", JobID: "a", Status: "completed", StartedAt: started})
+	store.Create(runstore.Run{ID: "busy", JobID: "a", Status: "running", StartedAt: started,
+		Result: map[string]any{"steps": 2}})
+	_ = db.Close()
+
+	db, err = coredb.Open(ctx, coredb.Options{DataDir: dir})
+	if err != nil {
+		t.Fatalf("reopen db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	store = OpenRunStore(ctx, db, func() time.Time { return restart })
+
+	done, ok := store.Get("done")
+	if !ok || done.Status != "completed" || done.FinishedAt != nil {
+		t.Fatalf("expected completed run untouched, got %+v", done)
+	}
+	busy, ok := store.Get("busy")
+	if !ok || busy.Status != "failed" {
+		t.Fatalf("expected interrupted run to fail, got %+v", busy)
+	}
+	if busy.FinishedAt == nil || !busy.FinishedAt.Equal(restart) {
+		t.Fatalf("expected finished_at %v, got %v", restart, busy.FinishedAt)
+	}
+	if busy.Result["error"] != interruptedRunError || busy.Result["interrupted_status"] != "running" {
+		t.Fatalf("unexpected result %+v", busy.Result)
+	}
+	if busy.Result["steps"] == nil {
+		t.Fatalf("expected earlier result fields kept, got %+v", busy.Result)
+	}
+}
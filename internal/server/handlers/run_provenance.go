@@ -0,0 +1,246 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+const (
+	provenanceFormatFlowd  = "flowd"
+	provenanceFormatInToto = "intoto"
+
+	inTotoStatementType   = "https://in-toto.io/Statement/v1"
+	inTotoContentType     = "application/vnd.in-toto+json"
+	slsaProvenanceType    = "https://slsa.dev/provenance/v1"
+	flowdRunBuildType     = "https://flowd.dev/provenance/run/v1"
+	flowdServeBuilderID   = "https://flowd.dev/builder/serve"
+	runSubjectNamePrefix  = "flowd-run/"
+	provenanceContentType = "application/json"
+)
+
+// inTotoStatement is an in-toto Statement v1 carrying a SLSA v1 provenance
+// predicate.
+type inTotoStatement struct {
+	Type          string             `json:"_type"`
+	Subject       []inTotoSubject    `json:"subject"`
+	PredicateType string             `json:"predicateType"`
+	Predicate     slsaProvenanceBody `json:"predicate"`
+}
+
+type inTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type slsaProvenanceBody struct {
+	BuildDefinition slsaBuildDefinition `json:"buildDefinition"`
+	RunDetails      slsaRunDetails      `json:"runDetails"`
+}
+
+type slsaBuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   map[string]any       `json:"externalParameters"`
+	InternalParameters   map[string]any       `json:"internalParameters,omitempty"`
+	ResolvedDependencies []slsaResourceDigest `json:"resolvedDependencies,omitempty"`
+}
+
+type slsaResourceDigest struct {
+	Name   string            `json:"name,omitempty"`
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+type slsaRunDetails struct {
+	Builder  slsaBuilder  `json:"builder"`
+	Metadata slsaMetadata `json:"metadata"`
+}
+
+type slsaBuilder struct {
+	ID string `json:"id"`
+}
+
+type slsaMetadata struct {
+	InvocationID string     `json:"invocationId"`
+	StartedOn    time.Time  `json:"startedOn"`
+	FinishedOn   *time.Time `json:"finishedOn,omitempty"`
+}
+
+// NewRunProvenanceHandler serves GET /runs/{id}/provenance. The default
+// format returns the stored provenance map; format=intoto converts it into
+// an in-toto Statement for attestation stores.
+func NewRunProvenanceHandler(store *runstore.Store) http.Handler {
+	if store == nil {
+		store = runstore.New()
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+			return
+		}
+		runID := strings.Trim(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/runs/"), "/provenance"), "/")
+		if runID == "" || strings.Contains(runID, "/") {
+			response.Write(w, response.New(http.StatusNotFound, "run not found"))
+			return
+		}
+		format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+		if format == "" {
+			format = provenanceFormatFlowd
+		}
+		if format != provenanceFormatFlowd && format != provenanceFormatInToto {
+			response.Write(w, response.New(http.StatusBadRequest, "unsupported provenance format",
+				response.WithDetail("format must be flowd or intoto"),
+			))
+			return
+		}
+		run, ok := store.Get(runID)
+		if !ok {
+			response.Write(w, response.New(http.StatusNotFound, "run not found"))
+			return
+		}
+
+		var (
+			body        any
+			contentType = provenanceContentType
+		)
+		if format == provenanceFormatInToto {
+			body = runInTotoStatement(run)
+			contentType = inTotoContentType
+		} else {
+			prov := run.Provenance
+			if prov == nil {
+				prov = map[string]any{}
+			}
+			body = prov
+		}
+		data, err := json.Marshal(body)
+		if err != nil {
+			response.Write(w, response.New(http.StatusInternalServerError, "encode provenance failed", response.WithDetail(err.Error())))
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(data)
+	})
+}
+
+// runInTotoStatement describes the run as the statement subject. The first
+// subject digest covers the run record: id, job, status, timestamps and
+// result. Each artifact the run published follows as its own subject.
+func runInTotoStatement(run runstore.Run) inTotoStatement {
+	external := map[string]any{"job_id": run.JobID}
+	if args, ok := run.Result["resolved_args"]; ok && args != nil {
+		external["args"] = args
+	}
+	internal := map[string]any{}
+	if run.Executor != "" {
+		internal["executor"] = run.Executor
+	}
+	if run.Runtime != "" {
+		internal["runtime"] = run.Runtime
+	}
+	for _, key := range []string{"canonical_id", "canonical_path", "invoked_path", "alias"} {
+		if v, ok := run.Provenance[key]; ok {
+			internal[key] = v
+		}
+	}
+	subjects := []inTotoSubject{{
+		Name:   runSubjectNamePrefix + run.ID,
+		Digest: map[string]string{"sha256": runRecordDigest(run)},
+	}}
+	for _, a := range run.Artifacts {
+		subjects = append(subjects, inTotoSubject{
+			Name:   runSubjectNamePrefix + run.ID + "/artifacts/" + a.Name,
+			Digest: map[string]string{"sha256": a.SHA256},
+		})
+	}
+	return inTotoStatement{
+		Type:          inTotoStatementType,
+		Subject:       subjects,
+		PredicateType: slsaProvenanceType,
+		Predicate: slsaProvenanceBody{
+			BuildDefinition: slsaBuildDefinition{
+				BuildType:            flowdRunBuildType,
+				ExternalParameters:   external,
+				InternalParameters:   internal,
+				ResolvedDependencies: provenanceDependencies(run.Provenance),
+			},
+			RunDetails: slsaRunDetails{
+				Builder: slsaBuilder{ID: flowdServeBuilderID},
+				Metadata: slsaMetadata{
+					InvocationID: run.ID,
+					StartedOn:    run.StartedAt,
+					FinishedOn:   run.FinishedAt,
+				},
+			},
+		},
+	}
+}
+
+func runRecordDigest(run runstore.Run) string {
+	record := map[string]any{
+		"id":         run.ID,
+		"job_id":     run.JobID,
+		"status":     run.Status,
+		"started_at": run.StartedAt,
+		"result":     run.Result,
+	}
+	if run.FinishedAt != nil {
+		record["finished_at"] = run.FinishedAt
+	}
+	// encoding/json sorts map keys, so the encoding is canonical.
+	data, _ := json.Marshal(record)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// provenanceDependencies maps the job source recorded in the provenance to
+// SLSA resolved dependencies with whatever digests are known.
+func provenanceDependencies(prov map[string]any) []slsaResourceDigest {
+	src, ok := prov["source"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	dep := slsaResourceDigest{Name: stringField(src, "name")}
+	switch {
+	case stringField(src, "url") != "":
+		dep.URI = stringField(src, "url")
+	case stringField(src, "resolved_ref") != "":
+		dep.URI = stringField(src, "resolved_ref")
+	default:
+		dep.URI = stringField(src, "ref")
+	}
+	if ref := stringField(src, "ref"); ref != "" && dep.URI != "" && dep.URI != ref && stringField(src, "type") == "git" {
+		dep.URI += "@" + ref
+	}
+	digest := map[string]string{}
+	if raw := stringField(src, "digest"); raw != "" {
+		if algo, value, found := strings.Cut(raw, ":"); found {
+			digest[algo] = value
+		}
+	}
+	if commit := stringField(src, "resolved_commit"); commit != "" {
+		digest["gitCommit"] = commit
+	}
+	if len(digest) > 0 {
+		dep.Digest = digest
+	}
+	if dep.URI == "" && dep.Name == "" && dep.Digest == nil {
+		return nil
+	}
+	return []slsaResourceDigest{dep}
+}
+
+func stringField(m map[string]any, key string) string {
+	v, _ := m[key].(string)
+	return v
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+func newProvenanceStore() *runstore.Store {
+	store := runstore.New()
+	finished := time.Date(2025, 1, 2, 3, 5, 0, 0, time.UTC)
+	store.Create(runstore.Run{
+		ID:         "run-1",
+		JobID:      "demo",
+		Status:     "completed",
+		StartedAt:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		FinishedAt: &finished,
+		Executor:   "shell",
+		Result:     map[string]any{"resolved_args": map[string]any{"name": "Ada"}},
+		Provenance: map[string]any{
+			"canonical_id": "demo",
+			"source": map[string]any{
+				"name":            "tools",
+				"type":            "git",
+				"url":             "https://git.example/tools.git",
+				"ref":             "main",
+				"resolved_commit": "0123abcd",
+			},
+		},
+	})
+	return store
+}
+
+func TestRunProvenanceInToto(t *testing.T) {
+	h := NewRunProvenanceHandler(newProvenanceStore())
+	req := httptest.NewRequest(http.MethodGet, "/runs/run-1/provenance?format=intoto", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != inTotoContentType {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	var stmt inTotoStatement
+	if err := json.Unmarshal(rec.Body.Bytes(), &stmt); err != nil {
+		t.Fatalf("decode statement: %v", err)
+	}
+	if stmt.Type != inTotoStatementType || stmt.PredicateType != slsaProvenanceType {
+		t.Fatalf("unexpected statement types %q / %q", stmt.Type, stmt.PredicateType)
+	}
+	if len(stmt.Subject) != 1 || stmt.Subject[0].Name != "flowd-run/run-1" || len(stmt.Subject[0].Digest["sha256"]) != 64 {
+		t.Fatalf("unexpected subject %+v", stmt.Subject)
+	}
+	deps := stmt.Predicate.BuildDefinition.ResolvedDependencies
+	if len(deps) != 1 || deps[0].URI != "https://git.example/tools.git@main" || deps[0].Digest["gitCommit"] != "0123abcd" {
+		t.Fatalf("unexpected dependencies %+v", deps)
+	}
+	if stmt.Predicate.RunDetails.Metadata.InvocationID != "run-1" || stmt.Predicate.RunDetails.Metadata.FinishedOn == nil {
+		t.Fatalf("unexpected run details %+v", stmt.Predicate.RunDetails)
+	}
+
+	// The subject digest is stable across requests.
+	again := httptest.NewRecorder()
+	h.ServeHTTP(again, httptest.NewRequest(http.MethodGet, "/runs/run-1/provenance?format=intoto", nil))
+	var second inTotoStatement
+	_ = json.Unmarshal(again.Body.Bytes(), &second)
+	if second.Subject[0].Digest["sha256"] != stmt.Subject[0].Digest["sha256"] {
+		t.Fatal("expected deterministic subject digest")
+	}
+}
+
+func TestRunProvenanceDefaultAndErrors(t *testing.T) {
+	h := NewRunProvenanceHandler(newProvenanceStore())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs/run-1/provenance", nil))
+	var prov map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &prov); err != nil || prov["canonical_id"] != "demo" {
+		t.Fatalf("expected stored provenance, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	bad := httptest.NewRecorder()
+	h.ServeHTTP(bad, httptest.NewRequest(http.MethodGet, "/runs/run-1/provenance?format=spdx", nil))
+	if bad.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown format, got %d", bad.Code)
+	}
+
+	missing := httptest.NewRecorder()
+	h.ServeHTTP(missing, httptest.NewRequest(http.MethodGet, "/runs/nope/provenance?format=intoto", nil))
+	if missing.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown run, got %d", missing.Code)
+	}
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"github.com/flowd-org/flowd/internal/server/metrics"
+)
+
+const runRegistryShards = 32
+
+// runRegistry tracks runs accepted for execution. It is sharded by run id so
+// concurrent submissions and cancellations of unrelated runs do not contend on
+// a single lock.
+type runRegistry struct {
+	shards [runRegistryShards]runRegistryShard
+}
+
+type runRegistryShard struct {
+	mu   sync.Mutex
+	runs map[string]*runExecutionContext
+}
+
+func newRunRegistry() *runRegistry {
+	r := &runRegistry{}
+	for i := range r.shards {
+		r.shards[i].runs = make(map[string]*runExecutionContext)
+	}
+	return r
+}
+
+func (r *runRegistry) shard(runID string) *runRegistryShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(runID))
+	return &r.shards[h.Sum32()%runRegistryShards]
+}
+
+// Register records execCtx under runID. Callers must register a run before it
+// becomes visible in the run store so that a concurrent Cancel always finds it.
+func (r *runRegistry) Register(runID string, execCtx *runExecutionContext) {
+	s := r.shard(runID)
+	s.mu.Lock()
+	_, existed := s.runs[runID]
+	s.runs[runID] = execCtx
+	s.mu.Unlock()
+	if !existed {
+		metrics.Default.RecordActiveRunsDelta(1)
+	}
+}
+
+// Lookup returns the execution context registered for runID.
+func (r *runRegistry) Lookup(runID string) (*runExecutionContext, bool) {
+	s := r.shard(runID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	execCtx, ok := s.runs[runID]
+	return execCtx, ok
+}
+
+// Cancel cancels the context of the run registered under runID. It reports
+// whether a registered run was found.
+func (r *runRegistry) Cancel(runID string) bool {
+	s := r.shard(runID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	execCtx, ok := s.runs[runID]
+	if !ok {
+		return false
+	}
+	if execCtx != nil && execCtx.cancel != nil {
+		execCtx.cancel()
+	}
+	return true
+}
+
+// Remove drops runID from the registry.
+func (r *runRegistry) Remove(runID string) {
+	s := r.shard(runID)
+	s.mu.Lock()
+	_, existed := s.runs[runID]
+	delete(s.runs, runID)
+	s.mu.Unlock()
+	if existed {
+		metrics.Default.RecordActiveRunsDelta(-1)
+	}
+}
+
+// Count returns the number of registered runs.
+func (r *runRegistry) Count() int {
+	total := 0
+	for i := range r.shards {
+		s := &r.shards[i]
+		s.mu.Lock()
+		total += len(s.runs)
+		s.mu.Unlock()
+	}
+	return total
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/server/metrics"
+)
+
+func TestRunRegistryCancelBeforeStart(t *testing.T) {
+	reg := newRunRegistry()
+	before := metrics.Default.ActiveRuns()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reg.Register("run-1", &runExecutionContext{ctx: ctx, cancel: cancel})
+	if got := metrics.Default.ActiveRuns(); got != before+1 {
+		t.Fatalf("expected active runs %d, got %d", before+1, got)
+	}
+
+	if !reg.Cancel("run-1") {
+		t.Fatalf("expected registered run to be canceled")
+	}
+	if ctx.Err() == nil {
+		t.Fatalf("expected context to be canceled")
+	}
+	if reg.Cancel("missing") {
+		t.Fatalf("expected cancel of unknown run to report false")
+	}
+
+	h := &RunsHandler{running: reg}
+	h.executeRun(&runExecutionContext{ctx: ctx, cancel: cancel, runPayload: RunPayload{ID: "run-1"}})
+	if _, ok := reg.Lookup("run-1"); ok {
+		t.Fatalf("expected canceled run to be removed")
+	}
+	if got := metrics.Default.ActiveRuns(); got != before {
+		t.Fatalf("expected active runs %d, got %d", before, got)
+	}
+}
+
+func TestRunRegistryConcurrentAccess(t *testing.T) {
+	reg := newRunRegistry()
+	const n = 200
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("run-%d", i)
+			ctx, cancel := context.WithCancel(context.Background())
+			reg.Register(id, &runExecutionContext{ctx: ctx, cancel: cancel})
+			reg.Cancel(id)
+			if ctx.Err() == nil {
+				t.Errorf("run %s not canceled", id)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if got := reg.Count(); got != n {
+		t.Fatalf("expected %d registered runs, got %d", n, got)
+	}
+	for i := 0; i < n; i++ {
+		reg.Remove(fmt.Sprintf("run-%d", i))
+	}
+	if got := reg.Count(); got != 0 {
+		t.Fatalf("expected empty registry, got %d", got)
+	}
+}
@@ -0,0 +1,269 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/secrets"
+	"github.com/flowd-org/flowd/internal/server/reporter"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/server/sourcestore"
+	"github.com/flowd-org/flowd/internal/server/sse"
+)
+
+const reportTimeout = 30 * time.Second
+
+// RunReporterConfig configures status reporting for webhook-triggered runs.
+type RunReporterConfig struct {
+	Runs    *runstore.Store
+	Sources *sourcestore.Store
+	Secrets secrets.Provider
+	// PublicURL is the externally reachable base URL used for run links.
+	PublicURL string
+	// NewReporter builds the reporter for a source; defaults to the
+	// built-in forge reporters.
+	NewReporter func(ctx context.Context, cfg sourcestore.Reporter, provider secrets.Provider) (reporter.Reporter, error)
+}
+
+// RunReporter follows run events and reports runs whose provenance names a
+// webhook trigger to the reporter configured on the triggering source. It is
+// an EventSink so it can be teed off the server's event stream.
+type RunReporter struct {
+	cfg RunReporterConfig
+
+	mu        sync.Mutex
+	runs      map[string]*reportedRun
+	ignored   map[string]struct{}
+	reporters map[string]cachedReporter
+}
+
+type cachedReporter struct {
+	cfg sourcestore.Reporter
+	rep reporter.Reporter
+}
+
+// reportedRun accumulates a run's status. Updates are delivered in order by a
+// per-run worker; sent tracks the furthest state delivered so a late queued
+// report never follows in_progress.
+type reportedRun struct {
+	status reporter.Status
+	sent   int
+	queue  chan reporter.Status
+}
+
+// NewRunReporter constructs a reporter tracker.
+func NewRunReporter(cfg RunReporterConfig) *RunReporter {
+	if cfg.Secrets == nil {
+		cfg.Secrets = secrets.Default{}
+	}
+	if cfg.NewReporter == nil {
+		cfg.NewReporter = newSourceReporter
+	}
+	return &RunReporter{
+		cfg:       cfg,
+		runs:      make(map[string]*reportedRun),
+		ignored:   make(map[string]struct{}),
+		reporters: make(map[string]cachedReporter),
+	}
+}
+
+// Queued reports a run that has been accepted but not yet started.
+func (r *RunReporter) Queued(run RunPayload) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tracked := r.lookup(run.ID)
+	if tracked == nil {
+		return
+	}
+	r.send(run.ID, tracked, reporter.StateQueued)
+}
+
+// Publish implements EventSink.
+func (r *RunReporter) Publish(runID string, ev sse.Event) {
+	switch ev.Event {
+	case "run.start", "step.finish", "run.finish":
+	default:
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tracked := r.lookup(runID)
+	if tracked == nil {
+		if ev.Event == "run.finish" {
+			delete(r.ignored, runID)
+		}
+		return
+	}
+	var payload struct {
+		Step       string     `json:"step"`
+		Status     string     `json:"status"`
+		ExitCode   int        `json:"exit_code"`
+		Error      string     `json:"error"`
+		StartedAt  time.Time  `json:"started_at"`
+		FinishedAt *time.Time `json:"finished_at"`
+	}
+	_ = json.Unmarshal([]byte(ev.Data), &payload)
+	switch ev.Event {
+	case "run.start":
+		if !payload.StartedAt.IsZero() {
+			tracked.status.StartedAt = payload.StartedAt
+		}
+		r.send(runID, tracked, reporter.StateInProgress)
+	case "step.finish":
+		tracked.status.Steps = append(tracked.status.Steps, reporter.Step{
+			Name:     payload.Step,
+			Status:   payload.Status,
+			ExitCode: payload.ExitCode,
+			Error:    payload.Error,
+		})
+	case "run.finish":
+		tracked.status.Conclusion = reporter.Conclusion(payload.Status)
+		tracked.status.Error = payload.Error
+		if payload.FinishedAt != nil {
+			tracked.status.CompletedAt = *payload.FinishedAt
+		}
+		r.send(runID, tracked, reporter.StateCompleted)
+	}
+}
+
+// lookup returns the tracked run, starting to track it when its provenance
+// names a webhook trigger on a source with a reporter. Callers hold r.mu.
+func (r *RunReporter) lookup(runID string) *reportedRun {
+	if tracked, ok := r.runs[runID]; ok {
+		return tracked
+	}
+	if _, ok := r.ignored[runID]; ok || r.cfg.Runs == nil || r.cfg.Sources == nil {
+		return nil
+	}
+	run, ok := r.cfg.Runs.Get(runID)
+	if !ok {
+		return nil
+	}
+	trigger, _ := run.Provenance["trigger"].(map[string]any)
+	if kind, _ := trigger["type"].(string); kind != "webhook" {
+		r.ignored[runID] = struct{}{}
+		return nil
+	}
+	sourceName, _ := trigger["source"].(string)
+	src, ok := r.cfg.Sources.Get(sourceName)
+	if !ok || src.Webhook == nil || src.Webhook.Reporter == nil {
+		r.ignored[runID] = struct{}{}
+		return nil
+	}
+	rep, err := r.reporterFor(sourceName, *src.Webhook.Reporter)
+	if err != nil {
+		slog.Default().Warn("run.report.unavailable",
+			slog.String("run_id", runID),
+			slog.String("source", sourceName),
+			slog.String("error", err.Error()))
+		r.ignored[runID] = struct{}{}
+		return nil
+	}
+	status := reporter.Status{
+		RunID:     run.ID,
+		JobID:     run.JobID,
+		StartedAt: run.StartedAt,
+	}
+	status.Repo, _ = trigger["repository"].(string)
+	status.CommitSHA, _ = trigger["commit"].(string)
+	if base := strings.TrimRight(r.cfg.PublicURL, "/"); base != "" {
+		status.DetailsURL = base + "/runs/" + run.ID
+		status.LogsURL = status.DetailsURL + "/events"
+	}
+	tracked := &reportedRun{status: status, sent: -1, queue: make(chan reporter.Status, 3)}
+	r.runs[runID] = tracked
+	go deliverReports(runID, rep, tracked.queue)
+	return tracked
+}
+
+// send enqueues the run's current status in state unless a later state was
+// already sent. Callers hold r.mu.
+func (r *RunReporter) send(runID string, tracked *reportedRun, state string) {
+	order := reportStateOrder(state)
+	if order <= tracked.sent {
+		return
+	}
+	tracked.sent = order
+	st := tracked.status
+	st.State = state
+	st.Steps = append([]reporter.Step(nil), tracked.status.Steps...)
+	tracked.queue <- st
+	if state == reporter.StateCompleted {
+		close(tracked.queue)
+		delete(r.runs, runID)
+	}
+}
+
+func (r *RunReporter) reporterFor(source string, cfg sourcestore.Reporter) (reporter.Reporter, error) {
+	if cached, ok := r.reporters[source]; ok && cached.cfg == cfg {
+		return cached.rep, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+	defer cancel()
+	rep, err := r.cfg.NewReporter(ctx, cfg, r.cfg.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	r.reporters[source] = cachedReporter{cfg: cfg, rep: rep}
+	return rep, nil
+}
+
+func deliverReports(runID string, rep reporter.Reporter, queue <-chan reporter.Status) {
+	for st := range queue {
+		ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+		if err := rep.Report(ctx, st); err != nil {
+			slog.Default().Warn("run.report.failed",
+				slog.String("run_id", runID),
+				slog.String("state", st.State),
+				slog.String("error", err.Error()))
+		}
+		cancel()
+	}
+}
+
+func reportStateOrder(state string) int {
+	switch state {
+	case reporter.StateQueued:
+		return 0
+	case reporter.StateInProgress:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// newSourceReporter builds the reporter named by cfg.Type.
+func newSourceReporter(ctx context.Context, cfg sourcestore.Reporter, provider secrets.Provider) (reporter.Reporter, error) {
+	switch cfg.Type {
+	case "github":
+		key, err := provider.Resolve(ctx, cfg.PrivateKeyRef)
+		if err != nil {
+			return nil, fmt.Errorf("resolve github app private key: %w", err)
+		}
+		return reporter.NewGitHubChecks(reporter.GitHubConfig{
+			APIURL:         cfg.APIURL,
+			AppID:          cfg.AppID,
+			InstallationID: cfg.InstallationID,
+			PrivateKey:     key,
+		})
+	case "gitlab", "gitea":
+		token, err := provider.Resolve(ctx, cfg.TokenRef)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s token: %w", cfg.Type, err)
+		}
+		statusCfg := reporter.CommitStatusConfig{APIURL: cfg.APIURL, Token: token}
+		if cfg.Type == "gitlab" {
+			return reporter.NewGitLabStatus(statusCfg)
+		}
+		return reporter.NewGiteaStatus(statusCfg)
+	default:
+		return nil, fmt.Errorf("unsupported reporter type %q", cfg.Type)
+	}
+}
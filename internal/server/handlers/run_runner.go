@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package handlers
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/executor/container"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// runnerProbe describes this flowd process for the runner block of run
+// payloads and receipts. Container runtime versions are looked up once per
+// runtime; a failed lookup leaves the version empty rather than failing runs.
+type runnerProbe struct {
+	version  string
+	hostname string
+
+	mu       sync.Mutex
+	runtimes map[container.Runtime]string
+}
+
+func newRunnerProbe(version string) *runnerProbe {
+	if version == "" {
+		version = "dev"
+	}
+	hostname, _ := os.Hostname()
+	return &runnerProbe{
+		version:  version,
+		hostname: hostname,
+		runtimes: make(map[container.Runtime]string),
+	}
+}
+
+// info returns the runner block for a run using rt, which is empty for proc runs.
+func (p *runnerProbe) info(rt container.Runtime) *types.RunnerInfo {
+	info := &types.RunnerInfo{
+		Version:  p.version,
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		Hostname: p.hostname,
+	}
+	if rt != "" {
+		info.ContainerRuntime = string(rt)
+		info.ContainerRuntimeVersion = p.runtimeVersion(rt)
+	}
+	return info
+}
+
+func (p *runnerProbe) runtimeVersion(rt container.Runtime) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if v, ok := p.runtimes[rt]; ok {
+		return v
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	v, _ := container.RuntimeVersion(ctx, rt)
+	p.runtimes[rt] = v
+	return v
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/paths"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+func TestRunRecordsRunnerInPayloadAndReceipt(t *testing.T) {
+	root := t.TempDir()
+	writeHashedJob(t, root, "runner")
+	store := runstore.New()
+	sink := &recordingSink{}
+	h := NewRunsHandler(RunsConfig{Root: root, Store: store, Events: sink, Version: "1.2.3"})
+
+	rec := postRun(t, h, `{"job_id":"runner"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var payload RunPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode run: %v", err)
+	}
+	runner := payload.Runner
+	if runner == nil || runner.Version != "1.2.3" || runner.OS != runtime.GOOS || runner.Arch != runtime.GOARCH {
+		t.Fatalf("unexpected runner %+v", runner)
+	}
+	if runner.ContainerRuntime != "" || runner.ContainerRuntimeVersion != "" {
+		t.Fatalf("proc run should not report a container runtime, got %+v", runner)
+	}
+
+	waitFor(func() bool { return sink.countBy("run.finish") >= 1 }, 2*time.Second, t)
+	runDir := paths.RunDir(payload.ID)
+	waitFor(func() bool {
+		_, err := os.Stat(filepath.Join(runDir, "receipt.json"))
+		return err == nil
+	}, 2*time.Second, t)
+	var receipt types.RunReceipt
+	readJSONFile(t, filepath.Join(runDir, "receipt.json"), &receipt)
+	if receipt.Runner == nil || *receipt.Runner != *runner {
+		t.Fatalf("expected receipt runner %+v, got %+v", runner, receipt.Runner)
+	}
+}
@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/coredb"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+// TimelineSpan is one interval on a run's timeline. OffsetMS is measured from
+// the time the run was accepted so spans can be drawn on a shared axis.
+type TimelineSpan struct {
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	OffsetMS   int64      `json:"offset_ms"`
+	DurationMS *int64     `json:"duration_ms,omitempty"`
+}
+
+// TimelineStep is a step's execution span.
+type TimelineStep struct {
+	ID string `json:"id"`
+	TimelineSpan
+	Status   string `json:"status,omitempty"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// TimelineImagePull is a container image pull made before a step ran.
+type TimelineImagePull struct {
+	Step  string `json:"step,omitempty"`
+	Image string `json:"image"`
+	TimelineSpan
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// Layers and Bytes are the latest totals reported by pull progress.
+	Layers int   `json:"layers,omitempty"`
+	Bytes  int64 `json:"bytes,omitempty"`
+}
+
+// RunTimeline is the response body of GET /runs/{id}/timeline.
+type RunTimeline struct {
+	RunID      string     `json:"run_id"`
+	JobID      string     `json:"job_id"`
+	Status     string     `json:"status"`
+	AcceptedAt time.Time  `json:"accepted_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	DurationMS *int64     `json:"duration_ms,omitempty"`
+	// QueueWaitMS is the time between acceptance and execution start.
+	QueueWaitMS      *int64              `json:"queue_wait_ms,omitempty"`
+	PolicyEvaluation *TimelineSpan       `json:"policy_evaluation,omitempty"`
+	ImagePullMS      int64               `json:"image_pull_ms"`
+	ImagePulls       []TimelineImagePull `json:"image_pulls"`
+	Steps            []TimelineStep      `json:"steps"`
+}
+
+type runTimelineHandler struct {
+	store   *runstore.Store
+	journal *coredb.Journal
+}
+
+// NewRunTimelineHandler serves GET /runs/{id}/timeline from the run record and
+// its journaled events.
+func NewRunTimelineHandler(store *runstore.Store, journal *coredb.Journal) http.Handler {
+	if store == nil {
+		store = runstore.New()
+	}
+	return &runTimelineHandler{store: store, journal: journal}
+}
+
+func (h *runTimelineHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+	runID := strings.Trim(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/runs/"), "/timeline"), "/")
+	if runID == "" || strings.Contains(runID, "/") {
+		response.Write(w, response.New(http.StatusNotFound, "run not found"))
+		return
+	}
+	run, ok := h.store.Get(runID)
+	if !ok {
+		response.Write(w, response.New(http.StatusNotFound, "run not found", response.WithDetail(runID)))
+		return
+	}
+	timeline := newTimeline(run)
+	if h.journal != nil {
+		err := h.journal.ForEach(r.Context(), runID, 0, func(entry coredb.JournalEntry) error {
+			timeline.apply(entry)
+			return nil
+		})
+		if err != nil {
+			response.Write(w, response.New(http.StatusInternalServerError, "read run events failed", response.WithDetail(err.Error())))
+			return
+		}
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, timeline.finish(), http.StatusOK)
+}
+
+type timelineBuilder struct {
+	RunTimeline
+	steps map[string]int
+	pulls map[string]int
+}
+
+func newTimeline(run runstore.Run) *timelineBuilder {
+	b := &timelineBuilder{
+		RunTimeline: RunTimeline{
+			RunID:      run.ID,
+			JobID:      run.JobID,
+			Status:     run.Status,
+			AcceptedAt: run.StartedAt,
+			ImagePulls: []TimelineImagePull{},
+			Steps:      []TimelineStep{},
+		},
+		steps: make(map[string]int),
+		pulls: make(map[string]int),
+	}
+	if run.FinishedAt != nil {
+		finished := *run.FinishedAt
+		b.FinishedAt = &finished
+	}
+	return b
+}
+
+func (b *timelineBuilder) apply(entry coredb.JournalEntry) {
+	var payload struct {
+		Step       string     `json:"step"`
+		Image      string     `json:"image"`
+		Status     string     `json:"status"`
+		ExitCode   *int       `json:"exit_code"`
+		Error      string     `json:"error"`
+		StartedAt  *time.Time `json:"started_at"`
+		FinishedAt *time.Time `json:"finished_at"`
+		Layers     int        `json:"layers_total"`
+		Bytes      int64      `json:"bytes_total"`
+	}
+	_ = json.Unmarshal(entry.Payload, &payload)
+	ts := entry.Timestamp.UTC()
+	switch entry.EventType {
+	case "policy.evaluation":
+		if payload.StartedAt != nil && payload.FinishedAt != nil {
+			span := b.span(*payload.StartedAt)
+			b.close(&span, *payload.FinishedAt)
+			b.PolicyEvaluation = &span
+		}
+	case "run.start":
+		if b.StartedAt == nil {
+			b.StartedAt = &ts
+		}
+	case "run.finish":
+		b.FinishedAt = &ts
+	case "step.start":
+		b.steps[payload.Step] = len(b.Steps)
+		b.Steps = append(b.Steps, TimelineStep{ID: payload.Step, TimelineSpan: b.span(ts)})
+	case "step.finish":
+		idx, ok := b.steps[payload.Step]
+		if !ok {
+			return
+		}
+		step := &b.Steps[idx]
+		b.close(&step.TimelineSpan, ts)
+		step.Status, step.ExitCode, step.Error = payload.Status, payload.ExitCode, payload.Error
+	case "image.pull.start":
+		key := payload.Step + "\x00" + payload.Image
+		b.pulls[key] = len(b.ImagePulls)
+		b.ImagePulls = append(b.ImagePulls, TimelineImagePull{Step: payload.Step, Image: payload.Image, TimelineSpan: b.span(ts)})
+	case "image.pull.progress":
+		idx, ok := b.pulls[payload.Step+"\x00"+payload.Image]
+		if !ok {
+			return
+		}
+		pull := &b.ImagePulls[idx]
+		pull.Layers = max(pull.Layers, payload.Layers)
+		pull.Bytes = max(pull.Bytes, payload.Bytes)
+	case "image.pull.finish":
+		idx, ok := b.pulls[payload.Step+"\x00"+payload.Image]
+		if !ok {
+			return
+		}
+		pull := &b.ImagePulls[idx]
+		b.close(&pull.TimelineSpan, ts)
+		pull.Status, pull.Error = payload.Status, payload.Error
+		b.ImagePullMS += *pull.DurationMS
+	}
+}
+
+func (b *timelineBuilder) finish() RunTimeline {
+	if b.StartedAt != nil {
+		wait := b.StartedAt.Sub(b.AcceptedAt).Milliseconds()
+		b.QueueWaitMS = &wait
+	}
+	if b.FinishedAt != nil && isTerminalStatus(b.Status) {
+		total := b.FinishedAt.Sub(b.AcceptedAt).Milliseconds()
+		b.DurationMS = &total
+	} else if !isTerminalStatus(b.Status) {
+		b.FinishedAt = nil
+	}
+	return b.RunTimeline
+}
+
+func (b *timelineBuilder) span(start time.Time) TimelineSpan {
+	return TimelineSpan{StartedAt: start, OffsetMS: start.Sub(b.AcceptedAt).Milliseconds()}
+}
+
+func (b *timelineBuilder) close(span *TimelineSpan, finished time.Time) {
+	span.FinishedAt = &finished
+	d := finished.Sub(span.StartedAt).Milliseconds()
+	span.DurationMS = &d
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+func getTimeline(t *testing.T, h http.Handler, runID string) (int, RunTimeline) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs/"+runID+"/timeline", nil))
+	var timeline RunTimeline
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &timeline); err != nil {
+			t.Fatalf("decode timeline: %v", err)
+		}
+	}
+	return rec.Code, timeline
+}
+
+func TestRunTimelineFromExecutedRun(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "build", `
+version: v1
+job:
+  id: build
+  name: Build
+interpreter: bash
+`)
+	for name, script := range map[string]string{"000_fetch.sh": "true\n", "100_compile.sh": "exit 2\n"} {
+		if err := os.WriteFile(filepath.Join(root, "build", name), []byte(script), 0o755); err != nil {
+			t.Fatalf("write script: %v", err)
+		}
+	}
+	store := runstore.New()
+	journal := newTestJournal(t)
+	h := NewRunsHandler(RunsConfig{Root: root, Store: store, Events: NewJournalEventSink(journal, nil)})
+	rec := postRun(t, h, `{"job_id":"build"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	run := waitForTerminalRun(t, store, rec.Body.Bytes())
+
+	code, timeline := getTimeline(t, NewRunTimelineHandler(store, journal), run.ID)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if timeline.Status != "failed" || timeline.StartedAt == nil || timeline.QueueWaitMS == nil || *timeline.QueueWaitMS < 0 {
+		t.Fatalf("expected started run with queue wait, got %+v", timeline)
+	}
+	if timeline.DurationMS == nil || timeline.FinishedAt == nil {
+		t.Fatalf("expected finished run duration, got %+v", timeline)
+	}
+	if timeline.PolicyEvaluation == nil || timeline.PolicyEvaluation.DurationMS == nil {
+		t.Fatalf("expected policy evaluation span, got %+v", timeline.PolicyEvaluation)
+	}
+	if len(timeline.Steps) != 2 {
+		t.Fatalf("expected two steps, got %+v", timeline.Steps)
+	}
+	fetch, compile := timeline.Steps[0], timeline.Steps[1]
+	if fetch.ID != "000_fetch.sh" || fetch.Status != "completed" || compile.Status != "failed" || compile.ExitCode == nil || *compile.ExitCode != 2 {
+		t.Fatalf("unexpected steps %+v", timeline.Steps)
+	}
+	if compile.StartedAt.Before(*fetch.FinishedAt) || compile.OffsetMS < fetch.OffsetMS {
+		t.Fatalf("expected steps in order, got %+v", timeline.Steps)
+	}
+}
+
+func TestRunTimelineImagePulls(t *testing.T) {
+	store := runstore.New()
+	journal := newTestJournal(t)
+	accepted := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	store.Create(runstore.Run{ID: "run-1", JobID: "build", Status: "running", StartedAt: accepted})
+	for _, ev := range []struct {
+		offset time.Duration
+		kind   string
+		data   string
+	}{
+		{2 * time.Second, "run.start", `{}`},
+		{2 * time.Second, "step.start", `{"step":"100_main.sh"}`},
+		{2 * time.Second, "image.pull.start", `{"step":"100_main.sh","image":"alpine:3"}`},
+		{4 * time.Second, "image.pull.progress", `{"step":"100_main.sh","image":"alpine:3","layers_total":2,"layers_done":1}`},
+		{8 * time.Second, "image.pull.progress", `{"step":"100_main.sh","image":"alpine:3","layers_total":2,"layers_done":2,"bytes_done":3000000,"bytes_total":3000000}`},
+		{9 * time.Second, "image.pull.finish", `{"step":"100_main.sh","image":"alpine:3","status":"completed"}`},
+	} {
+		if _, err := journal.Append(context.Background(), "run-1", ev.kind, []byte(ev.data), accepted.Add(ev.offset)); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	code, timeline := getTimeline(t, NewRunTimelineHandler(store, journal), "run-1")
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if *timeline.QueueWaitMS != 2000 || timeline.ImagePullMS != 7000 || timeline.FinishedAt != nil || timeline.DurationMS != nil {
+		t.Fatalf("unexpected timeline %+v", timeline)
+	}
+	if len(timeline.ImagePulls) != 1 || timeline.ImagePulls[0].OffsetMS != 2000 || timeline.ImagePulls[0].Image != "alpine:3" ||
+		timeline.ImagePulls[0].Layers != 2 || timeline.ImagePulls[0].Bytes != 3000000 {
+		t.Fatalf("unexpected pulls %+v", timeline.ImagePulls)
+	}
+	if len(timeline.Steps) != 1 || timeline.Steps[0].FinishedAt != nil {
+		t.Fatalf("expected one running step, got %+v", timeline.Steps)
+	}
+
+	if code, _ := getTimeline(t, NewRunTimelineHandler(store, journal), "missing"); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown run, got %d", code)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -30,20 +31,24 @@ import (
 	"github.com/flowd-org/flowd/internal/paths"
 	"github.com/flowd-org/flowd/internal/policy"
 	"github.com/flowd-org/flowd/internal/policy/verify"
+	"github.com/flowd-org/flowd/internal/secrets"
+	"github.com/flowd-org/flowd/internal/server/faults"
+	"github.com/flowd-org/flowd/internal/server/metrics"
 	"github.com/flowd-org/flowd/internal/server/requestctx"
 	"github.com/flowd-org/flowd/internal/server/response"
 	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/server/settings"
 	"github.com/flowd-org/flowd/internal/server/sourcestore"
 	"github.com/flowd-org/flowd/internal/server/sse"
+	"github.com/flowd-org/flowd/internal/sortspec"
 	"github.com/flowd-org/flowd/internal/types"
 )
 
 const (
 	defaultRunStatus          = "queued"
+	awaitingApprovalStatus    = "awaiting_approval"
+	scheduledRunStatus        = "scheduled"
 	defaultIdempotencyTTL     = 10 * time.Minute
-	defaultRunsPage           = 1
-	defaultRunsPerPage        = 50
-	maxRunsPerPage            = 200
 	storageQuotaProblemType   = "https://flowd.dev/problems/storage-quota-exceeded"
 	storageQuotaProblemDetail = "Core storage quota exceeded; free up space or increase the configured quota before retrying."
 )
@@ -67,7 +72,7 @@ var detectContainerRuntime = container.DetectRuntime
 type RunsConfig struct {
 	Root           string
 	Discover       func(string) (indexer.Result, error)
-	LoadConfig     func(string) (*types.Config, error)
+	LoadConfig     func(dir, overlay string) (*types.Config, error)
 	Now            func() time.Time
 	IdempotencyTTL time.Duration
 	Store          *runstore.Store
@@ -79,12 +84,62 @@ type RunsConfig struct {
 	Verifier       verify.ImageVerifier
 	Runtime        container.Runtime
 	DB             *coredb.DB
+	MaxBatch       int
+	// DefaultPerPage and MaxPerPage bound per_page on GET /runs; zero uses
+	// 50 and 200.
+	DefaultPerPage int
+	MaxPerPage     int
+	Settings       *settings.Store
+	// Notifiers are told about every run that reaches a terminal status.
+	Notifiers []RunNotifier
+	// Version is the flowd version recorded in each run's runner block;
+	// empty records "dev".
+	Version string
+	// Webhooks POST signed run lifecycle and policy events to external
+	// endpoints.
+	Webhooks WebhookDeliveryConfig
+	// MaxParallelSteps bounds how many independent DAG steps of a run execute
+	// at once; zero runs them one at a time.
+	MaxParallelSteps int
+	// MaxLogLineBytes truncates longer step output lines in step.log events;
+	// zero uses 16 KiB and a negative value keeps lines whole.
+	MaxLogLineBytes int
+	// StripANSILogs removes ANSI escape sequences from the stdout and stderr
+	// files kept in the run directory; step.log events still carry them.
+	StripANSILogs bool
+	// Flakiness flags jobs whose recent runs alternate between success and
+	// failure and decides what happens to their new runs.
+	Flakiness FlakinessPolicy
+	// ArgSecrets resolves the from references of secret args when a run
+	// starts; nil resolves env: references from FLWD_SECRET_ variables only.
+	ArgSecrets secrets.Provider
+	// ArtifactLimits bounds the files a run publishes under artifacts/.
+	ArtifactLimits executor.ArtifactLimits
+	// Admission is an external validation webhook consulted with the
+	// candidate plan before each run is admitted.
+	Admission AdmissionWebhookConfig
+}
+
+// defaultMaxLogLineBytes is the step.log line limit when RunsConfig leaves
+// MaxLogLineBytes zero.
+const defaultMaxLogLineBytes = 16 << 10
+
+// RunNotifier announces finished runs, e.g. by email. job is the run's job
+// config and may be nil; runErr is the error the run failed with, if any.
+type RunNotifier interface {
+	RunFinished(job *types.Config, run runstore.Run, runErr error)
+}
+
+// RunStartNotifier is implemented by notifiers that are also told when a run
+// starts executing.
+type RunStartNotifier interface {
+	RunStarted(job *types.Config, run runstore.Run)
 }
 
 type RunsHandler struct {
 	root           string
 	discover       func(string) (indexer.Result, error)
-	loadConfig     func(string) (*types.Config, error)
+	loadConfig     func(dir, overlay string) (*types.Config, error)
 	now            func() time.Time
 	idempotency    idempotencyStore
 	idempotencyTTL time.Duration
@@ -96,7 +151,25 @@ type RunsHandler struct {
 	policy         *policy.Context
 	verifier       verify.ImageVerifier
 	runtime        container.Runtime
-	running        sync.Map // runID -> *runExecutionContext
+	running        *runRegistry
+	limiter        *runLimiter
+	maxBatch       int
+	pagination     Pagination
+	settings       *settings.Store
+	notifiers      []RunNotifier
+	runner         *runnerProbe
+	approvalMu     sync.Mutex
+	pending        map[string]*pendingRun
+	delayedMu      sync.Mutex
+	delayed        map[string]*delayedRun
+	webhooks       *WebhookDispatcher
+	maxParallel    int
+	maxLogLine     int
+	stripANSI      bool
+	flakiness      FlakinessPolicy
+	argSecrets     secrets.Provider
+	artifactLimits executor.ArtifactLimits
+	admission      *admissionWebhook
 }
 
 // NewRunsHandler returns an HTTP handler for POST /runs.
@@ -111,7 +184,7 @@ func NewRunsHandler(cfg RunsConfig) *RunsHandler {
 	}
 	loadCfg := cfg.LoadConfig
 	if loadCfg == nil {
-		loadCfg = configloader.LoadConfig
+		loadCfg = configloader.LoadConfigOverlay
 	}
 	nowFn := cfg.Now
 	if nowFn == nil {
@@ -124,7 +197,16 @@ func NewRunsHandler(cfg RunsConfig) *RunsHandler {
 
 	store := cfg.Store
 	if store == nil {
-		store = runstore.New()
+		store = OpenRunStore(context.Background(), cfg.DB, nowFn)
+	}
+
+	maxBatch := cfg.MaxBatch
+	if maxBatch <= 0 {
+		maxBatch = defaultMaxBatchRuns
+	}
+	maxLogLine := cfg.MaxLogLineBytes
+	if maxLogLine == 0 {
+		maxLogLine = defaultMaxLogLineBytes
 	}
 
 	var idemStore idempotencyStore
@@ -133,6 +215,18 @@ func NewRunsHandler(cfg RunsConfig) *RunsHandler {
 	} else {
 		idemStore = newMemoryIdempotencyCache(ttl)
 	}
+	idemStore = faultyIdempotencyStore{idemStore}
+
+	argSecrets := cfg.ArgSecrets
+	if argSecrets == nil {
+		argSecrets = secrets.Schemes{"env": secrets.Env{Prefix: secrets.JobEnvPrefix}}
+	}
+
+	eventSink := cfg.Events
+	webhooks := newWebhookDispatcher(cfg.Webhooks, store)
+	if webhooks != nil {
+		eventSink = webhookTeeSink(eventSink, webhooks)
+	}
 
 	return &RunsHandler{
 		root:           root,
@@ -142,16 +236,39 @@ func NewRunsHandler(cfg RunsConfig) *RunsHandler {
 		idempotency:    idemStore,
 		idempotencyTTL: ttl,
 		store:          store,
-		events:         cfg.Events,
+		events:         eventSink,
 		resolveSrc:     cfg.ResolveSource,
 		sources:        cfg.Sources,
 		profile:        cfg.Profile,
 		policy:         cfg.Policy,
 		verifier:       cfg.Verifier,
 		runtime:        cfg.Runtime,
+		running:        newRunRegistry(),
+		limiter:        newRunLimiter(store),
+		maxBatch:       maxBatch,
+		pagination:     Pagination{DefaultPerPage: cfg.DefaultPerPage, MaxPerPage: cfg.MaxPerPage}.normalized(),
+		settings:       cfg.Settings,
+		notifiers:      cfg.Notifiers,
+		runner:         newRunnerProbe(cfg.Version),
+		pending:        make(map[string]*pendingRun),
+		delayed:        make(map[string]*delayedRun),
+		webhooks:       webhooks,
+		maxParallel:    cfg.MaxParallelSteps,
+		maxLogLine:     maxLogLine,
+		stripANSI:      cfg.StripANSILogs,
+		flakiness:      cfg.Flakiness,
+		argSecrets:     argSecrets,
+		artifactLimits: cfg.ArtifactLimits,
+		admission:      newAdmissionWebhook(cfg.Admission),
 	}
 }
 
+// Webhooks returns the outbound webhook dispatcher, or nil when no webhook
+// endpoints are configured.
+func (h *RunsHandler) Webhooks() *WebhookDispatcher {
+	return h.webhooks
+}
+
 func (h *RunsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodPost:
@@ -174,41 +291,17 @@ func (h *RunsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	canonicalBody, err := canonicalizeJSON(rawBody)
-	if err != nil {
-		response.Write(w, response.New(http.StatusBadRequest, "invalid request body", response.WithDetail(err.Error())))
+	bodyHashHex, prob := requestBodyHash(r, rawBody)
+	if prob != nil {
+		response.Write(w, *prob)
 		return
 	}
-	bodyHash := sha256.Sum256(canonicalBody)
-	bodyHashHex := hex.EncodeToString(bodyHash[:])
-
-	headerHash := strings.TrimSpace(r.Header.Get("Idempotency-SHA256"))
-	if headerHash != "" {
-		if !sha256Pattern.MatchString(strings.ToLower(headerHash)) {
-			response.Write(w, response.New(http.StatusBadRequest, "invalid Idempotency-SHA256 header"))
-			return
-		}
-		if !strings.EqualFold(headerHash, bodyHashHex) {
-			response.Write(w, response.New(http.StatusConflict, "idempotency hash mismatch",
-				response.WithType("https://flowd.dev/problems/idempotency-key-conflict"),
-				response.WithDetail("request hash does not match stored hash"),
-				response.WithExtension("incoming_sha256", strings.ToLower(headerHash)),
-				response.WithExtension("computed_sha256", bodyHashHex),
-			))
-			return
-		}
-	}
 
 	ctx := r.Context()
-	logger := requestctx.Logger(ctx)
 	principal, _ := requestctx.Principal(ctx)
-	idemKey := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
-	if idemKey == "" {
-		response.Write(w, response.New(http.StatusBadRequest, "Idempotency-Key header required"))
-		return
-	}
-	if !idempotencyKeyPattern.MatchString(idemKey) {
-		response.Write(w, response.New(http.StatusBadRequest, "invalid Idempotency-Key header"))
+	idemKey, prob := requestIdempotencyKey(r)
+	if prob != nil {
+		response.Write(w, *prob)
 		return
 	}
 	scopedKey := scopedIdempotencyKey(principal, idemKey)
@@ -222,43 +315,194 @@ func (h *RunsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		}
 		if found {
 			if storedHash != bodyHashHex {
-				response.Write(w, response.New(http.StatusConflict, "idempotency key conflict",
-					response.WithType("https://flowd.dev/problems/idempotency-key-conflict"),
-					response.WithExtension("stored_sha256", storedHash),
-					response.WithExtension("incoming_sha256", bodyHashHex),
-				))
+				response.Write(w, idempotencyConflictProblem(storedHash, bodyHashHex))
 				return
 			}
 			w.Header().Set("Idempotent-Replay", "true")
+			recordIdempotencyReplay(ctx)
 			writeRunPayload(w, cached, status)
 			return
 		}
 	}
 
+	prep, prob := h.prepareRun(ctx, req)
+	if prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+	resp, prob := h.newPreparedRunPayload(prep, now)
+	if prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+
+	if h.idempotency != nil {
+		expiresAt := now.Add(h.idempotencyTTL)
+		if err := h.idempotency.Store(ctx, scopedKey, endpoint, bodyHashHex, resp, http.StatusCreated, expiresAt); err != nil {
+			h.releaseSlot(resp.ID)
+			response.Write(w, h.idempotencyStoreProblem(prep.ctx, err))
+			return
+		}
+	}
+
+	h.startRun(prep, resp)
+	writeRunPayload(w, resp, http.StatusCreated)
+}
+
+// requestBodyHash returns the hex SHA-256 of the canonicalized request body and
+// checks it against the optional Idempotency-SHA256 header.
+func requestBodyHash(r *http.Request, rawBody []byte) (string, *response.Problem) {
+	canonicalBody, err := canonicalizeJSON(rawBody)
+	if err != nil {
+		prob := response.New(http.StatusBadRequest, "invalid request body", response.WithDetail(err.Error()))
+		return "", &prob
+	}
+	bodyHash := sha256.Sum256(canonicalBody)
+	bodyHashHex := hex.EncodeToString(bodyHash[:])
+
+	headerHash := strings.TrimSpace(r.Header.Get("Idempotency-SHA256"))
+	if headerHash != "" {
+		if !sha256Pattern.MatchString(strings.ToLower(headerHash)) {
+			prob := response.New(http.StatusBadRequest, "invalid Idempotency-SHA256 header")
+			return "", &prob
+		}
+		if !strings.EqualFold(headerHash, bodyHashHex) {
+			prob := response.New(http.StatusConflict, "idempotency hash mismatch",
+				response.WithType("https://flowd.dev/problems/idempotency-key-conflict"),
+				response.WithDetail("request hash does not match stored hash"),
+				response.WithExtension("incoming_sha256", strings.ToLower(headerHash)),
+				response.WithExtension("computed_sha256", bodyHashHex),
+			)
+			return "", &prob
+		}
+	}
+	return bodyHashHex, nil
+}
+
+// requestIdempotencyKey returns the validated Idempotency-Key header.
+func requestIdempotencyKey(r *http.Request) (string, *response.Problem) {
+	idemKey := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+	if idemKey == "" {
+		prob := response.New(http.StatusBadRequest, "Idempotency-Key header required")
+		return "", &prob
+	}
+	if !idempotencyKeyPattern.MatchString(idemKey) {
+		prob := response.New(http.StatusBadRequest, "invalid Idempotency-Key header")
+		return "", &prob
+	}
+	return idemKey, nil
+}
+
+// recordIdempotencyReplay counts a request answered from the idempotency
+// store under the route template the metrics middleware put in ctx.
+func recordIdempotencyReplay(ctx context.Context) {
+	if route, ok := requestctx.Route(ctx); ok {
+		metrics.Default.RecordIdempotencyReplay(route)
+	}
+}
+
+func idempotencyConflictProblem(storedHash, incomingHash string) response.Problem {
+	return response.New(http.StatusConflict, "idempotency key conflict",
+		response.WithType("https://flowd.dev/problems/idempotency-key-conflict"),
+		response.WithExtension("stored_sha256", storedHash),
+		response.WithExtension("incoming_sha256", incomingHash),
+	)
+}
+
+func (h *RunsHandler) idempotencyStoreProblem(ctx context.Context, err error) response.Problem {
+	if logger := requestctx.Logger(ctx); logger != nil {
+		logger.Error("idempotency store failed", slog.String("error", err.Error()))
+	}
+	if coredb.IsQuotaExceeded(err) {
+		return storageQuotaExceededProblem()
+	}
+	return response.New(http.StatusInternalServerError, "idempotency store failed", response.WithDetail(err.Error()))
+}
+
+// preparedRun is a run request that passed job resolution, argument binding and
+// policy evaluation but has not been assigned an id or started.
+type preparedRun struct {
+	ctx           context.Context
+	requestedID   string
+	effectiveID   string
+	aliasUsed     *indexer.AliasInfo
+	execScriptDir string
+	config        *types.Config
+	spec          *types.ArgSpec
+	binding       *engine.Binding
+	executor      string
+	runtime       container.Runtime
+	provenance    map[string]any
+	profile       string
+	image         string
+	plan          types.Plan
+	decisions     []policyDecision
+	policyEval    timeSpan
+	labels        map[string]string
+	sandboxed     bool
+	// minRetries forces step retries on runs of flaky jobs.
+	minRetries int
+	// startAt delays the run until the given time.
+	startAt *time.Time
+	// request is the request the run was prepared from.
+	request runRequest
+}
+
+// timeSpan brackets a phase of run admission or execution.
+type timeSpan struct {
+	start, end time.Time
+}
+
+// prepareRun resolves and validates req without side effects on the run store,
+// so callers can validate several requests before starting any of them.
+func (h *RunsHandler) prepareRun(ctx context.Context, req runRequest) (*preparedRun, *response.Problem) {
+	fail := func(p response.Problem) (*preparedRun, *response.Problem) {
+		return nil, &p
+	}
+
+	if err := validateRunLabels(req.Labels); err != nil {
+		return fail(response.New(http.StatusBadRequest, "invalid labels", response.WithDetail(err.Error())))
+	}
+	if req.StartAt != nil && !req.StartAt.After(h.now()) {
+		return fail(response.New(http.StatusBadRequest, "invalid start_at",
+			response.WithExtension("code", "run.start_at_past"),
+			response.WithDetail("start_at must be in the future")))
+	}
+
 	runRoot := h.root
 	if runRoot == "" {
 		runRoot = "scripts"
 	}
 
+	var custody *sourceCustody
+	var sourceTrust string
 	if req.Source != nil && req.Source.Name != "" {
 		if h.sources != nil {
 			src, ok := h.sources.Get(req.Source.Name)
 			if !ok {
-				response.Write(w, response.New(http.StatusNotFound, "source not found", response.WithDetail(req.Source.Name)))
-				return
+				return fail(response.New(http.StatusNotFound, "source not found", response.WithDetail(req.Source.Name)))
+			}
+			if src.Quarantine != nil {
+				return fail(sourceQuarantinedProblem(src))
+			}
+			sourceTrust = src.EffectiveTrustLevel()
+			if sourceTrust == sourcestore.TrustUntrusted {
+				return fail(sourceTrustProblem(src.Name, sourceTrust, fmt.Sprintf("source %s is untrusted; its jobs can be planned but not run", src.Name)))
 			}
 			if src.LocalPath == "" {
-				response.Write(w, response.New(http.StatusBadRequest, "source not materialized", response.WithDetail("source "+req.Source.Name+" has no local checkout")))
-				return
+				return fail(response.New(http.StatusBadRequest, "source not materialized", response.WithDetail("source "+req.Source.Name+" has no local checkout")))
 			}
 			runRoot = src.LocalPath
+			if src.Type == "git" || src.Type == "oci" {
+				inspected := inspectSourceCustody(ctx, src, h.now())
+				custody = &inspected
+			}
 		}
 	}
 
 	result, err := h.discover(runRoot)
 	if err != nil {
-		response.Write(w, response.New(http.StatusInternalServerError, "job discovery failed", response.WithDetail(err.Error())))
-		return
+		return fail(response.New(http.StatusInternalServerError, "job discovery failed", response.WithDetail(err.Error())))
 	}
 
 	jobMap := make(map[string]indexer.JobInfo, len(result.Jobs))
@@ -279,15 +523,13 @@ func (h *RunsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		return false
 	}
 
-	resolveAlias := func() bool {
+	resolveAlias := func() *response.Problem {
 		aliasInfo, hasAlias, colliders, hasCollision, validation, hasInvalid := lookup.resolve(requestedID)
 		if hasInvalid {
-			response.Write(w, *aliasValidationProblem(requestedID, validation))
-			return true
+			return aliasValidationProblem(requestedID, validation)
 		}
 		if hasCollision && len(colliders) > 1 {
-			response.Write(w, *aliasCollisionProblem(requestedID, colliders))
-			return true
+			return aliasCollisionProblem(requestedID, colliders)
 		}
 		if hasAlias {
 			effectiveID = aliasInfo.TargetID
@@ -295,12 +537,12 @@ func (h *RunsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 			aliasUsed = &temp
 			setScriptDir(effectiveID)
 		}
-		return false
+		return nil
 	}
 
 	if !setScriptDir(effectiveID) {
-		if resolveAlias() {
-			return
+		if prob := resolveAlias(); prob != nil {
+			return nil, prob
 		}
 	}
 
@@ -310,8 +552,8 @@ func (h *RunsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 				mergeJobInfo(jobMap, alt)
 				lookup.merge(alt)
 				if aliasUsed == nil {
-					if resolveAlias() {
-						return
+					if prob := resolveAlias(); prob != nil {
+						return nil, prob
 					}
 				}
 				if scriptDir == "" {
@@ -321,29 +563,58 @@ func (h *RunsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	var ociSource *sourcestore.Source
+	if scriptDir == "" && aliasUsed == nil {
+		if alias, ok := h.findOCIAlias(requestedID); ok {
+			effectiveID = alias.TargetID
+			aliasUsed = &alias
+		}
+		if src, job, ok := h.findOCIJob(effectiveID); ok {
+			if src.Quarantine != nil {
+				return fail(sourceQuarantinedProblem(src))
+			}
+			sourceTrust = src.EffectiveTrustLevel()
+			if sourceTrust == sourcestore.TrustUntrusted {
+				return fail(sourceTrustProblem(src.Name, sourceTrust, fmt.Sprintf("source %s is untrusted; its jobs can be planned but not run", src.Name)))
+			}
+			dir, err := materializeOCIJob(src, job)
+			if err != nil {
+				return fail(response.New(http.StatusInternalServerError, "materialize oci job failed",
+					response.WithExtension("code", "E_OCI"),
+					response.WithDetail(err.Error())))
+			}
+			inspected := inspectSourceCustody(ctx, src, h.now())
+			custody = &inspected
+			runRoot = src.LocalPath
+			scriptDir = dir
+			ociSource = &src
+		}
+	}
+
 	if scriptDir == "" {
 		if aliasUsed != nil {
 			validation := indexer.AliasValidation{Code: "alias.target.invalid", Detail: fmt.Sprintf("alias %q target %q not found", requestedID, aliasUsed.TargetPath)}
-			response.Write(w, *aliasValidationProblem(requestedID, validation))
-			return
-		}
-		if prob := h.ociRunUnsupported(requestedID); prob != nil {
-			response.Write(w, *prob)
-			return
+			return nil, aliasValidationProblem(requestedID, validation)
 		}
-		response.Write(w, response.New(http.StatusNotFound, "job not found", response.WithDetail(requestedID)))
-		return
+		return fail(response.New(http.StatusNotFound, "job not found", response.WithDetail(requestedID)))
+	}
+	sourceName := ""
+	if ociSource != nil {
+		sourceName = ociSource.Name
+	} else if req.Source != nil {
+		sourceName = req.Source.Name
+	}
+	if !jobVisible(ctx, effectiveID, sourceName) {
+		return fail(response.New(http.StatusNotFound, "job not found", response.WithDetail(requestedID)))
 	}
 
 	absScriptDir, err := filepath.Abs(scriptDir)
 	if err != nil {
-		response.Write(w, response.New(http.StatusInternalServerError, "resolve script directory", response.WithDetail(err.Error())))
-		return
+		return fail(response.New(http.StatusInternalServerError, "resolve script directory", response.WithDetail(err.Error())))
 	}
 	absScriptsRoot, err := filepath.Abs(runRoot)
 	if err != nil {
-		response.Write(w, response.New(http.StatusInternalServerError, "resolve scripts root", response.WithDetail(err.Error())))
-		return
+		return fail(response.New(http.StatusInternalServerError, "resolve scripts root", response.WithDetail(err.Error())))
 	}
 	relJobPath, relErr := filepath.Rel(absScriptsRoot, absScriptDir)
 	var execScriptDir string
@@ -353,10 +624,27 @@ func (h *RunsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		execScriptDir = absScriptDir
 	}
 
-	cfg, err := h.loadConfig(absScriptDir)
-	if err != nil {
-		response.Write(w, response.New(http.StatusInternalServerError, "load config failed", response.WithDetail(err.Error())))
-		return
+	cfg, prob := loadJobConfig(h.loadConfig, absScriptDir, req.Overlay)
+	if prob != nil {
+		return nil, prob
+	}
+	if prob := requireImpactScope(ctx, effectiveID, cfg.Impact); prob != nil {
+		return nil, prob
+	}
+	if req.StartAt != nil && cfg.Approval != nil {
+		return fail(response.New(http.StatusBadRequest, "invalid start_at",
+			response.WithExtension("code", "run.start_at_unsupported"),
+			response.WithDetail(fmt.Sprintf("job %s requires approval; its runs cannot be delayed", effectiveID))))
+	}
+
+	var inputsFrom map[string]any
+	if req.InputsFromRun != "" {
+		args, prov, prob := h.resolveRunInputs(req, cfg, jobMap)
+		if prob != nil {
+			return nil, prob
+		}
+		req.Args = args
+		inputsFrom = prov
 	}
 
 	spec := cfg.ArgSpec
@@ -366,17 +654,17 @@ func (h *RunsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		if bindErr != nil {
 			var argErr *engine.ArgError
 			if errors.As(bindErr, &argErr) {
-				response.Write(w, response.New(http.StatusUnprocessableEntity, "argument validation failed",
+				return fail(response.New(http.StatusUnprocessableEntity, "argument validation failed",
 					response.WithExtension("errors", []map[string]string{{"arg": argErr.Arg, "message": argErr.Msg}})))
-				return
 			}
-			response.Write(w, response.New(http.StatusBadRequest, "invalid arguments", response.WithDetail(bindErr.Error())))
-			return
+			return fail(response.New(http.StatusBadRequest, "invalid arguments", response.WithDetail(bindErr.Error())))
 		}
 		binding = bind
 	} else if len(req.Args) > 0 {
-		response.Write(w, response.New(http.StatusBadRequest, "job does not accept arguments"))
-		return
+		return fail(response.New(http.StatusBadRequest, "job does not accept arguments"))
+	}
+	if prob := applyArgEnv(cfg, spec, binding); prob != nil {
+		return nil, prob
 	}
 
 	executorMode := strings.ToLower(cfg.Executor)
@@ -387,15 +675,22 @@ func (h *RunsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		executorMode = "shell"
 	}
 
+	sandboxed := sourceTrust == sourcestore.TrustLimited
+	if sandboxed && executorMode != "container" {
+		return fail(sourceTrustProblem(req.Source.Name, sourceTrust, fmt.Sprintf("source %s is limited; only container jobs may run, sandboxed without network", req.Source.Name)))
+	}
+
 	var runtime container.Runtime
 	if executorMode == "container" {
+		if faults.Inject(ctx, faults.ContainerRuntime) {
+			return fail(runtimeUnavailableProblem(faults.Err(faults.ContainerRuntime)))
+		}
 		if h.runtime != "" {
 			runtime = h.runtime
 		} else {
 			detected, detectErr := detectContainerRuntime(nil)
 			if detectErr != nil {
-				response.Write(w, runtimeUnavailableProblem(detectErr))
-				return
+				return fail(runtimeUnavailableProblem(detectErr))
 			}
 			runtime = detected
 		}
@@ -406,7 +701,18 @@ func (h *RunsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 	if provenance == nil {
 		provenance = map[string]any{}
 	}
+	if ociSource != nil {
+		// The source view carries the image ref, resolved digest and pull
+		// policy the job runs with.
+		provenance["source"] = sourceToProvenance(*ociSource)
+	}
 	provenance["canonical_id"] = effectiveID
+	if req.ScheduleID != "" {
+		provenance["schedule_id"] = req.ScheduleID
+	}
+	if req.Trigger != nil {
+		provenance["trigger"] = req.Trigger
+	}
 	canonicalPath := strings.ReplaceAll(effectiveID, ".", "/")
 	if aliasUsed != nil {
 		canonicalPath = aliasUsed.TargetPath
@@ -423,15 +729,42 @@ func (h *RunsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		provenance["invoked_path"] = requestedID
 	}
 	provenance["canonical_path"] = canonicalPath
+	if custody != nil {
+		provenance["custody"] = custody.provenance()
+	}
+	if sourceTrust != "" {
+		provenance["source_trust_level"] = sourceTrust
+	}
+	if inputsFrom != nil {
+		provenance["inputs_from"] = inputsFrom
+	}
+	if req.Overlay != "" {
+		provenance["overlay"] = req.Overlay
+	}
+	minRetries, prob := h.applyFlakiness(effectiveID, req.Labels, provenance)
+	if prob != nil {
+		return nil, prob
+	}
 
-	effProfile, err := resolveEffectiveProfile(req.RequestedSecurityProfile, h.profile)
+	effProfile, err := resolveEffectiveProfile(req.RequestedSecurityProfile, cfg.SecurityProfile, h.profile)
 	if err != nil {
-		response.Write(w, response.New(http.StatusUnprocessableEntity, "invalid security profile",
+		return fail(response.New(http.StatusUnprocessableEntity, "invalid security profile",
 			response.WithExtension("code", "E_POLICY"),
 			response.WithDetail(err.Error())))
-		return
+	}
+	if custody != nil && custody.Drifted && custody.Type == "git" {
+		if effProfile == "secure" {
+			return fail(checkoutDriftedProblem(*custody))
+		}
+		if logger := requestctx.Logger(ctx); logger != nil {
+			logger.Warn("source.checkout.drifted",
+				slog.String("source", custody.Source),
+				slog.String("profile", effProfile),
+				slog.String("reason", custody.DriftReason))
+		}
 	}
 
+	policyEval := timeSpan{start: time.Now().UTC()}
 	policyCtx := h.policy
 	if policyCtx == nil {
 		policyCtx, _ = policy.NewContext(nil)
@@ -439,34 +772,36 @@ func (h *RunsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 
 	var findings []types.Finding
 	var trustPreview *types.ImageTrustPreview
-	ctx = requestctx.WithEffectiveProfile(r.Context(), effProfile)
+	ctx = requestctx.WithEffectiveProfile(ctx, effProfile)
 	if runtimeStr != "" {
 		ctx = requestctx.WithRuntime(ctx, runtimeStr)
 	}
-	r = r.WithContext(ctx)
-	logger = requestctx.Logger(ctx)
 	image := containerImageFromConfig(cfg)
 	if image != "" {
+		provenance["container_image"] = image
 		if prob := enforceRegistryAllowList(ctx, image, policyCtx); prob != nil {
-			response.Write(w, *prob)
-			return
+			return nil, prob
+		}
+		if ociSource != nil {
+			if prob := ensureOCIJobImage(ctx, runtime, *ociSource); prob != nil {
+				return nil, prob
+			}
 		}
 		mode, err := policyCtx.VerifyModeForProfile(effProfile)
 		if err != nil {
-			response.Write(w, response.New(http.StatusUnprocessableEntity, "policy error",
+			return fail(response.New(http.StatusUnprocessableEntity, "policy error",
 				response.WithExtension("code", "E_POLICY"),
 				response.WithDetail(err.Error())))
-			return
 		}
 		outcome, prob := enforceImageVerification(ctx, image, mode, h.verifier)
 		if prob != nil {
-			response.Write(w, *prob)
-			return
+			return nil, prob
 		}
 		if mode != policy.VerifyModeDisabled {
 			trustPreview = &types.ImageTrustPreview{
 				Image:    image,
 				Mode:     string(mode),
+				Backend:  outcome.Backend,
 				Verified: outcome.Verified,
 				Reason:   outcome.Reason,
 			}
@@ -483,8 +818,7 @@ func (h *RunsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 			})
 		}
 		if prob := enforceResourceCeilings(ctx, cfg, policyCtx.ContainerCeilings()); prob != nil {
-			response.Write(w, *prob)
-			return
+			return nil, prob
 		}
 	}
 	overrideFindings, decisions, prob := evaluateOverrides(ctx, cfg, effProfile, policyCtx)
@@ -498,170 +832,293 @@ func (h *RunsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 			}
 			publishPolicyDecisions(h.events, tempPayload, decisions)
 		}
-		response.Write(w, *prob)
-		return
+		return nil, prob
 	}
 	if len(overrideFindings) > 0 {
 		findings = append(findings, overrideFindings...)
 	}
+	findings = append(findings, evaluateEnvPolicy(ctx, cfg, effProfile, policyCtx)...)
+
+	scripts, err := executor.ScriptDigests(absScriptDir, cfg)
+	if err != nil {
+		return fail(response.New(http.StatusInternalServerError, "hash scripts failed", response.WithDetail(err.Error())))
+	}
+	if prob := enforceScriptAllowList(ctx, scripts, effProfile, policyCtx); prob != nil {
+		return nil, prob
+	}
+	if len(scripts) > 0 {
+		provenance["scripts"] = scripts
+	}
 
 	plan := engine.BuildPlan(effectiveID, cfg, spec, binding)
 	plan.SecurityProfile = effProfile
+	plan.Scripts = scripts
 	if len(findings) > 0 {
 		plan.PolicyFindings = findings
 	}
 	if trustPreview != nil {
 		plan.ImageTrust = trustPreview
 	}
+	principal, _ := requestctx.Principal(ctx)
+	admissionFindings, admissionDecisions, prob := h.admission.admit(ctx, admissionReview{
+		JobID:           effectiveID,
+		RequestedID:     requestedID,
+		Principal:       principal,
+		SecurityProfile: effProfile,
+		Executor:        executorMode,
+		Labels:          req.Labels,
+		Provenance:      provenance,
+		Plan:            plan,
+	})
+	decisions = append(decisions, admissionDecisions...)
+	if prob != nil {
+		publishPolicyDecisions(h.events, &RunPayload{
+			JobID:           effectiveID,
+			SecurityProfile: effProfile,
+			Executor:        executorMode,
+			Provenance:      provenance,
+		}, admissionDecisions)
+		return nil, prob
+	}
+	plan.PolicyFindings = append(plan.PolicyFindings, admissionFindings...)
+	policyEval.end = time.Now().UTC()
+	return &preparedRun{
+		ctx:           ctx,
+		requestedID:   requestedID,
+		effectiveID:   effectiveID,
+		aliasUsed:     aliasUsed,
+		execScriptDir: execScriptDir,
+		config:        cfg,
+		spec:          spec,
+		binding:       binding,
+		executor:      executorMode,
+		runtime:       runtime,
+		provenance:    provenance,
+		profile:       effProfile,
+		image:         image,
+		plan:          plan,
+		decisions:     decisions,
+		policyEval:    policyEval,
+		labels:        req.Labels,
+		sandboxed:     sandboxed,
+		minRetries:    minRetries,
+		startAt:       req.StartAt,
+		request:       req,
+	}, nil
+}
+
+// newPreparedRunPayload assigns a run id to prep and builds its accepted payload.
+func (h *RunsHandler) newPreparedRunPayload(prep *preparedRun, now time.Time) (RunPayload, *response.Problem) {
 	runID := events.GenerateRunID()
-	if executorMode == "container" && runtime != "" {
-		if err := container.RemoveContainer(context.Background(), runtime, runID); err != nil {
-			response.Write(w, containerNameConflictProblem(err))
-			return
+	if prep.executor == "container" && prep.runtime != "" {
+		if err := container.RemoveContainer(context.Background(), prep.runtime, runID); err != nil {
+			prob := containerNameConflictProblem(err)
+			return RunPayload{}, &prob
 		}
 	}
-	resp := newRunPayload(runID, effectiveID, defaultRunStatus, now)
-	resp.Executor = executorMode
-	resp.SecurityProfile = effProfile
-	if runtime != "" {
-		resp.Runtime = string(runtime)
+	resp := newRunPayload(runID, prep.effectiveID, defaultRunStatus, now)
+	resp.Executor = prep.executor
+	resp.SecurityProfile = prep.profile
+	if prep.runtime != "" {
+		resp.Runtime = string(prep.runtime)
 	}
-	if len(plan.ResolvedArgs) > 0 {
+	if len(prep.plan.ResolvedArgs) > 0 {
 		resp.Result = map[string]any{
-			"resolved_args": plan.ResolvedArgs,
+			"resolved_args": prep.plan.ResolvedArgs,
 		}
 	}
-	resp.Provenance = provenance
-
-	if h.idempotency != nil {
-		expiresAt := now.Add(h.idempotencyTTL)
-		if err := h.idempotency.Store(ctx, scopedKey, endpoint, bodyHashHex, resp, http.StatusCreated, expiresAt); err != nil {
-			if logger != nil {
-				logger.Error("idempotency store failed", slog.String("error", err.Error()))
-			}
-			if coredb.IsQuotaExceeded(err) {
-				response.Write(w, storageQuotaExceededProblem())
-			} else {
-				response.Write(w, response.New(http.StatusInternalServerError, "idempotency store failed", response.WithDetail(err.Error())))
-			}
-			return
-		}
+	resp.Provenance = prep.provenance
+	resp.Runner = h.runner.info(prep.runtime)
+	resp.Labels = prep.labels
+	resp.Warnings = prep.plan.Warnings
+	if approval := prep.config.Approval; approval != nil {
+		requestedBy, _ := requestctx.Principal(prep.ctx)
+		resp.Status = awaitingApprovalStatus
+		resp.Approval = &types.RunApproval{RequestedBy: requestedBy, Required: approval.Required}
+		return resp, nil
 	}
+	if prep.startAt != nil {
+		startAt := prep.startAt.UTC()
+		resp.Status = scheduledRunStatus
+		resp.StartAt = &startAt
+		return resp, nil
+	}
+	if prob := h.admitRun(prep.config.Concurrency, &resp); prob != nil {
+		return RunPayload{}, prob
+	}
+	return resp, nil
+}
 
+// startRun records resp in the run store and launches execution. Runs awaiting
+// approval are held until HandleApproval collects enough approvals; scheduled
+// runs until their start time.
+func (h *RunsHandler) startRun(prep *preparedRun, resp RunPayload) {
+	held := resp.Status == awaitingApprovalStatus || resp.Status == scheduledRunStatus
+	var runCtx *runExecutionContext
+	if !held {
+		// Register the execution context before the run becomes visible in
+		// the store so that a cancel arriving before executeRun starts is
+		// not lost.
+		runCtx = newRunExecutionContext(prep, resp)
+		h.running.Register(resp.ID, runCtx)
+	}
+	var request json.RawMessage
+	if resp.Status == scheduledRunStatus {
+		request = encodeScheduledRequest(prep)
+	}
 	h.store.Create(runstore.Run{
-		ID:         resp.ID,
-		JobID:      resp.JobID,
-		Status:     resp.Status,
-		StartedAt:  resp.StartedAt,
-		Result:     resp.Result,
-		Executor:   resp.Executor,
-		Runtime:    resp.Runtime,
-		Provenance: resp.Provenance,
+		ID:            resp.ID,
+		JobID:         resp.JobID,
+		Status:        resp.Status,
+		StartedAt:     resp.StartedAt,
+		Result:        resp.Result,
+		Executor:      resp.Executor,
+		Runtime:       resp.Runtime,
+		Provenance:    resp.Provenance,
+		Approval:      resp.Approval,
+		Runner:        resp.Runner,
+		Labels:        resp.Labels,
+		Warnings:      resp.Warnings,
+		QueuePosition: resp.QueuePosition,
+		StartAt:       resp.StartAt,
+		Request:       request,
 	})
 
-	if len(decisions) > 0 {
-		publishPolicyDecisions(h.events, &resp, decisions)
-	}
-	runCtx := &runExecutionContext{
-		ctx:        nil,
-		cancel:     nil,
-		runPayload: resp,
-		scriptDir:  execScriptDir,
-		config:     cfg,
-		spec:       spec,
-		binding:    binding,
-		plan:       plan,
-		executor:   executorMode,
-		runtime:    runtime,
+	if len(prep.decisions) > 0 {
+		publishPolicyDecisions(h.events, &resp, prep.decisions)
 	}
-	ctxWithCancel, cancel := context.WithCancel(context.Background())
-	runCtx.ctx = ctxWithCancel
-	runCtx.cancel = cancel
-	h.running.Store(runID, runCtx)
-	writeRunPayload(w, resp, http.StatusCreated)
-	if logger != nil {
+	if logger := requestctx.Logger(prep.ctx); logger != nil {
 		attrs := []any{
-			slog.String("run_id", runID),
-			slog.String("job_id", effectiveID),
+			slog.String("run_id", resp.ID),
+			slog.String("job_id", prep.effectiveID),
 			slog.String("status", resp.Status),
-			slog.String("executor", executorMode),
-			slog.String("security_profile", effProfile),
+			slog.String("executor", prep.executor),
+			slog.String("security_profile", prep.profile),
 		}
-		if aliasUsed != nil {
-			attrs = append(attrs, slog.String("invoked_path", requestedID))
+		if prep.aliasUsed != nil {
+			attrs = append(attrs, slog.String("invoked_path", prep.requestedID))
 		}
-		if runtimeStr != "" {
-			attrs = append(attrs, slog.String("runtime", runtimeStr))
+		if prep.runtime != "" {
+			attrs = append(attrs, slog.String("runtime", string(prep.runtime)))
 		}
-		if image != "" {
-			attrs = append(attrs, slog.String("image", image))
+		if prep.image != "" {
+			attrs = append(attrs, slog.String("image", prep.image))
 		}
 		logger.Info("run.accepted", attrs...)
 	}
-	go h.executeRun(runCtx)
+	if resp.Status == scheduledRunStatus {
+		h.delayRun(prep, resp)
+		return
+	}
+	if held {
+		h.holdRun(prep, resp)
+		return
+	}
+	h.dispatchRun(runCtx)
 }
 
-func (h *RunsHandler) ociRunUnsupported(jobID string) *response.Problem {
-	if h.sources == nil || strings.TrimSpace(jobID) == "" {
-		return nil
+func newRunExecutionContext(prep *preparedRun, resp RunPayload) *runExecutionContext {
+	ctxWithCancel, cancel := context.WithCancel(context.Background())
+	return &runExecutionContext{
+		ctx:        ctxWithCancel,
+		cancel:     cancel,
+		runPayload: resp,
+		scriptDir:  prep.execScriptDir,
+		config:     prep.config,
+		spec:       prep.spec,
+		binding:    prep.binding,
+		plan:       prep.plan,
+		executor:   prep.executor,
+		runtime:    prep.runtime,
+		policyEval: prep.policyEval,
+		sandboxed:  prep.sandboxed,
+		minRetries: prep.minRetries,
 	}
-	for _, src := range h.sources.List() {
-		if !strings.EqualFold(src.Type, "oci") {
-			continue
-		}
-		manifest, err := loadAddonManifestFromSource(src)
-		if err != nil {
-			continue
-		}
-		for _, job := range manifest.Jobs {
-			if composeOCIJobID(src.Name, job.ID) != jobID {
-				continue
-			}
-			detail := fmt.Sprintf("OCI add-on job %s from source %s cannot be executed in this phase.", jobID, src.Name)
-			options := []response.Option{
-				response.WithExtension("code", "E_OCI_RUN_UNSUPPORTED"),
-				response.WithDetail(detail),
-				response.WithExtension("source", sourceToProvenance(src)),
-			}
-			if summary := manifestSummary(manifest); len(summary) > 0 {
-				options = append(options, response.WithExtension("manifest", summary))
-			}
-			prob := response.New(http.StatusNotImplemented, "oci add-on runs not yet supported", options...)
-			return &prob
-		}
+}
+
+// launchRun prepares and starts req without idempotency bookkeeping. It backs
+// server-initiated runs such as pipeline promotions.
+func (h *RunsHandler) launchRun(ctx context.Context, req runRequest) (RunPayload, *response.Problem) {
+	prep, prob := h.prepareRun(ctx, req)
+	if prob != nil {
+		return RunPayload{}, prob
 	}
-	return nil
+	resp, prob := h.newPreparedRunPayload(prep, h.now())
+	if prob != nil {
+		return RunPayload{}, prob
+	}
+	h.startRun(prep, resp)
+	return resp, nil
 }
 
-func resolveEffectiveProfile(requested, cfgProfile string) (string, error) {
-	if requested != "" {
-		if prof, ok := normalizeProfile(requested); ok {
-			return prof, nil
-		}
-		return "", fmt.Errorf("invalid requested security profile %q", requested)
+// launchRunOnce is launchRun recorded in the idempotency store under key, as
+// POST /runs records Idempotency-Key. A key already recorded for the context's
+// principal and endpoint returns the run it started, with replayed set,
+// instead of starting another.
+func (h *RunsHandler) launchRunOnce(ctx context.Context, req runRequest, endpoint, key string) (resp RunPayload, replayed bool, prob *response.Problem) {
+	if h.idempotency == nil {
+		resp, prob = h.launchRun(ctx, req)
+		return resp, false, prob
 	}
-	if env := os.Getenv("FLWD_PROFILE"); env != "" {
-		if prof, ok := normalizeProfile(env); ok {
-			return prof, nil
-		}
-		return "", fmt.Errorf("invalid FLWD_PROFILE value %q", env)
+	principal, _ := requestctx.Principal(ctx)
+	scopedKey := scopedIdempotencyKey(principal, key)
+	now := h.now()
+	cached, _, _, found, err := h.idempotency.Lookup(ctx, scopedKey, endpoint, now)
+	if err != nil {
+		p := response.New(http.StatusInternalServerError, "idempotency lookup failed", response.WithDetail(err.Error()))
+		return RunPayload{}, false, &p
 	}
-	if cfgProfile != "" {
-		if prof, ok := normalizeProfile(cfgProfile); ok {
-			return prof, nil
-		}
-		return "", fmt.Errorf("invalid configured security profile %q", cfgProfile)
+	if found {
+		return cached, true, nil
+	}
+	prep, prob := h.prepareRun(ctx, req)
+	if prob != nil {
+		return RunPayload{}, false, prob
+	}
+	resp, prob = h.newPreparedRunPayload(prep, now)
+	if prob != nil {
+		return RunPayload{}, false, prob
 	}
-	return "secure", nil
+	keyHash := sha256.Sum256([]byte(key))
+	if err := h.idempotency.Store(ctx, scopedKey, endpoint, hex.EncodeToString(keyHash[:]), resp, http.StatusCreated, now.Add(h.idempotencyTTL)); err != nil {
+		h.releaseSlot(resp.ID)
+		p := h.idempotencyStoreProblem(prep.ctx, err)
+		return RunPayload{}, false, &p
+	}
+	h.startRun(prep, resp)
+	return resp, false, nil
 }
 
-func normalizeProfile(value string) (string, bool) {
-	switch strings.ToLower(strings.TrimSpace(value)) {
-	case "secure", "permissive", "disabled":
-		return strings.ToLower(strings.TrimSpace(value)), true
-	default:
-		return "", false
+// resolveEffectiveProfile returns the strictest of the server, job and request
+// profiles. A job's security_profile acts as a floor that requests cannot relax.
+// The server profile is resolved once at startup (flag > FLWD_PROFILE > secure)
+// and injected through the handler config; it is never read from the process
+// environment at request time.
+func resolveEffectiveProfile(requested, jobProfile, cfgProfile string) (string, error) {
+	serverProfile := "secure"
+	if cfgProfile != "" {
+		prof, ok := policy.NormalizeProfile(cfgProfile)
+		if !ok {
+			return "", fmt.Errorf("invalid configured security profile %q", cfgProfile)
+		}
+		serverProfile = prof
+	}
+	var jobProf, reqProf string
+	if jobProfile != "" {
+		prof, ok := policy.NormalizeProfile(jobProfile)
+		if !ok {
+			return "", fmt.Errorf("invalid job security profile %q", jobProfile)
+		}
+		jobProf = prof
+	}
+	if requested != "" {
+		prof, ok := policy.NormalizeProfile(requested)
+		if !ok {
+			return "", fmt.Errorf("invalid requested security profile %q", requested)
+		}
+		reqProf = prof
 	}
+	return policy.StrictestProfile(serverProfile, jobProf, reqProf), nil
 }
 
 func (h *RunsHandler) resolveProvenance(jobID string, src *RunSourceRef, scriptDir, absScriptDir string) map[string]any {
@@ -694,6 +1151,23 @@ type runRequest struct {
 	Args                     map[string]any `json:"args"`
 	RequestedSecurityProfile string         `json:"requested_security_profile"`
 	Source                   *RunSourceRef  `json:"source"`
+	// InputsFromRun chains this run to a completed run of the job named by
+	// the config's inputs_from; its outputs seed matching args.
+	InputsFromRun string `json:"inputs_from_run,omitempty"`
+	// Trigger records what started a server-initiated run, such as a forge
+	// webhook. It is copied into provenance and cannot be set by clients.
+	Trigger map[string]any `json:"-"`
+	// ScheduleID links runs started by a schedule, or triggered through it,
+	// to the schedule; it is recorded in provenance as schedule_id.
+	ScheduleID string `json:"-"`
+	// Labels are free-form tags stored with the run for filtering GET /runs.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Overlay selects config.d/overlays/<overlay>.yaml to merge over the
+	// job config.
+	Overlay string `json:"overlay,omitempty"`
+	// StartAt delays the run until the given time; it waits in the
+	// scheduled status until then.
+	StartAt *time.Time `json:"start_at,omitempty"`
 }
 
 // RunSourceRef represents a requested source reference for the run.
@@ -716,17 +1190,38 @@ func decodeRunRequest(body io.ReadCloser) (runRequest, []byte, error) {
 	if req.Args == nil {
 		req.Args = map[string]any{}
 	}
+	req.Overlay = strings.TrimSpace(req.Overlay)
 	return req, data, nil
 }
 
 func (h *RunsHandler) handleList(w http.ResponseWriter, r *http.Request) {
-	page, perPage, err := parseRunsPagination(r)
+	page, perPage, err := h.pagination.parse(r)
 	if err != nil {
 		response.Write(w, response.New(http.StatusBadRequest, "invalid pagination", response.WithDetail(err.Error())))
 		return
 	}
+	fields, prob := parseRunFields(r)
+	if prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+	sortKeys, err := sortspec.Parse(r.URL.Query().Get("sort"), runstore.SortFields...)
+	if err != nil {
+		response.Write(w, invalidSortProblem(err))
+		return
+	}
+	if len(sortKeys) == 0 {
+		sortKeys = runstore.DefaultSort
+	}
 
-	runs := h.store.List()
+	filter, prob := parseRunListFilter(r)
+	if prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+
+	runs := visibleRuns(r.Context(), filter.apply(h.store.ListSorted(sortKeys)))
+	writePaginationHeaders(w, len(runs), page, perPage)
 	start := (page - 1) * perPage
 	if start >= len(runs) {
 		runs = []runstore.Run{}
@@ -738,19 +1233,14 @@ func (h *RunsHandler) handleList(w http.ResponseWriter, r *http.Request) {
 		runs = runs[start:end]
 	}
 
-	payloads := make([]RunPayload, len(runs))
-	for i, run := range runs {
-		payloads[i] = payloadFromStore(run)
-	}
-
-	data, err := json.Marshal(payloads)
+	err = writeJSONArray(w, len(runs), func(i int) any {
+		return fields.apply(payloadFromStore(runs[i]))
+	})
 	if err != nil {
-		response.Write(w, response.New(http.StatusInternalServerError, "encode runs failed", response.WithDetail(err.Error())))
-		return
+		if logger := requestctx.Logger(r.Context()); logger != nil {
+			logger.Warn("runs.list.stream", slog.String("error", err.Error()))
+		}
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(data)
 }
 
 // HandleCancel processes POST /runs/{id}:cancel.
@@ -772,53 +1262,38 @@ func (h *RunsHandler) HandleCancel(w http.ResponseWriter, r *http.Request, runID
 		writeRunPayload(w, payloadFromStore(run), http.StatusOK)
 		return
 	}
-	if value, ok := h.running.Load(runID); ok {
-		if execCtx, ok := value.(*runExecutionContext); ok {
-			if execCtx.cancel != nil {
-				execCtx.cancel()
-			}
+	updated := h.cancelRun(r.Context(), runID, "canceled by request")
+	writeRunPayload(w, payloadFromStore(updated), http.StatusAccepted)
+}
+
+// cancelRun stops the run's execution, records the canceled status and
+// publishes the cancellation event. It returns the updated run.
+func (h *RunsHandler) cancelRun(ctx context.Context, runID, reason string) runstore.Run {
+	// Drop a held or scheduled run first so that a concurrent approval or
+	// its start time cannot start it.
+	h.releaseHeldRun(runID)
+	h.releaseDelayedRun(runID)
+	h.running.Cancel(runID)
+	// A run still waiting for a concurrency slot never executes, so it
+	// leaves the queue and the registry here.
+	if ready, dequeued := h.limiter.dequeue(runID); dequeued {
+		h.running.Remove(runID)
+		for _, runCtx := range ready {
+			go h.executeRun(runCtx)
 		}
 	}
 	finished := time.Now().UTC()
 	h.updateRunStatus(runID, "canceled", &finished)
 	updated, _ := h.store.Get(runID)
-	h.publishRunCanceled(updated, finished, "canceled by request")
-	if logger := requestctx.Logger(r.Context()); logger != nil {
+	h.publishRunCanceled(updated, finished, reason)
+	if logger := requestctx.Logger(ctx); logger != nil {
 		logger.Info("run.cancel.request",
 			slog.String("run_id", runID),
 			slog.String("status", "canceled"),
-			slog.String("reason", "canceled by request"),
+			slog.String("reason", reason),
 		)
 	}
-	writeRunPayload(w, payloadFromStore(updated), http.StatusAccepted)
-}
-
-func parseRunsPagination(r *http.Request) (int, int, error) {
-	page := defaultRunsPage
-	perPage := defaultRunsPerPage
-
-	q := r.URL.Query()
-
-	if v := q.Get("page"); v != "" {
-		val, err := strconv.Atoi(v)
-		if err != nil || val <= 0 {
-			return 0, 0, errors.New("page must be a positive integer")
-		}
-		page = val
-	}
-
-	if v := q.Get("per_page"); v != "" {
-		val, err := strconv.Atoi(v)
-		if err != nil {
-			return 0, 0, errors.New("per_page must be numeric")
-		}
-		if val <= 0 || val > maxRunsPerPage {
-			return 0, 0, errors.New("per_page must be between 1 and 200")
-		}
-		perPage = val
-	}
-
-	return page, perPage, nil
+	return updated
 }
 
 func encodeData(payload any) string {
@@ -846,6 +1321,9 @@ func sourceToProvenance(src sourcestore.Source) map[string]any {
 	if src.ResolvedRef != "" {
 		out["resolved_ref"] = src.ResolvedRef
 	}
+	if src.ResolvedCommit != "" {
+		out["resolved_commit"] = src.ResolvedCommit
+	}
 	if src.PullPolicy != "" {
 		out["pull_policy"] = src.PullPolicy
 	}
@@ -903,14 +1381,29 @@ func writePlanArtifact(plan types.Plan, runDir string) error {
 	return nil
 }
 
-func prepareSecrets(runDir string, binding *engine.Binding) (string, error) {
+func writeRunReceipt(receipt types.RunReceipt, runDir string) error {
+	data, err := json.MarshalIndent(receipt, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode receipt: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(runDir, "receipt.json"), append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write receipt: %w", err)
+	}
+	return nil
+}
+
+// prepareSecrets writes the run's secret args to files under runDir,
+// resolving args that declare from through provider. It returns the
+// directory and the resolved values, which must be redacted from output.
+func prepareSecrets(ctx context.Context, runDir string, binding *engine.Binding, provider secrets.Provider) (string, []string, error) {
 	if binding == nil || len(binding.SecretNames) == 0 {
-		return "", nil
+		return "", nil, nil
 	}
 	secretDir := filepath.Join(runDir, "secrets")
 	if err := os.MkdirAll(secretDir, 0o700); err != nil {
-		return "", fmt.Errorf("create secrets dir: %w", err)
+		return "", nil, fmt.Errorf("create secrets dir: %w", err)
 	}
+	var resolved []string
 	for name := range binding.SecretNames {
 		safeName := sanitizeSecretName(name)
 		if safeName == "" {
@@ -918,7 +1411,16 @@ func prepareSecrets(runDir string, binding *engine.Binding) (string, error) {
 		}
 		path := filepath.Join(secretDir, safeName)
 		value := ""
-		if raw, ok := binding.Values[name]; ok && raw != nil {
+		if ref, ok := binding.SecretRefs[name]; ok {
+			data, err := provider.Resolve(ctx, ref)
+			if err != nil {
+				return "", nil, fmt.Errorf("resolve secret %s from %s: %w", name, ref, err)
+			}
+			value = string(data)
+			if value != "" {
+				resolved = append(resolved, value)
+			}
+		} else if raw, ok := binding.Values[name]; ok && raw != nil {
 			if s, ok := raw.(string); ok {
 				value = s
 			} else {
@@ -926,10 +1428,10 @@ func prepareSecrets(runDir string, binding *engine.Binding) (string, error) {
 			}
 		}
 		if err := os.WriteFile(path, []byte(value), 0o600); err != nil {
-			return "", fmt.Errorf("write secret %s: %w", name, err)
+			return "", nil, fmt.Errorf("write secret %s: %w", name, err)
 		}
 	}
-	return secretDir, nil
+	return secretDir, resolved, nil
 }
 
 func sanitizeSecretName(name string) string {
@@ -981,6 +1483,28 @@ func publishPolicyDecisions(sink EventSink, payload *RunPayload, decisions []pol
 	}
 }
 
+// publishPolicyEvaluation records how long admission policy checks took for
+// the run's timeline.
+func publishPolicyEvaluation(sink EventSink, payload *RunPayload, span timeSpan, findings int) {
+	if sink == nil || span.start.IsZero() {
+		return
+	}
+	data := map[string]any{
+		"run_id":           payload.ID,
+		"job_id":           payload.JobID,
+		"security_profile": payload.SecurityProfile,
+		"started_at":       span.start,
+		"finished_at":      span.end,
+		"duration_ms":      span.end.Sub(span.start).Milliseconds(),
+		"findings":         findings,
+	}
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		bytes = []byte("{}")
+	}
+	sink.Publish(payload.ID, sse.Event{Event: "policy.evaluation", Data: string(bytes), Timestamp: span.end})
+}
+
 type runExecutionContext struct {
 	ctx        context.Context
 	cancel     context.CancelFunc
@@ -993,52 +1517,65 @@ type runExecutionContext struct {
 	executor   string
 	runtime    container.Runtime
 	sink       events.Sink
+	policyEval timeSpan
+	sandboxed  bool
+	minRetries int
 }
 
 func (h *RunsHandler) executeRun(execCtx *runExecutionContext) {
 	if execCtx == nil {
 		return
 	}
-	defer h.running.Delete(execCtx.runPayload.ID)
+	defer h.running.Remove(execCtx.runPayload.ID)
+	defer h.releaseSlot(execCtx.runPayload.ID)
 	if execCtx.cancel != nil {
 		defer execCtx.cancel()
 	}
 	runID := execCtx.runPayload.ID
+	if execCtx.ctx != nil && execCtx.ctx.Err() != nil {
+		// Canceled between acceptance and start; HandleCancel already
+		// recorded the terminal status.
+		return
+	}
 	jobID := execCtx.runPayload.JobID
 	runDir := paths.RunDir(runID)
 	absRunDir, err := filepath.Abs(runDir)
 	if err != nil {
-		h.failRun(runID, "failed", fmt.Errorf("resolve run dir: %w", err))
+		h.failRun(execCtx, "", fmt.Errorf("resolve run dir: %w", err))
 		return
 	}
 	runDir = absRunDir
 
 	if err := os.MkdirAll(runDir, 0o700); err != nil {
-		h.failRun(runID, "failed", fmt.Errorf("create run dir: %w", err))
+		h.failRun(execCtx, "", fmt.Errorf("create run dir: %w", err))
 		return
 	}
 
 	if err := writePlanArtifact(execCtx.plan, runDir); err != nil {
-		h.failRun(runID, "failed", err)
+		h.failRun(execCtx, runDir, err)
 		return
 	}
 
-	secretDir, err := prepareSecrets(runDir, execCtx.binding)
+	runCtx := execCtx.ctx
+	if runCtx == nil {
+		runCtx = context.Background()
+	}
+	secretDir, resolvedSecrets, err := prepareSecrets(runCtx, runDir, execCtx.binding, h.argSecrets)
 	if err != nil {
-		h.failRun(runID, "failed", err)
+		h.failRun(execCtx, runDir, err)
 		return
 	}
 
 	stdoutFile, err := os.OpenFile(filepath.Join(runDir, "stdout"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
 	if err != nil {
-		h.failRun(runID, "failed", fmt.Errorf("open stdout file: %w", err))
+		h.failRun(execCtx, runDir, fmt.Errorf("open stdout file: %w", err))
 		return
 	}
 	defer stdoutFile.Close()
 
 	stderrFile, err := os.OpenFile(filepath.Join(runDir, "stderr"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
 	if err != nil {
-		h.failRun(runID, "failed", fmt.Errorf("open stderr file: %w", err))
+		h.failRun(execCtx, runDir, fmt.Errorf("open stderr file: %w", err))
 		return
 	}
 	defer stderrFile.Close()
@@ -1052,9 +1589,24 @@ func (h *RunsHandler) executeRun(execCtx *runExecutionContext) {
 	if sink != nil {
 		sink.EmitRunStart(runID, jobID)
 	}
+	if run, ok := h.store.Get(runID); ok {
+		for _, n := range h.notifiers {
+			if sn, ok := n.(RunStartNotifier); ok {
+				sn.RunStarted(execCtx.config, run)
+			}
+		}
+	}
+	publishPolicyEvaluation(h.events, &execCtx.runPayload, execCtx.policyEval, len(execCtx.plan.PolicyFindings))
+	if h.settings.DebugEvents() {
+		h.publishRunDebug(execCtx, runDir)
+	}
 
 	stdoutWriter := io.MultiWriter(stdoutFile)
 	stderrWriter := io.MultiWriter(stderrFile)
+	if h.stripANSI {
+		stdoutWriter = events.NewANSIStripper(stdoutFile)
+		stderrWriter = events.NewANSIStripper(stderrFile)
+	}
 
 	execCfg := executor.ExecutorConfig{
 		Flags:            map[string]interface{}{},
@@ -1063,15 +1615,21 @@ func (h *RunsHandler) executeRun(execCtx *runExecutionContext) {
 		JobID:            jobID,
 		Emitter:          sink,
 		RunDir:           runDir,
-		StdoutWriter:     stdoutWriter,
-		StderrWriter:     stderrWriter,
+		StdoutLog:        stdoutWriter,
+		StderrLog:        stderrWriter,
 		ContainerRuntime: execCtx.runtime,
+		ScriptDigests:    executor.DigestMap(execCtx.plan.Scripts),
+		EnvFilter:        stepEnvFilter(execCtx.plan.SecurityProfile, h.policy),
+		Sandboxed:        execCtx.sandboxed,
+		MaxParallelSteps: h.maxParallel,
+		MaxLogLineBytes:  h.maxLogLine,
+		MinRetries:       execCtx.minRetries,
 	}
 	if execCtx.binding != nil {
 		execCfg.ArgEnv = execCtx.binding.ScalarEnv
 		execCfg.ArgsJSON = execCtx.binding.ArgsJSON
 		execCfg.ArgValues = execCtx.binding.Values
-		execCfg.LineRedactor = events.NewLineRedactor(execCtx.binding.SecretValues)
+		execCfg.LineRedactor = events.NewLineRedactor(slices.Concat(execCtx.binding.SecretValues, resolvedSecrets))
 	}
 	if execCtx.config != nil {
 		execCfg.EnvInherit = execCtx.config.EnvInheritance
@@ -1087,17 +1645,16 @@ func (h *RunsHandler) executeRun(execCtx *runExecutionContext) {
 		execCfg.SecretsDir = secretDir
 	}
 
-	runCtx := execCtx.ctx
-	if runCtx == nil {
-		runCtx = context.Background()
-	}
 	results, err := executor.RunScripts(runCtx, execCtx.scriptDir, execCfg)
 	status := "completed"
 	runErr := err
+	var timeout *executor.TimeoutError
 	if err != nil {
 		if errors.Is(err, context.Canceled) || errors.Is(runCtx.Err(), context.Canceled) {
 			status = "canceled"
 			runErr = context.Canceled
+		} else if errors.As(err, &timeout) {
+			status = "timed_out"
 		} else {
 			status = "failed"
 		}
@@ -1113,6 +1670,24 @@ func (h *RunsHandler) executeRun(execCtx *runExecutionContext) {
 			runErr = context.Canceled
 		}
 	}
+	var (
+		outputs    map[string]any
+		violations []engine.ContractViolation
+	)
+	if status == "completed" {
+		var outErr error
+		outputs, violations, outErr = collectRunOutputs(execCtx.config, runDir)
+		switch {
+		case outErr != nil:
+			status = "failed"
+			runErr = outErr
+		case len(violations) > 0:
+			status = "failed"
+			runErr = fmt.Errorf("outputs violate the job contract (%d violation(s))", len(violations))
+		}
+	}
+	h.recordRunOutputs(runID, outputs, violations)
+	artifacts := h.recordRunArtifacts(runID, runDir)
 	finished := time.Now().UTC()
 	execCtx.runPayload.FinishedAt = &finished
 	execCtx.runPayload.Status = status
@@ -1124,11 +1699,104 @@ func (h *RunsHandler) executeRun(execCtx *runExecutionContext) {
 		prevStatus = prev.Status
 	}
 	h.updateRunStatus(runID, status, &finished)
+	shutdown := ""
+	if status == "canceled" || status == "timed_out" {
+		shutdown = runShutdown(results)
+		h.recordRunShutdown(runID, shutdown)
+	}
+	receipt := runReceipt(execCtx, status, finished)
+	receipt.Shutdown = shutdown
+	receipt.Artifacts = artifacts
+	if err := writeRunReceipt(receipt, runDir); err != nil {
+		slog.Default().Warn("run.receipt.failed", slog.String("run_id", runID), slog.String("error", err.Error()))
+	}
 	if status == "canceled" && prevStatus != "canceled" {
 		if run, ok := h.store.Get(runID); ok {
 			h.publishRunCanceled(run, finished, "canceled")
 		}
 	}
+	h.notifyRunFinished(execCtx, runErr)
+}
+
+// runReceipt returns the receipt of execCtx's run finishing with status at
+// finished.
+func runReceipt(execCtx *runExecutionContext, status string, finished time.Time) types.RunReceipt {
+	return types.RunReceipt{
+		RunID:           execCtx.runPayload.ID,
+		JobID:           execCtx.runPayload.JobID,
+		Status:          status,
+		SecurityProfile: execCtx.plan.SecurityProfile,
+		StartedAt:       execCtx.runPayload.StartedAt,
+		FinishedAt:      finished,
+		Scripts:         execCtx.plan.Scripts,
+		Provenance:      execCtx.runPayload.Provenance,
+		Approval:        execCtx.runPayload.Approval,
+		Runner:          execCtx.runPayload.Runner,
+	}
+}
+
+// notifyRunFinished hands the run's final record to the run notifiers.
+func (h *RunsHandler) notifyRunFinished(execCtx *runExecutionContext, runErr error) {
+	run, ok := h.store.Get(execCtx.runPayload.ID)
+	if !ok {
+		return
+	}
+	for _, n := range h.notifiers {
+		n.RunFinished(execCtx.config, run, runErr)
+	}
+}
+
+// publishRunDebug emits a run.debug event describing how the run will execute.
+// It is only sent while debug events are enabled in the admin settings.
+func (h *RunsHandler) publishRunDebug(execCtx *runExecutionContext, runDir string) {
+	if h.events == nil {
+		return
+	}
+	payload := map[string]any{
+		"run_id":           execCtx.runPayload.ID,
+		"job_id":           execCtx.runPayload.JobID,
+		"executor":         execCtx.executor,
+		"script_dir":       execCtx.scriptDir,
+		"run_dir":          runDir,
+		"security_profile": execCtx.plan.SecurityProfile,
+		"steps":            len(execCtx.plan.Steps),
+		"timestamp":        time.Now().UTC(),
+	}
+	if execCtx.runtime != "" {
+		payload["runtime"] = string(execCtx.runtime)
+	}
+	if len(execCtx.plan.PolicyFindings) > 0 {
+		payload["policy_findings"] = execCtx.plan.PolicyFindings
+	}
+	h.events.Publish(execCtx.runPayload.ID, sse.Event{Event: "run.debug", Data: encodeData(payload)})
+}
+
+// runShutdown summarizes how a canceled or timed-out run's interrupted steps
+// exited: forced if any had to be killed, graceful if all exited within their
+// grace period.
+func runShutdown(results []executor.ScriptResult) string {
+	shutdown := ""
+	for _, res := range results {
+		switch res.Shutdown {
+		case types.ShutdownForced:
+			return types.ShutdownForced
+		case types.ShutdownGraceful:
+			shutdown = types.ShutdownGraceful
+		}
+	}
+	return shutdown
+}
+
+func (h *RunsHandler) recordRunShutdown(runID, shutdown string) {
+	if shutdown == "" {
+		return
+	}
+	current, ok := h.store.Get(runID)
+	if !ok {
+		return
+	}
+	current.Shutdown = shutdown
+	h.store.Update(current)
 }
 
 func (h *RunsHandler) updateRunStatus(runID, status string, finished *time.Time) {
@@ -1139,26 +1807,40 @@ func (h *RunsHandler) updateRunStatus(runID, status string, finished *time.Time)
 	if isTerminalStatus(current.Status) && current.Status != status {
 		return
 	}
+	finishing := !isTerminalStatus(current.Status) && isTerminalStatus(status)
 	current.Status = status
 	if finished != nil {
 		current.FinishedAt = finished
 	}
 	h.store.Update(current)
+	if finishing {
+		var duration time.Duration
+		if current.FinishedAt != nil && !current.StartedAt.IsZero() {
+			duration = current.FinishedAt.Sub(current.StartedAt)
+		}
+		metrics.Default.RecordRunFinished(status, duration)
+	}
 }
 
-func (h *RunsHandler) failRun(runID string, status string, err error) {
-	stamp := time.Now().UTC()
-	h.updateRunStatus(runID, status, &stamp)
-	if h.events != nil {
-		payload := map[string]any{"status": status}
-		if err != nil {
-			payload["error"] = err.Error()
+// failRun finishes a run that failed before its steps could start, such as
+// when its secrets cannot be resolved. The failure takes the same path as a
+// finished run: a full run.finish event, a receipt when runDir exists, and
+// the run notifiers.
+func (h *RunsHandler) failRun(execCtx *runExecutionContext, runDir string, err error) {
+	runID := execCtx.runPayload.ID
+	finished := time.Now().UTC()
+	execCtx.runPayload.FinishedAt = &finished
+	execCtx.runPayload.Status = "failed"
+	if sink := newSSESink(h.events, &execCtx.runPayload); sink != nil {
+		sink.EmitRunFinish(runID, "failed", err)
+	}
+	h.updateRunStatus(runID, "failed", &finished)
+	if runDir != "" {
+		if err := writeRunReceipt(runReceipt(execCtx, "failed", finished), runDir); err != nil {
+			slog.Default().Warn("run.receipt.failed", slog.String("run_id", runID), slog.String("error", err.Error()))
 		}
-		h.events.Publish(runID, sse.Event{
-			Event: "run.finish",
-			Data:  encodeData(payload),
-		})
 	}
+	h.notifyRunFinished(execCtx, err)
 }
 
 func (h *RunsHandler) publishRunCanceled(run runstore.Run, finished time.Time, reason string) {
@@ -1189,7 +1871,7 @@ func (h *RunsHandler) publishRunCanceled(run runstore.Run, finished time.Time, r
 
 func isTerminalStatus(status string) bool {
 	switch strings.ToLower(status) {
-	case "completed", "failed", "canceled":
+	case "completed", "failed", "canceled", "timed_out":
 		return true
 	default:
 		return false
@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/response"
+)
+
+const defaultMaxBatchRuns = 100
+
+type runBatchRequest struct {
+	Runs []runRequest `json:"runs"`
+}
+
+type runBatchResult struct {
+	Index  int            `json:"index"`
+	Status int            `json:"status"`
+	Run    *RunPayload    `json:"run,omitempty"`
+	Error  map[string]any `json:"error,omitempty"`
+}
+
+type runBatchResponse struct {
+	Results []runBatchResult `json:"results"`
+}
+
+// HandleBatch processes POST /runs:batch. Every item is resolved and evaluated
+// against policy before any run is created; if one item fails, none start and
+// the response lists the failing items. A single Idempotency-Key covers the
+// whole batch.
+func (h *RunsHandler) HandleBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+	batch, rawBody, err := decodeRunBatchRequest(r.Body)
+	if err != nil {
+		response.Write(w, response.New(http.StatusBadRequest, "invalid request body", response.WithDetail(err.Error())))
+		return
+	}
+	if len(batch.Runs) == 0 {
+		response.Write(w, response.New(http.StatusBadRequest, "runs must not be empty"))
+		return
+	}
+	if len(batch.Runs) > h.maxBatch {
+		response.Write(w, response.New(http.StatusRequestEntityTooLarge, "batch too large",
+			response.WithDetail(fmt.Sprintf("batch contains %d runs; at most %d are allowed", len(batch.Runs), h.maxBatch)),
+			response.WithExtension("max_batch", h.maxBatch)))
+		return
+	}
+	for i, req := range batch.Runs {
+		if req.JobID == "" {
+			response.Write(w, response.New(http.StatusBadRequest, "job_id is required",
+				response.WithDetail(fmt.Sprintf("runs[%d] is missing job_id", i))))
+			return
+		}
+	}
+
+	bodyHashHex, prob := requestBodyHash(r, rawBody)
+	if prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+
+	ctx := r.Context()
+	principal, _ := requestctx.Principal(ctx)
+	idemKey, prob := requestIdempotencyKey(r)
+	if prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+	scopedKey := scopedIdempotencyKey(principal, idemKey)
+	endpoint := r.Method + " " + r.URL.Path
+	now := h.now()
+	if h.idempotency != nil {
+		replay, storedHash, found, err := h.lookupBatch(r, scopedKey, endpoint, len(batch.Runs))
+		if err != nil {
+			response.Write(w, response.New(http.StatusInternalServerError, "idempotency lookup failed", response.WithDetail(err.Error())))
+			return
+		}
+		if found {
+			if storedHash != bodyHashHex {
+				response.Write(w, idempotencyConflictProblem(storedHash, bodyHashHex))
+				return
+			}
+			w.Header().Set("Idempotent-Replay", "true")
+			recordIdempotencyReplay(r.Context())
+			writeRunBatch(w, replay, http.StatusCreated)
+			return
+		}
+	}
+
+	prepared := make([]*preparedRun, len(batch.Runs))
+	var failures []runBatchResult
+	for i, req := range batch.Runs {
+		prep, prob := h.prepareRun(ctx, req)
+		if prob != nil {
+			failures = append(failures, batchFailure(i, *prob))
+			continue
+		}
+		prepared[i] = prep
+	}
+	if len(failures) > 0 {
+		response.Write(w, batchValidationProblem(failures))
+		return
+	}
+
+	payloads := make([]RunPayload, len(prepared))
+	for i, prep := range prepared {
+		resp, prob := h.newPreparedRunPayload(prep, now)
+		if prob != nil {
+			for _, admitted := range payloads[:i] {
+				h.releaseSlot(admitted.ID)
+			}
+			response.Write(w, batchValidationProblem([]runBatchResult{batchFailure(i, *prob)}))
+			return
+		}
+		payloads[i] = resp
+	}
+
+	if h.idempotency != nil {
+		expiresAt := now.Add(h.idempotencyTTL)
+		for i, resp := range payloads {
+			if err := h.idempotency.Store(ctx, batchItemKey(scopedKey, i), endpoint, bodyHashHex, resp, http.StatusCreated, expiresAt); err != nil {
+				for _, admitted := range payloads {
+					h.releaseSlot(admitted.ID)
+				}
+				response.Write(w, h.idempotencyStoreProblem(ctx, err))
+				return
+			}
+		}
+	}
+
+	results := make([]runBatchResult, len(payloads))
+	for i, prep := range prepared {
+		h.startRun(prep, payloads[i])
+		run := payloads[i]
+		results[i] = runBatchResult{Index: i, Status: http.StatusCreated, Run: &run}
+	}
+	writeRunBatch(w, results, http.StatusCreated)
+}
+
+// lookupBatch replays a previously accepted batch. Each item is stored under
+// its own derived key so the existing idempotency store can hold the batch; the
+// first item carries the body hash used for conflict detection.
+func (h *RunsHandler) lookupBatch(r *http.Request, scopedKey, endpoint string, n int) ([]runBatchResult, string, bool, error) {
+	now := h.now()
+	first, status, storedHash, found, err := h.idempotency.Lookup(r.Context(), batchItemKey(scopedKey, 0), endpoint, now)
+	if err != nil || !found {
+		return nil, "", false, err
+	}
+	results := []runBatchResult{{Index: 0, Status: status, Run: &first}}
+	for i := 1; i < n; i++ {
+		cached, status, hash, found, err := h.idempotency.Lookup(r.Context(), batchItemKey(scopedKey, i), endpoint, now)
+		if err != nil {
+			return nil, "", false, err
+		}
+		if !found {
+			return nil, "", false, fmt.Errorf("idempotency record for batch item %d missing", i)
+		}
+		if hash != storedHash {
+			// Same key, different batch: report the first item's hash so the
+			// caller answers with a conflict.
+			return nil, storedHash, true, nil
+		}
+		run := cached
+		results = append(results, runBatchResult{Index: i, Status: status, Run: &run})
+	}
+	return results, storedHash, true, nil
+}
+
+func batchItemKey(scopedKey string, index int) string {
+	return scopedKey + "#" + strconv.Itoa(index)
+}
+
+func batchFailure(index int, prob response.Problem) runBatchResult {
+	return runBatchResult{Index: index, Status: prob.Status, Error: prob.Body()}
+}
+
+func batchValidationProblem(failures []runBatchResult) response.Problem {
+	status := http.StatusUnprocessableEntity
+	if len(failures) == 1 {
+		status = failures[0].Status
+	}
+	return response.New(status, "batch rejected",
+		response.WithDetail(fmt.Sprintf("%d run(s) failed validation; no runs were created", len(failures))),
+		response.WithExtension("results", failures))
+}
+
+func decodeRunBatchRequest(body io.ReadCloser) (runBatchRequest, []byte, error) {
+	defer body.Close()
+	var req runBatchRequest
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return req, nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return req, data, err
+	}
+	for i := range req.Runs {
+		if req.Runs[i].Args == nil {
+			req.Runs[i].Args = map[string]any{}
+		}
+	}
+	return req, data, nil
+}
+
+func writeRunBatch(w http.ResponseWriter, results []runBatchResult, status int) {
+	data, err := json.Marshal(runBatchResponse{Results: results})
+	if err != nil {
+		response.Write(w, response.New(http.StatusInternalServerError, "encode runs failed", response.WithDetail(err.Error())))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
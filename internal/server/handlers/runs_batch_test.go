@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+const batchTestConfig = `
+version: v1
+job:
+  id: demo
+  name: Demo Job
+argspec:
+  args:
+    - name: name
+      type: string
+      required: true
+`
+
+func postBatch(h *RunsHandler, body, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/runs:batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	rec := httptest.NewRecorder()
+	h.HandleBatch(rec, req)
+	return rec
+}
+
+func TestRunsHandlerBatchCreatesAllRuns(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "demo", batchTestConfig)
+	store := runstore.New()
+	h := NewRunsHandler(RunsConfig{Root: root, Store: store})
+
+	body := `{"runs":[{"job_id":"demo","args":{"name":"a"}},{"job_id":"demo","args":{"name":"b"}}]}`
+	key := newIdempotencyKey()
+	rec := postBatch(h, body, key)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp runBatchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(resp.Results))
+	}
+	for i, res := range resp.Results {
+		if res.Index != i || res.Status != http.StatusCreated || res.Run == nil || res.Run.ID == "" {
+			t.Fatalf("unexpected result %d: %+v", i, res)
+		}
+	}
+	if resp.Results[0].Run.ID == resp.Results[1].Run.ID {
+		t.Fatalf("expected distinct run ids")
+	}
+
+	replay := postBatch(h, body, key)
+	if replay.Code != http.StatusCreated || replay.Header().Get("Idempotent-Replay") != "true" {
+		t.Fatalf("expected idempotent replay, got %d: %s", replay.Code, replay.Body.String())
+	}
+	var replayed runBatchResponse
+	if err := json.Unmarshal(replay.Body.Bytes(), &replayed); err != nil {
+		t.Fatalf("decode replay: %v", err)
+	}
+	if len(replayed.Results) != 2 || replayed.Results[1].Run.ID != resp.Results[1].Run.ID {
+		t.Fatalf("replay returned different runs: %+v", replayed.Results)
+	}
+	if got := len(store.List()); got != 2 {
+		t.Fatalf("expected 2 stored runs, got %d", got)
+	}
+
+	conflict := postBatch(h, `{"runs":[{"job_id":"demo","args":{"name":"c"}}]}`, key)
+	if conflict.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for reused key, got %d", conflict.Code)
+	}
+}
+
+func TestRunsHandlerBatchAllOrNothing(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "demo", batchTestConfig)
+	store := runstore.New()
+	h := NewRunsHandler(RunsConfig{Root: root, Store: store})
+
+	body := `{"runs":[{"job_id":"demo","args":{"name":"a"}},{"job_id":"missing"},{"job_id":"demo","args":{}}]}`
+	rec := postBatch(h, body, newIdempotencyKey())
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var problem struct {
+		Results []runBatchResult `json:"results"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(problem.Results) != 2 {
+		t.Fatalf("expected 2 failing items, got %+v", problem.Results)
+	}
+	if problem.Results[0].Index != 1 || problem.Results[0].Status != http.StatusNotFound {
+		t.Fatalf("unexpected first failure %+v", problem.Results[0])
+	}
+	if problem.Results[1].Index != 2 || problem.Results[1].Status != http.StatusUnprocessableEntity {
+		t.Fatalf("unexpected second failure %+v", problem.Results[1])
+	}
+	if got := len(store.List()); got != 0 {
+		t.Fatalf("expected no runs created, got %d", got)
+	}
+}
+
+func TestRunsHandlerBatchLimit(t *testing.T) {
+	h := NewRunsHandler(RunsConfig{Root: t.TempDir(), Store: runstore.New(), MaxBatch: 1})
+	rec := postBatch(h, `{"runs":[{"job_id":"a"},{"job_id":"b"}]}`, newIdempotencyKey())
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", rec.Code)
+	}
+	rec = postBatch(h, `{"runs":[]}`, newIdempotencyKey())
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for empty batch, got %d", rec.Code)
+	}
+}
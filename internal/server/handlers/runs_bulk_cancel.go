@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+// runCancelFilter selects runs for POST /runs:cancel. All set fields must
+// match; at least one field is required so an empty body never cancels every
+// run on the server.
+type runCancelFilter struct {
+	JobID         string     `json:"job_id"`
+	Label         string     `json:"label"`
+	Status        string     `json:"status"`
+	StartedBefore *time.Time `json:"started_before"`
+}
+
+type runCancelSummary struct {
+	Matched  int      `json:"matched"`
+	Canceled int      `json:"canceled"`
+	RunIDs   []string `json:"run_ids"`
+}
+
+// HandleBulkCancel processes POST /runs:cancel, canceling every non-terminal
+// run that matches the filter body.
+func (h *RunsHandler) HandleBulkCancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+	filter, err := decodeRunCancelFilter(r.Body)
+	if err != nil {
+		response.Write(w, response.New(http.StatusBadRequest, "invalid request body", response.WithDetail(err.Error())))
+		return
+	}
+	if err := filter.validate(); err != nil {
+		response.Write(w, response.New(http.StatusBadRequest, "invalid cancel filter", response.WithDetail(err.Error())))
+		return
+	}
+
+	summary := runCancelSummary{RunIDs: []string{}}
+	for _, run := range h.store.List() {
+		if isTerminalStatus(run.Status) || !filter.matches(run) || !RunVisible(r.Context(), run) {
+			continue
+		}
+		summary.Matched++
+		updated := h.cancelRun(r.Context(), run.ID, "canceled by bulk request")
+		if strings.EqualFold(updated.Status, "canceled") {
+			summary.Canceled++
+			summary.RunIDs = append(summary.RunIDs, run.ID)
+		}
+	}
+	if logger := requestctx.Logger(r.Context()); logger != nil {
+		logger.Info("run.cancel.bulk",
+			slog.String("job_id", filter.JobID),
+			slog.String("label", filter.Label),
+			slog.String("status", filter.Status),
+			slog.Int("matched", summary.Matched),
+			slog.Int("canceled", summary.Canceled),
+		)
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		response.Write(w, response.New(http.StatusInternalServerError, "encode summary failed", response.WithDetail(err.Error())))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+func decodeRunCancelFilter(body io.ReadCloser) (runCancelFilter, error) {
+	defer body.Close()
+	var filter runCancelFilter
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return filter, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&filter); err != nil {
+		return filter, err
+	}
+	filter.JobID = strings.TrimSpace(filter.JobID)
+	filter.Label = strings.TrimSpace(filter.Label)
+	filter.Status = strings.ToLower(strings.TrimSpace(filter.Status))
+	return filter, nil
+}
+
+func (f runCancelFilter) validate() error {
+	if f.JobID == "" && f.Label == "" && f.Status == "" && f.StartedBefore == nil {
+		return fmt.Errorf("at least one of job_id, label, status or started_before is required")
+	}
+	if f.Label != "" {
+		if _, _, err := parseLabelSelector(f.Label); err != nil {
+			return err
+		}
+	}
+	if f.Status != "" && isTerminalStatus(f.Status) {
+		return fmt.Errorf("status %q is terminal; only queued or running runs can be canceled", f.Status)
+	}
+	return nil
+}
+
+func (f runCancelFilter) matches(run runstore.Run) bool {
+	if f.JobID != "" && !strings.EqualFold(run.JobID, f.JobID) {
+		return false
+	}
+	if f.Status != "" && !strings.EqualFold(run.Status, f.Status) {
+		return false
+	}
+	if f.StartedBefore != nil && !run.StartedAt.Before(*f.StartedBefore) {
+		return false
+	}
+	if f.Label != "" {
+		key, value, _ := parseLabelSelector(f.Label)
+		if !runHasLabel(run, key, value) {
+			return false
+		}
+	}
+	return true
+}
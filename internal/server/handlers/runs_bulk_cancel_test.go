@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+func TestRunsHandlerBulkCancelFilters(t *testing.T) {
+	store := runstore.New()
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	store.Create(runstore.Run{ID: "r1", JobID: "deploy", Status: "running", StartedAt: base, Labels: map[string]string{"env": "prod"}})
+	store.Create(runstore.Run{ID: "r2", JobID: "deploy", Status: "running", StartedAt: base.Add(time.Hour), Labels: map[string]string{"env": "staging"}})
+	store.Create(runstore.Run{ID: "r3", JobID: "deploy", Status: "completed", StartedAt: base, Labels: map[string]string{"env": "prod"}})
+	store.Create(runstore.Run{ID: "r4", JobID: "backup", Status: "queued", StartedAt: base, Labels: map[string]string{"env": "prod"}})
+	h := NewRunsHandler(RunsConfig{Root: t.TempDir(), Store: store})
+
+	body := `{"job_id":"deploy","label":"env:prod","started_before":"2025-03-01T12:30:00Z"}`
+	req := httptest.NewRequest(http.MethodPost, "/runs:cancel", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.HandleBulkCancel(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var summary runCancelSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if summary.Matched != 1 || summary.Canceled != 1 || len(summary.RunIDs) != 1 || summary.RunIDs[0] != "r1" {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+	for id, want := range map[string]string{"r1": "canceled", "r2": "running", "r3": "completed", "r4": "queued"} {
+		run, _ := store.Get(id)
+		if run.Status != want {
+			t.Fatalf("run %s: expected status %s, got %s", id, want, run.Status)
+		}
+	}
+}
+
+func TestRunsHandlerBulkCancelRejectsEmptyFilter(t *testing.T) {
+	h := NewRunsHandler(RunsConfig{Root: t.TempDir(), Store: runstore.New()})
+	for _, body := range []string{`{}`, `{"status":"completed"}`, `{"label":"novalue"}`} {
+		req := httptest.NewRequest(http.MethodPost, "/runs:cancel", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.HandleBulkCancel(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("body %s: expected 400, got %d", body, rec.Code)
+		}
+	}
+}
@@ -23,10 +23,13 @@ import (
 	"github.com/flowd-org/flowd/internal/paths"
 	"github.com/flowd-org/flowd/internal/policy"
 	"github.com/flowd-org/flowd/internal/policy/verify"
+	"github.com/flowd-org/flowd/internal/secrets"
+	"github.com/flowd-org/flowd/internal/server/faults"
 	"github.com/flowd-org/flowd/internal/server/requestctx"
 	"github.com/flowd-org/flowd/internal/server/runstore"
 	"github.com/flowd-org/flowd/internal/server/sourcestore"
 	"github.com/flowd-org/flowd/internal/server/sse"
+	"github.com/flowd-org/flowd/internal/types"
 )
 
 var idempotencySeq uint64
@@ -127,7 +130,7 @@ argspec:
 	if source["resolved_ref"] == "" {
 		t.Fatalf("expected resolved_ref in provenance")
 	}
-	getHandler := NewRunGetHandler(store)
+	getHandler := NewRunGetHandler(store, nil)
 	getReq := httptest.NewRequest(http.MethodGet, "/runs/"+payload["id"].(string), nil)
 	getResp := httptest.NewRecorder()
 	getHandler.ServeHTTP(getResp, getReq)
@@ -435,7 +438,7 @@ func TestRunsHandlerUnknownJob(t *testing.T) {
 		t.Fatalf("expected 404, got %d", resp.Code)
 	}
 
-	getHandler := NewRunGetHandler(store)
+	getHandler := NewRunGetHandler(store, nil)
 	getReq := httptest.NewRequest(http.MethodGet, "/runs/does-not-exist", nil)
 	getResp := httptest.NewRecorder()
 	getHandler.ServeHTTP(getResp, getReq)
@@ -958,6 +961,9 @@ argspec:
 	if len(pageOne) != 2 {
 		t.Fatalf("expected 2 runs on first page, got %d", len(pageOne))
 	}
+	if total, remaining := listResp.Header().Get("X-Total-Count"), listResp.Header().Get("X-Remaining-Count"); total != "3" || remaining != "1" {
+		t.Fatalf("expected total 3 and remaining 1, got %q and %q", total, remaining)
+	}
 	if pageOne[0]["job_id"] != "demo" {
 		t.Fatalf("expected job_id demo first entry")
 	}
@@ -978,6 +984,47 @@ argspec:
 	if len(pageTwo) != 1 {
 		t.Fatalf("expected 1 run on second page, got %d", len(pageTwo))
 	}
+	if remaining := listResp2.Header().Get("X-Remaining-Count"); remaining != "0" {
+		t.Fatalf("expected remaining 0 on last page, got %q", remaining)
+	}
+}
+
+func TestRunsHandlerListConfiguredPageBounds(t *testing.T) {
+	store := runstore.New()
+	for _, id := range []string{"r1", "r2", "r3", "r4"} {
+		store.Create(runstore.Run{ID: id, JobID: "demo", Status: "completed"})
+	}
+	h := NewRunsHandler(RunsConfig{Store: store, DefaultPerPage: 3, MaxPerPage: 3})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs", nil))
+	var runs []map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&runs); err != nil {
+		t.Fatalf("decode runs: %v", err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("expected configured default of 3 runs, got %d", len(runs))
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs?per_page=4", nil))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "between 1 and 3") {
+		t.Fatalf("expected 400 above configured maximum, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs?sort=-id&per_page=1", nil))
+	if err := json.NewDecoder(rec.Body).Decode(&runs); err != nil {
+		t.Fatalf("decode runs: %v", err)
+	}
+	if len(runs) != 1 || runs[0]["id"] != "r4" {
+		t.Fatalf("expected r4 first when sorted by -id, got %v", runs)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs?sort=duration,size", nil))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "sort.invalid") {
+		t.Fatalf("expected sort.invalid problem, got %d: %s", rec.Code, rec.Body.String())
+	}
 }
 
 func TestRunsHandlerRejectsInvalidRequestedProfile(t *testing.T) {
@@ -1014,6 +1061,43 @@ job:
 	}
 }
 
+func TestResolveEffectiveProfileStrictest(t *testing.T) {
+	cases := []struct {
+		requested, job, server string
+		want                   string
+	}{
+		{"", "", "", "secure"},
+		{"", "", "permissive", "permissive"},
+		{"disabled", "", "permissive", "permissive"},
+		{"secure", "", "disabled", "secure"},
+		{"permissive", "secure", "disabled", "secure"},
+		{"", "permissive", "disabled", "permissive"},
+	}
+	for _, tc := range cases {
+		got, err := resolveEffectiveProfile(tc.requested, tc.job, tc.server)
+		if err != nil {
+			t.Fatalf("resolve(%q,%q,%q): %v", tc.requested, tc.job, tc.server, err)
+		}
+		if got != tc.want {
+			t.Fatalf("resolve(%q,%q,%q) = %q, want %q", tc.requested, tc.job, tc.server, got, tc.want)
+		}
+	}
+	if _, err := resolveEffectiveProfile("", "bogus", "secure"); err == nil {
+		t.Fatalf("expected error for invalid job profile")
+	}
+}
+
+func TestResolveEffectiveProfileIgnoresProcessEnv(t *testing.T) {
+	t.Setenv("FLWD_PROFILE", "disabled")
+	got, err := resolveEffectiveProfile("", "", "permissive")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if got != "permissive" {
+		t.Fatalf("expected injected profile permissive, got %q", got)
+	}
+}
+
 func TestRunsHandlerBlocksDisallowedRegistry(t *testing.T) {
 	root := t.TempDir()
 	writeJobConfig(t, root, "registry", `
@@ -1071,8 +1155,13 @@ container:
 	}
 }
 
-func TestRunsHandlerOCIRunUnsupported(t *testing.T) {
-	t.Setenv("FLWD_PROFILE", "")
+func TestRunsHandlerPreparesOCIAddonRun(t *testing.T) {
+	withOCIRuntimeStub(t, func(ctx context.Context, runtime container.Runtime, args ...string) ([]byte, error) {
+		if len(args) >= 2 && args[0] == "image" && args[1] == "inspect" {
+			return ociInspectPayloadWithDigest("sha256:deadbeef"), nil
+		}
+		return nil, fmt.Errorf("unexpected runtime call %v", args)
+	})
 	sources := sourcestore.New()
 	manifestPath := writeOCIRunManifest(t, `
 apiVersion: flwd.addon/v1
@@ -1086,8 +1175,12 @@ jobs:
   - id: build
     name: Build
     summary: Demo job
+    entrypoint: ["/usr/local/bin/build", "--ci"]
     argspec:
-      args: []
+      args:
+        - name: target
+          type: string
+          required: true
 `)
 	sources.Upsert(sourcestore.Source{
 		Name:        "addon",
@@ -1096,40 +1189,160 @@ jobs:
 		Ref:         "ghcr.io/example/addon:1.0.0",
 		Digest:      "sha256:deadbeef",
 		ResolvedRef: "sha256:deadbeef",
+		PullPolicy:  "on-run",
+		TrustLevel:  sourcestore.TrustTrusted,
+		Aliases:     []types.CommandAlias{{From: "addon/build", To: "ship"}},
 		Metadata: map[string]any{
 			"manifest_path": manifestPath,
 		},
 	})
+	policyCtx, err := policy.NewContext(&policy.Bundle{AllowedRegistries: []string{"ghcr.io"}})
+	if err != nil {
+		t.Fatalf("policy context: %v", err)
+	}
 
 	h := NewRunsHandler(RunsConfig{
-		Root:    filepath.Join(t.TempDir(), "scripts"),
-		Store:   runstore.New(),
-		Sources: sources,
-		Profile: "secure",
+		Root:     filepath.Join(t.TempDir(), "scripts"),
+		Store:    runstore.New(),
+		Sources:  sources,
+		Profile:  "secure",
+		Policy:   policyCtx,
+		Verifier: stubVerifier{result: verify.Result{Verified: true}},
+		Runtime:  container.Runtime("podman"),
 		Discover: func(string) (indexer.Result, error) {
 			return indexer.Result{}, nil
 		},
 	})
 
-	req := httptest.NewRequest(http.MethodPost, "/runs", strings.NewReader(`{"job_id":"addon/build"}`))
-	req.Header.Set("Content-Type", "application/json")
-	addIdempotencyHeader(req)
-	rec := httptest.NewRecorder()
+	if _, prob := h.prepareRun(context.Background(), runRequest{JobID: "addon/build", Args: map[string]any{}}); prob == nil || prob.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("expected the manifest argspec to require target, got %+v", prob)
+	}
+	prep, prob := h.prepareRun(context.Background(), runRequest{JobID: "addon/build", Args: map[string]any{"target": "prod"}})
+	if prob != nil {
+		t.Fatalf("prepare run: %+v", prob)
+	}
+	const image = "ghcr.io/example/addon:1.0.0@sha256:deadbeef"
+	if prep.executor != "container" || prep.image != image {
+		t.Fatalf("expected container executor with pinned image, got %q %q", prep.executor, prep.image)
+	}
+	source, ok := prep.provenance["source"].(map[string]any)
+	if !ok || source["digest"] != "sha256:deadbeef" || source["pull_policy"] != "on-run" || prep.provenance["container_image"] != image {
+		t.Fatalf("unexpected provenance %+v", prep.provenance)
+	}
+	script, err := os.ReadFile(filepath.Join(prep.execScriptDir, addonJobScript))
+	if err != nil {
+		t.Fatalf("read materialized script: %v", err)
+	}
+	if !strings.Contains(string(script), `exec '/usr/local/bin/build' '--ci' "$@"`) {
+		t.Fatalf("unexpected script %q", script)
+	}
+	if len(prep.plan.Scripts) != 1 || prep.plan.Scripts[0].Path != addonJobScript {
+		t.Fatalf("expected the materialized script to be pinned, got %+v", prep.plan.Scripts)
+	}
 
-	h.ServeHTTP(rec, req)
+	aliased, prob := h.prepareRun(context.Background(), runRequest{JobID: "ship", Args: map[string]any{"target": "prod"}})
+	if prob != nil {
+		t.Fatalf("prepare aliased run: %+v", prob)
+	}
+	if aliased.effectiveID != "addon/build" || aliased.image != image {
+		t.Fatalf("expected the imported alias to resolve to addon/build, got %q %q", aliased.effectiveID, aliased.image)
+	}
+	if alias, ok := aliased.provenance["alias"].(map[string]any); !ok || alias["source"] != "addon" || aliased.provenance["invoked_path"] != "ship" {
+		t.Fatalf("expected alias provenance, got %+v", aliased.provenance)
+	}
+}
 
-	if rec.Code != http.StatusNotImplemented {
-		t.Fatalf("expected 501, got %d: %s", rec.Code, rec.Body.String())
+func TestRunsHandlerPullsOCIAddonImageByDigest(t *testing.T) {
+	manifestPath := writeOCIRunManifest(t, `
+apiVersion: flwd.addon/v1
+kind: AddOn
+metadata:
+  name: OCI Addon
+  id: oci.addon
+  version: 1.0.0
+requires: {}
+jobs:
+  - id: build
+    name: Build
+    summary: Demo job
+    argspec:
+      args: []
+`)
+	newHandler := func(digest string) *RunsHandler {
+		sources := sourcestore.New()
+		sources.Upsert(sourcestore.Source{
+			Name:       "addon",
+			Type:       "oci",
+			LocalPath:  filepath.Dir(manifestPath),
+			Ref:        "ghcr.io/example/addon:1.0.0",
+			Digest:     digest,
+			PullPolicy: "never",
+			TrustLevel: sourcestore.TrustTrusted,
+			Metadata:   map[string]any{"manifest_path": manifestPath},
+		})
+		return NewRunsHandler(RunsConfig{
+			Root:     filepath.Join(t.TempDir(), "scripts"),
+			Store:    runstore.New(),
+			Sources:  sources,
+			Profile:  "secure",
+			Verifier: stubVerifier{result: verify.Result{Verified: true}},
+			Runtime:  container.Runtime("podman"),
+			Discover: func(string) (indexer.Result, error) {
+				return indexer.Result{}, nil
+			},
+		})
+	}
+	const pinned = "ghcr.io/example/addon:1.0.0@sha256:aaaa"
+
+	var pulled []string
+	served := ""
+	withOCIRuntimeStub(t, func(ctx context.Context, runtime container.Runtime, args ...string) ([]byte, error) {
+		switch {
+		case len(args) >= 3 && args[0] == "image" && args[1] == "inspect":
+			if served == "" {
+				return []byte("Error: " + args[2] + ": image not known"), errors.New("exit status 125")
+			}
+			return ociInspectPayloadWithDigest(served), nil
+		case len(args) == 2 && args[0] == "pull":
+			pulled = append(pulled, args[1])
+			if args[1] != pinned {
+				return nil, fmt.Errorf("expected a pull by digest, got %s", args[1])
+			}
+			served = "sha256:aaaa"
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unexpected runtime call %v", args)
+	})
+
+	// The never policy still pulls the recorded digest when it is absent.
+	if _, prob := newHandler("sha256:aaaa").prepareRun(context.Background(), runRequest{JobID: "addon/build"}); prob != nil {
+		t.Fatalf("prepare run: %+v", prob)
 	}
-	var problem map[string]any
-	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
-		t.Fatalf("decode problem: %v", err)
+	if len(pulled) != 1 || pulled[0] != pinned {
+		t.Fatalf("expected one pull of %s, got %v", pinned, pulled)
+	}
+
+	// The registry no longer serves the digest recorded at source-add time.
+	served = ""
+	pulled = nil
+	withOCIRuntimeStub(t, func(ctx context.Context, runtime container.Runtime, args ...string) ([]byte, error) {
+		if args[0] == "pull" {
+			pulled = append(pulled, args[1])
+			return []byte("Error: reading manifest sha256:bbbb in ghcr.io/example/addon: manifest unknown"), errors.New("exit status 125")
+		}
+		return []byte("Error: image not known"), errors.New("exit status 125")
+	})
+	_, prob := newHandler("sha256:bbbb").prepareRun(context.Background(), runRequest{JobID: "addon/build"})
+	if prob == nil || prob.Status != http.StatusConflict || prob.Ext["code"] != "oci.digest.mismatch" || prob.Ext["registered"] != "sha256:bbbb" {
+		t.Fatalf("expected oci.digest.mismatch, got %+v", prob)
 	}
-	if problem["code"] != "E_OCI_RUN_UNSUPPORTED" {
-		t.Fatalf("expected E_OCI_RUN_UNSUPPORTED, got %+v", problem["code"])
+	if len(pulled) != 1 || pulled[0] != "ghcr.io/example/addon:1.0.0@sha256:bbbb" {
+		t.Fatalf("expected a pull by digest, got %v", pulled)
 	}
-	if sourcePayload, ok := problem["source"].(map[string]any); !ok || sourcePayload["type"] != "oci" {
-		t.Fatalf("expected provenance source, got %+v", problem["source"])
+
+	_, prob = newHandler("").prepareRun(context.Background(), runRequest{JobID: "addon/build"})
+	if prob == nil || prob.Status != http.StatusConflict || prob.Ext["code"] != "oci.digest.missing" {
+		t.Fatalf("expected oci.digest.missing, got %+v", prob)
 	}
 }
 
@@ -1464,8 +1677,8 @@ func TestPrepareSecretsWritesFiles(t *testing.T) {
 		Values:      map[string]interface{}{"api-key": "supersecret"},
 		SecretNames: map[string]struct{}{"api-key": {}},
 	}
-	secretDir, err := prepareSecrets(runDir, binding)
-	if err != nil {
+	secretDir, resolved, err := prepareSecrets(context.Background(), runDir, binding, secrets.Map{})
+	if err != nil || len(resolved) != 0 {
 		t.Fatalf("prepare secrets: %v", err)
 	}
 	if secretDir == "" {
@@ -1487,6 +1700,30 @@ func TestPrepareSecretsWritesFiles(t *testing.T) {
 	}
 }
 
+func TestPrepareSecretsResolvesReferences(t *testing.T) {
+	runDir := t.TempDir()
+	binding := &engine.Binding{
+		Values:      map[string]interface{}{},
+		SecretNames: map[string]struct{}{"db_password": {}},
+		SecretRefs:  map[string]string{"db_password": "vault:apps/db/password"},
+	}
+	secretDir, resolved, err := prepareSecrets(context.Background(), runDir, binding, secrets.Map{"vault:apps/db/password": "hunter2"})
+	if err != nil {
+		t.Fatalf("prepare secrets: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(secretDir, "db_password"))
+	if err != nil || string(content) != "hunter2" {
+		t.Fatalf("expected resolved secret file, got %q (%v)", content, err)
+	}
+	if len(resolved) != 1 || resolved[0] != "hunter2" {
+		t.Fatalf("expected resolved value for redaction, got %v", resolved)
+	}
+
+	if _, _, err := prepareSecrets(context.Background(), t.TempDir(), binding, secrets.Map{}); err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Fatalf("expected unresolvable reference to fail, got %v", err)
+	}
+}
+
 func writeJobConfig(t *testing.T, root, jobID, yaml string) {
 	t.Helper()
 	jobDir := filepath.Join(root, jobID)
@@ -1563,3 +1800,118 @@ func waitFor(cond func() bool, timeout time.Duration, t *testing.T) {
 		time.Sleep(10 * time.Millisecond)
 	}
 }
+
+func TestRunsHandlerInjectedFaults(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "demo", `
+version: v1
+job:
+  id: demo
+  name: Demo Job
+interpreter: bash
+`)
+	writeJobConfig(t, root, "boxed", `
+version: v1
+job:
+  id: boxed
+  name: Boxed Job
+executor: container
+interpreter: "container:ghcr.io/example/app:1"
+container:
+  image: ghcr.io/example/app:1
+`)
+	h := NewRunsHandler(RunsConfig{Root: root, Store: runstore.New(), Runtime: container.RuntimeDocker})
+
+	cases := []struct {
+		job   string
+		fault faults.Kind
+		want  int
+		match string
+	}{
+		{"demo", faults.IdempotencyError, http.StatusInternalServerError, "injected fault: idempotency"},
+		{"boxed", faults.ContainerRuntime, http.StatusUnprocessableEntity, "container.runtime.unavailable"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/runs", strings.NewReader(`{"job_id":"`+tc.job+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		addIdempotencyHeader(req)
+		req = req.WithContext(faults.WithSet(req.Context(), faults.Set{tc.fault: 0}))
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, req)
+		if resp.Code != tc.want || !strings.Contains(resp.Body.String(), tc.match) {
+			t.Fatalf("%s: expected %d with %q, got %d: %s", tc.fault, tc.want, tc.match, resp.Code, resp.Body.String())
+		}
+	}
+}
+
+func TestRunsHandlerCancelRecordsShutdown(t *testing.T) {
+	cases := []struct {
+		name     string
+		script   string
+		shutdown string
+	}{
+		{"graceful", `trap 'kill $child; wait $child; exit 0' INT
+sleep 30 & child=$!
+touch "$MARKER"
+wait $child
+`, "graceful"},
+		{"forced", `trap '' INT
+touch "$MARKER"
+sleep 30
+`, "forced"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			marker := filepath.Join(t.TempDir(), "started")
+			writeJobConfig(t, root, "longrun", `
+version: v1
+job:
+  id: longrun
+interpreter: "/bin/bash"
+env:
+  MARKER: "`+marker+`"
+cancel:
+  signal: INT
+  grace_period: 300ms
+`)
+			script := "#!/usr/bin/env bash\n" + tc.script
+			if err := os.WriteFile(filepath.Join(root, "longrun", "100_main.sh"), []byte(script), 0o755); err != nil {
+				t.Fatalf("write script: %v", err)
+			}
+
+			runStore := runstore.New()
+			h := NewRunsHandler(RunsConfig{Root: root, Store: runStore})
+			req := httptest.NewRequest(http.MethodPost, "/runs", strings.NewReader(`{"job_id":"longrun"}`))
+			req.Header.Set("Content-Type", "application/json")
+			addIdempotencyHeader(req)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+			}
+			var payload RunPayload
+			if err := json.NewDecoder(rec.Body).Decode(&payload); err != nil {
+				t.Fatalf("decode run payload: %v", err)
+			}
+			waitFor(func() bool {
+				_, err := os.Stat(marker)
+				return err == nil
+			}, 5*time.Second, t)
+
+			h.HandleCancel(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/runs/"+payload.ID+":cancel", nil), payload.ID)
+			waitFor(func() bool {
+				run, ok := runStore.Get(payload.ID)
+				return ok && run.Shutdown != ""
+			}, 5*time.Second, t)
+
+			run, _ := runStore.Get(payload.ID)
+			if run.Status != "canceled" || run.Shutdown != tc.shutdown {
+				t.Fatalf("expected canceled/%s, got %s/%s", tc.shutdown, run.Status, run.Shutdown)
+			}
+			if got := payloadFromStore(run).Shutdown; got != tc.shutdown {
+				t.Fatalf("payload shutdown = %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+// runScheduleID returns the schedule a run was started by or through, or ""
+// for runs that did not come from a schedule.
+func runScheduleID(run runstore.Run) string {
+	id, _ := run.Provenance["schedule_id"].(string)
+	return id
+}
+
+// ScheduleStats summarizes the finished runs of a schedule for GET /stats.
+// Canceled runs are not counted; failed and timed out runs are failures.
+type ScheduleStats struct {
+	ScheduleID  string  `json:"schedule_id"`
+	Runs        int     `json:"runs"`
+	Failures    int     `json:"failures"`
+	SuccessRate float64 `json:"success_rate"`
+}
+
+// scheduleStats computes the success rate of every schedule with finished
+// runs, ordered by schedule ID.
+func scheduleStats(runs []runstore.Run) []ScheduleStats {
+	bySchedule := make(map[string]*ScheduleStats)
+	var ids []string
+	for _, run := range runs {
+		id := runScheduleID(run)
+		if id == "" || (run.Status != "completed" && run.Status != "failed" && run.Status != "timed_out") {
+			continue
+		}
+		s, ok := bySchedule[id]
+		if !ok {
+			s = &ScheduleStats{ScheduleID: id}
+			bySchedule[id] = s
+			ids = append(ids, id)
+		}
+		s.Runs++
+		if run.Status != "completed" {
+			s.Failures++
+		}
+	}
+	out := make([]ScheduleStats, 0, len(ids))
+	for _, id := range ids {
+		s := bySchedule[id]
+		s.SuccessRate = math.Round(float64(s.Runs-s.Failures)/float64(s.Runs)*100) / 100
+		out = append(out, *s)
+	}
+	slices.SortFunc(out, func(a, b ScheduleStats) int {
+		return strings.Compare(a.ScheduleID, b.ScheduleID)
+	})
+	return out
+}
+
+// handleRuns serves GET /schedules/{id}/runs: the runs of s, filtered and
+// paginated as GET /runs?schedule_id={id}.
+func (h *SchedulesHandler) handleRuns(w http.ResponseWriter, r *http.Request, s jobSchedule) {
+	if r.Method != http.MethodGet {
+		response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+	query := r.URL.Query()
+	query.Set("schedule_id", s.id)
+	r2 := r.Clone(r.Context())
+	r2.URL.RawQuery = query.Encode()
+	h.cfg.Runs.handleList(w, r2)
+}
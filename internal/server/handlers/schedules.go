@@ -0,0 +1,603 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/cron"
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/settings"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+const (
+	defaultScheduleInterval = 15 * time.Second
+	// schedulePrincipal is the principal scheduled runs are started as.
+	schedulePrincipal = "scheduler"
+	// maxMissedRuns caps the runs a run-all-missed schedule starts to catch
+	// up; older missed times are skipped.
+	maxMissedRuns = 100
+	// upcomingRunTimes is how many fire times GET /schedules/{id} lists.
+	upcomingRunTimes = 5
+)
+
+// SchedulesConfig configures cron-scheduled runs.
+type SchedulesConfig struct {
+	// Runs starts scheduled runs; its scripts root and config loader supply
+	// the jobs' schedule blocks.
+	Runs *RunsHandler
+	// Settings pauses scheduled runs while scheduler_paused is set.
+	Settings *settings.Store
+	// Interval is how often schedules are checked; zero uses 15s.
+	Interval time.Duration
+	Now      func() time.Time
+	Logger   *slog.Logger
+}
+
+// SchedulesHandler starts runs for the schedule blocks of job configs and
+// serves /schedules. Runs go through the same preparation as POST /runs, so
+// policy, idempotency and provenance apply to them as to any other run.
+type SchedulesHandler struct {
+	cfg SchedulesConfig
+	// mu guards state, which holds what the API and the loop know about each
+	// schedule beyond its config.
+	mu    sync.Mutex
+	state map[string]*scheduleState
+}
+
+type scheduleState struct {
+	// enabled overrides the config's enabled flag once set through the API.
+	enabled *bool
+	// recovered is set once times missed while the server was down have
+	// been handled, so re-arming after a change never replays them.
+	recovered bool
+	cron      string
+	timezone  string
+	dst       cron.DSTPolicy
+	next      time.Time
+	lastRunAt *time.Time
+	lastRunID string
+	lastError string
+}
+
+// jobSchedule is one schedule block of a discovered job.
+type jobSchedule struct {
+	id    string
+	jobID string
+	spec  types.ScheduleConfig
+	cron  cron.Schedule
+	loc   *time.Location
+}
+
+// SchedulePayload describes a schedule in /schedules responses.
+type SchedulePayload struct {
+	ID        string            `json:"id"`
+	JobID     string            `json:"job_id"`
+	Name      string            `json:"name"`
+	Cron      string            `json:"cron"`
+	Timezone  string            `json:"timezone"`
+	Enabled   bool              `json:"enabled"`
+	NextRunAt *time.Time        `json:"next_run_at,omitempty"`
+	LastRunAt *time.Time        `json:"last_run_at,omitempty"`
+	LastRunID string            `json:"last_run_id,omitempty"`
+	LastError string            `json:"last_error,omitempty"`
+	Misfire   string            `json:"misfire"`
+	DST       types.ScheduleDST `json:"dst"`
+	// NextRunTimes lists the upcoming fire times in the schedule's time
+	// zone; only GET /schedules/{id} fills it.
+	NextRunTimes []time.Time       `json:"next_run_times,omitempty"`
+	Args         map[string]any    `json:"args,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+}
+
+// NewSchedulesHandler returns the scheduler; Run drives it and ServeHTTP
+// serves its API.
+func NewSchedulesHandler(cfg SchedulesConfig) *SchedulesHandler {
+	if cfg.Runs == nil {
+		cfg.Runs = NewRunsHandler(RunsConfig{})
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultScheduleInterval
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return &SchedulesHandler{cfg: cfg, state: make(map[string]*scheduleState)}
+}
+
+// Run checks schedules every interval until ctx is canceled.
+func (h *SchedulesHandler) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.cfg.Interval)
+	defer ticker.Stop()
+	h.Tick(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.Tick(ctx)
+		}
+	}
+}
+
+// Tick starts a run for every enabled schedule that came due since the last
+// tick and returns how many were started. A schedule seen for the first time
+// is armed; times it missed since its last scheduled run in the run store are
+// dropped or started according to its misfire policy.
+func (h *SchedulesHandler) Tick(ctx context.Context) int {
+	schedules, err := h.load()
+	if err != nil {
+		h.cfg.Logger.Warn("schedule.load.failed", slog.String("error", err.Error()))
+		return 0
+	}
+	now := h.cfg.Now()
+	paused := h.cfg.Settings != nil && h.cfg.Settings.SchedulerPaused()
+
+	type fire struct {
+		schedule jobSchedule
+		at       time.Time
+		// missed is how many times the schedule missed when the fire
+		// catches up after downtime.
+		missed int
+	}
+	var due []fire
+	h.mu.Lock()
+	seen := make(map[string]bool, len(schedules))
+	for _, s := range schedules {
+		seen[s.id] = true
+		st := h.stateLocked(s)
+		if !st.isEnabled(s.spec) {
+			st.next = time.Time{}
+			st.recovered = true
+			continue
+		}
+		if st.next.IsZero() {
+			st.next = s.next(now)
+			if st.recovered {
+				continue
+			}
+			st.recovered = true
+			missed := h.missedLocked(s, st, now)
+			if len(missed) == 0 {
+				continue
+			}
+			policy := s.misfire()
+			if policy == types.MisfireSkip || paused {
+				reason := "misfire"
+				if policy != types.MisfireSkip {
+					reason = "scheduler_paused"
+				}
+				h.cfg.Logger.Info("schedule.skipped",
+					slog.String("schedule", s.id),
+					slog.String("reason", reason),
+					slog.Int("missed", len(missed)),
+					slog.Time("scheduled_at", missed[len(missed)-1]))
+				continue
+			}
+			count := len(missed)
+			if policy == types.MisfireRunOnce {
+				missed = missed[count-1:]
+			}
+			for _, at := range missed {
+				due = append(due, fire{schedule: s, at: at, missed: count})
+			}
+			continue
+		}
+		if now.Before(st.next) {
+			continue
+		}
+		at := st.next
+		st.next = s.next(now)
+		if paused {
+			h.cfg.Logger.Info("schedule.skipped",
+				slog.String("schedule", s.id),
+				slog.String("reason", "scheduler_paused"),
+				slog.Time("scheduled_at", at))
+			continue
+		}
+		due = append(due, fire{schedule: s, at: at})
+	}
+	for id := range h.state {
+		if !seen[id] {
+			delete(h.state, id)
+		}
+	}
+	h.mu.Unlock()
+
+	started := 0
+	for _, f := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		ctx := requestctx.WithLogger(requestctx.WithPrincipal(ctx, schedulePrincipal), h.cfg.Logger)
+		key := fmt.Sprintf("schedule:%s:%d", f.schedule.id, f.at.Unix())
+		req := f.schedule.request(f.at, false)
+		if f.missed > 0 {
+			req.Trigger["misfire"] = map[string]any{
+				"policy": f.schedule.misfire(),
+				"missed": f.missed,
+			}
+		}
+		run, replayed, prob := h.cfg.Runs.launchRunOnce(ctx, req, "SCHEDULE "+f.schedule.id, key)
+		h.record(f.schedule.id, f.at, run, prob)
+		if prob == nil && !replayed {
+			started++
+		}
+	}
+	return started
+}
+
+// load reads the schedule blocks of every job under the runs handler's
+// scripts root. Jobs whose config fails to load are skipped; job discovery
+// reports them.
+func (h *SchedulesHandler) load() ([]jobSchedule, error) {
+	runs := h.cfg.Runs
+	result, err := runs.discover(runs.root)
+	if err != nil {
+		return nil, err
+	}
+	var out []jobSchedule
+	for _, job := range result.Jobs {
+		cfg, err := runs.loadConfig(filepath.Dir(job.Path), "")
+		if err != nil || len(cfg.Schedule) == 0 {
+			continue
+		}
+		for _, spec := range cfg.Schedule {
+			sched, err := cron.Parse(spec.Cron)
+			if err != nil {
+				continue
+			}
+			loc, err := time.LoadLocation(spec.Timezone)
+			if err != nil {
+				continue
+			}
+			out = append(out, jobSchedule{
+				id:    job.ID + "/" + spec.Name,
+				jobID: job.ID,
+				spec:  spec,
+				cron:  sched,
+				loc:   loc,
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].id < out[j].id })
+	return out, nil
+}
+
+// stateLocked returns the state of s, re-arming it when its cron expression,
+// time zone or daylight saving policy changed.
+func (h *SchedulesHandler) stateLocked(s jobSchedule) *scheduleState {
+	st, ok := h.state[s.id]
+	if !ok {
+		st = &scheduleState{}
+		h.state[s.id] = st
+	}
+	if st.cron != s.spec.Cron || st.timezone != s.spec.Timezone || st.dst != s.dst() {
+		st.cron, st.timezone, st.dst = s.spec.Cron, s.spec.Timezone, s.dst()
+		st.next = time.Time{}
+	}
+	return st
+}
+
+// missedLocked returns the times s was due between its last scheduled run in
+// the run store and now, oldest first and at most maxMissedRuns of them. It
+// also restores the last run of s into st.
+func (h *SchedulesHandler) missedLocked(s jobSchedule, st *scheduleState, now time.Time) []time.Time {
+	var (
+		last  time.Time
+		runID string
+	)
+	for _, run := range h.cfg.Runs.store.List() {
+		trigger, _ := run.Provenance["trigger"].(map[string]any)
+		if trigger["type"] != "schedule" || trigger["schedule"] != s.id || trigger["manual"] == true {
+			continue
+		}
+		raw, _ := trigger["scheduled_at"].(string)
+		at, err := time.Parse(time.RFC3339, raw)
+		if err != nil || !at.After(last) {
+			continue
+		}
+		last, runID = at, run.ID
+	}
+	if last.IsZero() {
+		return nil
+	}
+	if st.lastRunAt == nil {
+		st.lastRunAt, st.lastRunID = &last, runID
+	}
+	var missed []time.Time
+	for at := s.next(last); !at.IsZero() && !at.After(now); at = s.next(at) {
+		missed = append(missed, at)
+		if len(missed) > maxMissedRuns {
+			missed = missed[1:]
+		}
+	}
+	return missed
+}
+
+// next returns the first time s fires after t, in its time zone.
+func (s jobSchedule) next(t time.Time) time.Time {
+	return s.cron.NextDST(t.In(s.loc), s.dst())
+}
+
+// dst returns the daylight saving policy of s with defaults applied.
+func (s jobSchedule) dst() cron.DSTPolicy {
+	p := cron.DSTPolicy{Skipped: cron.DSTSkip, Repeated: cron.DSTOnce}
+	if s.spec.DST != nil {
+		if s.spec.DST.Skipped != "" {
+			p.Skipped = s.spec.DST.Skipped
+		}
+		if s.spec.DST.Repeated != "" {
+			p.Repeated = s.spec.DST.Repeated
+		}
+	}
+	return p
+}
+
+// misfire returns the misfire policy of s.
+func (s jobSchedule) misfire() string {
+	if s.spec.Misfire == "" {
+		return types.MisfireSkip
+	}
+	return s.spec.Misfire
+}
+
+func (st *scheduleState) isEnabled(spec types.ScheduleConfig) bool {
+	if st.enabled != nil {
+		return *st.enabled
+	}
+	return spec.Enabled == nil || *spec.Enabled
+}
+
+// request builds the run request for s firing at at. The trigger block
+// records the schedule in the run's provenance.
+func (s jobSchedule) request(at time.Time, manual bool) runRequest {
+	timezone := s.spec.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	trigger := map[string]any{
+		"type":         "schedule",
+		"schedule":     s.id,
+		"cron":         s.spec.Cron,
+		"timezone":     timezone,
+		"scheduled_at": at.UTC().Format(time.RFC3339),
+	}
+	if manual {
+		trigger["manual"] = true
+	}
+	var labels map[string]string
+	if len(s.spec.Labels) > 0 {
+		labels = make(map[string]string, len(s.spec.Labels))
+		for k, v := range s.spec.Labels {
+			labels[k] = v
+		}
+	}
+	return runRequest{
+		JobID:      s.jobID,
+		Args:       cloneAnyMap(s.spec.Args),
+		Labels:     labels,
+		Trigger:    trigger,
+		ScheduleID: s.id,
+	}
+}
+
+// record stores the outcome of a run started for schedule id and logs it.
+func (h *SchedulesHandler) record(id string, at time.Time, run RunPayload, prob *response.Problem) {
+	h.mu.Lock()
+	if st, ok := h.state[id]; ok {
+		at := at.UTC()
+		st.lastRunAt = &at
+		st.lastRunID = run.ID
+		st.lastError = ""
+		if prob != nil {
+			st.lastError = problemDetail(*prob)
+		}
+	}
+	h.mu.Unlock()
+	if prob != nil {
+		h.cfg.Logger.Warn("schedule.run.failed",
+			slog.String("schedule", id),
+			slog.Int("status", prob.Status),
+			slog.String("error", problemDetail(*prob)))
+		return
+	}
+	h.cfg.Logger.Info("schedule.run.started",
+		slog.String("schedule", id),
+		slog.String("run_id", run.ID))
+}
+
+// ServeHTTP routes:
+//
+//	GET  /schedules
+//	GET  /schedules/{job_id}/{name}
+//	POST /schedules/{job_id}/{name}:enable
+//	POST /schedules/{job_id}/{name}:disable
+//	POST /schedules/{job_id}/{name}:trigger
+func (h *SchedulesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/schedules"), "/")
+	schedules, err := h.load()
+	if err != nil {
+		response.Write(w, response.New(http.StatusInternalServerError, "load schedules failed", response.WithDetail(err.Error())))
+		return
+	}
+	// Schedules of jobs outside the caller's grant are reported as not found.
+	schedules = visibleSchedules(r.Context(), schedules)
+	if path == "" {
+		if r.Method != http.MethodGet {
+			response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+			return
+		}
+		out := make([]SchedulePayload, 0, len(schedules))
+		for _, s := range schedules {
+			out = append(out, h.payload(s))
+		}
+		writeJSON(w, map[string]any{"schedules": out, "delayed_runs": h.cfg.Runs.delayedRuns(r.Context())}, http.StatusOK)
+		return
+	}
+
+	id, action, hasAction := strings.Cut(path, ":")
+	sched := findSchedule(schedules, id)
+	if sched == nil && !hasAction {
+		// Exact schedule IDs win, so a schedule named "runs" stays
+		// reachable; otherwise a trailing /runs lists a schedule's runs.
+		if scheduleID, ok := strings.CutSuffix(id, "/runs"); ok {
+			if s := findSchedule(schedules, scheduleID); s != nil {
+				h.handleRuns(w, r, *s)
+				return
+			}
+		}
+	}
+	if sched == nil {
+		response.Write(w, response.New(http.StatusNotFound, "schedule not found", response.WithDetail(id)))
+		return
+	}
+	if !hasAction {
+		if r.Method != http.MethodGet {
+			response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+			return
+		}
+		out := h.payload(*sched)
+		if out.NextRunAt != nil {
+			for at := *out.NextRunAt; !at.IsZero() && len(out.NextRunTimes) < upcomingRunTimes; at = sched.next(at) {
+				out.NextRunTimes = append(out.NextRunTimes, at.In(sched.loc))
+			}
+		}
+		writeJSON(w, out, http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+	switch action {
+	case "enable", "disable":
+		h.setEnabled(r, *sched, action == "enable")
+		writeJSON(w, h.payload(*sched), http.StatusOK)
+	case "trigger":
+		h.handleTrigger(w, r, *sched)
+	default:
+		response.Write(w, response.New(http.StatusNotFound, "not found"))
+	}
+}
+
+// visibleSchedules drops the schedules of jobs the caller may not see.
+func visibleSchedules(ctx context.Context, schedules []jobSchedule) []jobSchedule {
+	if requestctx.AccessFromContext(ctx) == nil {
+		return schedules
+	}
+	visible := schedules[:0:0]
+	for _, s := range schedules {
+		if jobVisible(ctx, s.jobID, "") {
+			visible = append(visible, s)
+		}
+	}
+	return visible
+}
+
+func findSchedule(schedules []jobSchedule, id string) *jobSchedule {
+	for i := range schedules {
+		if schedules[i].id == id {
+			return &schedules[i]
+		}
+	}
+	return nil
+}
+
+func (h *SchedulesHandler) setEnabled(r *http.Request, s jobSchedule, enabled bool) {
+	h.mu.Lock()
+	st := h.stateLocked(s)
+	st.enabled = &enabled
+	if !enabled {
+		st.next = time.Time{}
+	}
+	h.mu.Unlock()
+	msg := "schedule.disabled"
+	if enabled {
+		msg = "schedule.enabled"
+	}
+	if logger := requestctx.Logger(r.Context()); logger != nil {
+		principal, _ := requestctx.Principal(r.Context())
+		logger.Info(msg, slog.String("schedule", s.id), slog.String("principal", principal))
+	}
+}
+
+// handleTrigger starts a run of s now, whether or not it is enabled or the
+// scheduler is paused. It runs as the caller, so their scopes decide policy
+// checks such as high impact; an Idempotency-Key header deduplicates retries.
+func (h *SchedulesHandler) handleTrigger(w http.ResponseWriter, r *http.Request, s jobSchedule) {
+	at := h.cfg.Now()
+	req := s.request(at, true)
+	var (
+		run      RunPayload
+		replayed bool
+		prob     *response.Problem
+	)
+	if r.Header.Get("Idempotency-Key") != "" {
+		key, keyProb := requestIdempotencyKey(r)
+		if keyProb != nil {
+			response.Write(w, *keyProb)
+			return
+		}
+		run, replayed, prob = h.cfg.Runs.launchRunOnce(r.Context(), req, r.Method+" "+r.URL.Path, key)
+	} else {
+		run, prob = h.cfg.Runs.launchRun(r.Context(), req)
+	}
+	if !replayed {
+		h.record(s.id, at, run, prob)
+	}
+	if prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+	if replayed {
+		w.Header().Set("Idempotent-Replay", "true")
+		recordIdempotencyReplay(r.Context())
+	}
+	writeRunPayload(w, run, http.StatusCreated)
+}
+
+func (h *SchedulesHandler) payload(s jobSchedule) SchedulePayload {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	st := h.stateLocked(s)
+	out := SchedulePayload{
+		ID:        s.id,
+		JobID:     s.jobID,
+		Name:      s.spec.Name,
+		Cron:      s.spec.Cron,
+		Timezone:  s.loc.String(),
+		Enabled:   st.isEnabled(s.spec),
+		LastRunAt: st.lastRunAt,
+		LastRunID: st.lastRunID,
+		LastError: st.lastError,
+		Misfire:   s.misfire(),
+		DST:       types.ScheduleDST{Skipped: s.dst().Skipped, Repeated: s.dst().Repeated},
+		Args:      s.spec.Args,
+		Labels:    s.spec.Labels,
+	}
+	if out.Enabled {
+		next := st.next
+		if next.IsZero() {
+			next = s.next(h.cfg.Now())
+		}
+		if !next.IsZero() {
+			next = next.UTC()
+			out.NextRunAt = &next
+		}
+	}
+	return out
+}
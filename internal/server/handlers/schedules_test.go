@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/server/settings"
+)
+
+func writeScheduledJob(t *testing.T, root string) {
+	t.Helper()
+	writeJobConfig(t, root, "backup", `
+version: v1
+job:
+  id: backup
+  name: Backup
+interpreter: bash
+argspec:
+  args:
+    - name: target
+      type: string
+schedule:
+  - name: nightly
+    cron: "0 2 * * *"
+    timezone: Europe/Berlin
+    args:
+      target: s3
+    labels:
+      kind: nightly
+  - cron: "@hourly"
+    enabled: false
+`)
+	if err := os.WriteFile(filepath.Join(root, "backup", "100_main.sh"), []byte("echo \"$ARG_TARGET\"\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+}
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+func TestSchedulerStartsDueRunsWithProvenance(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Berlin"); err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	root := t.TempDir()
+	writeScheduledJob(t, root)
+	store := runstore.New()
+	runs := NewRunsHandler(RunsConfig{Root: root, Store: store, Events: &recordingSink{}})
+	clock := &fakeClock{now: time.Date(2025, time.June, 1, 23, 0, 0, 0, time.UTC)}
+	h := NewSchedulesHandler(SchedulesConfig{Runs: runs, Now: clock.Now})
+	ctx := context.Background()
+
+	if n := h.Tick(ctx); n != 0 {
+		t.Fatalf("expected first tick to only arm schedules, started %d", n)
+	}
+	// 02:00 in Berlin is 00:00 UTC in summer.
+	clock.Set(time.Date(2025, time.June, 2, 0, 0, 10, 0, time.UTC))
+	if n := h.Tick(ctx); n != 1 {
+		t.Fatalf("expected one scheduled run, started %d", n)
+	}
+	if n := h.Tick(ctx); n != 0 {
+		t.Fatalf("expected no second run for the same time, started %d", n)
+	}
+
+	list := store.List()
+	if len(list) != 1 {
+		t.Fatalf("expected one run, got %+v", list)
+	}
+	run := list[0]
+	if run.JobID != "backup" || run.Labels["kind"] != "nightly" {
+		t.Fatalf("unexpected run %+v", run)
+	}
+	trigger, _ := run.Provenance["trigger"].(map[string]any)
+	if trigger["type"] != "schedule" || trigger["schedule"] != "backup/nightly" || trigger["scheduled_at"] != "2025-06-02T00:00:00Z" {
+		t.Fatalf("unexpected trigger provenance %+v", run.Provenance["trigger"])
+	}
+	if run.Provenance["schedule_id"] != "backup/nightly" {
+		t.Fatalf("expected schedule_id in provenance, got %+v", run.Provenance)
+	}
+
+	_, view := pipelineRequest(t, h, http.MethodGet, "/schedules/backup/nightly", "", "")
+	if view["last_run_id"] != run.ID || view["next_run_at"] != "2025-06-03T00:00:00Z" || view["timezone"] != "Europe/Berlin" {
+		t.Fatalf("unexpected schedule view %+v", view)
+	}
+	store.Create(runstore.Run{ID: "other", JobID: "backup", Status: "completed", StartedAt: clock.Now()})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/schedules/backup/nightly/runs", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var scheduleRuns []RunPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &scheduleRuns); err != nil {
+		t.Fatalf("decode runs: %v", err)
+	}
+	if len(scheduleRuns) != 1 || scheduleRuns[0].ID != run.ID {
+		t.Fatalf("expected only the scheduled run, got %s", rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/schedules/backup/missing/runs", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown schedule, got %d", rec.Code)
+	}
+}
+
+func TestSchedulerHonoursPauseAndDisable(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "tick", `
+version: v1
+job:
+  id: tick
+  name: Tick
+interpreter: bash
+schedule:
+  - name: every-minute
+    cron: "* * * * *"
+`)
+	if err := os.WriteFile(filepath.Join(root, "tick", "100_main.sh"), []byte("true\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	store := runstore.New()
+	runs := NewRunsHandler(RunsConfig{Root: root, Store: store, Events: &recordingSink{}})
+	prefs := settings.New(settings.Values{})
+	start := time.Date(2025, time.June, 1, 12, 0, 30, 0, time.UTC)
+	clock := &fakeClock{now: start}
+	h := NewSchedulesHandler(SchedulesConfig{Runs: runs, Settings: prefs, Now: clock.Now})
+	ctx := context.Background()
+	h.Tick(ctx)
+
+	paused := true
+	if _, _, err := prefs.Apply(settings.Patch{SchedulerPaused: &paused}); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	clock.Set(start.Add(time.Minute))
+	if n := h.Tick(ctx); n != 0 {
+		t.Fatalf("expected paused scheduler to start nothing, started %d", n)
+	}
+	paused = false
+	if _, _, err := prefs.Apply(settings.Patch{SchedulerPaused: &paused}); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+
+	rec, view := pipelineRequest(t, h, http.MethodPost, "/schedules/tick/every-minute:disable", "alice", "")
+	if rec.Code != http.StatusOK || view["enabled"] != false {
+		t.Fatalf("expected schedule to be disabled, got %d: %s", rec.Code, rec.Body.String())
+	}
+	clock.Set(start.Add(2 * time.Minute))
+	if n := h.Tick(ctx); n != 0 {
+		t.Fatalf("expected disabled schedule to start nothing, started %d", n)
+	}
+
+	rec, _ = pipelineRequest(t, h, http.MethodPost, "/schedules/tick/every-minute:trigger", "alice", "")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected manual trigger to start a run, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(store.List()) != 1 {
+		t.Fatalf("expected one run, got %+v", store.List())
+	}
+	trigger, _ := store.List()[0].Provenance["trigger"].(map[string]any)
+	if trigger["manual"] != true {
+		t.Fatalf("expected manual trigger provenance, got %+v", trigger)
+	}
+}
+
+func TestSchedulesListAndUnknown(t *testing.T) {
+	root := t.TempDir()
+	writeScheduledJob(t, root)
+	h := NewSchedulesHandler(SchedulesConfig{
+		Runs: NewRunsHandler(RunsConfig{Root: root, Store: runstore.New()}),
+	})
+
+	rec, out := pipelineRequest(t, h, http.MethodGet, "/schedules", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	schedules, _ := out["schedules"].([]any)
+	if len(schedules) != 2 {
+		t.Fatalf("expected two schedules, got %s", rec.Body.String())
+	}
+	second := schedules[0].(map[string]any)
+	if second["id"] != "backup/2" || second["enabled"] != false || second["next_run_at"] != nil {
+		t.Fatalf("expected unnamed disabled schedule backup/2, got %+v", second)
+	}
+
+	rec, _ = pipelineRequest(t, h, http.MethodPost, "/schedules/backup/missing:trigger", "", "")
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "schedule not found") {
+		t.Fatalf("expected 404, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestSchedulesHonourAccess(t *testing.T) {
+	root := t.TempDir()
+	writeScheduledJob(t, root)
+	writeJobConfig(t, root, "report", `
+version: v1
+job:
+  id: report
+  name: Report
+interpreter: bash
+schedule:
+  - name: daily
+    cron: "0 6 * * *"
+`)
+	if err := os.WriteFile(filepath.Join(root, "report", "100_main.sh"), []byte("true\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	runs := NewRunsHandler(RunsConfig{Root: root, Store: runstore.New(), Events: &recordingSink{}})
+	startAt := time.Now().UTC().Add(time.Hour).Format(time.RFC3339)
+	for _, body := range []string{
+		`{"job_id":"backup","args":{"target":"s3"},"start_at":"` + startAt + `"}`,
+		`{"job_id":"report","start_at":"` + startAt + `"}`,
+	} {
+		if rec := postRun(t, runs, body); rec.Code != http.StatusCreated {
+			t.Fatalf("schedule run %s: expected 201, got %d: %s", body, rec.Code, rec.Body.String())
+		}
+	}
+	h := NewSchedulesHandler(SchedulesConfig{Runs: runs})
+	confined := func(method, path string) (*httptest.ResponseRecorder, map[string]any) {
+		req := httptest.NewRequest(method, path, nil)
+		req = req.WithContext(requestctx.WithAccess(req.Context(), jobsAccess{"report": true}))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		var out map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &out)
+		return rec, out
+	}
+
+	rec, out := confined(http.MethodGet, "/schedules")
+	schedules, _ := out["schedules"].([]any)
+	delayed, _ := out["delayed_runs"].([]any)
+	if rec.Code != http.StatusOK || len(schedules) != 1 || schedules[0].(map[string]any)["job_id"] != "report" {
+		t.Fatalf("expected only the report schedule, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(delayed) != 1 || delayed[0].(map[string]any)["job_id"] != "report" {
+		t.Fatalf("expected only the report run delayed, got %s", rec.Body.String())
+	}
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, "/schedules/backup/nightly"},
+		{http.MethodGet, "/schedules/backup/nightly/runs"},
+		{http.MethodPost, "/schedules/backup/nightly:disable"},
+		{http.MethodPost, "/schedules/backup/nightly:trigger"},
+	} {
+		if rec, _ := confined(req.method, req.path); rec.Code != http.StatusNotFound {
+			t.Fatalf("%s %s: expected 404, got %d: %s", req.method, req.path, rec.Code, rec.Body.String())
+		}
+	}
+	if rec, out := pipelineRequest(t, h, http.MethodGet, "/schedules/backup/nightly", "", ""); rec.Code != http.StatusOK || out["enabled"] != true {
+		t.Fatalf("expected backup/nightly still enabled, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestScheduleNextRunTimesAcrossDST(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Berlin"); err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	root := t.TempDir()
+	writeJobConfig(t, root, "report", `
+version: v1
+job:
+  id: report
+  name: Report
+interpreter: bash
+schedule:
+  - name: nightly
+    cron: "30 2 * * *"
+    timezone: Europe/Berlin
+    dst:
+      skipped: shift
+      repeated: twice
+`)
+	clock := &fakeClock{now: time.Date(2025, time.October, 24, 12, 0, 0, 0, time.UTC)}
+	h := NewSchedulesHandler(SchedulesConfig{
+		Runs: NewRunsHandler(RunsConfig{Root: root, Store: runstore.New()}),
+		Now:  clock.Now,
+	})
+
+	rec, out := pipelineRequest(t, h, http.MethodGet, "/schedules/report/nightly", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	dst, _ := out["dst"].(map[string]any)
+	if dst["skipped"] != "shift" || dst["repeated"] != "twice" {
+		t.Fatalf("expected resolved dst policy, got %+v", out["dst"])
+	}
+	var got []string
+	for _, at := range out["next_run_times"].([]any) {
+		got = append(got, at.(string))
+	}
+	want := []string{
+		"2025-10-25T02:30:00+02:00",
+		"2025-10-26T02:30:00+02:00",
+		"2025-10-26T02:30:00+01:00",
+		"2025-10-27T02:30:00+01:00",
+		"2025-10-28T02:30:00+01:00",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected next run times %v, got %v", want, got)
+	}
+
+	rec, _ = pipelineRequest(t, h, http.MethodGet, "/schedules", "", "")
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "next_run_times") {
+		t.Fatalf("expected list without next_run_times, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestSchedulerMisfirePolicies(t *testing.T) {
+	cases := []struct {
+		misfire string
+		want    []string
+	}{
+		{"", nil},
+		{"run-once", []string{"2025-06-01T12:04:00Z"}},
+		{"run-all-missed", []string{"2025-06-01T12:02:00Z", "2025-06-01T12:03:00Z", "2025-06-01T12:04:00Z"}},
+	}
+	for _, tc := range cases {
+		t.Run("misfire="+tc.misfire, func(t *testing.T) {
+			root := t.TempDir()
+			writeJobConfig(t, root, "tick", `
+version: v1
+job:
+  id: tick
+  name: Tick
+interpreter: bash
+schedule:
+  - name: every-minute
+    cron: "*/1 * * * *"
+    misfire: `+tc.misfire+`
+`)
+			if err := os.WriteFile(filepath.Join(root, "tick", "100_main.sh"), []byte("true\n"), 0o755); err != nil {
+				t.Fatalf("write script: %v", err)
+			}
+			// The last scheduled run before the server went down.
+			store := runstore.New()
+			store.Create(runstore.Run{
+				ID: "before", JobID: "tick", Status: "completed",
+				StartedAt: time.Date(2025, time.June, 1, 12, 1, 0, 0, time.UTC),
+				Provenance: map[string]any{"trigger": map[string]any{
+					"type": "schedule", "schedule": "tick/every-minute", "scheduled_at": "2025-06-01T12:01:00Z",
+				}},
+			})
+			runs := NewRunsHandler(RunsConfig{Root: root, Store: store, Events: &recordingSink{}})
+			clock := &fakeClock{now: time.Date(2025, time.June, 1, 12, 4, 30, 0, time.UTC)}
+			h := NewSchedulesHandler(SchedulesConfig{Runs: runs, Now: clock.Now})
+
+			if n := h.Tick(context.Background()); n != len(tc.want) {
+				t.Fatalf("expected %d catch-up runs, started %d", len(tc.want), n)
+			}
+			var got []string
+			for _, run := range store.List() {
+				if run.ID == "before" {
+					continue
+				}
+				trigger, _ := run.Provenance["trigger"].(map[string]any)
+				misfire, _ := trigger["misfire"].(map[string]any)
+				if misfire["policy"] != tc.misfire || misfire["missed"] != 3 {
+					t.Fatalf("unexpected misfire provenance %+v", trigger)
+				}
+				got = append(got, trigger["scheduled_at"].(string))
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+				t.Fatalf("expected runs for %v, got %v", tc.want, got)
+			}
+			if n := h.Tick(context.Background()); n != 0 {
+				t.Fatalf("expected missed times to be handled once, started %d", n)
+			}
+		})
+	}
+}
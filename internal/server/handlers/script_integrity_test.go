@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/executor"
+	"github.com/flowd-org/flowd/internal/paths"
+	"github.com/flowd-org/flowd/internal/policy"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+func writeHashedJob(t *testing.T, root, jobID string) string {
+	t.Helper()
+	writeJobConfig(t, root, jobID, `
+version: v1
+job:
+  id: `+jobID+`
+  name: Hashed Job
+interpreter: bash
+`)
+	script := filepath.Join(root, jobID, "100_main.sh")
+	if err := os.WriteFile(script, []byte("echo hashed\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	sum, err := executor.HashFile(script)
+	if err != nil {
+		t.Fatalf("hash script: %v", err)
+	}
+	return sum
+}
+
+func postRun(t *testing.T, h http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/runs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	addIdempotencyHeader(req)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRunRecordsScriptDigestsInPlanAndReceipt(t *testing.T) {
+	root := t.TempDir()
+	sum := writeHashedJob(t, root, "hashed")
+	store := runstore.New()
+	sink := &recordingSink{}
+	h := NewRunsHandler(RunsConfig{Root: root, Store: store, Events: sink})
+
+	rec := postRun(t, h, `{"job_id":"hashed"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var payload RunPayload
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode run: %v", err)
+	}
+	scripts, _ := payload.Provenance["scripts"].([]any)
+	if len(scripts) != 1 {
+		t.Fatalf("expected provenance.scripts, got %v", payload.Provenance)
+	}
+	if entry, _ := scripts[0].(map[string]any); entry["path"] != "100_main.sh" || entry["sha256"] != sum {
+		t.Fatalf("unexpected script digest %v", scripts[0])
+	}
+
+	waitFor(func() bool { return sink.countBy("run.finish") >= 1 }, 2*time.Second, t)
+	runDir := paths.RunDir(payload.ID)
+	var plan types.Plan
+	readJSONFile(t, filepath.Join(runDir, "plan.json"), &plan)
+	if len(plan.Scripts) != 1 || plan.Scripts[0].SHA256 != sum {
+		t.Fatalf("expected plan artifact to record script digest, got %+v", plan.Scripts)
+	}
+	var receipt types.RunReceipt
+	waitFor(func() bool {
+		_, err := os.Stat(filepath.Join(runDir, "receipt.json"))
+		return err == nil
+	}, 2*time.Second, t)
+	readJSONFile(t, filepath.Join(runDir, "receipt.json"), &receipt)
+	if receipt.RunID != payload.ID || receipt.Status != "completed" {
+		t.Fatalf("unexpected receipt %+v", receipt)
+	}
+	if len(receipt.Scripts) != 1 || receipt.Scripts[0].SHA256 != sum {
+		t.Fatalf("expected receipt to record script digest, got %+v", receipt.Scripts)
+	}
+}
+
+func TestRunRejectsScriptOutsideAllowList(t *testing.T) {
+	root := t.TempDir()
+	writeHashedJob(t, root, "unlisted")
+	policyCtx, err := policy.NewContext(&policy.Bundle{ScriptHashes: []string{strings.Repeat("0", 64)}})
+	if err != nil {
+		t.Fatalf("policy context: %v", err)
+	}
+	store := runstore.New()
+	h := NewRunsHandler(RunsConfig{Root: root, Store: store, Profile: "secure", Policy: policyCtx})
+
+	rec := postRun(t, h, `{"job_id":"unlisted"}`)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var problem map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &problem)
+	if problem["code"] != "script.hash.not.allowed" || problem["script"] != "100_main.sh" {
+		t.Fatalf("unexpected problem %v", problem)
+	}
+	if len(store.List()) != 0 {
+		t.Fatalf("expected no run persisted on script denial")
+	}
+
+	h = NewRunsHandler(RunsConfig{Root: root, Store: store, Events: &recordingSink{}, Profile: "permissive", Policy: policyCtx})
+	if rec := postRun(t, h, `{"job_id":"unlisted"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("expected allow-list to be skipped under permissive, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRunAllowsRegisteredScriptHash(t *testing.T) {
+	root := t.TempDir()
+	sum := writeHashedJob(t, root, "listed")
+	policyCtx, err := policy.NewContext(&policy.Bundle{ScriptHashes: []string{sum}})
+	if err != nil {
+		t.Fatalf("policy context: %v", err)
+	}
+	sink := &recordingSink{}
+	h := NewRunsHandler(RunsConfig{Root: root, Store: runstore.New(), Events: sink, Profile: "secure", Policy: policyCtx})
+
+	if rec := postRun(t, h, `{"job_id":"listed"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	waitFor(func() bool { return sink.countBy("run.finish") >= 1 }, 2*time.Second, t)
+}
+
+func readJSONFile(t *testing.T, path string, out any) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatalf("decode %s: %v", path, err)
+	}
+}
+
+func TestPlanCacheRehashesEditedScripts(t *testing.T) {
+	root := t.TempDir()
+	sum := writeHashedJob(t, root, "cached")
+	policyCtx, err := policy.NewContext(&policy.Bundle{ScriptHashes: []string{sum}})
+	if err != nil {
+		t.Fatalf("policy context: %v", err)
+	}
+	cache := NewPlanCache(PlanCacheConfig{})
+	h := NewPlansHandler(PlansConfig{Root: root, Cache: cache, Profile: "secure", Policy: policyCtx})
+	plan := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/plans", strings.NewReader(`{"job_id":"cached"}`))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := plan(); rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	if cache.Len() != 1 {
+		t.Fatalf("expected one cached plan, got %d", cache.Len())
+	}
+
+	// The config is untouched, so nothing purges the cache.
+	if err := os.WriteFile(filepath.Join(root, "cached", "100_main.sh"), []byte("echo tampered\n"), 0o755); err != nil {
+		t.Fatalf("edit script: %v", err)
+	}
+	rec := plan()
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected edited script to be refused, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var problem map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &problem)
+	if problem["code"] != "script.hash.not.allowed" {
+		t.Fatalf("unexpected problem %v", problem)
+	}
+}
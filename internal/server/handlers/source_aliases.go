@@ -0,0 +1,225 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/configloader"
+	"github.com/flowd-org/flowd/internal/indexer"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// Policies for add-on aliases that collide with existing jobs or aliases.
+const (
+	aliasConflictRefuse = "refuse"
+	aliasConflictPrefix = "prefix"
+)
+
+// Outcomes of importing one add-on alias.
+const (
+	aliasImported = "imported"
+	aliasPrefixed = "prefixed"
+	aliasConflict = "conflict"
+)
+
+// AddonAliasImport reports what adding an OCI source does with one alias of
+// its manifest.
+type AddonAliasImport struct {
+	// Name is the alias as declared by the manifest; Alias is the name it is
+	// imported under, which differs when it was prefixed.
+	Name   string `json:"name"`
+	Alias  string `json:"alias,omitempty"`
+	Target string `json:"target"`
+	Status string `json:"status"`
+	// ConflictsWith names the existing job or alias that Name collides with.
+	ConflictsWith *AliasConflictRef `json:"conflicts_with,omitempty"`
+}
+
+// AliasConflictRef identifies an existing job or alias.
+type AliasConflictRef struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Source string `json:"source,omitempty"`
+}
+
+// SourcePreview is the response of POST /sources?dry_run=true.
+type SourcePreview struct {
+	DryRun        bool               `json:"dry_run"`
+	Name          string             `json:"name"`
+	Type          string             `json:"type"`
+	Ref           string             `json:"ref"`
+	Digest        string             `json:"digest,omitempty"`
+	Manifest      map[string]any     `json:"manifest,omitempty"`
+	AliasConflict string             `json:"alias_conflict"`
+	Aliases       []AddonAliasImport `json:"aliases"`
+	// Conflicts counts the aliases that would make the add fail.
+	Conflicts int `json:"conflicts"`
+}
+
+// parseAliasImportOptions validates alias_conflict and reads the dry_run
+// query parameter into req.
+func parseAliasImportOptions(r *http.Request, req *sourceRequest) *response.Problem {
+	switch policy := strings.ToLower(strings.TrimSpace(req.AliasConflict)); policy {
+	case "":
+		req.AliasConflict = aliasConflictRefuse
+	case aliasConflictRefuse, aliasConflictPrefix:
+		req.AliasConflict = policy
+	default:
+		prob := response.New(http.StatusBadRequest, "invalid alias_conflict",
+			response.WithExtension("code", "source.alias_conflict.invalid"),
+			response.WithDetail(fmt.Sprintf("alias_conflict %q is not supported; use refuse or prefix", req.AliasConflict)))
+		return &prob
+	}
+	raw := strings.TrimSpace(r.URL.Query().Get("dry_run"))
+	if raw == "" {
+		return nil
+	}
+	dryRun, err := strconv.ParseBool(raw)
+	if err != nil {
+		prob := response.New(http.StatusBadRequest, "invalid dry_run", response.WithDetail(err.Error()))
+		return &prob
+	}
+	if dryRun && req.Type != "oci" {
+		prob := response.New(http.StatusBadRequest, "dry run unsupported",
+			response.WithExtension("code", "source.dry_run.unsupported"),
+			response.WithDetail("dry_run previews are only available for oci sources"))
+		return &prob
+	}
+	req.DryRun = dryRun
+	return nil
+}
+
+// planAddonAliases decides under which names the manifest's aliases are
+// imported for source name. Names already taken by a job or alias of the
+// scripts root or of another source are conflicts; under the prefix policy
+// they are retried as <source>-<alias>. It returns the per-alias outcomes, the
+// aliases to store on the source and the number of conflicts left.
+func planAddonAliases(cfg SourcesConfig, name string, manifest *addonManifest, policy string) ([]AddonAliasImport, []types.CommandAlias, int) {
+	if manifest == nil || len(manifest.Aliases) == 0 {
+		return []AddonAliasImport{}, nil, 0
+	}
+	taken := existingJobNames(cfg, name)
+	imports := make([]AddonAliasImport, 0, len(manifest.Aliases))
+	var (
+		aliases   []types.CommandAlias
+		conflicts int
+	)
+	for _, alias := range manifest.Aliases {
+		aliasName := strings.TrimSpace(alias.Name)
+		entry := AddonAliasImport{
+			Name:   aliasName,
+			Target: composeOCIJobID(name, strings.TrimSpace(alias.Job)),
+			Status: aliasImported,
+		}
+		imported := aliasName
+		if ref, ok := taken[strings.ToLower(aliasName)]; ok {
+			refCopy := ref
+			entry.ConflictsWith = &refCopy
+			entry.Status = aliasConflict
+			imported = ""
+			if policy == aliasConflictPrefix {
+				prefixed := name + "-" + aliasName
+				if _, clash := taken[strings.ToLower(prefixed)]; !clash {
+					entry.Status = aliasPrefixed
+					imported = prefixed
+				}
+			}
+		}
+		if imported == "" {
+			conflicts++
+		} else {
+			entry.Alias = imported
+			taken[strings.ToLower(imported)] = AliasConflictRef{Kind: "alias", ID: imported, Source: name}
+			aliases = append(aliases, types.CommandAlias{From: entry.Target, To: imported, Description: alias.Description})
+		}
+		imports = append(imports, entry)
+	}
+	return imports, aliases, conflicts
+}
+
+// existingJobNames indexes, by lower-cased name, the jobs and aliases of the
+// scripts root and of every source except the one named skip, which is about
+// to be replaced. Discovery failures leave a target out rather than failing
+// the add.
+func existingJobNames(cfg SourcesConfig, skip string) map[string]AliasConflictRef {
+	taken := make(map[string]AliasConflictRef)
+	add := func(kind, id, source string) {
+		key := strings.ToLower(strings.TrimSpace(id))
+		if key == "" {
+			return
+		}
+		if _, ok := taken[key]; !ok {
+			taken[key] = AliasConflictRef{Kind: kind, ID: id, Source: source}
+		}
+	}
+	targets, err := resolveJobTargets(cfg.Root, cfg.Store)
+	if err != nil {
+		return taken
+	}
+	for _, target := range targets {
+		if target.source == nil {
+			if aliases, err := configloader.LoadAliases(target.root); err == nil {
+				for _, alias := range aliases {
+					add("alias", alias.To, "")
+				}
+			}
+			if result, err := indexer.Discover(target.root); err == nil {
+				for _, job := range result.Jobs {
+					add("job", job.ID, "")
+				}
+			}
+			continue
+		}
+		src := *target.source
+		if src.Name == skip {
+			continue
+		}
+		for _, alias := range src.Aliases {
+			add("alias", alias.To, src.Name)
+		}
+		if strings.EqualFold(src.Type, "oci") {
+			if manifest, err := loadAddonManifestFromSource(src); err == nil {
+				for _, job := range manifest.Jobs {
+					add("job", composeOCIJobID(src.Name, job.ID), src.Name)
+				}
+			}
+			continue
+		}
+		if result, err := indexer.Discover(target.root); err == nil {
+			for _, job := range result.Jobs {
+				add("job", job.ID, src.Name)
+			}
+		}
+	}
+	return taken
+}
+
+// aliasConflictProblem refuses an add whose aliases collide.
+func aliasConflictProblem(name string, imports []AddonAliasImport) response.Problem {
+	conflicting := make([]AddonAliasImport, 0, len(imports))
+	for _, entry := range imports {
+		if entry.Status == aliasConflict {
+			conflicting = append(conflicting, entry)
+		}
+	}
+	return response.New(http.StatusConflict, "alias conflict",
+		response.WithExtension("code", "source.alias_conflict"),
+		response.WithExtension("conflicts", conflicting),
+		response.WithDetail(fmt.Sprintf("%d aliases of source %s collide with existing jobs or aliases; preview with dry_run=true or retry with alias_conflict=prefix", len(conflicting), name)))
+}
+
+func writeSourcePreview(w http.ResponseWriter, preview SourcePreview) {
+	data, err := json.Marshal(preview)
+	if err != nil {
+		response.Write(w, response.New(http.StatusInternalServerError, "encode preview failed", response.WithDetail(err.Error())))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
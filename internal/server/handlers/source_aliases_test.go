@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/executor/container"
+	"github.com/flowd-org/flowd/internal/policy"
+	policyverify "github.com/flowd-org/flowd/internal/policy/verify"
+	"github.com/flowd-org/flowd/internal/server/sourcestore"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+const aliasAddonManifest = `
+apiVersion: flwd.addon/v1
+kind: AddOn
+metadata:
+  name: Example Addon
+  id: example.addon
+  version: 1.2.3
+requires: {}
+jobs:
+  - id: example.job
+    name: Example Job
+    summary: Demo
+    argspec:
+      args: []
+aliases:
+  - name: build
+    job: example.job
+  - name: deploy
+    job: example.job
+  - name: lint
+    job: example.job
+    description: Lint the tree
+`
+
+// newAliasImportHandler serves POST /sources against a scripts root holding
+// a build job and a local source that already defines a deploy alias.
+func newAliasImportHandler(t *testing.T, store *sourcestore.Store) (http.Handler, string) {
+	t.Helper()
+	root := t.TempDir()
+	writeJobConfig(t, root, "build", `
+version: v1
+job:
+  id: build
+  name: Build
+interpreter: bash
+`)
+	store.Upsert(sourcestore.Source{
+		Name:      "tools",
+		Type:      "local",
+		LocalPath: t.TempDir(),
+		Aliases:   []types.CommandAlias{{From: "tools/release", To: "deploy"}},
+	})
+	policyCtx, err := policy.NewContext(nil)
+	if err != nil {
+		t.Fatalf("policy context: %v", err)
+	}
+	withOCIRuntimeStub(t, func(ctx context.Context, runtime container.Runtime, args ...string) ([]byte, error) {
+		switch {
+		case len(args) >= 1 && args[0] == "pull":
+			return []byte("pulled"), nil
+		case len(args) >= 1 && args[0] == "run":
+			return []byte(aliasAddonManifest), nil
+		case len(args) >= 2 && args[0] == "image" && args[1] == "inspect":
+			return ociInspectPayloadWithDigest("sha256:abc123"), nil
+		}
+		t.Fatalf("unexpected runtime args: %v", args)
+		return nil, nil
+	})
+	checkout := filepath.Join(t.TempDir(), "sources")
+	return NewSourcesHandler(SourcesConfig{
+		Store:       store,
+		Profile:     "secure",
+		Policy:      policyCtx,
+		Verifier:    &stubImageVerifier{result: policyverify.Result{Verified: true}},
+		Runtime:     container.Runtime("podman"),
+		CheckoutDir: checkout,
+		Root:        root,
+	}), checkout
+}
+
+func postSource(h http.Handler, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSourcesHandlerOCIAliasDryRunPreviewsConflicts(t *testing.T) {
+	store := sourcestore.New()
+	h, checkout := newAliasImportHandler(t, store)
+
+	rec := postSource(h, "/sources?dry_run=true", `{"type":"oci","ref":"ghcr.io/example/addon:1.2.3","trusted":true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var preview SourcePreview
+	if err := json.Unmarshal(rec.Body.Bytes(), &preview); err != nil {
+		t.Fatalf("decode preview: %v", err)
+	}
+	if !preview.DryRun || preview.Name != "addon" || preview.AliasConflict != "refuse" || preview.Conflicts != 2 {
+		t.Fatalf("unexpected preview %+v", preview)
+	}
+	want := []struct{ name, status, kind string }{
+		{"build", "conflict", "job"},
+		{"deploy", "conflict", "alias"},
+		{"lint", "imported", ""},
+	}
+	if len(preview.Aliases) != len(want) {
+		t.Fatalf("expected %d aliases, got %+v", len(want), preview.Aliases)
+	}
+	for i, w := range want {
+		got := preview.Aliases[i]
+		if got.Name != w.name || got.Status != w.status || got.Target != "addon/example.job" {
+			t.Fatalf("alias %d: expected %s %s, got %+v", i, w.name, w.status, got)
+		}
+		if (got.ConflictsWith == nil) != (w.kind == "") || (got.ConflictsWith != nil && got.ConflictsWith.Kind != w.kind) {
+			t.Fatalf("alias %d: expected conflict with %q, got %+v", i, w.kind, got.ConflictsWith)
+		}
+	}
+	if preview.Aliases[1].ConflictsWith.Source != "tools" {
+		t.Fatalf("expected deploy to collide with the tools source, got %+v", preview.Aliases[1].ConflictsWith)
+	}
+	if _, ok := store.Get("addon"); ok {
+		t.Fatal("dry run must not store the source")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(checkout), ociCacheDirName, "addon")); !os.IsNotExist(err) {
+		t.Fatalf("dry run must not cache the manifest, stat err %v", err)
+	}
+}
+
+func TestSourcesHandlerOCIAliasConflictRefused(t *testing.T) {
+	store := sourcestore.New()
+	h, _ := newAliasImportHandler(t, store)
+
+	rec := postSource(h, "/sources", `{"type":"oci","ref":"ghcr.io/example/addon:1.2.3","trusted":true}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var problem struct {
+		Code      string             `json:"code"`
+		Conflicts []AddonAliasImport `json:"conflicts"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if problem.Code != "source.alias_conflict" || len(problem.Conflicts) != 2 {
+		t.Fatalf("unexpected problem %s", rec.Body.String())
+	}
+	if _, ok := store.Get("addon"); ok {
+		t.Fatal("refused source must not be stored")
+	}
+}
+
+func TestSourcesHandlerOCIAliasConflictPrefixed(t *testing.T) {
+	store := sourcestore.New()
+	h, _ := newAliasImportHandler(t, store)
+
+	rec := postSource(h, "/sources", `{"type":"oci","ref":"ghcr.io/example/addon:1.2.3","trusted":true,"alias_conflict":"prefix"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	src, ok := store.Get("addon")
+	if !ok {
+		t.Fatal("expected source stored")
+	}
+	want := []types.CommandAlias{
+		{From: "addon/example.job", To: "addon-build"},
+		{From: "addon/example.job", To: "addon-deploy"},
+		{From: "addon/example.job", To: "lint", Description: "Lint the tree"},
+	}
+	if len(src.Aliases) != len(want) {
+		t.Fatalf("expected aliases %+v, got %+v", want, src.Aliases)
+	}
+	for i := range want {
+		if src.Aliases[i] != want[i] {
+			t.Fatalf("alias %d: expected %+v, got %+v", i, want[i], src.Aliases[i])
+		}
+	}
+}
+
+func TestSourcesHandlerAliasImportOptionsValidated(t *testing.T) {
+	store := sourcestore.New()
+	h, _ := newAliasImportHandler(t, store)
+
+	rec := postSource(h, "/sources", `{"type":"oci","ref":"ghcr.io/example/addon:1.2.3","trusted":true,"alias_conflict":"merge"}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "source.alias_conflict.invalid") {
+		t.Fatalf("expected invalid alias_conflict, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = postSource(h, "/sources?dry_run=true", `{"type":"local","ref":"."}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "source.dry_run.unsupported") {
+		t.Fatalf("expected dry run to be refused for local sources, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAddonManifestAliasesValidated(t *testing.T) {
+	manifest := strings.Replace(aliasAddonManifest, "  - name: lint\n    job: example.job", "  - name: lint/all\n    job: missing.job", 1)
+	_, errs, err := parseAndValidateAddonManifest([]byte(manifest))
+	if err != nil {
+		t.Fatalf("parse manifest: %v", err)
+	}
+	joined := strings.Join(errs, "; ")
+	if !strings.Contains(joined, `aliases[2].name "lint/all" must be single-level`) || !strings.Contains(joined, `aliases[2].job "missing.job"`) {
+		t.Fatalf("expected alias validation errors, got %v", errs)
+	}
+}
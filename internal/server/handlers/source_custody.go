@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/sourcestore"
+)
+
+// sourceCustody links a run to the exact state of its source checkout at
+// execution time, as opposed to the state recorded when the source was
+// registered.
+type sourceCustody struct {
+	Source       string
+	Type         string
+	CheckoutPath string
+	// Registered is the commit (git) or manifest digest (oci) recorded at
+	// registration; Observed is the same value re-read before execution.
+	Registered   string
+	Observed     string
+	Drifted      bool
+	Dirty        bool
+	DriftReason  string
+	Trust        map[string]any
+	Verification map[string]any
+	VerifiedAt   time.Time
+}
+
+// inspectSourceCustody re-reads the checkout of src and compares it with the
+// registration record.
+func inspectSourceCustody(ctx context.Context, src sourcestore.Source, now time.Time) sourceCustody {
+	custody := sourceCustody{
+		Source:       src.Name,
+		Type:         src.Type,
+		CheckoutPath: src.LocalPath,
+		Trust:        cloneTrust(src.Trust),
+		Verification: sourceVerification(src),
+		VerifiedAt:   now,
+	}
+	switch src.Type {
+	case "git":
+		custody.Registered = src.ResolvedCommit
+		head, err := runGit(ctx, src.LocalPath, "rev-parse", "HEAD")
+		if err != nil {
+			custody.Drifted = true
+			custody.DriftReason = "checkout unreadable: " + err.Error()
+			return custody
+		}
+		custody.Observed = head
+		if custody.Registered != "" && head != custody.Registered {
+			custody.Drifted = true
+			custody.DriftReason = fmt.Sprintf("HEAD %s does not match registered commit %s", head, custody.Registered)
+			return custody
+		}
+		status, err := runGit(ctx, src.LocalPath, "status", "--porcelain")
+		if err != nil {
+			custody.Drifted = true
+			custody.DriftReason = "checkout unreadable: " + err.Error()
+			return custody
+		}
+		if status != "" {
+			custody.Drifted = true
+			custody.Dirty = true
+			custody.DriftReason = "working tree has uncommitted changes"
+		}
+	case "oci":
+		manifestPath, _ := src.Metadata["manifest_path"].(string)
+		custody.Registered, _ = src.Metadata["manifest_sha256"].(string)
+		if manifestPath == "" {
+			return custody
+		}
+		data, err := os.ReadFile(manifestPath)
+		if err != nil {
+			custody.Drifted = true
+			custody.DriftReason = "cached manifest unreadable: " + err.Error()
+			return custody
+		}
+		custody.Observed = sha256Hex(data)
+		if custody.Registered != "" && custody.Observed != custody.Registered {
+			custody.Drifted = true
+			custody.DriftReason = "cached manifest changed since registration"
+		}
+	}
+	return custody
+}
+
+// sourceVerification reports the verification outcome recorded for src.
+func sourceVerification(src sourcestore.Source) map[string]any {
+	out := map[string]any{"verify_signatures": src.VerifySignatures}
+	if trust, ok := src.Metadata["image_trust"].(map[string]any); ok {
+		for k, v := range trust {
+			out[k] = v
+		}
+	}
+	if src.Digest != "" {
+		out["digest"] = src.Digest
+	}
+	return out
+}
+
+// provenance renders the custody record for the run provenance map.
+func (c sourceCustody) provenance() map[string]any {
+	out := map[string]any{
+		"source":        c.Source,
+		"type":          c.Type,
+		"checkout_path": c.CheckoutPath,
+		"verified_at":   c.VerifiedAt.UTC().Format(time.RFC3339),
+		"drifted":       c.Drifted,
+		"verification":  c.Verification,
+	}
+	key := "commit"
+	if c.Type == "oci" {
+		key = "manifest_sha256"
+	}
+	if c.Registered != "" {
+		out["registered_"+key] = c.Registered
+	}
+	if c.Observed != "" {
+		out["observed_"+key] = c.Observed
+	}
+	if c.Dirty {
+		out["dirty"] = true
+	}
+	if c.DriftReason != "" {
+		out["drift_reason"] = c.DriftReason
+	}
+	if len(c.Trust) > 0 {
+		out["trust"] = c.Trust
+	}
+	return out
+}
+
+// checkoutDriftedProblem refuses a run whose source checkout no longer matches
+// its registration.
+func checkoutDriftedProblem(c sourceCustody) response.Problem {
+	opts := []response.Option{
+		response.WithType("https://flowd.dev/problems/source-checkout-drifted"),
+		response.WithExtension("code", "source.checkout.drifted"),
+		response.WithExtension("source", c.Source),
+		response.WithDetail(c.DriftReason),
+	}
+	if c.Registered != "" {
+		opts = append(opts, response.WithExtension("registered", c.Registered))
+	}
+	if c.Observed != "" {
+		opts = append(opts, response.WithExtension("observed", c.Observed))
+	}
+	return response.New(http.StatusConflict, "source checkout drifted", opts...)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
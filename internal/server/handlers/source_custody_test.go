@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/server/sourcestore"
+)
+
+func registerGitSource(t *testing.T, jobID string) (*sourcestore.Store, string, string) {
+	t.Helper()
+	repo, commit := createGitJobRepo(t, jobID, "")
+	repoURL := url.URL{Scheme: "file", Path: filepath.ToSlash(repo)}
+	store := sourcestore.New()
+	checkoutDir := filepath.Join(t.TempDir(), "checkouts")
+	sourcesHandler := NewSourcesHandler(SourcesConfig{
+		Store:           store,
+		AllowLocalRoots: []string{repo},
+		CheckoutDir:     checkoutDir,
+	})
+	body := `{"type":"git","name":"custody","url":"` + repoURL.String() + `","ref":"main","trust":{"owner":"platform"}}`
+	req := httptest.NewRequest(http.MethodPost, "/sources", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	sourcesHandler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("register git source: %d %s", rec.Code, rec.Body.String())
+	}
+	return store, filepath.Join(checkoutDir, "custody"), commit
+}
+
+func submitSourceRun(t *testing.T, h *RunsHandler, jobID string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/runs", strings.NewReader(`{"job_id":"`+jobID+`","args":{"name":"Dana"},"source":{"name":"custody"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	addIdempotencyHeader(req)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	var payload map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &payload)
+	return rec.Code, payload
+}
+
+func TestRunProvenanceRecordsSourceCustody(t *testing.T) {
+	store, checkout, commit := registerGitSource(t, "custodyjob")
+	sink := &recordingSink{}
+	h := NewRunsHandler(RunsConfig{Root: t.TempDir(), Store: runstore.New(), Events: sink, Sources: store})
+
+	code, payload := submitSourceRun(t, h, "custodyjob")
+	if code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %v", code, payload)
+	}
+	prov, _ := payload["provenance"].(map[string]any)
+	custody, ok := prov["custody"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected provenance.custody, got %v", prov)
+	}
+	if custody["registered_commit"] != commit || custody["observed_commit"] != commit {
+		t.Fatalf("expected registered and observed commit %s, got %v", commit, custody)
+	}
+	if custody["drifted"] != false {
+		t.Fatalf("expected no drift, got %v", custody)
+	}
+	if custody["checkout_path"] != checkout {
+		t.Fatalf("expected checkout path %s, got %v", checkout, custody["checkout_path"])
+	}
+	trust, _ := custody["trust"].(map[string]any)
+	if trust["owner"] != "platform" {
+		t.Fatalf("expected source trust metadata, got %v", custody["trust"])
+	}
+	waitFor(func() bool { return sink.countBy("run.finish") >= 1 }, 2*time.Second, t)
+}
+
+func TestRunProvenanceDetectsMovedCheckout(t *testing.T) {
+	store, checkout, commit := registerGitSource(t, "movedjob")
+	runGitTest(t, checkout, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "--allow-empty", "-m", "local change")
+	sink := &recordingSink{}
+	h := NewRunsHandler(RunsConfig{Root: t.TempDir(), Store: runstore.New(), Events: sink, Sources: store, Profile: "permissive"})
+
+	code, payload := submitSourceRun(t, h, "movedjob")
+	if code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %v", code, payload)
+	}
+	prov, _ := payload["provenance"].(map[string]any)
+	custody, _ := prov["custody"].(map[string]any)
+	if custody["drifted"] != true || custody["observed_commit"] == commit {
+		t.Fatalf("expected drift to be recorded, got %v", custody)
+	}
+	waitFor(func() bool { return sink.countBy("run.finish") >= 1 }, 2*time.Second, t)
+}
+
+func TestRunRefusesDirtyCheckoutUnderSecureProfile(t *testing.T) {
+	store, checkout, _ := registerGitSource(t, "dirtyjob")
+	script := filepath.Join(checkout, "scripts", "dirtyjob", "100_main.sh")
+	if err := os.WriteFile(script, []byte("#!/usr/bin/env bash\necho tampered\n"), 0o755); err != nil {
+		t.Fatalf("modify script: %v", err)
+	}
+	runs := runstore.New()
+	h := NewRunsHandler(RunsConfig{Root: t.TempDir(), Store: runs, Events: &recordingSink{}, Sources: store})
+
+	code, payload := submitSourceRun(t, h, "dirtyjob")
+	if code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %v", code, payload)
+	}
+	if payload["code"] != "source.checkout.drifted" {
+		t.Fatalf("expected source.checkout.drifted, got %v", payload)
+	}
+	if payload["source"] != "custody" {
+		t.Fatalf("expected source extension, got %v", payload)
+	}
+	if len(runs.List()) != 0 {
+		t.Fatalf("expected no run to be recorded")
+	}
+}
+
+func TestRunRecordsDirtyCheckoutUnderPermissiveProfile(t *testing.T) {
+	store, checkout, _ := registerGitSource(t, "dirtyokjob")
+	if err := os.WriteFile(filepath.Join(checkout, "untracked.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write untracked file: %v", err)
+	}
+	sink := &recordingSink{}
+	h := NewRunsHandler(RunsConfig{Root: t.TempDir(), Store: runstore.New(), Events: sink, Sources: store, Profile: "permissive"})
+
+	code, payload := submitSourceRun(t, h, "dirtyokjob")
+	if code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %v", code, payload)
+	}
+	prov, _ := payload["provenance"].(map[string]any)
+	custody, _ := prov["custody"].(map[string]any)
+	if custody["drifted"] != true || custody["dirty"] != true {
+		t.Fatalf("expected dirty checkout to be recorded, got %v", custody)
+	}
+	waitFor(func() bool { return sink.countBy("run.finish") >= 1 }, 2*time.Second, t)
+}
@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/configloader"
+	"github.com/flowd-org/flowd/internal/indexer"
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/sourcestore"
+)
+
+const sourceJobConfigContentType = "application/yaml"
+
+// splitSourceJobConfigPath splits "{name}/jobs/{job}/config" into the source
+// name and job ID. Job IDs may contain slashes.
+func splitSourceJobConfigPath(rest string) (name, jobID string, ok bool) {
+	name, tail, found := strings.Cut(rest, "/jobs/")
+	if !found || name == "" || strings.Contains(name, "/") {
+		return "", "", false
+	}
+	jobID, found = strings.CutSuffix(tail, "/config")
+	if !found || jobID == "" {
+		return "", "", false
+	}
+	return name, jobID, true
+}
+
+// serveSourceJobConfig handles GET and PUT /sources/{name}/jobs/{job}/config.
+// GET returns the job's config.d/config.yaml; PUT replaces it on local
+// sources exposed readwrite. Writes are validated with the config loader,
+// honor If-Match against the ETag of the current file and purge cached plans
+// so the next plan or run sees the edit.
+func serveSourceJobConfig(w http.ResponseWriter, r *http.Request, cfg SourcesConfig, name, jobID string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
+		response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+	src, ok := cfg.Store.Get(name)
+	if !ok {
+		response.Write(w, response.New(http.StatusNotFound, "source not found", response.WithDetail(name)))
+		return
+	}
+	if !strings.EqualFold(src.Type, "local") || src.LocalPath == "" {
+		response.Write(w, response.New(http.StatusConflict, "source not writable",
+			response.WithExtension("code", "source.not_local"),
+			response.WithDetail("only local sources hold editable job configs")))
+		return
+	}
+	if r.Method == http.MethodPut && src.Expose != "readwrite" {
+		response.Write(w, response.New(http.StatusForbidden, "source not writable",
+			response.WithExtension("code", "source.read_only"),
+			response.WithDetail(fmt.Sprintf("source %s is exposed %q; set expose to readwrite to allow edits", name, src.Expose))))
+		return
+	}
+	configPath, prob := locateSourceJobConfig(src, jobID)
+	if prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+	current, err := os.ReadFile(configPath)
+	if err != nil {
+		response.Write(w, response.New(http.StatusInternalServerError, "read job config failed", response.WithDetail(err.Error())))
+		return
+	}
+	etag := configETag(current)
+
+	if r.Method == http.MethodGet {
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", sourceJobConfigContentType)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(current)
+		return
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || !isYAMLMediaType(mediaType) {
+		response.Write(w, response.New(http.StatusUnsupportedMediaType, "unsupported media type",
+			response.WithDetail("send the config as application/yaml")))
+		return
+	}
+	if match := r.Header.Get("If-Match"); match != "" && !etagMatches(match, etag) {
+		response.Write(w, response.New(http.StatusPreconditionFailed, "job config changed",
+			response.WithExtension("code", "config.conflict"),
+			response.WithDetail("the config was modified since it was read; fetch it again and reapply the edit")))
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Write(w, response.New(http.StatusBadRequest, "read body failed", response.WithDetail(err.Error())))
+		return
+	}
+	if err := validateJobConfig(body); err != nil {
+		response.Write(w, response.New(http.StatusUnprocessableEntity, "invalid job config",
+			response.WithExtension("code", "config.invalid"),
+			response.WithDetail(err.Error())))
+		return
+	}
+	if err := writeFileAtomic(configPath, body); err != nil {
+		response.Write(w, response.New(http.StatusInternalServerError, "write job config failed", response.WithDetail(err.Error())))
+		return
+	}
+	// Re-index the source; an edit that renames the job away is rolled back
+	// so the URL the editor used keeps resolving.
+	if _, prob := locateSourceJobConfig(src, jobID); prob != nil {
+		if err := writeFileAtomic(configPath, current); err != nil {
+			response.Write(w, response.New(http.StatusInternalServerError, "restore job config failed", response.WithDetail(err.Error())))
+			return
+		}
+		response.Write(w, response.New(http.StatusUnprocessableEntity, "invalid job config",
+			response.WithExtension("code", "config.invalid"),
+			response.WithDetail(fmt.Sprintf("the edited config no longer declares job %s", jobID))))
+		return
+	}
+	cfg.PlanCache.Purge()
+	if logger := requestctx.Logger(r.Context()); logger != nil {
+		logger.Info("source.job_config.updated", "source", name, "job", jobID)
+	}
+	w.Header().Set("ETag", configETag(body))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// locateSourceJobConfig discovers the source's jobs and returns the config
+// file declaring jobID. The resolved file must stay inside the source.
+func locateSourceJobConfig(src sourcestore.Source, jobID string) (string, *response.Problem) {
+	result, err := indexer.Discover(src.LocalPath)
+	if err != nil {
+		prob := response.New(http.StatusInternalServerError, "job discovery failed", response.WithDetail(err.Error()))
+		return "", &prob
+	}
+	for _, job := range result.Jobs {
+		if job.ID != jobID {
+			continue
+		}
+		configPath := filepath.Join(job.Path, "config.yaml")
+		resolved, err := filepath.EvalSymlinks(configPath)
+		if err != nil {
+			prob := response.New(http.StatusInternalServerError, "resolve job config failed", response.WithDetail(err.Error()))
+			return "", &prob
+		}
+		root, err := filepath.EvalSymlinks(src.LocalPath)
+		if err != nil || !isSubPath(resolved, root) {
+			prob := response.New(http.StatusForbidden, "source not writable",
+				response.WithExtension("code", "source.outside_root"),
+				response.WithDetail("job config resolves outside the source"))
+			return "", &prob
+		}
+		return resolved, nil
+	}
+	prob := response.New(http.StatusNotFound, "job not found", response.WithDetail(jobID))
+	return "", &prob
+}
+
+// validateJobConfig loads data with the same loader runs use.
+func validateJobConfig(data []byte) error {
+	dir, err := os.MkdirTemp("", "flwd-config-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Mkdir(filepath.Join(dir, "config.d"), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.d", "config.yaml"), data, 0o600); err != nil {
+		return err
+	}
+	_, err = configloader.LoadConfig(dir)
+	return err
+}
+
+// writeFileAtomic replaces path through a rename so readers never see a
+// partial config. The file keeps its permissions.
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func configETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+func isYAMLMediaType(mediaType string) bool {
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/server/sourcestore"
+)
+
+const demoJobConfig = `
+version: v1
+job:
+  id: demo
+  name: Demo Job
+interpreter: bash
+`
+
+func TestSourceJobConfigReadWrite(t *testing.T) {
+	root := t.TempDir()
+	writeJobConfig(t, root, "demo", demoJobConfig)
+	configPath := filepath.Join(root, "demo", "config.d", "config.yaml")
+
+	store := sourcestore.New()
+	store.Upsert(sourcestore.Source{Name: "local", Type: "local", LocalPath: root, Expose: "readwrite"})
+	store.Upsert(sourcestore.Source{Name: "ro", Type: "local", LocalPath: root, Expose: "read"})
+	store.Upsert(sourcestore.Source{Name: "remote", Type: "git", LocalPath: root, Expose: "readwrite"})
+	handler := NewSourceGetHandler(SourcesConfig{Store: store, PlanCache: NewPlanCache(PlanCacheConfig{})})
+
+	do := func(method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/yaml")
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/sources/local/jobs/demo/config", "", nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Demo Job") {
+		t.Fatalf("expected config, got %d: %s", rec.Code, rec.Body.String())
+	}
+	etag := rec.Header().Get("ETag")
+
+	edited := strings.Replace(demoJobConfig, "Demo Job", "Edited Demo", 1)
+	for _, tc := range []struct {
+		name   string
+		path   string
+		body   string
+		header map[string]string
+		want   int
+	}{
+		{"read-only source", "/sources/ro/jobs/demo/config", edited, nil, http.StatusForbidden},
+		{"git source", "/sources/remote/jobs/demo/config", edited, nil, http.StatusConflict},
+		{"unknown job", "/sources/local/jobs/missing/config", edited, nil, http.StatusNotFound},
+		{"invalid yaml", "/sources/local/jobs/demo/config", "job: [", nil, http.StatusUnprocessableEntity},
+		{"renamed job", "/sources/local/jobs/demo/config", strings.Replace(demoJobConfig, "id: demo", "id: other", 1), nil, http.StatusUnprocessableEntity},
+		{"stale etag", "/sources/local/jobs/demo/config", edited, map[string]string{"If-Match": `"stale"`}, http.StatusPreconditionFailed},
+		{"wrong media type", "/sources/local/jobs/demo/config", edited, map[string]string{"Content-Type": "application/json"}, http.StatusUnsupportedMediaType},
+	} {
+		if rec := do(http.MethodPut, tc.path, tc.body, tc.header); rec.Code != tc.want {
+			t.Fatalf("%s: expected %d, got %d: %s", tc.name, tc.want, rec.Code, rec.Body.String())
+		}
+		data, err := os.ReadFile(configPath)
+		if err != nil || !strings.Contains(string(data), "Demo Job") {
+			t.Fatalf("%s: config changed on rejected edit: %s", tc.name, data)
+		}
+	}
+
+	rec = do(http.MethodPut, "/sources/local/jobs/demo/config", edited, map[string]string{"If-Match": etag})
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil || string(data) != edited {
+		t.Fatalf("expected edited config on disk, got %q (%v)", data, err)
+	}
+	if rec.Header().Get("ETag") == etag {
+		t.Fatal("expected a new ETag after the edit")
+	}
+}
@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/server/sourcestore"
+)
+
+// SourceQuarantineConfig configures POST /sources/{name}:quarantine and
+// :release.
+type SourceQuarantineConfig struct {
+	Sources SourcesConfig
+	// Runs cancels in-flight runs of a quarantined source and annotates the
+	// provenance of its runs.
+	Runs *RunsHandler
+	Now  func() time.Time
+}
+
+// SourceQuarantineHandler takes sources out of service during incident
+// response. A quarantined source refuses new runs and plans at once, unlike
+// the trust level, which only gates how its jobs run.
+type SourceQuarantineHandler struct {
+	cfg SourceQuarantineConfig
+}
+
+type sourceQuarantineRequest struct {
+	Reason string `json:"reason"`
+	// CancelRuns also cancels the source's queued and running runs.
+	CancelRuns bool `json:"cancel_runs,omitempty"`
+}
+
+type sourceQuarantineResponse struct {
+	Source sourcestore.Source `json:"source"`
+	// AffectedRuns lists every run from the source whose provenance was
+	// annotated; CanceledRuns the in-flight ones among them that were
+	// canceled.
+	AffectedRuns []string `json:"affected_runs"`
+	CanceledRuns []string `json:"canceled_runs"`
+}
+
+// NewSourceQuarantineHandler returns the quarantine handler.
+func NewSourceQuarantineHandler(cfg SourceQuarantineConfig) *SourceQuarantineHandler {
+	if cfg.Sources.Store == nil {
+		cfg.Sources.Store = sourcestore.New()
+	}
+	if cfg.Now == nil {
+		cfg.Now = func() time.Time { return time.Now().UTC() }
+	}
+	return &SourceQuarantineHandler{cfg: cfg}
+}
+
+// IsSourceQuarantinePath reports whether path is one of the routes served
+// by SourceQuarantineHandler.
+func IsSourceQuarantinePath(path string) bool {
+	return strings.HasPrefix(path, "/sources/") && (strings.HasSuffix(path, ":quarantine") || strings.HasSuffix(path, ":release"))
+}
+
+// ServeHTTP routes:
+//
+//	POST /sources/{name}:quarantine
+//	POST /sources/{name}:release
+func (h *SourceQuarantineHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+	name, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/sources/"), ":")
+	defer r.Body.Close()
+	var req sourceQuarantineRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.Write(w, response.New(http.StatusBadRequest, "invalid request body", response.WithDetail(err.Error())))
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	switch action {
+	case "quarantine":
+		h.quarantine(w, r, name, req)
+	case "release":
+		h.release(w, r, name, req)
+	default:
+		response.Write(w, response.New(http.StatusNotFound, "not found"))
+	}
+}
+
+func (h *SourceQuarantineHandler) quarantine(w http.ResponseWriter, r *http.Request, name string, req sourceQuarantineRequest) {
+	if req.Reason == "" {
+		response.Write(w, response.New(http.StatusBadRequest, "reason is required"))
+		return
+	}
+	principal, _ := requestctx.Principal(r.Context())
+	now := h.cfg.Now()
+	src, ok := h.cfg.Sources.Store.Modify(name, func(s *sourcestore.Source) {
+		since := now
+		if s.Quarantine.IsManual() {
+			since = s.Quarantine.Since
+		}
+		s.Quarantine = &sourcestore.Quarantine{
+			Code:   sourcestore.QuarantineManual,
+			Reason: req.Reason,
+			Since:  since,
+			By:     principal,
+		}
+	})
+	if !ok {
+		response.Write(w, response.New(http.StatusNotFound, "source not found", response.WithDetail(name)))
+		return
+	}
+
+	out := sourceQuarantineResponse{AffectedRuns: []string{}, CanceledRuns: []string{}}
+	if h.cfg.Runs != nil {
+		runs := h.cfg.Runs
+		for _, run := range runs.store.List() {
+			if !runFromSource(run, name) {
+				continue
+			}
+			inFlight := !isTerminalStatus(run.Status)
+			canceled := false
+			if inFlight && req.CancelRuns {
+				updated := runs.cancelRun(r.Context(), run.ID, "source "+name+" quarantined: "+req.Reason)
+				canceled = strings.EqualFold(updated.Status, "canceled")
+			}
+			annotateRunQuarantine(runs.store, run.ID, *src.Quarantine, inFlight, canceled)
+			out.AffectedRuns = append(out.AffectedRuns, run.ID)
+			if canceled {
+				out.CanceledRuns = append(out.CanceledRuns, run.ID)
+			}
+		}
+	}
+	if logger := requestctx.Logger(r.Context()); logger != nil {
+		logger.Warn("source.quarantine.manual",
+			slog.String("source", name),
+			slog.String("principal", principal),
+			slog.String("reason", req.Reason),
+			slog.Int("affected_runs", len(out.AffectedRuns)),
+			slog.Int("canceled_runs", len(out.CanceledRuns)))
+	}
+	out.Source = sanitizeSourceForResponse(src, shouldExposeAliases(r, h.cfg.Sources))
+	writeJSON(w, out, http.StatusOK)
+}
+
+func (h *SourceQuarantineHandler) release(w http.ResponseWriter, r *http.Request, name string, req sourceQuarantineRequest) {
+	if req.CancelRuns {
+		response.Write(w, response.New(http.StatusBadRequest, "cancel_runs is only valid when quarantining"))
+		return
+	}
+	var lifted *sourcestore.Quarantine
+	src, ok := h.cfg.Sources.Store.Modify(name, func(s *sourcestore.Source) {
+		lifted = s.Quarantine
+		s.Quarantine = nil
+	})
+	if !ok {
+		response.Write(w, response.New(http.StatusNotFound, "source not found", response.WithDetail(name)))
+		return
+	}
+	if lifted == nil {
+		response.Write(w, response.New(http.StatusConflict, "source not quarantined",
+			response.WithExtension("code", "source.not_quarantined"),
+			response.WithDetail(name)))
+		return
+	}
+	if logger := requestctx.Logger(r.Context()); logger != nil {
+		principal, _ := requestctx.Principal(r.Context())
+		logger.Info("source.quarantine.released",
+			slog.String("source", name),
+			slog.String("principal", principal),
+			slog.String("code", lifted.Code),
+			slog.String("reason", req.Reason))
+	}
+	writeSourceResponse(w, sanitizeSourceForResponse(src, shouldExposeAliases(r, h.cfg.Sources)), false)
+}
+
+// runFromSource reports whether run's provenance names the source.
+func runFromSource(run runstore.Run, name string) bool {
+	src, _ := run.Provenance["source"].(map[string]any)
+	got, _ := src["name"].(string)
+	return got == name
+}
+
+// annotateRunQuarantine records the quarantine in the run's provenance under
+// source_quarantine, so GET /runs/{id}/provenance shows which runs used the
+// source.
+func annotateRunQuarantine(store *runstore.Store, runID string, q sourcestore.Quarantine, inFlight, canceled bool) {
+	run, ok := store.Get(runID)
+	if !ok {
+		return
+	}
+	provenance := make(map[string]any, len(run.Provenance)+1)
+	for k, v := range run.Provenance {
+		provenance[k] = v
+	}
+	note := map[string]any{
+		"reason":    q.Reason,
+		"since":     q.Since.UTC().Format(time.RFC3339),
+		"in_flight": inFlight,
+		"canceled":  canceled,
+	}
+	if q.By != "" {
+		note["by"] = q.By
+	}
+	provenance["source_quarantine"] = note
+	run.Provenance = provenance
+	store.Update(run)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/server/sourcestore"
+)
+
+func quarantineRequest(t *testing.T, h http.Handler, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req = req.WithContext(requestctx.WithPrincipal(req.Context(), "oncall"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSourceQuarantineCancelsAndAnnotatesRuns(t *testing.T) {
+	sources := sourcestore.New()
+	sources.Upsert(sourcestore.Source{Name: "vendor", Type: "git", URL: "https://git.example/vendor.git"})
+	store := runstore.New()
+	started := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	fromVendor := map[string]any{"source": map[string]any{"name": "vendor", "type": "git"}}
+	store.Create(runstore.Run{ID: "done", JobID: "build", Status: "completed", StartedAt: started, Provenance: fromVendor})
+	store.Create(runstore.Run{ID: "busy", JobID: "build", Status: "running", StartedAt: started, Provenance: fromVendor})
+	store.Create(runstore.Run{ID: "other", JobID: "lint", Status: "running", StartedAt: started,
+		Provenance: map[string]any{"source": map[string]any{"name": "lint", "type": "local"}}})
+	runs := NewRunsHandler(RunsConfig{Root: t.TempDir(), Store: store, Sources: sources, Events: &recordingSink{}})
+	now := started.Add(time.Hour)
+	h := NewSourceQuarantineHandler(SourceQuarantineConfig{
+		Sources: SourcesConfig{Store: sources},
+		Runs:    runs,
+		Now:     func() time.Time { return now },
+	})
+
+	rec := quarantineRequest(t, h, "/sources/vendor:quarantine", `{}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected a reason to be required, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = quarantineRequest(t, h, "/sources/vendor:quarantine", `{"reason":"leaked deploy key","cancel_runs":true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var out sourceQuarantineResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if strings.Join(out.AffectedRuns, ",") != "busy,done" || strings.Join(out.CanceledRuns, ",") != "busy" {
+		t.Fatalf("unexpected affected %v / canceled %v", out.AffectedRuns, out.CanceledRuns)
+	}
+	if q := out.Source.Quarantine; q == nil || q.Code != sourcestore.QuarantineManual || q.By != "oncall" || !q.Since.Equal(now) {
+		t.Fatalf("unexpected quarantine %+v", out.Source.Quarantine)
+	}
+
+	busy, _ := store.Get("busy")
+	note, _ := busy.Provenance["source_quarantine"].(map[string]any)
+	if busy.Status != "canceled" || note["canceled"] != true || note["reason"] != "leaked deploy key" {
+		t.Fatalf("expected canceled, annotated run, got %+v", busy)
+	}
+	done, _ := store.Get("done")
+	if note, _ := done.Provenance["source_quarantine"].(map[string]any); done.Status != "completed" || note["in_flight"] != false {
+		t.Fatalf("expected finished run annotated only, got %+v", done)
+	}
+	if other, _ := store.Get("other"); other.Status != "running" || other.Provenance["source_quarantine"] != nil {
+		t.Fatalf("expected unrelated run untouched, got %+v", other)
+	}
+
+	// Re-verification must not lift a manual quarantine.
+	sources.Upsert(func() sourcestore.Source { s, _ := sources.Get("vendor"); s.Type = "oci"; return s }())
+	NewSourceReverifier(SourceReverifierConfig{Sources: SourcesConfig{Store: sources}}).Sweep(context.Background())
+	if src, _ := sources.Get("vendor"); !src.Quarantine.IsManual() {
+		t.Fatalf("expected manual quarantine to survive re-verification, got %+v", src.Quarantine)
+	}
+
+	rec = quarantineRequest(t, h, "/sources/vendor:release", `{"reason":"key rotated"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected release, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if src, _ := sources.Get("vendor"); src.Quarantine != nil {
+		t.Fatalf("expected quarantine lifted, got %+v", src.Quarantine)
+	}
+	rec = quarantineRequest(t, h, "/sources/vendor:release", ``)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 releasing an unquarantined source, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPlansRefuseQuarantinedSource(t *testing.T) {
+	sourceRoot := t.TempDir()
+	writeJobConfig(t, sourceRoot, "remote", `
+version: v1
+job:
+  id: remote
+  name: Remote Job
+`)
+	ss := sourcestore.New()
+	ss.Upsert(sourcestore.Source{
+		Name:       "external",
+		Type:       "local",
+		LocalPath:  sourceRoot,
+		Quarantine: &sourcestore.Quarantine{Code: sourcestore.QuarantineManual, Reason: "incident 42", Since: time.Unix(0, 0).UTC()},
+	})
+	h := NewPlansHandler(PlansConfig{Root: t.TempDir(), Sources: ss})
+
+	req := httptest.NewRequest(http.MethodPost, "/plans", strings.NewReader(`{"job_id":"remote","source":{"name":"external"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "source.quarantined") {
+		t.Fatalf("expected 409 source.quarantined, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
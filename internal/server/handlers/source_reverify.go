@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/policy"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/sourcestore"
+)
+
+const problemTypeSourceQuarantined = "https://flowd.dev/problems/source-quarantined"
+
+// SourceReverifierConfig configures scheduled re-verification of OCI
+// sources.
+type SourceReverifierConfig struct {
+	// Sources supplies the store, policy, verifier and profile used when the
+	// sources were added.
+	Sources  SourcesConfig
+	Interval time.Duration
+	Now      func() time.Time
+	Logger   *slog.Logger
+}
+
+// SourceReverifier re-runs the registry allow-list and signature checks for
+// stored OCI sources, since images can be re-tagged and signing keys revoked
+// after a source is added. A source failing either check is quarantined and
+// refuses new runs until it passes again.
+type SourceReverifier struct {
+	cfg SourceReverifierConfig
+}
+
+// NewSourceReverifier returns a reverifier; Run does nothing when Interval is
+// not positive.
+func NewSourceReverifier(cfg SourceReverifierConfig) *SourceReverifier {
+	if cfg.Sources.Store == nil {
+		cfg.Sources.Store = sourcestore.New()
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return &SourceReverifier{cfg: cfg}
+}
+
+// Run re-verifies every interval until ctx is canceled.
+func (v *SourceReverifier) Run(ctx context.Context) {
+	if v == nil || v.cfg.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(v.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			v.Sweep(ctx)
+		}
+	}
+}
+
+// Sweep checks every stored OCI source once and returns how many are
+// quarantined afterwards.
+func (v *SourceReverifier) Sweep(ctx context.Context) int {
+	quarantined := 0
+	for _, src := range v.cfg.Sources.Store.List() {
+		if src.Type != "oci" {
+			continue
+		}
+		if src.Quarantine.IsManual() {
+			quarantined++
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if v.check(ctx, src) != nil {
+			quarantined++
+		}
+	}
+	return quarantined
+}
+
+// check re-verifies src and records the outcome on the stored source.
+func (v *SourceReverifier) check(ctx context.Context, src sourcestore.Source) *sourcestore.Quarantine {
+	now := v.cfg.Now().UTC()
+	failure := v.verify(ctx, src)
+	if failure != nil {
+		failure.Since = now
+		if src.Quarantine != nil && src.Quarantine.Code == failure.Code {
+			failure.Since = src.Quarantine.Since
+		}
+	}
+	updated, ok := v.cfg.Sources.Store.Modify(src.Name, func(s *sourcestore.Source) {
+		if s.Quarantine.IsManual() {
+			// Quarantined by hand while the check ran.
+			return
+		}
+		s.Quarantine = failure
+		if failure == nil {
+			s.VerifiedAt = &now
+		}
+	})
+	if !ok || updated.Quarantine.IsManual() {
+		return updated.Quarantine
+	}
+	switch {
+	case failure != nil && src.Quarantine == nil:
+		v.cfg.Logger.Warn("source.quarantined",
+			slog.String("source", src.Name),
+			slog.String("ref", src.Ref),
+			slog.String("code", failure.Code),
+			slog.String("reason", failure.Reason))
+	case failure == nil && src.Quarantine != nil:
+		v.cfg.Logger.Info("source.quarantine.lifted",
+			slog.String("source", src.Name),
+			slog.String("ref", src.Ref))
+	}
+	return updated.Quarantine
+}
+
+// verify repeats the admission checks of handleOCISource for src.
+func (v *SourceReverifier) verify(ctx context.Context, src sourcestore.Source) *sourcestore.Quarantine {
+	cfg := v.cfg.Sources
+	policyCtx := cfg.Policy
+	if policyCtx == nil {
+		var err error
+		if policyCtx, err = policy.NewContext(nil); err != nil {
+			return &sourcestore.Quarantine{Code: "E_POLICY", Reason: err.Error()}
+		}
+	}
+	if prob := enforceRegistryAllowList(ctx, src.Ref, policyCtx); prob != nil {
+		return &sourcestore.Quarantine{Code: "image.registry.not.allowed", Reason: problemDetail(*prob)}
+	}
+	if cfg.OfflineVerification {
+		// Offline material is not retained, so signatures cannot be
+		// re-checked without network access.
+		return nil
+	}
+	profile, err := resolveEffectiveProfile("", "", cfg.Profile)
+	if err != nil {
+		return &sourcestore.Quarantine{Code: "E_POLICY", Reason: err.Error()}
+	}
+	mode, err := policyCtx.VerifyModeForProfile(profile)
+	if err != nil {
+		return &sourcestore.Quarantine{Code: "E_POLICY", Reason: err.Error()}
+	}
+	outcome, prob := enforceImageVerification(ctx, src.Ref, mode, cfg.Verifier)
+	if src.VerifySignatures && mode != policy.VerifyModeDisabled && !outcome.Verified {
+		reason := outcome.Reason
+		if reason == "" {
+			reason = "signature verification failed"
+		}
+		return &sourcestore.Quarantine{Code: "source-signature-invalid", Reason: reason}
+	}
+	if prob != nil {
+		return &sourcestore.Quarantine{Code: "image.signature.required", Reason: problemDetail(*prob)}
+	}
+	return nil
+}
+
+func problemDetail(p response.Problem) string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+	return p.Title
+}
+
+// sourceQuarantinedProblem refuses runs from a quarantined source.
+func sourceQuarantinedProblem(src sourcestore.Source) response.Problem {
+	q := src.Quarantine
+	detail := fmt.Sprintf("source %s failed re-verification (%s): %s", src.Name, q.Code, q.Reason)
+	if q.IsManual() {
+		detail = fmt.Sprintf("source %s was quarantined: %s", src.Name, q.Reason)
+	}
+	return response.New(http.StatusConflict, "source quarantined",
+		response.WithType(problemTypeSourceQuarantined),
+		response.WithExtension("code", "source.quarantined"),
+		response.WithExtension("source", src.Name),
+		response.WithExtension("quarantine", q),
+		response.WithDetail(detail))
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/policy"
+	policyverify "github.com/flowd-org/flowd/internal/policy/verify"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/server/sourcestore"
+)
+
+func TestSourceReverifierQuarantinesAndLifts(t *testing.T) {
+	policyCtx, err := policy.NewContext(nil)
+	if err != nil {
+		t.Fatalf("policy context: %v", err)
+	}
+	store := sourcestore.New()
+	store.Upsert(sourcestore.Source{Name: "addon", Type: "oci", Ref: "ghcr.io/example/addon:1.0"})
+	store.Upsert(sourcestore.Source{Name: "local", Type: "local"})
+	verifier := &stubImageVerifier{result: policyverify.Result{Verified: false, Reason: "key revoked"}}
+	now := time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)
+	rv := NewSourceReverifier(SourceReverifierConfig{
+		Sources: SourcesConfig{Store: store, Profile: "secure", Policy: policyCtx, Verifier: verifier},
+		Now:     func() time.Time { return now },
+	})
+
+	if n := rv.Sweep(context.Background()); n != 1 {
+		t.Fatalf("expected one quarantined source, got %d", n)
+	}
+	if verifier.calls != 1 || verifier.image != "ghcr.io/example/addon:1.0" {
+		t.Fatalf("expected OCI source re-verified once, got %d calls for %q", verifier.calls, verifier.image)
+	}
+	src, _ := store.Get("addon")
+	if src.Quarantine == nil || src.Quarantine.Code != "image.signature.required" || !strings.Contains(src.Quarantine.Reason, "key revoked") {
+		t.Fatalf("expected signature quarantine, got %+v", src.Quarantine)
+	}
+
+	verifier.result = policyverify.Result{Verified: true}
+	now = now.Add(time.Hour)
+	if n := rv.Sweep(context.Background()); n != 0 {
+		t.Fatalf("expected quarantine lifted, got %d", n)
+	}
+	src, _ = store.Get("addon")
+	if src.Quarantine != nil || src.VerifiedAt == nil || !src.VerifiedAt.Equal(now) {
+		t.Fatalf("expected verified source, got %+v", src)
+	}
+}
+
+func TestRunsHandlerRefusesQuarantinedSource(t *testing.T) {
+	sourceRoot := t.TempDir()
+	writeJobConfig(t, sourceRoot, "remote", `
+version: v1
+job:
+  id: remote
+  name: Remote Job
+`)
+	ss := sourcestore.New()
+	ss.Upsert(sourcestore.Source{
+		Name:       "external",
+		Type:       "local",
+		LocalPath:  sourceRoot,
+		Quarantine: &sourcestore.Quarantine{Code: "image.registry.not.allowed", Reason: "registry removed", Since: time.Unix(0, 0).UTC()},
+	})
+	h := NewRunsHandler(RunsConfig{Root: t.TempDir(), Store: runstore.New(), Sources: ss})
+
+	req := httptest.NewRequest(http.MethodPost, "/runs", strings.NewReader(`{"job_id":"remote","source":{"name":"external"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	addIdempotencyHeader(req)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var problem map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if problem["type"] != problemTypeSourceQuarantined || problem["code"] != "source.quarantined" {
+		t.Fatalf("unexpected problem %+v", problem)
+	}
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/sourcestore"
+)
+
+const problemTypeSourceTrustInsufficient = "https://flowd.dev/problems/source-trust-insufficient"
+
+type sourceTrustRequest struct {
+	TrustLevel string `json:"trust_level"`
+	Reason     string `json:"reason"`
+}
+
+// applySourceTrust sets the trust level of src before it is stored. A new
+// source takes the requested level (trusted when empty); re-registering an
+// existing source keeps its level and history, and asking for a different
+// level is refused so transitions always go through the audited endpoint.
+// A manual quarantine is likewise kept; only :release lifts it.
+func applySourceTrust(store *sourcestore.Store, src *sourcestore.Source, requested string) *response.Problem {
+	existing, ok := store.Get(src.Name)
+	if !ok {
+		level, _ := sourcestore.ParseTrustLevel(requested)
+		src.TrustLevel = level
+		return nil
+	}
+	current := existing.EffectiveTrustLevel()
+	if requested != "" && requested != current {
+		prob := response.New(http.StatusConflict, "trust level change requires transition",
+			response.WithExtension("code", "source.trust.transition"),
+			response.WithDetail(fmt.Sprintf("source %s is %s; use POST /sources/%s:trust to change it", src.Name, current, src.Name)))
+		return &prob
+	}
+	src.TrustLevel = current
+	src.TrustHistory = existing.TrustHistory
+	if existing.Quarantine.IsManual() {
+		src.Quarantine = existing.Quarantine
+	}
+	return nil
+}
+
+// handleSourceTrust serves POST /sources/{name}:trust, which moves a source
+// between trust levels. Every change is appended to the source's
+// trust_history and logged with the acting principal.
+func handleSourceTrust(w http.ResponseWriter, r *http.Request, cfg SourcesConfig, name string) {
+	if r.Method != http.MethodPost {
+		response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+	defer r.Body.Close()
+	var req sourceTrustRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		response.Write(w, response.New(http.StatusBadRequest, "invalid request body", response.WithDetail(err.Error())))
+		return
+	}
+	if strings.TrimSpace(req.TrustLevel) == "" {
+		response.Write(w, response.New(http.StatusBadRequest, "trust_level is required"))
+		return
+	}
+	level, err := sourcestore.ParseTrustLevel(req.TrustLevel)
+	if err != nil {
+		response.Write(w, response.New(http.StatusBadRequest, "invalid trust level", response.WithDetail(err.Error())))
+		return
+	}
+
+	principal, _ := requestctx.Principal(r.Context())
+	reason := strings.TrimSpace(req.Reason)
+	src, change, ok := cfg.Store.SetTrustLevel(name, level, principal, reason, time.Now())
+	if !ok {
+		response.Write(w, response.New(http.StatusNotFound, "source not found", response.WithDetail(name)))
+		return
+	}
+	if change.To != "" {
+		if logger := requestctx.Logger(r.Context()); logger != nil {
+			logger.Info("source.trust.changed",
+				slog.String("source", name),
+				slog.String("from", change.From),
+				slog.String("to", change.To),
+				slog.String("principal", principal),
+				slog.String("reason", reason),
+			)
+		}
+	}
+	writeSourceResponse(w, sanitizeSourceForResponse(src, shouldExposeAliases(r, cfg)), false)
+}
+
+// sourceTrustProblem rejects a run of a job from a source whose trust level
+// does not allow it.
+func sourceTrustProblem(source, level, detail string) response.Problem {
+	return response.New(http.StatusForbidden, "source trust insufficient",
+		response.WithType(problemTypeSourceTrustInsufficient),
+		response.WithExtension("code", "source.trust.insufficient"),
+		response.WithExtension("source", source),
+		response.WithExtension("trust_level", level),
+		response.WithDetail(detail))
+}
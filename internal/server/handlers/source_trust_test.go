@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/server/sourcestore"
+)
+
+func TestSourceTrustTransitionIsAudited(t *testing.T) {
+	store := sourcestore.New()
+	store.Upsert(sourcestore.Source{Name: "external", Type: "local", TrustLevel: sourcestore.TrustTrusted})
+	h := NewSourceGetHandler(SourcesConfig{Store: store})
+
+	req := httptest.NewRequest(http.MethodPost, "/sources/external:trust", strings.NewReader(`{"trust_level":"limited","reason":"new maintainer"}`))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var src sourcestore.Source
+	if err := json.Unmarshal(rec.Body.Bytes(), &src); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if src.TrustLevel != sourcestore.TrustLimited || len(src.TrustHistory) != 1 {
+		t.Fatalf("expected limited with one history entry, got %+v", src)
+	}
+	if change := src.TrustHistory[0]; change.From != sourcestore.TrustTrusted || change.Reason != "new maintainer" {
+		t.Fatalf("unexpected history entry %+v", change)
+	}
+
+	for body, want := range map[string]int{
+		`{"trust_level":"root"}`: http.StatusBadRequest,
+		`{}`:                     http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/sources/external:trust", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Fatalf("%s: expected %d, got %d", body, want, rec.Code)
+		}
+	}
+}
+
+func TestApplySourceTrustRefusesImplicitTransition(t *testing.T) {
+	store := sourcestore.New()
+	store.Upsert(sourcestore.Source{Name: "external", TrustLevel: sourcestore.TrustUntrusted})
+
+	src := sourcestore.Source{Name: "external"}
+	if prob := applySourceTrust(store, &src, sourcestore.TrustTrusted); prob == nil || prob.Status != http.StatusConflict {
+		t.Fatalf("expected 409 for implicit upgrade, got %+v", prob)
+	}
+	if prob := applySourceTrust(store, &src, ""); prob != nil || src.TrustLevel != sourcestore.TrustUntrusted {
+		t.Fatalf("expected re-registration to keep untrusted, got %+v (%s)", prob, src.TrustLevel)
+	}
+	fresh := sourcestore.Source{Name: "fresh"}
+	if prob := applySourceTrust(store, &fresh, ""); prob != nil || fresh.TrustLevel != sourcestore.TrustTrusted {
+		t.Fatalf("expected new source to default to trusted, got %+v (%s)", prob, fresh.TrustLevel)
+	}
+}
+
+func TestRunsHandlerGatesSourceTrustLevels(t *testing.T) {
+	sourceRoot := t.TempDir()
+	writeJobConfig(t, sourceRoot, "remote", `
+version: v1
+job:
+  id: remote
+  name: Remote Job
+`)
+	for level, want := range map[string]int{
+		sourcestore.TrustUntrusted: http.StatusForbidden,
+		sourcestore.TrustLimited:   http.StatusForbidden,
+		sourcestore.TrustTrusted:   http.StatusCreated,
+	} {
+		ss := sourcestore.New()
+		ss.Upsert(sourcestore.Source{Name: "external", Type: "local", LocalPath: sourceRoot, TrustLevel: level})
+		h := NewRunsHandler(RunsConfig{Root: t.TempDir(), Store: runstore.New(), Sources: ss})
+
+		req := httptest.NewRequest(http.MethodPost, "/runs", strings.NewReader(`{"job_id":"remote","source":{"name":"external"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		addIdempotencyHeader(req)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Fatalf("%s: expected %d, got %d: %s", level, want, rec.Code, rec.Body.String())
+		}
+		if want != http.StatusForbidden {
+			continue
+		}
+		var problem map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if problem["code"] != "source.trust.insufficient" || problem["trust_level"] != level {
+			t.Fatalf("%s: unexpected problem %+v", level, problem)
+		}
+	}
+}
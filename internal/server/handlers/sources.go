@@ -19,6 +19,7 @@ import (
 	"github.com/flowd-org/flowd/internal/paths"
 	"github.com/flowd-org/flowd/internal/policy"
 	policyverify "github.com/flowd-org/flowd/internal/policy/verify"
+	"github.com/flowd-org/flowd/internal/server/faults"
 	"github.com/flowd-org/flowd/internal/server/metrics"
 	"github.com/flowd-org/flowd/internal/server/requestctx"
 	"github.com/flowd-org/flowd/internal/server/response"
@@ -39,6 +40,15 @@ type SourcesConfig struct {
 	RuntimeDetector func() (container.Runtime, error)
 	AliasesPublic   bool
 	ExposeAliases   func(*http.Request) bool
+	// PlanCache is purged after a job config is edited through the API.
+	PlanCache *PlanCache
+	// OfflineVerification verifies OCI sources only against the material in
+	// their offline_verification field; under a required verify mode a
+	// source without it is rejected.
+	OfflineVerification bool
+	// Root is the scripts root; aliases imported from add-on manifests must
+	// not shadow its jobs and aliases.
+	Root string
 }
 
 type sourceRequest struct {
@@ -51,6 +61,18 @@ type sourceRequest struct {
 	Trust            map[string]interface{} `json:"trust"`
 	Expose           string                 `json:"expose"`
 	VerifySignatures bool                   `json:"verify_signatures"`
+	Webhook          *sourcestore.Webhook   `json:"webhook"`
+
+	OfflineVerification *policyverify.OfflineMaterial `json:"offline_verification"`
+	// TrustLevel is untrusted, limited or trusted (the default). Existing
+	// sources change level only through POST /sources/{name}:trust.
+	TrustLevel string `json:"trust_level"`
+	// AliasConflict decides what happens to add-on aliases that collide
+	// with existing jobs or aliases: refuse (the default) or prefix.
+	AliasConflict string `json:"alias_conflict"`
+	// DryRun previews an OCI source without storing it; it is set from the
+	// dry_run query parameter.
+	DryRun bool `json:"-"`
 }
 
 var (
@@ -64,7 +86,10 @@ var (
 	addonManifestMountPath = "/flwd-addon/" + addonManifestFileName
 )
 
-const problemTypeSignatureInvalid = "https://flowd.dev/problems/source-signature-invalid"
+const (
+	problemTypeSignatureInvalid     = "https://flowd.dev/problems/source-signature-invalid"
+	problemTypeOfflineBundleMissing = "https://flowd.dev/problems/offline-bundle-missing"
+)
 
 func normalizeExpose(value string) (string, error) {
 	v := strings.ToLower(strings.TrimSpace(value))
@@ -200,6 +225,15 @@ func handleListSources(w http.ResponseWriter, r *http.Request, cfg SourcesConfig
 		store = sourcestore.New()
 	}
 	items := store.List()
+	if requestctx.AccessFromContext(r.Context()) != nil {
+		visible := items[:0]
+		for _, item := range items {
+			if requestctx.SourceAllowed(r.Context(), item.Name) {
+				visible = append(visible, item)
+			}
+		}
+		items = visible
+	}
 	includeAliases := shouldExposeAliases(r, cfg)
 	for i := range items {
 		if items[i].Provenance == nil {
@@ -235,6 +269,39 @@ func handleUpsertSource(ctx context.Context, w http.ResponseWriter, r *http.Requ
 		response.Write(w, response.New(http.StatusBadRequest, "invalid name", response.WithDetail("name must not contain path separators")))
 		return
 	}
+	if prob := sourceAccessProblem(ctx, req.Name); prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+	if req.TrustLevel != "" {
+		level, err := sourcestore.ParseTrustLevel(req.TrustLevel)
+		if err != nil {
+			response.Write(w, response.New(http.StatusBadRequest, "invalid trust level", response.WithDetail(err.Error())))
+			return
+		}
+		req.TrustLevel = level
+	}
+	if prob := parseAliasImportOptions(r, &req); prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+
+	if req.Webhook != nil {
+		if req.Type != "git" {
+			response.Write(w, response.New(http.StatusBadRequest, "invalid webhook",
+				response.WithExtension("code", "webhook.invalid"),
+				response.WithDetail("webhooks are only supported for git sources")))
+			return
+		}
+		webhook, err := normalizeWebhook(*req.Webhook)
+		if err != nil {
+			response.Write(w, response.New(http.StatusBadRequest, "invalid webhook",
+				response.WithExtension("code", "webhook.invalid"),
+				response.WithDetail(err.Error())))
+			return
+		}
+		req.Webhook = webhook
+	}
 
 	switch req.Type {
 	case "local":
@@ -322,6 +389,10 @@ func handleLocalSource(w http.ResponseWriter, req sourceRequest, cfg SourcesConf
 		},
 	}
 
+	if prob := applySourceTrust(cfg.Store, &src, req.TrustLevel); prob != nil {
+		response.Write(w, *prob)
+		return
+	}
 	created := cfg.Store.Upsert(src)
 	writeSourceResponse(w, sanitizeSourceForResponse(src, true), created)
 }
@@ -349,21 +420,11 @@ func handleGitSource(ctx context.Context, w http.ResponseWriter, req sourceReque
 
 	repoForClone := repoURL
 	if isLocalGitURL(parsed) {
-		localPath := parsed.Path
-		if parsed.Scheme == "file" {
-			if parsed.Host != "" {
-				localPath = "//" + parsed.Host + parsed.Path
-			}
-		}
-		if localPath == "" {
-			localPath = repoURL
-		}
-		absPath, err := filepath.Abs(localPath)
+		absPath, err := localGitPath(parsed, repoURL)
 		if err != nil {
 			response.Write(w, response.New(http.StatusBadRequest, "invalid git url", response.WithDetail(err.Error())))
 			return
 		}
-		absPath = filepath.Clean(absPath)
 		allowed := false
 		for _, root := range cfg.AllowLocalRoots {
 			if isSubPath(absPath, root) {
@@ -433,6 +494,7 @@ func handleGitSource(ctx context.Context, w http.ResponseWriter, req sourceReque
 		LocalPath:      checkoutPath,
 		Aliases:        aliasDefs,
 		Expose:         expose,
+		Webhook:        req.Webhook,
 		Provenance: map[string]any{
 			"type":            "git",
 			"resolved_commit": commit,
@@ -441,6 +503,10 @@ func handleGitSource(ctx context.Context, w http.ResponseWriter, req sourceReque
 		},
 	}
 
+	if prob := applySourceTrust(cfg.Store, &src, req.TrustLevel); prob != nil {
+		response.Write(w, *prob)
+		return
+	}
 	created := cfg.Store.Upsert(src)
 	writeSourceResponse(w, sanitizeSourceForResponse(src, true), created)
 }
@@ -483,7 +549,7 @@ func handleOCISource(ctx context.Context, w http.ResponseWriter, req sourceReque
 		return
 	}
 
-	effProfile, err := resolveEffectiveProfile("", cfg.Profile)
+	effProfile, err := resolveEffectiveProfile("", "", cfg.Profile)
 	if err != nil {
 		response.Write(w, response.New(http.StatusUnprocessableEntity, "policy error",
 			response.WithExtension("code", "E_POLICY"),
@@ -516,7 +582,25 @@ func handleOCISource(ctx context.Context, w http.ResponseWriter, req sourceReque
 		return
 	}
 
-	outcome, prob := enforceImageVerification(ctx, imageRef, mode, cfg.Verifier)
+	verifier := cfg.Verifier
+	switch {
+	case req.OfflineVerification != nil:
+		if err := req.OfflineVerification.Validate(); err != nil {
+			response.Write(w, response.New(http.StatusBadRequest, "invalid offline verification material",
+				response.WithDetail(err.Error())))
+			return
+		}
+		verifier = policyverify.NewOfflineVerifier(*req.OfflineVerification)
+	case cfg.OfflineVerification && mode == policy.VerifyModeRequired:
+		response.Write(w, response.New(http.StatusUnprocessableEntity, "offline verification bundle missing",
+			response.WithType(problemTypeOfflineBundleMissing),
+			response.WithExtension("code", "source.offline_bundle.missing"),
+			response.WithDetail(fmt.Sprintf("%s cannot be verified without network access; supply offline_verification", imageRef))))
+		return
+	case cfg.OfflineVerification:
+		verifier = missingOfflineMaterial{}
+	}
+	outcome, prob := enforceImageVerification(ctx, imageRef, mode, verifier)
 	if req.VerifySignatures {
 		if mode == policy.VerifyModeDisabled {
 			response.Write(w, response.New(http.StatusUnprocessableEntity, "signature verification required",
@@ -615,6 +699,26 @@ func handleOCISource(ctx context.Context, w http.ResponseWriter, req sourceReque
 		name = deriveOCIName(imageRef)
 	}
 
+	aliasImports, aliasDefs, aliasConflicts := planAddonAliases(cfg, name, manifest, req.AliasConflict)
+	if req.DryRun {
+		writeSourcePreview(w, SourcePreview{
+			DryRun:        true,
+			Name:          name,
+			Type:          "oci",
+			Ref:           imageRef,
+			Digest:        digest,
+			Manifest:      manifestSummary(manifest),
+			AliasConflict: req.AliasConflict,
+			Aliases:       aliasImports,
+			Conflicts:     aliasConflicts,
+		})
+		return
+	}
+	if aliasConflicts > 0 {
+		response.Write(w, aliasConflictProblem(name, aliasImports))
+		return
+	}
+
 	cacheRoot := deriveOCICacheRoot(cfg.CheckoutDir)
 	manifestPath, writeErr := writeAddonManifest(cacheRoot, name, manifestBytes)
 	if writeErr != nil {
@@ -625,10 +729,11 @@ func handleOCISource(ctx context.Context, w http.ResponseWriter, req sourceReque
 	}
 
 	metadata := map[string]any{
-		"trusted":       true,
-		"pull_policy":   storedPolicy,
-		"manifest_path": manifestPath,
-		"manifest":      manifestSummary(manifest),
+		"trusted":         true,
+		"pull_policy":     storedPolicy,
+		"manifest_path":   manifestPath,
+		"manifest_sha256": sha256Hex(manifestBytes),
+		"manifest":        manifestSummary(manifest),
 	}
 	if digest != "" {
 		metadata["digest"] = digest
@@ -650,6 +755,9 @@ func handleOCISource(ctx context.Context, w http.ResponseWriter, req sourceReque
 			"verify_mode":        string(mode),
 			"signature_verified": outcome.Verified,
 		}
+		if outcome.Backend != "" {
+			trustMeta["backend"] = outcome.Backend
+		}
 		if outcome.Reason != "" {
 			trustMeta["signature_reason"] = outcome.Reason
 		}
@@ -661,6 +769,7 @@ func handleOCISource(ctx context.Context, w http.ResponseWriter, req sourceReque
 		Type:             "oci",
 		Ref:              imageRef,
 		ResolvedRef:      digest,
+		Aliases:          aliasDefs,
 		URL:              strings.TrimSpace(req.URL),
 		Trust:            cloneTrust(req.Trust),
 		Metadata:         metadata,
@@ -678,6 +787,10 @@ func handleOCISource(ctx context.Context, w http.ResponseWriter, req sourceReque
 		}),
 	}
 
+	if prob := applySourceTrust(cfg.Store, &src, req.TrustLevel); prob != nil {
+		response.Write(w, *prob)
+		return
+	}
 	created := cfg.Store.Upsert(src)
 	if created {
 		metrics.Default.RecordSourceAdded(src.Type)
@@ -709,10 +822,18 @@ func NewSourceGetHandler(cfg SourcesConfig) http.Handler {
 	cfg.Store = store
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		name := strings.TrimPrefix(r.URL.Path, "/sources/")
+		if source, jobID, ok := splitSourceJobConfigPath(name); ok {
+			serveSourceJobConfig(w, r, cfg, source, jobID)
+			return
+		}
 		if name == "" || strings.ContainsAny(name, "/\\") {
 			response.Write(w, response.New(http.StatusNotFound, "source not found"))
 			return
 		}
+		if base, ok := strings.CutSuffix(name, ":trust"); ok {
+			handleSourceTrust(w, r, cfg, base)
+			return
+		}
 		switch r.Method {
 		case http.MethodGet:
 			src, ok := store.Get(name)
@@ -871,6 +992,22 @@ func isLocalGitURL(u *url.URL) bool {
 	return u.Scheme == "" && u.Host == "" && u.Path != ""
 }
 
+// localGitPath returns the absolute repository path of a local git url.
+func localGitPath(u *url.URL, raw string) (string, error) {
+	localPath := u.Path
+	if u.Scheme == "file" && u.Host != "" {
+		localPath = "//" + u.Host + u.Path
+	}
+	if localPath == "" {
+		localPath = raw
+	}
+	absPath, err := filepath.Abs(localPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Clean(absPath), nil
+}
+
 func materializeGitSource(ctx context.Context, baseDir, name, repoURL, ref string) (string, string, error) {
 	if ctx == nil {
 		ctx = context.Background()
@@ -958,6 +1095,9 @@ func resolveGitCommit(ctx context.Context, dir, ref string) (string, error) {
 }
 
 func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	if faults.Inject(ctx, faults.GitTimeout) {
+		return "", fmt.Errorf("git %s: %w: %v", strings.Join(args, " "), context.DeadlineExceeded, faults.Err(faults.GitTimeout))
+	}
 	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = dir
 	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
@@ -969,3 +1109,14 @@ func runGit(ctx context.Context, dir string, args ...string) (string, error) {
 	}
 	return strings.TrimSpace(stdout.String()), nil
 }
+
+// missingOfflineMaterial stands in for the configured verifier when offline
+// verification is enabled but a source brought no material, so permissive
+// profiles record the gap rather than reaching for the network.
+type missingOfflineMaterial struct{}
+
+func (missingOfflineMaterial) Backend() string { return policyverify.BackendCosignOffline }
+
+func (missingOfflineMaterial) Verify(context.Context, string) (policyverify.Result, error) {
+	return policyverify.Result{Reason: "no offline verification bundle provided"}, nil
+}
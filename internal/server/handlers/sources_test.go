@@ -277,7 +277,6 @@ func TestSourcesHandlerExposeNoneHidesAliases(t *testing.T) {
 }
 
 func TestSourcesHandlerOCIRequiresTrust(t *testing.T) {
-	t.Setenv("FLWD_PROFILE", "")
 	store := sourcestore.New()
 	policyCtx, err := policy.NewContext(nil)
 	if err != nil {
@@ -308,7 +307,6 @@ func TestSourcesHandlerOCIRequiresTrust(t *testing.T) {
 }
 
 func TestSourcesHandlerOCIRegistryDenied(t *testing.T) {
-	t.Setenv("FLWD_PROFILE", "")
 	store := sourcestore.New()
 	bundle := &policy.Bundle{
 		AllowedRegistries: []string{"allowed.registry"},
@@ -343,7 +341,6 @@ func TestSourcesHandlerOCIRegistryDenied(t *testing.T) {
 }
 
 func TestSourcesHandlerOCISignatureFailureRequired(t *testing.T) {
-	t.Setenv("FLWD_PROFILE", "")
 	store := sourcestore.New()
 	policyCtx, err := policy.NewContext(nil)
 	if err != nil {
@@ -376,8 +373,48 @@ func TestSourcesHandlerOCISignatureFailureRequired(t *testing.T) {
 	}
 }
 
+func TestSourcesHandlerOCIOfflineBundleMissing(t *testing.T) {
+	policyCtx, err := policy.NewContext(nil)
+	if err != nil {
+		t.Fatalf("policy context: %v", err)
+	}
+	verifier := &stubImageVerifier{result: policyverify.Result{Verified: true}}
+	h := NewSourcesHandler(SourcesConfig{
+		Store:               sourcestore.New(),
+		Profile:             "secure",
+		Policy:              policyCtx,
+		Verifier:            verifier,
+		OfflineVerification: true,
+	})
+
+	for body, want := range map[string]int{
+		`{"type":"oci","ref":"ghcr.io/example/addon:1.0","trusted":true}`:                                             http.StatusUnprocessableEntity,
+		`{"type":"oci","ref":"ghcr.io/example/addon:1.0","trusted":true,"offline_verification":{"signature":"c2ln"}}`: http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/sources", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Fatalf("%s: expected %d, got %d: %s", body, want, rec.Code, rec.Body.String())
+		}
+		if want != http.StatusUnprocessableEntity {
+			continue
+		}
+		var problem map[string]any
+		if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+			t.Fatalf("decode problem: %v", err)
+		}
+		if problem["type"] != problemTypeOfflineBundleMissing || problem["code"] != "source.offline_bundle.missing" {
+			t.Fatalf("expected offline bundle missing problem, got %+v", problem)
+		}
+	}
+	if verifier.calls != 0 {
+		t.Fatalf("expected configured verifier to be bypassed, got %d calls", verifier.calls)
+	}
+}
+
 func TestSourcesHandlerOCIPermissiveSignatureWarning(t *testing.T) {
-	t.Setenv("FLWD_PROFILE", "")
 	store := sourcestore.New()
 	policyCtx, err := policy.NewContext(nil)
 	if err != nil {
@@ -479,7 +516,6 @@ jobs:
 }
 
 func TestSourcesHandlerOCIPullPolicyOnRun(t *testing.T) {
-	t.Setenv("FLWD_PROFILE", "")
 	store := sourcestore.New()
 	policyCtx, err := policy.NewContext(nil)
 	if err != nil {
@@ -549,7 +585,6 @@ jobs:
 }
 
 func TestSourcesHandlerOCIVerifySignaturesFailure(t *testing.T) {
-	t.Setenv("FLWD_PROFILE", "")
 	store := sourcestore.New()
 	cacheRoot := filepath.Join(t.TempDir(), "sources")
 	policyCtx, err := policy.NewContext(nil)
@@ -609,7 +644,6 @@ jobs: []
 }
 
 func TestSourcesHandlerOCIVerifySignaturesSuccess(t *testing.T) {
-	t.Setenv("FLWD_PROFILE", "")
 	store := sourcestore.New()
 	cacheRoot := filepath.Join(t.TempDir(), "sources")
 	policyCtx, err := policy.NewContext(nil)
@@ -676,7 +710,6 @@ jobs:
 }
 
 func TestSourcesHandlerOCIAddSuccess(t *testing.T) {
-	t.Setenv("FLWD_PROFILE", "")
 	store := sourcestore.New()
 	cacheRoot := filepath.Join(t.TempDir(), "sources")
 	policyCtx, err := policy.NewContext(nil)
@@ -794,7 +827,6 @@ func TestSourceHandlerDeleteNotFound(t *testing.T) {
 }
 
 func TestSourcesHandlerOCIManifestExtraField(t *testing.T) {
-	t.Setenv("FLWD_PROFILE", "")
 	store := sourcestore.New()
 	cacheRoot := filepath.Join(t.TempDir(), "sources")
 	policyCtx, err := policy.NewContext(nil)
@@ -861,7 +893,6 @@ unexpected: true
 }
 
 func TestSourcesHandlerOCIMetricsCounters(t *testing.T) {
-	t.Setenv("FLWD_PROFILE", "")
 	metrics.Default = metrics.NewRegistry()
 	store := sourcestore.New()
 	cacheRoot := filepath.Join(t.TempDir(), "sources")
@@ -929,7 +960,6 @@ jobs:
 }
 
 func TestSourcesHandlerOCIManifestInvalidMetric(t *testing.T) {
-	t.Setenv("FLWD_PROFILE", "")
 	metrics.Default = metrics.NewRegistry()
 	store := sourcestore.New()
 	cacheRoot := filepath.Join(t.TempDir(), "sources")
@@ -972,7 +1002,6 @@ func TestSourcesHandlerOCIManifestInvalidMetric(t *testing.T) {
 }
 
 func TestSourcesHandlerOCIManifestInvalid(t *testing.T) {
-	t.Setenv("FLWD_PROFILE", "")
 	store := sourcestore.New()
 	cacheRoot := filepath.Join(t.TempDir(), "sources")
 	policyCtx, err := policy.NewContext(nil)
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/flowd-org/flowd/internal/events"
+	"github.com/flowd-org/flowd/internal/executor"
 	"github.com/flowd-org/flowd/internal/server/sse"
 )
 
@@ -44,6 +47,9 @@ func (s *sseSink) EmitRunFinish(runID, status string, err error) {
 	if err != nil {
 		data["error"] = err.Error()
 	}
+	if reason := timeoutReason(err); reason != "" {
+		data["reason"] = reason
+	}
 	s.publish("run.finish", data)
 }
 
@@ -61,6 +67,34 @@ func (s *sseSink) EmitStepLog(runID, step, channel, message string) {
 	s.publish("step.log", data)
 }
 
+// EmitStepLogAt publishes step.log with the line's RFC3339Nano time and its
+// number within the step, so parallel steps' output can be put in order.
+func (s *sseSink) EmitStepLogAt(runID, step, channel, message string, at time.Time, line int64) {
+	data := s.basePayload()
+	data["step"] = step
+	data["channel"] = channel
+	data["message"] = message
+	data["ts"] = at.Format(time.RFC3339Nano)
+	data["line"] = line
+	s.publish("step.log", data)
+}
+
+// EmitStepBinary publishes step.output.binary in place of the step.log lines
+// of binary output, base64-encoding the bytes when they are small enough.
+func (s *sseSink) EmitStepBinary(runID, step, channel string, size int, data []byte) {
+	payload := s.basePayload()
+	payload["step"] = step
+	payload["channel"] = channel
+	payload["bytes"] = size
+	if data != nil {
+		payload["encoding"] = "base64"
+		payload["data"] = base64.StdEncoding.EncodeToString(data)
+	} else {
+		payload["skipped"] = true
+	}
+	s.publish("step.output.binary", payload)
+}
+
 func (s *sseSink) EmitStepFinish(runID, step string, exitCode int, err error) {
 	data := s.basePayload()
 	data["step"] = step
@@ -68,12 +102,61 @@ func (s *sseSink) EmitStepFinish(runID, step string, exitCode int, err error) {
 	if err != nil {
 		data["error"] = err.Error()
 		data["status"] = "failed"
+		if timeoutReason(err) != "" {
+			data["status"] = "timed_out"
+		}
 	} else {
 		data["status"] = "completed"
 	}
 	s.publish("step.finish", data)
 }
 
+// timeoutReason tells apart runs stopped by the job's timeout ("run_timeout")
+// from those stopped by a step's ("step_timeout").
+func timeoutReason(err error) string {
+	var timeout *executor.TimeoutError
+	if !errors.As(err, &timeout) {
+		return ""
+	}
+	if timeout.Step != "" {
+		return "step_timeout"
+	}
+	return "run_timeout"
+}
+
+func (s *sseSink) EmitImagePullStart(runID, step, image string) {
+	data := s.basePayload()
+	data["step"] = step
+	data["image"] = image
+	s.publish("image.pull.start", data)
+}
+
+func (s *sseSink) EmitImagePullProgress(runID, step, image string, progress events.ImagePullProgress) {
+	data := s.basePayload()
+	data["step"] = step
+	data["image"] = image
+	data["layers_total"] = progress.LayersTotal
+	data["layers_done"] = progress.LayersDone
+	if progress.BytesTotal > 0 {
+		data["bytes_done"] = progress.BytesDone
+		data["bytes_total"] = progress.BytesTotal
+	}
+	s.publish("image.pull.progress", data)
+}
+
+func (s *sseSink) EmitImagePullFinish(runID, step, image string, err error) {
+	data := s.basePayload()
+	data["step"] = step
+	data["image"] = image
+	if err != nil {
+		data["error"] = err.Error()
+		data["status"] = "failed"
+	} else {
+		data["status"] = "completed"
+	}
+	s.publish("image.pull.finish", data)
+}
+
 func (s *sseSink) basePayload() map[string]any {
 	payload := map[string]any{}
 	if s.run != nil {
@@ -103,5 +186,7 @@ func (s *sseSink) publish(event string, payload map[string]any) {
 	if err != nil {
 		bytes = []byte("{}")
 	}
-	s.sink.Publish(s.run.ID, sse.Event{Event: event, Data: string(bytes)})
+	// Stamp events when emitted so the journal records execution time rather
+	// than the time an asynchronous sink got around to persisting them.
+	s.sink.Publish(s.run.ID, sse.Event{Event: event, Data: string(bytes), Timestamp: time.Now().UTC()})
 }
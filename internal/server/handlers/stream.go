@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+)
+
+// streamFlushEvery bounds how many array elements are buffered before the
+// response is flushed to the client.
+const streamFlushEvery = 256
+
+// writeJSONArray streams a JSON array of n elements to w, encoding one element
+// at a time so that large listings never materialize as a single byte slice.
+// item returns the value for index i. The status line is committed before the
+// first element, so encode failures after that point can only abort the body;
+// the returned error lets callers log them.
+func writeJSONArray(w http.ResponseWriter, n int, item func(i int) any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+	if err := buf.WriteByte('['); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			if err := buf.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(item(i)); err != nil {
+			return err
+		}
+		if flusher != nil && (i+1)%streamFlushEvery == 0 {
+			if err := buf.Flush(); err != nil {
+				return err
+			}
+			flusher.Flush()
+		}
+	}
+	if err := buf.WriteByte(']'); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
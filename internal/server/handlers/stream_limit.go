@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/flowd-org/flowd/internal/metrics"
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/response"
+)
+
+const streamLimitProblemType = "https://flowd.dev/problems/stream-limit-exceeded"
+
+// anonymousPrincipal groups streams opened without an authenticated
+// principal, e.g. in dev mode.
+const anonymousPrincipal = "anonymous"
+
+// StreamLimits caps concurrently open event streams. Zero or negative values
+// leave that dimension unlimited.
+type StreamLimits struct {
+	PerPrincipal int
+	PerRun       int
+}
+
+// StreamLimiter tracks open event streams per run and per principal and
+// refuses new ones beyond StreamLimits.
+type StreamLimiter struct {
+	limits      StreamLimits
+	mu          sync.Mutex
+	byRun       map[string]int
+	byPrincipal map[string]int
+}
+
+// NewStreamLimiter returns a limiter enforcing limits.
+func NewStreamLimiter(limits StreamLimits) *StreamLimiter {
+	return &StreamLimiter{
+		limits:      limits,
+		byRun:       make(map[string]int),
+		byPrincipal: make(map[string]int),
+	}
+}
+
+// Acquire registers a stream for runID, which is empty for the global feed,
+// opened by the request's principal. It returns a release function, or a 429
+// problem when a limit is reached.
+func (l *StreamLimiter) Acquire(r *http.Request, runID string) (func(), *response.Problem) {
+	principal, ok := requestctx.Principal(r.Context())
+	if !ok {
+		principal = anonymousPrincipal
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n := l.limits.PerPrincipal; n > 0 && l.byPrincipal[principal] >= n {
+		metrics.RecordSSEStreamRejected("principal")
+		return nil, streamLimitProblem("principal", n)
+	}
+	if n := l.limits.PerRun; n > 0 && runID != "" && l.byRun[runID] >= n {
+		metrics.RecordSSEStreamRejected("run")
+		return nil, streamLimitProblem("run", n)
+	}
+	l.byPrincipal[principal]++
+	if runID != "" {
+		l.byRun[runID]++
+	}
+	metrics.RecordSSEStreamDelta(runID, principal, 1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			decrement(l.byPrincipal, principal)
+			decrement(l.byRun, runID)
+			metrics.RecordSSEStreamDelta(runID, principal, -1)
+		})
+	}, nil
+}
+
+func decrement(counts map[string]int, key string) {
+	if key == "" {
+		return
+	}
+	if counts[key]--; counts[key] <= 0 {
+		delete(counts, key)
+	}
+}
+
+func streamLimitProblem(scope string, limit int) *response.Problem {
+	problem := response.New(http.StatusTooManyRequests, "too many event streams",
+		response.WithType(streamLimitProblemType),
+		response.WithDetail(fmt.Sprintf("at most %d open event streams per %s; close one and retry", limit, scope)),
+		response.WithExtension("limit", scope),
+		response.WithExtension("max_streams", limit),
+	)
+	return &problem
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	servermetrics "github.com/flowd-org/flowd/internal/server/metrics"
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/server/sse"
+)
+
+func streamRequest(path, principal string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	return req.WithContext(requestctx.WithPrincipal(req.Context(), principal))
+}
+
+func TestStreamLimiterCapsPrincipalAndRun(t *testing.T) {
+	limiter := NewStreamLimiter(StreamLimits{PerPrincipal: 2, PerRun: 1})
+
+	releaseA, problem := limiter.Acquire(streamRequest("/", "limit-alice"), "limit-run-1")
+	if problem != nil {
+		t.Fatalf("first stream refused: %+v", problem)
+	}
+	if runs, principals := servermetrics.Default.SSEStreams("limit-run-1", "limit-alice"); runs != 1 || principals != 1 {
+		t.Fatalf("expected gauges 1/1, got %d/%d", runs, principals)
+	}
+	if _, problem := limiter.Acquire(streamRequest("/", "limit-bob"), "limit-run-1"); problem == nil || problem.Status != http.StatusTooManyRequests || problem.Ext["limit"] != "run" {
+		t.Fatalf("expected run limit problem, got %+v", problem)
+	}
+	releaseB, problem := limiter.Acquire(streamRequest("/", "limit-alice"), "")
+	if problem != nil {
+		t.Fatalf("global stream refused: %+v", problem)
+	}
+	if _, problem := limiter.Acquire(streamRequest("/", "limit-alice"), "limit-run-2"); problem == nil || problem.Ext["limit"] != "principal" {
+		t.Fatalf("expected principal limit problem, got %+v", problem)
+	}
+
+	releaseA()
+	releaseA()
+	releaseB()
+	if runs, principals := servermetrics.Default.SSEStreams("limit-run-1", "limit-alice"); runs != 0 || principals != 0 {
+		t.Fatalf("expected gauges cleared after release, got %d/%d", runs, principals)
+	}
+	if _, problem := limiter.Acquire(streamRequest("/", "limit-bob"), "limit-run-1"); problem != nil {
+		t.Fatalf("expected stream after release, got %+v", problem)
+	}
+}
+
+func TestRunEventsHandlerRefusesStreamsOverLimit(t *testing.T) {
+	store := runstore.New()
+	store.Create(runstore.Run{ID: "run-limit", JobID: "demo", Status: "queued", StartedAt: time.Unix(0, 0)})
+	hub := sse.New(sse.Config{KeepAliveInterval: time.Hour})
+	h := NewRunEventsHandler(store, hub, nil, NewStreamLimiter(StreamLimits{PerPrincipal: 1}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := streamRequest("/runs/run-limit/events", "limit-carol").WithContext(requestctx.WithPrincipal(ctx, "limit-carol"))
+	done := make(chan struct{})
+	go func() {
+		h.ServeHTTP(httptest.NewRecorder(), first)
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, streamRequest("/runs/run-limit/events", "limit-carol"))
+	cancel()
+	<-done
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var problem map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if problem["type"] != streamLimitProblemType || problem["limit"] != "principal" {
+		t.Fatalf("unexpected problem %v", problem)
+	}
+}
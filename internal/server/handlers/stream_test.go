@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteJSONArrayEmpty(t *testing.T) {
+	rec := httptest.NewRecorder()
+	if err := writeJSONArray(rec, 0, func(int) any { return nil }); err != nil {
+		t.Fatalf("writeJSONArray: %v", err)
+	}
+	if got := rec.Body.String(); got != "[]" {
+		t.Fatalf("expected empty array, got %q", got)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+}
+
+func TestWriteJSONArrayAcrossFlushes(t *testing.T) {
+	rec := httptest.NewRecorder()
+	n := streamFlushEvery*2 + 3
+	err := writeJSONArray(rec, n, func(i int) any {
+		return map[string]int{"i": i}
+	})
+	if err != nil {
+		t.Fatalf("writeJSONArray: %v", err)
+	}
+	if !rec.Flushed {
+		t.Fatalf("expected intermediate flush")
+	}
+	var items []map[string]int
+	if err := json.Unmarshal(rec.Body.Bytes(), &items); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(items) != n {
+		t.Fatalf("expected %d items, got %d", n, len(items))
+	}
+	for i, item := range items {
+		if item["i"] != i {
+			t.Fatalf("item %d out of order: %+v", i, item)
+		}
+	}
+}
@@ -0,0 +1,443 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/secrets"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/server/sse"
+)
+
+// WebhookEvents lists the run lifecycle and policy events outbound webhooks
+// can subscribe to.
+var WebhookEvents = []string{"run.start", "run.finish", "run.canceled", "policy.decision"}
+
+// Webhook delivery states.
+const (
+	WebhookPending   = "pending"
+	WebhookDelivered = "delivered"
+	WebhookFailed    = "failed"
+)
+
+const (
+	defaultWebhookAttempts = 5
+	defaultWebhookBackoff  = time.Second
+	maxWebhookBackoff      = time.Minute
+	defaultWebhookTimeout  = 10 * time.Second
+	defaultWebhookLogSize  = 1000
+)
+
+// WebhookEndpoint is one outbound webhook receiver.
+type WebhookEndpoint struct {
+	URL string
+	// SecretRef names the HMAC key, e.g. env:CI_HOOK_SECRET. It is resolved
+	// through the secrets provider for each delivery; when empty payloads
+	// are sent unsigned.
+	SecretRef string
+	// Events filters deliveries; empty subscribes to every WebhookEvents
+	// entry.
+	Events []string
+}
+
+// WebhookDeliveryConfig configures outbound webhooks.
+type WebhookDeliveryConfig struct {
+	Endpoints []WebhookEndpoint
+	Secrets   secrets.Provider
+	// MaxAttempts bounds attempts per delivery; zero means 5.
+	MaxAttempts int
+	// Backoff is the wait before the first retry. It doubles after every
+	// failed attempt, up to a minute; zero means one second.
+	Backoff time.Duration
+	// Timeout bounds each attempt; zero means 10s.
+	Timeout time.Duration
+	// LogSize bounds the delivery log; zero keeps the last 1000 deliveries.
+	LogSize int
+	Client  *http.Client
+	Logger  *slog.Logger
+}
+
+// WebhookDelivery is one entry of the delivery log.
+type WebhookDelivery struct {
+	ID    string `json:"id"`
+	Event string `json:"event"`
+	RunID string `json:"run_id,omitempty"`
+	// URL is the endpoint without credentials or query string.
+	URL    string `json:"url"`
+	Status string `json:"status"`
+	// Attempts counts the POSTs made so far.
+	Attempts      int        `json:"attempts"`
+	ResponseCode  int        `json:"response_code,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastAttemptAt *time.Time `json:"last_attempt_at,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+}
+
+// webhookPayload is the document POSTed to endpoints. Retries resend the
+// same document, so receivers can drop duplicates by id.
+type webhookPayload struct {
+	ID        string          `json:"id"`
+	Event     string          `json:"event"`
+	RunID     string          `json:"run_id,omitempty"`
+	JobID     string          `json:"job_id,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookDispatcher POSTs signed JSON payloads for run lifecycle and policy
+// events to the configured endpoints, for CI integrations that cannot hold an
+// SSE stream open. Deliveries run in the background, in order per run and
+// endpoint, and are retried with exponential backoff; a failing endpoint
+// never affects the run. Every delivery is recorded in a bounded in-memory
+// log served by GET /webhooks/deliveries.
+type WebhookDispatcher struct {
+	cfg  WebhookDeliveryConfig
+	runs *runstore.Store
+	ctx  context.Context
+	stop context.CancelFunc
+
+	mu sync.Mutex
+	// log holds deliveries oldest first.
+	log  []*WebhookDelivery
+	tail map[string]chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newWebhookDispatcher returns nil when no endpoints are configured.
+func newWebhookDispatcher(cfg WebhookDeliveryConfig, runs *runstore.Store) *WebhookDispatcher {
+	if len(cfg.Endpoints) == 0 {
+		return nil
+	}
+	if cfg.Secrets == nil {
+		cfg.Secrets = secrets.Default{}
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultWebhookAttempts
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = defaultWebhookBackoff
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultWebhookTimeout
+	}
+	if cfg.LogSize <= 0 {
+		cfg.LogSize = defaultWebhookLogSize
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	return &WebhookDispatcher{
+		cfg:  cfg,
+		runs: runs,
+		ctx:  ctx,
+		stop: stop,
+		tail: make(map[string]chan struct{}),
+	}
+}
+
+// Publish queues ev for every endpoint subscribed to it. Events other than
+// WebhookEvents are ignored.
+func (d *WebhookDispatcher) Publish(runID string, ev sse.Event) {
+	if d == nil || !slices.Contains(WebhookEvents, ev.Event) {
+		return
+	}
+	data := json.RawMessage(ev.Data)
+	if !json.Valid(data) {
+		data, _ = json.Marshal(ev.Data)
+	}
+	jobID := ""
+	if run, ok := d.runs.Get(runID); ok {
+		jobID = run.JobID
+	} else {
+		var fields struct {
+			JobID string `json:"job_id"`
+		}
+		_ = json.Unmarshal(data, &fields)
+		jobID = fields.JobID
+	}
+	now := time.Now().UTC()
+	for i, endpoint := range d.cfg.Endpoints {
+		if len(endpoint.Events) > 0 && !slices.Contains(endpoint.Events, ev.Event) {
+			continue
+		}
+		delivery := &WebhookDelivery{
+			ID:        newWebhookDeliveryID(),
+			Event:     ev.Event,
+			RunID:     runID,
+			URL:       webhookURLLabel(endpoint.URL),
+			Status:    WebhookPending,
+			CreatedAt: now,
+		}
+		body, err := json.Marshal(webhookPayload{
+			ID:        delivery.ID,
+			Event:     ev.Event,
+			RunID:     runID,
+			JobID:     jobID,
+			Timestamp: now,
+			Data:      data,
+		})
+		if err != nil {
+			continue
+		}
+		d.enqueue(strconv.Itoa(i)+"/"+runID, endpoint, delivery, body)
+	}
+}
+
+// Flush waits for queued deliveries, including their retries.
+func (d *WebhookDispatcher) Flush() {
+	if d == nil {
+		return
+	}
+	d.wg.Wait()
+}
+
+// Close abandons pending retries, marking them failed, and waits for
+// in-flight attempts.
+func (d *WebhookDispatcher) Close() {
+	if d == nil {
+		return
+	}
+	d.stop()
+	d.wg.Wait()
+}
+
+// Deliveries returns the delivery log, newest first.
+func (d *WebhookDispatcher) Deliveries() []WebhookDelivery {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make([]WebhookDelivery, 0, len(d.log))
+	for i := len(d.log) - 1; i >= 0; i-- {
+		out = append(out, *d.log[i])
+	}
+	return out
+}
+
+// enqueue records delivery and sends it after the previous delivery sharing
+// key.
+func (d *WebhookDispatcher) enqueue(key string, endpoint WebhookEndpoint, delivery *WebhookDelivery, body []byte) {
+	d.mu.Lock()
+	d.log = append(d.log, delivery)
+	if over := len(d.log) - d.cfg.LogSize; over > 0 {
+		d.log = slices.Delete(d.log, 0, over)
+	}
+	prev := d.tail[key]
+	done := make(chan struct{})
+	d.tail[key] = done
+	d.mu.Unlock()
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer func() {
+			close(done)
+			d.mu.Lock()
+			if d.tail[key] == done {
+				delete(d.tail, key)
+			}
+			d.mu.Unlock()
+		}()
+		if prev != nil {
+			<-prev
+		}
+		d.deliver(endpoint, delivery, body)
+	}()
+}
+
+// deliver POSTs body until it is accepted, fails permanently or runs out of
+// attempts.
+func (d *WebhookDispatcher) deliver(endpoint WebhookEndpoint, delivery *WebhookDelivery, body []byte) {
+	backoff := d.cfg.Backoff
+	for attempt := 1; ; attempt++ {
+		if d.ctx.Err() != nil {
+			d.update(delivery, func(w *WebhookDelivery) {
+				w.Status = WebhookFailed
+				w.Error = "server shutting down"
+				w.NextAttemptAt = nil
+			})
+			return
+		}
+		code, retry, err := d.post(endpoint, delivery, body)
+		now := time.Now().UTC()
+		d.update(delivery, func(w *WebhookDelivery) {
+			w.Attempts = attempt
+			w.ResponseCode = code
+			w.LastAttemptAt = &now
+			w.NextAttemptAt = nil
+			w.Error = ""
+			if err != nil {
+				w.Error = err.Error()
+			}
+		})
+		if err == nil {
+			d.update(delivery, func(w *WebhookDelivery) { w.Status = WebhookDelivered })
+			d.cfg.Logger.Info("webhook.delivered",
+				slog.String("delivery_id", delivery.ID),
+				slog.String("event", delivery.Event),
+				slog.String("run_id", delivery.RunID),
+				slog.String("url", delivery.URL),
+				slog.Int("attempts", attempt))
+			return
+		}
+		if !retry || attempt >= d.cfg.MaxAttempts {
+			d.update(delivery, func(w *WebhookDelivery) { w.Status = WebhookFailed })
+			d.cfg.Logger.Warn("webhook.failed",
+				slog.String("delivery_id", delivery.ID),
+				slog.String("event", delivery.Event),
+				slog.String("run_id", delivery.RunID),
+				slog.String("url", delivery.URL),
+				slog.Int("attempts", attempt),
+				slog.String("error", err.Error()))
+			return
+		}
+		next := now.Add(backoff)
+		d.update(delivery, func(w *WebhookDelivery) { w.NextAttemptAt = &next })
+		timer := time.NewTimer(backoff)
+		select {
+		case <-d.ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, maxWebhookBackoff)
+	}
+}
+
+// post makes one attempt. retry reports whether a failure is worth retrying:
+// network errors, 408, 429 and 5xx responses are, other statuses and
+// unresolvable secrets are not.
+func (d *WebhookDispatcher) post(endpoint WebhookEndpoint, delivery *WebhookDelivery, body []byte) (code int, retry bool, err error) {
+	ctx, cancel := context.WithTimeout(d.ctx, d.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "flowd-webhook")
+	req.Header.Set("X-Flowd-Event", delivery.Event)
+	req.Header.Set("X-Flowd-Delivery", delivery.ID)
+	if endpoint.SecretRef != "" {
+		key, err := d.cfg.Secrets.Resolve(ctx, endpoint.SecretRef)
+		if err != nil {
+			return 0, false, fmt.Errorf("resolve webhook secret: %w", err)
+		}
+		req.Header.Set("X-Flowd-Signature", "sha256="+signWebhookPayload([]byte(strings.TrimSpace(string(key))), body))
+	}
+	resp, err := d.cfg.Client.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<10))
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return resp.StatusCode, false, nil
+	}
+	retry = resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return resp.StatusCode, retry, fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
+
+func (d *WebhookDispatcher) update(delivery *WebhookDelivery, fn func(*WebhookDelivery)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	fn(delivery)
+}
+
+// signWebhookPayload returns the hex HMAC-SHA256 of body sent in
+// X-Flowd-Signature.
+func signWebhookPayload(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newWebhookDeliveryID() string {
+	var b [12]byte
+	_, _ = rand.Read(b[:])
+	return "whd_" + hex.EncodeToString(b[:])
+}
+
+// webhookURLLabel drops credentials and the query string, which often carry
+// tokens, from an endpoint URL.
+func webhookURLLabel(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// webhookTeeSink forwards events to next and to the webhook dispatcher.
+func webhookTeeSink(next EventSink, d *WebhookDispatcher) EventSink {
+	return EventSinkFunc(func(runID string, ev sse.Event) {
+		if next != nil {
+			next.Publish(runID, ev)
+		}
+		d.Publish(runID, ev)
+	})
+}
+
+// NewWebhookDeliveriesHandler serves GET /webhooks/deliveries, optionally
+// filtered by status, event and run_id.
+func NewWebhookDeliveriesHandler(d *WebhookDispatcher, pagination Pagination) http.Handler {
+	pagination = pagination.normalized()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+			return
+		}
+		page, perPage, err := pagination.parse(r)
+		if err != nil {
+			response.Write(w, response.New(http.StatusBadRequest, "invalid pagination", response.WithDetail(err.Error())))
+			return
+		}
+		query := r.URL.Query()
+		status := query.Get("status")
+		switch status {
+		case "", WebhookPending, WebhookDelivered, WebhookFailed:
+		default:
+			response.Write(w, response.New(http.StatusBadRequest, "invalid status filter",
+				response.WithDetail(fmt.Sprintf("status %q is not one of pending, delivered or failed", status))))
+			return
+		}
+		event, runID := query.Get("event"), query.Get("run_id")
+		deliveries := make([]WebhookDelivery, 0)
+		for _, delivery := range d.Deliveries() {
+			if (status == "" || delivery.Status == status) &&
+				(event == "" || delivery.Event == event) &&
+				(runID == "" || delivery.RunID == runID) {
+				deliveries = append(deliveries, delivery)
+			}
+		}
+		writePaginationHeaders(w, len(deliveries), page, perPage)
+		start := min((page-1)*perPage, len(deliveries))
+		end := min(start+perPage, len(deliveries))
+		writeJSON(w, deliveries[start:end], http.StatusOK)
+	})
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/secrets"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/server/sse"
+)
+
+type webhookCall struct {
+	header http.Header
+	body   []byte
+}
+
+func TestWebhookDispatcherSignsRetriesAndLogs(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []webhookCall
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		calls = append(calls, webhookCall{header: r.Header.Clone(), body: body})
+		n := len(calls)
+		mu.Unlock()
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer rejecting.Close()
+
+	store := runstore.New()
+	store.Create(runstore.Run{ID: "run-1", JobID: "deploy", Status: "running"})
+	d := newWebhookDispatcher(WebhookDeliveryConfig{
+		Endpoints: []WebhookEndpoint{
+			{URL: srv.URL + "/hook?token=abc", SecretRef: "env:HOOK_KEY", Events: []string{"run.finish"}},
+			{URL: rejecting.URL, Events: []string{"run.canceled"}},
+		},
+		Secrets: secrets.Map{"env:HOOK_KEY": "s3cret\n"},
+		Backoff: time.Millisecond,
+	}, store)
+
+	d.Publish("run-1", sse.Event{Event: "step.log", Data: `{"message":"hi"}`})
+	d.Publish("run-1", sse.Event{Event: "run.finish", Data: `{"status":"completed"}`})
+	d.Publish("run-1", sse.Event{Event: "run.canceled", Data: `{"status":"canceled"}`})
+	d.Flush()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) != 2 {
+		t.Fatalf("expected one retry of run.finish, got %d calls", len(calls))
+	}
+	first, second := calls[0], calls[1]
+	if string(first.body) != string(second.body) {
+		t.Fatalf("expected retries to resend the same payload")
+	}
+	if got, want := second.header.Get("X-Flowd-Signature"), "sha256="+signWebhookPayload([]byte("s3cret"), second.body); got != want {
+		t.Fatalf("signature = %q, want %q", got, want)
+	}
+	var payload webhookPayload
+	if err := json.Unmarshal(second.body, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.Event != "run.finish" || payload.RunID != "run-1" || payload.JobID != "deploy" || string(payload.Data) != `{"status":"completed"}` {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+	if second.header.Get("X-Flowd-Delivery") != payload.ID || second.header.Get("X-Flowd-Event") != "run.finish" {
+		t.Fatalf("unexpected delivery headers %v", second.header)
+	}
+
+	deliveries := d.Deliveries()
+	if len(deliveries) != 2 {
+		t.Fatalf("expected two logged deliveries, got %+v", deliveries)
+	}
+	canceled, finished := deliveries[0], deliveries[1]
+	if finished.Status != WebhookDelivered || finished.Attempts != 2 || finished.ResponseCode != http.StatusNoContent {
+		t.Fatalf("unexpected run.finish delivery %+v", finished)
+	}
+	if finished.URL != srv.URL+"/hook" {
+		t.Fatalf("expected the query string dropped from the logged url, got %q", finished.URL)
+	}
+	if canceled.Status != WebhookFailed || canceled.Attempts != 1 || canceled.ResponseCode != http.StatusGone {
+		t.Fatalf("expected a 410 to fail without retrying, got %+v", canceled)
+	}
+
+	rec := httptest.NewRecorder()
+	NewWebhookDeliveriesHandler(d, Pagination{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/webhooks/deliveries?status=failed", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET deliveries: %d %s", rec.Code, rec.Body.String())
+	}
+	var listed []WebhookDelivery
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("decode deliveries: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Event != "run.canceled" {
+		t.Fatalf("expected only the failed delivery, got %+v", listed)
+	}
+}
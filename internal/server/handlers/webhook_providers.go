@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var (
+	commitSHAPattern  = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+	zeroCommitPattern = regexp.MustCompile(`^0+$`)
+)
+
+// webhookProvider authenticates and parses one forge's deliveries. parse
+// returns a non-empty reason for deliveries that should not start a run.
+type webhookProvider struct {
+	verify func(secret, body []byte, header http.Header) bool
+	parse  func(header http.Header, body []byte) (webhookTrigger, string, error)
+}
+
+var webhookProviders = map[string]webhookProvider{
+	"github": {verify: verifyGitHubSignature, parse: parseGitHubDelivery},
+	"gitlab": {verify: verifyGitLabToken, parse: parseGitLabDelivery},
+	"gitea":  {verify: verifyGiteaSignature, parse: parseGiteaDelivery},
+}
+
+// verifyGitHubSignature checks the X-Hub-Signature-256 HMAC of body.
+func verifyGitHubSignature(secret, body []byte, header http.Header) bool {
+	sig, ok := strings.CutPrefix(strings.TrimSpace(header.Get("X-Hub-Signature-256")), "sha256=")
+	return ok && verifyHMACHex(secret, body, sig)
+}
+
+// verifyGiteaSignature checks the X-Gitea-Signature HMAC of body, which is
+// hex encoded without an algorithm prefix.
+func verifyGiteaSignature(secret, body []byte, header http.Header) bool {
+	return verifyHMACHex(secret, body, strings.TrimSpace(header.Get("X-Gitea-Signature")))
+}
+
+// verifyGitLabToken compares the X-Gitlab-Token header with the secret;
+// GitLab sends the shared token itself rather than a signature.
+func verifyGitLabToken(secret, _ []byte, header http.Header) bool {
+	token := header.Get("X-Gitlab-Token")
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), secret) == 1
+}
+
+func verifyHMACHex(secret, body []byte, sig string) bool {
+	got, err := hex.DecodeString(sig)
+	if err != nil || len(got) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// githubStylePayload is the subset of push and pull_request deliveries that
+// GitHub and Gitea share.
+type githubStylePayload struct {
+	Ref         string `json:"ref"`
+	After       string `json:"after"`
+	Deleted     bool   `json:"deleted"`
+	Action      string `json:"action"`
+	PullRequest struct {
+		Head struct {
+			SHA string `json:"sha"`
+			Ref string `json:"ref"`
+		} `json:"head"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// parseGitHubDelivery extracts the commit to build from push and
+// pull_request deliveries. Other events, branch deletions and pull request
+// actions that do not change code are ignored with a reason.
+func parseGitHubDelivery(header http.Header, body []byte) (webhookTrigger, string, error) {
+	trigger := webhookTrigger{Event: header.Get("X-GitHub-Event"), Delivery: header.Get("X-GitHub-Delivery")}
+	return parseGitHubStyle(trigger, body, "synchronize")
+}
+
+// parseGiteaDelivery handles Gitea's GitHub-compatible push and pull_request
+// deliveries; Gitea names new pull request commits "synchronized".
+func parseGiteaDelivery(header http.Header, body []byte) (webhookTrigger, string, error) {
+	trigger := webhookTrigger{Event: header.Get("X-Gitea-Event"), Delivery: header.Get("X-Gitea-Delivery")}
+	return parseGitHubStyle(trigger, body, "synchronized")
+}
+
+func parseGitHubStyle(trigger webhookTrigger, body []byte, syncAction string) (webhookTrigger, string, error) {
+	switch trigger.Event {
+	case "push", "pull_request":
+	case "ping":
+		return trigger, "ping", nil
+	default:
+		return trigger, fmt.Sprintf("event %q is not handled", trigger.Event), nil
+	}
+	var payload githubStylePayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return trigger, "", err
+	}
+	trigger.Repository = payload.Repository.FullName
+	if trigger.Event == "push" {
+		if payload.Deleted {
+			return trigger, "branch deleted", nil
+		}
+		trigger.Ref = payload.Ref
+		trigger.Commit = payload.After
+	} else {
+		switch payload.Action {
+		case "opened", "reopened", syncAction:
+		default:
+			return trigger, fmt.Sprintf("pull_request action %q is not handled", payload.Action), nil
+		}
+		trigger.Ref = payload.PullRequest.Head.Ref
+		trigger.Commit = payload.PullRequest.Head.SHA
+	}
+	return checkTrigger(trigger)
+}
+
+// parseGitLabDelivery handles push and merge request hooks. Merge request
+// updates only start a run when they add commits, which GitLab signals with
+// oldrev.
+func parseGitLabDelivery(header http.Header, body []byte) (webhookTrigger, string, error) {
+	trigger := webhookTrigger{Event: header.Get("X-Gitlab-Event"), Delivery: header.Get("X-Gitlab-Event-UUID")}
+	var payload struct {
+		ObjectKind  string `json:"object_kind"`
+		Ref         string `json:"ref"`
+		CheckoutSHA string `json:"checkout_sha"`
+		After       string `json:"after"`
+		Project     struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+		} `json:"project"`
+		ObjectAttributes struct {
+			Action       string `json:"action"`
+			OldRev       string `json:"oldrev"`
+			SourceBranch string `json:"source_branch"`
+			LastCommit   struct {
+				ID string `json:"id"`
+			} `json:"last_commit"`
+		} `json:"object_attributes"`
+	}
+	switch trigger.Event {
+	case "Push Hook", "Merge Request Hook":
+	default:
+		return trigger, fmt.Sprintf("event %q is not handled", trigger.Event), nil
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return trigger, "", err
+	}
+	trigger.Repository = payload.Project.PathWithNamespace
+	if payload.ObjectKind == "push" {
+		trigger.Ref = payload.Ref
+		trigger.Commit = payload.After
+	} else {
+		attrs := payload.ObjectAttributes
+		switch {
+		case attrs.Action == "open", attrs.Action == "reopen":
+		case attrs.Action == "update" && attrs.OldRev != "":
+		default:
+			return trigger, fmt.Sprintf("merge request action %q is not handled", attrs.Action), nil
+		}
+		trigger.Ref = attrs.SourceBranch
+		trigger.Commit = attrs.LastCommit.ID
+	}
+	return checkTrigger(trigger)
+}
+
+// checkTrigger ignores deliveries for deleted refs, whose commit is all
+// zeros, and rejects malformed commits before they reach git.
+func checkTrigger(trigger webhookTrigger) (webhookTrigger, string, error) {
+	if zeroCommitPattern.MatchString(trigger.Commit) {
+		return trigger, "branch deleted", nil
+	}
+	if !commitSHAPattern.MatchString(trigger.Commit) {
+		return trigger, "", fmt.Errorf("invalid commit %q", trigger.Commit)
+	}
+	if trigger.Repository == "" {
+		return trigger, "", errors.New("repository is required")
+	}
+	return trigger, "", nil
+}
@@ -0,0 +1,238 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/flowd-org/flowd/internal/secrets"
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/sourcestore"
+)
+
+// WebhooksConfig configures the forge webhook receiver.
+type WebhooksConfig struct {
+	Sources     *sourcestore.Store
+	CheckoutDir string
+	Runs        *RunsHandler
+	Secrets     secrets.Provider
+	// Reporting, when set, reports the status of triggered runs back to the
+	// forge for sources that configure a reporter.
+	Reporting *RunReporter
+}
+
+// WebhooksHandler serves POST /webhooks/{source}. Deliveries authenticate
+// with the source's shared secret instead of a bearer token.
+type WebhooksHandler struct {
+	cfg WebhooksConfig
+	// mu serialises checkouts so concurrent deliveries cannot interleave
+	// resets of the same working tree.
+	mu sync.Mutex
+}
+
+// NewWebhooksHandler constructs the webhook receiver.
+func NewWebhooksHandler(cfg WebhooksConfig) *WebhooksHandler {
+	if cfg.Sources == nil {
+		cfg.Sources = sourcestore.New()
+	}
+	if cfg.Secrets == nil {
+		cfg.Secrets = secrets.Default{}
+	}
+	return &WebhooksHandler{cfg: cfg}
+}
+
+// webhookTrigger is the commit a delivery asks flowd to build.
+type webhookTrigger struct {
+	Event      string
+	Delivery   string
+	Repository string
+	Ref        string
+	Commit     string
+}
+
+func (t webhookTrigger) provenance(provider, source string) map[string]any {
+	out := map[string]any{
+		"type":       "webhook",
+		"provider":   provider,
+		"source":     source,
+		"event":      t.Event,
+		"repository": t.Repository,
+		"commit":     t.Commit,
+	}
+	if t.Ref != "" {
+		out["ref"] = t.Ref
+	}
+	if t.Delivery != "" {
+		out["delivery"] = t.Delivery
+	}
+	return out
+}
+
+func (h *WebhooksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+		return
+	}
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/webhooks/"), "/")
+	src, ok := h.cfg.Sources.Get(name)
+	if name == "" || strings.Contains(name, "/") || !ok || src.Webhook == nil {
+		response.Write(w, response.New(http.StatusNotFound, "webhook not found", response.WithDetail(name)))
+		return
+	}
+	hook := src.Webhook
+
+	defer r.Body.Close()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Write(w, response.New(http.StatusBadRequest, "invalid request body", response.WithDetail(err.Error())))
+		return
+	}
+	secret, err := h.cfg.Secrets.Resolve(r.Context(), hook.SecretRef)
+	if err != nil {
+		logWebhook(r, "webhook.secret.unavailable", name, slog.String("error", err.Error()))
+		response.Write(w, response.New(http.StatusInternalServerError, "webhook secret unavailable",
+			response.WithExtension("code", "webhook.secret.unavailable")))
+		return
+	}
+	provider := webhookProviders[hook.Provider]
+	if !provider.verify(secret, body, r.Header) {
+		response.Write(w, response.New(http.StatusUnauthorized, "invalid webhook signature",
+			response.WithExtension("code", "webhook.signature.invalid")))
+		return
+	}
+
+	trigger, ignored, err := provider.parse(r.Header, body)
+	if err != nil {
+		response.Write(w, response.New(http.StatusBadRequest, "invalid webhook payload", response.WithDetail(err.Error())))
+		return
+	}
+	if ignored != "" {
+		writeJSON(w, map[string]string{"status": "ignored", "reason": ignored}, http.StatusAccepted)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if prob := h.checkout(r, &src, trigger.Commit); prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+	run, prob := h.cfg.Runs.launchRun(r.Context(), runRequest{
+		JobID:   hook.Job,
+		Source:  &RunSourceRef{Name: src.Name},
+		Trigger: trigger.provenance(hook.Provider, src.Name),
+	})
+	if prob != nil {
+		response.Write(w, *prob)
+		return
+	}
+	if h.cfg.Reporting != nil {
+		h.cfg.Reporting.Queued(run)
+	}
+	logWebhook(r, "webhook.run.started", name,
+		slog.String("event", trigger.Event),
+		slog.String("commit", trigger.Commit),
+		slog.String("run_id", run.ID))
+	writeJSON(w, run, http.StatusAccepted)
+}
+
+// checkout moves the source's working tree to commit and records it as the
+// source's resolved revision.
+func (h *WebhooksHandler) checkout(r *http.Request, src *sourcestore.Source, commit string) *response.Problem {
+	repo := src.URL
+	if parsed, err := url.Parse(repo); err == nil && isLocalGitURL(parsed) {
+		if abs, err := localGitPath(parsed, repo); err == nil {
+			repo = abs
+		}
+	}
+	resolved, checkoutPath, err := materializeGitSource(r.Context(), h.cfg.CheckoutDir, src.Name, repo, commit)
+	if err != nil {
+		prob := response.New(http.StatusUnprocessableEntity, "git checkout failed",
+			response.WithExtension("code", "webhook.checkout.failed"),
+			response.WithDetail(err.Error()))
+		return &prob
+	}
+	src.ResolvedRef = resolved
+	src.ResolvedCommit = resolved
+	src.LocalPath = checkoutPath
+	src.Metadata = cloneAnyMap(src.Metadata)
+	src.Metadata["resolved_commit"] = resolved
+	src.Metadata["checkout_path"] = checkoutPath
+	src.Provenance = cloneAnyMap(src.Provenance)
+	src.Provenance["resolved_commit"] = resolved
+	h.cfg.Sources.Upsert(*src)
+	return nil
+}
+
+// normalizeWebhook validates a source's webhook configuration.
+func normalizeWebhook(in sourcestore.Webhook) (*sourcestore.Webhook, error) {
+	out := in
+	out.Provider = strings.ToLower(strings.TrimSpace(in.Provider))
+	out.Job = strings.TrimSpace(in.Job)
+	out.SecretRef = strings.TrimSpace(in.SecretRef)
+	if _, ok := webhookProviders[out.Provider]; !ok {
+		return nil, fmt.Errorf("unsupported webhook provider %q", in.Provider)
+	}
+	if out.Job == "" {
+		return nil, errors.New("webhook job is required")
+	}
+	if out.SecretRef == "" {
+		return nil, errors.New("webhook secret_ref is required")
+	}
+	if in.Reporter == nil {
+		return &out, nil
+	}
+	rep := *in.Reporter
+	rep.Type = strings.ToLower(strings.TrimSpace(rep.Type))
+	if rep.Type == "" {
+		rep.Type = out.Provider
+	}
+	switch rep.Type {
+	case "github":
+		if rep.AppID <= 0 || rep.InstallationID <= 0 {
+			return nil, errors.New("github reporter requires app_id and installation_id")
+		}
+		if strings.TrimSpace(rep.PrivateKeyRef) == "" {
+			return nil, errors.New("github reporter requires private_key_ref")
+		}
+	case "gitlab", "gitea":
+		if strings.TrimSpace(rep.TokenRef) == "" {
+			return nil, fmt.Errorf("%s reporter requires token_ref", rep.Type)
+		}
+		if rep.Type == "gitea" && strings.TrimSpace(rep.APIURL) == "" {
+			return nil, errors.New("gitea reporter requires api_url")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported reporter type %q", in.Reporter.Type)
+	}
+	if rep.Type != out.Provider {
+		return nil, fmt.Errorf("reporter %s cannot report %s webhooks", rep.Type, out.Provider)
+	}
+	out.Reporter = &rep
+	return &out, nil
+}
+
+func cloneAnyMap(in map[string]any) map[string]any {
+	out := make(map[string]any, len(in)+1)
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
+
+func logWebhook(r *http.Request, msg, source string, attrs ...any) {
+	logger := requestctx.Logger(r.Context())
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Info(msg, append([]any{slog.String("source", source)}, attrs...)...)
+}
@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/secrets"
+	"github.com/flowd-org/flowd/internal/server/reporter"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/server/sourcestore"
+	"github.com/flowd-org/flowd/internal/server/sse"
+)
+
+const testWebhookSecret = "hook-secret"
+
+type fakeChecksAPI struct {
+	mu    sync.Mutex
+	calls []map[string]any
+}
+
+func (f *fakeChecksAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/access_tokens"):
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"token":"inst","expires_at":"2099-01-01T00:00:00Z"}`))
+		return
+	case strings.HasPrefix(r.URL.Path, "/repos/acme/app/check-runs"):
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		body["_method"] = r.Method
+		f.mu.Lock()
+		f.calls = append(f.calls, body)
+		f.mu.Unlock()
+		_, _ = w.Write([]byte(`{"id":5}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeChecksAPI) snapshot() []map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]map[string]any(nil), f.calls...)
+}
+
+// createWebhookRepo commits a build job twice and returns the repo and both
+// commits, oldest first.
+func createWebhookRepo(t *testing.T) (string, []string) {
+	t.Helper()
+	repo := t.TempDir()
+	runGitTest(t, repo, "init")
+	runGitTest(t, repo, "config", "user.name", "Runner Tests")
+	runGitTest(t, repo, "config", "user.email", "flwd-tests@example.com")
+	runGitTest(t, repo, "symbolic-ref", "HEAD", "refs/heads/main")
+	jobDir := filepath.Join(repo, "scripts", "build")
+	if err := os.MkdirAll(filepath.Join(jobDir, "config.d"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(jobDir, "config.d", "config.yaml"), []byte("version: v1\njob:\n  id: build\n  name: Build\ninterpreter: bash\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	var commits []string
+	for _, msg := range []string{"one", "two"} {
+		script := "#!/usr/bin/env bash\necho " + msg + "\n"
+		if err := os.WriteFile(filepath.Join(jobDir, "100_main.sh"), []byte(script), 0o755); err != nil {
+			t.Fatalf("write script: %v", err)
+		}
+		runGitTest(t, repo, "add", ".")
+		runGitTest(t, repo, "commit", "-m", msg)
+		commits = append(commits, strings.TrimSpace(runGitTest(t, repo, "rev-parse", "HEAD")))
+	}
+	return repo, commits
+}
+
+func testAppKeyPEM(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+func signWebhook(body string) string {
+	mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func postWebhook(t *testing.T, h http.Handler, event, body, signature string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/app", strings.NewReader(body))
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-GitHub-Delivery", "delivery-1")
+	req.Header.Set("X-Hub-Signature-256", signature)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestWebhookPushRunsCommitAndReportsChecks(t *testing.T) {
+	api := &fakeChecksAPI{}
+	apiSrv := httptest.NewServer(api)
+	defer apiSrv.Close()
+
+	repo, commits := createWebhookRepo(t)
+	provider := secrets.Map{"env:HOOK": testWebhookSecret, "file:app.pem": testAppKeyPEM(t)}
+	sources := sourcestore.New()
+	checkoutDir := filepath.Join(t.TempDir(), "checkouts")
+	sourcesHandler := NewSourcesHandler(SourcesConfig{Store: sources, AllowLocalRoots: []string{repo}, CheckoutDir: checkoutDir})
+	repoURL := url.URL{Scheme: "file", Path: filepath.ToSlash(repo)}
+	body := `{"type":"git","name":"app","url":"` + repoURL.String() + `","ref":"main","webhook":{"provider":"github","job":"build","secret_ref":"env:HOOK",` +
+		`"reporter":{"app_id":1,"installation_id":2,"private_key_ref":"file:app.pem","api_url":"` + apiSrv.URL + `"}}}`
+	req := httptest.NewRequest(http.MethodPost, "/sources", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	sourcesHandler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("register source: %d %s", rec.Code, rec.Body.String())
+	}
+
+	store := runstore.New()
+	reporting := NewRunReporter(RunReporterConfig{Runs: store, Sources: sources, Secrets: provider, PublicURL: "https://flowd.example/"})
+	sink := &recordingSink{}
+	runs := NewRunsHandler(RunsConfig{
+		Root:    t.TempDir(),
+		Store:   store,
+		Sources: sources,
+		Events: EventSinkFunc(func(runID string, ev sse.Event) {
+			sink.Publish(runID, ev)
+			reporting.Publish(runID, ev)
+		}),
+	})
+	hooks := NewWebhooksHandler(WebhooksConfig{Sources: sources, CheckoutDir: checkoutDir, Runs: runs, Secrets: provider, Reporting: reporting})
+
+	push := `{"ref":"refs/heads/main","after":"` + commits[0] + `","repository":{"full_name":"acme/app"}}`
+	if rec := postWebhook(t, hooks, "push", push, "sha256=00"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected bad signature to be refused, got %d", rec.Code)
+	}
+	if rec := postWebhook(t, hooks, "ping", `{}`, signWebhook(`{}`)); rec.Code != http.StatusAccepted || !strings.Contains(rec.Body.String(), "ignored") {
+		t.Fatalf("expected ping to be ignored, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = postWebhook(t, hooks, "push", push, signWebhook(push))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected run to start, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var run map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &run)
+	prov, _ := run["provenance"].(map[string]any)
+	trigger, _ := prov["trigger"].(map[string]any)
+	if trigger["commit"] != commits[0] || trigger["repository"] != "acme/app" || trigger["event"] != "push" {
+		t.Fatalf("unexpected trigger provenance %v", prov["trigger"])
+	}
+	if src, _ := sources.Get("app"); src.ResolvedCommit != commits[0] {
+		t.Fatalf("expected checkout at %s, got %s", commits[0], src.ResolvedCommit)
+	}
+
+	waitFor(func() bool {
+		calls := api.snapshot()
+		return len(calls) > 0 && calls[len(calls)-1]["status"] == "completed"
+	}, 5*time.Second, t)
+	calls := api.snapshot()
+	if len(calls) != 3 {
+		t.Fatalf("expected queued, in_progress and completed reports, got %v", calls)
+	}
+	if calls[0]["_method"] != http.MethodPost || calls[0]["head_sha"] != commits[0] || calls[0]["status"] != "queued" {
+		t.Fatalf("unexpected check creation %v", calls[0])
+	}
+	if calls[1]["status"] != "in_progress" {
+		t.Fatalf("expected in_progress update, got %v", calls[1])
+	}
+	final := calls[2]
+	if final["conclusion"] != "success" || final["details_url"] != "https://flowd.example/runs/"+run["id"].(string) {
+		t.Fatalf("unexpected completion %v", final)
+	}
+	output, _ := final["output"].(map[string]any)
+	if summary, _ := output["summary"].(string); !strings.Contains(summary, "100_main.sh") || !strings.Contains(summary, "/events") {
+		t.Fatalf("expected step summary with log link, got %q", summary)
+	}
+}
+
+func TestNormalizeWebhookValidation(t *testing.T) {
+	cases := []sourcestore.Webhook{
+		{Provider: "bitbucket", Job: "build", SecretRef: "env:X"},
+		{Provider: "github", SecretRef: "env:X"},
+		{Provider: "github", Job: "build"},
+		{Provider: "github", Job: "build", SecretRef: "env:X", Reporter: &sourcestore.Reporter{AppID: 1}},
+	}
+	for _, tc := range cases {
+		if _, err := normalizeWebhook(tc); err == nil {
+			t.Fatalf("expected %+v to be rejected", tc)
+		}
+	}
+	got, err := normalizeWebhook(sourcestore.Webhook{Provider: "GitHub", Job: "build", SecretRef: "env:X",
+		Reporter: &sourcestore.Reporter{AppID: 1, InstallationID: 2, PrivateKeyRef: "file:k"}})
+	if err != nil || got.Provider != "github" || got.Reporter.Type != "github" {
+		t.Fatalf("unexpected normalisation %+v, %v", got, err)
+	}
+}
+
+func TestRunReporterIgnoresUntriggeredRuns(t *testing.T) {
+	store := runstore.New()
+	store.Create(runstore.Run{ID: "r1", JobID: "build", Status: "running"})
+	built := 0
+	reporting := NewRunReporter(RunReporterConfig{Runs: store, Sources: sourcestore.New(),
+		NewReporter: func(context.Context, sourcestore.Reporter, secrets.Provider) (reporter.Reporter, error) {
+			built++
+			return nil, nil
+		}})
+	reporting.Publish("r1", sse.Event{Event: "run.start", Data: `{}`})
+	reporting.Publish("r1", sse.Event{Event: "run.finish", Data: `{"status":"completed"}`})
+	if built != 0 || len(reporting.runs) != 0 || len(reporting.ignored) != 0 {
+		t.Fatalf("expected untriggered run to be ignored and forgotten")
+	}
+}
+
+func TestWebhookGitLabPushReportsCommitStatus(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		states []string
+	)
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if r.Header.Get("PRIVATE-TOKEN") != "glpat" || !strings.HasPrefix(r.URL.EscapedPath(), "/projects/group%2Fapp/statuses/") {
+			t.Errorf("unexpected status request %s %v", r.URL.EscapedPath(), r.Header)
+		}
+		mu.Lock()
+		states = append(states, body["state"].(string))
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer apiSrv.Close()
+
+	repo, commits := createWebhookRepo(t)
+	provider := secrets.Map{"env:HOOK": testWebhookSecret, "env:GITLAB_TOKEN": "glpat"}
+	sources := sourcestore.New()
+	checkoutDir := filepath.Join(t.TempDir(), "checkouts")
+	sourcesHandler := NewSourcesHandler(SourcesConfig{Store: sources, AllowLocalRoots: []string{repo}, CheckoutDir: checkoutDir})
+	repoURL := url.URL{Scheme: "file", Path: filepath.ToSlash(repo)}
+	body := `{"type":"git","name":"app","url":"` + repoURL.String() + `","ref":"main","webhook":{"provider":"gitlab","job":"build","secret_ref":"env:HOOK",` +
+		`"reporter":{"type":"gitlab","token_ref":"env:GITLAB_TOKEN","api_url":"` + apiSrv.URL + `"}}}`
+	rec := httptest.NewRecorder()
+	sourcesHandler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/sources", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("register source: %d %s", rec.Code, rec.Body.String())
+	}
+
+	store := runstore.New()
+	reporting := NewRunReporter(RunReporterConfig{Runs: store, Sources: sources, Secrets: provider})
+	runs := NewRunsHandler(RunsConfig{Root: t.TempDir(), Store: store, Sources: sources, Events: reporting})
+	hooks := NewWebhooksHandler(WebhooksConfig{Sources: sources, CheckoutDir: checkoutDir, Runs: runs, Secrets: provider, Reporting: reporting})
+
+	push := `{"object_kind":"push","ref":"refs/heads/main","after":"` + commits[1] + `","project":{"path_with_namespace":"group/app"}}`
+	send := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/app", strings.NewReader(push))
+		req.Header.Set("X-Gitlab-Event", "Push Hook")
+		req.Header.Set("X-Gitlab-Token", token)
+		rec := httptest.NewRecorder()
+		hooks.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := send("wrong"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected wrong token to be refused, got %d", rec.Code)
+	}
+	if rec := send(testWebhookSecret); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected run to start, got %d: %s", rec.Code, rec.Body.String())
+	}
+	waitFor(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(states) == 3
+	}, 5*time.Second, t)
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(states, ",") != "pending,running,success" {
+		t.Fatalf("unexpected status sequence %v", states)
+	}
+}
+
+func TestWebhookProvidersParseDeliveries(t *testing.T) {
+	sha := strings.Repeat("a", 40)
+	cases := []struct {
+		name     string
+		provider string
+		event    string
+		body     string
+		commit   string
+		ignored  bool
+	}{
+		{"gitea push", "gitea", "push", `{"ref":"refs/heads/main","after":"` + sha + `","repository":{"full_name":"org/app"}}`, sha, false},
+		{"gitea pr sync", "gitea", "pull_request", `{"action":"synchronized","pull_request":{"head":{"sha":"` + sha + `"}},"repository":{"full_name":"org/app"}}`, sha, false},
+		{"gitea pr closed", "gitea", "pull_request", `{"action":"closed","repository":{"full_name":"org/app"}}`, "", true},
+		{"gitea branch deleted", "gitea", "push", `{"ref":"refs/heads/x","after":"` + strings.Repeat("0", 40) + `","repository":{"full_name":"org/app"}}`, "", true},
+		{"gitlab mr update with commits", "gitlab", "Merge Request Hook", `{"object_kind":"merge_request","project":{"path_with_namespace":"g/app"},"object_attributes":{"action":"update","oldrev":"x","last_commit":{"id":"` + sha + `"}}}`, sha, false},
+		{"gitlab mr title edit", "gitlab", "Merge Request Hook", `{"object_kind":"merge_request","project":{"path_with_namespace":"g/app"},"object_attributes":{"action":"update"}}`, "", true},
+		{"gitlab tag push", "gitlab", "Tag Push Hook", `{}`, "", true},
+	}
+	for _, tc := range cases {
+		header := http.Header{}
+		header.Set("X-Gitea-Event", tc.event)
+		header.Set("X-Gitlab-Event", tc.event)
+		trigger, ignored, err := webhookProviders[tc.provider].parse(header, []byte(tc.body))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if (ignored != "") != tc.ignored || (!tc.ignored && trigger.Commit != tc.commit) {
+			t.Fatalf("%s: unexpected result %+v ignored=%q", tc.name, trigger, ignored)
+		}
+	}
+
+	body := []byte(`{"x":1}`)
+	header := http.Header{}
+	header.Set("X-Gitea-Signature", strings.TrimPrefix(signWebhook(string(body)), "sha256="))
+	if !verifyGiteaSignature([]byte(testWebhookSecret), body, header) {
+		t.Fatalf("expected gitea signature to verify")
+	}
+	if verifyGiteaSignature([]byte("other"), body, header) {
+		t.Fatalf("expected gitea signature with another secret to fail")
+	}
+}
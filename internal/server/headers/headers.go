@@ -3,4 +3,14 @@ package headers
 
 const (
 	DiscoveryErrors = "X-Flowd-Discovery-Errors"
+	// TotalCount and RemainingCount carry pagination metadata on list
+	// endpoints: the number of items in the full listing and the number
+	// after the returned page.
+	TotalCount     = "X-Total-Count"
+	RemainingCount = "X-Remaining-Count"
+	// APIVersion carries the API specification version: clients send the
+	// version they implement and the server answers with its own.
+	// ServerVersion is the flowd build version on responses.
+	APIVersion    = "X-Flowd-API-Version"
+	ServerVersion = "X-Flowd-Version"
 )
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/coredb"
+)
+
+// defaultJournalCompactInterval is how often the event journal is compacted
+// when a retention is configured.
+const defaultJournalCompactInterval = 10 * time.Minute
+
+// startJournalCompaction drops the journaled events of runs that have been
+// quiet for longer than cfg.Retention, every cfg.Interval. The returned
+// function stops the loop. A zero retention keeps events until the journal
+// size budget evicts them.
+func startJournalCompaction(journal *coredb.Journal, cfg EventJournalConfig, logger *slog.Logger) func() {
+	if journal == nil || cfg.Retention <= 0 {
+		return func() {}
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultJournalCompactInterval
+	}
+	compact := func(ctx context.Context) {
+		deleted, err := journal.Compact(ctx, time.Now().UTC().Add(-cfg.Retention))
+		if err != nil {
+			logger.Warn("journal.compact.failed", slog.String("error", err.Error()))
+			return
+		}
+		if deleted > 0 {
+			logger.Info("journal.compacted", slog.Int64("events", deleted))
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		compact(ctx)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				compact(ctx)
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
@@ -18,6 +18,8 @@ type Registry struct {
 	mu sync.Mutex
 
 	httpRequests          *httpHistogram
+	handlerLatency        map[[2]string]*simpleHistogram
+	responseClasses       map[[3]string]uint64
 	securityProfileGauge  string
 	buildInfoLabels       map[string]string
 	policyDenials         map[string]uint64
@@ -33,14 +35,32 @@ type Registry struct {
 	sseActive             map[string]int64
 	sseResumeTotal        uint64
 	sseCursorExpiredTotal uint64
+	sseRunStreams         map[string]int64
+	ssePrincipalStreams   map[string]int64
+	sseStreamsRejected    map[string]uint64
+	planCache             map[string]uint64
+	runsActive            int64
+	eventsDropped         map[string]uint64
+	logLinesTruncated     map[string]uint64
+	restored              map[string]map[string]uint64
+	restoredAt            time.Time
+
+	// runsFinished and runDurations count runs reaching a terminal status;
+	// runStatus reports the runs currently held per status at scrape time.
+	runsFinished       map[string]uint64
+	runDurations       map[string]*simpleHistogram
+	runStatus          func() map[string]int
+	idempotencyReplays map[string]uint64
 }
 
 // NewRegistry constructs a metrics registry with default buckets.
 func NewRegistry() *Registry {
 	r := &Registry{
-		httpRequests:  newHTTPHistogram(),
-		policyDenials: make(map[string]uint64),
-		sourcesAdded:  make(map[string]uint64),
+		httpRequests:    newHTTPHistogram(),
+		handlerLatency:  make(map[[2]string]*simpleHistogram),
+		responseClasses: make(map[[3]string]uint64),
+		policyDenials:   make(map[string]uint64),
+		sourcesAdded:    make(map[string]uint64),
 		buildInfoLabels: map[string]string{
 			"version":      "dev",
 			"spec_version": "1.2.0",
@@ -51,6 +71,15 @@ func NewRegistry() *Registry {
 		persistenceEvictions: make(map[string]uint64),
 		persistenceBytes:     make(map[string]uint64),
 		sseActive:            make(map[string]int64),
+		sseRunStreams:        make(map[string]int64),
+		ssePrincipalStreams:  make(map[string]int64),
+		sseStreamsRejected:   map[string]uint64{"principal": 0, "run": 0},
+		planCache:            map[string]uint64{"hit": 0, "miss": 0, "invalidated": 0},
+		eventsDropped:        map[string]uint64{"buffer_full": 0, "slow_subscriber": 0},
+		logLinesTruncated:    map[string]uint64{"stdout": 0, "stderr": 0},
+		runsFinished:         make(map[string]uint64),
+		runDurations:         make(map[string]*simpleHistogram),
+		idempotencyReplays:   make(map[string]uint64),
 	}
 	for op, outcomes := range persistenceLatencyDefaults {
 		op = normalizeLabel(op)
@@ -86,6 +115,22 @@ func (r *Registry) RecordHTTP(route, method string, status int, duration time.Du
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.httpRequests.observe(route, method, status, duration)
+	key := [2]string{route, method}
+	hist, ok := r.handlerLatency[key]
+	if !ok {
+		hist = newSimpleHistogram(httpLatencyBuckets)
+		r.handlerLatency[key] = hist
+	}
+	hist.observe(duration)
+	r.responseClasses[[3]string{route, method, statusClass(status)}]++
+}
+
+// statusClass groups status codes as 1xx..5xx for error-rate queries.
+func statusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(status/100) + "xx"
 }
 
 // RecordSecurityProfileGauge sets the active security profile gauge (1 for active).
@@ -138,6 +183,129 @@ func (r *Registry) RecordAddonManifestInvalid() {
 	r.addonManifestInvalid++
 }
 
+// RecordPlanCache increments the plan cache counter for outcome (hit|miss|invalidated).
+func (r *Registry) RecordPlanCache(outcome string) {
+	outcome = normalizeLabel(outcome)
+	if r == nil || outcome == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.planCache[outcome]++
+}
+
+// RecordActiveRunsDelta adjusts the gauge of runs registered for execution.
+func (r *Registry) RecordActiveRunsDelta(delta int64) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runsActive += delta
+	if r.runsActive < 0 {
+		r.runsActive = 0
+	}
+}
+
+// RecordRunFinished counts a run that reached status and observes how long
+// it took from start to finish.
+func (r *Registry) RecordRunFinished(status string, duration time.Duration) {
+	status = normalizeLabel(status)
+	if r == nil || status == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runsFinished[status]++
+	hist, ok := r.runDurations[status]
+	if !ok {
+		hist = newSimpleHistogram(runDurationBuckets)
+		r.runDurations[status] = hist
+	}
+	hist.observe(duration)
+}
+
+// SetRunStatusSource sets the function reporting how many runs are held per
+// status; flwd_runs and flwd_run_queue_depth are read from it on every
+// scrape. A nil source leaves both empty.
+func (r *Registry) SetRunStatusSource(fn func() map[string]int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runStatus = fn
+}
+
+// RecordIdempotencyReplay increments the counter of requests answered from
+// the idempotency store for route.
+func (r *Registry) RecordIdempotencyReplay(route string) {
+	route = strings.TrimSpace(route)
+	if r == nil || route == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.idempotencyReplays[route]++
+}
+
+// RunsFinished returns the finished run counter for status for testing.
+func (r *Registry) RunsFinished(status string) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.runsFinished[normalizeLabel(status)]
+}
+
+// IdempotencyReplays returns the replay counter for route for testing.
+func (r *Registry) IdempotencyReplays(route string) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.idempotencyReplays[route]
+}
+
+// RecordEventDropped increments the dropped run event counter for reason
+// (buffer_full|slow_subscriber|closed).
+func (r *Registry) RecordEventDropped(reason string) {
+	reason = normalizeLabel(reason)
+	if r == nil || reason == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.eventsDropped[reason]++
+}
+
+// RecordLogLineTruncated increments the counter of step output lines cut
+// short in step.log events for channel (stdout|stderr).
+func (r *Registry) RecordLogLineTruncated(channel string) {
+	channel = normalizeLabel(channel)
+	if r == nil || channel == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logLinesTruncated[channel]++
+}
+
+// LogLinesTruncated returns the truncated line counter for channel for
+// testing.
+func (r *Registry) LogLinesTruncated(channel string) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.logLinesTruncated[normalizeLabel(channel)]
+}
+
+// EventsDropped returns the dropped event counter for reason for testing.
+func (r *Registry) EventsDropped(reason string) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.eventsDropped[normalizeLabel(reason)]
+}
+
+// ActiveRuns returns the active runs gauge for testing.
+func (r *Registry) ActiveRuns() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.runsActive
+}
+
 // SourceAddedTotals returns a copy of the added sources counter for testing.
 func (r *Registry) SourceAddedTotals() map[string]uint64 {
 	r.mu.Lock()
@@ -172,6 +340,16 @@ func (r *Registry) Handler() http.Handler {
 }
 
 func (r *Registry) writeAll(w http.ResponseWriter) {
+	// The run status source takes the run store's lock; call it before
+	// taking ours.
+	r.mu.Lock()
+	source := r.runStatus
+	r.mu.Unlock()
+	var runStatus map[string]int
+	if source != nil {
+		runStatus = source()
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	buf := bufio.NewWriter(w)
@@ -189,6 +367,45 @@ func (r *Registry) writeAll(w http.ResponseWriter) {
 	r.httpRequests.writeHistograms(buf)
 	buf.WriteByte('\n')
 
+	writeMetricHeader(buf, "flwd_http_handler_duration_seconds", "HTTP handler latency in seconds by route and method", "histogram")
+	handlerKeys := make([][2]string, 0, len(r.handlerLatency))
+	for key := range r.handlerLatency {
+		handlerKeys = append(handlerKeys, key)
+	}
+	sort.Slice(handlerKeys, func(i, j int) bool {
+		a, b := handlerKeys[i], handlerKeys[j]
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		return a[1] < b[1]
+	})
+	for _, key := range handlerKeys {
+		r.handlerLatency[key].writeWithLabels(buf, "flwd_http_handler_duration_seconds", map[string]string{
+			"route":  key[0],
+			"method": key[1],
+		})
+	}
+	buf.WriteByte('\n')
+
+	writeMetricHeader(buf, "flwd_http_responses_total", "HTTP responses by route, method and status class", "counter")
+	classKeys := make([][3]string, 0, len(r.responseClasses))
+	for key := range r.responseClasses {
+		classKeys = append(classKeys, key)
+	}
+	sort.Slice(classKeys, func(i, j int) bool {
+		a, b := classKeys[i], classKeys[j]
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+	for _, key := range classKeys {
+		fmt.Fprintf(buf, "flwd_http_responses_total{route=%q,method=%q,class=%q} %d\n", key[0], key[1], key[2], r.responseClasses[key])
+	}
+	buf.WriteByte('\n')
+
 	writeMetricHeader(buf, "flwd_build_info", "Runner build info", "gauge")
 	buf.WriteString("flwd_build_info")
 	buf.WriteByte('{')
@@ -254,6 +471,24 @@ func (r *Registry) writeAll(w http.ResponseWriter) {
 	}
 	buf.WriteByte('\n')
 
+	writeMetricHeader(buf, "flwd_sse_run_streams", "Open event streams by run", "gauge")
+	for _, runID := range sortedKeysInt64(r.sseRunStreams) {
+		fmt.Fprintf(buf, "flwd_sse_run_streams{run_id=%q} %d\n", runID, r.sseRunStreams[runID])
+	}
+	buf.WriteByte('\n')
+
+	writeMetricHeader(buf, "flwd_sse_principal_streams", "Open event streams by principal", "gauge")
+	for _, principal := range sortedKeysInt64(r.ssePrincipalStreams) {
+		fmt.Fprintf(buf, "flwd_sse_principal_streams{principal=%q} %d\n", principal, r.ssePrincipalStreams[principal])
+	}
+	buf.WriteByte('\n')
+
+	writeMetricHeader(buf, "flwd_sse_streams_rejected_total", "Event streams refused by the open stream limit", "counter")
+	for _, limit := range sortedKeysUint(r.sseStreamsRejected) {
+		fmt.Fprintf(buf, "flwd_sse_streams_rejected_total{limit=%q} %d\n", limit, r.sseStreamsRejected[limit])
+	}
+	buf.WriteByte('\n')
+
 	writeMetricHeader(buf, "flowd_sse_resume_total", "SSE resume attempts", "counter")
 	fmt.Fprintf(buf, "flowd_sse_resume_total %d\n\n", r.sseResumeTotal)
 
@@ -278,6 +513,70 @@ func (r *Registry) writeAll(w http.ResponseWriter) {
 
 	writeMetricHeader(buf, "flwd_addon_manifest_invalid_total", "Invalid add-on manifests", "counter")
 	fmt.Fprintf(buf, "flwd_addon_manifest_invalid_total %d\n\n", r.addonManifestInvalid)
+
+	writeMetricHeader(buf, "flwd_plan_cache_total", "Plan cache lookups and invalidations by outcome", "counter")
+	for _, outcome := range sortedKeysUint(r.planCache) {
+		fmt.Fprintf(buf, "flwd_plan_cache_total{outcome=%q} %d\n", outcome, r.planCache[outcome])
+	}
+	buf.WriteByte('\n')
+
+	writeMetricHeader(buf, "flwd_runs_active", "Runs registered for execution", "gauge")
+	fmt.Fprintf(buf, "flwd_runs_active %d\n\n", r.runsActive)
+
+	writeMetricHeader(buf, "flwd_runs", "Runs held by the server by status", "gauge")
+	statuses := make([]string, 0, len(runStatus))
+	for status := range runStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		fmt.Fprintf(buf, "flwd_runs{status=%q} %d\n", status, runStatus[status])
+	}
+	buf.WriteByte('\n')
+
+	queued := 0
+	for _, status := range runQueueStatuses {
+		queued += runStatus[status]
+	}
+	writeMetricHeader(buf, "flwd_run_queue_depth", "Runs accepted but not yet executing (queued or awaiting approval)", "gauge")
+	fmt.Fprintf(buf, "flwd_run_queue_depth %d\n\n", queued)
+
+	writeMetricHeader(buf, "flwd_runs_finished_total", "Runs that reached a terminal status by status", "counter")
+	for _, status := range sortedKeysUint(r.runsFinished) {
+		fmt.Fprintf(buf, "flwd_runs_finished_total{status=%q} %d\n", status, r.runsFinished[status])
+	}
+	buf.WriteByte('\n')
+
+	writeMetricHeader(buf, "flwd_run_duration_seconds", "Run duration from start to finish in seconds by status", "histogram")
+	durationKeys := make([]string, 0, len(r.runDurations))
+	for status := range r.runDurations {
+		durationKeys = append(durationKeys, status)
+	}
+	sort.Strings(durationKeys)
+	for _, status := range durationKeys {
+		r.runDurations[status].writeWithLabels(buf, "flwd_run_duration_seconds", map[string]string{"status": status})
+	}
+	buf.WriteByte('\n')
+
+	writeMetricHeader(buf, "flwd_idempotency_replays_total", "Requests answered from the idempotency store by route", "counter")
+	for _, route := range sortedKeysUint(r.idempotencyReplays) {
+		fmt.Fprintf(buf, "flwd_idempotency_replays_total{route=%q} %d\n", route, r.idempotencyReplays[route])
+	}
+	buf.WriteByte('\n')
+
+	writeMetricHeader(buf, "flwd_events_dropped_total", "Run events dropped before delivery by reason", "counter")
+	for _, reason := range sortedKeysUint(r.eventsDropped) {
+		fmt.Fprintf(buf, "flwd_events_dropped_total{reason=%q} %d\n", reason, r.eventsDropped[reason])
+	}
+	buf.WriteByte('\n')
+
+	writeMetricHeader(buf, "flwd_log_lines_truncated_total", "Step output lines truncated in step.log events by channel", "counter")
+	for _, channel := range sortedKeysUint(r.logLinesTruncated) {
+		fmt.Fprintf(buf, "flwd_log_lines_truncated_total{channel=%q} %d\n", channel, r.logLinesTruncated[channel])
+	}
+	buf.WriteByte('\n')
+
+	r.writeRestored(buf)
 }
 
 func (r *Registry) writeHistogram(buf *bufio.Writer, name, metricType string, getter func() (float64, bool)) {
@@ -348,7 +647,7 @@ func (h *httpHistogram) observe(route, method string, status int, duration time.
 	label := route + "|" + method + "|" + strconv.Itoa(status)
 	b, ok := h.hist[label]
 	if !ok {
-		b = newSimpleHistogram([]float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10})
+		b = newSimpleHistogram(httpLatencyBuckets)
 		h.hist[label] = b
 	}
 	b.observe(duration)
@@ -548,6 +847,46 @@ func (r *Registry) RecordSSEActiveDelta(transport string, delta int64) {
 	}
 }
 
+// RecordSSEStreamDelta adjusts the open stream gauges of a run and a
+// principal; either may be empty. Series are dropped once they reach zero so
+// finished runs do not linger.
+func (r *Registry) RecordSSEStreamDelta(runID, principal string, delta int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	adjustGauge(r.sseRunStreams, runID, delta)
+	adjustGauge(r.ssePrincipalStreams, principal, delta)
+}
+
+func adjustGauge(series map[string]int64, key string, delta int64) {
+	if key == "" {
+		return
+	}
+	series[key] += delta
+	if series[key] <= 0 {
+		delete(series, key)
+	}
+}
+
+// RecordSSEStreamRejected increments the refused stream counter for limit
+// (principal|run).
+func (r *Registry) RecordSSEStreamRejected(limit string) {
+	limit = normalizeLabel(limit)
+	if limit == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sseStreamsRejected[limit]++
+}
+
+// SSEStreams returns the open stream gauges of a run and a principal for
+// testing.
+func (r *Registry) SSEStreams(runID, principal string) (int64, int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sseRunStreams[runID], r.ssePrincipalStreams[principal]
+}
+
 // RecordSSEResumeAttempt increments the SSE resume counter.
 func (r *Registry) RecordSSEResumeAttempt() {
 	r.mu.Lock()
@@ -567,6 +906,8 @@ func normalizeLabel(v string) string {
 	return v
 }
 
+var httpLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
 var persistenceLatencyBuckets = []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000}
 
 var persistenceLatencyDefaults = map[string][]string{
@@ -578,6 +919,11 @@ var persistenceLatencyDefaults = map[string][]string{
 
 var persistenceDefaultKinds = []string{"journal", "idempotency"}
 
+var runDurationBuckets = []float64{1, 5, 15, 30, 60, 300, 900, 1800, 3600, 7200}
+
+// runQueueStatuses are the statuses of runs accepted but not yet executing.
+var runQueueStatuses = []string{"queued", "awaiting_approval"}
+
 type valueHistogram struct {
 	buckets []float64
 	counts  []uint64
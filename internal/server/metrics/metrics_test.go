@@ -69,3 +69,92 @@ func TestSSEMetricsOutput(t *testing.T) {
 		t.Fatalf("expected cursor expired counter, got body:\n%s", body)
 	}
 }
+
+func TestRestoreCountersKeepsTotalsMonotonic(t *testing.T) {
+	before := NewRegistry()
+	before.RecordPolicyDenial("image_unsigned")
+	before.RecordPolicyDenial("image_unsigned")
+	before.RecordContainerPull(time.Second)
+	before.RecordPlanCache("hit")
+	at := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	after := NewRegistry()
+	after.RestoreCounters(append(before.Counters(), CounterSample{Name: "unknown_total", Value: 7}), at)
+	after.RecordPolicyDenial("image_unsigned")
+	after.RecordContainerPull(time.Second)
+
+	if got := after.ContainerPullsTotal(); got != 2 {
+		t.Fatalf("expected 2 pulls after restore, got %d", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rr := httptest.NewRecorder()
+	after.Handler().ServeHTTP(rr, req)
+	body := rr.Body.String()
+	for _, want := range []string{
+		`flwd_policy_denials_total{reason="image_unsigned"} 3`,
+		`flwd_policy_denials_restored{reason="image_unsigned"} 2`,
+		`flwd_container_pulls_restored 1`,
+		`flwd_plan_cache_restored{outcome="hit"} 1`,
+		`flwd_metrics_restored_timestamp_seconds 1740830400`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q, got body:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, `flwd_plan_cache_restored{outcome="miss"}`) || strings.Contains(body, "unknown") {
+		t.Fatalf("expected only non-zero known series to be restored, got body:\n%s", body)
+	}
+}
+
+func TestHTTPHandlerLatencyAndStatusClasses(t *testing.T) {
+	reg := NewRegistry()
+	reg.RecordHTTP("/runs/{id}", http.MethodGet, http.StatusOK, 20*time.Millisecond)
+	reg.RecordHTTP("/runs/{id}", http.MethodGet, http.StatusNotFound, 3*time.Millisecond)
+	reg.RecordHTTP("/runs/{id}", http.MethodGet, http.StatusBadGateway, 2*time.Second)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rr := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rr, req)
+	body := rr.Body.String()
+	for _, want := range []string{
+		`flwd_http_handler_duration_seconds_bucket{le="0.005",method="GET",route="/runs/{id}"} 1`,
+		`flwd_http_handler_duration_seconds_bucket{le="0.025",method="GET",route="/runs/{id}"} 2`,
+		`flwd_http_handler_duration_seconds_count{method="GET",route="/runs/{id}"} 3`,
+		`flwd_http_responses_total{route="/runs/{id}",method="GET",class="2xx"} 1`,
+		`flwd_http_responses_total{route="/runs/{id}",method="GET",class="4xx"} 1`,
+		`flwd_http_responses_total{route="/runs/{id}",method="GET",class="5xx"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q, got body:\n%s", want, body)
+		}
+	}
+}
+
+func TestRunMetricsOutput(t *testing.T) {
+	reg := NewRegistry()
+	reg.SetRunStatusSource(func() map[string]int {
+		return map[string]int{"queued": 2, "awaiting_approval": 1, "running": 3, "completed": 7}
+	})
+	reg.RecordRunFinished("completed", 20*time.Second)
+	reg.RecordRunFinished("failed", 2*time.Second)
+	reg.RecordIdempotencyReplay("/runs")
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rr := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+	for _, want := range []string{
+		`flwd_runs{status="running"} 3`,
+		"flwd_run_queue_depth 3\n",
+		`flwd_runs_finished_total{status="completed"} 1`,
+		`flwd_run_duration_seconds_bucket{le="30",status="completed"} 1`,
+		`flwd_run_duration_seconds_bucket{le="1",status="failed"} 0`,
+		`flwd_idempotency_replays_total{route="/runs"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q, got body:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CounterSample is one counter series as persisted across restarts. Label is
+// the value of the family's label and empty for unlabeled counters.
+type CounterSample struct {
+	Name  string
+	Label string
+	Value uint64
+}
+
+// counterFamily describes a persistable counter: either a map keyed by the
+// value of label, or a single scalar.
+type counterFamily struct {
+	name   string
+	label  string
+	series func(r *Registry) map[string]uint64
+	scalar func(r *Registry) *uint64
+}
+
+// counterFamilies lists the counters kept across restarts. HTTP request
+// totals are left out because they are derived from their histogram.
+var counterFamilies = []counterFamily{
+	{name: "flwd_policy_denials_total", label: "reason", series: func(r *Registry) map[string]uint64 { return r.policyDenials }},
+	{name: "flowd_persistence_evictions_total", label: "kind", series: func(r *Registry) map[string]uint64 { return r.persistenceEvictions }},
+	{name: "flowd_persistence_eviction_bytes_total", label: "kind", series: func(r *Registry) map[string]uint64 { return r.persistenceBytes }},
+	{name: "flwd_sse_streams_rejected_total", label: "limit", series: func(r *Registry) map[string]uint64 { return r.sseStreamsRejected }},
+	{name: "flowd_sse_resume_total", scalar: func(r *Registry) *uint64 { return &r.sseResumeTotal }},
+	{name: "flowd_sse_cursor_expired_total", scalar: func(r *Registry) *uint64 { return &r.sseCursorExpiredTotal }},
+	{name: "flwd_container_runs_total", scalar: func(r *Registry) *uint64 { return &r.containerRunsTotal }},
+	{name: "flwd_container_pulls_total", scalar: func(r *Registry) *uint64 { return &r.containerPullsTotal }},
+	{name: "flwd_sources_added_total", label: "type", series: func(r *Registry) map[string]uint64 { return r.sourcesAdded }},
+	{name: "flwd_addon_manifest_invalid_total", scalar: func(r *Registry) *uint64 { return &r.addonManifestInvalid }},
+	{name: "flwd_plan_cache_total", label: "outcome", series: func(r *Registry) map[string]uint64 { return r.planCache }},
+	{name: "flwd_events_dropped_total", label: "reason", series: func(r *Registry) map[string]uint64 { return r.eventsDropped }},
+	{name: "flwd_log_lines_truncated_total", label: "channel", series: func(r *Registry) map[string]uint64 { return r.logLinesTruncated }},
+	{name: "flwd_runs_finished_total", label: "status", series: func(r *Registry) map[string]uint64 { return r.runsFinished }},
+	{name: "flwd_idempotency_replays_total", label: "route", series: func(r *Registry) map[string]uint64 { return r.idempotencyReplays }},
+}
+
+// Counters returns the current value of every persistable counter series.
+func (r *Registry) Counters() []CounterSample {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []CounterSample
+	for _, f := range counterFamilies {
+		if f.scalar != nil {
+			out = append(out, CounterSample{Name: f.name, Value: *f.scalar(r)})
+			continue
+		}
+		series := f.series(r)
+		for _, label := range sortedKeysUint(series) {
+			out = append(out, CounterSample{Name: f.name, Label: label, Value: series[label]})
+		}
+	}
+	return out
+}
+
+// RestoreCounters adds samples taken at snapshot time at to the counters so
+// totals stay monotonic across restarts. It should run before the registry
+// starts recording. The restored portion of each series is exposed as a
+// separate *_restored gauge; samples for unknown counters are ignored.
+func (r *Registry) RestoreCounters(samples []CounterSample, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	families := make(map[string]counterFamily, len(counterFamilies))
+	for _, f := range counterFamilies {
+		families[f.name] = f
+	}
+	for _, s := range samples {
+		f, ok := families[s.Name]
+		if !ok || s.Value == 0 {
+			continue
+		}
+		label := normalizeLabel(s.Label)
+		if f.scalar != nil {
+			label = ""
+			*f.scalar(r) += s.Value
+		} else {
+			if label == "" {
+				continue
+			}
+			f.series(r)[label] += s.Value
+		}
+		if r.restored == nil {
+			r.restored = make(map[string]map[string]uint64)
+		}
+		if r.restored[s.Name] == nil {
+			r.restored[s.Name] = make(map[string]uint64)
+		}
+		r.restored[s.Name][label] += s.Value
+	}
+	if !at.IsZero() {
+		r.restoredAt = at
+	}
+}
+
+// writeRestored exposes when counters were restored and how much of each
+// series came from the snapshot rather than this process.
+func (r *Registry) writeRestored(buf *bufio.Writer) {
+	writeMetricHeader(buf, "flwd_metrics_restored_timestamp_seconds", "Time of the counter snapshot restored at startup (0 when none)", "gauge")
+	var ts int64
+	if !r.restoredAt.IsZero() {
+		ts = r.restoredAt.Unix()
+	}
+	fmt.Fprintf(buf, "flwd_metrics_restored_timestamp_seconds %d\n\n", ts)
+
+	for _, f := range counterFamilies {
+		restored := r.restored[f.name]
+		if len(restored) == 0 {
+			continue
+		}
+		name := strings.TrimSuffix(f.name, "_total") + "_restored"
+		writeMetricHeader(buf, name, "Portion of "+f.name+" restored from the last snapshot", "gauge")
+		labels := make([]string, 0, len(restored))
+		for label := range restored {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+		for _, label := range labels {
+			if f.scalar != nil {
+				fmt.Fprintf(buf, "%s %d\n", name, restored[label])
+			} else {
+				fmt.Fprintf(buf, "%s{%s=%q} %d\n", name, f.label, label, restored[label])
+			}
+		}
+		buf.WriteByte('\n')
+	}
+}
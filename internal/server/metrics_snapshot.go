@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/coredb"
+	"github.com/flowd-org/flowd/internal/server/metrics"
+)
+
+// metricsSnapshotSaveTimeout bounds the final snapshot written on shutdown.
+const metricsSnapshotSaveTimeout = 5 * time.Second
+
+// startMetricsSnapshots restores the counters saved by the previous process
+// into reg and saves them again every interval. The returned function stops
+// the loop and writes a final snapshot; call it before the Core DB closes.
+func startMetricsSnapshots(ctx context.Context, db *coredb.DB, reg *metrics.Registry, interval time.Duration, logger *slog.Logger) func() {
+	store := coredb.NewMetricsSnapshotStore(db)
+	if store == nil || interval <= 0 {
+		return func() {}
+	}
+	samples, at, err := store.Load(ctx)
+	if err != nil {
+		logger.Warn("metrics.snapshot.restore_failed", slog.String("error", err.Error()))
+	} else if len(samples) > 0 {
+		restored := make([]metrics.CounterSample, len(samples))
+		for i, s := range samples {
+			restored[i] = metrics.CounterSample{Name: s.Name, Label: s.Label, Value: s.Value}
+		}
+		reg.RestoreCounters(restored, at)
+		logger.Info("metrics.snapshot.restored", slog.Int("series", len(samples)), slog.Time("taken_at", at))
+	}
+
+	save := func(ctx context.Context) {
+		counters := reg.Counters()
+		out := make([]coredb.MetricSample, len(counters))
+		for i, c := range counters {
+			out[i] = coredb.MetricSample{Name: c.Name, Label: c.Label, Value: c.Value}
+		}
+		if err := store.Save(ctx, out, time.Now().UTC()); err != nil {
+			logger.Warn("metrics.snapshot.save_failed", slog.String("error", err.Error()))
+		}
+	}
+	loopCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-loopCtx.Done():
+				return
+			case <-ticker.C:
+				save(loopCtx)
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+		saveCtx, cancelSave := context.WithTimeout(context.Background(), metricsSnapshotSaveTimeout)
+		defer cancelSave()
+		save(saveCtx)
+	}
+}
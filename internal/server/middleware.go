@@ -2,15 +2,25 @@
 package server
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
 	"log/slog"
 	"net/http"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/flowd-org/flowd/internal/server/authz"
+	"github.com/flowd-org/flowd/internal/server/faults"
+	"github.com/flowd-org/flowd/internal/server/handlers"
+	"github.com/flowd-org/flowd/internal/server/headers"
 	"github.com/flowd-org/flowd/internal/server/metrics"
 	"github.com/flowd-org/flowd/internal/server/requestctx"
 	"github.com/flowd-org/flowd/internal/server/response"
+	"github.com/flowd-org/flowd/internal/server/runstore"
 )
 
 // Middleware defines a HTTP middleware component.
@@ -65,6 +75,23 @@ func loggingMiddleware(cfg Config) Middleware {
 	}
 }
 
+// bodyLimitMiddleware caps request bodies for the JSON API. Rule-Y endpoints
+// are exempt because they enforce per-namespace value limits themselves.
+func bodyLimitMiddleware(cfg Config) Middleware {
+	limit := cfg.MaxBodyBytes
+	if limit <= 0 {
+		limit = defaultMaxBodyBytes
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body != nil && !strings.HasPrefix(r.URL.Path, "/kv/") {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // corsMiddleware is a no-op placeholder until dev-mode CORS support is implemented.
 func corsMiddleware(cfg Config) Middleware {
 	if !cfg.Dev {
@@ -76,8 +103,9 @@ func corsMiddleware(cfg Config) Middleware {
 			if origin != "" && (strings.HasPrefix(origin, "http://localhost") || strings.HasPrefix(origin, "http://127.0.0.1")) {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
 				w.Header().Set("Vary", "Origin")
-				w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, If-Match, If-None-Match, "+headers.APIVersion+", "+faults.Header)
+				w.Header().Set("Access-Control-Expose-Headers", "ETag, Last-Modified, "+headers.TotalCount+", "+headers.RemainingCount+", "+headers.APIVersion+", "+headers.ServerVersion)
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 				w.Header().Set("Access-Control-Allow-Credentials", "true")
 				if r.Method == http.MethodOptions {
 					w.WriteHeader(http.StatusNoContent)
@@ -89,7 +117,24 @@ func corsMiddleware(cfg Config) Middleware {
 	}
 }
 
-// authMiddleware is stubbed; it will enforce JWT bearer scopes in later tasks.
+// versionMiddleware stamps every response, including auth failures, with the
+// server's API and build versions so clients can detect version skew.
+func versionMiddleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(headers.APIVersion, specVersion)
+			w.Header().Set(headers.ServerVersion, serverVersion())
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authMiddleware authenticates the bearer token and rejects requests lacking
+// the route's required scopes. With OIDC configured the token must be a JWT
+// verified against the issuer's JWKS; otherwise it is a JWT signed with
+// FLWD_JWT_SECRET or an opaque scope list. RBAC restrictions,
+// the principal and its scopes are attached to the request context. Metrics,
+// webhook deliveries and public badges bypass it when so configured.
 func authMiddleware(cfg Config) Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -97,6 +142,16 @@ func authMiddleware(cfg Config) Middleware {
 				next.ServeHTTP(w, r)
 				return
 			}
+			// Webhook deliveries are authenticated by the receiving handler
+			// against the source's shared secret.
+			if r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/webhooks/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if cfg.PublicBadges && r.Method == http.MethodGet && isBadgePath(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
 			required := authz.RequiredScopes(r.Method, r.URL.Path)
 			info, err := resolveAuthInfo(r, cfg)
 			if err != nil {
@@ -109,17 +164,193 @@ func authMiddleware(cfg Config) Middleware {
 				response.Write(w, response.New(http.StatusUnauthorized, "unauthorized"))
 				return
 			}
+			if trail := requestctx.AuditTrailFromContext(r.Context()); trail != nil {
+				trail.SetPrincipal(info.principal())
+			}
+			grant := info.applyRBAC(cfg.RBAC)
 			if len(required) > 0 && !info.hasScopes(required) {
+				requestctx.LogPolicyDecision(r.Context(), "scope", "denied", "scope.missing", "requires "+strings.Join(required, ", "))
 				response.Write(w, response.New(http.StatusForbidden, "forbidden", response.WithDetail("missing required scope")))
 				return
 			}
 			ctx := withAuth(r.Context(), info)
 			ctx = requestctx.WithPrincipal(ctx, info.principal())
+			ctx = requestctx.WithScopes(ctx, info.scopesSlice())
+			if grant.Restricted() {
+				ctx = requestctx.WithAccess(ctx, grant)
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// auditMiddleware appends every mutating request to the audit log once it
+// has been answered: the caller, the action, the outcome, a hash of the
+// request body and the policy decisions taken while serving it. It runs
+// before authentication so refused requests are recorded too.
+func auditMiddleware(log *handlers.AuditLog) Middleware {
+	return func(next http.Handler) http.Handler {
+		if log == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions || r.URL.Path == "/plans" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			received := time.Now().UTC()
+			var body []byte
+			if r.Body != nil {
+				var readErr error
+				body, readErr = io.ReadAll(r.Body)
+				// Handlers still see the body, and a read error such as an
+				// exceeded body limit, as if they read it themselves.
+				r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), errReader{readErr}))
+			}
+			sum := sha256.Sum256(body)
+			trail := &requestctx.AuditTrail{}
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(requestctx.WithAuditTrail(r.Context(), trail)))
+
+			entry := handlers.AuditEntry{
+				Timestamp:     received,
+				Principal:     trail.Principal(),
+				Action:        r.Method + " " + templateRoute(r.URL.Path),
+				Method:        r.Method,
+				Path:          r.URL.Path,
+				Status:        recorder.status,
+				Outcome:       handlers.AuditOutcome(recorder.status),
+				RemoteAddr:    r.RemoteAddr,
+				RequestSHA256: hex.EncodeToString(sum[:]),
+				Decisions:     trail.Decisions(),
+			}
+			if err := log.Record(context.WithoutCancel(r.Context()), entry); err != nil {
+				slog.Default().Error("audit.record_failed",
+					slog.String("action", entry.Action),
+					slog.String("principal", entry.Principal),
+					slog.String("error", err.Error()))
+			}
+		})
+	}
+}
+
+// errReader returns err, or io.EOF when err is nil.
+type errReader struct{ err error }
+
+func (e errReader) Read([]byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	return 0, io.EOF
+}
+
+// accessMiddleware hides the runs, sources and job badges outside the
+// caller's RBAC grant: requests naming them in the path answer 404 as if they
+// did not exist. Collections are filtered by their handlers.
+func accessMiddleware(runs *runstore.Store, archive handlers.RunArchive) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			if requestctx.AccessFromContext(ctx) == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if id := pathResource(r.URL.Path, "/runs/"); id != "" {
+				run, ok := runs.Get(id)
+				if !ok && archive != nil {
+					run, ok, _ = archive.LoadRun(ctx, id)
+				}
+				if ok && !handlers.RunVisible(ctx, run) {
+					response.Write(w, response.New(http.StatusNotFound, "run not found"))
+					return
+				}
+			}
+			if name := pathResource(r.URL.Path, "/sources/"); name != "" && !requestctx.SourceAllowed(ctx, name) {
+				response.Write(w, response.New(http.StatusNotFound, "source not found", response.WithDetail(name)))
+				return
+			}
+			if isBadgePath(r.URL.Path) {
+				jobID := strings.TrimPrefix(path.Dir(r.URL.Path), "/jobs/")
+				if !requestctx.JobAllowed(ctx, jobID) {
+					response.Write(w, response.New(http.StatusNotFound, "job not found", response.WithDetail(jobID)))
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// pathResource returns the name following prefix in path, up to the next
+// slash or action suffix.
+func pathResource(path, prefix string) string {
+	rest, ok := strings.CutPrefix(path, prefix)
+	if !ok {
+		return ""
+	}
+	if i := strings.IndexAny(rest, "/:"); i >= 0 {
+		rest = rest[:i]
+	}
+	return rest
+}
+
+func isBadgePath(path string) bool {
+	return strings.HasPrefix(path, "/jobs/") && (strings.HasSuffix(path, "/badge.svg") || strings.HasSuffix(path, "/badge.json"))
+}
+
+// readOnlyMiddleware refuses mutating requests while the read_only setting is
+// on. Reads, event streams and plan previews keep working, and PUT
+// /admin/settings stays open so operators can lift the freeze.
+func readOnlyMiddleware(cfg Config) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !cfg.Settings.ReadOnly() || !isMutatingRequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			response.Write(w, response.New(http.StatusServiceUnavailable, "server is read-only",
+				response.WithExtension("code", "E_READ_ONLY"),
+				response.WithDetail("flowd is in read-only mode; mutating requests are refused until an operator sets read_only to false via PUT /admin/settings")))
+		})
+	}
+}
+
+// faultMiddleware arms the configured faults plus any requested through the
+// X-Flowd-Fault header. It is a no-op outside dev mode.
+func faultMiddleware(cfg Config) Middleware {
+	if !cfg.Dev {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			set := cfg.Faults
+			if header := r.Header.Get(faults.Header); header != "" {
+				requested, err := faults.Parse(header)
+				if err != nil {
+					response.Write(w, response.New(http.StatusBadRequest, "invalid fault header",
+						response.WithExtension("code", "fault.invalid"),
+						response.WithDetail(err.Error())))
+					return
+				}
+				set = set.Merge(requested)
+			}
+			next.ServeHTTP(w, r.WithContext(faults.WithSet(r.Context(), set)))
+		})
+	}
+}
+
+func isMutatingRequest(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	switch r.URL.Path {
+	case "/plans", "/admin/settings":
+		return false
+	}
+	return true
+}
+
 func metricsMiddleware(cfg Config) Middleware {
 	if !cfg.MetricsEnabled {
 		return func(next http.Handler) http.Handler { return next }
@@ -145,33 +376,116 @@ func templateRoute(path string) string {
 		return "/metrics"
 	case path == "/healthz":
 		return "/healthz"
+	case path == "/openapi.json":
+		return "/openapi.json"
 	case path == "/health/storage":
 		return "/health/storage"
+	case path == "/health/runtime":
+		return "/health/runtime"
 	case path == "/plans":
 		return "/plans"
 	case path == "/runs":
 		return "/runs"
+	case path == "/runs:batch":
+		return "/runs:batch"
+	case path == "/runs:cancel":
+		return "/runs:cancel"
+	case path == "/runs:compare":
+		return "/runs:compare"
+	case path == "/admin/settings":
+		return "/admin/settings"
+	case path == "/capabilities":
+		return "/capabilities"
+	case path == "/stats":
+		return "/stats"
+	case path == "/audit":
+		return "/audit"
+	case path == "/audit.ndjson":
+		return "/audit.ndjson"
 	case strings.HasPrefix(path, "/runs/"):
 		switch {
+		case strings.Contains(path, "/artifacts/"):
+			return "/runs/{id}/artifacts/{name}"
+		case strings.HasSuffix(path, "/artifacts"):
+			return "/runs/{id}/artifacts"
 		case strings.HasSuffix(path, ":cancel"):
 			return "/runs/{id}:cancel"
+		case strings.HasSuffix(path, ":approve"):
+			return "/runs/{id}:approve"
+		case strings.HasSuffix(path, ":reject"):
+			return "/runs/{id}:reject"
 		case strings.HasSuffix(path, "/events.ndjson"):
 			return "/runs/{id}/events.ndjson"
 		case strings.HasSuffix(path, "/events"):
 			return "/runs/{id}/events"
+		case strings.HasSuffix(path, "/provenance"):
+			return "/runs/{id}/provenance"
+		case strings.HasSuffix(path, "/timeline"):
+			return "/runs/{id}/timeline"
 		default:
 			return "/runs/{id}"
 		}
 	case path == "/jobs":
 		return "/jobs"
+	case isBadgePath(path):
+		if strings.HasSuffix(path, ".json") {
+			return "/jobs/{id}/badge.json"
+		}
+		return "/jobs/{id}/badge.svg"
+	case strings.HasPrefix(path, "/jobs/"):
+		return "/jobs/{id}"
+	case strings.HasPrefix(path, "/kv/"):
+		if strings.Contains(strings.TrimPrefix(path, "/kv/"), "/") {
+			return "/kv/{namespace}/{key}"
+		}
+		return "/kv/{namespace}"
 	case path == "/sources":
 		return "/sources"
+	case strings.HasPrefix(path, "/sources/") && strings.HasSuffix(path, ":trust"):
+		return "/sources/{name}:trust"
+	case strings.HasPrefix(path, "/sources/") && strings.HasSuffix(path, ":quarantine"):
+		return "/sources/{name}:quarantine"
+	case strings.HasPrefix(path, "/sources/") && strings.HasSuffix(path, ":release"):
+		return "/sources/{name}:release"
 	case strings.HasPrefix(path, "/sources/"):
 		return "/sources/{name}"
 	case path == "/events":
 		return "/events"
+	case path == "/webhooks/deliveries":
+		return "/webhooks/deliveries"
+	case strings.HasPrefix(path, "/webhooks/"):
+		return "/webhooks/{source}"
+	case path == "/schedules":
+		return "/schedules"
+	case strings.HasPrefix(path, "/schedules/"):
+		for _, action := range []string{"enable", "disable", "trigger"} {
+			if strings.HasSuffix(path, ":"+action) {
+				return "/schedules/{job_id}/{name}:" + action
+			}
+		}
+		if strings.HasSuffix(path, "/runs") {
+			return "/schedules/{job_id}/{name}/runs"
+		}
+		return "/schedules/{job_id}/{name}"
+	case path == "/pipelines":
+		return "/pipelines"
+	case strings.HasPrefix(path, "/pipelines/"):
+		switch {
+		case strings.HasSuffix(path, ":approve"):
+			return "/pipelines/{id}/promotions/{promotion_id}:approve"
+		case strings.HasSuffix(path, ":reject"):
+			return "/pipelines/{id}/promotions/{promotion_id}:reject"
+		case strings.HasSuffix(path, "/promotions"):
+			return "/pipelines/{id}/promotions"
+		case strings.Contains(path, "/promotions/"):
+			return "/pipelines/{id}/promotions/{promotion_id}"
+		default:
+			return "/pipelines/{id}"
+		}
 	default:
-		return path
+		// Unknown paths share one label so scanners cannot inflate the
+		// number of series.
+		return "unmatched"
 	}
 }
 
@@ -185,13 +499,26 @@ func (s *statusRecorder) WriteHeader(status int) {
 	s.ResponseWriter.WriteHeader(status)
 }
 
+// Flush forwards to the wrapped writer so SSE streams are not buffered when
+// metrics are enabled.
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
 func newLogger(cfg Config) *slog.Logger {
 	var handler slog.Handler
+	opts := &slog.HandlerOptions{Level: cfg.Settings.Level()}
 	switch strings.ToLower(cfg.Log) {
 	case "json":
-		handler = slog.NewJSONHandler(cfg.StdOut, nil)
+		handler = slog.NewJSONHandler(cfg.StdOut, opts)
 	default:
-		handler = slog.NewTextHandler(cfg.StdOut, nil)
+		handler = slog.NewTextHandler(cfg.StdOut, opts)
 	}
 	return slog.New(handler)
 }
@@ -1,9 +1,24 @@
 package server
 
 import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/faults"
+	"github.com/flowd-org/flowd/internal/server/headers"
+	"github.com/flowd-org/flowd/internal/server/oidc"
+	"github.com/flowd-org/flowd/internal/server/requestctx"
+	"github.com/flowd-org/flowd/internal/server/settings"
 )
 
 func TestAuthMiddlewareRequiresToken(t *testing.T) {
@@ -89,7 +104,216 @@ func TestAuthMiddlewareForbidden(t *testing.T) {
 	}
 }
 
+func TestAuthMiddlewareLeavesWebhooksToHandler(t *testing.T) {
+	t.Setenv("FLWD_JWT_SECRET", "")
+	mw := authMiddleware(Config{})
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/app", nil)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	if resp.Code != http.StatusAccepted {
+		t.Fatalf("expected webhook delivery to reach the handler, got %d", resp.Code)
+	}
+
+	reqGet := httptest.NewRequest(http.MethodGet, "/webhooks/app", nil)
+	respGet := httptest.NewRecorder()
+	handler.ServeHTTP(respGet, reqGet)
+	if respGet.Code != http.StatusUnauthorized {
+		t.Fatalf("expected non-POST webhook requests to require a token, got %d", respGet.Code)
+	}
+}
+
+func TestAuthMiddlewareValidatesOIDCTokens(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa key: %v", err)
+	}
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "k1",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer jwks.Close()
+	verifier, err := oidc.NewVerifier(oidc.Config{Issuer: "https://idp.example", Audience: "flowd", JWKSURL: jwks.URL})
+	if err != nil {
+		t.Fatalf("new verifier: %v", err)
+	}
+	sign := func(claims map[string]any) string {
+		payload, _ := json.Marshal(claims)
+		unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","kid":"k1"}`)) + "." + base64.RawURLEncoding.EncodeToString(payload)
+		digest := sha256.Sum256([]byte(unsigned))
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig)
+	}
+
+	var principal string
+	var scoped bool
+	handler := authMiddleware(Config{OIDC: verifier})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = requestctx.Principal(r.Context())
+		scoped = requestctx.HasScope(r.Context(), "runs:write")
+		w.WriteHeader(http.StatusOK)
+	}))
+	call := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/jobs", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+	if code := call(sign(map[string]any{"iss": "https://idp.example", "aud": "flowd", "sub": "ci-bot", "exp": exp, "scp": []string{"jobs:read", "runs:write"}})); code != http.StatusOK {
+		t.Fatalf("expected a valid OIDC token to pass, got %d", code)
+	}
+	if principal != "ci-bot" || !scoped {
+		t.Fatalf("expected principal ci-bot with runs:write, got %q %v", principal, scoped)
+	}
+	if code := call(sign(map[string]any{"iss": "https://idp.example", "aud": "other", "sub": "ci-bot", "exp": exp, "scope": "jobs:read"})); code != http.StatusUnauthorized {
+		t.Fatalf("expected a token for another audience to be refused, got %d", code)
+	}
+	if code := call("jobs:read runs:write"); code != http.StatusUnauthorized {
+		t.Fatalf("expected opaque scope tokens to be refused, got %d", code)
+	}
+}
+
 type nopWriter struct{}
 
 func (nopWriter) Write(p []byte) (int, error) { return len(p), nil }
 func (nopWriter) Sync() error                 { return nil }
+
+func TestAuthMiddlewarePublicBadges(t *testing.T) {
+	t.Setenv("FLWD_JWT_SECRET", "")
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/jobs/build/badge.svg", nil)
+	resp := httptest.NewRecorder()
+	authMiddleware(Config{})(next).ServeHTTP(resp, req)
+	if resp.Code != http.StatusUnauthorized {
+		t.Fatalf("expected badges to require a token by default, got %d", resp.Code)
+	}
+
+	scoped := httptest.NewRequest(http.MethodGet, "/jobs/build/badge.json", nil)
+	scoped.Header.Set("Authorization", "Bearer jobs:read")
+	resp = httptest.NewRecorder()
+	authMiddleware(Config{})(next).ServeHTTP(resp, scoped)
+	if resp.Code != http.StatusForbidden {
+		t.Fatalf("expected badges to require runs:read, got %d", resp.Code)
+	}
+
+	resp = httptest.NewRecorder()
+	authMiddleware(Config{PublicBadges: true})(next).ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/jobs/build/badge.svg", nil))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected public badge to skip auth, got %d", resp.Code)
+	}
+}
+
+func TestReadOnlyMiddlewareRefusesMutations(t *testing.T) {
+	store := newSettings(Config{ReadOnly: true})
+	handler := readOnlyMiddleware(Config{Settings: store})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	cases := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/runs", http.StatusOK},
+		{http.MethodGet, "/runs/run-1/events", http.StatusOK},
+		{http.MethodPost, "/plans", http.StatusOK},
+		{http.MethodPut, "/admin/settings", http.StatusOK},
+		{http.MethodPost, "/runs", http.StatusServiceUnavailable},
+		{http.MethodPost, "/runs/run-1:cancel", http.StatusServiceUnavailable},
+		{http.MethodDelete, "/sources/main", http.StatusServiceUnavailable},
+		{http.MethodPut, "/kv/ns/key", http.StatusServiceUnavailable},
+	}
+	for _, tc := range cases {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(tc.method, tc.path, nil))
+		if resp.Code != tc.want {
+			t.Fatalf("%s %s: expected %d, got %d", tc.method, tc.path, tc.want, resp.Code)
+		}
+		if tc.want == http.StatusServiceUnavailable && !strings.Contains(resp.Body.String(), "E_READ_ONLY") {
+			t.Fatalf("%s %s: expected E_READ_ONLY problem, got %s", tc.method, tc.path, resp.Body.String())
+		}
+	}
+
+	off := false
+	if _, _, err := store.Apply(settings.Patch{ReadOnly: &off}); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/runs", nil))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected mutations to resume after lifting read-only, got %d", resp.Code)
+	}
+}
+
+func TestFaultMiddlewareArmsFaultsOnlyInDev(t *testing.T) {
+	var armed bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		armed = faults.Inject(r.Context(), faults.GitTimeout)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/sources", nil)
+	req.Header.Set(faults.Header, "git-timeout")
+	faultMiddleware(Config{})(next).ServeHTTP(httptest.NewRecorder(), req)
+	if armed {
+		t.Fatalf("expected fault header to be ignored outside dev mode")
+	}
+
+	faultMiddleware(Config{Dev: true})(next).ServeHTTP(httptest.NewRecorder(), req)
+	if !armed {
+		t.Fatalf("expected fault header to arm git-timeout in dev mode")
+	}
+
+	armed = false
+	faultMiddleware(Config{Dev: true, Faults: faults.Set{faults.GitTimeout: 0}})(next).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/sources", nil))
+	if !armed {
+		t.Fatalf("expected configured faults to apply without a header")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/sources", nil)
+	req.Header.Set(faults.Header, "disk-full")
+	resp := httptest.NewRecorder()
+	faultMiddleware(Config{Dev: true})(next).ServeHTTP(resp, req)
+	if resp.Code != http.StatusBadRequest || !strings.Contains(resp.Body.String(), "fault.invalid") {
+		t.Fatalf("expected fault.invalid, got %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestVersionMiddlewareStampsUnauthorizedResponses(t *testing.T) {
+	h := chainMiddleware(http.NotFoundHandler(), versionMiddleware(), authMiddleware(Config{}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/runs", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+	if rec.Header().Get(headers.APIVersion) != specVersion || rec.Header().Get(headers.ServerVersion) != serverVersion() {
+		t.Fatalf("expected version headers, got %v", rec.Header())
+	}
+}
+
+func TestTemplateRouteBoundsCardinality(t *testing.T) {
+	for path, want := range map[string]string{
+		"/runs/r-1/events":          "/runs/{id}/events",
+		"/jobs/build/badge.svg":     "/jobs/{id}/badge.svg",
+		"/jobs/build":               "/jobs/{id}",
+		"/kv/core_triggers":         "/kv/{namespace}",
+		"/kv/core_triggers/app:one": "/kv/{namespace}/{key}",
+		"/wp-login.php":             "unmatched",
+	} {
+		if got := templateRoute(path); got != want {
+			t.Fatalf("templateRoute(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
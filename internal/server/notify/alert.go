@@ -0,0 +1,299 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/secrets"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// Provider endpoints used when a rule does not set api_url.
+const (
+	DefaultPagerDutyURL = "https://events.pagerduty.com"
+	DefaultOpsgenieURL  = "https://api.opsgenie.com"
+)
+
+// Alert actions.
+const (
+	actionTrigger = "trigger"
+	actionResolve = "resolve"
+)
+
+// AlertConfig configures the incident alerter.
+type AlertConfig struct {
+	Secrets secrets.Provider
+	// PublicURL is the externally reachable base URL used for run links.
+	PublicURL string
+	Client    *http.Client
+	Logger    *slog.Logger
+}
+
+// Alerter opens PagerDuty or Opsgenie incidents for jobs whose notify.alerts
+// rules see enough consecutive failures, and resolves them on the job's next
+// completed run. Every incident for a job shares the dedup key flowd/<job>,
+// so repeated triggers update the open incident instead of paging again.
+// Canceled runs neither count as failures nor reset the streak.
+type Alerter struct {
+	cfg AlertConfig
+
+	mu       sync.Mutex
+	failures map[string]int
+	open     map[string]bool
+	// tail holds the completion channel of each job's latest delivery so a
+	// resolve is never sent before the trigger it closes.
+	tail map[string]chan struct{}
+	wg   sync.WaitGroup
+}
+
+type alertEvent struct {
+	Action   string
+	Rule     types.AlertRule
+	DedupKey string
+	JobID    string
+	RunID    string
+	Failures int
+	Error    string
+	URL      string
+}
+
+// NewAlerter constructs an alerter.
+func NewAlerter(cfg AlertConfig) *Alerter {
+	if cfg.Secrets == nil {
+		cfg.Secrets = secrets.Default{}
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return &Alerter{
+		cfg:      cfg,
+		failures: make(map[string]int),
+		open:     make(map[string]bool),
+		tail:     make(map[string]chan struct{}),
+	}
+}
+
+// RunFinished updates the job's failure streak and triggers or resolves
+// incidents for its alert rules.
+func (a *Alerter) RunFinished(job *types.Config, run runstore.Run, runErr error) {
+	if job == nil || job.Notify == nil || len(job.Notify.Alerts) == 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ev := alertEvent{JobID: run.JobID, RunID: run.ID, DedupKey: "flowd/" + run.JobID}
+	if runErr != nil {
+		ev.Error = runErr.Error()
+	}
+	if base := strings.TrimRight(a.cfg.PublicURL, "/"); base != "" {
+		ev.URL = base + "/runs/" + run.ID
+	}
+	switch run.Status {
+	case "failed", "timed_out":
+		a.failures[run.JobID]++
+		ev.Failures = a.failures[run.JobID]
+		for _, rule := range job.Notify.Alerts {
+			key := openKey(run.JobID, rule)
+			if ev.Failures < max(rule.Failures, 1) || a.open[key] {
+				continue
+			}
+			a.open[key] = true
+			ev.Action, ev.Rule = actionTrigger, rule
+			a.enqueue(ev)
+		}
+	case "completed":
+		delete(a.failures, run.JobID)
+		for _, rule := range job.Notify.Alerts {
+			key := openKey(run.JobID, rule)
+			if !a.open[key] {
+				continue
+			}
+			delete(a.open, key)
+			ev.Action, ev.Rule = actionResolve, rule
+			a.enqueue(ev)
+		}
+	}
+}
+
+// Flush waits for outstanding deliveries.
+func (a *Alerter) Flush() {
+	a.wg.Wait()
+}
+
+func openKey(jobID string, rule types.AlertRule) string {
+	return jobID + "\x00" + rule.Provider + "\x00" + rule.KeyRef
+}
+
+// enqueue delivers ev after the job's previous delivery. Callers hold a.mu.
+func (a *Alerter) enqueue(ev alertEvent) {
+	prev := a.tail[ev.JobID]
+	done := make(chan struct{})
+	a.tail[ev.JobID] = done
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		defer func() {
+			close(done)
+			a.mu.Lock()
+			if a.tail[ev.JobID] == done {
+				delete(a.tail, ev.JobID)
+			}
+			a.mu.Unlock()
+		}()
+		if prev != nil {
+			<-prev
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		defer cancel()
+		if err := a.deliver(ctx, ev); err != nil {
+			a.cfg.Logger.Warn("notify.alert.failed",
+				slog.String("job_id", ev.JobID),
+				slog.String("provider", ev.Rule.Provider),
+				slog.String("action", ev.Action),
+				slog.String("error", err.Error()))
+			return
+		}
+		a.cfg.Logger.Info("notify.alert.sent",
+			slog.String("job_id", ev.JobID),
+			slog.String("provider", ev.Rule.Provider),
+			slog.String("action", ev.Action),
+			slog.String("dedup_key", ev.DedupKey))
+	}()
+}
+
+func (a *Alerter) deliver(ctx context.Context, ev alertEvent) error {
+	key, err := a.cfg.Secrets.Resolve(ctx, ev.Rule.KeyRef)
+	if err != nil {
+		return fmt.Errorf("resolve %s key: %w", ev.Rule.Provider, err)
+	}
+	secret := strings.TrimSpace(string(key))
+	switch ev.Rule.Provider {
+	case "pagerduty":
+		return a.sendPagerDuty(ctx, secret, ev)
+	case "opsgenie":
+		return a.sendOpsgenie(ctx, secret, ev)
+	default:
+		return fmt.Errorf("unsupported alert provider %q", ev.Rule.Provider)
+	}
+}
+
+// sendPagerDuty posts to the Events API v2.
+func (a *Alerter) sendPagerDuty(ctx context.Context, routingKey string, ev alertEvent) error {
+	body := map[string]any{
+		"routing_key":  routingKey,
+		"event_action": ev.Action,
+		"dedup_key":    ev.DedupKey,
+	}
+	if ev.Action == actionTrigger {
+		severity := ev.Rule.Severity
+		if severity == "" {
+			severity = "error"
+		}
+		body["payload"] = map[string]any{
+			"summary":        alertSummary(ev),
+			"source":         "flowd",
+			"severity":       severity,
+			"component":      ev.JobID,
+			"custom_details": alertDetails(ev),
+		}
+		if ev.URL != "" {
+			body["links"] = []map[string]string{{"href": ev.URL, "text": "flowd run " + ev.RunID}}
+		}
+	}
+	return a.post(ctx, endpoint(ev.Rule.APIURL, DefaultPagerDutyURL)+"/v2/enqueue", nil, body)
+}
+
+// sendOpsgenie creates an alert keyed by alias, or closes it by alias.
+func (a *Alerter) sendOpsgenie(ctx context.Context, apiKey string, ev alertEvent) error {
+	base := endpoint(ev.Rule.APIURL, DefaultOpsgenieURL)
+	header := http.Header{"Authorization": {"GenieKey " + apiKey}}
+	if ev.Action == actionResolve {
+		target := base + "/v2/alerts/" + url.PathEscape(ev.DedupKey) + "/close?identifierType=alias"
+		return a.post(ctx, target, header, map[string]any{
+			"source": "flowd",
+			"note":   fmt.Sprintf("Run %s of %s completed.", ev.RunID, ev.JobID),
+		})
+	}
+	priority := ev.Rule.Severity
+	if priority == "" {
+		priority = "P3"
+	}
+	description := alertSummary(ev)
+	if ev.URL != "" {
+		description += "\n" + ev.URL
+	}
+	details := map[string]string{}
+	for k, v := range alertDetails(ev) {
+		details[k] = fmt.Sprint(v)
+	}
+	return a.post(ctx, base+"/v2/alerts", header, map[string]any{
+		"message":     alertSummary(ev),
+		"alias":       ev.DedupKey,
+		"description": description,
+		"priority":    priority,
+		"source":      "flowd",
+		"details":     details,
+	})
+}
+
+func (a *Alerter) post(ctx context.Context, target string, header http.Header, body any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", req.URL.Host, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func alertSummary(ev alertEvent) string {
+	return fmt.Sprintf("flowd job %s failed %d time(s) in a row", ev.JobID, ev.Failures)
+}
+
+func alertDetails(ev alertEvent) map[string]any {
+	details := map[string]any{
+		"job_id":               ev.JobID,
+		"run_id":               ev.RunID,
+		"consecutive_failures": ev.Failures,
+	}
+	if ev.Error != "" {
+		details["error"] = ev.Error
+	}
+	return details
+}
+
+func endpoint(override, fallback string) string {
+	if override = strings.TrimSpace(override); override != "" {
+		return strings.TrimRight(override, "/")
+	}
+	return fallback
+}
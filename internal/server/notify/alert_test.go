@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/secrets"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+type alertCall struct {
+	Path   string
+	Query  string
+	Header http.Header
+	Body   map[string]any
+}
+
+func newAlertServer(t *testing.T) (*httptest.Server, func() []alertCall) {
+	t.Helper()
+	var (
+		mu    sync.Mutex
+		calls []alertCall
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := alertCall{Path: r.URL.EscapedPath(), Query: r.URL.RawQuery, Header: r.Header.Clone()}
+		_ = json.NewDecoder(r.Body).Decode(&call.Body)
+		mu.Lock()
+		calls = append(calls, call)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []alertCall {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]alertCall(nil), calls...)
+	}
+}
+
+func alertJob(rules ...types.AlertRule) *types.Config {
+	return &types.Config{Notify: &types.NotifyConfig{Alerts: rules}}
+}
+
+func TestAlerterPagerDutyTriggersAfterConsecutiveFailures(t *testing.T) {
+	srv, calls := newAlertServer(t)
+	a := NewAlerter(AlertConfig{
+		Secrets:   secrets.Map{"env:PD_KEY": "routing-key\n"},
+		PublicURL: "https://flowd.example",
+	})
+	job := alertJob(types.AlertRule{Provider: "pagerduty", Failures: 2, KeyRef: "env:PD_KEY", Severity: "critical", APIURL: srv.URL})
+	start := time.Now().UTC()
+	fail := errors.New("step main exited 1")
+
+	a.RunFinished(job, finishedRun("r1", "failed", start), fail)
+	a.RunFinished(job, finishedRun("r2", "canceled", start), nil)
+	a.RunFinished(job, finishedRun("r3", "failed", start), fail)
+	a.RunFinished(job, finishedRun("r4", "failed", start), fail)
+	a.RunFinished(job, finishedRun("r5", "completed", start), nil)
+	a.RunFinished(job, finishedRun("r6", "completed", start), nil)
+	a.Flush()
+
+	got := calls()
+	if len(got) != 2 {
+		t.Fatalf("expected trigger and resolve, got %+v", got)
+	}
+	trigger, resolve := got[0].Body, got[1].Body
+	if got[0].Path != "/v2/enqueue" || trigger["event_action"] != "trigger" || trigger["routing_key"] != "routing-key" {
+		t.Fatalf("unexpected trigger %s %v", got[0].Path, trigger)
+	}
+	if trigger["dedup_key"] != "flowd/nightly" || resolve["dedup_key"] != "flowd/nightly" || resolve["event_action"] != "resolve" {
+		t.Fatalf("expected resolve with the trigger's dedup key, got %v then %v", trigger, resolve)
+	}
+	payload, _ := trigger["payload"].(map[string]any)
+	details, _ := payload["custom_details"].(map[string]any)
+	if payload["severity"] != "critical" || details["run_id"] != "r3" || details["consecutive_failures"] != float64(2) {
+		t.Fatalf("unexpected payload %v", payload)
+	}
+	if links, _ := trigger["links"].([]any); len(links) != 1 {
+		t.Fatalf("expected run link, got %v", trigger["links"])
+	}
+}
+
+func TestAlerterOpsgenieCreatesAndClosesByAlias(t *testing.T) {
+	srv, calls := newAlertServer(t)
+	a := NewAlerter(AlertConfig{Secrets: secrets.Map{"env:OG_KEY": "genie"}})
+	job := alertJob(types.AlertRule{Provider: "opsgenie", Failures: 1, KeyRef: "env:OG_KEY", Severity: "P2", APIURL: srv.URL + "/"})
+	start := time.Now().UTC()
+	a.RunFinished(job, finishedRun("r1", "failed", start), nil)
+	a.RunFinished(job, finishedRun("r2", "completed", start), nil)
+	a.Flush()
+
+	got := calls()
+	if len(got) != 2 {
+		t.Fatalf("expected create and close, got %+v", got)
+	}
+	if got[0].Path != "/v2/alerts" || got[0].Body["alias"] != "flowd/nightly" || got[0].Body["priority"] != "P2" {
+		t.Fatalf("unexpected create %s %v", got[0].Path, got[0].Body)
+	}
+	if got[0].Header.Get("Authorization") != "GenieKey genie" {
+		t.Fatalf("expected GenieKey auth, got %v", got[0].Header)
+	}
+	if got[1].Path != "/v2/alerts/flowd%2Fnightly/close" || got[1].Query != "identifierType=alias" {
+		t.Fatalf("unexpected close %s?%s", got[1].Path, got[1].Query)
+	}
+}
@@ -0,0 +1,240 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+const sendTimeout = time.Minute
+
+// Default templates used when a job leaves subject or body empty.
+const (
+	DefaultSubject       = `[flowd] {{.JobID}} {{.Status}}`
+	DefaultBody          = "Run {{.RunID}} of {{.JobID}} finished with status {{.Status}}.\n{{if .PreviousStatus}}Previous run: {{.PreviousStatus}}.\n{{end}}Started: {{.StartedAt.Format \"2006-01-02 15:04:05Z07:00\"}}\nDuration: {{.Duration}}\n{{if .Error}}Error: {{.Error}}\n{{end}}{{if .URL}}\n{{.URL}}\n{{end}}"
+	DefaultDigestSubject = `[flowd] {{.JobID}}: {{len .Runs}} run(s)`
+	DefaultDigestBody    = "{{len .Runs}} run(s) of {{.JobID}} finished between {{.Since.Format \"2006-01-02 15:04Z07:00\"}} and {{.Until.Format \"2006-01-02 15:04Z07:00\"}}.\n\n{{range .Runs}}{{.FinishedAt.Format \"2006-01-02 15:04:05Z07:00\"}}  {{.Status}}  {{.RunID}}{{if .Error}}  {{.Error}}{{end}}\n{{end}}"
+)
+
+// RunInfo is the data passed to per-run templates.
+type RunInfo struct {
+	RunID          string
+	JobID          string
+	Status         string
+	PreviousStatus string
+	StartedAt      time.Time
+	FinishedAt     time.Time
+	Duration       time.Duration
+	Error          string
+	URL            string
+}
+
+// DigestInfo is the data passed to digest templates.
+type DigestInfo struct {
+	JobID string
+	Since time.Time
+	Until time.Time
+	Runs  []RunInfo
+}
+
+// EmailConfig configures the email notifier.
+type EmailConfig struct {
+	Mailer Mailer
+	// PublicURL is the externally reachable base URL used for run links.
+	PublicURL string
+	Now       func() time.Time
+	Logger    *slog.Logger
+}
+
+// Email mails finished runs for jobs that declare notify.email. It remembers
+// each job's last status to support only_on_change and buffers runs of jobs
+// with a digest interval until the interval elapses.
+type Email struct {
+	cfg EmailConfig
+
+	mu      sync.Mutex
+	last    map[string]string
+	digests map[string]*pendingDigest
+	wg      sync.WaitGroup
+}
+
+type pendingDigest struct {
+	notify types.EmailNotify
+	since  time.Time
+	runs   []RunInfo
+	timer  *time.Timer
+}
+
+// NewEmail constructs an email notifier.
+func NewEmail(cfg EmailConfig) *Email {
+	if cfg.Now == nil {
+		cfg.Now = func() time.Time { return time.Now().UTC() }
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return &Email{
+		cfg:     cfg,
+		last:    make(map[string]string),
+		digests: make(map[string]*pendingDigest),
+	}
+}
+
+// RunFinished handles a run that reached a terminal status. Delivery happens
+// in the background so a slow relay never holds up run completion.
+func (e *Email) RunFinished(job *types.Config, run runstore.Run, runErr error) {
+	e.mu.Lock()
+	previous, known := e.last[run.JobID]
+	e.last[run.JobID] = run.Status
+	if job == nil || job.Notify == nil || job.Notify.Email == nil {
+		e.mu.Unlock()
+		return
+	}
+	cfg := *job.Notify.Email
+	if len(cfg.On) > 0 && !slices.Contains(cfg.On, run.Status) {
+		e.mu.Unlock()
+		return
+	}
+	if cfg.OnlyOnChange && known && previous == run.Status {
+		e.mu.Unlock()
+		return
+	}
+	info := e.runInfo(run, previous, runErr)
+	if interval, _ := time.ParseDuration(cfg.Digest); interval > 0 {
+		e.enqueueDigest(cfg, info, interval)
+		e.mu.Unlock()
+		return
+	}
+	e.mu.Unlock()
+
+	e.deliver(run.JobID, func() (Message, error) { return renderRun(cfg, info) })
+}
+
+// Flush sends every pending digest immediately and waits for outstanding
+// deliveries, e.g. during shutdown.
+func (e *Email) Flush() {
+	e.mu.Lock()
+	jobs := make([]string, 0, len(e.digests))
+	for jobID, pending := range e.digests {
+		pending.timer.Stop()
+		jobs = append(jobs, jobID)
+	}
+	e.mu.Unlock()
+	for _, jobID := range jobs {
+		e.flushDigest(jobID)
+	}
+	e.wg.Wait()
+}
+
+// enqueueDigest buffers info, starting the job's interval timer on the first
+// run. Callers hold e.mu.
+func (e *Email) enqueueDigest(cfg types.EmailNotify, info RunInfo, interval time.Duration) {
+	pending, ok := e.digests[info.JobID]
+	if !ok {
+		pending = &pendingDigest{since: e.cfg.Now()}
+		jobID := info.JobID
+		pending.timer = time.AfterFunc(interval, func() { e.flushDigest(jobID) })
+		e.digests[info.JobID] = pending
+	}
+	pending.notify = cfg
+	pending.runs = append(pending.runs, info)
+}
+
+func (e *Email) flushDigest(jobID string) {
+	e.mu.Lock()
+	pending, ok := e.digests[jobID]
+	delete(e.digests, jobID)
+	e.mu.Unlock()
+	if !ok || len(pending.runs) == 0 {
+		return
+	}
+	digest := DigestInfo{JobID: jobID, Since: pending.since, Until: e.cfg.Now(), Runs: pending.runs}
+	e.deliver(jobID, func() (Message, error) { return renderDigest(pending.notify, digest) })
+}
+
+func (e *Email) deliver(jobID string, render func() (Message, error)) {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		msg, err := render()
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			err = e.cfg.Mailer.Send(ctx, msg)
+			cancel()
+		}
+		if err != nil {
+			e.cfg.Logger.Warn("notify.email.failed",
+				slog.String("job_id", jobID),
+				slog.String("error", err.Error()))
+		}
+	}()
+}
+
+func (e *Email) runInfo(run runstore.Run, previous string, runErr error) RunInfo {
+	info := RunInfo{
+		RunID:          run.ID,
+		JobID:          run.JobID,
+		Status:         run.Status,
+		PreviousStatus: previous,
+		StartedAt:      run.StartedAt,
+		FinishedAt:     e.cfg.Now(),
+	}
+	if run.FinishedAt != nil {
+		info.FinishedAt = *run.FinishedAt
+	}
+	if !info.StartedAt.IsZero() {
+		info.Duration = info.FinishedAt.Sub(info.StartedAt).Round(time.Second)
+	}
+	if runErr != nil {
+		info.Error = runErr.Error()
+	}
+	if base := strings.TrimRight(e.cfg.PublicURL, "/"); base != "" {
+		info.URL = base + "/runs/" + run.ID
+	}
+	return info
+}
+
+func renderRun(cfg types.EmailNotify, info RunInfo) (Message, error) {
+	return render(cfg.To, cfg.Subject, DefaultSubject, cfg.Body, DefaultBody, info)
+}
+
+func renderDigest(cfg types.EmailNotify, digest DigestInfo) (Message, error) {
+	return render(cfg.To, cfg.DigestSubject, DefaultDigestSubject, cfg.DigestBody, DefaultDigestBody, digest)
+}
+
+func render(to []string, subject, defSubject, body, defBody string, data any) (Message, error) {
+	msg := Message{To: append([]string(nil), to...)}
+	var err error
+	if msg.Subject, err = execute("subject", subject, defSubject, data); err != nil {
+		return msg, err
+	}
+	if msg.Body, err = execute("body", body, defBody, data); err != nil {
+		return msg, err
+	}
+	return msg, nil
+}
+
+func execute(name, text, fallback string, data any) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		text = fallback
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse %s template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render %s template: %w", name, err)
+	}
+	return buf.String(), nil
+}
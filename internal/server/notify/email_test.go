@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package notify
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []Message
+}
+
+func (m *fakeMailer) Send(_ context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *fakeMailer) messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.sent...)
+}
+
+func emailJob(cfg types.EmailNotify) *types.Config {
+	return &types.Config{Notify: &types.NotifyConfig{Email: &cfg}}
+}
+
+func finishedRun(id, status string, started time.Time) runstore.Run {
+	finished := started.Add(90 * time.Second)
+	return runstore.Run{ID: id, JobID: "nightly", Status: status, StartedAt: started, FinishedAt: &finished}
+}
+
+func TestEmailRendersTemplates(t *testing.T) {
+	mailer := &fakeMailer{}
+	e := NewEmail(EmailConfig{Mailer: mailer, PublicURL: "https://flowd.example/"})
+	job := emailJob(types.EmailNotify{
+		To:      []string{"ops@example.com"},
+		Subject: "{{.JobID}} is {{.Status}}",
+	})
+	start := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	e.RunFinished(job, finishedRun("r1", "failed", start), errors.New("step main exited 3"))
+	e.Flush()
+
+	sent := mailer.messages()
+	if len(sent) != 1 {
+		t.Fatalf("expected one message, got %d", len(sent))
+	}
+	msg := sent[0]
+	if msg.Subject != "nightly is failed" || msg.To[0] != "ops@example.com" {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+	for _, want := range []string{"Run r1 of nightly finished with status failed", "Duration: 1m30s", "Error: step main exited 3", "https://flowd.example/runs/r1"} {
+		if !strings.Contains(msg.Body, want) {
+			t.Fatalf("expected body to contain %q, got:\n%s", want, msg.Body)
+		}
+	}
+}
+
+func TestEmailOnlyOnChangeAndStatusFilter(t *testing.T) {
+	mailer := &fakeMailer{}
+	e := NewEmail(EmailConfig{Mailer: mailer})
+	job := emailJob(types.EmailNotify{To: []string{"ops@example.com"}, OnlyOnChange: true, On: []string{"completed", "failed"}})
+	start := time.Now().UTC()
+	for i, status := range []string{"completed", "completed", "failed", "failed", "canceled", "completed"} {
+		e.RunFinished(job, finishedRun("r"+string(rune('a'+i)), status, start), nil)
+	}
+	e.Flush()
+
+	var got []string
+	for _, msg := range mailer.messages() {
+		got = append(got, msg.Subject)
+	}
+	// Delivery is asynchronous, so compare without order. canceled is
+	// filtered by on but still counts as the previous status.
+	slices.Sort(got)
+	want := []string{"[flowd] nightly completed", "[flowd] nightly completed", "[flowd] nightly failed"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestEmailDigestBatchesRuns(t *testing.T) {
+	mailer := &fakeMailer{}
+	e := NewEmail(EmailConfig{Mailer: mailer})
+	job := emailJob(types.EmailNotify{
+		To:         []string{"ops@example.com"},
+		Digest:     "1h",
+		DigestBody: "{{range .Runs}}{{.RunID}}={{.Status}};{{end}}",
+	})
+	start := time.Now().UTC()
+	e.RunFinished(job, finishedRun("r1", "completed", start), nil)
+	e.RunFinished(job, finishedRun("r2", "failed", start), nil)
+	if len(mailer.messages()) != 0 {
+		t.Fatalf("expected digest to hold messages until the interval elapses")
+	}
+	e.Flush()
+
+	sent := mailer.messages()
+	if len(sent) != 1 {
+		t.Fatalf("expected one digest, got %d", len(sent))
+	}
+	if sent[0].Subject != "[flowd] nightly: 2 run(s)" || sent[0].Body != "r1=completed;r2=failed;" {
+		t.Fatalf("unexpected digest %+v", sent[0])
+	}
+}
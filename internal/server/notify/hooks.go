@@ -0,0 +1,254 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// DefaultHookTimeout bounds each hook delivery when RunHookConfig.Timeout is
+// unset.
+const DefaultHookTimeout = 10 * time.Second
+
+// Run lifecycle hook events.
+const (
+	HookRunStart  = "run.start"
+	HookRunFinish = "run.finish"
+)
+
+// hookOutputLimit bounds how much command output is quoted in errors.
+const hookOutputLimit = 1 << 10
+
+// RunHookConfig configures server-wide run lifecycle hooks.
+type RunHookConfig struct {
+	// Targets are http(s) URLs that receive a JSON POST, or commands that
+	// receive the same document on stdin.
+	Targets []string
+	Timeout time.Duration
+	// PublicURL is the externally reachable base URL used for run links.
+	PublicURL string
+	Client    *http.Client
+	Logger    *slog.Logger
+	// OnFailure, when set, is told about each failed delivery so it can be
+	// surfaced on the run. Failures never affect the run's status.
+	OnFailure func(runID, event, target string, err error)
+}
+
+// RunHookEvent is the document delivered to run lifecycle hooks.
+type RunHookEvent struct {
+	Event      string     `json:"event"`
+	RunID      string     `json:"run_id"`
+	JobID      string     `json:"job_id"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	URL        string     `json:"url,omitempty"`
+}
+
+// RunHooks calls operator-configured hooks when any run starts or finishes,
+// e.g. to register runs in a CMDB. Deliveries run in the background, in order
+// per run, so a slow or failing hook never delays or fails the run.
+type RunHooks struct {
+	cfg RunHookConfig
+
+	mu   sync.Mutex
+	tail map[string]chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewRunHooks constructs lifecycle hooks.
+func NewRunHooks(cfg RunHookConfig) *RunHooks {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultHookTimeout
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return &RunHooks{cfg: cfg, tail: make(map[string]chan struct{})}
+}
+
+// RunStarted delivers a run.start event.
+func (h *RunHooks) RunStarted(job *types.Config, run runstore.Run) {
+	h.enqueue(h.event(HookRunStart, run, nil))
+}
+
+// RunFinished delivers a run.finish event.
+func (h *RunHooks) RunFinished(job *types.Config, run runstore.Run, runErr error) {
+	h.enqueue(h.event(HookRunFinish, run, runErr))
+}
+
+// Flush waits for outstanding deliveries.
+func (h *RunHooks) Flush() {
+	h.wg.Wait()
+}
+
+func (h *RunHooks) event(name string, run runstore.Run, runErr error) RunHookEvent {
+	ev := RunHookEvent{
+		Event:      name,
+		RunID:      run.ID,
+		JobID:      run.JobID,
+		Status:     run.Status,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+	}
+	if runErr != nil {
+		ev.Error = runErr.Error()
+	}
+	if base := strings.TrimRight(h.cfg.PublicURL, "/"); base != "" {
+		ev.URL = base + "/runs/" + run.ID
+	}
+	return ev
+}
+
+// enqueue delivers ev to every target after the run's previous delivery.
+func (h *RunHooks) enqueue(ev RunHookEvent) {
+	if len(h.cfg.Targets) == 0 {
+		return
+	}
+	h.mu.Lock()
+	prev := h.tail[ev.RunID]
+	done := make(chan struct{})
+	h.tail[ev.RunID] = done
+	h.mu.Unlock()
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		defer func() {
+			close(done)
+			h.mu.Lock()
+			if h.tail[ev.RunID] == done {
+				delete(h.tail, ev.RunID)
+			}
+			h.mu.Unlock()
+		}()
+		if prev != nil {
+			<-prev
+		}
+		body, err := json.Marshal(ev)
+		if err != nil {
+			return
+		}
+		for _, target := range h.cfg.Targets {
+			ctx, cancel := context.WithTimeout(context.Background(), h.cfg.Timeout)
+			err := h.deliver(ctx, target, ev, body)
+			cancel()
+			if err != nil {
+				h.cfg.Logger.Warn("notify.hook.failed",
+					slog.String("run_id", ev.RunID),
+					slog.String("event", ev.Event),
+					slog.String("target", hookTargetLabel(target)),
+					slog.String("error", err.Error()))
+				if h.cfg.OnFailure != nil {
+					h.cfg.OnFailure(ev.RunID, ev.Event, hookTargetLabel(target), err)
+				}
+				continue
+			}
+			h.cfg.Logger.Info("notify.hook.sent",
+				slog.String("run_id", ev.RunID),
+				slog.String("event", ev.Event),
+				slog.String("target", hookTargetLabel(target)))
+		}
+	}()
+}
+
+func (h *RunHooks) deliver(ctx context.Context, target string, ev RunHookEvent, body []byte) error {
+	if IsHookURL(target) {
+		return h.post(ctx, target, body)
+	}
+	return runHookCommand(ctx, target, ev, body)
+}
+
+func (h *RunHooks) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, hookOutputLimit))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// runHookCommand runs a hook command with the event on stdin and its key
+// fields in FLWD_HOOK_EVENT, FLWD_RUN_ID, FLWD_JOB_ID and FLWD_RUN_STATUS.
+func runHookCommand(ctx context.Context, command string, ev RunHookEvent, body []byte) error {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return fmt.Errorf("empty hook command")
+	}
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, fields[0], fields[1:]...)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.WaitDelay = time.Second
+	cmd.Env = append(os.Environ(),
+		"FLWD_HOOK_EVENT="+ev.Event,
+		"FLWD_RUN_ID="+ev.RunID,
+		"FLWD_JOB_ID="+ev.JobID,
+		"FLWD_RUN_STATUS="+ev.Status,
+	)
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("timed out: %w", ctx.Err())
+		}
+		msg := strings.TrimSpace(output.String())
+		if len(msg) > hookOutputLimit {
+			msg = msg[len(msg)-hookOutputLimit:]
+		}
+		if msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// IsHookURL reports whether a hook target is delivered over HTTP rather than
+// run as a command.
+func IsHookURL(target string) bool {
+	return strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://")
+}
+
+// hookTargetLabel names a target in logs without leaking URL credentials or
+// query strings, which often carry tokens.
+func hookTargetLabel(target string) string {
+	if !IsHookURL(target) {
+		if fields := strings.Fields(target); len(fields) > 0 {
+			return fields[0]
+		}
+		return target
+	}
+	scheme, rest, _ := strings.Cut(target, "://")
+	if at := strings.Index(rest, "@"); at >= 0 && at < strings.IndexAny(rest+"/", "/") {
+		rest = rest[at+1:]
+	}
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		rest = rest[:i]
+	}
+	return scheme + "://" + rest
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+func TestRunHooksPostStartAndFinishInOrder(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []RunHookEvent
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev RunHookEvent
+		_ = json.NewDecoder(r.Body).Decode(&ev)
+		if ev.Event == HookRunStart {
+			// A slow first delivery must not let the finish overtake it.
+			time.Sleep(50 * time.Millisecond)
+		}
+		mu.Lock()
+		events = append(events, ev)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	hooks := NewRunHooks(RunHookConfig{Targets: []string{srv.URL}, PublicURL: "https://flowd.example/"})
+	start := time.Now().UTC()
+
+	hooks.RunStarted(nil, runstore.Run{ID: "r1", JobID: "nightly", Status: "running", StartedAt: start})
+	hooks.RunFinished(nil, finishedRun("r1", "failed", start), errors.New("step main exited 1"))
+	hooks.Flush()
+
+	if len(events) != 2 || events[0].Event != HookRunStart || events[1].Event != HookRunFinish {
+		t.Fatalf("expected start then finish, got %+v", events)
+	}
+	finish := events[1]
+	if finish.RunID != "r1" || finish.JobID != "nightly" || finish.Status != "failed" || finish.FinishedAt == nil ||
+		finish.Error != "step main exited 1" || finish.URL != "https://flowd.example/runs/r1" {
+		t.Fatalf("unexpected finish event %+v", finish)
+	}
+}
+
+func TestRunHooksRunCommand(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	script := filepath.Join(dir, "hook.sh")
+	body := "#!/bin/sh\n{ echo \"$FLWD_HOOK_EVENT $FLWD_RUN_ID $FLWD_JOB_ID $FLWD_RUN_STATUS\"; cat; echo; } >> " + out + "\n"
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	hooks := NewRunHooks(RunHookConfig{Targets: []string{script}})
+	hooks.RunFinished(nil, finishedRun("r2", "completed", time.Now().UTC()), nil)
+	hooks.Flush()
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read hook output: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || lines[0] != "run.finish r2 nightly completed" || !strings.Contains(lines[1], `"run_id":"r2"`) {
+		t.Fatalf("unexpected hook output %q", data)
+	}
+}
+
+func TestRunHooksReportFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	var (
+		mu       sync.Mutex
+		failures []string
+	)
+	hooks := NewRunHooks(RunHookConfig{
+		Targets: []string{srv.URL + "/hook?token=secret", "sleep 5", "false"},
+		Timeout: 100 * time.Millisecond,
+		OnFailure: func(runID, event, target string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failures = append(failures, runID+" "+event+" "+target+": "+err.Error())
+		},
+	})
+	hooks.RunStarted(nil, runstore.Run{ID: "r3", JobID: "nightly", Status: "running", StartedAt: time.Now().UTC()})
+	hooks.Flush()
+
+	if len(failures) != 3 {
+		t.Fatalf("expected three failures, got %q", failures)
+	}
+	if want := "r3 run.start " + srv.URL + "/hook: unexpected status 502"; failures[0] != want {
+		t.Fatalf("got %q, want %q", failures[0], want)
+	}
+	if !strings.HasPrefix(failures[1], "r3 run.start sleep: timed out") || !strings.HasPrefix(failures[2], "r3 run.start false: exit status 1") {
+		t.Fatalf("unexpected command failures %q", failures)
+	}
+}
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/flowd-org/flowd/internal/secrets"
+)
+
+// fakeSMTP accepts one session and records the AUTH PLAIN credentials and
+// the DATA payload.
+func fakeSMTP(t *testing.T, extensions ...string) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	out := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		var transcript strings.Builder
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.TrimRight(line, "\r\n")
+			switch verb := strings.ToUpper(strings.Fields(cmd + " x")[0]); verb {
+			case "EHLO":
+				reply("250-fake")
+				for _, ext := range extensions {
+					reply("250-" + ext)
+				}
+				reply("250 AUTH PLAIN")
+			case "AUTH":
+				creds, _ := base64.StdEncoding.DecodeString(strings.Fields(cmd)[2])
+				transcript.WriteString("AUTH " + strings.ReplaceAll(string(creds), "\x00", "|") + "\n")
+				reply("235 ok")
+			case "DATA":
+				reply("354 go ahead")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					transcript.WriteString(l)
+				}
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				out <- transcript.String()
+				return
+			default:
+				transcript.WriteString(cmd + "\n")
+				reply("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().String(), out
+}
+
+func TestSMTPMailerSendsWithAuth(t *testing.T) {
+	addr, transcript := fakeSMTP(t)
+	m, err := NewSMTPMailer(SMTPConfig{
+		Addr:        addr,
+		From:        "flowd@example.com",
+		Username:    "flowd",
+		PasswordRef: "env:SMTP_PASSWORD",
+		TLS:         TLSNone,
+		Secrets:     secrets.Map{"env:SMTP_PASSWORD": "s3cret\n"},
+	})
+	if err != nil {
+		t.Fatalf("new mailer: %v", err)
+	}
+	msg := Message{To: []string{"ops@example.com"}, Subject: "nightly failed\r\nBcc: x@example.com", Body: "line one\nline two\n"}
+	if err := m.Send(context.Background(), msg); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	got := <-transcript
+	for _, want := range []string{
+		"AUTH |flowd|s3cret",
+		"MAIL FROM:<flowd@example.com>",
+		"RCPT TO:<ops@example.com>",
+		"Subject: nightly failed Bcc: x@example.com\r\n",
+		"line one\r\nline two\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected transcript to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestSMTPMailerRequiresStartTLS(t *testing.T) {
+	addr, _ := fakeSMTP(t)
+	m, err := NewSMTPMailer(SMTPConfig{Addr: addr, From: "flowd@example.com"})
+	if err != nil {
+		t.Fatalf("new mailer: %v", err)
+	}
+	err = m.Send(context.Background(), Message{To: []string{"ops@example.com"}, Subject: "s", Body: "b"})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("expected STARTTLS to be required, got %v", err)
+	}
+}
+
+func TestNewSMTPMailerValidates(t *testing.T) {
+	for _, cfg := range []SMTPConfig{
+		{Addr: "mail.example.com", From: "a@example.com"},
+		{Addr: "mail.example.com:587"},
+		{Addr: "mail.example.com:587", From: "a@example.com", TLS: "ssl3"},
+		{Addr: "mail.example.com:587", From: "a@example.com", Username: "u"},
+	} {
+		if _, err := NewSMTPMailer(cfg); err == nil {
+			t.Fatalf("expected %+v to be rejected", cfg)
+		}
+	}
+}
@@ -0,0 +1,479 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package oidc validates bearer tokens issued by an OpenID Connect provider.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// DefaultClockSkew is the leeway allowed on exp, nbf and iat.
+	DefaultClockSkew = time.Minute
+	// DefaultKeysTTL is how long a fetched key set is used before it is
+	// fetched again.
+	DefaultKeysTTL = 15 * time.Minute
+	// minRefreshInterval bounds how often an unknown key ID triggers an
+	// early key set fetch, so forged key IDs cannot flood the provider.
+	minRefreshInterval = 30 * time.Second
+	// refreshBackoff is the wait after a failed key set fetch before the
+	// next attempt; it doubles with each further failure up to
+	// maxRefreshBackoff.
+	refreshBackoff    = 5 * time.Second
+	maxRefreshBackoff = 5 * time.Minute
+	maxDocumentBytes  = 1 << 20
+)
+
+// Config locates the provider and says which tokens it accepts.
+type Config struct {
+	// Issuer is the provider's issuer URL; tokens must carry it as iss.
+	Issuer string
+	// Audience must appear in the token's aud claim.
+	Audience string
+	// JWKSURL overrides the jwks_uri advertised by the provider's discovery
+	// document.
+	JWKSURL string
+	// ClockSkew is the leeway on time claims; zero selects DefaultClockSkew.
+	ClockSkew time.Duration
+	// KeysTTL is how long fetched keys are cached; zero selects
+	// DefaultKeysTTL.
+	KeysTTL time.Duration
+	// HTTPClient fetches the discovery document and key set; nil selects a
+	// client with a 10 second timeout.
+	HTTPClient *http.Client
+}
+
+// Verifier checks token signatures against the provider's published keys and
+// validates the issuer, audience and time claims. It is safe for concurrent
+// use; keys are fetched on first use and cached. Concurrent fetches are
+// collapsed into one, made without holding the cache lock, and a failed fetch
+// keeps the last good key set until a backed-off retry succeeds.
+type Verifier struct {
+	cfg    Config
+	client *http.Client
+	now    func() time.Time
+	fetch  singleflight.Group
+
+	mu       sync.Mutex
+	jwksURL  string
+	keys     *keySet
+	fetched  time.Time
+	failures int
+	retryAt  time.Time
+	fetchErr error
+}
+
+// keySet is a fetched JSON Web Key Set. Keys without a key ID cannot be told
+// apart, so they are kept in publication order rather than by ID.
+type keySet struct {
+	byID    map[string]crypto.PublicKey
+	unnamed []crypto.PublicKey
+}
+
+// candidates returns the keys that may have signed a token with key ID kid.
+// Tokens without a key ID are tried against the unnamed keys in order, then
+// the named ones.
+func (s *keySet) candidates(kid string) ([]crypto.PublicKey, error) {
+	if kid != "" {
+		key, ok := s.byID[kid]
+		if !ok {
+			return nil, fmt.Errorf("token key %q is not published by the issuer", kid)
+		}
+		return []crypto.PublicKey{key}, nil
+	}
+	keys := slices.Clone(s.unnamed)
+	for _, id := range slices.Sorted(maps.Keys(s.byID)) {
+		keys = append(keys, s.byID[id])
+	}
+	return keys, nil
+}
+
+// NewVerifier returns a verifier for cfg. It does not contact the provider.
+func NewVerifier(cfg Config) (*Verifier, error) {
+	cfg.Issuer = strings.TrimSpace(cfg.Issuer)
+	cfg.Audience = strings.TrimSpace(cfg.Audience)
+	u, err := url.Parse(cfg.Issuer)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("oidc issuer %q must be an http(s) URL", cfg.Issuer)
+	}
+	if cfg.Audience == "" {
+		return nil, errors.New("oidc audience is required")
+	}
+	if cfg.ClockSkew < 0 {
+		return nil, errors.New("oidc clock skew must not be negative")
+	}
+	if cfg.ClockSkew == 0 {
+		cfg.ClockSkew = DefaultClockSkew
+	}
+	if cfg.KeysTTL <= 0 {
+		cfg.KeysTTL = DefaultKeysTTL
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Verifier{cfg: cfg, client: client, now: time.Now, jwksURL: strings.TrimSpace(cfg.JWKSURL)}, nil
+}
+
+// Issuer returns the configured issuer URL.
+func (v *Verifier) Issuer() string {
+	return v.cfg.Issuer
+}
+
+// Verify validates token and returns its claims.
+func (v *Verifier) Verify(ctx context.Context, token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token is not a JWT")
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("decode token header: %w", err)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, fmt.Errorf("parse token header: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decode token signature: %w", err)
+	}
+	if _, ok := algorithms[header.Alg]; !ok {
+		return nil, fmt.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	keys, err := v.keysFor(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	verified := false
+	for _, key := range keys {
+		if verifySignature(header.Alg, key, signed, sig) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("invalid token signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decode token claims: %w", err)
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("parse token claims: %w", err)
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *Verifier) validateClaims(claims map[string]any) error {
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != strings.TrimSuffix(v.cfg.Issuer, "/") {
+		return fmt.Errorf("token issuer %q is not trusted", iss)
+	}
+	if !audienceContains(claims["aud"], v.cfg.Audience) {
+		return fmt.Errorf("token audience does not include %q", v.cfg.Audience)
+	}
+	now := v.now()
+	skew := v.cfg.ClockSkew
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return errors.New("token has no exp claim")
+	}
+	if now.After(exp.Add(skew)) {
+		return errors.New("token expired")
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(skew).Before(nbf) {
+		return errors.New("token not yet valid")
+	}
+	if iat, ok := numericDate(claims["iat"]); ok && now.Add(skew).Before(iat) {
+		return errors.New("token issued in the future")
+	}
+	return nil
+}
+
+func audienceContains(raw any, audience string) bool {
+	switch aud := raw.(type) {
+	case string:
+		return aud == audience
+	case []any:
+		for _, v := range aud {
+			if s, ok := v.(string); ok && s == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func numericDate(raw any) (time.Time, bool) {
+	f, ok := raw.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*1e9)), true
+}
+
+// keysFor returns the cached keys that may have signed a token with key ID
+// kid, fetching the key set when it is stale or lacks kid.
+func (v *Verifier) keysFor(ctx context.Context, kid string) ([]crypto.PublicKey, error) {
+	v.mu.Lock()
+	due := v.refreshDueLocked(v.now(), kid)
+	v.mu.Unlock()
+	if due {
+		// The fetch is shared by every waiting request, so it does not run
+		// under any one request's context. A fetch that finished while this
+		// request was on its way here makes another one unnecessary.
+		result := v.fetch.DoChan("keys", func() (any, error) {
+			v.mu.Lock()
+			due := v.refreshDueLocked(v.now(), kid)
+			v.mu.Unlock()
+			if !due {
+				return nil, nil
+			}
+			return nil, v.refresh(context.WithoutCancel(ctx))
+		})
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-result:
+		}
+	}
+	v.mu.Lock()
+	keys, fetchErr := v.keys, v.fetchErr
+	v.mu.Unlock()
+	if keys == nil {
+		return nil, fetchErr
+	}
+	return keys.candidates(kid)
+}
+
+// refreshDueLocked reports whether the key set must be fetched before
+// looking up kid. No fetch is due while backing off from a failed one.
+func (v *Verifier) refreshDueLocked(now time.Time, kid string) bool {
+	if now.Before(v.retryAt) {
+		return false
+	}
+	if v.keys == nil || now.Sub(v.fetched) >= v.cfg.KeysTTL {
+		return true
+	}
+	// The provider may have rotated its keys since the last fetch.
+	_, known := v.keys.byID[kid]
+	return kid != "" && !known && now.Sub(v.fetched) >= minRefreshInterval
+}
+
+// refresh fetches the key set and records the outcome. A failure keeps the
+// last good keys and delays the next attempt.
+func (v *Verifier) refresh(ctx context.Context) error {
+	keys, err := v.fetchKeys(ctx)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := v.now()
+	if err != nil {
+		v.failures++
+		backoff := refreshBackoff << min(v.failures-1, 6)
+		v.retryAt = now.Add(min(backoff, maxRefreshBackoff))
+		v.fetchErr = err
+		return err
+	}
+	v.keys = keys
+	v.fetched = now
+	v.failures = 0
+	v.retryAt = time.Time{}
+	v.fetchErr = nil
+	return nil
+}
+
+func (v *Verifier) fetchKeys(ctx context.Context) (*keySet, error) {
+	v.mu.Lock()
+	jwksURL := v.jwksURL
+	v.mu.Unlock()
+	if jwksURL == "" {
+		var doc struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		discovery := strings.TrimSuffix(v.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+		if err := v.getJSON(ctx, discovery, &doc); err != nil {
+			return nil, fmt.Errorf("oidc discovery: %w", err)
+		}
+		if strings.TrimSuffix(doc.Issuer, "/") != strings.TrimSuffix(v.cfg.Issuer, "/") {
+			return nil, fmt.Errorf("oidc discovery: issuer %q does not match %q", doc.Issuer, v.cfg.Issuer)
+		}
+		if doc.JWKSURI == "" {
+			return nil, errors.New("oidc discovery: jwks_uri missing")
+		}
+		jwksURL = doc.JWKSURI
+		v.mu.Lock()
+		v.jwksURL = jwksURL
+		v.mu.Unlock()
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := v.getJSON(ctx, jwksURL, &set); err != nil {
+		return nil, fmt.Errorf("oidc keys: %w", err)
+	}
+	keys := &keySet{byID: make(map[string]crypto.PublicKey, len(set.Keys))}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// Keys of unsupported types are skipped.
+			continue
+		}
+		if k.Kid == "" {
+			keys.unnamed = append(keys.unnamed, key)
+		} else {
+			keys.byID[k.Kid] = key
+		}
+	}
+	if len(keys.byID) == 0 && len(keys.unnamed) == 0 {
+		return nil, errors.New("oidc keys: no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (v *Verifier) getJSON(ctx context.Context, target string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", target, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxDocumentBytes)).Decode(out)
+}
+
+// jwk is one entry of a JSON Web Key Set.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// algorithms maps the accepted JWS algorithms to their hash. Symmetric and
+// "none" algorithms are refused.
+var algorithms = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed, sig []byte) error {
+	hash := algorithms[alg]
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		switch alg[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(pub, hash, digest, sig)
+		case "PS":
+			return rsa.VerifyPSS(pub, hash, digest, sig, nil)
+		}
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if alg[:2] != "ES" || len(sig) != 2*size {
+			break
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if ecdsa.Verify(pub, digest, r, s) {
+			return nil
+		}
+	}
+	return errors.New("signature mismatch")
+}
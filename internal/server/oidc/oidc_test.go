@@ -0,0 +1,319 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testProvider struct {
+	server *httptest.Server
+	keys   atomic.Value // []map[string]string
+	fetch  atomic.Int32
+	// fail makes the key set endpoint answer 503.
+	fail atomic.Bool
+	// hold, when set, stalls key set fetches until it is closed.
+	hold atomic.Pointer[chan struct{}]
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+	p := &testProvider{}
+	p.keys.Store([]map[string]string{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": p.server.URL, "jwks_uri": p.server.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		p.fetch.Add(1)
+		if hold := p.hold.Load(); hold != nil {
+			<-*hold
+		}
+		if p.fail.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": p.keys.Load()})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA", "kid": kid, "use": "sig",
+		"n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "EC", "kid": kid, "crv": "P-256",
+		"x": b64(key.X.FillBytes(make([]byte, 32))), "y": b64(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func signToken(t *testing.T, alg, kid string, key any, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+	var sig []byte
+	var err error
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest[:])
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	}
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed + "." + b64(sig)
+}
+
+func TestVerifierValidatesSignatureIssuerAudienceAndTime(t *testing.T) {
+	provider := newTestProvider(t)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ec key: %v", err)
+	}
+	provider.keys.Store([]map[string]string{rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey)})
+
+	v, err := NewVerifier(Config{Issuer: provider.server.URL, Audience: "flowd", ClockSkew: 30 * time.Second})
+	if err != nil {
+		t.Fatalf("new verifier: %v", err)
+	}
+	now := time.Unix(1_800_000_000, 0)
+	v.now = func() time.Time { return now }
+	claims := func(overrides map[string]any) map[string]any {
+		c := map[string]any{
+			"iss": provider.server.URL, "aud": []string{"other", "flowd"}, "sub": "alice",
+			"iat": now.Unix(), "exp": now.Add(time.Hour).Unix(), "scope": "runs:read",
+		}
+		for k, val := range overrides {
+			c[k] = val
+		}
+		return c
+	}
+	ctx := context.Background()
+
+	got, err := v.Verify(ctx, signToken(t, "RS256", "rsa-1", rsaKey, claims(nil)))
+	if err != nil || got["sub"] != "alice" {
+		t.Fatalf("expected a valid RS256 token, got %v %v", got, err)
+	}
+	if _, err := v.Verify(ctx, signToken(t, "ES256", "ec-1", ecKey, claims(nil))); err != nil {
+		t.Fatalf("expected a valid ES256 token, got %v", err)
+	}
+	// Expired 20s ago, within the 30s skew.
+	if _, err := v.Verify(ctx, signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"exp": now.Add(-20 * time.Second).Unix()}))); err != nil {
+		t.Fatalf("expected the clock skew to cover a just-expired token, got %v", err)
+	}
+
+	// A valid signature over other claims.
+	genuine := strings.Split(signToken(t, "RS256", "rsa-1", rsaKey, claims(nil)), ".")
+	forged, _ := json.Marshal(claims(map[string]any{"sub": "mallory"}))
+	tampered := genuine[0] + "." + b64(forged) + "." + genuine[2]
+
+	cases := map[string]string{
+		signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"aud": "someone-else"})):               "audience",
+		signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"iss": "https://evil.example"})):       "issuer",
+		signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"exp": now.Add(-time.Minute).Unix()})): "expired",
+		signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"nbf": now.Add(time.Minute).Unix()})):  "not yet valid",
+		signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"exp": nil})):                          "no exp",
+		signToken(t, "RS256", "ec-1", rsaKey, claims(nil)):                                                  "signature",
+		signToken(t, "HS256", "rsa-1", []byte("shared-secret"), claims(nil)):                                "unsupported token algorithm",
+		tampered:               "signature",
+		"jobs:read runs:write": "not a JWT",
+	}
+	for token, want := range cases {
+		if _, err := v.Verify(ctx, token); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error containing %q, got %v", want, err)
+		}
+	}
+	if n := provider.fetch.Load(); n != 1 {
+		t.Fatalf("expected the key set to be fetched once, got %d", n)
+	}
+}
+
+func TestVerifierRefetchesKeysAfterRotation(t *testing.T) {
+	provider := newTestProvider(t)
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	provider.keys.Store([]map[string]string{rsaJWK("old", &oldKey.PublicKey)})
+
+	v, err := NewVerifier(Config{Issuer: provider.server.URL, Audience: "flowd"})
+	if err != nil {
+		t.Fatalf("new verifier: %v", err)
+	}
+	now := time.Unix(1_800_000_000, 0)
+	v.now = func() time.Time { return now }
+	claims := map[string]any{"iss": provider.server.URL, "aud": "flowd", "sub": "ci", "exp": now.Add(time.Hour).Unix()}
+	ctx := context.Background()
+
+	if _, err := v.Verify(ctx, signToken(t, "RS256", "old", oldKey, claims)); err != nil {
+		t.Fatalf("verify with old key: %v", err)
+	}
+	provider.keys.Store([]map[string]string{rsaJWK("new", &newKey.PublicKey)})
+	rotated := signToken(t, "RS256", "new", newKey, claims)
+	// Unknown key IDs do not refetch more often than minRefreshInterval.
+	if _, err := v.Verify(ctx, rotated); err == nil {
+		t.Fatal("expected the rotated key to be unknown right after a fetch")
+	}
+	now = now.Add(minRefreshInterval)
+	if _, err := v.Verify(ctx, rotated); err != nil {
+		t.Fatalf("expected the rotated key to be fetched, got %v", err)
+	}
+	if n := provider.fetch.Load(); n != 2 {
+		t.Fatalf("expected two key set fetches, got %d", n)
+	}
+}
+
+func TestVerifierKeepsKeysWithoutKeyIDApart(t *testing.T) {
+	provider := newTestProvider(t)
+	first, _ := rsa.GenerateKey(rand.Reader, 2048)
+	second, _ := rsa.GenerateKey(rand.Reader, 2048)
+	provider.keys.Store([]map[string]string{rsaJWK("", &first.PublicKey), rsaJWK("", &second.PublicKey)})
+
+	v, err := NewVerifier(Config{Issuer: provider.server.URL, Audience: "flowd"})
+	if err != nil {
+		t.Fatalf("new verifier: %v", err)
+	}
+	claims := map[string]any{"iss": provider.server.URL, "aud": "flowd", "sub": "ci", "exp": time.Now().Add(time.Hour).Unix()}
+	for name, key := range map[string]*rsa.PrivateKey{"first": first, "second": second} {
+		if _, err := v.Verify(context.Background(), signToken(t, "RS256", "", key, claims)); err != nil {
+			t.Fatalf("verify token of the %s unnamed key: %v", name, err)
+		}
+	}
+}
+
+func TestVerifierBacksOffAfterFailedFetch(t *testing.T) {
+	provider := newTestProvider(t)
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	provider.keys.Store([]map[string]string{rsaJWK("k1", &key.PublicKey)})
+
+	v, err := NewVerifier(Config{Issuer: provider.server.URL, Audience: "flowd"})
+	if err != nil {
+		t.Fatalf("new verifier: %v", err)
+	}
+	now := time.Unix(1_800_000_000, 0)
+	v.now = func() time.Time { return now }
+	token := signToken(t, "RS256", "k1", key, map[string]any{"iss": provider.server.URL, "aud": "flowd", "sub": "ci", "exp": now.Add(2 * DefaultKeysTTL).Unix()})
+	ctx := context.Background()
+	if _, err := v.Verify(ctx, token); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	provider.fail.Store(true)
+	now = now.Add(DefaultKeysTTL)
+	// The failed refresh falls back to the last good keys and is not
+	// retried until the backoff has passed.
+	for range 3 {
+		if _, err := v.Verify(ctx, token); err != nil {
+			t.Fatalf("expected the last good keys to be used, got %v", err)
+		}
+	}
+	if n := provider.fetch.Load(); n != 2 {
+		t.Fatalf("expected one failed refresh, got %d fetches", n)
+	}
+	provider.fail.Store(false)
+	now = now.Add(refreshBackoff)
+	if _, err := v.Verify(ctx, token); err != nil {
+		t.Fatalf("verify after backoff: %v", err)
+	}
+	if n := provider.fetch.Load(); n != 3 {
+		t.Fatalf("expected a retry once the backoff passed, got %d fetches", n)
+	}
+}
+
+func TestVerifierFetchesKeysOutsideTheLock(t *testing.T) {
+	provider := newTestProvider(t)
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	provider.keys.Store([]map[string]string{rsaJWK("old", &oldKey.PublicKey)})
+
+	v, err := NewVerifier(Config{Issuer: provider.server.URL, Audience: "flowd"})
+	if err != nil {
+		t.Fatalf("new verifier: %v", err)
+	}
+	now := time.Unix(1_800_000_000, 0)
+	v.now = func() time.Time { return now }
+	claims := map[string]any{"iss": provider.server.URL, "aud": "flowd", "sub": "ci", "exp": now.Add(time.Hour).Unix()}
+	ctx := context.Background()
+	known := signToken(t, "RS256", "old", oldKey, claims)
+	if _, err := v.Verify(ctx, known); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	provider.keys.Store([]map[string]string{rsaJWK("old", &oldKey.PublicKey), rsaJWK("new", &newKey.PublicKey)})
+	hold := make(chan struct{})
+	provider.hold.Store(&hold)
+	now = now.Add(minRefreshInterval)
+	rotated := signToken(t, "RS256", "new", newKey, claims)
+	errs := make(chan error, 4)
+	for range cap(errs) {
+		go func() {
+			_, err := v.Verify(ctx, rotated)
+			errs <- err
+		}()
+	}
+	for provider.fetch.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	// Tokens of cached keys verify while the fetch is stalled.
+	if _, err := v.Verify(ctx, known); err != nil {
+		t.Fatalf("verify with a cached key during a fetch: %v", err)
+	}
+	close(hold)
+	for range cap(errs) {
+		if err := <-errs; err != nil {
+			t.Fatalf("verify with the rotated key: %v", err)
+		}
+	}
+	if n := provider.fetch.Load(); n != 2 {
+		t.Fatalf("expected concurrent refreshes to share one fetch, got %d fetches", n)
+	}
+}
+
+func TestNewVerifierValidatesConfig(t *testing.T) {
+	for _, cfg := range []Config{
+		{Issuer: "", Audience: "flowd"},
+		{Issuer: "issuer.example", Audience: "flowd"},
+		{Issuer: "https://issuer.example"},
+		{Issuer: "https://issuer.example", Audience: "flowd", ClockSkew: -time.Second},
+	} {
+		if _, err := NewVerifier(cfg); err == nil {
+			t.Fatalf("expected %+v to be rejected", cfg)
+		}
+	}
+}
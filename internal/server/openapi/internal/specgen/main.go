@@ -0,0 +1,458 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Command specgen generates the OpenAPI 3.1 document of the serve-mode API
+// embedded by package openapi. Payload schemas are derived from the Go
+// client's types, the same ones the TypeScript client is generated from;
+// paths, parameters and responses are declared here. Run it with go generate
+// in ./internal/server/openapi.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/client"
+	"github.com/flowd-org/flowd/internal/coredb"
+	"github.com/flowd-org/flowd/internal/server/handlers"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// openAPIVersion is the OpenAPI specification version of the document.
+const openAPIVersion = "3.1.0"
+
+// schemaTypes are the payloads published under components/schemas, named
+// after their Go type.
+var schemaTypes = []any{
+	client.Run{},
+	client.RunnerInfo{},
+	client.ArgWarning{},
+	client.SourceRef{},
+	client.RunRequest{},
+	client.BatchResult{},
+	client.PlanRequest{},
+	client.Plan{},
+	client.Source{},
+	client.TrustChange{},
+	client.Quarantine{},
+	client.SourceQuarantine{},
+	client.SourceRequest{},
+	handlers.JobFlakiness{},
+	handlers.RuntimeHealth{},
+	coredb.StorageStats{},
+	types.RunArtifact{},
+}
+
+func main() {
+	out := flag.String("out", "openapi.json", "Output file")
+	flag.Parse()
+	data, err := generate()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "specgen:", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "specgen:", err)
+		os.Exit(1)
+	}
+}
+
+// generate returns the document as indented JSON.
+func generate() ([]byte, error) {
+	data, err := json.MarshalIndent(document(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func document() map[string]any {
+	schemas := map[string]any{}
+	known := map[reflect.Type]bool{}
+	for _, v := range schemaTypes {
+		known[reflect.TypeOf(v)] = true
+	}
+	for _, v := range schemaTypes {
+		t := reflect.TypeOf(v)
+		schemas[t.Name()] = objectSchema(t, known)
+	}
+	for name, schema := range declaredSchemas {
+		schemas[name] = schema
+	}
+	return map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":       "flowd serve-mode API",
+			"version":     client.APIVersion,
+			"description": "REST API of flwd :serve. Errors are RFC 7807 problem documents.",
+			"license":     map[string]any{"name": "AGPL-3.0-or-later", "identifier": "AGPL-3.0-or-later"},
+		},
+		"security": []any{map[string]any{"bearerAuth": []any{}}},
+		"paths":    paths(),
+		"components": map[string]any{
+			"schemas":    schemas,
+			"parameters": parameters,
+			"headers":    responseHeaders,
+			"responses":  problemResponses,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+}
+
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// objectSchema describes a struct. Fields without omitempty are required;
+// pointers among them may be null.
+func objectSchema(t reflect.Type, known map[reflect.Type]bool) map[string]any {
+	props := map[string]any{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		schema := typeSchema(field.Type, known)
+		if strings.Contains(opts, "omitempty") {
+			props[name] = schema
+			continue
+		}
+		required = append(required, name)
+		if field.Type.Kind() == reflect.Pointer {
+			schema = map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+		}
+		props[name] = schema
+	}
+	out := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		sort.Strings(required)
+		out["required"] = required
+	}
+	return out
+}
+
+func typeSchema(t reflect.Type, known map[reflect.Type]bool) map[string]any {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), known)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{} // json.RawMessage
+		}
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), known)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), known)}
+	case reflect.Struct:
+		if known[t] {
+			return ref(t.Name())
+		}
+		return objectSchema(t, known)
+	}
+	// Interfaces accept any JSON value.
+	return map[string]any{}
+}
+
+// declaredSchemas are payloads without a client type.
+var declaredSchemas = map[string]any{
+	"Problem": map[string]any{
+		"type":        "object",
+		"description": "RFC 7807 problem details. Extension members such as code carry machine-readable detail.",
+		"properties": map[string]any{
+			"type":     map[string]any{"type": "string", "format": "uri-reference"},
+			"title":    map[string]any{"type": "string"},
+			"status":   map[string]any{"type": "integer"},
+			"detail":   map[string]any{"type": "string"},
+			"instance": map[string]any{"type": "string"},
+			"code":     map[string]any{"type": "string"},
+		},
+		"required":             []string{"title", "status"},
+		"additionalProperties": true,
+	},
+	"Job": map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":           map[string]any{"type": "string"},
+			"name":         map[string]any{"type": "string"},
+			"description":  map[string]any{"type": "string"},
+			"args":         map[string]any{"type": "array", "items": map[string]any{}},
+			"extends":      map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"source":       map[string]any{"type": "object", "properties": map[string]any{"name": map[string]any{"type": "string"}, "type": map[string]any{"type": "string"}}},
+			"alias_of":     map[string]any{"type": "string"},
+			"alias_detail": map[string]any{"type": "string"},
+			"flakiness":    ref("JobFlakiness"),
+		},
+		"required": []string{"id", "name"},
+	},
+	"BatchRequest": map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"runs": map[string]any{"type": "array", "items": ref("RunRequest")}},
+		"required":   []string{"runs"},
+	},
+	"BatchResponse": map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"results": map[string]any{"type": "array", "items": ref("BatchResult")}},
+		"required":   []string{"results"},
+	},
+}
+
+var parameters = map[string]any{
+	"RunID":      pathParam("id", "Run ID."),
+	"SourceName": pathParam("name", "Source name."),
+	"Page":       queryParam("page", "1-based page number.", map[string]any{"type": "integer", "minimum": 1}),
+	"PerPage":    queryParam("per_page", "Page size, bounded by the server's maximum.", map[string]any{"type": "integer", "minimum": 1}),
+	"IdempotencyKey": map[string]any{
+		"name":        "Idempotency-Key",
+		"in":          "header",
+		"required":    true,
+		"description": "20-128 characters of [A-Za-z0-9_-]. Repeating a request with the same key and body returns the original response.",
+		"schema":      map[string]any{"type": "string", "pattern": "^[A-Za-z0-9_-]{20,128}$"},
+	},
+}
+
+var responseHeaders = map[string]any{
+	"IdempotentReplay": map[string]any{
+		"description": "true when the response was replayed from an earlier request with the same Idempotency-Key.",
+		"schema":      map[string]any{"type": "string", "enum": []string{"true"}},
+	},
+	"TotalCount": map[string]any{
+		"description": "Items in the full listing.",
+		"schema":      map[string]any{"type": "integer"},
+	},
+	"RemainingCount": map[string]any{
+		"description": "Items after the returned page.",
+		"schema":      map[string]any{"type": "integer"},
+	},
+}
+
+// problemStatus names the shared problem responses.
+var problemStatus = map[string]int{
+	"BadRequest": 400, "Unauthorized": 401, "Forbidden": 403, "NotFound": 404,
+	"MethodNotAllowed": 405, "Conflict": 409, "UnprocessableEntity": 422,
+	"TooManyRequests": 429, "InternalError": 500, "ServiceUnavailable": 503,
+}
+
+var problemResponses = func() map[string]any {
+	out := map[string]any{}
+	for name, status := range problemStatus {
+		out[name] = map[string]any{
+			"description": fmt.Sprintf("%d %s", status, http.StatusText(status)),
+			"content":     map[string]any{"application/problem+json": map[string]any{"schema": ref("Problem")}},
+		}
+	}
+	return out
+}()
+
+func pathParam(name, description string) map[string]any {
+	return map[string]any{"name": name, "in": "path", "required": true, "description": description, "schema": map[string]any{"type": "string"}}
+}
+
+func queryParam(name, description string, schema map[string]any) map[string]any {
+	return map[string]any{"name": name, "in": "query", "description": description, "schema": schema}
+}
+
+func paramRef(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/parameters/" + name}
+}
+
+func jsonBody(schema map[string]any) map[string]any {
+	return map[string]any{"required": true, "content": map[string]any{"application/json": map[string]any{"schema": schema}}}
+}
+
+func jsonResponse(description string, schema map[string]any) map[string]any {
+	return map[string]any{"description": description, "content": map[string]any{"application/json": map[string]any{"schema": schema}}}
+}
+
+func arrayOf(schema map[string]any) map[string]any {
+	return map[string]any{"type": "array", "items": schema}
+}
+
+// operation builds an operation whose listed problem statuses refer to the
+// shared problem responses.
+func operation(id, summary string, responses map[string]any, problems ...string) map[string]any {
+	for _, name := range problems {
+		responses[strconv.Itoa(problemStatus[name])] = map[string]any{"$ref": "#/components/responses/" + name}
+	}
+	return map[string]any{"operationId": id, "summary": summary, "responses": responses}
+}
+
+func with(op map[string]any, key string, value any) map[string]any {
+	op[key] = value
+	return op
+}
+
+func paths() map[string]any {
+	pageHeaders := map[string]any{
+		"X-Total-Count":     map[string]any{"$ref": "#/components/headers/TotalCount"},
+		"X-Remaining-Count": map[string]any{"$ref": "#/components/headers/RemainingCount"},
+	}
+	replayHeaders := map[string]any{
+		"Idempotent-Replay": map[string]any{"$ref": "#/components/headers/IdempotentReplay"},
+	}
+	created := jsonResponse("Run accepted, or replayed from an earlier request with the same key.", ref("Run"))
+	created["headers"] = replayHeaders
+	listRuns := jsonResponse("A page of runs.", arrayOf(ref("Run")))
+	listRuns["headers"] = pageHeaders
+	listJobs := jsonResponse("A page of jobs.", arrayOf(ref("Job")))
+	listJobs["headers"] = pageHeaders
+	batch := jsonResponse("Every item was accepted.", ref("BatchResponse"))
+	batch["headers"] = replayHeaders
+
+	return map[string]any{
+		"/runs": map[string]any{
+			"get": with(operation("listRuns", "List runs", map[string]any{"200": listRuns}, "BadRequest", "Unauthorized", "Forbidden"),
+				"parameters", []any{
+					paramRef("Page"), paramRef("PerPage"),
+					queryParam("sort", "Comma-separated fields, each optionally prefixed with - for descending order.", map[string]any{"type": "string"}),
+					queryParam("job_id", "Only runs of this job.", map[string]any{"type": "string"}),
+					queryParam("status", "Only runs with this status.", map[string]any{"type": "string"}),
+					with(queryParam("label", "key:value label selector; repeat to require several.", arrayOf(map[string]any{"type": "string"})), "explode", true),
+					queryParam("fields", "Comma-separated fields to return.", map[string]any{"type": "string"}),
+				}),
+			"post": with(with(operation("createRun", "Start a run", map[string]any{"201": created},
+				"BadRequest", "Unauthorized", "Forbidden", "NotFound", "Conflict", "UnprocessableEntity", "ServiceUnavailable"),
+				"parameters", []any{paramRef("IdempotencyKey")}),
+				"requestBody", jsonBody(ref("RunRequest"))),
+		},
+		"/runs:batch": map[string]any{
+			"post": with(with(operation("createRunBatch", "Start several runs atomically", map[string]any{"201": batch},
+				"BadRequest", "Unauthorized", "Forbidden", "Conflict", "UnprocessableEntity"),
+				"parameters", []any{paramRef("IdempotencyKey")}),
+				"requestBody", jsonBody(ref("BatchRequest"))),
+		},
+		"/runs:cancel": map[string]any{
+			"post": with(operation("cancelRuns", "Cancel every matching non-terminal run", map[string]any{
+				"200": jsonResponse("Runs canceled.", map[string]any{"type": "object", "properties": map[string]any{
+					"matched": map[string]any{"type": "integer"}, "canceled": map[string]any{"type": "integer"}, "run_ids": arrayOf(map[string]any{"type": "string"}),
+				}}),
+			}, "BadRequest", "Unauthorized", "Forbidden"),
+				"requestBody", jsonBody(map[string]any{"type": "object", "properties": map[string]any{
+					"job_id": map[string]any{"type": "string"}, "label": map[string]any{"type": "string"},
+					"status": map[string]any{"type": "string"}, "started_before": map[string]any{"type": "string", "format": "date-time"},
+				}})),
+		},
+		"/runs:compare": map[string]any{
+			"get": with(operation("compareRuns", "Compare the steps, args and images of two runs", map[string]any{
+				"200": jsonResponse("Comparison of run a and run b.", map[string]any{"type": "object"}),
+			}, "BadRequest", "Unauthorized", "Forbidden", "NotFound"),
+				"parameters", []any{with(queryParam("ids", "Two comma-separated run IDs.", map[string]any{"type": "string"}), "required", true)}),
+		},
+		"/runs/{id}": map[string]any{
+			"parameters": []any{paramRef("RunID")},
+			"get":        operation("getRun", "Get a run", map[string]any{"200": jsonResponse("The run.", ref("Run"))}, "Unauthorized", "Forbidden", "NotFound"),
+		},
+		"/runs/{id}:cancel": map[string]any{
+			"parameters": []any{paramRef("RunID")},
+			"post":       operation("cancelRun", "Cancel a run", map[string]any{"200": jsonResponse("The canceled run.", ref("Run"))}, "Unauthorized", "Forbidden", "NotFound", "Conflict"),
+		},
+		"/runs/{id}/events": map[string]any{
+			"parameters": []any{paramRef("RunID")},
+			"get": operation("streamRunEvents", "Stream run events", map[string]any{
+				"200": map[string]any{"description": "Server-sent events; resume with Last-Event-ID.", "content": map[string]any{"text/event-stream": map[string]any{"schema": map[string]any{"type": "string"}}}},
+			}, "Unauthorized", "Forbidden", "NotFound", "TooManyRequests"),
+		},
+		"/runs/{id}/provenance": map[string]any{
+			"parameters": []any{paramRef("RunID")},
+			"get": with(operation("getRunProvenance", "Get a run's provenance", map[string]any{
+				"200": jsonResponse("The stored provenance map, or an in-toto Statement with format=intoto.", map[string]any{"type": "object"}),
+			}, "BadRequest", "Unauthorized", "Forbidden", "NotFound"),
+				"parameters", []any{queryParam("format", "flowd (default) or intoto.", map[string]any{"type": "string", "enum": []string{"flowd", "intoto"}})}),
+		},
+		"/runs/{id}/timeline": map[string]any{
+			"parameters": []any{paramRef("RunID")},
+			"get": operation("getRunTimeline", "Get a run's timeline", map[string]any{
+				"200": jsonResponse("Timestamped spans of the run's phases.", map[string]any{"type": "object"}),
+			}, "Unauthorized", "Forbidden", "NotFound"),
+		},
+		"/runs/{id}/artifacts": map[string]any{
+			"parameters": []any{paramRef("RunID")},
+			"get": operation("listRunArtifacts", "List the files a run published", map[string]any{
+				"200": jsonResponse("Artifacts indexed when the run finished.", map[string]any{"type": "object", "properties": map[string]any{
+					"run_id": map[string]any{"type": "string"}, "artifacts": arrayOf(ref("RunArtifact")),
+				}, "required": []string{"run_id", "artifacts"}}),
+			}, "Unauthorized", "Forbidden", "NotFound"),
+		},
+		"/runs/{id}/artifacts/{name}": map[string]any{
+			"parameters": []any{paramRef("RunID"), pathParam("name", "Artifact name; may contain slashes.")},
+			"get": operation("downloadRunArtifact", "Download a run artifact", map[string]any{
+				"200": map[string]any{"description": "The artifact, with a detected content type; supports Range requests.", "content": map[string]any{"application/octet-stream": map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}}}},
+			}, "Unauthorized", "Forbidden", "NotFound", "Conflict"),
+		},
+		"/plans": map[string]any{
+			"post": with(operation("createPlan", "Preview a run without starting it", map[string]any{"200": jsonResponse("The plan.", ref("Plan"))},
+				"BadRequest", "Unauthorized", "Forbidden", "NotFound", "UnprocessableEntity"),
+				"requestBody", jsonBody(ref("PlanRequest"))),
+		},
+		"/jobs": map[string]any{
+			"get": with(operation("listJobs", "List jobs", map[string]any{"200": listJobs}, "BadRequest", "Unauthorized", "Forbidden"),
+				"parameters", []any{paramRef("Page"), paramRef("PerPage"),
+					queryParam("sort", "id or name, optionally prefixed with -.", map[string]any{"type": "string"})}),
+		},
+		"/sources": map[string]any{
+			"get": operation("listSources", "List sources", map[string]any{"200": jsonResponse("Registered sources.", arrayOf(ref("Source")))}, "Unauthorized", "Forbidden"),
+			"post": with(with(operation("addSource", "Register or update a source", map[string]any{"201": jsonResponse("Source registered.", ref("Source")), "200": jsonResponse("Source updated.", ref("Source"))}, "BadRequest", "Unauthorized", "Forbidden", "Conflict", "UnprocessableEntity"),
+				"parameters", []any{queryParam("dry_run", "For oci sources, preview the manifest and alias imports with status 200 without adding the source.", map[string]any{"type": "boolean"})}),
+				"requestBody", jsonBody(ref("SourceRequest"))),
+		},
+		"/sources/{name}": map[string]any{
+			"parameters": []any{paramRef("SourceName")},
+			"get":        operation("getSource", "Get a source", map[string]any{"200": jsonResponse("The source.", ref("Source"))}, "Unauthorized", "Forbidden", "NotFound"),
+			"delete":     operation("deleteSource", "Remove a source", map[string]any{"204": map[string]any{"description": "Source removed."}}, "Unauthorized", "Forbidden", "NotFound"),
+		},
+		"/sources/{name}:trust": map[string]any{
+			"parameters": []any{paramRef("SourceName")},
+			"post": with(operation("setSourceTrust", "Change a source's trust level", map[string]any{"200": jsonResponse("The source.", ref("Source"))}, "BadRequest", "Unauthorized", "Forbidden", "NotFound"),
+				"requestBody", jsonBody(map[string]any{"type": "object", "properties": map[string]any{
+					"trust_level": map[string]any{"type": "string", "enum": []string{"untrusted", "limited", "trusted"}}, "reason": map[string]any{"type": "string"},
+				}, "required": []string{"trust_level"}})),
+		},
+		"/sources/{name}:quarantine": map[string]any{
+			"parameters": []any{paramRef("SourceName")},
+			"post": with(operation("quarantineSource", "Quarantine a source", map[string]any{"200": jsonResponse("The quarantined source and affected runs.", ref("SourceQuarantine"))}, "BadRequest", "Unauthorized", "Forbidden", "NotFound"),
+				"requestBody", jsonBody(map[string]any{"type": "object", "properties": map[string]any{
+					"reason": map[string]any{"type": "string"}, "cancel_runs": map[string]any{"type": "boolean"},
+				}, "required": []string{"reason"}})),
+		},
+		"/sources/{name}:release": map[string]any{
+			"parameters": []any{paramRef("SourceName")},
+			"post": with(operation("releaseSource", "Lift a source's quarantine", map[string]any{"200": jsonResponse("The source.", ref("Source"))}, "BadRequest", "Unauthorized", "Forbidden", "NotFound"),
+				"requestBody", jsonBody(map[string]any{"type": "object", "properties": map[string]any{"reason": map[string]any{"type": "string"}}})),
+		},
+		"/healthz": map[string]any{
+			"get": operation("healthz", "Liveness probe", map[string]any{"204": map[string]any{"description": "The server is up."}}, "Unauthorized"),
+		},
+		"/health/storage": map[string]any{
+			"get": operation("getStorageHealth", "Core DB storage health", map[string]any{"200": jsonResponse("Storage is healthy.", ref("StorageStats"))}, "Unauthorized", "Forbidden", "ServiceUnavailable"),
+		},
+		"/health/runtime": map[string]any{
+			"get": operation("getRuntimeHealth", "Container runtime health", map[string]any{"200": jsonResponse("The runtime answered.", ref("RuntimeHealth"))}, "Unauthorized", "Forbidden", "ServiceUnavailable"),
+		},
+		"/openapi.json": map[string]any{
+			"get": operation("getOpenAPI", "This document", map[string]any{"200": jsonResponse("The OpenAPI document.", map[string]any{"type": "object"})}, "Unauthorized"),
+		},
+	}
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestGeneratedDocumentUpToDate(t *testing.T) {
+	want, err := generate()
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	got, err := os.ReadFile("../../openapi.json")
+	if err != nil {
+		t.Fatalf("read generated document: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("internal/server/openapi/openapi.json is stale; run go generate ./internal/server/openapi")
+	}
+}
+
+func TestDocumentReferencesResolve(t *testing.T) {
+	data, err := generate()
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	components := doc["components"].(map[string]any)
+	for _, m := range regexp.MustCompile(`"#/components/(\w+)/(\w+)"`).FindAllStringSubmatch(string(data), -1) {
+		section, _ := components[m[1]].(map[string]any)
+		if _, ok := section[m[2]]; !ok {
+			t.Errorf("unresolved reference %s", m[0])
+		}
+	}
+	paths := doc["paths"].(map[string]any)
+	for _, path := range []string{"/runs", "/runs/{id}", "/plans", "/jobs", "/sources", "/sources/{name}", "/healthz", "/health/storage", "/openapi.json"} {
+		if _, ok := paths[path]; !ok {
+			t.Errorf("missing path %s", path)
+		}
+	}
+}
+
+func TestDocumentDescribesRunCreation(t *testing.T) {
+	doc := document()
+	post := doc["paths"].(map[string]any)["/runs"].(map[string]any)["post"].(map[string]any)
+	params := post["parameters"].([]any)
+	if len(params) != 1 || params[0].(map[string]any)["$ref"] != "#/components/parameters/IdempotencyKey" {
+		t.Fatalf("expected Idempotency-Key parameter, got %v", params)
+	}
+	responses := post["responses"].(map[string]any)
+	if _, ok := responses["201"].(map[string]any)["headers"].(map[string]any)["Idempotent-Replay"]; !ok {
+		t.Fatalf("expected Idempotent-Replay header on 201, got %v", responses["201"])
+	}
+	if responses["409"].(map[string]any)["$ref"] != "#/components/responses/Conflict" {
+		t.Fatalf("expected problem response for 409, got %v", responses["409"])
+	}
+
+	run := doc["components"].(map[string]any)["schemas"].(map[string]any)["Run"].(map[string]any)
+	if got := run["required"].([]string); !slices.Equal(got, []string{"id", "job_id", "started_at", "status"}) {
+		t.Fatalf("unexpected required run fields %v", got)
+	}
+	props := run["properties"].(map[string]any)
+	if props["started_at"].(map[string]any)["format"] != "date-time" || props["runner"].(map[string]any)["$ref"] != "#/components/schemas/RunnerInfo" {
+		t.Fatalf("unexpected run properties %v", props)
+	}
+}
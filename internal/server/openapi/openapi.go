@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package openapi serves the OpenAPI 3.1 document of the serve-mode API. The
+// document is generated from the Go client's types by internal/specgen.
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/flowd-org/flowd/internal/server/response"
+)
+
+//go:generate go run ./internal/specgen -out openapi.json
+
+//go:embed openapi.json
+var document []byte
+
+// JSON returns the OpenAPI document.
+func JSON() []byte {
+	return document
+}
+
+// Handler serves the document at GET /openapi.json.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			response.Write(w, response.New(http.StatusMethodNotAllowed, "method not allowed"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		_, _ = w.Write(document)
+	})
+}
@@ -0,0 +1,102 @@
+package pipelinestore
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Promotion statuses.
+const (
+	StatusPendingApproval = "pending_approval"
+	StatusRunning         = "running"
+	StatusSucceeded       = "succeeded"
+	StatusFailed          = "failed"
+	StatusRejected        = "rejected"
+)
+
+// Promotion records one attempt to move a version into a pipeline stage.
+type Promotion struct {
+	ID          string       `json:"id"`
+	PipelineID  string       `json:"pipeline_id"`
+	Stage       string       `json:"stage"`
+	Version     string       `json:"version"`
+	Status      string       `json:"status"`
+	RunID       string       `json:"run_id,omitempty"`
+	RequestedBy string       `json:"requested_by,omitempty"`
+	Approvals   []Approval   `json:"approvals,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	Audit       []AuditEntry `json:"audit"`
+}
+
+// Approval is a single principal's sign-off on a promotion.
+type Approval struct {
+	Principal string    `json:"principal"`
+	At        time.Time `json:"at"`
+}
+
+// AuditEntry is one step in a promotion's history.
+type AuditEntry struct {
+	At     time.Time `json:"at"`
+	Action string    `json:"action"`
+	Actor  string    `json:"actor,omitempty"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// Active reports whether the promotion may still change stage state.
+func (p Promotion) Active() bool {
+	return p.Status == StatusPendingApproval || p.Status == StatusRunning
+}
+
+// Store keeps promotions in memory for serve mode.
+type Store struct {
+	mu         sync.RWMutex
+	promotions map[string]Promotion
+}
+
+// New returns an empty promotion store.
+func New() *Store {
+	return &Store{
+		promotions: make(map[string]Promotion),
+	}
+}
+
+// Create inserts or replaces a promotion.
+func (s *Store) Create(p Promotion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.promotions[p.ID] = p
+}
+
+// Update replaces the stored promotion.
+func (s *Store) Update(p Promotion) {
+	s.Create(p)
+}
+
+// Get retrieves a promotion by ID.
+func (s *Store) Get(id string) (Promotion, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.promotions[id]
+	return p, ok
+}
+
+// List returns a pipeline's promotions sorted by CreatedAt ascending.
+func (s *Store) List(pipelineID string) []Promotion {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]Promotion, 0)
+	for _, p := range s.promotions {
+		if p.PipelineID == pipelineID {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	return out
+}
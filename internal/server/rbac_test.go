@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/policy"
+	"github.com/flowd-org/flowd/internal/server/authz"
+)
+
+func signedTestToken(t *testing.T, secret string, claims map[string]any) string {
+	t.Helper()
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("encode claims: %v", err)
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(computeHMAC(unsigned, secret))
+}
+
+func TestRBACConfinesTenantsToTheirJobsAndRuns(t *testing.T) {
+	t.Setenv("FLWD_JWT_SECRET", "rbac-secret")
+	root := t.TempDir()
+	for _, id := range []string{"alpha.deploy", "beta.deploy"} {
+		dir := filepath.Join(root, id, "config.d")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		config := "version: v1\njob:\n  id: " + id + "\n  name: Deploy\ninterpreter: bash\n"
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0o644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+	}
+	rbac := &authz.Policy{Bindings: []authz.Binding{
+		{Principals: []string{"tenant-a"}, Roles: []string{authz.RoleOperator}, Jobs: []string{"alpha.*"}},
+		{Principals: []string{"tenant-b"}, Roles: []string{authz.RoleOperator}, Jobs: []string{"beta.*"}},
+	}}
+	cfg := Config{Bind: "127.0.0.1:0", Profile: "secure", DataDir: t.TempDir(), ScriptsRoot: root, RBAC: rbac}
+	cfg = cfg.normalize()
+	policyCtx, err := policy.NewContext(nil)
+	if err != nil {
+		t.Fatalf("policy context: %v", err)
+	}
+	handler, closeHandler := buildHandler(cfg, policyCtx, nil)
+	defer closeHandler()
+
+	tokenA := signedTestToken(t, "rbac-secret", map[string]any{"sub": "tenant-a"})
+	tokenB := signedTestToken(t, "rbac-secret", map[string]any{"sub": "tenant-b"})
+	keys := 0
+	call := func(token, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+			keys++
+			req.Header.Set("Idempotency-Key", strings.Repeat(string(rune('a'+keys)), 20))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	startAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	rec := call(tokenA, http.MethodPost, "/runs", `{"job_id":"alpha.deploy","start_at":"`+startAt+`"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected tenant-a to start its job, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var run struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &run); err != nil || run.ID == "" {
+		t.Fatalf("decode run: %v %s", err, rec.Body.String())
+	}
+	if rec := call(tokenA, http.MethodPost, "/runs", `{"job_id":"beta.deploy","start_at":"`+startAt+`"}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected tenant-a to be refused tenant-b's job, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := call(tokenB, http.MethodGet, "/runs", ""); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), run.ID) {
+		t.Fatalf("expected tenant-b's run list to omit tenant-a's run, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := call(tokenB, http.MethodGet, "/runs/"+run.ID, ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected tenant-b not to see tenant-a's run, got %d", rec.Code)
+	}
+	if rec := call(tokenB, http.MethodPost, "/runs/"+run.ID+":cancel", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected tenant-b not to cancel tenant-a's run, got %d", rec.Code)
+	}
+	rec = call(tokenB, http.MethodGet, "/jobs", "")
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "alpha.deploy") || !strings.Contains(rec.Body.String(), "beta.deploy") {
+		t.Fatalf("expected tenant-b to list only its jobs, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := call(tokenA, http.MethodGet, "/runs/"+run.ID, ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected tenant-a to see its run, got %d", rec.Code)
+	}
+	if rec := call(tokenA, http.MethodPost, "/runs/"+run.ID+":cancel", ""); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected tenant-a to cancel its run, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := call(tokenA, http.MethodGet, "/admin/settings", ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected the operator role to lack admin:read, got %d", rec.Code)
+	}
+}
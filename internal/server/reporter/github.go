@@ -0,0 +1,242 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package reporter
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultGitHubAPIURL is the public GitHub REST endpoint.
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+// GitHubConfig configures a GitHub App installation used to publish checks.
+type GitHubConfig struct {
+	APIURL         string
+	AppID          int64
+	InstallationID int64
+	// PrivateKey is the App's PEM-encoded RSA private key.
+	PrivateKey []byte
+	Client     *http.Client
+	Now        func() time.Time
+}
+
+// GitHubChecks reports runs through the GitHub Checks API, creating one check
+// run per flowd run and updating it as the run progresses.
+type GitHubChecks struct {
+	apiURL         string
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	client         *http.Client
+	now            func() time.Time
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+	checkRuns   map[string]int64
+}
+
+// NewGitHubChecks validates cfg and parses the App private key.
+func NewGitHubChecks(cfg GitHubConfig) (*GitHubChecks, error) {
+	if cfg.AppID <= 0 || cfg.InstallationID <= 0 {
+		return nil, errors.New("github checks require app_id and installation_id")
+	}
+	key, err := parseRSAPrivateKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	apiURL := strings.TrimRight(strings.TrimSpace(cfg.APIURL), "/")
+	if apiURL == "" {
+		apiURL = DefaultGitHubAPIURL
+	}
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &GitHubChecks{
+		apiURL:         apiURL,
+		appID:          cfg.AppID,
+		installationID: cfg.InstallationID,
+		key:            key,
+		client:         defaultClient(cfg.Client),
+		now:            now,
+		checkRuns:      make(map[string]int64),
+	}, nil
+}
+
+type checkRunOutput struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+}
+
+type checkRunRequest struct {
+	Name        string          `json:"name,omitempty"`
+	HeadSHA     string          `json:"head_sha,omitempty"`
+	ExternalID  string          `json:"external_id,omitempty"`
+	DetailsURL  string          `json:"details_url,omitempty"`
+	Status      string          `json:"status"`
+	Conclusion  string          `json:"conclusion,omitempty"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	Output      *checkRunOutput `json:"output,omitempty"`
+}
+
+// Report creates the check run on the first call for st.RunID and updates it
+// afterwards. The completed state forgets the check run.
+func (g *GitHubChecks) Report(ctx context.Context, st Status) error {
+	if st.Repo == "" || st.CommitSHA == "" {
+		return errors.New("github checks require a repository and commit")
+	}
+	body := checkRunRequest{
+		DetailsURL: st.DetailsURL,
+		Status:     st.State,
+		Output:     &checkRunOutput{Title: Title(st), Summary: Summary(st)},
+	}
+	if !st.StartedAt.IsZero() && st.State != StateQueued {
+		started := st.StartedAt.UTC()
+		body.StartedAt = &started
+	}
+	if st.State == StateCompleted {
+		body.Conclusion = st.Conclusion
+		completed := st.CompletedAt
+		if completed.IsZero() {
+			completed = g.now()
+		}
+		completed = completed.UTC()
+		body.CompletedAt = &completed
+	}
+
+	g.mu.Lock()
+	id, exists := g.checkRuns[st.RunID]
+	g.mu.Unlock()
+
+	method := http.MethodPatch
+	path := fmt.Sprintf("/repos/%s/check-runs/%d", st.Repo, id)
+	if !exists {
+		method = http.MethodPost
+		path = fmt.Sprintf("/repos/%s/check-runs", st.Repo)
+		body.Name = Name(st.JobID)
+		body.HeadSHA = st.CommitSHA
+		body.ExternalID = st.RunID
+	}
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	if err := g.do(ctx, method, path, body, &created); err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if st.State == StateCompleted {
+		delete(g.checkRuns, st.RunID)
+	} else if !exists && created.ID != 0 {
+		g.checkRuns[st.RunID] = created.ID
+	}
+	return nil
+}
+
+func (g *GitHubChecks) do(ctx context.Context, method, path string, body any, out any) error {
+	token, err := g.installationToken(ctx)
+	if err != nil {
+		return err
+	}
+	return g.call(ctx, method, path, "token "+token, body, out)
+}
+
+func (g *GitHubChecks) call(ctx context.Context, method, path, auth string, body any, out any) error {
+	headers := map[string]string{
+		"Accept":               "application/vnd.github+json",
+		"Authorization":        auth,
+		"X-GitHub-Api-Version": "2022-11-28",
+	}
+	return doJSON(ctx, g.client, method, g.apiURL+path, headers, body, out)
+}
+
+// installationToken exchanges an App JWT for an installation access token,
+// reusing the token until shortly before it expires.
+func (g *GitHubChecks) installationToken(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	if g.token != "" && g.now().Before(g.tokenExpiry.Add(-time.Minute)) {
+		token := g.token
+		g.mu.Unlock()
+		return token, nil
+	}
+	g.mu.Unlock()
+
+	jwt, err := g.appJWT()
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	path := fmt.Sprintf("/app/installations/%d/access_tokens", g.installationID)
+	if err := g.call(ctx, http.MethodPost, path, "Bearer "+jwt, nil, &resp); err != nil {
+		return "", err
+	}
+	if resp.Token == "" {
+		return "", errors.New("github returned an empty installation token")
+	}
+	g.mu.Lock()
+	g.token = resp.Token
+	g.tokenExpiry = resp.ExpiresAt
+	g.mu.Unlock()
+	return resp.Token, nil
+}
+
+// appJWT signs the short-lived RS256 JWT GitHub expects from Apps. The issue
+// time is backdated to tolerate clock drift.
+func (g *GitHubChecks) appJWT() (string, error) {
+	now := g.now().UTC()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": strconv.FormatInt(g.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+	signing := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(signing))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, g.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("sign app jwt: %w", err)
+	}
+	return signing + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("github app private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse github app private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("github app private key is not an RSA key")
+	}
+	return key, nil
+}
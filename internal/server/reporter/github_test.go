@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package reporter
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordedCall struct {
+	Method string
+	Path   string
+	Auth   string
+	Body   map[string]any
+}
+
+func newFakeGitHub(t *testing.T, key *rsa.PrivateKey) (*httptest.Server, func() []recordedCall) {
+	t.Helper()
+	var (
+		mu    sync.Mutex
+		calls []recordedCall
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := recordedCall{Method: r.Method, Path: r.URL.Path, Auth: r.Header.Get("Authorization")}
+		_ = json.NewDecoder(r.Body).Decode(&call.Body)
+		mu.Lock()
+		calls = append(calls, call)
+		mu.Unlock()
+		switch {
+		case r.URL.Path == "/app/installations/7/access_tokens":
+			verifyAppJWT(t, strings.TrimPrefix(call.Auth, "Bearer "), &key.PublicKey)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"token":"inst-token","expires_at":"2099-01-01T00:00:00Z"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/app/check-runs":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":42}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/acme/app/check-runs/42":
+			_, _ = w.Write([]byte(`{"id":42}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []recordedCall {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]recordedCall(nil), calls...)
+	}
+}
+
+func verifyAppJWT(t *testing.T, token string, pub *rsa.PublicKey) {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Errorf("malformed app jwt %q", token)
+		return
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Errorf("decode signature: %v", err)
+		return
+	}
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig); err != nil {
+		t.Errorf("app jwt signature invalid: %v", err)
+	}
+	claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	if !strings.Contains(string(claims), `"iss":"99"`) {
+		t.Errorf("unexpected claims %s", claims)
+	}
+}
+
+func testRSAKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return key, pemBytes
+}
+
+func TestGitHubChecksLifecycle(t *testing.T) {
+	key, pemBytes := testRSAKey(t)
+	srv, calls := newFakeGitHub(t, key)
+	checks, err := NewGitHubChecks(GitHubConfig{APIURL: srv.URL, AppID: 99, InstallationID: 7, PrivateKey: pemBytes})
+	if err != nil {
+		t.Fatalf("new github checks: %v", err)
+	}
+	base := Status{RunID: "run-1", JobID: "build", Repo: "acme/app", CommitSHA: "abc123", DetailsURL: "https://flowd.example/runs/run-1"}
+	ctx := context.Background()
+
+	queued := base
+	queued.State = StateQueued
+	if err := checks.Report(ctx, queued); err != nil {
+		t.Fatalf("report queued: %v", err)
+	}
+	running := base
+	running.State = StateInProgress
+	running.StartedAt = time.Now()
+	if err := checks.Report(ctx, running); err != nil {
+		t.Fatalf("report in_progress: %v", err)
+	}
+	done := base
+	done.State = StateCompleted
+	done.Conclusion = ConclusionFailure
+	done.Steps = []Step{{Name: "100_main.sh", Status: "failed", ExitCode: 3}}
+	if err := checks.Report(ctx, done); err != nil {
+		t.Fatalf("report completed: %v", err)
+	}
+
+	got := calls()
+	if len(got) != 4 {
+		t.Fatalf("expected token exchange plus 3 check calls, got %d: %+v", len(got), got)
+	}
+	create := got[1]
+	if create.Method != http.MethodPost || create.Auth != "token inst-token" {
+		t.Fatalf("unexpected create call %+v", create)
+	}
+	if create.Body["name"] != "flowd/build" || create.Body["head_sha"] != "abc123" || create.Body["status"] != "queued" || create.Body["external_id"] != "run-1" {
+		t.Fatalf("unexpected create body %+v", create.Body)
+	}
+	if got[2].Method != http.MethodPatch || got[2].Body["status"] != "in_progress" {
+		t.Fatalf("unexpected update call %+v", got[2])
+	}
+	final := got[3]
+	if final.Body["conclusion"] != "failure" || final.Body["completed_at"] == nil {
+		t.Fatalf("unexpected completion body %+v", final.Body)
+	}
+	output, _ := final.Body["output"].(map[string]any)
+	summary, _ := output["summary"].(string)
+	if !strings.Contains(summary, "| 100_main.sh | failed | 3 |") || !strings.Contains(summary, "https://flowd.example/runs/run-1") {
+		t.Fatalf("summary missing steps or links: %q", summary)
+	}
+}
+
+func TestNewGitHubChecksRejectsBadKey(t *testing.T) {
+	if _, err := NewGitHubChecks(GitHubConfig{AppID: 1, InstallationID: 1, PrivateKey: []byte("nope")}); err == nil {
+		t.Fatalf("expected invalid key to be rejected")
+	}
+	_, pemBytes := testRSAKey(t)
+	if _, err := NewGitHubChecks(GitHubConfig{PrivateKey: pemBytes}); err == nil {
+		t.Fatalf("expected missing app ids to be rejected")
+	}
+}
@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package reporter reports the status of webhook-triggered runs back to the
+// forge that sent the webhook.
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Run states, in the order a reporter sees them.
+const (
+	StateQueued     = "queued"
+	StateInProgress = "in_progress"
+	StateCompleted  = "completed"
+)
+
+// Conclusions of completed runs.
+const (
+	ConclusionSuccess   = "success"
+	ConclusionFailure   = "failure"
+	ConclusionCancelled = "cancelled"
+	ConclusionTimedOut  = "timed_out"
+)
+
+// Reporter publishes run status for a commit. Implementations are called
+// sequentially per run and may keep per-run state between calls.
+type Reporter interface {
+	Report(ctx context.Context, st Status) error
+}
+
+// Status describes a run at one point in its lifecycle.
+type Status struct {
+	RunID       string
+	JobID       string
+	Repo        string
+	CommitSHA   string
+	State       string
+	Conclusion  string
+	Steps       []Step
+	Error       string
+	DetailsURL  string
+	LogsURL     string
+	StartedAt   time.Time
+	CompletedAt time.Time
+}
+
+// Step summarises one finished step.
+type Step struct {
+	Name     string
+	Status   string
+	ExitCode int
+	Error    string
+}
+
+// Conclusion maps a flowd run status to a reporter conclusion.
+func Conclusion(runStatus string) string {
+	switch runStatus {
+	case "completed":
+		return ConclusionSuccess
+	case "canceled", "cancelled":
+		return ConclusionCancelled
+	case "timed_out":
+		return ConclusionTimedOut
+	default:
+		return ConclusionFailure
+	}
+}
+
+// Name is the check or status context name used for a job.
+func Name(jobID string) string {
+	return "flowd/" + jobID
+}
+
+// Title is a one-line description of st.
+func Title(st Status) string {
+	switch st.State {
+	case StateQueued:
+		return "Queued"
+	case StateInProgress:
+		return "Running"
+	}
+	switch st.Conclusion {
+	case ConclusionSuccess:
+		return "Succeeded"
+	case ConclusionCancelled:
+		return "Canceled"
+	case ConclusionTimedOut:
+		return "Timed out"
+	default:
+		return "Failed"
+	}
+}
+
+// Summary renders st as Markdown: the run, its finished steps and links to
+// its logs.
+func Summary(st Status) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Run `%s` of job `%s`", st.RunID, st.JobID)
+	if st.DetailsURL != "" {
+		fmt.Fprintf(&b, " ([details](%s)", st.DetailsURL)
+		if st.LogsURL != "" {
+			fmt.Fprintf(&b, ", [logs](%s)", st.LogsURL)
+		}
+		b.WriteString(")")
+	}
+	b.WriteString(".\n")
+	if st.Error != "" {
+		fmt.Fprintf(&b, "\n> %s\n", st.Error)
+	}
+	if len(st.Steps) > 0 {
+		b.WriteString("\n| Step | Status | Exit code |\n| --- | --- | --- |\n")
+		for _, step := range st.Steps {
+			fmt.Fprintf(&b, "| %s | %s | %d |\n", step.Name, step.Status, step.ExitCode)
+		}
+	}
+	return b.String()
+}
+
+// doJSON sends body as JSON and decodes a JSON response into out. Non-2xx
+// responses are errors carrying the response body.
+func doJSON(ctx context.Context, client *http.Client, method, url string, headers map[string]string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, url, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: %s: %s", method, url, resp.Status, strings.TrimSpace(string(data)))
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("%s %s: decode response: %w", method, url, err)
+		}
+	}
+	return nil
+}
+
+func defaultClient(client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+	return &http.Client{Timeout: 30 * time.Second}
+}
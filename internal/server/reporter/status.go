@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package reporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultGitLabAPIURL is the gitlab.com REST endpoint.
+const DefaultGitLabAPIURL = "https://gitlab.com/api/v4"
+
+// CommitStatusConfig configures a commit status reporter. Token is an access
+// token allowed to set commit statuses on the repository.
+type CommitStatusConfig struct {
+	APIURL string
+	Token  []byte
+	Client *http.Client
+}
+
+type commitStatusRequest struct {
+	State       string `json:"state"`
+	Name        string `json:"name,omitempty"`
+	Context     string `json:"context,omitempty"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// GitLabStatus reports runs as GitLab commit statuses.
+type GitLabStatus struct {
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+// NewGitLabStatus validates cfg.
+func NewGitLabStatus(cfg CommitStatusConfig) (*GitLabStatus, error) {
+	token := strings.TrimSpace(string(cfg.Token))
+	if token == "" {
+		return nil, errors.New("gitlab status requires an access token")
+	}
+	apiURL := strings.TrimRight(strings.TrimSpace(cfg.APIURL), "/")
+	if apiURL == "" {
+		apiURL = DefaultGitLabAPIURL
+	}
+	return &GitLabStatus{apiURL: apiURL, token: token, client: defaultClient(cfg.Client)}, nil
+}
+
+// Report sets the commit status of st.CommitSHA in project st.Repo.
+func (g *GitLabStatus) Report(ctx context.Context, st Status) error {
+	if st.Repo == "" || st.CommitSHA == "" {
+		return errors.New("gitlab status requires a project and commit")
+	}
+	body := commitStatusRequest{
+		State:       gitLabState(st),
+		Name:        Name(st.JobID),
+		TargetURL:   st.DetailsURL,
+		Description: Title(st),
+	}
+	endpoint := fmt.Sprintf("%s/projects/%s/statuses/%s", g.apiURL, url.PathEscape(st.Repo), url.PathEscape(st.CommitSHA))
+	return doJSON(ctx, g.client, http.MethodPost, endpoint, map[string]string{"PRIVATE-TOKEN": g.token}, body, nil)
+}
+
+func gitLabState(st Status) string {
+	switch st.State {
+	case StateQueued:
+		return "pending"
+	case StateInProgress:
+		return "running"
+	}
+	switch st.Conclusion {
+	case ConclusionSuccess:
+		return "success"
+	case ConclusionCancelled:
+		return "canceled"
+	default:
+		return "failed"
+	}
+}
+
+// GiteaStatus reports runs as Gitea commit statuses.
+type GiteaStatus struct {
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+// NewGiteaStatus validates cfg. Gitea has no public default instance, so the
+// API URL (for example https://gitea.example/api/v1) is required.
+func NewGiteaStatus(cfg CommitStatusConfig) (*GiteaStatus, error) {
+	token := strings.TrimSpace(string(cfg.Token))
+	if token == "" {
+		return nil, errors.New("gitea status requires an access token")
+	}
+	apiURL := strings.TrimRight(strings.TrimSpace(cfg.APIURL), "/")
+	if apiURL == "" {
+		return nil, errors.New("gitea status requires api_url")
+	}
+	return &GiteaStatus{apiURL: apiURL, token: token, client: defaultClient(cfg.Client)}, nil
+}
+
+// Report sets the commit status of st.CommitSHA in repository st.Repo.
+func (g *GiteaStatus) Report(ctx context.Context, st Status) error {
+	if st.Repo == "" || st.CommitSHA == "" {
+		return errors.New("gitea status requires a repository and commit")
+	}
+	body := commitStatusRequest{
+		State:       giteaState(st),
+		Context:     Name(st.JobID),
+		TargetURL:   st.DetailsURL,
+		Description: Title(st),
+	}
+	endpoint := fmt.Sprintf("%s/repos/%s/statuses/%s", g.apiURL, st.Repo, url.PathEscape(st.CommitSHA))
+	return doJSON(ctx, g.client, http.MethodPost, endpoint, map[string]string{"Authorization": "token " + g.token}, body, nil)
+}
+
+// giteaState maps st to Gitea's status states, which have no running or
+// canceled state: running runs stay pending and canceled runs are errors.
+func giteaState(st Status) string {
+	if st.State != StateCompleted {
+		return "pending"
+	}
+	switch st.Conclusion {
+	case ConclusionSuccess:
+		return "success"
+	case ConclusionCancelled:
+		return "error"
+	default:
+		return "failure"
+	}
+}
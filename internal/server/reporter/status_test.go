@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type statusCall struct {
+	Path   string
+	Header http.Header
+	Body   commitStatusRequest
+}
+
+func newStatusServer(t *testing.T, calls *[]statusCall) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := statusCall{Path: r.URL.EscapedPath(), Header: r.Header.Clone()}
+		_ = json.NewDecoder(r.Body).Decode(&call.Body)
+		*calls = append(*calls, call)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGitLabStatusStates(t *testing.T) {
+	var calls []statusCall
+	srv := newStatusServer(t, &calls)
+	rep, err := NewGitLabStatus(CommitStatusConfig{APIURL: srv.URL, Token: []byte("glpat\n")})
+	if err != nil {
+		t.Fatalf("new gitlab status: %v", err)
+	}
+	base := Status{RunID: "r1", JobID: "build", Repo: "group/app", CommitSHA: "abc", DetailsURL: "https://flowd.example/runs/r1"}
+	for _, st := range []Status{
+		withState(base, StateQueued, ""),
+		withState(base, StateInProgress, ""),
+		withState(base, StateCompleted, ConclusionCancelled),
+	} {
+		if err := rep.Report(context.Background(), st); err != nil {
+			t.Fatalf("report %s: %v", st.State, err)
+		}
+	}
+	want := []string{"pending", "running", "canceled"}
+	for i, call := range calls {
+		if call.Body.State != want[i] {
+			t.Fatalf("call %d: expected state %s, got %s", i, want[i], call.Body.State)
+		}
+	}
+	if calls[0].Path != "/projects/group%2Fapp/statuses/abc" || calls[0].Header.Get("PRIVATE-TOKEN") != "glpat" {
+		t.Fatalf("unexpected request %s %v", calls[0].Path, calls[0].Header)
+	}
+	if calls[0].Body.Name != "flowd/build" || calls[0].Body.TargetURL != base.DetailsURL {
+		t.Fatalf("unexpected body %+v", calls[0].Body)
+	}
+}
+
+func TestGiteaStatusStates(t *testing.T) {
+	var calls []statusCall
+	srv := newStatusServer(t, &calls)
+	if _, err := NewGiteaStatus(CommitStatusConfig{Token: []byte("x")}); err == nil {
+		t.Fatalf("expected missing api_url to be rejected")
+	}
+	rep, err := NewGiteaStatus(CommitStatusConfig{APIURL: srv.URL + "/api/v1/", Token: []byte("tok")})
+	if err != nil {
+		t.Fatalf("new gitea status: %v", err)
+	}
+	base := Status{RunID: "r1", JobID: "build", Repo: "org/app", CommitSHA: "abc"}
+	for _, st := range []Status{
+		withState(base, StateInProgress, ""),
+		withState(base, StateCompleted, ConclusionFailure),
+	} {
+		if err := rep.Report(context.Background(), st); err != nil {
+			t.Fatalf("report %s: %v", st.State, err)
+		}
+	}
+	if calls[0].Body.State != "pending" || calls[1].Body.State != "failure" {
+		t.Fatalf("unexpected states %+v", calls)
+	}
+	if calls[0].Path != "/api/v1/repos/org/app/statuses/abc" || calls[0].Header.Get("Authorization") != "token tok" || calls[0].Body.Context != "flowd/build" {
+		t.Fatalf("unexpected request %s %v %+v", calls[0].Path, calls[0].Header, calls[0].Body)
+	}
+}
+
+func withState(st Status, state, conclusion string) Status {
+	st.State = state
+	st.Conclusion = conclusion
+	return st
+}
@@ -3,18 +3,25 @@ package requestctx
 import (
 	"context"
 	"log/slog"
+	"sync"
 )
 
 type loggerKey struct{}
 type profileKey struct{}
 type metadataKey struct{}
 type principalKey struct{}
+type scopesKey struct{}
+type accessKey struct{}
+type auditKey struct{}
 
 var (
 	ctxLoggerKey    = &loggerKey{}
 	ctxProfileKey   = &profileKey{}
 	ctxMetadataKey  = &metadataKey{}
 	ctxPrincipalKey = &principalKey{}
+	ctxScopesKey    = &scopesKey{}
+	ctxAccessKey    = &accessKey{}
+	ctxAuditKey     = &auditKey{}
 )
 
 // Metadata stores auxiliary request attributes for structured logging.
@@ -143,8 +150,126 @@ func Principal(ctx context.Context) (string, bool) {
 	return principal, true
 }
 
-// LogPolicyDecision emits a structured policy decision log using the request-scoped logger.
+// WithScopes stores the authenticated caller's scopes on the context.
+func WithScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, ctxScopesKey, scopes)
+}
+
+// HasScope reports whether the authenticated caller holds scope. It is false
+// when the request carries no scopes at all.
+func HasScope(ctx context.Context, scope string) bool {
+	if ctx == nil {
+		return false
+	}
+	scopes, _ := ctx.Value(ctxScopesKey).([]string)
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Access confines a caller to some jobs and sources.
+type Access interface {
+	AllowsJob(id string) bool
+	AllowsSource(name string) bool
+}
+
+// WithAccess stores the authenticated caller's access rules on the context.
+func WithAccess(ctx context.Context, access Access) context.Context {
+	if access == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxAccessKey, access)
+}
+
+// AccessFromContext returns the caller's access rules; nil means the caller
+// is not confined.
+func AccessFromContext(ctx context.Context) Access {
+	if ctx == nil {
+		return nil
+	}
+	access, _ := ctx.Value(ctxAccessKey).(Access)
+	return access
+}
+
+// JobAllowed reports whether the caller may see and use job id.
+func JobAllowed(ctx context.Context, id string) bool {
+	access := AccessFromContext(ctx)
+	return access == nil || access.AllowsJob(id)
+}
+
+// SourceAllowed reports whether the caller may see and use source name.
+func SourceAllowed(ctx context.Context, name string) bool {
+	access := AccessFromContext(ctx)
+	return access == nil || access.AllowsSource(name)
+}
+
+// PolicyDecision is one policy check taken while serving a request.
+type PolicyDecision struct {
+	Subject  string `json:"subject"`
+	Decision string `json:"decision"`
+	Code     string `json:"code,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// AuditTrail gathers what the audit log records about a request as it
+// passes authentication and policy checks.
+type AuditTrail struct {
+	mu        sync.Mutex
+	principal string
+	decisions []PolicyDecision
+}
+
+// SetPrincipal records the authenticated caller.
+func (t *AuditTrail) SetPrincipal(principal string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.principal = principal
+}
+
+// Principal returns the authenticated caller, empty when the request was
+// not authenticated.
+func (t *AuditTrail) Principal() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.principal
+}
+
+// Decisions returns the policy decisions recorded so far.
+func (t *AuditTrail) Decisions() []PolicyDecision {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]PolicyDecision(nil), t.decisions...)
+}
+
+// WithAuditTrail stores the request's audit trail on the context.
+func WithAuditTrail(ctx context.Context, trail *AuditTrail) context.Context {
+	if trail == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxAuditKey, trail)
+}
+
+// AuditTrailFromContext returns the request's audit trail, nil when the
+// request is not audited.
+func AuditTrailFromContext(ctx context.Context) *AuditTrail {
+	if ctx == nil {
+		return nil
+	}
+	trail, _ := ctx.Value(ctxAuditKey).(*AuditTrail)
+	return trail
+}
+
+// LogPolicyDecision emits a structured policy decision log using the
+// request-scoped logger and adds the decision to the request's audit trail.
 func LogPolicyDecision(ctx context.Context, subject, decision, code, reason string) {
+	if trail := AuditTrailFromContext(ctx); trail != nil {
+		trail.mu.Lock()
+		trail.decisions = append(trail.decisions, PolicyDecision{Subject: subject, Decision: decision, Code: code, Reason: reason})
+		trail.mu.Unlock()
+	}
 	logger := Logger(ctx)
 	if logger == nil {
 		return
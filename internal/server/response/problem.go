@@ -64,6 +64,17 @@ func New(status int, title string, opts ...Option) Problem {
 
 // Write serializes and writes the problem response with appropriate headers.
 func Write(w http.ResponseWriter, p Problem) {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p.Body())
+}
+
+// Body returns the RFC7807 JSON object for the problem, for embedding problems
+// inside other response documents.
+func (p Problem) Body() map[string]any {
 	if p.Status == 0 {
 		p.Status = http.StatusInternalServerError
 	}
@@ -86,7 +97,5 @@ func Write(w http.ResponseWriter, p Problem) {
 		}
 		body[k] = v
 	}
-	w.Header().Set("Content-Type", "application/problem+json")
-	w.WriteHeader(p.Status)
-	_ = json.NewEncoder(w).Encode(body)
+	return body
 }
@@ -3,21 +3,27 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/flowd-org/flowd/internal/coredb"
 	"github.com/flowd-org/flowd/internal/executor/container"
+	"github.com/flowd-org/flowd/internal/indexer"
 	"github.com/flowd-org/flowd/internal/paths"
 	"github.com/flowd-org/flowd/internal/policy"
 	policyverify "github.com/flowd-org/flowd/internal/policy/verify"
 	"github.com/flowd-org/flowd/internal/server/handlers"
 	"github.com/flowd-org/flowd/internal/server/metrics"
-	"github.com/flowd-org/flowd/internal/server/runstore"
+	"github.com/flowd-org/flowd/internal/server/notify"
+	"github.com/flowd-org/flowd/internal/server/openapi"
+	"github.com/flowd-org/flowd/internal/server/runarchive"
+	"github.com/flowd-org/flowd/internal/server/settings"
 	"github.com/flowd-org/flowd/internal/server/sourcestore"
 	"github.com/flowd-org/flowd/internal/server/sse"
 )
@@ -28,6 +34,9 @@ func Run(ctx context.Context, cfg Config) error {
 		paths.SetDataDirOverride(cfg.DataDir)
 	}
 	norm := cfg.normalize()
+	if err := norm.validate(); err != nil {
+		return err
+	}
 	paths.SetDataDirOverride(norm.DataDir)
 
 	db, err := coredb.Open(ctx, norm.CoreDBOptions)
@@ -37,6 +46,9 @@ func Run(ctx context.Context, cfg Config) error {
 	defer db.Close()
 	norm.CoreDB = db
 
+	if norm.Settings == nil {
+		norm.Settings = newSettings(norm)
+	}
 	logger := newLogger(norm)
 	runtimeDetector := norm.RuntimeDetector
 	if runtimeDetector == nil {
@@ -45,13 +57,14 @@ func Run(ctx context.Context, cfg Config) error {
 		}
 	}
 	if norm.MetricsEnabled {
-		version := os.Getenv("FLWD_VERSION")
-		if version == "" {
-			version = "dev"
-		}
-		metrics.Default.SetBuildInfo(map[string]string{"version": version})
+		metrics.Default.SetBuildInfo(map[string]string{"version": serverVersion()})
 		metrics.Default.RecordSecurityProfileGauge(norm.Profile)
 	}
+	if norm.MetricsEnabled {
+		stopSnapshots := startMetricsSnapshots(ctx, db, metrics.Default, norm.MetricsSnapshotInterval, logger)
+		// Deferred after db.Close so the final snapshot is written first.
+		defer stopSnapshots()
+	}
 	runtime, err := runtimeDetector()
 	if err != nil {
 		logger.Error("container runtime preflight failed", slog.String("error", err.Error()))
@@ -59,6 +72,15 @@ func Run(ctx context.Context, cfg Config) error {
 	}
 	logger.Info("container runtime ready", slog.String("runtime.selected", string(runtime)))
 	norm.ContainerRuntime = runtime
+	logger.Info("flowd serve starting",
+		slog.String("version", serverVersion()),
+		slog.String("bind", norm.Bind),
+		slog.String("grpc_bind", norm.GRPCBind),
+		slog.String("profile", norm.Profile),
+		slog.String("scripts_root", norm.ScriptsRoot),
+		slog.String("runtime", string(runtime)),
+		slog.Any("features", enabledFeatures(serverFeatures(norm))),
+	)
 
 	policyCtx, err := loadPolicyContext(ctx, norm.Profile, norm.PolicyVerifier)
 	if err != nil {
@@ -66,15 +88,40 @@ func Run(ctx context.Context, cfg Config) error {
 	}
 	verifier := norm.Verifier
 	if verifier == nil {
-		verifier = policyverify.NewCosignVerifier()
+		verifier, err = policyCtx.NewImageVerifier()
+		if err != nil {
+			return fmt.Errorf("image verifier: %w", err)
+		}
 	}
 
+	if norm.PlanCache == nil {
+		norm.PlanCache = handlers.NewPlanCache(handlers.PlanCacheConfig{})
+	}
+	watcher := indexer.NewWatcher(norm.ScriptsRoot, norm.WatchInterval)
+	if norm.WatchScripts {
+		watcher = indexer.NewTreeWatcher(norm.ScriptsRoot, norm.WatchInterval)
+	}
+	watcher.OnChange(norm.PlanCache.Purge)
+	watcher.OnChange(func() {
+		logger.Info("scripts.reloaded", slog.String("scripts_root", norm.ScriptsRoot))
+	})
+	go watcher.Run(ctx)
+
+	handler, closeHandler := buildHandler(norm, policyCtx, verifier)
+	// Deferred after db.Close so queued run events are journaled first.
+	defer closeHandler()
+
 	server := &http.Server{
 		Addr:    norm.Bind,
-		Handler: buildHandler(norm, policyCtx, verifier),
+		Handler: handler,
 	}
 
-	errCh := make(chan error, 1)
+	errCh := make(chan error, 2)
+	stopGRPC, err := listenGRPC(norm, handler, errCh)
+	if err != nil {
+		return err
+	}
+	defer stopGRPC()
 	go func() {
 		errCh <- server.ListenAndServe()
 	}()
@@ -91,6 +138,7 @@ func Run(ctx context.Context, cfg Config) error {
 		}
 		return ctx.Err()
 	case err := <-errCh:
+		_ = server.Close()
 		if err == nil || errors.Is(err, http.ErrServerClosed) {
 			return nil
 		}
@@ -119,7 +167,20 @@ func loadPolicyContext(ctx context.Context, profile string, bundleVerifier polic
 	return policyCtx, nil
 }
 
-func buildHandler(cfg Config, policyCtx *policy.Context, verifier policyverify.ImageVerifier) http.Handler {
+// newSettings returns the runtime settings store seeded from startup flags.
+func newSettings(cfg Config) *settings.Store {
+	initial := settings.Defaults()
+	initial.ReadOnly = cfg.ReadOnly
+	return settings.New(initial)
+}
+
+// buildHandler wires the serve-mode mux. The returned cleanup flushes queued
+// run events, pending notification digests and alert deliveries, and must be
+// called once the HTTP server has stopped.
+func buildHandler(cfg Config, policyCtx *policy.Context, verifier policyverify.ImageVerifier) (http.Handler, func()) {
+	if cfg.Settings == nil {
+		cfg.Settings = newSettings(cfg)
+	}
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Cache-Control", "no-store")
@@ -128,6 +189,7 @@ func buildHandler(cfg Config, policyCtx *policy.Context, verifier policyverify.I
 	if cfg.MetricsEnabled {
 		mux.Handle("/metrics", metrics.Default.Handler())
 	}
+	mux.Handle("/openapi.json", openapi.Handler())
 
 	sourceStore := sourcestore.New()
 	exposeAliases := func(r *http.Request) bool {
@@ -158,29 +220,56 @@ func buildHandler(cfg Config, policyCtx *policy.Context, verifier policyverify.I
 		RuntimeDetector: cfg.RuntimeDetector,
 		AliasesPublic:   cfg.AliasesPublic,
 		ExposeAliases:   exposeAliases,
+		PlanCache:       cfg.PlanCache,
+
+		OfflineVerification: cfg.Sources.OfflineVerification,
+		Root:                cfg.ScriptsRoot,
 	}
 	mux.Handle("/sources", handlers.NewSourcesHandler(sourcesCfg))
-	mux.Handle("/sources/", handlers.NewSourceGetHandler(sourcesCfg))
+	sourceGet := handlers.NewSourceGetHandler(sourcesCfg)
+	reverifyCtx, stopReverifier := context.WithCancel(context.Background())
+	go handlers.NewSourceReverifier(handlers.SourceReverifierConfig{
+		Sources:  sourcesCfg,
+		Interval: cfg.Sources.ReverifyInterval,
+	}).Run(reverifyCtx)
 
 	kvStore := coredb.NewRuleYStore(cfg.CoreDB)
 	kvAllow := make(map[string]handlers.KVNamespaceConfig, len(cfg.RuleY.Allowlist))
+	kvLimits := make(map[string]int64, len(cfg.RuleY.Allowlist))
 	for ns, entry := range cfg.RuleY.Allowlist {
 		kvAllow[ns] = handlers.KVNamespaceConfig{LimitBytes: entry.LimitBytes}
+		kvLimits[ns] = entry.LimitBytes
 	}
 	mux.Handle("/kv/", handlers.NewKVHandler(handlers.KVConfig{
 		Store:     kvStore,
 		Allowlist: kvAllow,
 	}))
 
-	runStore := runstore.New()
+	runStore := handlers.OpenRunStore(context.Background(), cfg.CoreDB, nil)
+	if cfg.MetricsEnabled {
+		metrics.Default.SetRunStatusSource(runStore.CountByStatus)
+	}
 	hub := sse.New(sse.Config{})
 	globalHub := sse.New(sse.Config{})
 	journal := coredb.NewJournal(cfg.CoreDB, cfg.CoreDBOptions.JournalMaxBytes)
+	stopCompaction := startJournalCompaction(journal, cfg.EventJournal, slog.Default())
+	reporting := handlers.NewRunReporter(handlers.RunReporterConfig{
+		Runs:      runStore,
+		Sources:   sourceStore,
+		Secrets:   cfg.Secrets,
+		PublicURL: cfg.PublicURL,
+	})
 	baseSink := handlers.EventSinkFunc(func(runID string, ev sse.Event) {
 		hub.Publish(runID, ev)
 		globalHub.Publish("global", handlers.WrapGlobalEvent(runID, ev))
+		reporting.Publish(runID, ev)
 	})
-	eventSink := handlers.NewJournalEventSink(journal, baseSink)
+	// Every event is journaled before live fan-out, which goes through a
+	// bounded per-run queue so a slow subscriber never stalls run execution.
+	fanout := handlers.NewAsyncEventSink(baseSink, handlers.AsyncEventSinkConfig{
+		BufferSize: cfg.EventBufferSize,
+	})
+	eventSink := handlers.NewJournalEventSink(journal, fanout)
 	resolveSource := func(jobID string, ref *handlers.RunSourceRef) (map[string]any, bool) {
 		var name string
 		if ref != nil && ref.Name != "" {
@@ -194,27 +283,102 @@ func buildHandler(cfg Config, policyCtx *policy.Context, verifier policyverify.I
 		}
 		return sourcetoProvenance(src), true
 	}
-	runGet := handlers.NewRunGetHandler(runStore)
-	runEvents := handlers.NewRunEventsHandler(runStore, hub, journal)
+	var archive handlers.RunArchive
+	stopArchiver := func() {}
+	if cfg.RunArchive.HotRetention > 0 {
+		archiver := runarchive.New(runarchive.Config{
+			Runs:         runStore,
+			Journal:      journal,
+			Blobs:        runarchive.NewDirStore(cfg.RunArchive.Dir),
+			HotRetention: cfg.RunArchive.HotRetention,
+			Interval:     cfg.RunArchive.Interval,
+		})
+		archive = archiver
+		archiveCtx, cancel := context.WithCancel(context.Background())
+		go archiver.Run(archiveCtx)
+		stopArchiver = cancel
+	}
+	runGet := handlers.NewRunGetHandler(runStore, archive)
+	runProvenance := handlers.NewRunProvenanceHandler(runStore)
+	runTimeline := handlers.NewRunTimelineHandler(runStore, journal)
+	runArtifacts := handlers.NewRunArtifactsHandler(runStore)
+	streams := handlers.NewStreamLimiter(cfg.EventStreams)
+	runEvents := handlers.NewRunEventsHandler(runStore, hub, journal, streams)
 	runEventsExport := handlers.NewRunEventsExportHandler(runStore, journal, cfg.ExtensionEnabled("export"))
 	storageHealth := handlers.NewStorageHealthHandler(cfg.CoreDB)
+	alerter := notify.NewAlerter(notify.AlertConfig{Secrets: cfg.Secrets, PublicURL: cfg.PublicURL})
+	var (
+		notifiers = []handlers.RunNotifier{alerter}
+		email     *notify.Email
+	)
+	if mailer, err := cfg.mailer(); err == nil && mailer != nil {
+		email = notify.NewEmail(notify.EmailConfig{Mailer: mailer, PublicURL: cfg.PublicURL})
+		notifiers = append(notifiers, email)
+	}
+	var runHooks *notify.RunHooks
+	if len(cfg.RunHooks.Targets) > 0 {
+		runHooks = notify.NewRunHooks(notify.RunHookConfig{
+			Targets:   cfg.RunHooks.Targets,
+			Timeout:   cfg.RunHooks.Timeout,
+			PublicURL: cfg.PublicURL,
+			OnFailure: func(runID, event, target string, err error) {
+				data, _ := json.Marshal(map[string]any{
+					"run_id": runID,
+					"event":  event,
+					"target": target,
+					"error":  err.Error(),
+				})
+				eventSink.Publish(runID, sse.Event{Event: "run.hook.warning", Data: string(data)})
+			},
+		})
+		notifiers = append(notifiers, runHooks)
+	}
 	runHandler := handlers.NewRunsHandler(handlers.RunsConfig{
-		Root:          cfg.ScriptsRoot,
-		Store:         runStore,
-		Events:        eventSink,
-		ResolveSource: resolveSource,
-		Sources:       sourceStore,
-		Profile:       cfg.Profile,
-		Policy:        policyCtx,
-		Verifier:      verifier,
-		Runtime:       cfg.ContainerRuntime,
-		DB:            cfg.CoreDB,
+		Root:           cfg.ScriptsRoot,
+		Store:          runStore,
+		Events:         eventSink,
+		ResolveSource:  resolveSource,
+		Sources:        sourceStore,
+		Profile:        cfg.Profile,
+		Policy:         policyCtx,
+		Verifier:       verifier,
+		Runtime:        cfg.ContainerRuntime,
+		DB:             cfg.CoreDB,
+		Settings:       cfg.Settings,
+		Notifiers:      notifiers,
+		DefaultPerPage: cfg.DefaultPerPage,
+		MaxPerPage:     cfg.MaxPerPage,
+		Version:        serverVersion(),
+		Webhooks: handlers.WebhookDeliveryConfig{
+			Endpoints: cfg.Webhooks.endpoints(),
+			Secrets:   cfg.Secrets,
+		},
+		MaxParallelSteps: cfg.MaxParallelSteps,
+		MaxLogLineBytes:  cfg.MaxLogLineBytes,
+		StripANSILogs:    cfg.StripANSILogs,
+		Flakiness:        cfg.Flakiness,
+		ArgSecrets:       cfg.ArgSecrets,
+		ArtifactLimits:   cfg.ArtifactLimits,
+		Admission:        cfg.AdmissionWebhook,
 	})
+	if n := runHandler.ResumeScheduledRuns(); n > 0 {
+		slog.Default().Info("runs.scheduled.resumed", slog.Int("count", n))
+	}
 	mux.Handle("/jobs", handlers.NewJobsHandler(handlers.JobsConfig{
-		Root:          cfg.ScriptsRoot,
-		Sources:       sourceStore,
-		AliasesPublic: cfg.AliasesPublic,
-		ExposeAliases: exposeAliases,
+		Root:           cfg.ScriptsRoot,
+		Sources:        sourceStore,
+		AliasesPublic:  cfg.AliasesPublic,
+		ExposeAliases:  exposeAliases,
+		DefaultPerPage: cfg.DefaultPerPage,
+		MaxPerPage:     cfg.MaxPerPage,
+		Runs:           runStore,
+		Flakiness:      cfg.Flakiness,
+	}))
+	mux.Handle("/stats", handlers.NewStatsHandler(runStore, cfg.Flakiness))
+	mux.Handle("/jobs/", handlers.NewJobBadgeHandler(handlers.JobBadgeConfig{
+		Root:   cfg.ScriptsRoot,
+		Runs:   runStore,
+		Public: cfg.PublicBadges,
 	}))
 	mux.Handle("/plans", handlers.NewPlansHandler(handlers.PlansConfig{
 		Root:     cfg.ScriptsRoot,
@@ -223,14 +387,40 @@ func buildHandler(cfg Config, policyCtx *policy.Context, verifier policyverify.I
 		Policy:   policyCtx,
 		Verifier: verifier,
 		Runtime:  cfg.ContainerRuntime,
+		Cache:    cfg.PlanCache,
+	}))
+	sourceQuarantine := handlers.NewSourceQuarantineHandler(handlers.SourceQuarantineConfig{
+		Sources: sourcesCfg,
+		Runs:    runHandler,
+	})
+	mux.Handle("/sources/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if handlers.IsSourceQuarantinePath(r.URL.Path) {
+			sourceQuarantine.ServeHTTP(w, r)
+			return
+		}
+		sourceGet.ServeHTTP(w, r)
 	}))
 	mux.Handle("/runs", runHandler)
+	mux.HandleFunc("/runs:batch", runHandler.HandleBatch)
+	mux.HandleFunc("/runs:cancel", runHandler.HandleBulkCancel)
+	mux.Handle("/runs:compare", handlers.NewRunCompareHandler(runStore, journal))
 	mux.Handle("/runs/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if handlers.IsRunArtifactsPath(r.URL.Path) {
+			runArtifacts.ServeHTTP(w, r)
+			return
+		}
 		if strings.HasSuffix(r.URL.Path, ":cancel") {
 			id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/runs/"), ":cancel")
 			runHandler.HandleCancel(w, r, strings.Trim(id, "/"))
 			return
 		}
+		for _, action := range []string{"approve", "reject"} {
+			if strings.HasSuffix(r.URL.Path, ":"+action) {
+				id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/runs/"), ":"+action)
+				runHandler.HandleApproval(w, r, strings.Trim(id, "/"), action)
+				return
+			}
+		}
 		if strings.HasSuffix(r.URL.Path, "/events.ndjson") {
 			runEventsExport.ServeHTTP(w, r)
 			return
@@ -239,21 +429,154 @@ func buildHandler(cfg Config, policyCtx *policy.Context, verifier policyverify.I
 			runEvents.ServeHTTP(w, r)
 			return
 		}
+		if strings.HasSuffix(r.URL.Path, "/provenance") {
+			runProvenance.ServeHTTP(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/timeline") {
+			runTimeline.ServeHTTP(w, r)
+			return
+		}
 		runGet.ServeHTTP(w, r)
 	}))
+	pipelines := handlers.NewPipelinesHandler(handlers.PipelinesConfig{
+		Root: cfg.ScriptsRoot,
+		Runs: runHandler,
+		Events: handlers.EventSinkFunc(func(_ string, ev sse.Event) {
+			globalHub.Publish("global", ev)
+		}),
+	})
+	mux.Handle("/pipelines", pipelines)
+	mux.Handle("/pipelines/", pipelines)
+	schedules := handlers.NewSchedulesHandler(handlers.SchedulesConfig{
+		Runs:     runHandler,
+		Settings: cfg.Settings,
+	})
+	mux.Handle("/schedules", schedules)
+	mux.Handle("/schedules/", schedules)
+	scheduleCtx, stopScheduler := context.WithCancel(context.Background())
+	go schedules.Run(scheduleCtx)
+	forgeWebhooks := handlers.NewWebhooksHandler(handlers.WebhooksConfig{
+		Sources:     sourceStore,
+		CheckoutDir: cfg.Sources.CheckoutDir,
+		Runs:        runHandler,
+		Secrets:     cfg.Secrets,
+		Reporting:   reporting,
+	})
+	webhookDeliveries := handlers.NewWebhookDeliveriesHandler(runHandler.Webhooks(), handlers.Pagination{DefaultPerPage: cfg.DefaultPerPage, MaxPerPage: cfg.MaxPerPage})
+	mux.Handle("/webhooks/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/webhooks/deliveries" {
+			webhookDeliveries.ServeHTTP(w, r)
+			return
+		}
+		forgeWebhooks.ServeHTTP(w, r)
+	}))
+	auditLog := handlers.NewAuditLog(coredb.NewAuditStore(cfg.CoreDB))
+	auditHandler := handlers.NewAuditHandler(auditLog, handlers.Pagination{DefaultPerPage: cfg.DefaultPerPage, MaxPerPage: cfg.MaxPerPage})
+	mux.Handle("/audit", auditHandler)
+	mux.Handle("/audit.ndjson", auditHandler)
 	mux.Handle("/health/storage", storageHealth)
+	mux.Handle("/health/runtime", handlers.NewRuntimeHealthHandler(handlers.RuntimeHealthConfig{
+		Runtime: cfg.ContainerRuntime,
+	}))
+	mux.Handle("/capabilities", handlers.NewCapabilitiesHandler(handlers.CapabilitiesConfig{
+		Version:      serverVersion(),
+		SpecVersion:  specVersion,
+		Profile:      cfg.Profile,
+		Features:     serverFeatures(cfg),
+		Policy:       policyCtx,
+		MaxBodyBytes: cfg.MaxBodyBytes,
+		KVLimitBytes: kvLimits,
+		Pagination:   handlers.Pagination{DefaultPerPage: cfg.DefaultPerPage, MaxPerPage: cfg.MaxPerPage},
+	}))
+	mux.Handle("/admin/settings", handlers.NewAdminSettingsHandler(handlers.AdminSettingsConfig{
+		Settings: cfg.Settings,
+		Events: handlers.EventSinkFunc(func(_ string, ev sse.Event) {
+			globalHub.Publish("global", ev)
+		}),
+	}))
 	mux.Handle("/events", handlers.NewEventsHandler(handlers.EventsConfig{
 		RunStore:  runStore,
 		RunHub:    hub,
 		GlobalHub: globalHub,
+		Streams:   streams,
 	}))
 
-	return chainMiddleware(mux,
+	handler := chainMiddleware(mux,
 		metricsMiddleware(cfg),
 		loggingMiddleware(cfg),
+		bodyLimitMiddleware(cfg),
 		corsMiddleware(cfg),
+		versionMiddleware(),
+		auditMiddleware(auditLog),
 		authMiddleware(cfg),
+		accessMiddleware(runStore, archive),
+		readOnlyMiddleware(cfg),
+		faultMiddleware(cfg),
 	)
+	return handler, func() {
+		stopArchiver()
+		stopCompaction()
+		stopReverifier()
+		stopScheduler()
+		// Hook failures publish warnings, so drain hooks before the sink.
+		if runHooks != nil {
+			runHooks.Flush()
+		}
+		runHandler.Webhooks().Close()
+		fanout.Close()
+		if email != nil {
+			email.Flush()
+		}
+		alerter.Flush()
+	}
+}
+
+// specVersion is the API specification version implemented by this server.
+const specVersion = "1.2.0"
+
+// serverVersion reports the build version, overridable through FLWD_VERSION.
+func serverVersion() string {
+	if version := os.Getenv("FLWD_VERSION"); version != "" {
+		return version
+	}
+	return "dev"
+}
+
+// serverFeatures lists optional capabilities and whether this server offers
+// them. Clients should treat unknown or missing features as unavailable.
+func serverFeatures(cfg Config) map[string]bool {
+	return map[string]bool{
+		"sse":              true,
+		"runs-batch":       true,
+		"runs-bulk-cancel": true,
+		"pipelines":        true,
+		"webhooks":         true,
+		"webhook-delivery": len(cfg.Webhooks.URLs) > 0,
+		"admin-settings":   true,
+		"metrics":          cfg.MetricsEnabled,
+		"export":           cfg.ExtensionEnabled("export"),
+		"oci-run":          true,
+		"scheduler":        true,
+		"artifacts":        true,
+		"websocket":        false,
+		"grpc":             cfg.GRPCBind != "",
+		"rbac":             cfg.RBAC != nil,
+		"oidc":             cfg.OIDC != nil,
+		"admission":        cfg.AdmissionWebhook.URL != "",
+		"audit":            cfg.CoreDB != nil,
+	}
+}
+
+func enabledFeatures(features map[string]bool) []string {
+	out := make([]string, 0, len(features))
+	for name, enabled := range features {
+		if enabled {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
 }
 
 func sourcetoProvenance(src sourcestore.Source) map[string]any {
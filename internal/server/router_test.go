@@ -3,19 +3,24 @@ package server
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/flowd-org/flowd/internal/coredb"
 	"github.com/flowd-org/flowd/internal/policy"
 	"github.com/flowd-org/flowd/internal/policy/verify"
+	"github.com/flowd-org/flowd/internal/server/handlers"
 	"github.com/flowd-org/flowd/internal/server/metrics"
 	"github.com/flowd-org/flowd/internal/types"
 )
@@ -106,7 +111,8 @@ func TestMetricsEndpointExposesSeries(t *testing.T) {
 	if err != nil {
 		t.Fatalf("policy context: %v", err)
 	}
-	handler := buildHandler(cfg, policyCtx, nil)
+	handler, closeHandler := buildHandler(cfg, policyCtx, nil)
+	defer closeHandler()
 	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
 	rec := httptest.NewRecorder()
 
@@ -149,7 +155,8 @@ func TestRuleYKVHandlerIntegration(t *testing.T) {
 	if err != nil {
 		t.Fatalf("policy context: %v", err)
 	}
-	handler := buildHandler(cfg, policyCtx, nil)
+	handler, closeHandler := buildHandler(cfg, policyCtx, nil)
+	defer closeHandler()
 
 	putBody := func(val string) *bytes.Reader {
 		payload := map[string]string{"value": base64.StdEncoding.EncodeToString([]byte(val))}
@@ -199,3 +206,149 @@ func TestRuleYKVHandlerIntegration(t *testing.T) {
 		t.Fatalf("expected 429 when quota exceeded, got %d", quota.Code)
 	}
 }
+
+func TestAuditLogRecordsMutatingRequests(t *testing.T) {
+	cfg := Config{
+		Bind:    "127.0.0.1:0",
+		Profile: "secure",
+		DataDir: t.TempDir(),
+		RuleY: types.RuleYConfig{
+			Allowlist: map[string]types.RuleYNamespaceConfig{
+				"core_triggers": {LimitBytes: defaultRuleYLimitBytes},
+			},
+		},
+	}
+	cfg = cfg.normalize()
+	db, err := coredb.Open(context.Background(), cfg.CoreDBOptions)
+	if err != nil {
+		t.Fatalf("open core db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	cfg.CoreDB = db
+
+	policyCtx, err := policy.NewContext(nil)
+	if err != nil {
+		t.Fatalf("policy context: %v", err)
+	}
+	handler, closeHandler := buildHandler(cfg, policyCtx, nil)
+	defer closeHandler()
+
+	do := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	payload := `{"value":"YWJjZA=="}`
+	if rec := do(http.MethodPut, "/kv/core_triggers/a", payload, "ruley:write"); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPut, "/kv/core_triggers/b", payload, "jobs:read"); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/kv/core_triggers/a", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+	// Reads are not audited.
+	do(http.MethodGet, "/kv/core_triggers/a", "", "ruley:read")
+
+	if rec := do(http.MethodGet, "/audit", "", "jobs:read"); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected audit:read to be required, got %d", rec.Code)
+	}
+	rec := do(http.MethodGet, "/audit", "", "audit:read")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var entries []handlers.AuditEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("decode audit entries: %v", err)
+	}
+	if len(entries) != 3 || rec.Header().Get("X-Total-Count") != "3" {
+		t.Fatalf("expected 3 audited requests, got %d: %+v", len(entries), entries)
+	}
+	written, denied, anonymous := entries[0], entries[1], entries[2]
+	sum := sha256.Sum256([]byte(payload))
+	if written.Action != "PUT /kv/{namespace}/{key}" || written.Outcome != handlers.AuditSucceeded ||
+		written.Status != http.StatusNoContent || written.RequestSHA256 != hex.EncodeToString(sum[:]) {
+		t.Fatalf("unexpected entry for the write: %+v", written)
+	}
+	if !strings.HasPrefix(written.Principal, "token:") || written.Principal == denied.Principal {
+		t.Fatalf("expected per-token principals, got %q and %q", written.Principal, denied.Principal)
+	}
+	if denied.Outcome != handlers.AuditDenied || len(denied.Decisions) != 1 ||
+		denied.Decisions[0].Subject != "scope" || denied.Decisions[0].Code != "scope.missing" {
+		t.Fatalf("expected the scope denial to be recorded, got %+v", denied)
+	}
+	if anonymous.Principal != "" || anonymous.Status != http.StatusUnauthorized {
+		t.Fatalf("expected an unauthenticated entry, got %+v", anonymous)
+	}
+
+	query := url.Values{"principal": {written.Principal}, "since": {written.Timestamp.Add(-time.Second).Format(time.RFC3339)}}
+	rec = do(http.MethodGet, "/audit.ndjson?"+query.Encode(), "", "audit:read")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/x-ndjson" {
+		t.Fatalf("expected an ndjson export, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"action":"PUT /kv/{namespace}/{key}"`) {
+		t.Fatalf("expected the write alone in the export, got %q", rec.Body.String())
+	}
+	rec = do(http.MethodGet, "/audit?action="+url.QueryEscape("DELETE /kv/{namespace}/{key}"), "", "audit:read")
+	if rec.Header().Get("X-Total-Count") != "1" {
+		t.Fatalf("expected one delete, got %s", rec.Body.String())
+	}
+	if rec := do(http.MethodGet, "/audit?since=yesterday", "", "audit:read"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad time range, got %d", rec.Code)
+	}
+}
+
+func TestCapabilitiesEndpointReportsFeaturesAndLimits(t *testing.T) {
+	cfg := Config{Bind: "127.0.0.1:0", Profile: "secure"}
+	cfg = cfg.normalize()
+	policyCtx, err := policy.NewContext(&policy.Bundle{AllowedRegistries: []string{"registry.corp.example"}})
+	if err != nil {
+		t.Fatalf("policy context: %v", err)
+	}
+	handler, closeHandler := buildHandler(cfg, policyCtx, nil)
+	defer closeHandler()
+	req := httptest.NewRequest(http.MethodGet, "/capabilities", nil)
+	req.Header.Set("Authorization", "Bearer jobs:read")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var caps struct {
+		Version           string          `json:"version"`
+		Features          map[string]bool `json:"features"`
+		AllowedRegistries []string        `json:"allowed_registries"`
+		Limits            struct {
+			MaxBodyBytes int64 `json:"max_body_bytes"`
+		} `json:"limits"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &caps); err != nil {
+		t.Fatalf("decode capabilities: %v", err)
+	}
+	if caps.Version == "" {
+		t.Fatal("expected version to be reported")
+	}
+	for _, name := range []string{"oci-run", "scheduler", "artifacts", "websocket"} {
+		if _, ok := caps.Features[name]; !ok {
+			t.Fatalf("expected feature %q to be listed, got %v", name, caps.Features)
+		}
+	}
+	if !caps.Features["oci-run"] {
+		t.Fatalf("expected oci-run to be available, got %v", caps.Features)
+	}
+	if len(caps.AllowedRegistries) != 1 || caps.AllowedRegistries[0] != "registry.corp.example" {
+		t.Fatalf("unexpected allowed registries %v", caps.AllowedRegistries)
+	}
+	if caps.Limits.MaxBodyBytes != defaultMaxBodyBytes {
+		t.Fatalf("expected max body %d, got %d", defaultMaxBodyBytes, caps.Limits.MaxBodyBytes)
+	}
+}
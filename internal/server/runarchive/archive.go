@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package runarchive moves finished runs out of hot storage once they age past
+// the hot retention window. Each run's metadata, journaled events and receipt
+// are written as one gzip-compressed JSON document to a blob store, from which
+// GET /runs/{id} hydrates them on demand.
+package runarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/coredb"
+	"github.com/flowd-org/flowd/internal/paths"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+// DefaultInterval is how often Run sweeps for runs to archive.
+const DefaultInterval = 10 * time.Minute
+
+// ErrNotFound is returned by BlobStore.Get for unknown keys.
+var ErrNotFound = errors.New("runarchive: blob not found")
+
+// BlobStore keeps archived runs as opaque blobs addressed by key.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// Record is the archived form of a run.
+type Record struct {
+	Run        runstore.Run    `json:"run"`
+	Events     []Event         `json:"events,omitempty"`
+	Receipt    json.RawMessage `json:"receipt,omitempty"`
+	ArchivedAt time.Time       `json:"archived_at"`
+}
+
+// Event is one journaled run event.
+type Event struct {
+	Seq       int64     `json:"seq"`
+	Type      string    `json:"type"`
+	Data      string    `json:"data"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Config configures an Archiver.
+type Config struct {
+	Runs    *runstore.Store
+	Journal *coredb.Journal
+	Blobs   BlobStore
+	// HotRetention is how long finished runs stay in hot storage.
+	HotRetention time.Duration
+	Interval     time.Duration
+	Now          func() time.Time
+	Logger       *slog.Logger
+}
+
+// Archiver moves finished runs older than the hot retention window into the
+// blob store.
+type Archiver struct {
+	cfg Config
+}
+
+// New returns an archiver for cfg.
+func New(cfg Config) *Archiver {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.Now == nil {
+		cfg.Now = func() time.Time { return time.Now().UTC() }
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return &Archiver{cfg: cfg}
+}
+
+// Run sweeps every Interval until ctx is canceled.
+func (a *Archiver) Run(ctx context.Context) {
+	if a == nil {
+		return
+	}
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = a.Sweep(ctx)
+		}
+	}
+}
+
+// Sweep archives every run that finished before the hot retention window and
+// returns how many were moved. A run whose archive cannot be written stays in
+// hot storage and is retried on the next sweep.
+func (a *Archiver) Sweep(ctx context.Context) (int, error) {
+	cutoff := a.cfg.Now().Add(-a.cfg.HotRetention)
+	moved := 0
+	var errs []error
+	for _, run := range a.cfg.Runs.List() {
+		if run.FinishedAt == nil || !run.FinishedAt.Before(cutoff) {
+			continue
+		}
+		if err := a.archive(ctx, run); err != nil {
+			a.cfg.Logger.Warn("run.archive.failed", slog.String("run_id", run.ID), slog.String("error", err.Error()))
+			errs = append(errs, err)
+			continue
+		}
+		moved++
+	}
+	if moved > 0 {
+		a.cfg.Logger.Info("run.archive.sweep", slog.Int("archived", moved))
+	}
+	return moved, errors.Join(errs...)
+}
+
+func (a *Archiver) archive(ctx context.Context, run runstore.Run) error {
+	rec := Record{Run: run, ArchivedAt: a.cfg.Now()}
+	err := a.cfg.Journal.ForEach(ctx, run.ID, 0, func(e coredb.JournalEntry) error {
+		rec.Events = append(rec.Events, Event{Seq: e.Seq, Type: e.EventType, Data: string(e.Payload), Timestamp: e.Timestamp})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	receiptPath := filepath.Join(paths.RunDir(run.ID), "receipt.json")
+	receipt, err := os.ReadFile(receiptPath)
+	switch {
+	case err == nil:
+		rec.Receipt = receipt
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("read receipt: %w", err)
+	}
+
+	data, err := encodeRecord(rec)
+	if err != nil {
+		return err
+	}
+	if err := a.cfg.Blobs.Put(ctx, blobKey(run.ID), data); err != nil {
+		return fmt.Errorf("store archive: %w", err)
+	}
+	if _, err := a.cfg.Journal.DeleteRun(ctx, run.ID); err != nil {
+		return err
+	}
+	if rec.Receipt != nil {
+		if err := os.Remove(receiptPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove receipt: %w", err)
+		}
+	}
+	a.cfg.Runs.Delete(run.ID)
+	return nil
+}
+
+// Load returns the archived record for runID.
+func (a *Archiver) Load(ctx context.Context, runID string) (Record, bool, error) {
+	data, err := a.cfg.Blobs.Get(ctx, blobKey(runID))
+	if errors.Is(err, ErrNotFound) {
+		return Record{}, false, nil
+	}
+	if err != nil {
+		return Record{}, false, err
+	}
+	rec, err := decodeRecord(data)
+	if err != nil {
+		return Record{}, false, fmt.Errorf("decode archive %s: %w", runID, err)
+	}
+	return rec, true, nil
+}
+
+// LoadRun returns the archived run metadata for runID.
+func (a *Archiver) LoadRun(ctx context.Context, runID string) (runstore.Run, bool, error) {
+	rec, ok, err := a.Load(ctx, runID)
+	return rec.Run, ok, err
+}
+
+func blobKey(runID string) string {
+	return "runs/" + runID + ".json.gz"
+}
+
+func encodeRecord(rec Record) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(rec); err != nil {
+		return nil, fmt.Errorf("encode archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("compress archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeRecord(data []byte) (Record, error) {
+	var rec Record
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return rec, err
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return rec, err
+	}
+	err = json.Unmarshal(raw, &rec)
+	return rec, err
+}
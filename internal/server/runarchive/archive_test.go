@@ -0,0 +1,83 @@
+package runarchive
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/coredb"
+	"github.com/flowd-org/flowd/internal/paths"
+	"github.com/flowd-org/flowd/internal/server/runstore"
+)
+
+func TestSweepMovesOldRunsToArchive(t *testing.T) {
+	ctx := context.Background()
+	dataDir := t.TempDir()
+	paths.SetDataDirOverride(dataDir)
+	t.Cleanup(func() { paths.SetDataDirOverride("") })
+
+	db, err := coredb.Open(ctx, coredb.Options{DataDir: dataDir})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	journal := coredb.NewJournal(db, 0)
+
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	oldFinish := now.Add(-48 * time.Hour)
+	newFinish := now.Add(-time.Hour)
+	runs := runstore.New()
+	runs.Create(runstore.Run{ID: "run-old", JobID: "backup", Status: "completed", StartedAt: oldFinish.Add(-time.Minute), FinishedAt: &oldFinish})
+	runs.Create(runstore.Run{ID: "run-new", JobID: "backup", Status: "completed", StartedAt: newFinish.Add(-time.Minute), FinishedAt: &newFinish})
+	runs.Create(runstore.Run{ID: "run-active", JobID: "backup", Status: "running", StartedAt: oldFinish})
+	if _, err := journal.Append(ctx, "run-old", "run.finish", []byte(`{"status":"completed"}`), oldFinish); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	runDir := paths.RunDir("run-old")
+	if err := os.MkdirAll(runDir, 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(runDir, "receipt.json"), []byte(`{"run_id":"run-old"}`), 0o600); err != nil {
+		t.Fatalf("write receipt: %v", err)
+	}
+
+	archiver := New(Config{
+		Runs:         runs,
+		Journal:      journal,
+		Blobs:        NewDirStore(filepath.Join(dataDir, "archive")),
+		HotRetention: 24 * time.Hour,
+		Now:          func() time.Time { return now },
+	})
+	moved, err := archiver.Sweep(ctx)
+	if err != nil || moved != 1 {
+		t.Fatalf("Sweep = %d, %v; want 1 archived run", moved, err)
+	}
+	if _, ok := runs.Get("run-old"); ok {
+		t.Fatalf("expected archived run to leave hot storage")
+	}
+	for _, id := range []string{"run-new", "run-active"} {
+		if _, ok := runs.Get(id); !ok {
+			t.Fatalf("expected %s to stay in hot storage", id)
+		}
+	}
+	if earliest, _, _ := journal.Bounds(ctx, "run-old"); earliest != 0 {
+		t.Fatalf("expected journal events to be removed")
+	}
+	if _, err := os.Stat(filepath.Join(runDir, "receipt.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected receipt to move to the archive, stat err = %v", err)
+	}
+
+	rec, ok, err := archiver.Load(ctx, "run-old")
+	if err != nil || !ok {
+		t.Fatalf("Load = %v, %v", ok, err)
+	}
+	if rec.Run.JobID != "backup" || len(rec.Events) != 1 || rec.Events[0].Type != "run.finish" ||
+		string(rec.Receipt) != `{"run_id":"run-old"}` {
+		t.Fatalf("unexpected archive record %+v", rec)
+	}
+	if _, ok, err := archiver.LoadRun(ctx, "run-missing"); ok || err != nil {
+		t.Fatalf("expected missing run to report not found, got %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,95 @@
+package runstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/flowd-org/flowd/internal/coredb"
+)
+
+// Backend persists runs outside the process. coredb.RunRecordStore
+// implements it.
+type Backend interface {
+	All(ctx context.Context) ([]coredb.RunRecord, error)
+	Put(ctx context.Context, rec coredb.RunRecord) error
+	Delete(ctx context.Context, id string) error
+}
+
+// Open returns a store loaded from backend that writes every change through
+// to it. Write failures are passed to onError, if set; the in-memory copy
+// stays authoritative for the life of the process.
+func Open(ctx context.Context, backend Backend, onError func(error)) (*Store, error) {
+	s := New()
+	if err := s.Persist(ctx, backend, onError); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Persist attaches backend to a store already in use. Persisted runs are
+// loaded, runs only held in memory are written to backend, and later changes
+// are written through. A run present in both keeps the in-memory copy.
+func (s *Store) Persist(ctx context.Context, backend Backend, onError func(error)) error {
+	records, err := backend.All(ctx)
+	if err != nil {
+		return err
+	}
+	loaded := make(map[string]Run, len(records))
+	for _, rec := range records {
+		var run Run
+		if err := json.Unmarshal(rec.Data, &run); err != nil {
+			return fmt.Errorf("decode run %s: %w", rec.ID, err)
+		}
+		loaded[run.ID] = run
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, run := range s.runs {
+		if err := putRun(ctx, backend, run); err != nil {
+			return err
+		}
+		delete(loaded, id)
+	}
+	for id, run := range loaded {
+		s.runs[id] = run
+	}
+	s.backend = backend
+	s.onError = onError
+	return nil
+}
+
+func (s *Store) persistLocked(run Run) {
+	if s.backend == nil {
+		return
+	}
+	s.reportErr(putRun(context.Background(), s.backend, run))
+}
+
+func (s *Store) removeLocked(id string) {
+	if s.backend == nil {
+		return
+	}
+	s.reportErr(s.backend.Delete(context.Background(), id))
+}
+
+func (s *Store) reportErr(err error) {
+	if err != nil && s.onError != nil {
+		s.onError(err)
+	}
+}
+
+func putRun(ctx context.Context, backend Backend, run Run) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("encode run %s: %w", run.ID, err)
+	}
+	return backend.Put(ctx, coredb.RunRecord{
+		ID:        run.ID,
+		JobID:     run.JobID,
+		Status:    run.Status,
+		StartedAt: run.StartedAt,
+		Data:      data,
+	})
+}
@@ -0,0 +1,85 @@
+package runstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/coredb"
+)
+
+// openTestBackend opens the core DB in dir; close it before reopening.
+func openTestBackend(t *testing.T, dir string) (*coredb.RunRecordStore, func()) {
+	t.Helper()
+	db, err := coredb.Open(context.Background(), coredb.Options{DataDir: dir})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	return coredb.NewRunRecordStore(db), func() { _ = db.Close() }
+}
+
+func TestStorePersistsAcrossReopen(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	started := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	backend, closeDB := openTestBackend(t, dir)
+	store, err := Open(ctx, backend, func(err error) { t.Errorf("persist: %v", err) })
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	store.Create(Run{ID: "r1", JobID: "build", Status: "running", StartedAt: started,
+		Provenance: map[string]any{"source": "local"}, Labels: map[string]string{"env": "ci"}})
+	store.Create(Run{ID: "r2", JobID: "test", Status: "queued", StartedAt: started})
+	store.Update(Run{ID: "r1", JobID: "build", Status: "completed", StartedAt: started,
+		Provenance: map[string]any{"source": "local"}, Labels: map[string]string{"env": "ci"}})
+	store.Delete("r2")
+	closeDB()
+
+	backend, closeDB = openTestBackend(t, dir)
+	defer closeDB()
+	reopened, err := Open(ctx, backend, nil)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	run, ok := reopened.Get("r1")
+	if !ok || run.Status != "completed" || run.Provenance["source"] != "local" || run.Labels["env"] != "ci" {
+		t.Fatalf("expected persisted r1, got %+v (ok=%v)", run, ok)
+	}
+	if !run.StartedAt.Equal(started) {
+		t.Fatalf("expected started_at %v, got %v", started, run.StartedAt)
+	}
+	if _, ok := reopened.Get("r2"); ok {
+		t.Fatal("expected deleted run to stay deleted")
+	}
+}
+
+func TestStorePersistMigratesMemoryRuns(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	backend, closeDB := openTestBackend(t, dir)
+	if err := backend.Put(ctx, coredb.RunRecord{ID: "stored", Status: "completed", Data: []byte(`{"id":"stored","status":"completed"}`)}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	store := New()
+	store.Create(Run{ID: "memory", Status: "completed"})
+	if err := store.Persist(ctx, backend, nil); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if _, ok := store.Get("stored"); !ok {
+		t.Fatal("expected persisted run to be loaded")
+	}
+	closeDB()
+
+	backend, closeDB = openTestBackend(t, dir)
+	defer closeDB()
+	reopened, err := Open(ctx, backend, nil)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if len(reopened.List()) != 2 {
+		t.Fatalf("expected both runs after migration, got %+v", reopened.List())
+	}
+}
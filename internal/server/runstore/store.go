@@ -1,28 +1,59 @@
 package runstore
 
 import (
-	"sort"
+	"cmp"
+	"encoding/json"
+	"slices"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/flowd-org/flowd/internal/sortspec"
+	"github.com/flowd-org/flowd/internal/types"
 )
 
 // Run represents the persisted metadata for a run.
 type Run struct {
-	ID         string         `json:"id"`
-	JobID      string         `json:"job_id"`
-	Status     string         `json:"status"`
-	StartedAt  time.Time      `json:"started_at"`
-	FinishedAt *time.Time     `json:"finished_at,omitempty"`
-	Result     map[string]any `json:"result,omitempty"`
-	Executor   string         `json:"executor,omitempty"`
-	Runtime    string         `json:"runtime,omitempty"`
-	Provenance map[string]any `json:"provenance,omitempty"`
+	ID         string            `json:"id"`
+	JobID      string            `json:"job_id"`
+	Status     string            `json:"status"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+	Result     map[string]any    `json:"result,omitempty"`
+	Executor   string            `json:"executor,omitempty"`
+	Runtime    string            `json:"runtime,omitempty"`
+	Provenance map[string]any    `json:"provenance,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	// Approval tracks the requester and approvals of runs held for approval.
+	Approval *types.RunApproval `json:"approval,omitempty"`
+	// Shutdown is "graceful" or "forced" for canceled runs whose steps were
+	// interrupted.
+	Shutdown string `json:"shutdown,omitempty"`
+	// Runner describes the flowd build and host that executed the run.
+	Runner *types.RunnerInfo `json:"runner,omitempty"`
+	// Warnings lists arg rules marked severity: warning that the args broke.
+	Warnings []types.ArgWarning `json:"warnings,omitempty"`
+	// StartAt is when a run created with a delayed start is dispatched.
+	StartAt *time.Time `json:"start_at,omitempty"`
+	// QueuePosition is the run's 1-based place in its job's queue while it
+	// waits for a concurrency slot.
+	QueuePosition int `json:"queue_position,omitempty"`
+	// Artifacts lists the files the run published, indexed when it finished.
+	Artifacts []types.RunArtifact `json:"artifacts,omitempty"`
+	// Request is the creation request of a scheduled run, kept so that it can
+	// be prepared again when the server restarts before its start time.
+	Request json.RawMessage `json:"request,omitempty"`
 }
 
-// Store keeps runs in memory for serve mode.
+// Store keeps runs in memory for serve mode, optionally writing them
+// through to a Backend (see Persist).
 type Store struct {
-	mu   sync.RWMutex
-	runs map[string]Run
+	mu       sync.RWMutex
+	runs     map[string]Run
+	watchers map[string][]chan struct{}
+
+	backend Backend
+	onError func(error)
 }
 
 // New returns an empty run store.
@@ -37,6 +68,8 @@ func (s *Store) Create(run Run) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.runs[run.ID] = run
+	s.persistLocked(run)
+	s.notifyLocked(run.ID)
 }
 
 // Update replaces the stored run if it exists.
@@ -44,6 +77,53 @@ func (s *Store) Update(run Run) {
 	s.Create(run)
 }
 
+// Delete removes a run. It reports whether the run existed.
+func (s *Store) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.runs[id]
+	delete(s.runs, id)
+	if ok {
+		s.removeLocked(id)
+	}
+	s.notifyLocked(id)
+	return ok
+}
+
+// Watch returns a channel that is closed the next time the run with the
+// given ID is created, updated or deleted, and a function that releases the
+// watch early. Take the watch before reading the run so no change is missed.
+func (s *Store) Watch(id string) (<-chan struct{}, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.watchers == nil {
+		s.watchers = make(map[string][]chan struct{})
+	}
+	ch := make(chan struct{})
+	s.watchers[id] = append(s.watchers[id], ch)
+	return ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		watchers := s.watchers[id]
+		for i, w := range watchers {
+			if w == ch {
+				s.watchers[id] = append(watchers[:i:i], watchers[i+1:]...)
+				break
+			}
+		}
+		if len(s.watchers[id]) == 0 {
+			delete(s.watchers, id)
+		}
+	}
+}
+
+func (s *Store) notifyLocked(id string) {
+	for _, ch := range s.watchers[id] {
+		close(ch)
+	}
+	delete(s.watchers, id)
+}
+
 // Get retrieves a run by ID.
 func (s *Store) Get(id string) (Run, bool) {
 	s.mu.RLock()
@@ -52,17 +132,75 @@ func (s *Store) Get(id string) (Run, bool) {
 	return run, ok
 }
 
-// List returns runs sorted by StartedAt descending.
-func (s *Store) List() []Run {
+// CountByStatus returns the number of stored runs per status.
+func (s *Store) CountByStatus() map[string]int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	counts := make(map[string]int)
+	for _, run := range s.runs {
+		counts[run.Status]++
+	}
+	return counts
+}
+
+// SortFields are the run fields accepted by ListSorted.
+var SortFields = []string{"started_at", "finished_at", "duration", "status", "job_id", "id"}
+
+// DefaultSort lists the newest runs first.
+var DefaultSort = []sortspec.Key{{Field: "started_at", Desc: true}}
+
+// List returns runs sorted by StartedAt descending.
+func (s *Store) List() []Run {
+	return s.ListSorted(DefaultSort)
+}
 
+// ListSorted returns runs ordered by keys. Runs that have not finished sort
+// after finished ones on finished_at and duration in either direction. Ties
+// fall back to the run ID so pages stay stable.
+func (s *Store) ListSorted(keys []sortspec.Key) []Run {
+	s.mu.RLock()
 	out := make([]Run, 0, len(s.runs))
 	for _, run := range s.runs {
 		out = append(out, run)
 	}
-	sort.Slice(out, func(i, j int) bool {
-		return out[i].StartedAt.After(out[j].StartedAt)
+	s.mu.RUnlock()
+
+	slices.SortFunc(out, func(a, b Run) int {
+		for _, key := range keys {
+			if c := compareRuns(a, b, key); c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(a.ID, b.ID)
 	})
 	return out
 }
+
+func compareRuns(a, b Run, key sortspec.Key) int {
+	switch key.Field {
+	case "started_at":
+		return key.Apply(a.StartedAt.Compare(b.StartedAt))
+	case "finished_at", "duration":
+		if a.FinishedAt == nil || b.FinishedAt == nil {
+			return cmp.Compare(boolRank(a.FinishedAt == nil), boolRank(b.FinishedAt == nil))
+		}
+		if key.Field == "finished_at" {
+			return key.Apply(a.FinishedAt.Compare(*b.FinishedAt))
+		}
+		return key.Apply(cmp.Compare(a.FinishedAt.Sub(a.StartedAt), b.FinishedAt.Sub(b.StartedAt)))
+	case "status":
+		return key.Apply(strings.Compare(a.Status, b.Status))
+	case "job_id":
+		return key.Apply(strings.Compare(a.JobID, b.JobID))
+	case "id":
+		return key.Apply(strings.Compare(a.ID, b.ID))
+	}
+	return 0
+}
+
+func boolRank(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
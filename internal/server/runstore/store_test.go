@@ -1,8 +1,11 @@
 package runstore
 
 import (
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/flowd-org/flowd/internal/sortspec"
 )
 
 func TestStoreCreateGetList(t *testing.T) {
@@ -26,3 +29,72 @@ func TestStoreCreateGetList(t *testing.T) {
 		t.Fatalf("expected newest run first, got %s", list[0].ID)
 	}
 }
+
+func TestStoreWatch(t *testing.T) {
+	store := New()
+	store.Create(Run{ID: "r1", Status: "running"})
+
+	changed, stop := store.Watch("r1")
+	defer stop()
+	other, stopOther := store.Watch("r2")
+	stopOther()
+
+	select {
+	case <-changed:
+		t.Fatal("watch fired before the run changed")
+	default:
+	}
+
+	store.Update(Run{ID: "r1", Status: "completed"})
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("watch did not fire on update")
+	}
+	select {
+	case <-other:
+		t.Fatal("watch on another run fired")
+	default:
+	}
+	if len(store.watchers) != 0 {
+		t.Fatalf("expected watchers to be released, got %d", len(store.watchers))
+	}
+}
+
+func TestStoreListSorted(t *testing.T) {
+	store := New()
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	finished := func(d time.Duration) *time.Time {
+		at := base.Add(d)
+		return &at
+	}
+	store.Create(Run{ID: "r1", Status: "failed", StartedAt: base, FinishedAt: finished(5 * time.Minute)})
+	store.Create(Run{ID: "r2", Status: "completed", StartedAt: base.Add(time.Minute), FinishedAt: finished(2 * time.Minute)})
+	store.Create(Run{ID: "r3", Status: "running", StartedAt: base.Add(2 * time.Minute)})
+	store.Create(Run{ID: "r4", Status: "completed", StartedAt: base.Add(2 * time.Minute), FinishedAt: finished(12 * time.Minute)})
+
+	ids := func(keys []sortspec.Key) []string {
+		var out []string
+		for _, run := range store.ListSorted(keys) {
+			out = append(out, run.ID)
+		}
+		return out
+	}
+	for _, tc := range []struct {
+		spec string
+		want []string
+	}{
+		{"-started_at", []string{"r3", "r4", "r2", "r1"}},
+		{"-duration", []string{"r4", "r1", "r2", "r3"}},
+		{"duration", []string{"r2", "r1", "r4", "r3"}},
+		{"status,-started_at", []string{"r4", "r2", "r1", "r3"}},
+	} {
+		keys, err := sortspec.Parse(tc.spec, SortFields...)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.spec, err)
+		}
+		if got := ids(keys); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.spec, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package settings holds serve-mode settings that operators can change at
+// runtime through the admin API without restarting the server.
+package settings
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Values is a snapshot of the runtime-adjustable settings.
+type Values struct {
+	LogLevel        string `json:"log_level"`
+	DebugEvents     bool   `json:"debug_events"`
+	SchedulerPaused bool   `json:"scheduler_paused"`
+	ReadOnly        bool   `json:"read_only"`
+}
+
+// Patch describes a partial update; nil fields are left unchanged.
+type Patch struct {
+	LogLevel        *string `json:"log_level,omitempty"`
+	DebugEvents     *bool   `json:"debug_events,omitempty"`
+	SchedulerPaused *bool   `json:"scheduler_paused,omitempty"`
+	ReadOnly        *bool   `json:"read_only,omitempty"`
+}
+
+// Change records a single applied field update.
+type Change struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// Store guards the current settings. The log level is also exposed as a
+// slog.LevelVar so loggers pick up changes immediately.
+type Store struct {
+	mu       sync.RWMutex
+	values   Values
+	level    slog.LevelVar
+	handlers []func(before, after Values, changes []Change)
+}
+
+// Defaults returns the settings used when none are configured.
+func Defaults() Values {
+	return Values{LogLevel: "info"}
+}
+
+// New returns a store initialised with initial. An empty or invalid log level
+// falls back to info.
+func New(initial Values) *Store {
+	s := &Store{values: initial}
+	level, err := ParseLevel(initial.LogLevel)
+	if err != nil {
+		level = slog.LevelInfo
+	}
+	s.values.LogLevel = levelName(level)
+	s.level.Set(level)
+	return s
+}
+
+// Get returns the current settings.
+func (s *Store) Get() Values {
+	if s == nil {
+		return Defaults()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.values
+}
+
+// Level returns the leveler backing the server loggers.
+func (s *Store) Level() slog.Leveler {
+	if s == nil {
+		return slog.LevelInfo
+	}
+	return &s.level
+}
+
+// DebugEvents reports whether verbose run debug events are enabled.
+func (s *Store) DebugEvents() bool {
+	return s.Get().DebugEvents
+}
+
+// SchedulerPaused reports whether scheduled runs are paused.
+func (s *Store) SchedulerPaused() bool {
+	return s.Get().SchedulerPaused
+}
+
+// ReadOnly reports whether mutating API requests are refused.
+func (s *Store) ReadOnly() bool {
+	return s.Get().ReadOnly
+}
+
+// OnChange registers fn to run after a patch changes at least one setting.
+func (s *Store) OnChange(fn func(before, after Values, changes []Change)) {
+	if s == nil || fn == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers = append(s.handlers, fn)
+}
+
+// Apply validates and applies p, returning the resulting settings and the
+// fields that actually changed. Invalid patches leave the store untouched.
+func (s *Store) Apply(p Patch) (Values, []Change, error) {
+	var level slog.Level
+	if p.LogLevel != nil {
+		parsed, err := ParseLevel(*p.LogLevel)
+		if err != nil {
+			return Values{}, nil, err
+		}
+		level = parsed
+	}
+
+	s.mu.Lock()
+	before := s.values
+	after := before
+	var changes []Change
+	if p.LogLevel != nil {
+		after.LogLevel = levelName(level)
+		if after.LogLevel != before.LogLevel {
+			changes = append(changes, Change{Field: "log_level", From: before.LogLevel, To: after.LogLevel})
+			s.level.Set(level)
+		}
+	}
+	applyBool := func(field string, patch *bool, current *bool) {
+		if patch == nil || *patch == *current {
+			return
+		}
+		changes = append(changes, Change{Field: field, From: *current, To: *patch})
+		*current = *patch
+	}
+	applyBool("debug_events", p.DebugEvents, &after.DebugEvents)
+	applyBool("scheduler_paused", p.SchedulerPaused, &after.SchedulerPaused)
+	applyBool("read_only", p.ReadOnly, &after.ReadOnly)
+	s.values = after
+	handlers := append([]func(Values, Values, []Change){}, s.handlers...)
+	s.mu.Unlock()
+
+	if len(changes) > 0 {
+		for _, fn := range handlers {
+			fn(before, after, changes)
+		}
+	}
+	return after, changes, nil
+}
+
+// ParseLevel maps debug|info|warn|error (case-insensitive) to a slog level.
+// An empty value selects info.
+func ParseLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("invalid log level %q (expected debug, info, warn or error)", value)
+	}
+}
+
+func levelName(level slog.Level) string {
+	switch {
+	case level <= slog.LevelDebug:
+		return "debug"
+	case level <= slog.LevelInfo:
+		return "info"
+	case level <= slog.LevelWarn:
+		return "warn"
+	default:
+		return "error"
+	}
+}
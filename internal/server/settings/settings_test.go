@@ -0,0 +1,49 @@
+package settings
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestApplyUpdatesLevelAndNotifies(t *testing.T) {
+	s := New(Defaults())
+	var notified []Change
+	s.OnChange(func(before, after Values, changes []Change) {
+		notified = append(notified, changes...)
+	})
+
+	level := "DEBUG"
+	paused := true
+	after, changes, err := s.Apply(Patch{LogLevel: &level, SchedulerPaused: &paused})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if after.LogLevel != "debug" || !after.SchedulerPaused {
+		t.Fatalf("unexpected values %+v", after)
+	}
+	if len(changes) != 2 || len(notified) != 2 {
+		t.Fatalf("expected 2 changes, got %v (notified %v)", changes, notified)
+	}
+	if s.Level().Level() != slog.LevelDebug {
+		t.Fatalf("expected debug level to be enabled")
+	}
+
+	if _, changes, _ := s.Apply(Patch{SchedulerPaused: &paused}); len(changes) != 0 {
+		t.Fatalf("expected no-op patch to report no changes, got %v", changes)
+	}
+	if len(notified) != 2 {
+		t.Fatalf("expected no notification for no-op patch")
+	}
+}
+
+func TestApplyRejectsInvalidLevel(t *testing.T) {
+	s := New(Defaults())
+	bad := "verbose"
+	debug := true
+	if _, _, err := s.Apply(Patch{LogLevel: &bad, DebugEvents: &debug}); err == nil {
+		t.Fatalf("expected error for invalid level")
+	}
+	if s.Get().DebugEvents {
+		t.Fatalf("invalid patch must not be partially applied")
+	}
+}
@@ -3,6 +3,7 @@ package sourcestore
 import (
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/flowd-org/flowd/internal/types"
 )
@@ -24,6 +25,56 @@ type Source struct {
 	VerifySignatures bool                 `json:"verify_signatures,omitempty"`
 	Provenance       map[string]any       `json:"provenance,omitempty"`
 	Expose           string               `json:"expose,omitempty"`
+	Webhook          *Webhook             `json:"webhook,omitempty"`
+	// TrustLevel gates what jobs from the source may do; empty means
+	// TrustTrusted.
+	TrustLevel string `json:"trust_level,omitempty"`
+	// TrustHistory records every change of TrustLevel.
+	TrustHistory []TrustChange `json:"trust_history,omitempty"`
+	// Quarantine is set when a scheduled re-verification fails or an
+	// operator quarantines the source; new runs and plans from the source
+	// are refused while it is set.
+	Quarantine *Quarantine `json:"quarantine,omitempty"`
+	// VerifiedAt is when the source last passed re-verification.
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+}
+
+// Quarantine records why a source was taken out of service.
+type Quarantine struct {
+	Code   string    `json:"code"`
+	Reason string    `json:"reason"`
+	Since  time.Time `json:"since"`
+	// By is the principal that quarantined the source by hand.
+	By string `json:"by,omitempty"`
+}
+
+// QuarantineManual is the Quarantine code of sources quarantined through
+// the API. Re-verification never lifts it.
+const QuarantineManual = "manual"
+
+// IsManual reports whether q was set through the API; q may be nil.
+func (q *Quarantine) IsManual() bool {
+	return q != nil && q.Code == QuarantineManual
+}
+
+// Webhook configures runs triggered by a forge's push and pull request
+// webhooks. Secrets are held as references resolved by the secrets provider.
+type Webhook struct {
+	Provider  string    `json:"provider"`
+	Job       string    `json:"job"`
+	SecretRef string    `json:"secret_ref"`
+	Reporter  *Reporter `json:"reporter,omitempty"`
+}
+
+// Reporter configures how the status of webhook-triggered runs is reported
+// back to the forge.
+type Reporter struct {
+	Type           string `json:"type"`
+	APIURL         string `json:"api_url,omitempty"`
+	AppID          int64  `json:"app_id,omitempty"`
+	InstallationID int64  `json:"installation_id,omitempty"`
+	PrivateKeyRef  string `json:"private_key_ref,omitempty"`
+	TokenRef       string `json:"token_ref,omitempty"`
 }
 
 // Store keeps sources in memory for the API lifetime.
@@ -72,6 +123,20 @@ func (s *Store) Upsert(src Source) bool {
 	return !exists
 }
 
+// Modify applies fn to the stored source under the store lock and reports
+// whether the source existed.
+func (s *Store) Modify(name string, fn func(*Source)) (Source, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	src, ok := s.sources[name]
+	if !ok {
+		return Source{}, false
+	}
+	fn(&src)
+	s.sources[name] = src
+	return src, true
+}
+
 // Delete removes a source by name and returns true if it existed.
 func (s *Store) Delete(name string) bool {
 	s.mu.Lock()
@@ -1,6 +1,9 @@
 package sourcestore
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestStoreUpsertAndGet(t *testing.T) {
 	store := New()
@@ -43,3 +46,31 @@ func TestStoreDelete(t *testing.T) {
 		t.Fatalf("expected deleting non-existent source to return false")
 	}
 }
+
+func TestStoreSetTrustLevelRecordsHistory(t *testing.T) {
+	store := New()
+	store.Upsert(Source{Name: "demo", Type: "git"})
+	got, _ := store.Get("demo")
+	if level := got.EffectiveTrustLevel(); level != TrustTrusted {
+		t.Fatalf("expected default trust level trusted, got %s", level)
+	}
+
+	at := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+	src, change, ok := store.SetTrustLevel("demo", TrustLimited, "alice", "new upstream", at)
+	if !ok {
+		t.Fatalf("expected source to exist")
+	}
+	if src.TrustLevel != TrustLimited || change.From != TrustTrusted || change.To != TrustLimited || change.Actor != "alice" {
+		t.Fatalf("unexpected change %+v on %+v", change, src)
+	}
+	if _, change, _ = store.SetTrustLevel("demo", TrustLimited, "bob", "", at); change.To != "" {
+		t.Fatalf("expected no-op change, got %+v", change)
+	}
+	got, _ = store.Get("demo")
+	if len(got.TrustHistory) != 1 || !got.TrustHistory[0].At.Equal(at) {
+		t.Fatalf("expected one history entry, got %+v", got.TrustHistory)
+	}
+	if _, _, ok := store.SetTrustLevel("missing", TrustTrusted, "", "", at); ok {
+		t.Fatalf("expected missing source to report false")
+	}
+}
@@ -0,0 +1,67 @@
+package sourcestore
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Source trust levels, from least to most privileged. Untrusted sources may
+// only be planned; limited sources run solely in a container sandbox without
+// network access; trusted sources run like any local job.
+const (
+	TrustUntrusted = "untrusted"
+	TrustLimited   = "limited"
+	TrustTrusted   = "trusted"
+)
+
+// TrustChange is one audited transition of a source's trust level.
+type TrustChange struct {
+	At     time.Time `json:"at"`
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Actor  string    `json:"actor,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// ParseTrustLevel normalizes level, treating "" as TrustTrusted.
+func ParseTrustLevel(level string) (string, error) {
+	switch v := strings.ToLower(strings.TrimSpace(level)); v {
+	case "":
+		return TrustTrusted, nil
+	case TrustUntrusted, TrustLimited, TrustTrusted:
+		return v, nil
+	default:
+		return "", fmt.Errorf("trust_level must be one of %s, %s or %s", TrustUntrusted, TrustLimited, TrustTrusted)
+	}
+}
+
+// EffectiveTrustLevel returns the source's trust level, defaulting to
+// TrustTrusted for sources registered without one.
+func (s Source) EffectiveTrustLevel() string {
+	if level, err := ParseTrustLevel(s.TrustLevel); err == nil {
+		return level
+	}
+	return TrustUntrusted
+}
+
+// SetTrustLevel moves the named source to level and appends the change to its
+// history. It reports false when the source does not exist; a change to the
+// current level is recorded as a no-op and returns a zero TrustChange.
+func (s *Store) SetTrustLevel(name, level, actor, reason string, at time.Time) (Source, TrustChange, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	src, ok := s.sources[name]
+	if !ok {
+		return Source{}, TrustChange{}, false
+	}
+	from := src.EffectiveTrustLevel()
+	if from == level {
+		return src, TrustChange{}, true
+	}
+	change := TrustChange{At: at.UTC(), From: from, To: level, Actor: actor, Reason: reason}
+	src.TrustLevel = level
+	src.TrustHistory = append(append([]TrustChange(nil), src.TrustHistory...), change)
+	s.sources[name] = src
+	return src, change, true
+}
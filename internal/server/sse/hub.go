@@ -6,6 +6,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/flowd-org/flowd/internal/server/metrics"
 )
 
 const (
@@ -171,23 +173,22 @@ func (rs *runStream) removeSubscriber(sub *subscriber) {
 	delete(rs.subscribers, sub)
 }
 
+// replay sends buffered events after lastID to ch. The buffer is snapshotted
+// so that a slow subscriber never holds the stream lock and stalls Publish.
 func (rs *runStream) replay(ch chan<- []byte, lastID string) {
 	rs.mu.RLock()
-	defer rs.mu.RUnlock()
-	if lastID == "" {
-		for _, ev := range rs.events {
-			ch <- formatEvent(ev)
-		}
-		return
-	}
 	start := 0
-	for i, ev := range rs.events {
-		if ev.ID == lastID {
-			start = i + 1
-			break
+	if lastID != "" {
+		for i, ev := range rs.events {
+			if ev.ID == lastID {
+				start = i + 1
+				break
+			}
 		}
 	}
-	for _, ev := range rs.events[start:] {
+	pending := append([]Event(nil), rs.events[start:]...)
+	rs.mu.RUnlock()
+	for _, ev := range pending {
 		ch <- formatEvent(ev)
 	}
 }
@@ -200,6 +201,7 @@ func (rs *runStream) broadcast(payload []byte) {
 		case sub.ch <- payload:
 		default:
 			// drop if slow; keep stream responsive
+			metrics.Default.RecordEventDropped("slow_subscriber")
 		}
 	}
 }
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package sortspec parses the sort query parameter of list endpoints: a
+// comma-separated list of fields, each optionally prefixed with "-" for
+// descending order, e.g. "status,-started_at".
+package sortspec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Key is one sort field. Earlier keys take precedence.
+type Key struct {
+	Field string
+	Desc  bool
+}
+
+// Parse reads spec and rejects fields outside allowed and repeated fields.
+// An empty spec yields no keys.
+func Parse(spec string, allowed ...string) ([]Key, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	known := make(map[string]bool, len(allowed))
+	for _, field := range allowed {
+		known[field] = true
+	}
+	var keys []Key
+	seen := map[string]bool{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		key := Key{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		switch {
+		case key.Field == "":
+			return nil, fmt.Errorf("empty sort field in %q", spec)
+		case !known[key.Field]:
+			return nil, fmt.Errorf("unknown sort field %q; expected one of %s", key.Field, strings.Join(allowed, ", "))
+		case seen[key.Field]:
+			return nil, fmt.Errorf("sort field %q given twice", key.Field)
+		}
+		seen[key.Field] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Apply orients cmp, the ascending comparison of the key's field.
+func (k Key) Apply(cmp int) int {
+	if k.Desc {
+		return -cmp
+	}
+	return cmp
+}
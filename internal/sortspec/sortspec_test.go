@@ -0,0 +1,26 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package sortspec
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	keys, err := Parse(" status, -started_at ", "started_at", "status")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := []Key{{Field: "status"}, {Field: "started_at", Desc: true}}
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("expected %+v, got %+v", want, keys)
+	}
+	if keys, err := Parse("", "status"); err != nil || keys != nil {
+		t.Fatalf("expected no keys for empty spec, got %+v, %v", keys, err)
+	}
+	for _, spec := range []string{"name", "status,", "-", "status,-status"} {
+		if _, err := Parse(spec, "status"); err == nil {
+			t.Fatalf("%q: expected error", spec)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package supportbundle packs the data of one run into a redacted archive
+// that can be attached to bug reports. JSON documents are reduced to an
+// allow-list of fields and every included string, log line and config file
+// passes through a Scrubber, so a bundle carries what is needed to diagnose
+// a run but no secrets.
+package supportbundle
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/engine"
+	"github.com/flowd-org/flowd/internal/events"
+	"github.com/flowd-org/flowd/internal/types"
+)
+
+// Fields allow-listed per document. Anything else, such as provenance with
+// source URLs or the principals of an approval, is left out.
+var (
+	PlanFields    = []string{"job_id", "effective_argspec", "executor_preview", "requirements", "resolved_args", "security_profile", "policy_findings", "image_trust", "steps", "scripts", "impact"}
+	ReceiptFields = []string{"run_id", "job_id", "status", "security_profile", "started_at", "finished_at", "scripts", "shutdown", "runner"}
+	RunFields     = []string{"id", "job_id", "status", "started_at", "finished_at", "result", "executor", "runtime", "security_profile", "archived", "shutdown", "runner"}
+	EventFields   = []string{"sequence", "timestamp", "event", "data"}
+)
+
+// Source is the run data a bundle is built from. Pieces that are missing are
+// skipped and noted in the manifest.
+type Source struct {
+	RunID string
+	// RunDir is the run's artifact directory (plan.json, receipt.json, logs).
+	RunDir string
+	// JobDir is the job directory holding config.d/config.yaml.
+	JobDir string
+	// Run and Events are the server's GET /runs/{id} payload and
+	// events.ndjson export, for runs executed in serve mode.
+	Run    []byte
+	Events []byte
+	// Secrets are extra literal values to scrub.
+	Secrets []string
+}
+
+// Manifest is written to manifest.json at the root of the bundle.
+type Manifest struct {
+	RunID      string              `json:"run_id"`
+	CreatedAt  time.Time           `json:"created_at"`
+	Files      []string            `json:"files"`
+	Skipped    []string            `json:"skipped,omitempty"`
+	Fields     map[string][]string `json:"fields"`
+	Redactions int                 `json:"redactions"`
+}
+
+type bundleFile struct {
+	name string
+	data []byte
+}
+
+// Write builds the bundle for src as a gzip-compressed tar archive on w.
+func Write(w io.Writer, src Source, now time.Time) (Manifest, error) {
+	manifest := Manifest{
+		RunID:     src.RunID,
+		CreatedAt: now.UTC(),
+		Fields: map[string][]string{
+			"plan.json":     PlanFields,
+			"receipt.json":  ReceiptFields,
+			"run.json":      RunFields,
+			"events.ndjson": EventFields,
+		},
+	}
+	plan, planErr := readJSON(filepath.Join(src.RunDir, "plan.json"))
+	scrub := NewScrubber(append(secretArgValues(plan), src.Secrets...))
+	var files []bundleFile
+	add := func(name string, data []byte) {
+		files = append(files, bundleFile{name: name, data: data})
+	}
+	skip := func(name string, err error) {
+		manifest.Skipped = append(manifest.Skipped, fmt.Sprintf("%s: %v", name, err))
+	}
+
+	if planErr != nil {
+		skip("plan.json", planErr)
+	} else {
+		add("plan.json", encodeJSON(scrub.Value(pick(redactSecretArgs(plan), PlanFields))))
+	}
+	if receipt, err := readJSON(filepath.Join(src.RunDir, "receipt.json")); err != nil {
+		skip("receipt.json", err)
+	} else {
+		add("receipt.json", encodeJSON(scrub.Value(pick(receipt, ReceiptFields))))
+	}
+	if len(src.Run) > 0 {
+		var run map[string]any
+		if err := json.Unmarshal(src.Run, &run); err != nil {
+			skip("run.json", err)
+		} else {
+			add("run.json", encodeJSON(scrub.Value(pick(run, RunFields))))
+		}
+	}
+	if len(src.Events) > 0 {
+		add("events.ndjson", scrubEvents(src.Events, scrub))
+	}
+	for _, log := range []struct{ name, file string }{
+		{"logs/stdout.log", "stdout"},
+		{"logs/stderr.log", "stderr"},
+		{engine.OutputsFile, engine.OutputsFile},
+	} {
+		data, err := os.ReadFile(filepath.Join(src.RunDir, log.file))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				skip(log.name, err)
+			}
+			continue
+		}
+		add(log.name, []byte(scrub.String(string(data))))
+	}
+	if src.JobDir == "" {
+		skip("config.yaml", fmt.Errorf("job directory unknown"))
+	} else if data, err := os.ReadFile(filepath.Join(src.JobDir, "config.d", "config.yaml")); err != nil {
+		skip("config.yaml", err)
+	} else {
+		add("config.yaml", []byte(scrub.String(string(data))))
+	}
+	if len(files) == 0 {
+		return manifest, fmt.Errorf("no data found for run %s", src.RunID)
+	}
+
+	for _, f := range files {
+		manifest.Files = append(manifest.Files, f.name)
+	}
+	manifest.Redactions = scrub.Redactions()
+	files = append([]bundleFile{{name: "manifest.json", data: encodeJSON(manifest)}}, files...)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	prefix := "support-" + src.RunID + "/"
+	for _, f := range files {
+		hdr := &tar.Header{Name: prefix + f.name, Mode: 0o600, Size: int64(len(f.data)), ModTime: manifest.CreatedAt}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return manifest, err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return manifest, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return manifest, err
+	}
+	return manifest, gz.Close()
+}
+
+func readJSON(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", filepath.Base(path), err)
+	}
+	return doc, nil
+}
+
+func encodeJSON(v any) []byte {
+	data, _ := json.MarshalIndent(v, "", "  ")
+	return append(data, '\n')
+}
+
+// pick keeps the allow-listed keys of doc.
+func pick(doc map[string]any, fields []string) map[string]any {
+	out := make(map[string]any, len(fields))
+	for _, f := range fields {
+		if v, ok := doc[f]; ok {
+			out[f] = v
+		}
+	}
+	return out
+}
+
+// secretArgs returns the names of the plan's secret arguments.
+func secretArgs(plan map[string]any) map[string]struct{} {
+	raw, err := json.Marshal(plan["effective_argspec"])
+	if err != nil {
+		return nil
+	}
+	var spec types.ArgSpec
+	if json.Unmarshal(raw, &spec) != nil {
+		return nil
+	}
+	names := map[string]struct{}{}
+	for _, arg := range spec.Args {
+		if arg.Secret || arg.Format == "secret" {
+			names[arg.Name] = struct{}{}
+		}
+	}
+	return names
+}
+
+// secretArgValues collects the values of secret arguments so they are also
+// scrubbed where they were echoed, e.g. in logs or outputs.
+func secretArgValues(plan map[string]any) []string {
+	args, _ := plan["resolved_args"].(map[string]any)
+	var values []string
+	for name := range secretArgs(plan) {
+		if v, ok := args[name].(string); ok {
+			values = append(values, v)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	return values
+}
+
+func redactSecretArgs(plan map[string]any) map[string]any {
+	args, ok := plan["resolved_args"].(map[string]any)
+	if !ok {
+		return plan
+	}
+	redacted := make(map[string]any, len(plan))
+	for k, v := range plan {
+		redacted[k] = v
+	}
+	redacted["resolved_args"] = events.RedactSecrets(args, secretArgs(plan))
+	return redacted
+}
+
+// scrubEvents reduces each NDJSON event to the allow-listed fields and
+// scrubs it. Lines that are not JSON objects are dropped.
+func scrubEvents(data []byte, scrub *Scrubber) []byte {
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		var ev map[string]any
+		if line == "" || json.Unmarshal([]byte(line), &ev) != nil {
+			continue
+		}
+		encoded, err := json.Marshal(scrub.Value(pick(ev, EventFields)))
+		if err != nil {
+			continue
+		}
+		out.Write(encoded)
+		out.WriteByte('\n')
+	}
+	return out.Bytes()
+}
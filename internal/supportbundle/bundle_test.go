@@ -0,0 +1,90 @@
+package supportbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readBundle(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	files := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(tr)
+		files[strings.TrimPrefix(hdr.Name, "support-run1/")] = string(body)
+	}
+}
+
+func TestWriteRedactsAndAllowLists(t *testing.T) {
+	runDir, jobDir := t.TempDir(), t.TempDir()
+	write := func(path, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(runDir, "plan.json"), `{
+  "job_id": "deploy",
+  "effective_argspec": {"args": [{"name": "key", "type": "string", "secret": true}, {"name": "env", "type": "string"}]},
+  "resolved_args": {"key": "s3cr3t-value", "env": "prod"},
+  "provenance": {"source": {"url": "https://bot:pw@git.example.com/x.git"}}
+}`)
+	write(filepath.Join(runDir, "receipt.json"), `{"run_id":"run1","job_id":"deploy","status":"failed","approval":{"requested_by":"alice"}}`)
+	write(filepath.Join(runDir, "stdout"), "using key s3cr3t-value\nAPI_TOKEN=abc123\n")
+	write(filepath.Join(jobDir, "config.d", "config.yaml"), "env:\n  DB_PASSWORD: hunter22\n")
+	events := `{"sequence":1,"event":"step.log","data":{"message":"key s3cr3t-value"},"extra":"x"}` + "\n"
+
+	var buf bytes.Buffer
+	manifest, err := Write(&buf, Source{RunID: "run1", RunDir: runDir, JobDir: jobDir, Events: []byte(events)}, time.Unix(0, 0))
+	if err != nil {
+		t.Fatalf("write bundle: %v", err)
+	}
+	files := readBundle(t, buf.Bytes())
+	for name, body := range files {
+		for _, secret := range []string{"s3cr3t-value", "abc123", "hunter22", "alice", "git.example.com"} {
+			if strings.Contains(body, secret) {
+				t.Fatalf("%s leaks %q:\n%s", name, secret, body)
+			}
+		}
+	}
+	if !strings.Contains(files["plan.json"], `"env": "prod"`) || !strings.Contains(files["logs/stdout.log"], "API_TOKEN=[secret]") {
+		t.Fatalf("expected non-secret data to survive, got plan %s log %s", files["plan.json"], files["logs/stdout.log"])
+	}
+	if strings.Contains(files["events.ndjson"], "extra") {
+		t.Fatalf("expected events to be allow-listed, got %s", files["events.ndjson"])
+	}
+	var got Manifest
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &got); err != nil {
+		t.Fatalf("decode manifest: %v", err)
+	}
+	if len(got.Files) != 5 || got.Redactions != manifest.Redactions || got.Redactions < 4 {
+		t.Fatalf("unexpected manifest %+v", got)
+	}
+}
+
+func TestWriteFailsWithoutData(t *testing.T) {
+	if _, err := Write(io.Discard, Source{RunID: "missing", RunDir: t.TempDir()}, time.Now()); err == nil {
+		t.Fatalf("expected an error for a run without data")
+	}
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package supportbundle
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/flowd-org/flowd/internal/events"
+)
+
+// scrubRule replaces matches of pattern; $1 keeps the part that names the
+// secret (a key or header) so the redacted text still reads naturally.
+type scrubRule struct {
+	pattern *regexp.Regexp
+	keep    bool
+}
+
+var scrubRules = []scrubRule{
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), false},
+	{regexp.MustCompile(`(?i)(authorization:\s*(?:bearer|basic|token)\s+)\S+`), true},
+	{regexp.MustCompile(`(?i)(\b[a-z0-9_.-]*(?:token|secret|password|passwd|api[_-]?key|access[_-]?key|private[_-]?key|credentials?)[a-z0-9_.-]*["']?\s*[=:]\s*)("[^"]*"|'[^']*'|[^\s,;&"']+)`), true},
+	{regexp.MustCompile(`([a-z][a-z0-9+.-]*://[^/\s:@]+:)[^@\s/]+@`), true},
+	{regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`), false},
+	{regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`), false},
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), false},
+}
+
+var notSecretValues = map[string]bool{"true": true, "false": true, "null": true, "~": true}
+
+// Scrubber removes secrets from text: literal values known to be secret, such
+// as secret job arguments, and anything matching common credential shapes.
+// Matches are replaced with the same [secret] token run logs use.
+type Scrubber struct {
+	values     []string
+	redactions int
+}
+
+// NewScrubber returns a Scrubber that also removes the given literal values.
+func NewScrubber(values []string) *Scrubber {
+	s := &Scrubber{}
+	for _, v := range values {
+		// Very short values would shred unrelated text.
+		if len(v) >= 4 && v != events.SecretToken() {
+			s.values = append(s.values, v)
+		}
+	}
+	return s
+}
+
+// String returns text with secrets replaced.
+func (s *Scrubber) String(text string) string {
+	token := events.SecretToken()
+	for _, v := range s.values {
+		if n := strings.Count(text, v); n > 0 {
+			s.redactions += n
+			text = strings.ReplaceAll(text, v, token)
+		}
+	}
+	for _, rule := range scrubRules {
+		text = rule.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if rule.keep {
+				groups := rule.pattern.FindStringSubmatch(match)
+				prefix := groups[1]
+				if strings.HasSuffix(match, token) || strings.HasSuffix(match, token+"@") {
+					return match
+				}
+				// "secret: true" flags an argument; it is not a secret.
+				if len(groups) > 2 && notSecretValues[strings.ToLower(groups[2])] {
+					return match
+				}
+				s.redactions++
+				if strings.HasSuffix(match, "@") {
+					return prefix + token + "@"
+				}
+				return prefix + token
+			}
+			s.redactions++
+			return token
+		})
+	}
+	return text
+}
+
+// Value scrubs every string inside a decoded JSON value.
+func (s *Scrubber) Value(v any) any {
+	switch t := v.(type) {
+	case string:
+		return s.String(t)
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, val := range t {
+			out[k] = s.Value(val)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, val := range t {
+			out[i] = s.Value(val)
+		}
+		return out
+	}
+	return v
+}
+
+// Redactions reports how many secrets have been replaced so far.
+func (s *Scrubber) Redactions() int {
+	return s.redactions
+}
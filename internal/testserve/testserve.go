@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package testserve runs the real serve-mode server on a loopback port for
+// the tests of its clients.
+package testserve
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flowd-org/flowd/internal/executor/container"
+	"github.com/flowd-org/flowd/internal/server"
+)
+
+// Job is a job written into the served scripts root.
+type Job struct {
+	ID string
+	// Config is the job's config.yaml.
+	Config string
+	// Script is the job's only step, 100_main.sh.
+	Script string
+}
+
+// Greet echoes a greeting for its required name argument.
+var Greet = Job{
+	ID:     "greet",
+	Config: "version: v1\njob:\n  id: greet\n  name: Greet\ninterpreter: \"/bin/bash\"\nargspec:\n  args:\n    - name: name\n      type: string\n      required: true\n",
+	Script: "#!/usr/bin/env bash\necho \"hello $1\"\n",
+}
+
+// Start serves jobs on a free loopback port until the test ends and returns
+// the server's host:port once /healthz answers. Bearer tokens are opaque
+// scope lists, so token must grant what the test needs.
+func Start(t testing.TB, token string, jobs ...Job) string {
+	t.Helper()
+	t.Setenv("FLWD_JWT_SECRET", "")
+	root := t.TempDir()
+	for _, job := range jobs {
+		jobDir := filepath.Join(root, job.ID)
+		if err := os.MkdirAll(filepath.Join(jobDir, "config.d"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(jobDir, "config.d", "config.yaml"), []byte(job.Config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(jobDir, "100_main.sh"), []byte(job.Script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Run(ctx, server.Config{
+			Bind:            addr,
+			Profile:         "permissive",
+			ScriptsRoot:     root,
+			DataDir:         t.TempDir(),
+			StdOut:          io.Discard,
+			StdErr:          io.Discard,
+			RuntimeDetector: func() (container.Runtime, error) { return container.RuntimePodman, nil },
+		})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		req, _ := http.NewRequest(http.MethodGet, "http://"+addr+"/healthz", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusNoContent {
+				return addr
+			}
+		}
+		select {
+		case runErr := <-done:
+			t.Fatalf("server exited: %v", runErr)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not become ready: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -25,6 +25,44 @@ type Arg struct {
 	ItemsType   string      `yaml:"items_type,omitempty" json:"items_type,omitempty"`
 	ItemsEnum   []string    `yaml:"items_enum,omitempty" json:"items_enum,omitempty"`
 	ValueType   string      `yaml:"value_type,omitempty" json:"value_type,omitempty"`
+
+	// Severity "warning" turns violations of the required, enum and
+	// items_enum rules into warnings on the plan and run instead of
+	// failing validation.
+	Severity string `yaml:"severity,omitempty" json:"severity,omitempty"`
+	// Deprecated is reported as a warning whenever the arg is supplied,
+	// e.g. "use --region instead".
+	Deprecated string `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	// From is a secret reference, e.g. vault:path/to/key or env:NAME, that
+	// serve mode resolves when the run starts. Secret args with From are
+	// never supplied by callers.
+	From string `yaml:"from,omitempty" json:"from,omitempty"`
+}
+
+// ArgEnvConfig controls the environment variables scalar args are exported
+// as, so scripts can keep reading legacy names while they migrate to
+// ARG_<NAME>.
+type ArgEnvConfig struct {
+	// Names maps an arg name to an extra variable that also receives its
+	// value, e.g. region: DEPLOY_REGION.
+	Names map[string]string `yaml:"names,omitempty" json:"names,omitempty"`
+	// DropArgVars stops exporting ARG_<NAME> for the args in Names once
+	// scripts only read the mapped names.
+	DropArgVars bool `yaml:"drop_arg_vars,omitempty" json:"drop_arg_vars,omitempty"`
+}
+
+// Arg rule severities; the empty severity means error.
+const (
+	ArgSeverityError   = "error"
+	ArgSeverityWarning = "warning"
+)
+
+// ArgWarning reports an arg rule marked severity: warning that the supplied
+// args broke, or the use of a deprecated arg.
+type ArgWarning struct {
+	Arg     string `json:"arg"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 type ArgSpec struct {
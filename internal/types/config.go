@@ -10,18 +10,127 @@ type ErrorHandling struct {
 type Config struct {
 	Interpreter    string            `yaml:"interpreter,omitempty"`
 	Env            map[string]string `yaml:"env,omitempty"`
-	Timeout        int               `yaml:"timeout,omitempty"`
+	Timeout        string            `yaml:"timeout,omitempty"` // whole run, e.g. "10m"; plain numbers are seconds
 	ErrorHandling  ErrorHandling     `yaml:"error_handling,omitempty"`
 	Executor       string            `yaml:"executor,omitempty"`
 	Container      *ContainerConfig  `yaml:"container,omitempty"`
 	EnvInheritance bool              `yaml:"env_inheritance,omitempty"`
-	Composition    string            `yaml:"composition,omitempty"`
-	Steps          []StepConfig      `yaml:"steps,omitempty"`
+	// SecurityProfile pins a minimum profile for the job; requests cannot relax it.
+	SecurityProfile string       `yaml:"security_profile,omitempty"`
+	Composition     string       `yaml:"composition,omitempty"`
+	Steps           []StepConfig `yaml:"steps,omitempty"`
 	//old ---------------
 	Arguments map[string]ArgumentDefinition `yaml:"arguments,omitempty"`
 	// New (Phase 1): SOT-aligned ArgSpec (preferred when provided)
 	ArgSpec *ArgSpec       `yaml:"argspec,omitempty"`
 	Aliases []CommandAlias `yaml:"aliases,omitempty"`
+	// Outputs declares the values the job's scripts write to $FLWD_OUTPUTS.
+	Outputs []OutputSpec `yaml:"outputs,omitempty"`
+	// InputsFrom names the upstream job whose outputs may seed this job's args.
+	InputsFrom string `yaml:"inputs_from,omitempty"`
+	// Notify announces finished runs, e.g. by email.
+	Notify *NotifyConfig `yaml:"notify,omitempty"`
+	// ArgsStyle additionally passes validated args to scripts as positional
+	// parameters, --name=value flags or a JSON document on stdin.
+	ArgsStyle string `yaml:"args_style,omitempty"`
+	// Hooks run setup and teardown scripts around the job's steps.
+	Hooks *HooksConfig `yaml:"hooks,omitempty"`
+	// Cancel chooses the signal steps receive when the run is canceled and
+	// how long they may take to exit before being killed.
+	Cancel *CancelConfig `yaml:"cancel,omitempty"`
+	// Impact declares target environments and blast radius; high-impact jobs
+	// need explicit confirmation to run.
+	Impact *ImpactConfig `yaml:"impact,omitempty"`
+	// Approval holds serve-mode runs until principals other than the
+	// requester approve them.
+	Approval *ApprovalPolicy `yaml:"approval,omitempty"`
+	// Concurrency limits how many of the job's runs execute at once in
+	// serve mode.
+	Concurrency *ConcurrencyConfig `yaml:"concurrency,omitempty"`
+	// Schedule starts runs on cron schedules while the server is running.
+	Schedule []ScheduleConfig `yaml:"schedule,omitempty"`
+	// ArgEnv renames the environment variables scalar args are exported as.
+	ArgEnv *ArgEnvConfig `yaml:"arg_env,omitempty"`
+	// Kubernetes places the step pods of executor: kubernetes jobs.
+	Kubernetes *KubernetesConfig `yaml:"kubernetes,omitempty"`
+	// SSH names the remote host executor: ssh steps run on.
+	SSH *SSHConfig `yaml:"ssh,omitempty"`
+}
+
+// ConcurrencyConfig caps the job's simultaneously executing runs at Max.
+// Policy decides what happens to runs submitted while all slots are taken:
+// ConcurrencyQueue (the default) keeps them queued until a slot frees, oldest
+// first; ConcurrencyReject refuses them.
+type ConcurrencyConfig struct {
+	Max    int    `yaml:"max"`
+	Policy string `yaml:"policy,omitempty"`
+}
+
+// Concurrency policies for runs submitted while a job is at its limit.
+const (
+	ConcurrencyQueue  = "queue"
+	ConcurrencyReject = "reject"
+)
+
+// KubernetesConfig chooses where executor: kubernetes steps run. Each step
+// becomes a pod built from the container image and resources. Context names
+// a kubeconfig context (default: the current one) and Namespace defaults to
+// the context's namespace.
+type KubernetesConfig struct {
+	Context        string            `yaml:"context,omitempty"`
+	Namespace      string            `yaml:"namespace,omitempty"`
+	ServiceAccount string            `yaml:"service_account,omitempty"`
+	NodeSelector   map[string]string `yaml:"node_selector,omitempty"`
+}
+
+// SSHConfig chooses the host executor: ssh steps run on and how to log in.
+// IdentityFile is an absolute path to a private key on the server; when it
+// is empty the server's ssh-agent provides the keys. KnownHostsFile replaces
+// the client's known_hosts; host keys are always checked.
+type SSHConfig struct {
+	Host           string `yaml:"host"`
+	Port           int    `yaml:"port,omitempty"`
+	User           string `yaml:"user,omitempty"`
+	IdentityFile   string `yaml:"identity_file,omitempty"`
+	KnownHostsFile string `yaml:"known_hosts_file,omitempty"`
+}
+
+// HooksConfig names scripts, relative to the job directory, that run in the
+// job's sandbox before and after its steps. PostRun always runs, even when the
+// run failed or was canceled, and sees the outcome in $FLWD_RUN_STATUS.
+type HooksConfig struct {
+	PreRun  string `yaml:"pre_run,omitempty"`
+	PostRun string `yaml:"post_run,omitempty"`
+}
+
+// CancelConfig customizes how steps are stopped on cancel. Signal is a name
+// such as SIGINT (default SIGTERM); GracePeriod is a duration such as "30s"
+// (default 10s) after which remaining processes are killed.
+type CancelConfig struct {
+	Signal      string `yaml:"signal,omitempty"`
+	GracePeriod string `yaml:"grace_period,omitempty"`
+}
+
+// Run shutdown outcomes recorded for canceled runs.
+const (
+	ShutdownGraceful = "graceful"
+	ShutdownForced   = "forced"
+)
+
+// Script argument styles for Config.ArgsStyle. ARG_* and FLWD_ARGS_JSON are
+// always set regardless of style.
+const (
+	ArgsStylePositional = "positional"
+	ArgsStyleFlags      = "flags"
+	ArgsStyleStdinJSON  = "stdin-json"
+)
+
+// OutputSpec declares one job output. Types mirror the scalar arg types.
+type OutputSpec struct {
+	Name        string `yaml:"name" json:"name"`
+	Type        string `yaml:"type" json:"type"` // string|integer|boolean
+	Required    bool   `yaml:"required,omitempty" json:"required,omitempty"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
 }
 
 // CommandAlias defines a friendly alias for a fully qualified job path.
@@ -39,6 +148,13 @@ type StepConfig struct {
 	Needs     []string         `yaml:"needs,omitempty"`
 	Executor  string           `yaml:"executor,omitempty"`
 	Container *ContainerConfig `yaml:"container,omitempty"`
+	// Workdir is relative to the run directory, or to the job checkout when
+	// prefixed with "checkout:".
+	Workdir string `yaml:"workdir,omitempty"`
+	// Shell overrides the job interpreter: bash, sh or pwsh plus flags.
+	Shell string `yaml:"shell,omitempty"`
+	// Timeout bounds the step, like Config.Timeout bounds the run.
+	Timeout string `yaml:"timeout,omitempty"`
 }
 
 // ContainerConfig captures container-specific execution settings.
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package types
+
+import (
+	"slices"
+	"strings"
+)
+
+// Blast radius levels for ImpactConfig.BlastRadius.
+const (
+	BlastRadiusLow    = "low"
+	BlastRadiusMedium = "medium"
+	BlastRadiusHigh   = "high"
+)
+
+// productionEnvironments are the environment names that make a job
+// high-impact on their own.
+var productionEnvironments = []string{"prod", "production"}
+
+// ImpactConfig declares where a job acts and how much a bad run can break.
+type ImpactConfig struct {
+	Environments []string `yaml:"environments,omitempty" json:"environments,omitempty"`
+	BlastRadius  string   `yaml:"blast_radius,omitempty" json:"blast_radius,omitempty"`
+}
+
+// High reports whether runs need explicit confirmation: a high blast radius
+// or any production environment.
+func (c *ImpactConfig) High() bool {
+	if c == nil {
+		return false
+	}
+	if c.BlastRadius == BlastRadiusHigh {
+		return true
+	}
+	for _, env := range c.Environments {
+		if slices.Contains(productionEnvironments, env) {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary renders the declaration for confirmation prompts and errors, e.g.
+// "environments: prod; blast radius: high".
+func (c *ImpactConfig) Summary() string {
+	if c == nil {
+		return ""
+	}
+	var parts []string
+	if len(c.Environments) > 0 {
+		parts = append(parts, "environments: "+strings.Join(c.Environments, ", "))
+	}
+	if c.BlastRadius != "" {
+		parts = append(parts, "blast radius: "+c.BlastRadius)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ImpactPreview surfaces a job's impact in its plan.
+type ImpactPreview struct {
+	Environments []string `json:"environments,omitempty"`
+	BlastRadius  string   `json:"blast_radius,omitempty"`
+	// ConfirmationRequired is set for high-impact jobs: the CLI needs
+	// --yes-prod and the API the runs:high-impact scope.
+	ConfirmationRequired bool `json:"confirmation_required"`
+}
@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package types
+
+// NotifyConfig declares how a job's finished runs are announced.
+type NotifyConfig struct {
+	Email  *EmailNotify `yaml:"email,omitempty" json:"email,omitempty"`
+	Alerts []AlertRule  `yaml:"alerts,omitempty" json:"alerts,omitempty"`
+}
+
+// AlertRule opens an incident once a job fails Failures times in a row and
+// resolves it on the job's next successful run.
+type AlertRule struct {
+	// Provider is pagerduty or opsgenie.
+	Provider string `yaml:"provider" json:"provider"`
+	// Failures is the number of consecutive failed runs that opens the
+	// incident; defaults to 1.
+	Failures int `yaml:"failures,omitempty" json:"failures,omitempty"`
+	// KeyRef is a secret reference to the PagerDuty routing key or the
+	// Opsgenie API key.
+	KeyRef string `yaml:"key_ref" json:"key_ref"`
+	// Severity is the PagerDuty severity (critical, error, warning, info) or
+	// the Opsgenie priority (P1-P5).
+	Severity string `yaml:"severity,omitempty" json:"severity,omitempty"`
+	// APIURL overrides the provider endpoint, e.g. Opsgenie's EU instance.
+	APIURL string `yaml:"api_url,omitempty" json:"api_url,omitempty"`
+}
+
+// EmailNotify sends finished runs to a list of recipients. Subject and Body
+// are Go templates; empty templates use built-in defaults.
+type EmailNotify struct {
+	To      []string `yaml:"to" json:"to"`
+	Subject string   `yaml:"subject,omitempty" json:"subject,omitempty"`
+	Body    string   `yaml:"body,omitempty" json:"body,omitempty"`
+	// On limits notifications to runs finishing with these statuses.
+	On []string `yaml:"on,omitempty" json:"on,omitempty"`
+	// OnlyOnChange suppresses runs whose status matches the job's previous run.
+	OnlyOnChange bool `yaml:"only_on_change,omitempty" json:"only_on_change,omitempty"`
+	// Digest batches notifications into one email per interval (e.g. "1h"),
+	// for jobs that run too often to mail every run.
+	Digest        string `yaml:"digest,omitempty" json:"digest,omitempty"`
+	DigestSubject string `yaml:"digest_subject,omitempty" json:"digest_subject,omitempty"`
+	DigestBody    string `yaml:"digest_body,omitempty" json:"digest_body,omitempty"`
+}
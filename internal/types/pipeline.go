@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package types
+
+// PipelineFile is the document stored under <scripts root>/pipelines/.
+type PipelineFile struct {
+	Pipeline Pipeline `yaml:"pipeline"`
+}
+
+// Pipeline describes ordered promotion stages (e.g. dev -> staging -> prod).
+// A version must succeed in a stage before it can be promoted to the next.
+type Pipeline struct {
+	ID          string          `yaml:"id" json:"id"`
+	Description string          `yaml:"description,omitempty" json:"description,omitempty"`
+	Stages      []PipelineStage `yaml:"stages" json:"stages"`
+}
+
+// PipelineStage maps a stage to the job that deploys into it.
+type PipelineStage struct {
+	Name string         `yaml:"name" json:"name"`
+	Job  string         `yaml:"job" json:"job"`
+	Args map[string]any `yaml:"args,omitempty" json:"args,omitempty"`
+	// VersionArg names the job argument that receives the promoted version.
+	VersionArg string `yaml:"version_arg,omitempty" json:"version_arg,omitempty"`
+	// Chain passes the previous stage's run as inputs_from_run.
+	Chain    bool            `yaml:"chain,omitempty" json:"chain,omitempty"`
+	Approval *ApprovalPolicy `yaml:"approval,omitempty" json:"approval,omitempty"`
+}
+
+// ApprovalPolicy gates a pipeline stage or a job's runs on distinct approvals.
+// When Approvers is set only those principals may approve.
+type ApprovalPolicy struct {
+	Required  int      `yaml:"required,omitempty" json:"required,omitempty"`
+	Approvers []string `yaml:"approvers,omitempty" json:"approvers,omitempty"`
+}
@@ -13,7 +13,18 @@ type Plan struct {
 	PolicyFindings   []Finding              `json:"policy_findings,omitempty"`
 	ImageTrust       *ImageTrustPreview     `json:"image_trust,omitempty"`
 	Steps            []PlanStepPreview      `json:"steps,omitempty"`
+	Scripts          []ScriptDigest         `json:"scripts,omitempty"`
 	Provenance       map[string]interface{} `json:"provenance,omitempty"`
+	Impact           *ImpactPreview         `json:"impact,omitempty"`
+	// Warnings lists arg rules marked severity: warning that the args broke.
+	Warnings []ArgWarning `json:"warnings,omitempty"`
+}
+
+// ScriptDigest records the content hash of a script the plan will execute.
+// Path is relative to the job directory, with forward slashes.
+type ScriptDigest struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
 }
 
 type PlanRequirements struct {
@@ -40,6 +51,7 @@ type Finding struct {
 type ImageTrustPreview struct {
 	Image    string `json:"image,omitempty"`
 	Mode     string `json:"mode,omitempty"`
+	Backend  string `json:"backend,omitempty"`
 	Verified bool   `json:"verified"`
 	Reason   string `json:"reason,omitempty"`
 }
@@ -49,6 +61,9 @@ type PlanStepPreview struct {
 	ID             string              `json:"id,omitempty"`
 	Name           string              `json:"name,omitempty"`
 	Executor       string              `json:"executor,omitempty"`
+	Shell          string              `json:"shell,omitempty"`
+	Workdir        string              `json:"workdir,omitempty"`
+	Timeout        string              `json:"timeout,omitempty"`
 	ContainerImage string              `json:"container_image,omitempty"`
 	Network        string              `json:"network,omitempty"`
 	RootfsWritable bool                `json:"rootfs_writable,omitempty"`
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+package types
+
+import "time"
+
+// RunReceipt is written to receipt.json in the run directory once a run
+// reaches a terminal status. It ties the outcome to the exact scripts that
+// were executed.
+type RunReceipt struct {
+	RunID           string                 `json:"run_id"`
+	JobID           string                 `json:"job_id"`
+	Status          string                 `json:"status"`
+	SecurityProfile string                 `json:"security_profile,omitempty"`
+	StartedAt       time.Time              `json:"started_at"`
+	FinishedAt      time.Time              `json:"finished_at"`
+	Scripts         []ScriptDigest         `json:"scripts,omitempty"`
+	Provenance      map[string]interface{} `json:"provenance,omitempty"`
+	// Approval records the requester and approvers of runs of jobs with an
+	// approval policy.
+	Approval *RunApproval `json:"approval,omitempty"`
+	// Shutdown is "graceful" or "forced" for canceled runs.
+	Shutdown string `json:"shutdown,omitempty"`
+	// Runner describes the flowd build and host that executed the run.
+	Runner *RunnerInfo `json:"runner,omitempty"`
+	// Artifacts lists the files the run left under artifacts/ in its run
+	// directory.
+	Artifacts []RunArtifact `json:"artifacts,omitempty"`
+}
+
+// RunArtifact is a file a run published under artifacts/ in its run
+// directory. Name is its slash-separated path below that directory.
+type RunArtifact struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// RunnerInfo records what executed a run so that reproducibility
+// investigations can tell runs from different builds or hosts apart. The
+// container runtime fields are set for container runs only.
+type RunnerInfo struct {
+	Version                 string `json:"version"`
+	OS                      string `json:"os"`
+	Arch                    string `json:"arch"`
+	Hostname                string `json:"hostname,omitempty"`
+	ContainerRuntime        string `json:"container_runtime,omitempty"`
+	ContainerRuntimeVersion string `json:"container_runtime_version,omitempty"`
+}
+
+// RunApproval records who requested a run and which distinct principals
+// approved it before it was allowed to start.
+type RunApproval struct {
+	RequestedBy string          `json:"requested_by,omitempty"`
+	Required    int             `json:"required"`
+	Approvals   []RunApprovalBy `json:"approvals,omitempty"`
+}
+
+// RunApprovalBy is a single principal's sign-off on a run.
+type RunApprovalBy struct {
+	Principal string    `json:"principal"`
+	At        time.Time `json:"at"`
+	Comment   string    `json:"comment,omitempty"`
+}
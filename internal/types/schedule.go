@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package types
+
+// ScheduleConfig starts runs of a job on a cron schedule in serve mode. Cron
+// is a five-field expression or a macro such as @daily, evaluated in Timezone
+// (an IANA name, default UTC). Args and Labels are passed to every run.
+// Misfire decides what happens to times missed while the server was down and
+// DST to wall-clock times a daylight saving change skips or repeats.
+type ScheduleConfig struct {
+	// Name identifies the schedule within the job; it defaults to the
+	// schedule's 1-based position.
+	Name     string            `yaml:"name,omitempty" json:"name"`
+	Cron     string            `yaml:"cron" json:"cron"`
+	Timezone string            `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+	Args     map[string]any    `yaml:"args,omitempty" json:"args,omitempty"`
+	Labels   map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	// Enabled set to false keeps the schedule idle until it is enabled
+	// through the API.
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// Misfire is MisfireSkip (the default), MisfireRunOnce or
+	// MisfireRunAllMissed.
+	Misfire string       `yaml:"misfire,omitempty" json:"misfire,omitempty"`
+	DST     *ScheduleDST `yaml:"dst,omitempty" json:"dst,omitempty"`
+}
+
+// ScheduleDST decides how a schedule fires around daylight saving changes in
+// its time zone. Skipped is "skip" (the default, the time does not fire) or
+// "shift" (it fires when the change ends the gap); Repeated is "once" (the
+// default, the first occurrence fires) or "twice" (both occurrences fire).
+type ScheduleDST struct {
+	Skipped  string `yaml:"skipped,omitempty" json:"skipped,omitempty"`
+	Repeated string `yaml:"repeated,omitempty" json:"repeated,omitempty"`
+}
+
+// Misfire policies of a schedule.
+const (
+	// MisfireSkip drops times missed while the server was down.
+	MisfireSkip = "skip"
+	// MisfireRunOnce starts one run for the latest missed time.
+	MisfireRunOnce = "run-once"
+	// MisfireRunAllMissed starts a run for every missed time, oldest first.
+	MisfireRunAllMissed = "run-all-missed"
+)